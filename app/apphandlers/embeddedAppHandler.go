@@ -301,6 +301,9 @@ func (a *embeddedApp) StartRudderCore(ctx context.Context, options *app.Options)
 	g.Go(func() error {
 		return gw.StartWebHandler(ctx)
 	})
+	g.Go(func() error {
+		return gw.StartStreamSources(ctx)
+	})
 	if a.config.enableReplay {
 		var replayDB jobsdb.HandleT
 		err := replayDB.Setup(
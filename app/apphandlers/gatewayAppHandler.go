@@ -145,5 +145,8 @@ func (a *gatewayApp) StartRudderCore(ctx context.Context, options *app.Options)
 	g.Go(func() error {
 		return gw.StartWebHandler(ctx)
 	})
+	g.Go(func() error {
+		return gw.StartStreamSources(ctx)
+	})
 	return g.Wait()
 }
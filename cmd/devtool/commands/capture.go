@@ -0,0 +1,103 @@
+package commands
+
+import (
+	"fmt"
+	"net/http"
+	"path/filepath"
+	"time"
+
+	"github.com/urfave/cli/v2"
+
+	"github.com/rudderlabs/rudder-server/services/debugger/capture"
+)
+
+func init() {
+	DefaultList = append(DefaultList, CAPTURE())
+}
+
+func CAPTURE() *cli.Command {
+	c := &cli.Command{
+		Name:  "capture",
+		Usage: "inspect and replay captured destination http exchanges",
+		Subcommands: []*cli.Command{
+			{
+				Name:   "list",
+				Usage:  "list the captured exchanges of a destination",
+				Action: CaptureList,
+				Flags: []cli.Flag{
+					&cli.StringFlag{
+						Name:     "dir",
+						Usage:    "the directory of the captured exchanges (Router.capture.dir)",
+						Required: true,
+					},
+					&cli.StringFlag{
+						Name:     "destination",
+						Usage:    "the destination id",
+						Required: true,
+					},
+				},
+			},
+			{
+				Name:      "replay",
+				Usage:     "replay a captured request against a local stub, e.g. the one started by `devtool webhook run`",
+				ArgsUsage: "<exchange file>",
+				Action:    CaptureReplay,
+				Flags: []cli.Flag{
+					&cli.StringFlag{
+						Name:  "target",
+						Usage: "the url of the stub to send the request to",
+						Value: "http://localhost:8083",
+					},
+					&cli.DurationFlag{
+						Name:  "timeout",
+						Usage: "the request timeout",
+						Value: 30 * time.Second,
+					},
+				},
+			},
+		},
+	}
+
+	return c
+}
+
+func CaptureList(c *cli.Context) error {
+	files, err := capture.ListFiles(filepath.Join(c.String("dir"), c.String("destination")))
+	if err != nil {
+		return err
+	}
+	for _, file := range files {
+		e, err := capture.ReadExchange(file)
+		if err != nil {
+			return err
+		}
+		status := e.Error
+		if e.Response != nil {
+			status = fmt.Sprint(e.Response.StatusCode)
+		}
+		fmt.Printf("%s\t%s\t%s %s\t%s\n", file, e.CapturedAt.Format(time.RFC3339), e.Request.Method, e.Request.URL, status)
+	}
+	return nil
+}
+
+func CaptureReplay(c *cli.Context) error {
+	if c.NArg() != 1 {
+		return fmt.Errorf("expected exactly one exchange file, got %d", c.NArg())
+	}
+	e, err := capture.ReadExchange(c.Args().First())
+	if err != nil {
+		return err
+	}
+	client := &http.Client{Timeout: c.Duration("timeout")}
+	resp, body, err := capture.Replay(c.Context, client, e, c.String("target"))
+	if err != nil {
+		return err
+	}
+	if e.Response != nil {
+		fmt.Printf("captured response: %d\n%s\n\n", e.Response.StatusCode, e.Response.Body)
+	} else {
+		fmt.Printf("captured error: %s\n\n", e.Error)
+	}
+	fmt.Printf("replayed response: %d\n%s\n", resp.StatusCode, body)
+	return nil
+}
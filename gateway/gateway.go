@@ -42,6 +42,7 @@ import (
 	event_schema "github.com/rudderlabs/rudder-server/event-schema"
 	gwstats "github.com/rudderlabs/rudder-server/gateway/internal/stats"
 	"github.com/rudderlabs/rudder-server/gateway/response"
+	"github.com/rudderlabs/rudder-server/gateway/streamsource"
 	"github.com/rudderlabs/rudder-server/gateway/throttler"
 	"github.com/rudderlabs/rudder-server/gateway/webhook"
 	"github.com/rudderlabs/rudder-server/jobsdb"
@@ -1400,6 +1401,22 @@ func (gateway *HandleT) StartWebHandler(ctx context.Context) error {
 	return kithttputil.ListenAndServe(ctx, gateway.httpWebServer)
 }
 
+// StartStreamSources consumes the events of the Kafka and Pulsar stream sources configured in
+// Gateway.streamSources.sources and stores them like the events received by the web handlers.
+// This function will block, returning immediately if no stream sources are configured.
+func (gateway *HandleT) StartStreamSources(ctx context.Context) error {
+	sources, err := streamsource.LoadConfig(config.Default)
+	if err != nil {
+		return err
+	}
+	if len(sources) == 0 {
+		return nil
+	}
+	gateway.logger.Infof("StreamSources waiting for BackendConfig before starting %d sources", len(sources))
+	gateway.backendConfig.WaitForConfig(ctx)
+	return streamsource.New(gateway, gateway.logger, gateway.stats).Run(ctx, sources)
+}
+
 // StartAdminHandler for Admin Operations
 func (gateway *HandleT) StartAdminHandler(ctx context.Context) error {
 	gateway.logger.Infof("AdminHandler waiting for BackendConfig before starting on %d", adminWebPort)
@@ -1476,6 +1493,40 @@ func (gateway *HandleT) addToWebRequestQ(_ *http.ResponseWriter, req *http.Reque
 	userWebRequestWorker.webRequestQ <- &webReq
 }
 
+// ProcessStreamEvent stores an event consumed by the stream sources, going through the same
+// validation, rate limiting and batching as the events of the batch endpoint.
+// It returns an empty string on success, or the error message otherwise.
+func (gateway *HandleT) ProcessStreamEvent(event []byte, writeKey string) string {
+	atomic.AddUint64(&gateway.recvCount, 1)
+	payload, err := sjson.SetRawBytes(BatchEvent, "batch.0", event)
+	if err != nil {
+		errorMessage := response.NotRudderEvent
+		atomic.AddUint64(&gateway.ackCount, 1)
+		gateway.trackRequestMetrics(errorMessage)
+		return errorMessage
+	}
+	userIDHeader := strings.TrimSpace(gjson.GetBytes(event, "anonymousId").String())
+	workerKey := userIDHeader
+	if workerKey == "" {
+		workerKey = strings.TrimSpace(gjson.GetBytes(event, "userId").String())
+	}
+	if workerKey == "" {
+		workerKey = uuid.New().String()
+	}
+	done := make(chan string, 1)
+	gateway.findUserWebRequestWorker(workerKey).webRequestQ <- &webRequestT{
+		done:           done,
+		reqType:        "batch",
+		requestPayload: payload,
+		writeKey:       writeKey,
+		userIDHeader:   userIDHeader,
+	}
+	errorMessage := <-done
+	atomic.AddUint64(&gateway.ackCount, 1)
+	gateway.trackRequestMetrics(errorMessage)
+	return errorMessage
+}
+
 // IncrementRecvCount increments the received count for gateway requests
 func (gateway *HandleT) IncrementRecvCount(count uint64) {
 	atomic.AddUint64(&gateway.recvCount, count)
@@ -1299,6 +1299,64 @@ var _ = Describe("Gateway", func() {
 			Expect(err).To(BeNil())
 		})
 	})
+
+	Context("ProcessStreamEvent", func() {
+		var gateway *HandleT
+		BeforeEach(func() {
+			gateway = &HandleT{}
+			err := gateway.Setup(context.Background(), c.mockApp, c.mockBackendConfig, c.mockJobsDB, c.mockErrJobsDB, nil, c.mockVersionHandler, rsources.NewNoOpService(), sourcedebugger.NewNoOpService())
+			Expect(err).To(BeNil())
+		})
+
+		AfterEach(func() {
+			err := gateway.Shutdown()
+			Expect(err).To(BeNil())
+		})
+
+		It("should store the event to jobsdb keeping its type", func() {
+			c.mockJobsDB.EXPECT().WithStoreSafeTx(gomock.Any(), gomock.Any()).Times(1).Do(func(ctx context.Context, f func(tx jobsdb.StoreSafeTx) error) {
+				_ = f(jobsdb.EmptyStoreSafeTx())
+			}).Return(nil)
+			c.mockJobsDB.
+				EXPECT().StoreEachBatchRetryInTx(gomock.Any(), gomock.Any(), gomock.Any()).
+				DoAndReturn(func(ctx context.Context, tx jobsdb.StoreSafeTx, jobBatches [][]*jobsdb.JobT) (map[uuid.UUID]string, error) {
+					Expect(jobBatches).To(HaveLen(1))
+					Expect(jobBatches[0]).To(HaveLen(1))
+					job := jobBatches[0][0]
+					Expect(job.UserID).To(Equal("anon-1<<>>anon-1<<>>user-1"))
+					Expect(gjson.GetBytes(job.EventPayload, "writeKey").String()).To(Equal(WriteKeyEnabled))
+					payload := gjson.GetBytes(job.EventPayload, "batch.0")
+					Expect(payload.Get("type").String()).To(Equal("identify"))
+					Expect(payload.Get("messageId").String()).To(Equal("topic-0-1"))
+					return jobsToEmptyErrors(ctx, tx, jobBatches)
+				}).
+				Times(1)
+
+			errorMessage := gateway.ProcessStreamEvent(
+				[]byte(`{"type":"identify","anonymousId":"anon-1","userId":"user-1","messageId":"topic-0-1"}`),
+				WriteKeyEnabled,
+			)
+			Expect(errorMessage).To(BeEmpty())
+		})
+
+		It("should return the error message if the event is invalid", func() {
+			errorMessage := gateway.ProcessStreamEvent([]byte(`{"type":"track"}`), WriteKeyEnabled)
+			Expect(errorMessage).To(Equal(response.NonIdentifiableRequest))
+		})
+
+		It("should return the error message if jobsdb store fails", func() {
+			c.mockJobsDB.EXPECT().WithStoreSafeTx(gomock.Any(), gomock.Any()).Times(1).Do(func(ctx context.Context, f func(tx jobsdb.StoreSafeTx) error) {
+				_ = f(jobsdb.EmptyStoreSafeTx())
+			}).Return(nil)
+			c.mockJobsDB.
+				EXPECT().StoreEachBatchRetryInTx(gomock.Any(), gomock.Any(), gomock.Any()).
+				DoAndReturn(jobsToJobsdbErrors).
+				Times(1)
+
+			errorMessage := gateway.ProcessStreamEvent([]byte(`{"type":"track","userId":"user-1"}`), WriteKeyEnabled)
+			Expect(errorMessage).To(Equal("tx error"))
+		})
+	})
 })
 
 func unauthorizedRequest(body io.Reader) *http.Request {
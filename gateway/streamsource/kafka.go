@@ -0,0 +1,104 @@
+package streamsource
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+
+	"github.com/rudderlabs/rudder-go-kit/logger"
+	"github.com/rudderlabs/rudder-server/services/streammanager/kafka/client"
+)
+
+// KafkaConfig is the configuration of the kafka brokers of a stream source
+type KafkaConfig struct {
+	Brokers []string `json:"brokers"`
+	// StartFromLatest starts consuming from the latest offset when the consumer group has no committed offsets
+	StartFromLatest bool `json:"startFromLatest"`
+	UseTLS          bool `json:"useTLS"`
+	// SASL is optional, Mechanism being one of plain, sha256 and sha512
+	SASL *struct {
+		Mechanism string `json:"mechanism"`
+		Username  string `json:"username"`
+		Password  string `json:"password"`
+	} `json:"sasl"`
+}
+
+type kafkaConsumer struct {
+	consumer *client.Consumer
+}
+
+func newKafkaConsumer(conf Config, topic string) (*kafkaConsumer, error) {
+	clientConf := client.Config{ClientID: "rudder-server-" + conf.ConsumerGroup}
+	if conf.Kafka.UseTLS {
+		clientConf.TLS = &client.TLS{WithSystemCertPool: true}
+	}
+	if conf.Kafka.SASL != nil {
+		hashGen, err := client.ScramHashGeneratorFromString(conf.Kafka.SASL.Mechanism)
+		if err != nil {
+			return nil, err
+		}
+		clientConf.SASL = &client.SASL{
+			ScramHashGen: hashGen,
+			Username:     conf.Kafka.SASL.Username,
+			Password:     conf.Kafka.SASL.Password,
+		}
+	}
+	c, err := client.New("tcp", conf.Kafka.Brokers, clientConf)
+	if err != nil {
+		return nil, fmt.Errorf("creating kafka client: %w", err)
+	}
+
+	startOffset := client.FirstOffset
+	if conf.Kafka.StartFromLatest {
+		startOffset = client.LastOffset
+	}
+	log := logger.NewLogger().Child("gateway").Child("streamsource").Child("kafka")
+	return &kafkaConsumer{
+		consumer: c.NewConsumer(topic, client.ConsumerConfig{
+			GroupID:     conf.ConsumerGroup,
+			StartOffset: startOffset,
+			Logger:      &client.KafkaLogger{Logger: log},
+			ErrorLogger: &client.KafkaLogger{Logger: log, IsErrorLogger: true},
+		}),
+	}, nil
+}
+
+func (c *kafkaConsumer) Fetch(ctx context.Context) (Record, error) {
+	msg, err := c.consumer.Fetch(ctx)
+	if err != nil {
+		return Record{}, err
+	}
+	var headers map[string]string
+	if len(msg.Headers) > 0 {
+		headers = make(map[string]string, len(msg.Headers))
+		for _, h := range msg.Headers {
+			headers[h.Key] = string(h.Value)
+		}
+	}
+	return Record{
+		ID:        msg.Topic + "-" + strconv.Itoa(int(msg.Partition)) + "-" + strconv.FormatInt(msg.Offset, 10),
+		Topic:     msg.Topic,
+		Key:       msg.Key,
+		Value:     msg.Value,
+		Headers:   headers,
+		Timestamp: msg.Timestamp,
+		msg:       msg,
+	}, nil
+}
+
+func (c *kafkaConsumer) Commit(ctx context.Context, records ...Record) error {
+	msgs := make([]client.Message, len(records))
+	for i := range records {
+		msg, ok := records[i].msg.(client.Message)
+		if !ok {
+			return errors.New("record was not fetched from kafka")
+		}
+		msgs[i] = msg
+	}
+	return c.consumer.Commit(ctx, msgs...)
+}
+
+func (c *kafkaConsumer) Close(ctx context.Context) error {
+	return c.consumer.Close(ctx)
+}
@@ -0,0 +1,138 @@
+package streamsource
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/tidwall/gjson"
+	"github.com/tidwall/sjson"
+
+	"github.com/rudderlabs/rudder-server/utils/misc"
+)
+
+const defaultEventType = "track"
+
+// Mapping maps the records of a stream to rudderstack events
+type Mapping struct {
+	// Type is the type of the events which don't have one after being mapped, defaults to track
+	Type string `json:"type"`
+	// Fields maps the fields of the events to paths of the records, which are one of:
+	//  - key: the key of the record
+	//  - value: the JSON value of the record, or value.<path> for one of its fields
+	//  - headers.<name>: a header of the record
+	//  - topic: the topic of the record
+	//  - timestamp: the timestamp of the record
+	// Without fields, the values of the records are expected to be rudderstack events.
+	Fields map[string]string `json:"fields"`
+}
+
+func (m *Mapping) validate() error {
+	for field, path := range m.Fields {
+		if field == "" {
+			return errors.New("mapping field cannot be empty")
+		}
+		switch {
+		case path == "key", path == "value", path == "topic", path == "timestamp":
+		case strings.HasPrefix(path, "value.") && len(path) > len("value."):
+		case strings.HasPrefix(path, "headers.") && len(path) > len("headers."):
+		default:
+			return fmt.Errorf("invalid record path %q for field %q", path, field)
+		}
+	}
+	return nil
+}
+
+// event returns the rudderstack event of the record. Unless mapped, the messageId of the event is the ID of the record,
+// so that the events of records consumed more than once can be deduplicated.
+func (m *Mapping) event(r Record) ([]byte, error) {
+	var event []byte
+	if len(m.Fields) == 0 {
+		if !gjson.ValidBytes(r.Value) || !gjson.ParseBytes(r.Value).IsObject() {
+			return nil, errors.New("record value is not a JSON object")
+		}
+		event = r.Value
+	} else {
+		event = []byte(`{}`)
+		// setting the fields in order, so that nested fields can override the ones of their parents deterministically
+		fields := make([]string, 0, len(m.Fields))
+		for field := range m.Fields {
+			fields = append(fields, field)
+		}
+		sort.Strings(fields)
+		for _, field := range fields {
+			value, ok, err := m.recordValue(r, m.Fields[field])
+			if err != nil {
+				return nil, fmt.Errorf("mapping field %q: %w", field, err)
+			}
+			if !ok {
+				continue
+			}
+			if event, err = sjson.SetRawBytes(event, field, value); err != nil {
+				return nil, fmt.Errorf("mapping field %q: %w", field, err)
+			}
+		}
+	}
+
+	var err error
+	if !gjson.GetBytes(event, "type").Exists() {
+		eventType := m.Type
+		if eventType == "" {
+			eventType = defaultEventType
+		}
+		if event, err = sjson.SetBytes(event, "type", eventType); err != nil {
+			return nil, err
+		}
+	}
+	if gjson.GetBytes(event, "messageId").String() == "" && r.ID != "" {
+		if event, err = sjson.SetBytes(event, "messageId", r.ID); err != nil {
+			return nil, err
+		}
+	}
+	return event, nil
+}
+
+// recordValue returns the raw JSON value of the record path, if it exists
+func (*Mapping) recordValue(r Record, path string) ([]byte, bool, error) {
+	jsonString := func(s string) ([]byte, bool, error) {
+		b, err := json.Marshal(s)
+		return b, err == nil, err
+	}
+	switch {
+	case path == "key":
+		if len(r.Key) == 0 {
+			return nil, false, nil
+		}
+		return jsonString(string(r.Key))
+	case path == "topic":
+		return jsonString(r.Topic)
+	case path == "timestamp":
+		if r.Timestamp.IsZero() {
+			return nil, false, nil
+		}
+		return jsonString(r.Timestamp.UTC().Format(misc.RFC3339Milli))
+	case path == "value":
+		if !gjson.ValidBytes(r.Value) {
+			return nil, false, errors.New("record value is not valid JSON")
+		}
+		return r.Value, true, nil
+	case strings.HasPrefix(path, "value."):
+		if !gjson.ValidBytes(r.Value) {
+			return nil, false, errors.New("record value is not valid JSON")
+		}
+		result := gjson.GetBytes(r.Value, strings.TrimPrefix(path, "value."))
+		if !result.Exists() {
+			return nil, false, nil
+		}
+		return []byte(result.Raw), true, nil
+	case strings.HasPrefix(path, "headers."):
+		value, ok := r.Headers[strings.TrimPrefix(path, "headers.")]
+		if !ok {
+			return nil, false, nil
+		}
+		return jsonString(value)
+	}
+	return nil, false, fmt.Errorf("invalid record path %q", path)
+}
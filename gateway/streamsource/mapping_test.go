@@ -0,0 +1,95 @@
+package streamsource
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestMapping(t *testing.T) {
+	record := Record{
+		ID:        "events-0-42",
+		Topic:     "events",
+		Key:       []byte("user-1"),
+		Value:     []byte(`{"name":"Order Completed","props":{"total":10.5},"user":{"id":"user-1"}}`),
+		Headers:   map[string]string{"anonymousId": "anon-1"},
+		Timestamp: time.Date(2023, 5, 1, 10, 0, 0, 123000000, time.UTC),
+	}
+
+	t.Run("passthrough", func(t *testing.T) {
+		m := Mapping{}
+		event, err := m.event(Record{ID: "events-0-1", Value: []byte(`{"type":"identify","userId":"user-1"}`)})
+		require.NoError(t, err)
+		require.JSONEq(t, `{"type":"identify","userId":"user-1","messageId":"events-0-1"}`, string(event))
+	})
+
+	t.Run("passthrough with default type and messageId", func(t *testing.T) {
+		m := Mapping{Type: "page"}
+		event, err := m.event(Record{ID: "events-0-1", Value: []byte(`{"userId":"user-1","messageId":"msg-1"}`)})
+		require.NoError(t, err)
+		require.JSONEq(t, `{"type":"page","userId":"user-1","messageId":"msg-1"}`, string(event))
+	})
+
+	t.Run("passthrough of a non object value", func(t *testing.T) {
+		m := Mapping{}
+		_, err := m.event(Record{Value: []byte(`[1,2]`)})
+		require.Error(t, err)
+		_, err = m.event(Record{Value: []byte(`not json`)})
+		require.Error(t, err)
+	})
+
+	t.Run("fields", func(t *testing.T) {
+		m := Mapping{Fields: map[string]string{
+			"userId":                      "key",
+			"anonymousId":                 "headers.anonymousId",
+			"event":                       "value.name",
+			"properties":                  "value.props",
+			"properties.missing":          "value.missing",
+			"context.traits.id":           "value.user.id",
+			"context.kafka.topic":         "topic",
+			"originalTimestamp":           "timestamp",
+			"context.headers.notExisting": "headers.notExisting",
+		}}
+		require.NoError(t, m.validate())
+		event, err := m.event(record)
+		require.NoError(t, err)
+		require.JSONEq(t, `{
+			"type": "track",
+			"messageId": "events-0-42",
+			"userId": "user-1",
+			"anonymousId": "anon-1",
+			"event": "Order Completed",
+			"properties": {"total": 10.5},
+			"context": {"traits": {"id": "user-1"}, "kafka": {"topic": "events"}},
+			"originalTimestamp": "2023-05-01T10:00:00.123Z"
+		}`, string(event))
+	})
+
+	t.Run("fields with the whole value", func(t *testing.T) {
+		m := Mapping{Type: "identify", Fields: map[string]string{"traits": "value", "userId": "key"}}
+		event, err := m.event(record)
+		require.NoError(t, err)
+		require.JSONEq(t, `{
+			"type": "identify",
+			"messageId": "events-0-42",
+			"userId": "user-1",
+			"traits": {"name":"Order Completed","props":{"total":10.5},"user":{"id":"user-1"}}
+		}`, string(event))
+	})
+
+	t.Run("fields of an invalid value", func(t *testing.T) {
+		m := Mapping{Fields: map[string]string{"event": "value.name"}}
+		_, err := m.event(Record{Value: []byte(`not json`)})
+		require.ErrorContains(t, err, `mapping field "event"`)
+	})
+
+	t.Run("validate", func(t *testing.T) {
+		for _, path := range []string{"value.", "headers.", "keys", "partition", ""} {
+			m := Mapping{Fields: map[string]string{"event": path}}
+			require.Error(t, m.validate(), path)
+		}
+		m := Mapping{Fields: map[string]string{"": "key"}}
+		require.Error(t, m.validate())
+	})
+}
@@ -0,0 +1,73 @@
+package streamsource
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/apache/pulsar-client-go/pulsar"
+
+	"github.com/rudderlabs/rudder-go-kit/config"
+	pulsarClient "github.com/rudderlabs/rudder-server/internal/pulsar"
+)
+
+type pulsarConsumer struct {
+	client   pulsarClient.Client
+	consumer pulsarClient.ConsumerAdapter
+}
+
+// newPulsarConsumer subscribes to the topic with a key shared subscription named after the consumer group,
+// so that the topic can be consumed by multiple instances while the records of the same key are consumed in order
+func newPulsarConsumer(conf Config, topic string) (*pulsarConsumer, error) {
+	c, err := pulsarClient.NewClient(config.Default)
+	if err != nil {
+		return nil, err
+	}
+	consumer, err := c.NewConsumer(pulsar.ConsumerOptions{
+		Topic:            topic,
+		SubscriptionName: conf.ConsumerGroup,
+		Type:             pulsar.KeyShared,
+	})
+	if err != nil {
+		c.Close()
+		return nil, fmt.Errorf("subscribing to pulsar topic: %w", err)
+	}
+	return &pulsarConsumer{client: c, consumer: consumer}, nil
+}
+
+func (c *pulsarConsumer) Fetch(ctx context.Context) (Record, error) {
+	msg, err := c.consumer.Receive(ctx)
+	if err != nil {
+		return Record{}, err
+	}
+	return Record{
+		ID:        msg.ID().String(),
+		Topic:     msg.Topic(),
+		Key:       []byte(msg.Key()),
+		Value:     msg.Payload(),
+		Headers:   msg.Properties(),
+		Timestamp: msg.PublishTime(),
+		msg:       msg,
+	}, nil
+}
+
+// Commit acknowledges the records one by one, since pulsar key shared subscriptions don't support cumulative acks
+func (c *pulsarConsumer) Commit(_ context.Context, records ...Record) error {
+	var errs []error
+	for i := range records {
+		msg, ok := records[i].msg.(pulsar.Message)
+		if !ok {
+			return errors.New("record was not received from pulsar")
+		}
+		if err := c.consumer.Ack(msg); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func (c *pulsarConsumer) Close(_ context.Context) error {
+	c.consumer.Close()
+	c.client.Close()
+	return nil
+}
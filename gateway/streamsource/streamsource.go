@@ -0,0 +1,377 @@
+// Package streamsource consumes events from Kafka and Pulsar topics and stores them in the gateway jobsdb,
+// exactly like the events received by the HTTP gateway.
+//
+// Records are consumed in batches and mapped to rudderstack events through the Mapping of their source.
+// The offsets of a batch are committed only after all of its events have been stored, or dropped if they can
+// never be stored (e.g. invalid events), so that records are consumed at least once.
+package streamsource
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/samber/lo"
+	"github.com/tidwall/gjson"
+	"golang.org/x/sync/errgroup"
+
+	"github.com/rudderlabs/rudder-go-kit/config"
+	"github.com/rudderlabs/rudder-go-kit/logger"
+	"github.com/rudderlabs/rudder-go-kit/stats"
+	"github.com/rudderlabs/rudder-server/gateway/response"
+	"github.com/rudderlabs/rudder-server/utils/misc"
+)
+
+const (
+	TypeKafka  = "kafka"
+	TypePulsar = "pulsar"
+)
+
+var (
+	batchSize       int
+	batchTimeout    time.Duration
+	minRetryBackoff time.Duration
+	maxRetryBackoff time.Duration
+	commitTimeout   time.Duration
+)
+
+func Init() {
+	loadConfig()
+}
+
+func loadConfig() {
+	config.RegisterIntConfigVariable(100, &batchSize, false, 1, "Gateway.streamSources.batchSize")
+	config.RegisterDurationConfigVariable(100, &batchTimeout, false, time.Millisecond, "Gateway.streamSources.batchTimeout")
+	config.RegisterDurationConfigVariable(100, &minRetryBackoff, true, time.Millisecond, "Gateway.streamSources.minRetryBackoff")
+	config.RegisterDurationConfigVariable(30, &maxRetryBackoff, true, time.Second, "Gateway.streamSources.maxRetryBackoff")
+	config.RegisterDurationConfigVariable(30, &commitTimeout, true, time.Second, "Gateway.streamSources.commitTimeout")
+}
+
+// Record is a record consumed from a stream
+type Record struct {
+	// ID uniquely identifies the record in the stream
+	ID        string
+	Topic     string
+	Key       []byte
+	Value     []byte
+	Headers   map[string]string
+	Timestamp time.Time
+
+	// msg is the underlying message of the consumer, used for committing the record
+	msg interface{}
+}
+
+// Consumer consumes the records of a topic
+type Consumer interface {
+	// Fetch returns the next record of the topic, blocking until one is available or the context is canceled
+	Fetch(ctx context.Context) (Record, error)
+	// Commit marks the records as consumed, so that they are not fetched again
+	Commit(ctx context.Context, records ...Record) error
+	Close(ctx context.Context) error
+}
+
+// Gateway stores the events of the stream sources
+type Gateway interface {
+	// ProcessStreamEvent stores the event for the source of the write key, returning an error message on failure
+	ProcessStreamEvent(event []byte, writeKey string) string
+}
+
+// Config is the configuration of a stream source
+type Config struct {
+	// Type of the stream, either kafka or pulsar
+	Type string `json:"type"`
+	// WriteKey of the source which the events are stored for
+	WriteKey string   `json:"writeKey"`
+	Topics   []string `json:"topics"`
+	// ConsumerGroup is the kafka consumer group, or the pulsar subscription, committing the offsets of the records
+	ConsumerGroup string  `json:"consumerGroup"`
+	Mapping       Mapping `json:"mapping"`
+
+	// Kafka is the configuration of the kafka brokers, pulsar sources use the Pulsar.Client configuration
+	Kafka KafkaConfig `json:"kafka"`
+}
+
+func (c *Config) validate() error {
+	switch c.Type {
+	case TypeKafka:
+		if len(c.Kafka.Brokers) == 0 {
+			return errors.New("kafka brokers cannot be empty")
+		}
+	case TypePulsar:
+	default:
+		return fmt.Errorf("unknown stream source type %q", c.Type)
+	}
+	if c.WriteKey == "" {
+		return errors.New("write key cannot be empty")
+	}
+	if len(c.Topics) == 0 {
+		return errors.New("topics cannot be empty")
+	}
+	if c.ConsumerGroup == "" {
+		return errors.New("consumer group cannot be empty")
+	}
+	return c.Mapping.validate()
+}
+
+// LoadConfig returns the stream sources of the Gateway.streamSources.sources configuration, a JSON array of Config
+func LoadConfig(c *config.Config) ([]Config, error) {
+	raw := c.GetString("Gateway.streamSources.sources", "")
+	if raw == "" {
+		return nil, nil
+	}
+	var sources []Config
+	if err := json.Unmarshal([]byte(raw), &sources); err != nil {
+		return nil, fmt.Errorf("unmarshalling stream sources: %w", err)
+	}
+	for i := range sources {
+		if err := sources[i].validate(); err != nil {
+			return nil, fmt.Errorf("invalid stream source at index %d: %w", i, err)
+		}
+	}
+	return sources, nil
+}
+
+// Handle consumes the stream sources
+type Handle struct {
+	gateway     Gateway
+	logger      logger.Logger
+	stats       stats.Stats
+	newConsumer func(conf Config, topic string) (Consumer, error)
+}
+
+func New(gateway Gateway, log logger.Logger, stat stats.Stats) *Handle {
+	return &Handle{
+		gateway:     gateway,
+		logger:      log.Child("streamsource"),
+		stats:       stat,
+		newConsumer: newConsumer,
+	}
+}
+
+func newConsumer(conf Config, topic string) (Consumer, error) {
+	if conf.Type == TypePulsar {
+		return newPulsarConsumer(conf, topic)
+	}
+	return newKafkaConsumer(conf, topic)
+}
+
+// Run consumes the topics of the sources until the context is canceled.
+// Consumers which can't be created, e.g. while the stream is unavailable, are retried with a backoff instead of failing,
+// so that an unavailable stream doesn't stop the gateway.
+func (h *Handle) Run(ctx context.Context, sources []Config) error {
+	g, ctx := errgroup.WithContext(ctx)
+	for _, source := range sources {
+		for _, topic := range source.Topics {
+			source, topic := source, topic
+			g.Go(misc.WithBugsnag(func() error {
+				consumer, ok := h.connect(ctx, source, topic)
+				if !ok {
+					return nil
+				}
+				defer func() {
+					closeCtx, cancel := context.WithTimeout(context.Background(), commitTimeout)
+					defer cancel()
+					if err := consumer.Close(closeCtx); err != nil {
+						h.logger.Warnf("Closing %s consumer for topic %q: %v", source.Type, topic, err)
+					}
+				}()
+				h.consume(ctx, source, topic, consumer)
+				return nil
+			}))
+		}
+	}
+	return g.Wait()
+}
+
+// connect creates the consumer of the topic, retrying with a backoff until it gets created or the context is canceled
+func (h *Handle) connect(ctx context.Context, source Config, topic string) (Consumer, bool) {
+	for attempt := 0; ; attempt++ {
+		consumer, err := h.newConsumer(source, topic)
+		if err == nil {
+			return consumer, true
+		}
+		h.stats.NewTaggedStat("gateway.stream_source_connect_errors", stats.CountType, h.tags(source, topic)).Increment()
+		h.logger.Errorf("Creating %s consumer for topic %q, retrying: %v", source.Type, topic, err)
+		if !sleep(ctx, backoff(attempt)) {
+			return nil, false
+		}
+	}
+}
+
+// consume fetches the records of the topic and processes them in batches until the context is canceled
+func (h *Handle) consume(ctx context.Context, source Config, topic string, consumer Consumer) {
+	h.logger.Infof("Consuming %s topic %q for source with write key %q", source.Type, topic, source.WriteKey)
+	records := make(chan Record)
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		defer close(records)
+		h.fetch(ctx, source, topic, consumer, records)
+	}()
+	defer wg.Wait()
+
+	for {
+		batch, n, _, ok := lo.BufferWithTimeout(records, batchSize, batchTimeout)
+		if n > 0 {
+			if !h.process(ctx, source, batch) {
+				// the context was canceled before the batch was stored, its records will be consumed again
+				return
+			}
+			h.commit(source, topic, consumer, batch)
+		}
+		if !ok {
+			return
+		}
+	}
+}
+
+// fetch sends the records of the consumer to the channel, retrying on errors until the context is canceled
+func (h *Handle) fetch(ctx context.Context, source Config, topic string, consumer Consumer, records chan<- Record) {
+	for attempt := 0; ; {
+		record, err := consumer.Fetch(ctx)
+		if ctx.Err() != nil {
+			return
+		}
+		if err != nil {
+			h.stats.NewTaggedStat("gateway.stream_source_fetch_errors", stats.CountType, h.tags(source, topic)).Increment()
+			h.logger.Errorf("Fetching from %s topic %q: %v", source.Type, topic, err)
+			if !sleep(ctx, backoff(attempt)) {
+				return
+			}
+			attempt++
+			continue
+		}
+		attempt = 0
+		select {
+		case records <- record:
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// process stores the events of the records, returning false if the context was canceled before all of them were
+// either stored or dropped. The events of the same user are stored in the order of their records.
+func (h *Handle) process(ctx context.Context, source Config, batch []Record) bool {
+	type userEvent struct {
+		topic string
+		event []byte
+	}
+	var (
+		users      []string
+		userEvents = make(map[string][]userEvent)
+	)
+	for _, record := range batch {
+		event, err := source.Mapping.event(record)
+		if err != nil {
+			h.stats.NewTaggedStat("gateway.stream_source_records", stats.CountType, h.statusTags(source, record.Topic, "invalid")).Increment()
+			h.logger.Errorf("Dropping record %q of %s topic %q: %v", record.ID, source.Type, record.Topic, err)
+			continue
+		}
+		user := gjson.GetBytes(event, "anonymousId").String()
+		if user == "" {
+			user = gjson.GetBytes(event, "userId").String()
+		}
+		if _, ok := userEvents[user]; !ok {
+			users = append(users, user)
+		}
+		userEvents[user] = append(userEvents[user], userEvent{topic: record.Topic, event: event})
+	}
+
+	var (
+		wg     sync.WaitGroup
+		mu     sync.Mutex
+		stored = true
+	)
+	for _, user := range users {
+		events := userEvents[user]
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for _, e := range events {
+				if !h.store(ctx, source, e.topic, e.event) {
+					mu.Lock()
+					stored = false
+					mu.Unlock()
+					return
+				}
+			}
+		}()
+	}
+	wg.Wait()
+	return stored
+}
+
+// store stores the event through the gateway, retrying with a backoff as long as the failure is transient.
+// Events which can never be stored are dropped.
+func (h *Handle) store(ctx context.Context, source Config, topic string, event []byte) bool {
+	for attempt := 0; ; attempt++ {
+		errorMessage := h.gateway.ProcessStreamEvent(event, source.WriteKey)
+		if errorMessage == "" {
+			h.stats.NewTaggedStat("gateway.stream_source_records", stats.CountType, h.statusTags(source, topic, "stored")).Increment()
+			return true
+		}
+		statusCode := response.GetErrorStatusCode(errorMessage)
+		if statusCode != http.StatusTooManyRequests && statusCode < http.StatusInternalServerError {
+			h.stats.NewTaggedStat("gateway.stream_source_records", stats.CountType, h.statusTags(source, topic, "dropped")).Increment()
+			h.logger.Errorf("Dropping event of %s topic %q: %s", source.Type, topic, errorMessage)
+			return true
+		}
+		h.stats.NewTaggedStat("gateway.stream_source_retries", stats.CountType, h.tags(source, topic)).Increment()
+		h.logger.Warnf("Storing event of %s topic %q failed, retrying: %s", source.Type, topic, errorMessage)
+		if !sleep(ctx, backoff(attempt)) {
+			return false
+		}
+	}
+}
+
+// commit commits the records of the batch. A failed commit is not retried, since the records of the next batch
+// commit the offsets of the previous ones as well. Otherwise, the records are consumed again on restart.
+func (h *Handle) commit(source Config, topic string, consumer Consumer, batch []Record) {
+	// committing even if the context was canceled after the batch was stored
+	ctx, cancel := context.WithTimeout(context.Background(), commitTimeout)
+	defer cancel()
+	if err := consumer.Commit(ctx, batch...); err != nil {
+		h.stats.NewTaggedStat("gateway.stream_source_commit_errors", stats.CountType, h.tags(source, topic)).Increment()
+		h.logger.Errorf("Committing %d records of %s topic %q: %v", len(batch), source.Type, topic, err)
+	}
+}
+
+func (*Handle) tags(source Config, topic string) stats.Tags {
+	return stats.Tags{"type": source.Type, "topic": topic, "writeKey": source.WriteKey}
+}
+
+func (h *Handle) statusTags(source Config, topic, status string) stats.Tags {
+	tags := h.tags(source, topic)
+	tags["status"] = status
+	return tags
+}
+
+// backoff returns the exponential backoff of the attempt, capped to the max retry backoff
+func backoff(attempt int) time.Duration {
+	d := minRetryBackoff
+	for i := 0; i < attempt && d < maxRetryBackoff; i++ {
+		d *= 2
+	}
+	if d > maxRetryBackoff {
+		d = maxRetryBackoff
+	}
+	return d
+}
+
+// sleep waits for the duration, returning false if the context was canceled
+func sleep(ctx context.Context, d time.Duration) bool {
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-t.C:
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
@@ -0,0 +1,275 @@
+package streamsource
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/tidwall/gjson"
+
+	"github.com/rudderlabs/rudder-go-kit/config"
+	"github.com/rudderlabs/rudder-go-kit/logger"
+	"github.com/rudderlabs/rudder-go-kit/stats/memstats"
+	"github.com/rudderlabs/rudder-server/gateway/response"
+)
+
+func init() {
+	batchSize = 10
+	batchTimeout = 10 * time.Millisecond
+	minRetryBackoff = time.Millisecond
+	maxRetryBackoff = 10 * time.Millisecond
+	commitTimeout = time.Second
+}
+
+// mockStream records the calls of both the consumer and the gateway, so that their order can be asserted
+type mockStream struct {
+	records chan Record
+
+	mu        sync.Mutex
+	calls     []string
+	committed []string
+	closed    bool
+	// storeResponses are the error messages returned by the gateway for the events by messageId, in order
+	storeResponses map[string][]string
+	fetchErrors    int
+	connectErrors  int
+}
+
+func newMockStream(records ...Record) *mockStream {
+	m := &mockStream{records: make(chan Record, len(records)), storeResponses: make(map[string][]string)}
+	for _, r := range records {
+		m.records <- r
+	}
+	return m
+}
+
+func (m *mockStream) Fetch(ctx context.Context) (Record, error) {
+	m.mu.Lock()
+	if m.fetchErrors > 0 {
+		m.fetchErrors--
+		m.mu.Unlock()
+		return Record{}, errors.New("fetch error")
+	}
+	m.mu.Unlock()
+	select {
+	case r := <-m.records:
+		return r, nil
+	case <-ctx.Done():
+		return Record{}, ctx.Err()
+	}
+}
+
+func (m *mockStream) Commit(_ context.Context, records ...Record) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, r := range records {
+		m.calls = append(m.calls, "commit:"+r.ID)
+		m.committed = append(m.committed, r.ID)
+	}
+	return nil
+}
+
+func (m *mockStream) Close(context.Context) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.closed = true
+	return nil
+}
+
+func (m *mockStream) ProcessStreamEvent(event []byte, writeKey string) string {
+	if writeKey != "write-key" {
+		return response.InvalidWriteKey
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	id := gjson.GetBytes(event, "messageId").String()
+	var errorMessage string
+	if responses := m.storeResponses[id]; len(responses) > 0 {
+		errorMessage, m.storeResponses[id] = responses[0], responses[1:]
+	}
+	m.calls = append(m.calls, fmt.Sprintf("store:%s:%q", id, errorMessage))
+	return errorMessage
+}
+
+func (m *mockStream) getCommitted() []string {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return append([]string{}, m.committed...)
+}
+
+func (m *mockStream) getCalls() []string {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return append([]string{}, m.calls...)
+}
+
+func record(id, value string) Record {
+	return Record{ID: id, Topic: "events", Value: []byte(value)}
+}
+
+func run(t *testing.T, m *mockStream, statsStore *memstats.Store) (cancel func()) {
+	t.Helper()
+	h := New(m, logger.NOP, statsStore)
+	h.newConsumer = func(conf Config, topic string) (Consumer, error) {
+		require.Equal(t, "events", topic)
+		m.mu.Lock()
+		defer m.mu.Unlock()
+		if m.connectErrors > 0 {
+			m.connectErrors--
+			return nil, errors.New("connect error")
+		}
+		return m, nil
+	}
+	ctx, cancelCtx := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() {
+		done <- h.Run(ctx, []Config{{Type: TypeKafka, WriteKey: "write-key", Topics: []string{"events"}, ConsumerGroup: "group"}})
+	}()
+	return func() {
+		cancelCtx()
+		require.NoError(t, <-done)
+		m.mu.Lock()
+		defer m.mu.Unlock()
+		require.True(t, m.closed)
+	}
+}
+
+func TestHandle(t *testing.T) {
+	tags := func(status string) map[string]string {
+		return map[string]string{"type": TypeKafka, "topic": "events", "writeKey": "write-key", "status": status}
+	}
+
+	t.Run("commits the records after storing their events", func(t *testing.T) {
+		m := newMockStream(
+			record("events-0-1", `{"userId":"user-1"}`),
+			record("events-0-2", `{"userId":"user-1"}`),
+			record("events-0-3", `{"userId":"user-1"}`),
+		)
+		statsStore := memstats.New()
+		cancel := run(t, m, statsStore)
+		require.Eventually(t, func() bool { return len(m.getCommitted()) == 3 }, time.Second, time.Millisecond)
+		cancel()
+
+		// the events of the same user are stored in order, before committing
+		require.Equal(t, []string{
+			`store:events-0-1:""`, `store:events-0-2:""`, `store:events-0-3:""`,
+			"commit:events-0-1", "commit:events-0-2", "commit:events-0-3",
+		}, m.getCalls())
+		require.NotNil(t, statsStore.Get("gateway.stream_source_records", tags("stored")))
+	})
+
+	t.Run("retries transient failures before committing", func(t *testing.T) {
+		m := newMockStream(
+			record("events-0-1", `{"userId":"user-1"}`),
+			record("events-0-2", `{"userId":"user-2"}`),
+		)
+		m.storeResponses["events-0-1"] = []string{"tx error", response.TooManyRequests}
+		m.fetchErrors = 2
+		statsStore := memstats.New()
+		cancel := run(t, m, statsStore)
+		require.Eventually(t, func() bool { return len(m.getCommitted()) == 2 }, time.Second, time.Millisecond)
+		cancel()
+
+		calls := m.getCalls()
+		require.ElementsMatch(t, []string{
+			`store:events-0-1:"tx error"`, `store:events-0-1:"Max Requests Limit reached"`, `store:events-0-1:""`, `store:events-0-2:""`,
+		}, calls[:4])
+		require.ElementsMatch(t, []string{"commit:events-0-1", "commit:events-0-2"}, calls[4:])
+		require.NotNil(t, statsStore.Get("gateway.stream_source_retries", map[string]string{"type": TypeKafka, "topic": "events", "writeKey": "write-key"}))
+		require.NotNil(t, statsStore.Get("gateway.stream_source_fetch_errors", map[string]string{"type": TypeKafka, "topic": "events", "writeKey": "write-key"}))
+	})
+
+	t.Run("retries creating the consumer", func(t *testing.T) {
+		m := newMockStream(record("events-0-1", `{"userId":"user-1"}`))
+		m.connectErrors = 3
+		statsStore := memstats.New()
+		cancel := run(t, m, statsStore)
+		require.Eventually(t, func() bool { return len(m.getCommitted()) == 1 }, time.Second, time.Millisecond)
+		cancel()
+
+		require.NotNil(t, statsStore.Get("gateway.stream_source_connect_errors", map[string]string{"type": TypeKafka, "topic": "events", "writeKey": "write-key"}))
+		require.Zero(t, m.connectErrors, "the consumer should have been created after the failures")
+	})
+
+	t.Run("stops retrying to create the consumer when canceled", func(t *testing.T) {
+		h := New(newMockStream(), logger.NOP, memstats.New())
+		h.newConsumer = func(Config, string) (Consumer, error) { return nil, errors.New("connect error") }
+		ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+		defer cancel()
+		require.NoError(t, h.Run(ctx, []Config{{Type: TypePulsar, WriteKey: "write-key", Topics: []string{"events"}, ConsumerGroup: "group"}}))
+	})
+
+	t.Run("drops the records which can never be stored", func(t *testing.T) {
+		m := newMockStream(
+			record("events-0-1", `not json`),
+			record("events-0-2", `{"type":"track"}`),
+			record("events-0-3", `{"userId":"user-1"}`),
+		)
+		m.storeResponses["events-0-2"] = []string{response.NonIdentifiableRequest}
+		statsStore := memstats.New()
+		cancel := run(t, m, statsStore)
+		require.Eventually(t, func() bool { return len(m.getCommitted()) == 3 }, time.Second, time.Millisecond)
+		cancel()
+
+		require.EqualValues(t, 1, statsStore.Get("gateway.stream_source_records", tags("invalid")).LastValue())
+		require.EqualValues(t, 1, statsStore.Get("gateway.stream_source_records", tags("dropped")).LastValue())
+		require.EqualValues(t, 1, statsStore.Get("gateway.stream_source_records", tags("stored")).LastValue())
+	})
+
+	t.Run("doesn't commit the records which weren't stored before stopping", func(t *testing.T) {
+		m := newMockStream(record("events-0-1", `{"userId":"user-1"}`))
+		m.storeResponses["events-0-1"] = []string{"tx error", "tx error", "tx error"}
+		cancel := run(t, m, memstats.New())
+		require.Eventually(t, func() bool { return len(m.getCalls()) >= 2 }, time.Second, time.Millisecond)
+		cancel()
+
+		require.Empty(t, m.getCommitted())
+	})
+}
+
+func TestLoadConfig(t *testing.T) {
+	t.Run("no sources", func(t *testing.T) {
+		sources, err := LoadConfig(config.New())
+		require.NoError(t, err)
+		require.Empty(t, sources)
+	})
+
+	t.Run("valid sources", func(t *testing.T) {
+		c := config.New()
+		c.Set("Gateway.streamSources.sources", `[
+			{"type":"kafka","writeKey":"wk-1","topics":["t1","t2"],"consumerGroup":"g1","kafka":{"brokers":["localhost:9092"],"sasl":{"mechanism":"sha256","username":"u","password":"p"}},"mapping":{"fields":{"event":"value.name"}}},
+			{"type":"pulsar","writeKey":"wk-2","topics":["t3"],"consumerGroup":"g2"}
+		]`)
+		sources, err := LoadConfig(c)
+		require.NoError(t, err)
+		require.Len(t, sources, 2)
+		require.Equal(t, []string{"t1", "t2"}, sources[0].Topics)
+		require.Equal(t, []string{"localhost:9092"}, sources[0].Kafka.Brokers)
+		require.Equal(t, "sha256", sources[0].Kafka.SASL.Mechanism)
+		require.Equal(t, map[string]string{"event": "value.name"}, sources[0].Mapping.Fields)
+		require.Equal(t, TypePulsar, sources[1].Type)
+	})
+
+	t.Run("invalid sources", func(t *testing.T) {
+		c := config.New()
+		for _, source := range []string{
+			`{"type":"kinesis","writeKey":"wk","topics":["t"],"consumerGroup":"g"}`,
+			`{"type":"kafka","writeKey":"wk","topics":["t"],"consumerGroup":"g"}`,
+			`{"type":"pulsar","topics":["t"],"consumerGroup":"g"}`,
+			`{"type":"pulsar","writeKey":"wk","consumerGroup":"g"}`,
+			`{"type":"pulsar","writeKey":"wk","topics":["t"]}`,
+			`{"type":"pulsar","writeKey":"wk","topics":["t"],"consumerGroup":"g","mapping":{"fields":{"event":"partition"}}}`,
+		} {
+			c.Set("Gateway.streamSources.sources", "["+source+"]")
+			_, err := LoadConfig(c)
+			require.Error(t, err, source)
+		}
+		c.Set("Gateway.streamSources.sources", "{")
+		_, err := LoadConfig(c)
+		require.Error(t, err)
+	})
+}
@@ -30,6 +30,16 @@ type ProducerAdapter interface {
 	Flush() error
 }
 
+type Consumer struct {
+	pulsar.Consumer
+}
+
+type ConsumerAdapter interface {
+	Receive(ctx context.Context) (pulsar.Message, error)
+	Ack(msg pulsar.Message) error
+	Close()
+}
+
 type Client struct {
 	pulsar.Client
 }
@@ -55,6 +65,17 @@ func (c *Client) NewProducer(opts pulsar.ProducerOptions) (ProducerAdapter, erro
 	}, nil
 }
 
+// NewConsumer returns a new instance of Pulsar consumer
+func (c *Client) NewConsumer(opts pulsar.ConsumerOptions) (ConsumerAdapter, error) {
+	consumer, err := c.Subscribe(opts)
+	if err != nil {
+		return nil, err
+	}
+	return &Consumer{
+		consumer,
+	}, nil
+}
+
 func newPulsarClient(conf ClientConf, log logger.Logger) (Client, error) {
 	if conf.url == "" {
 		return Client{}, errors.New("pulsar url is empty")
@@ -70,6 +70,55 @@ func Test_Pulsar(t *testing.T) {
 	}
 }
 
+func Test_PulsarConsumer(t *testing.T) {
+	var (
+		topic            = "test-consumer-topic"
+		key              = "test-key"
+		subscriptionName = "test-consumer-subscription"
+	)
+	pulsarContainer := PulsarResource(t)
+
+	conf := config.New()
+	conf.Set("Pulsar.Client.url", pulsarContainer.URL)
+	client, err := NewClient(conf)
+	require.NoError(t, err)
+	defer client.Close()
+
+	producer, err := client.NewProducer(pulsar.ProducerOptions{Topic: topic})
+	require.NoError(t, err)
+	defer producer.Close()
+
+	newConsumer := func() ConsumerAdapter {
+		consumer, err := client.NewConsumer(pulsar.ConsumerOptions{
+			Topic:            topic,
+			SubscriptionName: subscriptionName,
+		})
+		require.NoError(t, err)
+		require.NotNil(t, consumer)
+		return consumer
+	}
+	consumer := newConsumer()
+
+	for i := 0; i < 2; i++ {
+		require.NoError(t, producer.SendMessage(context.Background(), key, "", []byte(fmt.Sprintf("test-message-%d", i))))
+	}
+
+	msg, err := consumer.Receive(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, []byte("test-message-0"), msg.Payload())
+	require.Equal(t, key, msg.Key())
+	require.NoError(t, consumer.Ack(msg))
+	consumer.Close()
+
+	// acknowledged messages are not redelivered to the subscription
+	consumer = newConsumer()
+	defer consumer.Close()
+	msg, err = consumer.Receive(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, []byte("test-message-1"), msg.Payload())
+	require.NoError(t, consumer.Ack(msg))
+}
+
 func Test_PulsarInterface(t *testing.T) {
 	topic := "test-topic"
 	pulsarContainer := PulsarResource(t)
@@ -37,6 +37,8 @@ import (
 	"github.com/rudderlabs/rudder-server/processor/stash"
 	"github.com/rudderlabs/rudder-server/processor/transformer"
 	"github.com/rudderlabs/rudder-server/router/batchrouter"
+	"github.com/rudderlabs/rudder-server/router/ordering"
+	"github.com/rudderlabs/rudder-server/router/priority"
 	"github.com/rudderlabs/rudder-server/rruntime"
 	destinationdebugger "github.com/rudderlabs/rudder-server/services/debugger/destination"
 	transformationdebugger "github.com/rudderlabs/rudder-server/services/debugger/transformation"
@@ -214,6 +216,8 @@ type ParametersT struct {
 	SourceCategory          string      `json:"source_category"`
 	RecordID                interface{} `json:"record_id"`
 	WorkspaceId             string      `json:"workspaceId"`
+	PriorityClass           string      `json:"priority_class,omitempty"`
+	OrderingKey             string      `json:"ordering_key,omitempty"`
 }
 
 type MetricMetadata struct {
@@ -2332,6 +2336,14 @@ func (proc *Handle) transformSrcDest(
 	}
 
 	trace.WithRegion(ctx, "MarshalForDB", func() {
+		priorityRules, err := priority.RulesFromDestinationConfig(destination.Config)
+		if err != nil {
+			proc.logger.Errorf("[Processor] Invalid priority classes for destination %s, ignoring them: %v", destination.ID, err)
+		}
+		orderingKey, err := ordering.FromDestinationConfig(destination.Config)
+		if err != nil {
+			proc.logger.Errorf("[Processor] Invalid event ordering key for destination %s, ordering events by user: %v", destination.ID, err)
+		}
 		// Save the JSON in DB. This is what the router uses
 		for i := range response.Events {
 			destEventJSON, err := jsonfast.Marshal(response.Events[i].Output)
@@ -2387,6 +2399,12 @@ func (proc *Handle) transformSrcDest(
 				RecordID:                recordId,
 				WorkspaceId:             workspaceId,
 			}
+			if priorityRules != nil {
+				params.PriorityClass = string(priorityRules.Classify(eventName, eventType))
+			}
+			if orderingKey != nil {
+				params.OrderingKey = orderingKey.Evaluate(eventsByMessageID[messageId].SingularEvent, id.String())
+			}
 			marshalledParams, err := jsonfast.Marshal(params)
 			if err != nil {
 				proc.logger.Errorf("[Processor] Failed to marshal parameters object. Parameters: %v", params)
@@ -33,6 +33,9 @@ type WarehouseClient interface {
 	RetryWHUploads(ctx context.Context, in *RetryWHUploadsRequest, opts ...grpc.CallOption) (*RetryWHUploadsResponse, error)
 	ValidateObjectStorageDestination(ctx context.Context, in *ValidateObjectStorageRequest, opts ...grpc.CallOption) (*ValidateObjectStorageResponse, error)
 	CountWHUploadsToRetry(ctx context.Context, in *RetryWHUploadsRequest, opts ...grpc.CallOption) (*RetryWHUploadsResponse, error)
+	PlanWHUpload(ctx context.Context, in *WHUploadRequest, opts ...grpc.CallOption) (*WHUploadPlanResponse, error)
+	CreateWHBackfill(ctx context.Context, in *WHBackfillRequest, opts ...grpc.CallOption) (*WHBackfillResponse, error)
+	GetWHBackfill(ctx context.Context, in *WHBackfillStatusRequest, opts ...grpc.CallOption) (*WHBackfillResponse, error)
 }
 
 type warehouseClient struct {
@@ -124,6 +127,33 @@ func (c *warehouseClient) CountWHUploadsToRetry(ctx context.Context, in *RetryWH
 	return out, nil
 }
 
+func (c *warehouseClient) PlanWHUpload(ctx context.Context, in *WHUploadRequest, opts ...grpc.CallOption) (*WHUploadPlanResponse, error) {
+	out := new(WHUploadPlanResponse)
+	err := c.cc.Invoke(ctx, "/proto.Warehouse/PlanWHUpload", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *warehouseClient) CreateWHBackfill(ctx context.Context, in *WHBackfillRequest, opts ...grpc.CallOption) (*WHBackfillResponse, error) {
+	out := new(WHBackfillResponse)
+	err := c.cc.Invoke(ctx, "/proto.Warehouse/CreateWHBackfill", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *warehouseClient) GetWHBackfill(ctx context.Context, in *WHBackfillStatusRequest, opts ...grpc.CallOption) (*WHBackfillResponse, error) {
+	out := new(WHBackfillResponse)
+	err := c.cc.Invoke(ctx, "/proto.Warehouse/GetWHBackfill", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // WarehouseServer is the server API for Warehouse service.
 // All implementations must embed UnimplementedWarehouseServer
 // for forward compatibility
@@ -137,6 +167,9 @@ type WarehouseServer interface {
 	RetryWHUploads(context.Context, *RetryWHUploadsRequest) (*RetryWHUploadsResponse, error)
 	ValidateObjectStorageDestination(context.Context, *ValidateObjectStorageRequest) (*ValidateObjectStorageResponse, error)
 	CountWHUploadsToRetry(context.Context, *RetryWHUploadsRequest) (*RetryWHUploadsResponse, error)
+	PlanWHUpload(context.Context, *WHUploadRequest) (*WHUploadPlanResponse, error)
+	CreateWHBackfill(context.Context, *WHBackfillRequest) (*WHBackfillResponse, error)
+	GetWHBackfill(context.Context, *WHBackfillStatusRequest) (*WHBackfillResponse, error)
 	mustEmbedUnimplementedWarehouseServer()
 }
 
@@ -171,6 +204,15 @@ func (UnimplementedWarehouseServer) ValidateObjectStorageDestination(context.Con
 func (UnimplementedWarehouseServer) CountWHUploadsToRetry(context.Context, *RetryWHUploadsRequest) (*RetryWHUploadsResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method CountWHUploadsToRetry not implemented")
 }
+func (UnimplementedWarehouseServer) PlanWHUpload(context.Context, *WHUploadRequest) (*WHUploadPlanResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method PlanWHUpload not implemented")
+}
+func (UnimplementedWarehouseServer) CreateWHBackfill(context.Context, *WHBackfillRequest) (*WHBackfillResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CreateWHBackfill not implemented")
+}
+func (UnimplementedWarehouseServer) GetWHBackfill(context.Context, *WHBackfillStatusRequest) (*WHBackfillResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetWHBackfill not implemented")
+}
 func (UnimplementedWarehouseServer) mustEmbedUnimplementedWarehouseServer() {}
 
 // UnsafeWarehouseServer may be embedded to opt out of forward compatibility for this service.
@@ -346,6 +388,60 @@ func _Warehouse_CountWHUploadsToRetry_Handler(srv interface{}, ctx context.Conte
 	return interceptor(ctx, in, info, handler)
 }
 
+func _Warehouse_PlanWHUpload_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(WHUploadRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(WarehouseServer).PlanWHUpload(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/proto.Warehouse/PlanWHUpload",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(WarehouseServer).PlanWHUpload(ctx, req.(*WHUploadRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Warehouse_CreateWHBackfill_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(WHBackfillRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(WarehouseServer).CreateWHBackfill(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/proto.Warehouse/CreateWHBackfill",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(WarehouseServer).CreateWHBackfill(ctx, req.(*WHBackfillRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Warehouse_GetWHBackfill_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(WHBackfillStatusRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(WarehouseServer).GetWHBackfill(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/proto.Warehouse/GetWHBackfill",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(WarehouseServer).GetWHBackfill(ctx, req.(*WHBackfillStatusRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 // Warehouse_ServiceDesc is the grpc.ServiceDesc for Warehouse service.
 // It's only intended for direct use with grpc.RegisterService,
 // and not to be introspected or modified (even as a copy)
@@ -389,6 +485,18 @@ var Warehouse_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "CountWHUploadsToRetry",
 			Handler:    _Warehouse_CountWHUploadsToRetry_Handler,
 		},
+		{
+			MethodName: "PlanWHUpload",
+			Handler:    _Warehouse_PlanWHUpload_Handler,
+		},
+		{
+			MethodName: "CreateWHBackfill",
+			Handler:    _Warehouse_CreateWHBackfill_Handler,
+		},
+		{
+			MethodName: "GetWHBackfill",
+			Handler:    _Warehouse_GetWHBackfill_Handler,
+		},
 	},
 	Streams:  []grpc.StreamDesc{},
 	Metadata: "proto/warehouse/warehouse.proto",
@@ -0,0 +1,110 @@
+package warehouse
+
+// This is going to delete the rows of the users from every namespace of a warehouse destination,
+// i.e. one namespace for every source connected with the destination.
+// called by delete/deleteSvc with (model.Job, model.Destination).
+// returns final status,error ({successful, failure}, err)
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/rudderlabs/rudder-go-kit/logger"
+	"github.com/rudderlabs/rudder-go-kit/stats"
+	backendconfig "github.com/rudderlabs/rudder-server/backend-config"
+	"github.com/rudderlabs/rudder-server/regulation-worker/internal/model"
+	"github.com/rudderlabs/rudder-server/warehouse/regulation"
+)
+
+const anonymousIDAttribute = "anonymousId"
+
+var (
+	pkgLogger             = logger.NewLogger().Child("warehouse")
+	supportedDestinations = []string{
+		"RS", "BQ", "SNOWFLAKE", "POSTGRES", "CLICKHOUSE", "MSSQL", "AZURE_SYNAPSE", "DELTALAKE", "SQLITE",
+		// datalakes delete by rewriting the parquet files of their tables
+		"S3_DATALAKE", "GCS_DATALAKE", "AZURE_DATALAKE",
+	}
+
+	errNoSources = errors.New("no sources connected with the destination")
+)
+
+type deleter interface {
+	Namespace(ctx context.Context, destination backendconfig.DestinationT, source backendconfig.SourceT) (string, error)
+	Delete(ctx context.Context, req regulation.Request) error
+}
+
+type WarehouseManager struct {
+	Deleter deleter
+}
+
+func (*WarehouseManager) GetSupportedDestinations() []string {
+	return supportedDestinations
+}
+
+func (m *WarehouseManager) Delete(ctx context.Context, job model.Job, destDetail model.Destination) model.JobStatus {
+	pkgLogger.Debugf("deleting job: %v from warehouse", job)
+
+	cleaningTime := stats.Default.NewTaggedStat(
+		"regulation_worker_cleaning_time",
+		stats.TimerType,
+		stats.Tags{
+			"destinationId": job.DestinationID,
+			"workspaceId":   job.WorkspaceID,
+			"jobType":       "warehouse",
+		})
+	defer cleaningTime.RecordDuration()()
+
+	if len(destDetail.Sources) == 0 {
+		return model.JobStatus{Status: model.JobStatusFailed, Error: errNoSources}
+	}
+
+	var userIDs, anonymousIDs []string
+	for _, user := range job.Users {
+		if user.ID != "" {
+			userIDs = append(userIDs, user.ID)
+		}
+		if anonymousID := user.Attributes[anonymousIDAttribute]; anonymousID != "" {
+			anonymousIDs = append(anonymousIDs, anonymousID)
+		}
+	}
+
+	destination := backendconfig.DestinationT{
+		ID:          destDetail.DestinationID,
+		Config:      destDetail.Config,
+		WorkspaceID: job.WorkspaceID,
+		DestinationDefinition: backendconfig.DestinationDefinitionT{
+			Name:   destDetail.Name,
+			Config: destDetail.DestDefConfig,
+		},
+	}
+
+	// sources sharing a namespace are deleted from only once
+	namespaces := make(map[string]struct{}, len(destDetail.Sources))
+	for _, source := range destDetail.Sources {
+		namespace, err := m.Deleter.Namespace(ctx, destination, backendconfig.SourceT{ID: source.ID, Name: source.Name})
+		if err != nil {
+			pkgLogger.Errorf("failed to resolve namespace of source: %s with error: %v", source.ID, err)
+			return model.JobStatus{Status: model.JobStatusFailed, Error: fmt.Errorf("resolving namespace of source %s: %w", source.ID, err)}
+		}
+		if _, ok := namespaces[namespace]; ok {
+			continue
+		}
+		namespaces[namespace] = struct{}{}
+
+		err = m.Deleter.Delete(ctx, regulation.Request{
+			WorkspaceID:  job.WorkspaceID,
+			Destination:  destination,
+			Namespace:    namespace,
+			UserIDs:      userIDs,
+			AnonymousIDs: anonymousIDs,
+		})
+		if err != nil {
+			pkgLogger.Errorf("failed to delete users from namespace: %s with error: %v", namespace, err)
+			return model.JobStatus{Status: model.JobStatusFailed, Error: fmt.Errorf("deleting from namespace %s: %w", namespace, err)}
+		}
+	}
+
+	pkgLogger.Debugf("deletion successful")
+	return model.JobStatus{Status: model.JobStatusComplete}
+}
@@ -0,0 +1,130 @@
+package warehouse_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	backendconfig "github.com/rudderlabs/rudder-server/backend-config"
+	"github.com/rudderlabs/rudder-server/regulation-worker/internal/delete/warehouse"
+	"github.com/rudderlabs/rudder-server/regulation-worker/internal/model"
+	"github.com/rudderlabs/rudder-server/warehouse/regulation"
+)
+
+type mockDeleter struct {
+	err          error
+	namespaceErr error
+	requests     []regulation.Request
+}
+
+func (m *mockDeleter) Namespace(_ context.Context, _ backendconfig.DestinationT, source backendconfig.SourceT) (string, error) {
+	if m.namespaceErr != nil {
+		return "", m.namespaceErr
+	}
+	return "namespace_" + source.Name, nil
+}
+
+func (m *mockDeleter) Delete(_ context.Context, req regulation.Request) error {
+	m.requests = append(m.requests, req)
+	return m.err
+}
+
+func TestWarehouseManager_Delete(t *testing.T) {
+	job := model.Job{
+		ID:            1,
+		WorkspaceID:   "workspace_id",
+		DestinationID: "destination_id",
+		Users: []model.User{
+			{
+				ID: "user_1",
+				Attributes: map[string]string{
+					"anonymousId": "anonymous_1",
+					"email":       "user_1@example.com",
+				},
+			},
+			{
+				ID: "user_2",
+			},
+		},
+	}
+	destDetail := model.Destination{
+		DestinationID: "destination_id",
+		Name:          "POSTGRES",
+		Config:        map[string]interface{}{"host": "localhost"},
+		Sources: []model.Source{
+			{ID: "source_1", Name: "a"},
+			{ID: "source_2", Name: "b"},
+			{ID: "source_3", Name: "a"},
+		},
+	}
+	destination := backendconfig.DestinationT{
+		ID:          "destination_id",
+		Config:      map[string]interface{}{"host": "localhost"},
+		WorkspaceID: "workspace_id",
+		DestinationDefinition: backendconfig.DestinationDefinitionT{
+			Name: "POSTGRES",
+		},
+	}
+
+	t.Run("complete", func(t *testing.T) {
+		d := &mockDeleter{}
+		m := &warehouse.WarehouseManager{Deleter: d}
+
+		status := m.Delete(context.Background(), job, destDetail)
+		require.Equal(t, model.JobStatus{Status: model.JobStatusComplete}, status)
+		require.Equal(t, []regulation.Request{
+			{
+				WorkspaceID:  "workspace_id",
+				Destination:  destination,
+				Namespace:    "namespace_a",
+				UserIDs:      []string{"user_1", "user_2"},
+				AnonymousIDs: []string{"anonymous_1"},
+			},
+			{
+				WorkspaceID:  "workspace_id",
+				Destination:  destination,
+				Namespace:    "namespace_b",
+				UserIDs:      []string{"user_1", "user_2"},
+				AnonymousIDs: []string{"anonymous_1"},
+			},
+		}, d.requests)
+	})
+
+	t.Run("failed", func(t *testing.T) {
+		d := &mockDeleter{err: errors.New("some error")}
+		m := &warehouse.WarehouseManager{Deleter: d}
+
+		status := m.Delete(context.Background(), job, destDetail)
+		require.Equal(t, model.JobStatusFailed, status.Status)
+		require.EqualError(t, status.Error, "deleting from namespace namespace_a: some error")
+		require.Len(t, d.requests, 1)
+	})
+
+	t.Run("namespace not resolved", func(t *testing.T) {
+		d := &mockDeleter{namespaceErr: errors.New("some error")}
+		m := &warehouse.WarehouseManager{Deleter: d}
+
+		status := m.Delete(context.Background(), job, destDetail)
+		require.Equal(t, model.JobStatusFailed, status.Status)
+		require.EqualError(t, status.Error, "resolving namespace of source source_1: some error")
+		require.Empty(t, d.requests)
+	})
+
+	t.Run("no sources", func(t *testing.T) {
+		d := &mockDeleter{}
+		m := &warehouse.WarehouseManager{Deleter: d}
+
+		status := m.Delete(context.Background(), job, model.Destination{DestinationID: "destination_id", Name: "POSTGRES"})
+		require.Equal(t, model.JobStatusFailed, status.Status)
+		require.Empty(t, d.requests)
+	})
+}
+
+func TestWarehouseManager_GetSupportedDestinations(t *testing.T) {
+	supported := (&warehouse.WarehouseManager{}).GetSupportedDestinations()
+	for _, destType := range []string{"POSTGRES", "SQLITE", "S3_DATALAKE", "GCS_DATALAKE", "AZURE_DATALAKE"} {
+		require.Contains(t, supported, destType)
+	}
+}
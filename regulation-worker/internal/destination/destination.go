@@ -47,12 +47,20 @@ func (d *DestinationConfig) Start(ctx context.Context) {
 			for _, config := range configs {
 				for _, source := range config.Sources {
 					for _, dest := range source.Destinations {
-						destinations[dest.ID] = model.Destination{
-							DestinationID: dest.ID,
-							Config:        dest.Config,
-							Name:          dest.DestinationDefinition.Name,
-							DestDefConfig: dest.DestinationDefinition.Config,
+						destination, ok := destinations[dest.ID]
+						if !ok {
+							destination = model.Destination{
+								DestinationID: dest.ID,
+								Config:        dest.Config,
+								Name:          dest.DestinationDefinition.Name,
+								DestDefConfig: dest.DestinationDefinition.Config,
+							}
 						}
+						destination.Sources = append(destination.Sources, model.Source{
+							ID:   source.ID,
+							Name: source.Name,
+						})
+						destinations[dest.ID] = destination
 					}
 				}
 			}
@@ -33,6 +33,8 @@ func TestDestination(t *testing.T) {
 		WorkspaceID: "1234",
 		Sources: []backendconfig.SourceT{
 			{
+				ID:   "source_1",
+				Name: "Source 1",
 				Destinations: []backendconfig.DestinationT{
 					{
 						ID:     destinationID,
@@ -50,7 +52,19 @@ func TestDestination(t *testing.T) {
 				},
 			},
 			{
+				ID:   "source_2",
+				Name: "Source 2",
 				Destinations: []backendconfig.DestinationT{
+					{
+						ID:     destinationID,
+						Config: config,
+						DestinationDefinition: backendconfig.DestinationDefinitionT{
+							Config: map[string]interface{}{
+								"randomKey": "randomValue",
+							},
+							Name: "S3",
+						},
+					},
 					{
 						ID: "1113",
 					},
@@ -101,6 +115,10 @@ func TestDestination(t *testing.T) {
 		},
 		DestinationID: destinationID,
 		Name:          "S3",
+		Sources: []model.Source{
+			{ID: "source_1", Name: "Source 1"},
+			{ID: "source_2", Name: "Source 2"},
+		},
 	}
 
 	destDetail, err := dest.GetDestDetails(destinationID)
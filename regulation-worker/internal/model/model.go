@@ -50,6 +50,13 @@ type Destination struct {
 	DestDefConfig map[string]interface{}
 	DestinationID string
 	Name          string
+	Sources       []Source
+}
+
+// Source is a source connected to a destination
+type Source struct {
+	ID   string
+	Name string
 }
 
 type APIReqErr struct {
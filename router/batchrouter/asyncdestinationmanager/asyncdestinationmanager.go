@@ -248,8 +248,13 @@ func Upload(url, filePath string, config map[string]interface{}, destType string
 	return uploadResponse
 }
 
+// GetTransformedData returns the event found in the payload of a job, i.e. the body of the transformer's response
+// or the payload itself for destinations which are not transformed
 func GetTransformedData(payload stdjson.RawMessage) string {
-	return gjson.Get(string(payload), "body.JSON").String()
+	if data := gjson.GetBytes(payload, "body.JSON"); data.Exists() {
+		return data.String()
+	}
+	return string(payload)
 }
 
 func GetMarshalledData(payload string, jobID int64) string {
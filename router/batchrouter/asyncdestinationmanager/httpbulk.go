@@ -0,0 +1,283 @@
+package asyncdestinationmanager
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	stdjson "encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/tidwall/gjson"
+
+	"github.com/rudderlabs/rudder-go-kit/stats"
+	backendconfig "github.com/rudderlabs/rudder-server/backend-config"
+	"github.com/rudderlabs/rudder-server/jobsdb"
+)
+
+// HTTPBulkUpload is the destination type of generic bulk apis, where events are uploaded as a csv file to an http endpoint
+// creating an import job, the status of which is then polled. It is configured with the following destination config:
+//
+//   - uploadURL: the csv file is posted to this url, responding with {"importId": "..."}
+//   - statusURL: polled with GET, responding with {"status": "pending|completed|failed", "failedRecords": n}
+//   - failedRecordsURL: fetched with GET once an import with failed records is completed, responding with
+//     {"records": [{"rudder_job_id": 1, "error": "..."}]}
+//   - headers: optional headers of all requests, as a list of {"from": "<name>", "to": "<value>"}
+//
+// Occurrences of {importId} in statusURL and failedRecordsURL are replaced with the id of the import.
+// The first column of the csv file is [JobIDColumn], followed by the top level fields of the events, sorted by name.
+const HTTPBulkUpload = "HTTP_BULK_UPLOAD"
+
+// JobIDColumn is the column of the csv file holding the job id of each event
+const JobIDColumn = "rudder_job_id"
+
+const importIdPlaceholder = "{importId}"
+
+// httpBulkManager is the manager of [HTTPBulkUpload] destinations
+type httpBulkManager struct {
+	destType string
+	client   *http.Client
+}
+
+// NewHTTPBulkManager creates the manager of a generic bulk api destination type
+func NewHTTPBulkManager(destType string) AsyncDestinationManager {
+	return &httpBulkManager{
+		destType: destType,
+		client:   &http.Client{Timeout: HTTPTimeout},
+	}
+}
+
+func (m *httpBulkManager) Upload(destination *backendconfig.DestinationT, asyncDestStruct *AsyncDestinationStruct) AsyncUploadOutput {
+	failedJobIDs := asyncDestStruct.FailedJobIDs
+	importingJobIDs := asyncDestStruct.ImportingJobIDs
+	failed := func(reason string) AsyncUploadOutput {
+		return AsyncUploadOutput{
+			FailedJobIDs:  append(failedJobIDs, importingJobIDs...),
+			FailedReason:  fmt.Sprintf(`{"error":%q}`, reason),
+			FailedCount:   len(failedJobIDs) + len(importingJobIDs),
+			DestinationID: destination.ID,
+		}
+	}
+	aborted := func(reason string) AsyncUploadOutput {
+		return AsyncUploadOutput{
+			AbortJobIDs:   importingJobIDs,
+			AbortReason:   fmt.Sprintf(`{"error":%q}`, reason),
+			AbortCount:    len(importingJobIDs),
+			FailedJobIDs:  failedJobIDs,
+			FailedReason:  `{"error":"Jobs flowed over the prescribed limit"}`,
+			FailedCount:   len(failedJobIDs),
+			DestinationID: destination.ID,
+		}
+	}
+
+	for _, key := range []string{"uploadURL", "statusURL", "failedRecordsURL"} {
+		if configString(destination.Config, key) == "" {
+			return aborted(fmt.Sprintf("invalid destination config: %s is required", key))
+		}
+	}
+	body, err := csvPayload(asyncDestStruct.FileName)
+	if err != nil {
+		return failed(err.Error())
+	}
+
+	uploadTimeStat := stats.Default.NewTaggedStat("async_upload_time", stats.TimerType, map[string]string{
+		"module":   "batch_router",
+		"destType": m.destType,
+	})
+	startTime := time.Now()
+	pkgLogger.Debugf("[Async Destination Manager] File Upload Started for Dest Type %v", m.destType)
+	respBody, statusCode, err := m.do(destination, http.MethodPost, configString(destination.Config, "uploadURL"), "text/csv", body)
+	pkgLogger.Debugf("[Async Destination Manager] File Upload Finished for Dest Type %v", m.destType)
+	uploadTimeStat.Since(startTime)
+	switch {
+	case err != nil:
+		return failed(err.Error())
+	case statusCode == http.StatusTooManyRequests || statusCode == http.StatusRequestTimeout || statusCode >= http.StatusInternalServerError:
+		return failed(fmt.Sprintf("upload returned status code %d: %s", statusCode, respBody))
+	case statusCode >= http.StatusBadRequest:
+		return aborted(fmt.Sprintf("upload returned status code %d: %s", statusCode, respBody))
+	}
+	importId := gjson.GetBytes(respBody, "importId").String()
+	if importId == "" {
+		return failed(fmt.Sprintf("upload response without importId: %s", respBody))
+	}
+	importingParameters, err := json.Marshal(Parameters{ImportId: importId})
+	if err != nil {
+		return failed(err.Error())
+	}
+	return AsyncUploadOutput{
+		ImportingJobIDs:     importingJobIDs,
+		ImportingParameters: importingParameters,
+		importingCount:      len(importingJobIDs),
+		FailedJobIDs:        failedJobIDs,
+		FailedReason:        `{"error":"Jobs flowed over the prescribed limit"}`,
+		FailedCount:         len(failedJobIDs),
+		DestinationID:       destination.ID,
+	}
+}
+
+func (m *httpBulkManager) Poll(destination *backendconfig.DestinationT, parameters stdjson.RawMessage) (AsyncStatusResponse, error) {
+	statusURL := importURL(configString(destination.Config, "statusURL"), parameters)
+	respBody, statusCode, err := m.do(destination, http.MethodGet, statusURL, "", nil)
+	if err != nil {
+		return AsyncStatusResponse{}, fmt.Errorf("polling import status: %w", err)
+	}
+	if statusCode != http.StatusOK {
+		return AsyncStatusResponse{}, fmt.Errorf("polling import status: status code %d: %s", statusCode, respBody)
+	}
+	switch status := gjson.GetBytes(respBody, "status").String(); status {
+	case "completed":
+		return AsyncStatusResponse{
+			Success:    true,
+			StatusCode: http.StatusOK,
+			HasFailed:  gjson.GetBytes(respBody, "failedRecords").Int() > 0,
+		}, nil
+	case "failed":
+		return AsyncStatusResponse{StatusCode: http.StatusInternalServerError}, nil
+	default:
+		return AsyncStatusResponse{}, nil
+	}
+}
+
+func (m *httpBulkManager) GetFailedEvents(destination *backendconfig.DestinationT, importingJobs []*jobsdb.JobT, parameters stdjson.RawMessage, _ AsyncStatusResponse) (AsyncFailedEvents, error) {
+	failedRecordsURL := importURL(configString(destination.Config, "failedRecordsURL"), parameters)
+	respBody, statusCode, err := m.do(destination, http.MethodGet, failedRecordsURL, "", nil)
+	if err != nil {
+		return AsyncFailedEvents{}, fmt.Errorf("fetching failed records: %w", err)
+	}
+	if statusCode != http.StatusOK {
+		return AsyncFailedEvents{}, fmt.Errorf("fetching failed records: status code %d: %s", statusCode, respBody)
+	}
+	failedEvents := AsyncFailedEvents{AbortedReasons: map[int64]string{}}
+	for _, record := range gjson.GetBytes(respBody, "records").Array() {
+		failedEvents.AbortedReasons[record.Get(JobIDColumn).Int()] = record.Get("error").String()
+	}
+	for _, job := range importingJobs {
+		if _, ok := failedEvents.AbortedReasons[job.JobID]; ok {
+			failedEvents.AbortedJobIDs = append(failedEvents.AbortedJobIDs, job.JobID)
+		} else {
+			failedEvents.SucceededJobIDs = append(failedEvents.SucceededJobIDs, job.JobID)
+		}
+	}
+	return failedEvents, nil
+}
+
+// do sends a request to the bulk api, returning the body and status code of its response
+func (m *httpBulkManager) do(destination *backendconfig.DestinationT, method, rawURL, contentType string, body []byte) ([]byte, int, error) {
+	req, err := http.NewRequest(method, rawURL, bytes.NewReader(body))
+	if err != nil {
+		return nil, 0, fmt.Errorf("creating request: %w", err)
+	}
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+	if headers, ok := destination.Config["headers"].([]interface{}); ok {
+		for _, h := range headers {
+			if header, ok := h.(map[string]interface{}); ok {
+				name, _ := header["from"].(string)
+				value, _ := header["to"].(string)
+				if name != "" {
+					req.Header.Set(name, value)
+				}
+			}
+		}
+	}
+	resp, err := m.client.Do(req)
+	if err != nil {
+		return nil, 0, fmt.Errorf("sending request: %w", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, 0, fmt.Errorf("reading response: %w", err)
+	}
+	return respBody, resp.StatusCode, nil
+}
+
+// csvPayload converts the events of the async destination file to a csv file
+func csvPayload(fileName string) ([]byte, error) {
+	f, err := os.Open(fileName)
+	if err != nil {
+		return nil, fmt.Errorf("opening file: %w", err)
+	}
+	defer func() { _ = f.Close() }()
+	var jobs []AsyncJob
+	columns := map[string]struct{}{}
+	sc := bufio.NewScanner(f)
+	sc.Buffer(nil, 10*1024*1024)
+	for sc.Scan() {
+		var job AsyncJob
+		if err := json.Unmarshal(sc.Bytes(), &job); err != nil {
+			return nil, fmt.Errorf("unmarshalling job: %w", err)
+		}
+		for column := range job.Message {
+			columns[column] = struct{}{}
+		}
+		jobs = append(jobs, job)
+	}
+	if err := sc.Err(); err != nil {
+		return nil, fmt.Errorf("reading file: %w", err)
+	}
+	delete(columns, JobIDColumn)
+	header := make([]string, 0, len(columns)+1)
+	for column := range columns {
+		header = append(header, column)
+	}
+	sort.Strings(header)
+	header = append([]string{JobIDColumn}, header...)
+
+	var buf bytes.Buffer
+	w := csv.NewWriter(&buf)
+	if err := w.Write(header); err != nil {
+		return nil, fmt.Errorf("writing csv header: %w", err)
+	}
+	record := make([]string, len(header))
+	for _, job := range jobs {
+		jobID, _ := job.Metadata["job_id"].(float64)
+		record[0] = strconv.FormatInt(int64(jobID), 10)
+		for i, column := range header[1:] {
+			if record[i+1], err = csvValue(job.Message[column]); err != nil {
+				return nil, err
+			}
+		}
+		if err := w.Write(record); err != nil {
+			return nil, fmt.Errorf("writing csv record: %w", err)
+		}
+	}
+	w.Flush()
+	if err := w.Error(); err != nil {
+		return nil, fmt.Errorf("flushing csv writer: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+func csvValue(value interface{}) (string, error) {
+	switch v := value.(type) {
+	case nil:
+		return "", nil
+	case string:
+		return v, nil
+	default:
+		b, err := json.Marshal(v)
+		if err != nil {
+			return "", fmt.Errorf("marshalling csv value: %w", err)
+		}
+		return string(b), nil
+	}
+}
+
+func importURL(rawURL string, parameters stdjson.RawMessage) string {
+	return strings.ReplaceAll(rawURL, importIdPlaceholder, url.PathEscape(gjson.GetBytes(parameters, "importId").String()))
+}
+
+func configString(destConfig map[string]interface{}, key string) string {
+	value, _ := destConfig[key].(string)
+	return value
+}
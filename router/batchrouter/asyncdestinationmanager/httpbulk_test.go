@@ -0,0 +1,319 @@
+package asyncdestinationmanager_test
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/rudderlabs/rudder-go-kit/config"
+	backendconfig "github.com/rudderlabs/rudder-server/backend-config"
+	"github.com/rudderlabs/rudder-server/jobsdb"
+	"github.com/rudderlabs/rudder-server/router/batchrouter/asyncdestinationmanager"
+)
+
+// fakeBulkAPI is a bulk api which accepts csv files, completing their imports after a poll and rejecting records without an email
+type fakeBulkAPI struct {
+	mu      sync.Mutex
+	imports map[string][][]string
+	polls   map[string]int
+	headers http.Header
+}
+
+func newFakeBulkAPI(t *testing.T) (*fakeBulkAPI, *httptest.Server) {
+	api := &fakeBulkAPI{imports: map[string][][]string{}, polls: map[string]int{}}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/upload", func(w http.ResponseWriter, r *http.Request) {
+		api.mu.Lock()
+		defer api.mu.Unlock()
+		api.headers = r.Header.Clone()
+		records, err := csv.NewReader(r.Body).ReadAll()
+		if err != nil || r.Header.Get("Content-Type") != "text/csv" {
+			http.Error(w, "invalid csv", http.StatusBadRequest)
+			return
+		}
+		importID := fmt.Sprintf("import-%d", len(api.imports)+1)
+		api.imports[importID] = records
+		_, _ = fmt.Fprintf(w, `{"importId":%q}`, importID)
+	})
+	mux.HandleFunc("/imports/", func(w http.ResponseWriter, r *http.Request) {
+		api.mu.Lock()
+		defer api.mu.Unlock()
+		importID, resource, _ := strings.Cut(strings.TrimPrefix(r.URL.Path, "/imports/"), "/")
+		records, ok := api.imports[importID]
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		type failedRecord struct {
+			JobID json.Number `json:"rudder_job_id"`
+			Error string      `json:"error"`
+		}
+		var failed []failedRecord
+		emailIndex := -1
+		for i, column := range records[0] {
+			if column == "email" {
+				emailIndex = i
+			}
+		}
+		for _, record := range records[1:] {
+			if emailIndex < 0 || record[emailIndex] == "" {
+				failed = append(failed, failedRecord{JobID: json.Number(record[0]), Error: "email is required"})
+			}
+		}
+		switch resource {
+		case "status":
+			api.polls[importID]++
+			if api.polls[importID] == 1 {
+				_, _ = w.Write([]byte(`{"status":"pending"}`))
+				return
+			}
+			_, _ = fmt.Fprintf(w, `{"status":"completed","failedRecords":%d}`, len(failed))
+		case "failed":
+			_ = json.NewEncoder(w).Encode(map[string]interface{}{"records": failed})
+		default:
+			http.NotFound(w, r)
+		}
+	})
+	srv := httptest.NewServer(mux)
+	t.Cleanup(srv.Close)
+	return api, srv
+}
+
+func writeAsyncFile(t *testing.T, lines ...string) string {
+	fileName := filepath.Join(t.TempDir(), "async.txt")
+	require.NoError(t, os.WriteFile(fileName, []byte(strings.Join(lines, "\n")+"\n"), 0o600))
+	return fileName
+}
+
+func TestHTTPBulkManager(t *testing.T) {
+	asyncdestinationmanager.Init()
+	api, srv := newFakeBulkAPI(t)
+
+	require.True(t, asyncdestinationmanager.IsAsyncDestination(asyncdestinationmanager.HTTPBulkUpload))
+	require.True(t, asyncdestinationmanager.IsAsyncDestination("MARKETO_BULK_UPLOAD"))
+	require.False(t, asyncdestinationmanager.IsAsyncDestination("S3"))
+	manager, err := asyncdestinationmanager.NewManager(asyncdestinationmanager.HTTPBulkUpload)
+	require.NoError(t, err)
+
+	destination := &backendconfig.DestinationT{
+		ID: "destination-1",
+		Config: map[string]interface{}{
+			"uploadURL":        srv.URL + "/upload",
+			"statusURL":        srv.URL + "/imports/{importId}/status",
+			"failedRecordsURL": srv.URL + "/imports/{importId}/failed",
+			"headers":          []interface{}{map[string]interface{}{"from": "Authorization", "to": "Bearer token"}},
+		},
+	}
+	asyncDestStruct := &asyncdestinationmanager.AsyncDestinationStruct{
+		FileName: writeAsyncFile(t,
+			asyncdestinationmanager.GetMarshalledData(`{"email":"a@example.com","name":"a","attributes":{"plan":"pro"}}`, 1),
+			asyncdestinationmanager.GetMarshalledData(`{"name":"b"}`, 2),
+			asyncdestinationmanager.GetMarshalledData(`{"email":"c@example.com","score":3}`, 1000000),
+		),
+		ImportingJobIDs: []int64{1, 2, 1000000},
+		FailedJobIDs:    []int64{4},
+	}
+
+	t.Run("upload", func(t *testing.T) {
+		output := manager.Upload(destination, asyncDestStruct)
+		require.Equal(t, []int64{1, 2, 1000000}, output.ImportingJobIDs)
+		require.Equal(t, []int64{4}, output.FailedJobIDs, "jobs over the limit are retried")
+		require.Equal(t, "destination-1", output.DestinationID)
+		require.JSONEq(t, `"import-1"`, mustJSON(t, output.ImportingParameters, "importId"))
+
+		require.Equal(t, "Bearer token", api.headers.Get("Authorization"))
+		require.Equal(t, [][]string{
+			{"rudder_job_id", "attributes", "email", "name", "score"},
+			{"1", `{"plan":"pro"}`, "a@example.com", "a", ""},
+			{"2", "", "", "b", ""},
+			{"1000000", "", "c@example.com", "", "3"},
+		}, api.imports["import-1"])
+	})
+
+	parameters := json.RawMessage(`{"importId":"import-1"}`)
+	t.Run("poll", func(t *testing.T) {
+		status, err := manager.Poll(destination, parameters)
+		require.NoError(t, err)
+		require.Equal(t, asyncdestinationmanager.AsyncStatusResponse{}, status, "import in progress")
+
+		status, err = manager.Poll(destination, parameters)
+		require.NoError(t, err)
+		require.True(t, status.Success)
+		require.True(t, status.HasFailed)
+
+		_, err = manager.Poll(destination, json.RawMessage(`{"importId":"unknown"}`))
+		require.Error(t, err)
+	})
+
+	t.Run("failed events", func(t *testing.T) {
+		failedEvents, err := manager.GetFailedEvents(destination, []*jobsdb.JobT{{JobID: 1}, {JobID: 2}, {JobID: 1000000}}, parameters, asyncdestinationmanager.AsyncStatusResponse{Success: true, HasFailed: true})
+		require.NoError(t, err)
+		require.Equal(t, []int64{1, 1000000}, failedEvents.SucceededJobIDs)
+		require.Equal(t, []int64{2}, failedEvents.AbortedJobIDs)
+		require.Equal(t, "email is required", failedEvents.AbortedReasons[2])
+	})
+
+	t.Run("invalid config", func(t *testing.T) {
+		output := manager.Upload(&backendconfig.DestinationT{ID: "destination-2", Config: map[string]interface{}{"uploadURL": srv.URL + "/upload"}}, asyncDestStruct)
+		require.Equal(t, []int64{1, 2, 1000000}, output.AbortJobIDs)
+		require.Contains(t, output.AbortReason, "statusURL is required")
+	})
+
+	t.Run("upload failures", func(t *testing.T) {
+		for status, abort := range map[int]bool{http.StatusBadRequest: true, http.StatusTooManyRequests: false, http.StatusInternalServerError: false} {
+			failing := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(status)
+			}))
+			dest := &backendconfig.DestinationT{ID: "destination-3", Config: map[string]interface{}{
+				"uploadURL":        failing.URL,
+				"statusURL":        failing.URL,
+				"failedRecordsURL": failing.URL,
+			}}
+			output := manager.Upload(dest, asyncDestStruct)
+			failing.Close()
+			if abort {
+				require.Equal(t, []int64{1, 2, 1000000}, output.AbortJobIDs, "status %d", status)
+				require.Equal(t, []int64{4}, output.FailedJobIDs, "status %d", status)
+			} else {
+				require.Empty(t, output.AbortJobIDs, "status %d", status)
+				require.Equal(t, []int64{4, 1, 2, 1000000}, output.FailedJobIDs, "status %d", status)
+			}
+		}
+	})
+}
+
+// newFakeTransformer is a transformer implementing the async flow of marketo, accepting the jobs with an email
+func newFakeTransformer(t *testing.T) *httptest.Server {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/fileUpload", func(w http.ResponseWriter, r *http.Request) {
+		var upload asyncdestinationmanager.AsyncUploadT
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&upload))
+		require.Equal(t, "marketo_bulk_upload", upload.DestType)
+		require.Equal(t, "munchkin", upload.Config["munchkinId"])
+		successful, unsuccessful := []string{}, []string{}
+		for _, job := range upload.Input {
+			jobID := fmt.Sprint(job.Metadata["job_id"])
+			if _, ok := job.Message["email"]; ok {
+				successful = append(successful, jobID)
+				continue
+			}
+			unsuccessful = append(unsuccessful, jobID)
+		}
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{
+			"statusCode": 200,
+			"importId":   "import-1",
+			"pollURL":    "/pollStatus",
+			"metadata":   map[string]interface{}{"successfulJobs": successful, "unsuccessfulJobs": unsuccessful, "csvHeader": "email,name"},
+		})
+	})
+	mux.HandleFunc("/pollStatus", func(w http.ResponseWriter, r *http.Request) {
+		var poll asyncdestinationmanager.AsyncPoll
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&poll))
+		if poll.ImportId != "import-1" {
+			http.Error(w, "unknown import", http.StatusBadRequest)
+			return
+		}
+		_, _ = w.Write([]byte(`{"Success":true,"StatusCode":200,"HasFailed":true,"FailedJobsURL":"/getFailedJobs"}`))
+	})
+	mux.HandleFunc("/getFailedJobs", func(w http.ResponseWriter, r *http.Request) {
+		var failed asyncdestinationmanager.AsyncFailedPayload
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&failed))
+		require.Equal(t, "import-1", failed.ImportId)
+		require.Equal(t, "email,name", failed.MetaData.CSVHeaders)
+		require.Len(t, failed.Input, 3)
+		_, _ = w.Write([]byte(`{"status":"200","metadata":{"failedKeys":["2"],"failedReasons":{"2":"invalid email"},"warningKeys":["3"],"succeededKeys":["1"]}}`))
+	})
+	srv := httptest.NewServer(mux)
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func TestTransformerManager(t *testing.T) {
+	asyncdestinationmanager.Init()
+	srv := newFakeTransformer(t)
+	config.Set("DEST_TRANSFORM_URL", srv.URL)
+	t.Cleanup(config.Reset)
+
+	manager, err := asyncdestinationmanager.NewManager("MARKETO_BULK_UPLOAD")
+	require.NoError(t, err)
+	destination := &backendconfig.DestinationT{ID: "destination-1", Config: map[string]interface{}{"munchkinId": "munchkin"}}
+
+	var parameters json.RawMessage
+	t.Run("upload", func(t *testing.T) {
+		output := manager.Upload(destination, &asyncdestinationmanager.AsyncDestinationStruct{
+			FileName: writeAsyncFile(t,
+				asyncdestinationmanager.GetMarshalledData(`{"email":"a@example.com","name":"a"}`, 1),
+				asyncdestinationmanager.GetMarshalledData(`{"email":"b","name":"b"}`, 2),
+				asyncdestinationmanager.GetMarshalledData(`{"name":"c"}`, 3),
+			),
+			ImportingJobIDs: []int64{1, 2, 3},
+			FailedJobIDs:    []int64{4},
+			URL:             "/fileUpload",
+		})
+		require.Equal(t, "destination-1", output.DestinationID)
+		require.Equal(t, []int64{1, 2}, output.ImportingJobIDs)
+		require.Equal(t, []int64{4, 3}, output.FailedJobIDs, "jobs rejected by the transformer are retried")
+		require.JSONEq(t, `{"importId":"import-1","pollURL":"/pollStatus","metadata":{"csvHeader":"email,name"}}`, string(output.ImportingParameters))
+		parameters = output.ImportingParameters
+	})
+
+	t.Run("upload failure", func(t *testing.T) {
+		output := manager.Upload(destination, &asyncdestinationmanager.AsyncDestinationStruct{
+			FileName:        writeAsyncFile(t, asyncdestinationmanager.GetMarshalledData(`{"email":"a@example.com"}`, 1)),
+			ImportingJobIDs: []int64{1},
+			URL:             "/unknown",
+		})
+		require.Empty(t, output.ImportingJobIDs)
+		require.Equal(t, []int64{1}, output.FailedJobIDs)
+	})
+
+	t.Run("poll", func(t *testing.T) {
+		status, err := manager.Poll(destination, parameters)
+		require.NoError(t, err)
+		require.Equal(t, asyncdestinationmanager.AsyncStatusResponse{Success: true, StatusCode: 200, HasFailed: true, FailedJobsURL: "/getFailedJobs"}, status)
+
+		_, err = manager.Poll(destination, json.RawMessage(`{"importId":"unknown","pollURL":"/pollStatus"}`))
+		require.Error(t, err)
+	})
+
+	t.Run("failed keys", func(t *testing.T) {
+		newJob := func(jobID int64) *jobsdb.JobT {
+			return &jobsdb.JobT{JobID: jobID, EventPayload: []byte(`{"body":{"JSON":{"email":"a@example.com"}}}`)}
+		}
+		failedEvents, err := manager.GetFailedEvents(destination, []*jobsdb.JobT{newJob(1), newJob(2), newJob(3)}, parameters, asyncdestinationmanager.AsyncStatusResponse{Success: true, HasFailed: true, FailedJobsURL: "/getFailedJobs"})
+		require.NoError(t, err)
+		require.Equal(t, []int64{1, 3}, failedEvents.SucceededJobIDs, "jobs with warnings are succeeded")
+		require.Equal(t, []int64{2}, failedEvents.AbortedJobIDs)
+		require.Equal(t, map[int64]string{2: "invalid email"}, failedEvents.AbortedReasons)
+
+		_, err = manager.GetFailedEvents(destination, []*jobsdb.JobT{newJob(1)}, parameters, asyncdestinationmanager.AsyncStatusResponse{Success: true, HasFailed: true, FailedJobsURL: "/unknown"})
+		require.Error(t, err)
+	})
+}
+
+func TestRegister(t *testing.T) {
+	const destType = "TEST_BULK_UPLOAD"
+	_, err := asyncdestinationmanager.NewManager(destType)
+	require.Error(t, err)
+
+	asyncdestinationmanager.Register(destType, asyncdestinationmanager.NewHTTPBulkManager)
+	require.True(t, asyncdestinationmanager.IsAsyncDestination(destType))
+	manager, err := asyncdestinationmanager.NewManager(destType)
+	require.NoError(t, err)
+	require.NotNil(t, manager)
+}
+
+func mustJSON(t *testing.T, data json.RawMessage, key string) string {
+	var m map[string]json.RawMessage
+	require.NoError(t, json.Unmarshal(data, &m))
+	return string(m[key])
+}
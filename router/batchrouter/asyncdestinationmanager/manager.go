@@ -0,0 +1,82 @@
+package asyncdestinationmanager
+
+import (
+	stdjson "encoding/json"
+	"fmt"
+	"sync"
+
+	backendconfig "github.com/rudderlabs/rudder-server/backend-config"
+	"github.com/rudderlabs/rudder-server/jobsdb"
+)
+
+// AsyncDestinationManager is implemented by async destinations, i.e. destinations with bulk apis where batches of events are uploaded
+// as import jobs, the status of which is polled until they are finished.
+type AsyncDestinationManager interface {
+	// Upload uploads the events found in the file of the async destination struct.
+	// Jobs which are accepted by the destination are returned as importing, along with the parameters needed for polling the status of their import.
+	Upload(destination *backendconfig.DestinationT, asyncDestStruct *AsyncDestinationStruct) AsyncUploadOutput
+	// Poll returns the status of the import identified by the parameters returned by Upload.
+	// An error is returned if the status could not be retrieved, in which case the import is polled again later.
+	Poll(destination *backendconfig.DestinationT, parameters stdjson.RawMessage) (AsyncStatusResponse, error)
+	// GetFailedEvents returns the outcome of each job of a finished import which has failed events.
+	// An error is returned if the outcome could not be retrieved, in which case the import is polled again later.
+	GetFailedEvents(destination *backendconfig.DestinationT, importingJobs []*jobsdb.JobT, parameters stdjson.RawMessage, status AsyncStatusResponse) (AsyncFailedEvents, error)
+}
+
+// AsyncStatusResponse is the status of an import:
+//   - Success is true if the import is finished, HasFailed is also true if some of its events failed
+//   - otherwise a non-zero StatusCode means that the import failed, jobs are aborted if the status code is terminal and retried otherwise
+//   - otherwise the import is still in progress
+type AsyncStatusResponse struct {
+	Success        bool
+	StatusCode     int
+	HasFailed      bool
+	HasWarning     bool
+	FailedJobsURL  string
+	WarningJobsURL string
+}
+
+// AsyncFailedEvents is the outcome of the jobs of a finished import.
+// Jobs which are neither succeeded nor aborted are retried.
+type AsyncFailedEvents struct {
+	SucceededJobIDs []int64
+	AbortedJobIDs   []int64
+	AbortedReasons  map[int64]string
+}
+
+// NewManagerFunc creates the manager of an async destination type
+type NewManagerFunc func(destType string) AsyncDestinationManager
+
+var (
+	managersMu sync.RWMutex
+	managers   = map[string]NewManagerFunc{
+		"MARKETO_BULK_UPLOAD": NewTransformerManager,
+		HTTPBulkUpload:        NewHTTPBulkManager,
+	}
+)
+
+// Register registers the manager of an async destination type, replacing any manager previously registered for it
+func Register(destType string, newManager NewManagerFunc) {
+	managersMu.Lock()
+	defer managersMu.Unlock()
+	managers[destType] = newManager
+}
+
+// IsAsyncDestination returns true if a manager is registered for the destination type
+func IsAsyncDestination(destType string) bool {
+	managersMu.RLock()
+	defer managersMu.RUnlock()
+	_, ok := managers[destType]
+	return ok
+}
+
+// NewManager creates the manager of an async destination type
+func NewManager(destType string) (AsyncDestinationManager, error) {
+	managersMu.RLock()
+	newManager, ok := managers[destType]
+	managersMu.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("no async destination manager registered for %s", destType)
+	}
+	return newManager(destType), nil
+}
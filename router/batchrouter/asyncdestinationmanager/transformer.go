@@ -0,0 +1,122 @@
+package asyncdestinationmanager
+
+import (
+	stdjson "encoding/json"
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/tidwall/gjson"
+
+	"github.com/rudderlabs/rudder-go-kit/config"
+	backendconfig "github.com/rudderlabs/rudder-server/backend-config"
+	"github.com/rudderlabs/rudder-server/jobsdb"
+	"github.com/rudderlabs/rudder-server/utils/misc"
+)
+
+// AsyncPoll is the payload of the transformer's poll requests
+type AsyncPoll struct {
+	Config   map[string]interface{} `json:"config"`
+	ImportId string                 `json:"importId"`
+	DestType string                 `json:"destType"`
+}
+
+// transformerManager delegates uploading, polling and fetching failed events to the transformer,
+// which exposes the endpoints of the destination in the events it transforms
+type transformerManager struct {
+	destType       string
+	transformerURL string
+}
+
+// NewTransformerManager creates the manager of an async destination type which is implemented by the transformer
+func NewTransformerManager(destType string) AsyncDestinationManager {
+	return &transformerManager{
+		destType:       destType,
+		transformerURL: config.GetString("DEST_TRANSFORM_URL", "http://localhost:9090"),
+	}
+}
+
+func (m *transformerManager) Upload(destination *backendconfig.DestinationT, asyncDestStruct *AsyncDestinationStruct) AsyncUploadOutput {
+	uploadURL, err := m.resolveURL(asyncDestStruct.URL)
+	if err != nil {
+		return AsyncUploadOutput{
+			FailedJobIDs:  append(asyncDestStruct.FailedJobIDs, asyncDestStruct.ImportingJobIDs...),
+			FailedReason:  fmt.Sprintf(`{"error":%q}`, err.Error()),
+			FailedCount:   len(asyncDestStruct.FailedJobIDs) + len(asyncDestStruct.ImportingJobIDs),
+			DestinationID: destination.ID,
+		}
+	}
+	return Upload(uploadURL, asyncDestStruct.FileName, destination.Config, m.destType, asyncDestStruct.FailedJobIDs, asyncDestStruct.ImportingJobIDs, destination.ID)
+}
+
+func (m *transformerManager) Poll(destination *backendconfig.DestinationT, parameters stdjson.RawMessage) (AsyncStatusResponse, error) {
+	pollStruct := AsyncPoll{
+		ImportId: gjson.GetBytes(parameters, "importId").String(),
+		Config:   destination.Config,
+		DestType: strings.ToLower(m.destType),
+	}
+	payload, err := json.Marshal(pollStruct)
+	if err != nil {
+		return AsyncStatusResponse{}, fmt.Errorf("marshalling poll payload: %w", err)
+	}
+	pollURL := gjson.GetBytes(parameters, "pollURL").String()
+	bodyBytes, statusCode := misc.HTTPCallWithRetryWithTimeout(m.transformerURL+pollURL, payload, HTTPTimeout)
+	if statusCode != 200 {
+		return AsyncStatusResponse{}, fmt.Errorf("polling import status: transformer returned status code %d", statusCode)
+	}
+	var asyncResponse AsyncStatusResponse
+	if err := json.Unmarshal(bodyBytes, &asyncResponse); err != nil {
+		return AsyncStatusResponse{}, fmt.Errorf("unmarshalling poll response: %w", err)
+	}
+	return asyncResponse, nil
+}
+
+func (m *transformerManager) GetFailedEvents(destination *backendconfig.DestinationT, importingJobs []*jobsdb.JobT, parameters stdjson.RawMessage, status AsyncStatusResponse) (AsyncFailedEvents, error) {
+	importId := gjson.GetBytes(parameters, "importId").String()
+	csvHeaders := gjson.GetBytes(parameters, "metadata.csvHeader").String()
+	payload := GenerateFailedPayload(destination.Config, importingJobs, importId, m.destType, csvHeaders)
+	failedBodyBytes, statusCode := misc.HTTPCallWithRetryWithTimeout(m.transformerURL+status.FailedJobsURL, payload, HTTPTimeout)
+	if statusCode != 200 {
+		return AsyncFailedEvents{}, fmt.Errorf("fetching failed jobs: transformer returned status code %d", statusCode)
+	}
+	var failedJobsResponse map[string]interface{}
+	if err := json.Unmarshal(failedBodyBytes, &failedJobsResponse); err != nil {
+		return AsyncFailedEvents{}, fmt.Errorf("unmarshalling failed jobs response: %w", err)
+	}
+	internalStatusCode, ok := failedJobsResponse["status"].(string)
+	if internalStatusCode != "200" || !ok {
+		return AsyncFailedEvents{}, fmt.Errorf("fetching failed jobs: status %v and body %s", internalStatusCode, string(failedBodyBytes))
+	}
+	metadata, ok := failedJobsResponse["metadata"].(map[string]interface{})
+	if !ok {
+		return AsyncFailedEvents{}, fmt.Errorf("fetching failed jobs: invalid metadata in body %s", string(failedBodyBytes))
+	}
+	failedKeys, errFailed := misc.ConvertStringInterfaceToIntArray(metadata["failedKeys"])
+	warningKeys, errWarning := misc.ConvertStringInterfaceToIntArray(metadata["warningKeys"])
+	succeededKeys, errSuccess := misc.ConvertStringInterfaceToIntArray(metadata["succeededKeys"])
+	if errFailed != nil || errWarning != nil || errSuccess != nil {
+		// all importing jobs are retried
+		return AsyncFailedEvents{}, nil
+	}
+	failedEvents := AsyncFailedEvents{
+		SucceededJobIDs: append(succeededKeys, warningKeys...),
+		AbortedJobIDs:   failedKeys,
+		AbortedReasons:  make(map[int64]string, len(failedKeys)),
+	}
+	for _, jobID := range failedKeys {
+		failedEvents.AbortedReasons[jobID] = gjson.GetBytes(failedBodyBytes, fmt.Sprintf("metadata.failedReasons.%v", jobID)).String()
+	}
+	return failedEvents, nil
+}
+
+func (m *transformerManager) resolveURL(relative string) (string, error) {
+	baseURL, err := url.Parse(m.transformerURL)
+	if err != nil {
+		return "", fmt.Errorf("parsing transformer url: %w", err)
+	}
+	relURL, err := url.Parse(relative)
+	if err != nil {
+		return "", fmt.Errorf("parsing upload url: %w", err)
+	}
+	return baseURL.ResolveReference(relURL).String(), nil
+}
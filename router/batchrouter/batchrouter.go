@@ -5,7 +5,6 @@ import jsoniter "github.com/json-iterator/go"
 var (
 	json                    = jsoniter.ConfigCompatibleWithStandardLibrary
 	objectStoreDestinations = []string{"S3", "GCS", "AZURE_BLOB", "MINIO", "DIGITAL_OCEAN_SPACES"}
-	asyncDestinations       = []string{"MARKETO_BULK_UPLOAD"}
 	dateFormatLayouts       = map[string]string{
 		"01-02-2006": "MM-DD-YYYY",
 		"2006-01-02": "YYYY-MM-DD",
@@ -1,13 +1,18 @@
 package batchrouter
 
 import (
+	"compress/gzip"
 	"context"
+	"encoding/csv"
 	jsonb "encoding/json"
 	"errors"
 	"fmt"
 	"io"
 	"net/http"
 	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
 	"sync"
 	"testing"
 	"time"
@@ -18,17 +23,23 @@ import (
 	"github.com/google/uuid"
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
+	"github.com/samber/lo"
 	"github.com/stretchr/testify/require"
 
 	"github.com/rudderlabs/rudder-go-kit/config"
 	"github.com/rudderlabs/rudder-go-kit/filemanager"
 	"github.com/rudderlabs/rudder-go-kit/filemanager/mock_filemanager"
 	"github.com/rudderlabs/rudder-go-kit/logger"
+	"github.com/rudderlabs/rudder-go-kit/stats"
+	kitsync "github.com/rudderlabs/rudder-go-kit/sync"
 	"github.com/rudderlabs/rudder-server/admin"
 	backendconfig "github.com/rudderlabs/rudder-server/backend-config"
 	"github.com/rudderlabs/rudder-server/jobsdb"
 	mocksBackendConfig "github.com/rudderlabs/rudder-server/mocks/backend-config"
 	mocksJobsDB "github.com/rudderlabs/rudder-server/mocks/jobsdb"
+	"github.com/rudderlabs/rudder-server/router/batchrouter/internal/flushpolicy"
+	"github.com/rudderlabs/rudder-server/router/batchrouter/internal/outputformat"
+	"github.com/rudderlabs/rudder-server/router/batchrouter/isolation"
 	router_utils "github.com/rudderlabs/rudder-server/router/utils"
 	"github.com/rudderlabs/rudder-server/services/rsources"
 	"github.com/rudderlabs/rudder-server/services/transientsource"
@@ -385,3 +396,370 @@ func TestPostToWarehouse(t *testing.T) {
 		})
 	}
 }
+
+func TestUploadPartitions(t *testing.T) {
+	misc.Init()
+	ctrl := gomock.NewController(t)
+	mockJobsDB := mocksJobsDB.NewMockJobsDB(ctrl)
+	mockFileManager := mock_filemanager.NewMockFileManager(ctrl)
+
+	brt := &Handle{
+		destType:                 "S3",
+		logger:                   logger.NOP,
+		jobsDB:                   mockJobsDB,
+		fileManagerFactory:       func(*filemanager.Settings) (filemanager.FileManager, error) { return mockFileManager, nil },
+		datePrefixOverride:       "YYYY-MM-DD",
+		uploadedRawDataJobsCache: map[string]map[string]bool{},
+		jobsDBCommandTimeout:     time.Minute,
+		jobdDBMaxRetries:         1,
+		rsourcesService:          rsources.NewNoOpService(),
+	}
+	newJob := func(jobID int64, messageID, eventType string) *jobsdb.JobT {
+		return &jobsdb.JobT{JobID: jobID, EventPayload: []byte(fmt.Sprintf(`{"messageId":%q,"type":%q,"receivedAt":"2023-06-05T12:00:00.000Z"}`, messageID, eventType))}
+	}
+	batchJobs := &BatchedJobs{
+		Jobs: []*jobsdb.JobT{newJob(1, "m1", "track"), newJob(2, "m2", "identify"), newJob(3, "m3", "track")},
+		Connection: &Connection{
+			Source: backendconfig.SourceT{ID: "source-1"},
+			Destination: backendconfig.DestinationT{
+				ID:     "destination-1",
+				Config: map[string]interface{}{"pathTemplate": "{{.SourceID}}/type={{.EventType}}/hour={{.Hour}}"},
+			},
+		},
+	}
+
+	var opID int64
+	mockJobsDB.EXPECT().JournalMarkStart(jobsdb.RawDataDestUploadOperation, gomock.Any()).DoAndReturn(func(string, jsonb.RawMessage) (int64, error) {
+		opID++
+		return opID, nil
+	}).AnyTimes()
+
+	// the identify partition fails on the first attempt
+	uploads := map[string]int{}
+	identifyFailed := false
+	mockFileManager.EXPECT().Upload(gomock.Any(), gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, f *os.File, prefixes ...string) (filemanager.UploadedFile, error) {
+		key := strings.Join(prefixes, "/")
+		if strings.HasSuffix(f.Name(), manifestExtension) {
+			return filemanager.UploadedFile{Location: key, ObjectName: key}, nil
+		}
+		lines, err := outputformat.MessageIDs(outputformat.JSON, f.Name())
+		require.NoError(t, err)
+		if key == "source-1/type=identify/hour=12" && !identifyFailed {
+			identifyFailed = true
+			return filemanager.UploadedFile{}, errors.New("upload failed")
+		}
+		uploads[key] += len(lines)
+		return filemanager.UploadedFile{Location: key, ObjectName: key}, nil
+	}).AnyTimes()
+
+	// the jobs of the uploaded partition are marked as succeeded and its journal entry is deleted right away
+	mockJobsDB.EXPECT().WithUpdateSafeTx(gomock.Any(), gomock.Any()).Times(1).DoAndReturn(func(ctx context.Context, f func(tx jobsdb.UpdateSafeTx) error) error {
+		return f(jobsdb.EmptyUpdateSafeTx())
+	})
+	mockJobsDB.EXPECT().UpdateJobStatusInTx(gomock.Any(), gomock.Any(), gomock.Any(), []string{"S3"}, gomock.Any()).Times(1).
+		DoAndReturn(func(_ context.Context, _ jobsdb.UpdateSafeTx, statuses []*jobsdb.JobStatusT, _ []string, _ []jobsdb.ParameterFilterT) error {
+			require.Equal(t, []int64{1, 3}, lo.Map(statuses, func(status *jobsdb.JobStatusT, _ int) int64 { return status.JobID }))
+			for _, status := range statuses {
+				require.Equal(t, jobsdb.Succeeded.State, status.JobState)
+			}
+			return nil
+		})
+	mockJobsDB.EXPECT().JournalDeleteEntry(int64(1)).Times(1)
+
+	output := brt.upload("S3", batchJobs, false)
+	require.Error(t, output.Error)
+	require.Equal(t, []int64{2}, output.JournalOpIDs)
+	require.Equal(t, []string{"source-1/type=track/hour=12"}, output.Keys)
+	require.Equal(t, map[string]int{"source-1/type=track/hour=12": 2}, uploads)
+	require.Equal(t, []int64{2}, lo.Map(batchJobs.Jobs, func(job *jobsdb.JobT, _ int) int64 { return job.JobID }), "only the jobs of the failed partition should be retried")
+	misc.RemoveFilePaths(output.LocalFilePaths...)
+
+	output = brt.upload("S3", batchJobs, false)
+	require.NoError(t, output.Error)
+	require.Equal(t, map[string]int{"source-1/type=track/hour=12": 2, "source-1/type=identify/hour=12": 1}, uploads, "uploaded partitions shouldn't be uploaded again")
+	require.Equal(t, 1, output.TotalEvents)
+	require.Equal(t, []string{"source-1/type=identify/hour=12"}, output.Keys)
+	misc.RemoveFilePaths(output.LocalFilePaths...)
+
+	t.Run("every uploaded partition is reported", func(t *testing.T) {
+		batchJobs.Jobs = []*jobsdb.JobT{newJob(4, "m4", "track"), newJob(5, "m5", "identify")}
+		output := brt.upload("S3", batchJobs, false)
+		require.NoError(t, output.Error)
+		require.Equal(t, []string{"source-1/type=track/hour=12", "source-1/type=identify/hour=12"}, output.Keys)
+		require.Equal(t, output.Keys, output.FileLocations)
+		require.Equal(t, []int64{4, 5}, output.JournalOpIDs)
+		require.Equal(t, 2, output.TotalEvents)
+		misc.RemoveFilePaths(output.LocalFilePaths...)
+	})
+}
+
+func TestGetWorkerJobsFlushPolicy(t *testing.T) {
+	misc.Init()
+	ctrl := gomock.NewController(t)
+	mockJobsDB := mocksJobsDB.NewMockJobsDB(ctrl)
+	ctx, cancel := context.WithCancel(context.Background())
+	var limiterGroup sync.WaitGroup
+	defer func() {
+		cancel()
+		limiterGroup.Wait()
+	}()
+	isolationStrategy, err := isolation.GetStrategy(isolation.ModeNone, "S3", func(string) bool { return true })
+	require.NoError(t, err)
+
+	brt := &Handle{
+		destType:                  "S3",
+		logger:                    logger.NOP,
+		jobsDB:                    mockJobsDB,
+		isolationStrategy:         isolationStrategy,
+		adaptiveLimit:             func(limit int64) int64 { return limit },
+		jobQueryBatchSize:         5,
+		jobdDBQueryRequestTimeout: time.Minute,
+		jobdDBMaxRetries:          1,
+		flushMaxAge:               time.Hour,
+		lastExecTimes:             map[string]time.Time{},
+	}
+	brt.limiter.read = kitsync.NewLimiter(ctx, &limiterGroup, "brt_read", 1, stats.Default)
+	// 45 bytes per job as json, 6 bytes per job as csv
+	jsonDestination := backendconfig.DestinationT{ID: "dest-json", Config: map[string]interface{}{"flushTargetSize": float64(50)}}
+	csvDestination := backendconfig.DestinationT{ID: "dest-csv", Config: map[string]interface{}{"flushTargetSize": float64(50), "outputFormat": "csv"}}
+	brt.destinationsMap = map[string]*router_utils.DestinationWithSources{
+		jsonDestination.ID: {Destination: jsonDestination},
+		csvDestination.ID:  {Destination: csvDestination},
+	}
+	brt.flushPolicyMap = map[string]*flushpolicy.Policy{
+		jsonDestination.ID: brt.flushPolicy(&jsonDestination),
+		csvDestination.ID:  brt.flushPolicy(&csvDestination),
+	}
+
+	newJob := func(jobID int64, destinationID string) *jobsdb.JobT {
+		return &jobsdb.JobT{
+			JobID:        jobID,
+			CreatedAt:    time.Now(),
+			EventPayload: []byte(fmt.Sprintf(`{"messageId":"m%d","properties":{"total":10}}`, jobID)),
+			Parameters:   []byte(fmt.Sprintf(`{"destination_id":%q}`, destinationID)),
+		}
+	}
+	// limits are reached, so no unprocessed jobs are queried
+	mockJobsDB.EXPECT().GetToRetry(gomock.Any(), gomock.Any()).Return(jobsdb.JobsResult{
+		Jobs:          []*jobsdb.JobT{newJob(1, "dest-json"), newJob(2, "dest-csv"), newJob(3, "dest-json"), newJob(4, "dest-csv"), newJob(5, "dest-json")},
+		LimitsReached: true,
+	}, nil)
+
+	workerJobs := brt.getWorkerJobs("")
+	require.Len(t, workerJobs, 1, "the csv destination's policy shouldn't be due even though limits are reached, as its encoded jobs are below the target size")
+	require.Equal(t, "dest-json", workerJobs[0].destWithSources.Destination.ID)
+	require.Equal(t, []int64{1, 3}, lo.Map(workerJobs[0].jobs, func(job *jobsdb.JobT, _ int) int64 { return job.JobID }), "the batch should be cut at the target size")
+}
+
+func TestCrashRecoverFromManifest(t *testing.T) {
+	misc.Init()
+	ctrl := gomock.NewController(t)
+	mockJobsDB := mocksJobsDB.NewMockJobsDB(ctrl)
+	mockFileManager := mock_filemanager.NewMockFileManager(ctrl)
+
+	brt := &Handle{
+		destType:                 "S3",
+		logger:                   logger.NOP,
+		jobsDB:                   mockJobsDB,
+		fileManagerFactory:       func(*filemanager.Settings) (filemanager.FileManager, error) { return mockFileManager, nil },
+		datePrefixOverride:       "YYYY-MM-DD",
+		uploadedRawDataJobsCache: map[string]map[string]bool{},
+		jobsDBCommandTimeout:     time.Minute,
+		jobdDBMaxRetries:         1,
+	}
+
+	// the batch router crashed after uploading the object of jobs 1 & 2 along with its manifest
+	opPayload, err := json.Marshal(&ObjectStorageDefinition{
+		Config:        map[string]interface{}{"bucketName": "bucket"},
+		Key:           "rudder-logs/source-1/2023-06-05/source-1.1-2.json.gz",
+		ManifestKey:   "_manifests/rudder-logs/source-1/2023-06-05/source-1.1-2.json.gz" + manifestExtension,
+		Provider:      "S3",
+		DestinationID: "destination-1",
+		WorkspaceID:   "workspace-1",
+	})
+	require.NoError(t, err)
+	mockJobsDB.EXPECT().GetJournalEntries(jobsdb.RawDataDestUploadOperation).Return([]jobsdb.JournalEntryT{{OpID: 1, OpPayload: opPayload}}).Times(1)
+	mockJobsDB.EXPECT().JournalDeleteEntry(int64(1)).Times(1)
+	mockFileManager.EXPECT().Download(gomock.Any(), gomock.Any(), "_manifests/rudder-logs/source-1/2023-06-05/source-1.1-2.json.gz"+manifestExtension).DoAndReturn(func(_ context.Context, f *os.File, _ string) error {
+		_, err := f.WriteString(`{"key":"rudder-logs/source-1/2023-06-05/source-1.1-2.json.gz","format":"json","jobIds":[1,2],"totalEvents":2}`)
+		return err
+	}).Times(1)
+	// the jobs of the recovered object are marked as succeeded in the jobsdb
+	mockJobsDB.EXPECT().UpdateJobStatus(gomock.Any(), gomock.Any(), []string{"S3"}, []jobsdb.ParameterFilterT{{Name: "destination_id", Value: "destination-1"}}).
+		DoAndReturn(func(_ context.Context, statuses []*jobsdb.JobStatusT, _ []string, _ []jobsdb.ParameterFilterT) error {
+			require.Len(t, statuses, 2)
+			for i, status := range statuses {
+				require.EqualValues(t, i+1, status.JobID)
+				require.Equal(t, jobsdb.Succeeded.State, status.JobState)
+				require.Equal(t, "workspace-1", status.WorkspaceId)
+			}
+			return nil
+		}).Times(1)
+	brt.crashRecover()
+
+	uploads := map[string][]string{}
+	var manifests map[string]Manifest
+	mockFileManager.EXPECT().Upload(gomock.Any(), gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, f *os.File, prefixes ...string) (filemanager.UploadedFile, error) {
+		key := strings.Join(append(prefixes, filepath.Base(f.Name())), "/")
+		if strings.HasSuffix(f.Name(), manifestExtension) {
+			data, err := os.ReadFile(f.Name())
+			require.NoError(t, err)
+			var manifest Manifest
+			require.NoError(t, json.Unmarshal(data, &manifest))
+			manifests[key] = manifest
+			return filemanager.UploadedFile{Location: key, ObjectName: key}, nil
+		}
+		messageIDs, err := outputformat.MessageIDs(outputformat.JSON, f.Name())
+		require.NoError(t, err)
+		uploads[key] = messageIDs
+		return filemanager.UploadedFile{Location: key, ObjectName: key}, nil
+	}).AnyTimes()
+
+	createdAt := time.Date(2023, 6, 5, 23, 59, 0, 0, time.UTC)
+	newJob := func(jobID int64, messageID string) *jobsdb.JobT {
+		return &jobsdb.JobT{JobID: jobID, CreatedAt: createdAt, EventPayload: []byte(fmt.Sprintf(`{"messageId":%q,"receivedAt":"2023-06-05T12:00:00.000Z"}`, messageID))}
+	}
+	batchJobs := func(config map[string]interface{}) *BatchedJobs {
+		return &BatchedJobs{
+			Jobs: []*jobsdb.JobT{newJob(3, "m3"), newJob(4, "m4")},
+			Connection: &Connection{
+				Source:      backendconfig.SourceT{ID: "source-1"},
+				Destination: backendconfig.DestinationT{ID: "destination-1", Config: config},
+			},
+		}
+	}
+
+	t.Run("exactly once uploads", func(t *testing.T) {
+		uploads, manifests = map[string][]string{}, map[string]Manifest{}
+		mockJobsDB.EXPECT().JournalMarkStart(jobsdb.RawDataDestUploadOperation, gomock.Any()).DoAndReturn(func(_ string, opPayload jsonb.RawMessage) (int64, error) {
+			var object ObjectStorageDefinition
+			require.NoError(t, json.Unmarshal(opPayload, &object))
+			require.Equal(t, "_manifests/rudder-logs/source-1/2023-06-05/source-1.3-4.json.gz"+manifestExtension, object.ManifestKey)
+			return 2, nil
+		}).Times(1)
+		output := brt.upload("S3", batchJobs(map[string]interface{}{"exactlyOnceUploads": true}), false)
+		require.NoError(t, output.Error)
+		defer misc.RemoveFilePaths(output.LocalFilePaths...)
+
+		// object keys are derived from job ids and filed under the creation date of the batch
+		key := "rudder-logs/source-1/2023-06-05/source-1.3-4.json.gz"
+		require.Equal(t, map[string][]string{key: {"m3", "m4"}}, uploads)
+		require.Equal(t, map[string]Manifest{
+			"_manifests/" + key + manifestExtension: {Key: key, Format: outputformat.JSON, JobIDs: []int64{3, 4}, TotalEvents: 2},
+		}, manifests)
+	})
+
+	t.Run("default uploads", func(t *testing.T) {
+		uploads, manifests = map[string][]string{}, map[string]Manifest{}
+		mockJobsDB.EXPECT().JournalMarkStart(jobsdb.RawDataDestUploadOperation, gomock.Any()).DoAndReturn(func(_ string, opPayload jsonb.RawMessage) (int64, error) {
+			var object ObjectStorageDefinition
+			require.NoError(t, json.Unmarshal(opPayload, &object))
+			require.Empty(t, object.ManifestKey)
+			return 3, nil
+		}).Times(1)
+		output := brt.upload("S3", batchJobs(nil), false)
+		require.NoError(t, output.Error)
+		defer misc.RemoveFilePaths(output.LocalFilePaths...)
+
+		require.Len(t, uploads, 1)
+		require.Empty(t, manifests, "manifests are only uploaded for exactly once uploads")
+		for key := range uploads {
+			require.True(t, strings.HasPrefix(key, fmt.Sprintf("rudder-logs/source-1/%s/", time.Now().Format("2006-01-02"))), key)
+			require.NotContains(t, key, "source-1.3-4")
+		}
+	})
+}
+
+// listSession lists the provided files at once
+type listSession struct {
+	files []*filemanager.FileInfo
+	done  bool
+}
+
+func (s *listSession) Next() ([]*filemanager.FileInfo, error) {
+	if s.done {
+		return nil, nil
+	}
+	s.done = true
+	return s.files, nil
+}
+
+func TestOutputSchemaPersistence(t *testing.T) {
+	misc.Init()
+	ctrl := gomock.NewController(t)
+	mockJobsDB := mocksJobsDB.NewMockJobsDB(ctrl)
+	mockFileManager := mock_filemanager.NewMockFileManager(ctrl)
+
+	const schemaKey = "rudder-logs/_schemas/destination-1.json"
+	var (
+		storedSchema  []byte
+		schemaUploads int
+		headers       [][]string
+	)
+	mockJobsDB.EXPECT().JournalMarkStart(jobsdb.RawDataDestUploadOperation, gomock.Any()).Return(int64(1), nil).AnyTimes()
+	mockFileManager.EXPECT().Prefix().Return("").AnyTimes()
+	mockFileManager.EXPECT().ListFilesWithPrefix(gomock.Any(), "", schemaKey, int64(1)).DoAndReturn(func(context.Context, string, string, int64) filemanager.ListSession {
+		if storedSchema == nil {
+			return &listSession{}
+		}
+		return &listSession{files: []*filemanager.FileInfo{{Key: schemaKey}}}
+	}).AnyTimes()
+	mockFileManager.EXPECT().Download(gomock.Any(), gomock.Any(), schemaKey).DoAndReturn(func(_ context.Context, f *os.File, _ string) error {
+		_, err := f.Write(storedSchema)
+		return err
+	}).AnyTimes()
+	mockFileManager.EXPECT().Upload(gomock.Any(), gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, f *os.File, prefixes ...string) (filemanager.UploadedFile, error) {
+		key := strings.Join(append(prefixes, filepath.Base(f.Name())), "/")
+		switch {
+		case key == schemaKey:
+			data, err := os.ReadFile(f.Name())
+			require.NoError(t, err)
+			storedSchema = data
+			schemaUploads++
+		case strings.HasSuffix(key, ".csv.gz"):
+			gzFile, err := os.Open(f.Name())
+			require.NoError(t, err)
+			defer func() { _ = gzFile.Close() }()
+			gzReader, err := gzip.NewReader(gzFile)
+			require.NoError(t, err)
+			records, err := csv.NewReader(gzReader).ReadAll()
+			require.NoError(t, err)
+			headers = append(headers, records...)
+		}
+		return filemanager.UploadedFile{Location: key, ObjectName: key}, nil
+	}).AnyTimes()
+
+	upload := func(payload string) {
+		// a new handle for every upload, as if the batch router restarted in between
+		brt := &Handle{
+			destType:                 "S3",
+			logger:                   logger.NOP,
+			jobsDB:                   mockJobsDB,
+			fileManagerFactory:       func(*filemanager.Settings) (filemanager.FileManager, error) { return mockFileManager, nil },
+			datePrefixOverride:       "YYYY-MM-DD",
+			maxOutputColumns:         100,
+			uploadedRawDataJobsCache: map[string]map[string]bool{},
+		}
+		output := brt.upload("S3", &BatchedJobs{
+			Jobs: []*jobsdb.JobT{{JobID: 1, EventPayload: []byte(payload)}},
+			Connection: &Connection{
+				Source:      backendconfig.SourceT{ID: "source-1"},
+				Destination: backendconfig.DestinationT{ID: "destination-1", Config: map[string]interface{}{"outputFormat": "csv"}},
+			},
+		}, false)
+		require.NoError(t, output.Error)
+		misc.RemoveFilePaths(output.LocalFilePaths...)
+	}
+
+	upload(`{"messageId":"m1","total":1}`)
+	require.JSONEq(t, `[{"name":"_extra","type":"json"},{"name":"messageId","type":"string"},{"name":"total","type":"float"}]`, string(storedSchema))
+
+	upload(`{"messageId":"m2","total":"unknown"}`)
+	require.Equal(t, 1, schemaUploads, "unchanged schemas aren't uploaded again")
+	require.Equal(t, [][]string{
+		{"_extra", "messageId", "total"},
+		{"", "m1", "1"},
+		{"_extra", "messageId", "total"},
+		{`{"total":"unknown"}`, "m2", ""},
+	}, headers, "the type of the persisted column is kept after a restart")
+}
@@ -7,6 +7,7 @@ import (
 	"fmt"
 	"net/http"
 	"os"
+	"path"
 	"path/filepath"
 	"sort"
 	"strconv"
@@ -30,6 +31,10 @@ import (
 	backendconfig "github.com/rudderlabs/rudder-server/backend-config"
 	"github.com/rudderlabs/rudder-server/jobsdb"
 	"github.com/rudderlabs/rudder-server/router/batchrouter/asyncdestinationmanager"
+	"github.com/rudderlabs/rudder-server/router/batchrouter/internal/flushpolicy"
+	"github.com/rudderlabs/rudder-server/router/batchrouter/internal/outputformat"
+	"github.com/rudderlabs/rudder-server/router/batchrouter/internal/pathtemplate"
+	"github.com/rudderlabs/rudder-server/router/batchrouter/internal/tableformat"
 	"github.com/rudderlabs/rudder-server/router/batchrouter/isolation"
 	"github.com/rudderlabs/rudder-server/router/rterror"
 	router_utils "github.com/rudderlabs/rudder-server/router/utils"
@@ -85,9 +90,10 @@ type Handle struct {
 	disableEgress                bool
 	toAbortDestinationIDs        string
 	warehouseServiceMaxRetryTime time.Duration
-	transformerURL               string
 	datePrefixOverride           string
 	customDatePrefix             string
+	maxOutputColumns             int
+	flushMaxAge                  time.Duration
 
 	// state
 
@@ -103,6 +109,7 @@ type Handle struct {
 	destinationsMap          map[string]*router_utils.DestinationWithSources // destinationID -> destination
 	connectionWHNamespaceMap map[string]string                               // connectionIdentifier -> warehouseConnectionIdentifier(+namepsace)
 	uploadIntervalMap        map[string]time.Duration
+	flushPolicyMap           map[string]*flushpolicy.Policy
 
 	encounteredMergeRuleMapMu sync.Mutex
 	encounteredMergeRuleMap   map[string]map[string]bool
@@ -116,6 +123,9 @@ type Handle struct {
 	lastExecTimesMu sync.RWMutex
 	lastExecTimes   map[string]time.Time
 
+	tablesMu sync.Mutex
+	tables   map[string]*tableformat.Table // destinationID -> table the destination's files are committed into
+
 	batchRequestsMetricMu sync.RWMutex
 	batchRequestsMetric   []batchRequestMetric
 
@@ -124,9 +134,13 @@ type Handle struct {
 
 	dateFormatProvider *storageDateFormatProvider
 
-	diagnosisTicker          *time.Ticker
-	uploadedRawDataJobsCache map[string]map[string]bool
-	asyncDestinationStruct   map[string]*asyncdestinationmanager.AsyncDestinationStruct
+	diagnosisTicker *time.Ticker
+
+	uploadedRawDataJobsCacheMu sync.RWMutex
+	uploadedRawDataJobsCache   map[string]map[string]bool // destinationID -> messageID -> uploaded
+
+	asyncDestinationStruct  map[string]*asyncdestinationmanager.AsyncDestinationStruct
+	asyncDestinationManager asyncdestinationmanager.AsyncDestinationManager
 
 	asyncPollTimeStat       stats.Measurement
 	asyncFailedJobsTimeStat stats.Measurement
@@ -227,8 +241,18 @@ func (brt *Handle) getWorkerJobs(partition string) (workerJobs []*DestinationJob
 	jobsByDesID := lo.GroupBy(jobs, func(job *jobsdb.JobT) string {
 		return gjson.GetBytes(job.Parameters, "destination_id").String()
 	})
+	brt.configSubscriberMu.RLock()
+	flushPolicyMap := brt.flushPolicyMap
+	brt.configSubscriberMu.RUnlock()
 	for destID, destJobs := range jobsByDesID {
 		if batchDest, ok := destinationsMap[destID]; ok {
+			if policy := flushPolicyMap[destID]; policy != nil {
+				// jobs of destinations with a flush policy are left pending until the policy is due, regardless of limits being reached
+				if policy.Due(destJobs, time.Now()) {
+					workerJobs = append(workerJobs, &DestinationJobs{destWithSources: *batchDest, jobs: policy.Batch(destJobs)})
+				}
+				continue
+			}
 			var processJobs bool
 			brt.lastExecTimesMu.Lock()
 			if limitsReached && !brt.forceHonorUploadFrequency { // if limits are reached, process all jobs regardless of their upload frequency
@@ -252,12 +276,58 @@ func (brt *Handle) getWorkerJobs(partition string) (workerJobs []*DestinationJob
 	return
 }
 
+// flushPolicy returns the flush policy of the destination, if any, sizing its jobs in the destination's output format
+func (brt *Handle) flushPolicy(destination *backendconfig.DestinationT) *flushpolicy.Policy {
+	policy, err := flushpolicy.FromDestinationConfig(destination.Config, brt.flushMaxAge)
+	if err != nil {
+		brt.logger.Errorf("BRT: %s: Invalid flush policy for destination %s, falling back to the upload frequency: %v", brt.destType, destination.ID, err)
+	}
+	if policy != nil {
+		policy.Size = brt.outputFormat(destination).EncodedSize
+	}
+	return policy
+}
+
+// outputFormat returns the format of the files the jobs of the destination are uploaded in, see uploadObject
+func (brt *Handle) outputFormat(destination *backendconfig.DestinationT) outputformat.Format {
+	if slices.Contains(warehouseutils.WarehouseDestinations, brt.destType) {
+		return outputformat.JSON
+	}
+	if format, err := tableformat.FromDestinationConfig(destination.Config); err == nil && format != tableformat.None {
+		return outputformat.Parquet
+	}
+	format, _ := outputformat.FromDestinationConfig(destination.Config)
+	return format
+}
+
 // upload the given batch of jobs to the given object storage provider
 func (brt *Handle) upload(provider string, batchJobs *BatchedJobs, isWarehouse bool) UploadResult {
 	if brt.disableEgress {
 		return UploadResult{Error: rterror.DisabledEgress}
 	}
+	if !isWarehouse {
+		table, err := brt.table(provider, &batchJobs.Connection.Destination)
+		if err != nil {
+			brt.logger.Errorf("BRT: Invalid table format for destination %s, falling back to plain objects: %v", batchJobs.Connection.Destination.ID, err)
+		}
+		if table != nil {
+			return brt.uploadObject(provider, batchJobs, false, nil, table)
+		}
+		pathTemplate, err := pathtemplate.FromDestinationConfig(batchJobs.Connection.Destination.Config)
+		if err != nil {
+			brt.logger.Errorf("BRT: Invalid path template for destination %s, falling back to the default layout: %v", batchJobs.Connection.Destination.ID, err)
+		}
+		if pathTemplate != nil {
+			return brt.uploadPartitions(provider, batchJobs, pathTemplate)
+		}
+	}
+	return brt.uploadObject(provider, batchJobs, isWarehouse, nil, nil)
+}
 
+// uploadObject uploads the given batch of jobs as a single object, under the provided key prefixes or,
+// if none are provided, under the default <folder>/<sourceID>/<date> layout.
+// If a table is provided, the object is uploaded as a data file of the table and committed into it.
+func (brt *Handle) uploadObject(provider string, batchJobs *BatchedJobs, isWarehouse bool, keyPrefixes []string, table *tableformat.Table) UploadResult {
 	var localTmpDirName string
 	if isWarehouse {
 		localTmpDirName = fmt.Sprintf(`/%s/`, misc.RudderWarehouseStagingUploads)
@@ -272,14 +342,63 @@ func (brt *Handle) upload(provider string, batchJobs *BatchedJobs, isWarehouse b
 	if err != nil {
 		panic(err)
 	}
-	path := fmt.Sprintf("%v%v.json", tmpDirPath+localTmpDirName, fmt.Sprintf("%v.%v.%v", time.Now().Unix(), batchJobs.Connection.Source.ID, uuid))
-
-	gzipFilePath := fmt.Sprintf(`%v.gz`, path)
-	err = os.MkdirAll(filepath.Dir(gzipFilePath), os.ModePerm)
+	format := outputformat.JSON
+	if !isWarehouse {
+		if format, err = outputformat.FromDestinationConfig(batchJobs.Connection.Destination.Config); err != nil {
+			brt.logger.Errorf("BRT: Invalid output format for destination %s, falling back to json: %v", batchJobs.Connection.Destination.ID, err)
+		}
+	}
+	if table != nil {
+		// tables consist of parquet files
+		format = outputformat.Parquet
+		keyPrefixes = table.DataPrefixes()
+	}
+	// tables rely on manifests for not committing the same jobs twice
+	exactlyOnce := !isWarehouse && (table != nil || exactlyOnceUploads(&batchJobs.Connection.Destination))
+	fileName := fmt.Sprintf("%v.%v.%v", time.Now().Unix(), batchJobs.Connection.Source.ID, uuid)
+	if exactlyOnce {
+		// object keys are derived from the job id range, so that retries of a batch overwrite the same object
+		firstJobID, lastJobID := jobIDRange(batchJobs.Jobs)
+		fileName = fmt.Sprintf("%v.%v-%v", batchJobs.Connection.Source.ID, firstJobID, lastJobID)
+	}
+	filePath := fmt.Sprintf("%v%v%v", tmpDirPath+localTmpDirName, fileName, format.Extension())
+	err = os.MkdirAll(filepath.Dir(filePath), os.ModePerm)
 	if err != nil {
 		panic(err)
 	}
-	gzWriter, err := misc.CreateGZ(gzipFilePath)
+	// a file of a previous attempt of the same batch might have been left behind
+	if err := os.Remove(filePath); err != nil && !os.IsNotExist(err) {
+		panic(err)
+	}
+	useRudderStorage := isWarehouse && misc.IsConfiguredToUseRudderObjectStorage(batchJobs.Connection.Destination.Config)
+	uploader, err := brt.fileManagerFactory(&filemanager.Settings{
+		Provider: provider,
+		Config: misc.GetObjectStorageConfig(misc.ObjectStorageOptsT{
+			Provider:         provider,
+			Config:           batchJobs.Connection.Destination.Config,
+			UseRudderStorage: useRudderStorage,
+			WorkspaceID:      batchJobs.Connection.Destination.WorkspaceID,
+		}),
+	})
+	if err != nil {
+		return UploadResult{Error: err}
+	}
+	var (
+		schema           *outputformat.Schema
+		persistedColumns int
+	)
+	if table != nil {
+		if schema, err = table.Schema(context.TODO(), uploader); err != nil {
+			brt.logger.Errorf("BRT: Error loading table of destination %s: %v", batchJobs.Connection.Destination.ID, err)
+			return UploadResult{Error: err}
+		}
+	} else if format != outputformat.JSON {
+		if schema, persistedColumns, err = brt.outputSchema(context.TODO(), uploader, batchJobs.Connection.Destination.ID); err != nil {
+			brt.logger.Errorf("BRT: Error loading output schema of destination %s: %v", batchJobs.Connection.Destination.ID, err)
+			return UploadResult{Error: err}
+		}
+	}
+	writer, err := outputformat.NewWriter(format, filePath, brt.destType, schema)
 	if err != nil {
 		panic(err)
 	}
@@ -291,6 +410,8 @@ func (brt *Handle) upload(provider string, batchJobs *BatchedJobs, isWarehouse b
 	warehouseConnIdentifier := brt.connectionWHNamespaceMap[connIdentifier]
 	brt.configSubscriberMu.RUnlock()
 	var totalBytes int
+	var writeErr error
+	var writtenJobIDs []int64
 	for _, job := range batchJobs.Jobs {
 		// do not add to staging file if the event is a rudder_identity_merge_rules record
 		// and has been previously added to it
@@ -311,28 +432,30 @@ func (brt *Handle) upload(provider string, batchJobs *BatchedJobs, isWarehouse b
 			brt.encounteredMergeRuleMapMu.Unlock()
 		}
 
-		eventID := gjson.GetBytes(job.EventPayload, "messageId").String()
-		var ok bool
-		interruptedEventsMap, isDestInterrupted := brt.uploadedRawDataJobsCache[batchJobs.Connection.Destination.ID]
-		if isDestInterrupted {
-			if _, ok = interruptedEventsMap[eventID]; !ok {
-				eventsFound = true
-				line := string(job.EventPayload) + "\n"
-				totalBytes += len(line)
-				_ = gzWriter.WriteGZ(line)
-			}
-		} else {
-			eventsFound = true
-			line := string(job.EventPayload) + "\n"
-			totalBytes += len(line)
-			_ = gzWriter.WriteGZ(line)
+		if brt.isUploadedRawDataJob(batchJobs.Connection.Destination.ID, job) {
+			continue
+		}
+		eventsFound = true
+		writtenJobIDs = append(writtenJobIDs, job.JobID)
+		totalBytes += len(job.EventPayload) + 1
+		if err := writer.Write(job.EventPayload); err != nil && writeErr == nil {
+			writeErr = err
 		}
 	}
-	_ = gzWriter.CloseGZ()
+	if err := writer.Close(); err != nil && writeErr == nil {
+		writeErr = err
+	}
 	if !eventsFound {
 		brt.logger.Infof("BRT: No events in this batch for upload to %s. Events are either de-deuplicated or skipped", provider)
 		return UploadResult{
-			LocalFilePaths: []string{gzipFilePath},
+			LocalFilePaths: []string{filePath},
+		}
+	}
+	if writeErr != nil {
+		brt.logger.Errorf("BRT: Error writing %s file for upload to %s: %v", format, provider, writeErr)
+		return UploadResult{
+			Error:          writeErr,
+			LocalFilePaths: []string{filePath},
 		}
 	}
 	// assumes events from warehouse have receivedAt in metadata
@@ -360,67 +483,52 @@ func (brt *Handle) upload(provider string, batchJobs *BatchedJobs, isWarehouse b
 		lastEventAt = gjson.GetBytes(batchJobs.Jobs[len(batchJobs.Jobs)-1].EventPayload, "receivedAt").String()
 	}
 
-	brt.logger.Debugf("BRT: Logged to local file: %v", gzipFilePath)
-	useRudderStorage := isWarehouse && misc.IsConfiguredToUseRudderObjectStorage(batchJobs.Connection.Destination.Config)
-	uploader, err := brt.fileManagerFactory(&filemanager.Settings{
-		Provider: provider,
-		Config: misc.GetObjectStorageConfig(misc.ObjectStorageOptsT{
-			Provider:         provider,
-			Config:           batchJobs.Connection.Destination.Config,
-			UseRudderStorage: useRudderStorage,
-			WorkspaceID:      batchJobs.Connection.Destination.WorkspaceID,
-		}),
-	})
-	if err != nil {
-		return UploadResult{
-			Error:          err,
-			LocalFilePaths: []string{gzipFilePath},
+	brt.logger.Debugf("BRT: Logged to local file: %v", filePath)
+
+	// the evolved schema is persisted before the file gets uploaded, so that no file has columns missing from it
+	if table == nil && schema != nil && len(schema.Columns()) != persistedColumns {
+		if err := brt.saveOutputSchema(context.TODO(), uploader, batchJobs.Connection.Destination.ID, schema); err != nil {
+			brt.logger.Errorf("BRT: Error saving output schema of destination %s: %v", batchJobs.Connection.Destination.ID, err)
+			return UploadResult{
+				Error:          err,
+				LocalFilePaths: []string{filePath},
+			}
 		}
 	}
 
-	outputFile, err := os.Open(gzipFilePath)
+	outputFile, err := os.Open(filePath)
 	if err != nil {
 		panic(err)
 	}
 
 	brt.logger.Debugf("BRT: Starting upload to %s", provider)
-	var folderName string
-	if isWarehouse {
-		folderName = config.GetString("WAREHOUSE_STAGING_BUCKET_FOLDER_NAME", "rudder-warehouse-staging-logs")
-	} else {
-		folderName = config.GetString("DESTINATION_BUCKET_FOLDER_NAME", "rudder-logs")
-	}
-
-	var datePrefixLayout string
-	if brt.datePrefixOverride != "" {
-		datePrefixLayout = brt.datePrefixOverride
-	} else {
-		dateFormat, _ := brt.dateFormatProvider.GetFormat(brt.logger, uploader, batchJobs.Connection, folderName)
-		datePrefixLayout = dateFormat
+	if keyPrefixes == nil {
+		folderName := brt.folderName(isWarehouse)
+		keyPrefixes = []string{folderName, batchJobs.Connection.Source.ID, brt.datePrefix(uploader, batchJobs.Connection, folderName, uploadTime(batchJobs, exactlyOnce))}
 	}
 
-	brt.logger.Debugf("BRT: Date prefix layout is %s", datePrefixLayout)
-	switch datePrefixLayout {
-	case "MM-DD-YYYY": // used to be earlier default
-		datePrefixLayout = time.Now().Format("01-02-2006")
-	default:
-		datePrefixLayout = time.Now().Format("2006-01-02")
-	}
-	keyPrefixes := []string{folderName, batchJobs.Connection.Source.ID, brt.customDatePrefix + datePrefixLayout}
-
-	_, fileName := filepath.Split(gzipFilePath)
+	_, fileName = filepath.Split(filePath)
+	keyPrefix := strings.Join(keyPrefixes, "/")
 	var (
 		opID      int64
 		opPayload stdjson.RawMessage
 	)
+	manifestPrefixes := append([]string{manifestFolder}, keyPrefixes...)
 	if !isWarehouse {
-		opPayload, _ = json.Marshal(&ObjectStorageDefinition{
+		object := ObjectStorageDefinition{
 			Config:          batchJobs.Connection.Destination.Config,
-			Key:             strings.Join(append(keyPrefixes, fileName), "/"),
+			Key:             keyPrefix + "/" + fileName,
 			Provider:        provider,
 			DestinationID:   batchJobs.Connection.Destination.ID,
 			DestinationType: batchJobs.Connection.Destination.DestinationDefinition.Name,
-		})
+			Format:          format,
+			TableFormat:     tableFormat(table),
+			WorkspaceID:     batchJobs.Connection.Destination.WorkspaceID,
+		}
+		if exactlyOnce {
+			object.ManifestKey = strings.Join(manifestPrefixes, "/") + "/" + fileName + manifestExtension
+		}
+		opPayload, _ = json.Marshal(&object)
 		opID, err = brt.jobsDB.JournalMarkStart(jobsdb.RawDataDestUploadOperation, opPayload)
 		if err != nil {
 			panic(fmt.Errorf("BRT: Error marking start of upload operation in journal: %v", err))
@@ -441,8 +549,38 @@ func (brt *Handle) upload(provider string, batchJobs *BatchedJobs, isWarehouse b
 		brt.logger.Errorf("BRT: Error uploading to %s: Error: %v", provider, err)
 		return UploadResult{
 			Error:          err,
-			JournalOpID:    opID,
-			LocalFilePaths: []string{gzipFilePath},
+			JournalOpIDs:   journalOpIDs(opID),
+			LocalFilePaths: []string{filePath},
+		}
+	}
+
+	localFilePaths := []string{filePath}
+	if table != nil {
+		// the data file is committed before its manifest gets uploaded, so that the batch is retried if the commit fails
+		if err := brt.commitDataFile(uploader, table, filePath, uploadOutput.ObjectName, schema, len(writtenJobIDs)); err != nil {
+			brt.logger.Errorf("BRT: Error committing %s into %s table %s: %v", uploadOutput.ObjectName, table.Format(), table.Root(), err)
+			return UploadResult{
+				Error:          err,
+				JournalOpIDs:   journalOpIDs(opID),
+				LocalFilePaths: localFilePaths,
+			}
+		}
+	}
+	if exactlyOnce {
+		manifestPath := filePath + manifestExtension
+		localFilePaths = append(localFilePaths, manifestPath)
+		if err := brt.uploadManifest(uploader, manifestPath, manifestPrefixes, Manifest{
+			Key:         uploadOutput.ObjectName,
+			Format:      format,
+			JobIDs:      writtenJobIDs,
+			TotalEvents: len(writtenJobIDs),
+		}); err != nil {
+			brt.logger.Errorf("BRT: Error uploading manifest to %s: Error: %v", provider, err)
+			return UploadResult{
+				Error:          err,
+				JournalOpIDs:   journalOpIDs(opID),
+				LocalFilePaths: localFilePaths,
+			}
 		}
 	}
 
@@ -450,8 +588,10 @@ func (brt *Handle) upload(provider string, batchJobs *BatchedJobs, isWarehouse b
 		Config:           batchJobs.Connection.Destination.Config,
 		Key:              uploadOutput.ObjectName,
 		FileLocation:     uploadOutput.Location,
-		LocalFilePaths:   []string{gzipFilePath},
-		JournalOpID:      opID,
+		Keys:             []string{uploadOutput.ObjectName},
+		FileLocations:    []string{uploadOutput.Location},
+		LocalFilePaths:   localFilePaths,
+		JournalOpIDs:     journalOpIDs(opID),
 		FirstEventAt:     firstEventAt,
 		LastEventAt:      lastEventAt,
 		TotalEvents:      len(batchJobs.Jobs) - dedupedIDMergeRuleJobs,
@@ -460,6 +600,304 @@ func (brt *Handle) upload(provider string, batchJobs *BatchedJobs, isWarehouse b
 	}
 }
 
+// uploadPartitions splits the given batch of jobs into partitions according to the destination's path template and
+// uploads every partition as a separate object. If some partitions fail, the jobs of the partitions which got uploaded
+// are marked as succeeded and removed from the batch, so that only the failed partitions get uploaded again.
+func (brt *Handle) uploadPartitions(provider string, batchJobs *BatchedJobs, pathTemplate *pathtemplate.Template) UploadResult {
+	uploader, err := brt.fileManagerFactory(&filemanager.Settings{
+		Provider: provider,
+		Config: misc.GetObjectStorageConfig(misc.ObjectStorageOptsT{
+			Provider:    provider,
+			Config:      batchJobs.Connection.Destination.Config,
+			WorkspaceID: batchJobs.Connection.Destination.WorkspaceID,
+		}),
+	})
+	if err != nil {
+		return UploadResult{Error: err}
+	}
+	folderName := brt.folderName(false)
+	vars := pathtemplate.Vars{
+		Folder:        folderName,
+		SourceID:      batchJobs.Connection.Source.ID,
+		DestinationID: batchJobs.Connection.Destination.ID,
+		WorkspaceID:   batchJobs.Connection.Destination.WorkspaceID,
+		Date:          brt.datePrefix(uploader, batchJobs.Connection, folderName, uploadTime(batchJobs, exactlyOnceUploads(&batchJobs.Connection.Destination))),
+	}
+
+	var keys []string
+	partitions := map[string]*BatchedJobs{}
+	for _, job := range batchJobs.Jobs {
+		keyPrefixes, err := pathTemplate.Render(vars, job.EventPayload)
+		if err != nil {
+			return UploadResult{Error: err}
+		}
+		key := strings.Join(keyPrefixes, "/")
+		partition, ok := partitions[key]
+		if !ok {
+			partition = &BatchedJobs{Connection: batchJobs.Connection, TimeWindow: batchJobs.TimeWindow}
+			partitions[key] = partition
+			keys = append(keys, key)
+		}
+		partition.Jobs = append(partition.Jobs, job)
+	}
+
+	result := UploadResult{
+		Config:       batchJobs.Connection.Destination.Config,
+		FirstEventAt: gjson.GetBytes(batchJobs.Jobs[0].EventPayload, "receivedAt").String(),
+		LastEventAt:  gjson.GetBytes(batchJobs.Jobs[len(batchJobs.Jobs)-1].EventPayload, "receivedAt").String(),
+	}
+	var (
+		uploaded    []*jobsdb.JobT
+		uploadedOps []int64
+	)
+	for _, key := range keys {
+		output := brt.uploadObject(provider, partitions[key], false, strings.Split(key, "/"), nil)
+		result.LocalFilePaths = append(result.LocalFilePaths, output.LocalFilePaths...)
+		if output.Error != nil {
+			result.JournalOpIDs = append(result.JournalOpIDs, output.JournalOpIDs...)
+			if result.Error == nil {
+				result.Error = output.Error
+			}
+			continue
+		}
+		uploaded = append(uploaded, partitions[key].Jobs...)
+		uploadedOps = append(uploadedOps, output.JournalOpIDs...)
+		result.Keys = append(result.Keys, output.Keys...)
+		result.FileLocations = append(result.FileLocations, output.FileLocations...)
+		result.TotalEvents += output.TotalEvents
+		result.TotalBytes += output.TotalBytes
+	}
+	if len(result.Keys) == 1 {
+		result.Key, result.FileLocation = result.Keys[0], result.FileLocations[0]
+	}
+	if result.Error == nil {
+		result.JournalOpIDs = append(result.JournalOpIDs, uploadedOps...)
+		return result
+	}
+	if len(uploaded) > 0 {
+		brt.updateJobStatus(&BatchedJobs{Connection: batchJobs.Connection, TimeWindow: batchJobs.TimeWindow, Jobs: uploaded}, false, nil, false)
+		for _, opID := range uploadedOps {
+			brt.jobsDB.JournalDeleteEntry(opID)
+		}
+		uploadedJobIDs := lo.SliceToMap(uploaded, func(job *jobsdb.JobT) (int64, struct{}) { return job.JobID, struct{}{} })
+		batchJobs.Jobs = lo.Filter(batchJobs.Jobs, func(job *jobsdb.JobT, _ int) bool {
+			_, ok := uploadedJobIDs[job.JobID]
+			return !ok
+		})
+	}
+	return result
+}
+
+// isUploadedRawDataJob returns true if the event of the job has already been uploaded to the destination
+func (brt *Handle) isUploadedRawDataJob(destinationID string, job *jobsdb.JobT) bool {
+	brt.uploadedRawDataJobsCacheMu.RLock()
+	defer brt.uploadedRawDataJobsCacheMu.RUnlock()
+	return brt.uploadedRawDataJobsCache[destinationID][gjson.GetBytes(job.EventPayload, "messageId").String()]
+}
+
+// uploadManifest uploads the manifest of an object under the manifest folder
+func (*Handle) uploadManifest(uploader filemanager.FileManager, manifestPath string, keyPrefixes []string, manifest Manifest) error {
+	data, err := json.Marshal(manifest)
+	if err != nil {
+		return fmt.Errorf("marshalling manifest: %w", err)
+	}
+	if err := os.WriteFile(manifestPath, data, 0o600); err != nil {
+		return fmt.Errorf("writing manifest: %w", err)
+	}
+	f, err := os.Open(manifestPath)
+	if err != nil {
+		return fmt.Errorf("opening manifest: %w", err)
+	}
+	defer func() { _ = f.Close() }()
+	if _, err := uploader.Upload(context.TODO(), f, keyPrefixes...); err != nil {
+		return fmt.Errorf("uploading manifest: %w", err)
+	}
+	return nil
+}
+
+// jobIDRange returns the lowest and highest job ids of the jobs
+func jobIDRange(jobs []*jobsdb.JobT) (first, last int64) {
+	for i, job := range jobs {
+		if i == 0 || job.JobID < first {
+			first = job.JobID
+		}
+		if job.JobID > last {
+			last = job.JobID
+		}
+	}
+	return first, last
+}
+
+// folderName returns the root folder of the uploaded objects
+func (*Handle) folderName(isWarehouse bool) string {
+	if isWarehouse {
+		return config.GetString("WAREHOUSE_STAGING_BUCKET_FOLDER_NAME", "rudder-warehouse-staging-logs")
+	}
+	return config.GetString("DESTINATION_BUCKET_FOLDER_NAME", "rudder-logs")
+}
+
+// exactlyOnceUploads returns true if the destination is configured for uploading every job exactly once,
+// i.e. with object keys derived from the jobs of the batch and manifests listing the jobs of every object
+func exactlyOnceUploads(destination *backendconfig.DestinationT) bool {
+	enabled, _ := destination.Config["exactlyOnceUploads"].(bool)
+	return enabled
+}
+
+// uploadTime returns the time the objects of the batch are filed under. Exactly once uploads use the creation time of
+// the batch's first job, so that the retries of a batch overwrite the same objects, even across days.
+func uploadTime(batchJobs *BatchedJobs, exactlyOnce bool) time.Time {
+	if !exactlyOnce || len(batchJobs.Jobs) == 0 {
+		return time.Now()
+	}
+	first := lo.MinBy(batchJobs.Jobs, func(a, b *jobsdb.JobT) bool { return a.JobID < b.JobID })
+	return first.CreatedAt
+}
+
+// datePrefix returns the date folder of the objects uploaded at the provided time
+func (brt *Handle) datePrefix(uploader filemanager.FileManager, connection *Connection, folderName string, at time.Time) string {
+	var datePrefixLayout string
+	if brt.datePrefixOverride != "" {
+		datePrefixLayout = brt.datePrefixOverride
+	} else {
+		dateFormat, _ := brt.dateFormatProvider.GetFormat(brt.logger, uploader, connection, folderName)
+		datePrefixLayout = dateFormat
+	}
+
+	brt.logger.Debugf("BRT: Date prefix layout is %s", datePrefixLayout)
+	switch datePrefixLayout {
+	case "MM-DD-YYYY": // used to be earlier default
+		datePrefixLayout = at.Format("01-02-2006")
+	default:
+		datePrefixLayout = at.Format("2006-01-02")
+	}
+	return brt.customDatePrefix + datePrefixLayout
+}
+
+func journalOpIDs(opID int64) []int64 {
+	if opID > 0 {
+		return []int64{opID}
+	}
+	return nil
+}
+
+// outputSchemaFolder is the folder, under the destination folder, holding the schemas of the destinations' columnar files.
+// Its name starts with an underscore, so that it is ignored by readers of the destination folder (e.g. Athena, Spark).
+const outputSchemaFolder = "_schemas"
+
+// outputSchema loads the schema of the destination's columnar files from the object storage, along with the number of
+// its persisted columns. The schema is loaded for every upload, so that column typing stays consistent across restarts
+// and across the instances uploading for the destination.
+func (brt *Handle) outputSchema(ctx context.Context, uploader filemanager.FileManager, destinationID string) (*outputformat.Schema, int, error) {
+	schema := outputformat.NewSchema(brt.maxOutputColumns)
+	key := brt.outputSchemaKey(uploader, destinationID)
+	files, err := uploader.ListFilesWithPrefix(ctx, "", key, 1).Next()
+	if err != nil {
+		return nil, 0, fmt.Errorf("listing output schema %s: %w", key, err)
+	}
+	if !lo.ContainsBy(files, func(f *filemanager.FileInfo) bool { return f.Key == key }) {
+		return schema, len(schema.Columns()), nil
+	}
+	dir, err := os.MkdirTemp("", "rudder-output-schema")
+	if err != nil {
+		return nil, 0, fmt.Errorf("creating temporary directory: %w", err)
+	}
+	defer func() { _ = os.RemoveAll(dir) }()
+	f, err := os.Create(filepath.Join(dir, path.Base(key)))
+	if err != nil {
+		return nil, 0, fmt.Errorf("creating output schema file: %w", err)
+	}
+	defer func() { _ = f.Close() }()
+	if err := uploader.Download(ctx, f, key); err != nil {
+		return nil, 0, fmt.Errorf("downloading output schema %s: %w", key, err)
+	}
+	data, err := os.ReadFile(f.Name())
+	if err != nil {
+		return nil, 0, fmt.Errorf("reading output schema %s: %w", key, err)
+	}
+	var columns []outputformat.Column
+	if err := json.Unmarshal(data, &columns); err != nil {
+		return nil, 0, fmt.Errorf("unmarshalling output schema %s: %w", key, err)
+	}
+	schema.Seed(columns)
+	return schema, len(schema.Columns()), nil
+}
+
+// saveOutputSchema uploads the schema of the destination's columnar files to the object storage
+func (brt *Handle) saveOutputSchema(ctx context.Context, uploader filemanager.FileManager, destinationID string, schema *outputformat.Schema) error {
+	data, err := json.Marshal(schema.Columns())
+	if err != nil {
+		return fmt.Errorf("marshalling output schema: %w", err)
+	}
+	dir, err := os.MkdirTemp("", "rudder-output-schema")
+	if err != nil {
+		return fmt.Errorf("creating temporary directory: %w", err)
+	}
+	defer func() { _ = os.RemoveAll(dir) }()
+	filePath := filepath.Join(dir, destinationID+".json")
+	if err := os.WriteFile(filePath, data, 0o600); err != nil {
+		return fmt.Errorf("writing output schema: %w", err)
+	}
+	f, err := os.Open(filePath)
+	if err != nil {
+		return fmt.Errorf("opening output schema: %w", err)
+	}
+	defer func() { _ = f.Close() }()
+	if _, err := uploader.Upload(ctx, f, brt.folderName(false), outputSchemaFolder); err != nil {
+		return fmt.Errorf("uploading output schema: %w", err)
+	}
+	return nil
+}
+
+// outputSchemaKey returns the object key of the schema of the destination's columnar files
+func (brt *Handle) outputSchemaKey(uploader filemanager.FileManager, destinationID string) string {
+	return path.Join(uploader.Prefix(), brt.folderName(false), outputSchemaFolder, destinationID+".json")
+}
+
+// table returns the table the files of the destination are committed into, or nil if the destination doesn't have one
+func (brt *Handle) table(provider string, destination *backendconfig.DestinationT) (*tableformat.Table, error) {
+	format, err := tableformat.FromDestinationConfig(destination.Config)
+	if err != nil || format == tableformat.None {
+		return nil, err
+	}
+	if !slices.Contains(tableformat.Providers, provider) {
+		return nil, fmt.Errorf("table format %s is not supported by %s", format, provider)
+	}
+	bucket, _ := destination.Config["bucketName"].(string)
+	root := tableformat.PathFromDestinationConfig(destination.Config, brt.folderName(false), destination.ID)
+
+	brt.tablesMu.Lock()
+	defer brt.tablesMu.Unlock()
+	if table, ok := brt.tables[destination.ID]; ok && table.Format() == format && table.Bucket() == bucket && table.Root() == root {
+		return table, nil
+	}
+	table, err := tableformat.NewTable(format, bucket, root, brt.maxOutputColumns)
+	if err != nil {
+		return nil, err
+	}
+	brt.tables[destination.ID] = table
+	return table, nil
+}
+
+// commitDataFile commits an uploaded data file into the table
+func (*Handle) commitDataFile(uploader filemanager.FileManager, table *tableformat.Table, filePath, key string, schema *outputformat.Schema, records int) error {
+	fileInfo, err := os.Stat(filePath)
+	if err != nil {
+		return fmt.Errorf("reading size of data file: %w", err)
+	}
+	return table.Commit(context.TODO(), uploader, tableformat.DataFile{
+		Key:     key,
+		Size:    fileInfo.Size(),
+		Records: int64(records),
+	}, schema.Columns())
+}
+
+func tableFormat(table *tableformat.Table) tableformat.Format {
+	if table == nil {
+		return tableformat.None
+	}
+	return table.Format()
+}
+
 // pingWarehouse notifies the warehouse about a new data upload (staging files)
 func (brt *Handle) pingWarehouse(batchJobs *BatchedJobs, output UploadResult) (err error) {
 	schemaMap := make(map[string]map[string]interface{})
@@ -794,7 +1232,7 @@ func (brt *Handle) uploadInterval(destinationConfig map[string]interface{}) time
 
 // skipFetchingJobs returns true if the destination type is async and the there are still jobs in [importing] state for this destination type
 func (brt *Handle) skipFetchingJobs(partition string) bool {
-	if slices.Contains(asyncDestinations, brt.destType) {
+	if asyncdestinationmanager.IsAsyncDestination(brt.destType) {
 		queryParams := jobsdb.GetQueryParamsT{
 			CustomValFilters: []string{brt.destType},
 			JobsLimit:        1,
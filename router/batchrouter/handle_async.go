@@ -4,11 +4,9 @@ import (
 	"context"
 	stdjson "encoding/json"
 	"fmt"
-	"net/url"
 	"os"
 	"path/filepath"
 	"strconv"
-	"strings"
 	"time"
 
 	"github.com/google/uuid"
@@ -33,7 +31,7 @@ func (brt *Handle) pollAsyncStatus(ctx context.Context) {
 			brt.configSubscriberMu.RUnlock()
 
 			for key := range destinationsMap {
-				if slices.Contains(asyncDestinations, brt.destType) {
+				if asyncdestinationmanager.IsAsyncDestination(brt.destType) {
 					brt.logger.Debugf("pollAsyncStatus Started for Dest type: %s", brt.destType)
 					parameterFilters := []jobsdb.ParameterFilterT{{Name: "destination_id", Value: key}}
 					job, err := misc.QueryWithRetriesAndNotify(ctx, brt.jobdDBQueryRequestTimeout, brt.jobdDBMaxRetries, func(ctx context.Context) (jobsdb.JobsResult, error) {
@@ -55,275 +53,203 @@ func (brt *Handle) pollAsyncStatus(ctx context.Context) {
 					if len(importingJob) != 0 {
 						importingJob := importingJob[0]
 						parameters := importingJob.LastJobStatus.Parameters
-						pollUrl := gjson.GetBytes(parameters, "pollURL").String()
-						importId := gjson.GetBytes(parameters, "importId").String()
-						csvHeaders := gjson.GetBytes(parameters, "metadata.csvHeader").String()
-						var pollStruct AsyncPoll
-						pollStruct.ImportId = importId
-						pollStruct.Config = destinationsMap[key].Destination.Config
-						pollStruct.DestType = strings.ToLower(brt.destType)
-						payload, err := json.Marshal(pollStruct)
-						if err != nil {
-							panic("JSON Marshal Failed" + err.Error())
-						}
+						destination := &destinationsMap[key].Destination
 
 						startPollTime := time.Now()
 						brt.logger.Debugf("[Batch Router] Poll Status Started for Dest Type %v", brt.destType)
-						bodyBytes, statusCode := misc.HTTPCallWithRetryWithTimeout(brt.transformerURL+pollUrl, payload, asyncdestinationmanager.HTTPTimeout)
+						asyncResponse, err := brt.asyncDestinationManager.Poll(destination, parameters)
 						brt.logger.Debugf("[Batch Router] Poll Status Finished for Dest Type %v", brt.destType)
 						brt.asyncPollTimeStat.Since(startPollTime)
-
 						if err != nil {
-							panic("HTTP Request Failed" + err.Error())
+							brt.logger.Warnf("[Batch Router] Failed to poll status for Dest Type %v: %v", brt.destType, err)
+							continue
 						}
-						if statusCode == 200 {
-							var asyncResponse AsyncStatusResponse
-							if err != nil {
-								panic("Read Body Failed" + err.Error())
-							}
-							err = json.Unmarshal(bodyBytes, &asyncResponse)
+
+						uploadStatus := asyncResponse.Success
+						statusCode := asyncResponse.StatusCode
+						abortedJobs := make([]*jobsdb.JobT, 0)
+						if uploadStatus {
+							var statusList []*jobsdb.JobStatusT
+							list, err := misc.QueryWithRetriesAndNotify(ctx, brt.jobdDBQueryRequestTimeout, brt.jobdDBMaxRetries, func(ctx context.Context) (jobsdb.JobsResult, error) {
+								return brt.jobsDB.GetImporting(
+									ctx,
+									jobsdb.GetQueryParamsT{
+										CustomValFilters: []string{brt.destType},
+										JobsLimit:        brt.maxEventsInABatch,
+										ParameterFilters: parameterFilters,
+										PayloadSizeLimit: brt.adaptiveLimit(brt.payloadLimit),
+									},
+								)
+							}, brt.sendQueryRetryStats)
 							if err != nil {
-								panic("JSON Unmarshal Failed" + err.Error())
+								panic(err)
 							}
-							uploadStatus := asyncResponse.Success
-							statusCode := asyncResponse.StatusCode
-							abortedJobs := make([]*jobsdb.JobT, 0)
-							if uploadStatus {
-								var statusList []*jobsdb.JobStatusT
-								list, err := misc.QueryWithRetriesAndNotify(ctx, brt.jobdDBQueryRequestTimeout, brt.jobdDBMaxRetries, func(ctx context.Context) (jobsdb.JobsResult, error) {
-									return brt.jobsDB.GetImporting(
-										ctx,
-										jobsdb.GetQueryParamsT{
-											CustomValFilters: []string{brt.destType},
-											JobsLimit:        brt.maxEventsInABatch,
-											ParameterFilters: parameterFilters,
-											PayloadSizeLimit: brt.adaptiveLimit(brt.payloadLimit),
-										},
-									)
-								}, brt.sendQueryRetryStats)
+
+							importingList := list.Jobs
+							var failedEvents asyncdestinationmanager.AsyncFailedEvents
+							if !asyncResponse.HasFailed {
+								for _, job := range importingList {
+									failedEvents.SucceededJobIDs = append(failedEvents.SucceededJobIDs, job.JobID)
+								}
+							} else {
+								startFailedJobsPollTime := time.Now()
+								brt.logger.Debugf("[Batch Router] Fetching Failed Jobs Started for Dest Type %v", brt.destType)
+								failedEvents, err = brt.asyncDestinationManager.GetFailedEvents(destination, importingList, parameters, asyncResponse)
+								brt.logger.Debugf("[Batch Router] Fetching Failed Jobs for Dest Type %v", brt.destType)
+								brt.asyncFailedJobsTimeStat.Since(startFailedJobsPollTime)
 								if err != nil {
-									panic(err)
+									brt.logger.Errorf("[Batch Router] Failed to fetch failed jobs for Dest Type %v: %v", brt.destType, err)
+									continue
 								}
-
-								importingList := list.Jobs
-								if !asyncResponse.HasFailed {
-									for _, job := range importingList {
-										status := jobsdb.JobStatusT{
-											JobID:         job.JobID,
-											JobState:      jobsdb.Succeeded.State,
-											ExecTime:      time.Now(),
-											RetryTime:     time.Now(),
-											ErrorCode:     "",
-											ErrorResponse: []byte(`{}`),
-											Parameters:    []byte(`{}`),
-											JobParameters: job.Parameters,
-											WorkspaceId:   job.WorkspaceId,
-										}
-										statusList = append(statusList, &status)
+							}
+							var failedCount int
+							for _, job := range importingList {
+								jobID := job.JobID
+								var status *jobsdb.JobStatusT
+								if slices.Contains(failedEvents.SucceededJobIDs, jobID) {
+									status = &jobsdb.JobStatusT{
+										JobID:         jobID,
+										JobState:      jobsdb.Succeeded.State,
+										ExecTime:      time.Now(),
+										RetryTime:     time.Now(),
+										ErrorCode:     "200",
+										ErrorResponse: []byte(`{}`),
+										Parameters:    []byte(`{}`),
+										JobParameters: job.Parameters,
+										WorkspaceId:   job.WorkspaceId,
+									}
+								} else if slices.Contains(failedEvents.AbortedJobIDs, jobID) {
+									errorResp, _ := json.Marshal(ErrorResponse{Error: failedEvents.AbortedReasons[jobID]})
+									status = &jobsdb.JobStatusT{
+										JobID:         jobID,
+										JobState:      jobsdb.Aborted.State,
+										ExecTime:      time.Now(),
+										RetryTime:     time.Now(),
+										ErrorCode:     "",
+										ErrorResponse: errorResp,
+										Parameters:    []byte(`{}`),
+										JobParameters: job.Parameters,
+										WorkspaceId:   job.WorkspaceId,
 									}
-									brt.asyncSuccessfulJobCount.Count(len(statusList))
+									abortedJobs = append(abortedJobs, job)
 								} else {
-									failedJobUrl := asyncResponse.FailedJobsURL
-									payload = asyncdestinationmanager.GenerateFailedPayload(destinationsMap[key].Destination.Config, importingList, importId, brt.destType, csvHeaders)
-									startFailedJobsPollTime := time.Now()
-									brt.logger.Debugf("[Batch Router] Fetching Failed Jobs Started for Dest Type %v", brt.destType)
-									failedBodyBytes, statusCode := misc.HTTPCallWithRetryWithTimeout(brt.transformerURL+failedJobUrl, payload, asyncdestinationmanager.HTTPTimeout)
-									brt.logger.Debugf("[Batch Router] Fetching Failed Jobs for Dest Type %v", brt.destType)
-									brt.asyncFailedJobsTimeStat.Since(startFailedJobsPollTime)
-
-									if statusCode != 200 {
-										continue
+									status = &jobsdb.JobStatusT{
+										JobID:         jobID,
+										JobState:      jobsdb.Failed.State,
+										ExecTime:      time.Now(),
+										RetryTime:     time.Now(),
+										ErrorCode:     strconv.Itoa(statusCode),
+										ErrorResponse: []byte(`{}`),
+										Parameters:    []byte(`{}`),
+										JobParameters: job.Parameters,
+										WorkspaceId:   job.WorkspaceId,
 									}
-									var failedJobsResponse map[string]interface{}
-									err = json.Unmarshal(failedBodyBytes, &failedJobsResponse)
+									failedCount++
+								}
+								statusList = append(statusList, status)
+							}
+							brt.asyncSuccessfulJobCount.Count(len(statusList) - len(abortedJobs) - failedCount)
+							brt.asyncAbortedJobCount.Count(len(abortedJobs))
+							brt.asyncFailedJobCount.Count(failedCount)
+
+							if len(abortedJobs) > 0 {
+								err := misc.RetryWithNotify(context.Background(), brt.jobsDBCommandTimeout, brt.jobdDBMaxRetries, func(ctx context.Context) error {
+									return brt.errorDB.Store(ctx, abortedJobs)
+								}, brt.sendRetryStoreStats)
+								if err != nil {
+									panic(fmt.Errorf("storing %s jobs into ErrorDB: %w", brt.destType, err))
+								}
+							}
+							err = misc.RetryWithNotify(context.Background(), brt.jobsDBCommandTimeout, brt.jobdDBMaxRetries, func(ctx context.Context) error {
+								return brt.jobsDB.WithUpdateSafeTx(ctx, func(tx jobsdb.UpdateSafeTx) error {
+									err = brt.jobsDB.UpdateJobStatusInTx(ctx, tx, statusList, []string{brt.destType}, parameterFilters)
 									if err != nil {
-										panic("JSON Unmarshal Failed" + err.Error())
-									}
-									internalStatusCode, ok := failedJobsResponse["status"].(string)
-									if internalStatusCode != "200" || !ok {
-										brt.logger.Errorf("[Batch Router] Failed to fetch failed jobs for Dest Type %v with statusCode %v and body %v", brt.destType, internalStatusCode, string(failedBodyBytes))
-										continue
+										return fmt.Errorf("updating %s job statuses: %w", brt.destType, err)
 									}
-									metadata, ok := failedJobsResponse["metadata"].(map[string]interface{})
-									if !ok {
-										brt.logger.Errorf("[Batch Router] Failed to typecast failed jobs response for Dest Type %v with statusCode %v and body %v with error %v", brt.destType, internalStatusCode, string(failedBodyBytes), err)
-										continue
-									}
-									failedKeys, errFailed := misc.ConvertStringInterfaceToIntArray(metadata["failedKeys"])
-									warningKeys, errWarning := misc.ConvertStringInterfaceToIntArray(metadata["warningKeys"])
-									succeededKeys, errSuccess := misc.ConvertStringInterfaceToIntArray(metadata["succeededKeys"])
-									var status *jobsdb.JobStatusT
-									if errFailed != nil || errWarning != nil || errSuccess != nil || statusCode != 200 {
-										for _, job := range importingList {
-											jobID := job.JobID
-											status = &jobsdb.JobStatusT{
-												JobID:         jobID,
-												JobState:      jobsdb.Failed.State,
-												ExecTime:      time.Now(),
-												RetryTime:     time.Now(),
-												ErrorCode:     strconv.Itoa(statusCode),
-												ErrorResponse: []byte(`{}`),
-												Parameters:    []byte(`{}`),
-												JobParameters: job.Parameters,
-												WorkspaceId:   job.WorkspaceId,
-											}
-											statusList = append(statusList, status)
-										}
-										brt.asyncFailedJobCount.Count(len(statusList))
-										err := misc.RetryWithNotify(context.Background(), brt.jobsDBCommandTimeout, brt.jobdDBMaxRetries, func(ctx context.Context) error {
-											return brt.jobsDB.WithUpdateSafeTx(ctx, func(tx jobsdb.UpdateSafeTx) error {
-												err = brt.jobsDB.UpdateJobStatusInTx(ctx, tx, statusList, []string{brt.destType}, parameterFilters)
-												if err != nil {
-													return fmt.Errorf("updating %s job statuses: %w", brt.destType, err)
-												}
-												// no need to update rsources stats here since no terminal job state is recorded
-												return nil
-											})
-										}, brt.sendRetryUpdateStats)
-										if err != nil {
-											panic(err)
-										}
-										brt.updateProcessedEventsMetrics(statusList)
-										continue
-									}
-									for _, job := range importingList {
-										jobID := job.JobID
-										if slices.Contains(append(succeededKeys, warningKeys...), jobID) {
-											status = &jobsdb.JobStatusT{
-												JobID:         jobID,
-												JobState:      jobsdb.Succeeded.State,
-												ExecTime:      time.Now(),
-												RetryTime:     time.Now(),
-												ErrorCode:     "200",
-												ErrorResponse: []byte(`{}`),
-												Parameters:    []byte(`{}`),
-												JobParameters: job.Parameters,
-												WorkspaceId:   job.WorkspaceId,
-											}
-										} else if slices.Contains(failedKeys, job.JobID) {
-											errorResp, _ := json.Marshal(ErrorResponse{Error: gjson.GetBytes(failedBodyBytes, fmt.Sprintf("metadata.failedReasons.%v", job.JobID)).String()})
-											status = &jobsdb.JobStatusT{
-												JobID:         jobID,
-												JobState:      jobsdb.Aborted.State,
-												ExecTime:      time.Now(),
-												RetryTime:     time.Now(),
-												ErrorCode:     "",
-												ErrorResponse: errorResp,
-												Parameters:    []byte(`{}`),
-												JobParameters: job.Parameters,
-												WorkspaceId:   job.WorkspaceId,
-											}
-											abortedJobs = append(abortedJobs, job)
-										}
-										statusList = append(statusList, status)
-									}
-								}
-								brt.asyncSuccessfulJobCount.Count(len(statusList) - len(abortedJobs))
-								brt.asyncAbortedJobCount.Count(len(abortedJobs))
 
-								if len(abortedJobs) > 0 {
-									err := misc.RetryWithNotify(context.Background(), brt.jobsDBCommandTimeout, brt.jobdDBMaxRetries, func(ctx context.Context) error {
-										return brt.errorDB.Store(ctx, abortedJobs)
-									}, brt.sendRetryStoreStats)
-									if err != nil {
-										panic(fmt.Errorf("storing %s jobs into ErrorDB: %w", brt.destType, err))
+									// rsources stats
+									return brt.updateRudderSourcesStats(ctx, tx, importingList, statusList)
+								})
+							}, brt.sendRetryUpdateStats)
+							if err != nil {
+								panic(err)
+							}
+							brt.updateProcessedEventsMetrics(statusList)
+						} else if statusCode != 0 {
+							var statusList []*jobsdb.JobStatusT
+							list, err := misc.QueryWithRetriesAndNotify(ctx, brt.jobdDBQueryRequestTimeout, brt.jobdDBMaxRetries, func(ctx context.Context) (jobsdb.JobsResult, error) {
+								return brt.jobsDB.GetImporting(
+									ctx,
+									jobsdb.GetQueryParamsT{
+										CustomValFilters: []string{brt.destType},
+										JobsLimit:        brt.maxEventsInABatch,
+										ParameterFilters: parameterFilters,
+										PayloadSizeLimit: brt.adaptiveLimit(brt.payloadLimit),
+									},
+								)
+							}, brt.sendQueryRetryStats)
+							if err != nil {
+								panic(err)
+							}
+
+							importingList := list.Jobs
+							if isJobTerminated(statusCode) {
+								for _, job := range importingList {
+									status := jobsdb.JobStatusT{
+										JobID:         job.JobID,
+										JobState:      jobsdb.Aborted.State,
+										ExecTime:      time.Now(),
+										RetryTime:     time.Now(),
+										ErrorCode:     "",
+										ErrorResponse: []byte(`{}`),
+										Parameters:    []byte(`{}`),
+										JobParameters: job.Parameters,
+										WorkspaceId:   job.WorkspaceId,
 									}
+									statusList = append(statusList, &status)
+									abortedJobs = append(abortedJobs, job)
 								}
-								err = misc.RetryWithNotify(context.Background(), brt.jobsDBCommandTimeout, brt.jobdDBMaxRetries, func(ctx context.Context) error {
-									return brt.jobsDB.WithUpdateSafeTx(ctx, func(tx jobsdb.UpdateSafeTx) error {
-										err = brt.jobsDB.UpdateJobStatusInTx(ctx, tx, statusList, []string{brt.destType}, parameterFilters)
-										if err != nil {
-											return fmt.Errorf("updating %s job statuses: %w", brt.destType, err)
-										}
-
-										// rsources stats
-										return brt.updateRudderSourcesStats(ctx, tx, importingList, statusList)
-									})
-								}, brt.sendRetryUpdateStats)
-								if err != nil {
-									panic(err)
+								brt.asyncAbortedJobCount.Count(len(importingList))
+							} else {
+								for _, job := range importingList {
+									status := jobsdb.JobStatusT{
+										JobID:         job.JobID,
+										JobState:      jobsdb.Failed.State,
+										ExecTime:      time.Now(),
+										RetryTime:     time.Now(),
+										ErrorCode:     "",
+										ErrorResponse: []byte(`{}`),
+										Parameters:    []byte(`{}`),
+										JobParameters: job.Parameters,
+										WorkspaceId:   job.WorkspaceId,
+									}
+									statusList = append(statusList, &status)
 								}
-								brt.updateProcessedEventsMetrics(statusList)
-							} else if statusCode != 0 {
-								var statusList []*jobsdb.JobStatusT
-								list, err := misc.QueryWithRetriesAndNotify(ctx, brt.jobdDBQueryRequestTimeout, brt.jobdDBMaxRetries, func(ctx context.Context) (jobsdb.JobsResult, error) {
-									return brt.jobsDB.GetImporting(
-										ctx,
-										jobsdb.GetQueryParamsT{
-											CustomValFilters: []string{brt.destType},
-											JobsLimit:        brt.maxEventsInABatch,
-											ParameterFilters: parameterFilters,
-											PayloadSizeLimit: brt.adaptiveLimit(brt.payloadLimit),
-										},
-									)
-								}, brt.sendQueryRetryStats)
+								brt.asyncFailedJobCount.Count(len(importingList))
+							}
+							if len(abortedJobs) > 0 {
+								err := misc.RetryWithNotify(context.Background(), brt.jobsDBCommandTimeout, brt.jobdDBMaxRetries, func(ctx context.Context) error {
+									return brt.errorDB.Store(ctx, abortedJobs)
+								}, brt.sendRetryStoreStats)
 								if err != nil {
-									panic(err)
+									panic(fmt.Errorf("storing %s jobs into ErrorDB: %w", brt.destType, err))
 								}
+							}
 
-								importingList := list.Jobs
-								if isJobTerminated(statusCode) {
-									for _, job := range importingList {
-										status := jobsdb.JobStatusT{
-											JobID:         job.JobID,
-											JobState:      jobsdb.Aborted.State,
-											ExecTime:      time.Now(),
-											RetryTime:     time.Now(),
-											ErrorCode:     "",
-											ErrorResponse: []byte(`{}`),
-											Parameters:    []byte(`{}`),
-											JobParameters: job.Parameters,
-											WorkspaceId:   job.WorkspaceId,
-										}
-										statusList = append(statusList, &status)
-										abortedJobs = append(abortedJobs, job)
-									}
-									brt.asyncAbortedJobCount.Count(len(importingList))
-								} else {
-									for _, job := range importingList {
-										status := jobsdb.JobStatusT{
-											JobID:         job.JobID,
-											JobState:      jobsdb.Failed.State,
-											ExecTime:      time.Now(),
-											RetryTime:     time.Now(),
-											ErrorCode:     "",
-											ErrorResponse: []byte(`{}`),
-											Parameters:    []byte(`{}`),
-											JobParameters: job.Parameters,
-											WorkspaceId:   job.WorkspaceId,
-										}
-										statusList = append(statusList, &status)
-									}
-									brt.asyncFailedJobCount.Count(len(importingList))
-								}
-								if len(abortedJobs) > 0 {
-									err := misc.RetryWithNotify(context.Background(), brt.jobsDBCommandTimeout, brt.jobdDBMaxRetries, func(ctx context.Context) error {
-										return brt.errorDB.Store(ctx, abortedJobs)
-									}, brt.sendRetryStoreStats)
+							err = misc.RetryWithNotify(context.Background(), brt.jobsDBCommandTimeout, brt.jobdDBMaxRetries, func(ctx context.Context) error {
+								return brt.jobsDB.WithUpdateSafeTx(ctx, func(tx jobsdb.UpdateSafeTx) error {
+									err = brt.jobsDB.UpdateJobStatusInTx(ctx, tx, statusList, []string{brt.destType}, parameterFilters)
 									if err != nil {
-										panic(fmt.Errorf("storing %s jobs into ErrorDB: %w", brt.destType, err))
+										return fmt.Errorf("updating %s job statuses: %w", brt.destType, err)
 									}
-								}
-
-								err = misc.RetryWithNotify(context.Background(), brt.jobsDBCommandTimeout, brt.jobdDBMaxRetries, func(ctx context.Context) error {
-									return brt.jobsDB.WithUpdateSafeTx(ctx, func(tx jobsdb.UpdateSafeTx) error {
-										err = brt.jobsDB.UpdateJobStatusInTx(ctx, tx, statusList, []string{brt.destType}, parameterFilters)
-										if err != nil {
-											return fmt.Errorf("updating %s job statuses: %w", brt.destType, err)
-										}
-										// rsources stats
-										return brt.updateRudderSourcesStats(ctx, tx, importingList, statusList)
-									})
-								}, brt.sendRetryUpdateStats)
-								if err != nil {
-									panic(err)
-								}
-								brt.updateProcessedEventsMetrics(statusList)
-							} else {
-								continue
+									// rsources stats
+									return brt.updateRudderSourcesStats(ctx, tx, importingList, statusList)
+								})
+							}, brt.sendRetryUpdateStats)
+							if err != nil {
+								panic(err)
 							}
-						} else {
-							continue
+							brt.updateProcessedEventsMetrics(statusList)
 						}
 
 					}
@@ -334,20 +260,7 @@ func (brt *Handle) pollAsyncStatus(ctx context.Context) {
 }
 
 func (brt *Handle) asyncUploadWorker(ctx context.Context) {
-	resolveURL := func(base, relative string) string {
-		baseURL, err := url.Parse(base)
-		if err != nil {
-			brt.logger.Fatal(err)
-		}
-		relURL, err := url.Parse(relative)
-		if err != nil {
-			brt.logger.Fatal(err)
-		}
-		destURL := baseURL.ResolveReference(relURL).String()
-		return destURL
-	}
-
-	if !slices.Contains(asyncDestinations, brt.destType) {
+	if !asyncdestinationmanager.IsAsyncDestination(brt.destType) {
 		return
 	}
 
@@ -373,7 +286,7 @@ func (brt *Handle) asyncUploadWorker(ctx context.Context) {
 				timeout := uploadIntervalMap[destinationID]
 				if brt.asyncDestinationStruct[destinationID].Exists && (brt.asyncDestinationStruct[destinationID].CanUpload || timeElapsed > timeout) {
 					brt.asyncDestinationStruct[destinationID].CanUpload = true
-					uploadResponse := asyncdestinationmanager.Upload(resolveURL(brt.transformerURL, brt.asyncDestinationStruct[destinationID].URL), brt.asyncDestinationStruct[destinationID].FileName, destinationsMap[destinationID].Destination.Config, brt.destType, brt.asyncDestinationStruct[destinationID].FailedJobIDs, brt.asyncDestinationStruct[destinationID].ImportingJobIDs, destinationID)
+					uploadResponse := brt.asyncDestinationManager.Upload(&destinationsMap[destinationID].Destination, brt.asyncDestinationStruct[destinationID])
 					brt.setMultipleJobStatus(uploadResponse, brt.asyncDestinationStruct[destinationID].RsourcesStats)
 					brt.asyncStructCleanUp(destinationID)
 				}
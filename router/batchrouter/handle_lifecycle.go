@@ -1,8 +1,6 @@
 package batchrouter
 
 import (
-	"bufio"
-	"compress/gzip"
 	"context"
 	"fmt"
 	"net/http"
@@ -13,7 +11,7 @@ import (
 	"time"
 
 	"github.com/google/uuid"
-	"github.com/tidwall/gjson"
+	"github.com/samber/lo"
 	"golang.org/x/exp/slices"
 	"golang.org/x/sync/errgroup"
 
@@ -26,6 +24,9 @@ import (
 	backendconfig "github.com/rudderlabs/rudder-server/backend-config"
 	"github.com/rudderlabs/rudder-server/jobsdb"
 	"github.com/rudderlabs/rudder-server/router/batchrouter/asyncdestinationmanager"
+	"github.com/rudderlabs/rudder-server/router/batchrouter/internal/flushpolicy"
+	"github.com/rudderlabs/rudder-server/router/batchrouter/internal/outputformat"
+	"github.com/rudderlabs/rudder-server/router/batchrouter/internal/tableformat"
 	"github.com/rudderlabs/rudder-server/router/batchrouter/isolation"
 	router_utils "github.com/rudderlabs/rudder-server/router/utils"
 	destinationdebugger "github.com/rudderlabs/rudder-server/services/debugger/destination"
@@ -104,9 +105,10 @@ func (brt *Handle) Setup(
 	config.RegisterBoolConfigVariable(false, &brt.disableEgress, false, "disableEgress")
 	config.RegisterStringConfigVariable("", &brt.toAbortDestinationIDs, true, "BatchRouter.toAbortDestinationIDs")
 	config.RegisterDurationConfigVariable(3, &brt.warehouseServiceMaxRetryTime, true, time.Hour, []string{"BatchRouter.warehouseServiceMaxRetryTime", "BatchRouter.warehouseServiceMaxRetryTimeinHr"}...)
-	brt.transformerURL = config.GetString("DEST_TRANSFORM_URL", "http://localhost:9090")
 	config.RegisterStringConfigVariable("", &brt.datePrefixOverride, true, "BatchRouter.datePrefixOverride")
 	config.RegisterStringConfigVariable("", &brt.customDatePrefix, true, "BatchRouter.customDatePrefix")
+	config.RegisterDurationConfigVariable(30, &brt.flushMaxAge, true, time.Minute, []string{"BatchRouter." + brt.destType + ".flushMaxAge", "BatchRouter.flushMaxAge"}...)
+	config.RegisterIntConfigVariable(500, &brt.maxOutputColumns, false, 1, []string{"BatchRouter." + brt.destType + ".outputFormat.maxColumns", "BatchRouter.outputFormat.maxColumns"}...)
 
 	ctx, cancel := context.WithCancel(context.Background())
 	brt.backgroundGroup, brt.backgroundCtx = errgroup.WithContext(ctx)
@@ -120,13 +122,20 @@ func (brt *Handle) Setup(
 	brt.connectionWHNamespaceMap = map[string]string{}
 	brt.encounteredMergeRuleMap = map[string]map[string]bool{}
 	brt.uploadIntervalMap = map[string]time.Duration{}
+	brt.flushPolicyMap = map[string]*flushpolicy.Policy{}
 	brt.lastExecTimes = map[string]time.Time{}
+	brt.tables = map[string]*tableformat.Table{}
 	brt.dateFormatProvider = &storageDateFormatProvider{dateFormatsCache: make(map[string]string)}
 	var diagnosisTickerTime time.Duration
 	config.RegisterDurationConfigVariable(600, &diagnosisTickerTime, false, time.Second, []string{"Diagnostics.batchRouterTimePeriod", "Diagnostics.batchRouterTimePeriodInS"}...)
 	brt.diagnosisTicker = time.NewTicker(diagnosisTickerTime)
 	brt.uploadedRawDataJobsCache = make(map[string]map[string]bool)
 	brt.asyncDestinationStruct = make(map[string]*asyncdestinationmanager.AsyncDestinationStruct)
+	if asyncdestinationmanager.IsAsyncDestination(destType) {
+		if brt.asyncDestinationManager, err = asyncdestinationmanager.NewManager(destType); err != nil {
+			panic(fmt.Errorf("creating async destination manager for %q: %w", destType, err))
+		}
+	}
 
 	asyncStatTags := map[string]string{
 		"module":   "batch_router",
@@ -260,18 +269,40 @@ func (brt *Handle) crashRecover() {
 				panic(err)
 			}
 
+			if object.ManifestKey != "" {
+				// the object is complete if its manifest got uploaded, in which case its jobs are marked as succeeded
+				manifest, err := brt.downloadManifest(downloader, object)
+				if err == nil {
+					brt.logger.Infof("BRT: Found manifest for incomplete journal entry at key: %s, marking %d jobs as succeeded", object.ManifestKey, len(manifest.JobIDs))
+					brt.markRecoveredJobsSucceeded(object, manifest.JobIDs)
+					brt.jobsDB.JournalDeleteEntry(entry.OpID)
+					continue
+				}
+				if object.TableFormat != tableformat.None {
+					// data files which weren't committed into their table aren't visible, so their jobs are uploaded again
+					brt.logger.Infof("BRT: No manifest for incomplete journal entry at key: %s, uploading its jobs again: %v", object.ManifestKey, err)
+					brt.jobsDB.JournalDeleteEntry(entry.OpID)
+					continue
+				}
+				brt.logger.Debugf("BRT: No manifest for incomplete journal entry at key: %s, checking the object: %v", object.ManifestKey, err)
+			}
+
 			localTmpDirName := "/rudder-raw-data-dest-upload-crash-recovery/"
 			tmpDirPath, err := misc.CreateTMPDIR()
 			if err != nil {
 				panic(err)
 			}
-			jsonPath := fmt.Sprintf("%v%v.json", tmpDirPath+localTmpDirName, fmt.Sprintf("%v.%v", time.Now().Unix(), uuid.New().String()))
+			format := object.Format
+			if format == "" {
+				format = outputformat.JSON
+			}
+			filePath := fmt.Sprintf("%v%v%v", tmpDirPath+localTmpDirName, fmt.Sprintf("%v.%v", time.Now().Unix(), uuid.New().String()), format.Extension())
 
-			err = os.MkdirAll(filepath.Dir(jsonPath), os.ModePerm)
+			err = os.MkdirAll(filepath.Dir(filePath), os.ModePerm)
 			if err != nil {
 				panic(err)
 			}
-			jsonFile, err := os.Create(jsonPath)
+			file, err := os.Create(filePath)
 			if err != nil {
 				panic(err)
 			}
@@ -284,41 +315,96 @@ func (brt *Handle) crashRecover() {
 			}
 			objKey += object.Key
 
-			err = downloader.Download(context.TODO(), jsonFile, objKey)
+			err = downloader.Download(context.TODO(), file, objKey)
 			if err != nil {
 				brt.logger.Errorf("BRT: Failed to download data for incomplete journal entry to recover from %s at key: %s with error: %v\n", object.Provider, object.Key, err)
 				brt.jobsDB.JournalDeleteEntry(entry.OpID)
 				continue
 			}
 
-			jsonFile.Close()
-			defer os.Remove(jsonPath)
-			rawf, err := os.Open(jsonPath)
+			file.Close()
+			defer os.Remove(filePath)
+			messageIDs, err := outputformat.MessageIDs(format, filePath)
 			if err != nil {
 				panic(err)
 			}
-			reader, err := gzip.NewReader(rawf)
-			if err != nil {
-				panic(err)
-			}
-
-			sc := bufio.NewScanner(reader)
 
 			brt.logger.Debug("BRT: Setting go map cache for incomplete journal entry to recover from...")
-			for sc.Scan() {
-				lineBytes := sc.Bytes()
-				eventID := gjson.GetBytes(lineBytes, "messageId").String()
+			for _, eventID := range messageIDs {
 				if _, ok := brt.uploadedRawDataJobsCache[object.DestinationID]; !ok {
 					brt.uploadedRawDataJobsCache[object.DestinationID] = make(map[string]bool)
 				}
 				brt.uploadedRawDataJobsCache[object.DestinationID][eventID] = true
 			}
-			_ = reader.Close()
 			brt.jobsDB.JournalDeleteEntry(entry.OpID)
 		}
 	}
 }
 
+// markRecoveredJobsSucceeded marks the jobs of an object which got uploaded before a crash as succeeded,
+// so that they don't get uploaded again
+func (brt *Handle) markRecoveredJobsSucceeded(object ObjectStorageDefinition, jobIDs []int64) {
+	if len(jobIDs) == 0 {
+		return
+	}
+	now := time.Now()
+	statusList := lo.Map(jobIDs, func(jobID int64, _ int) *jobsdb.JobStatusT {
+		return &jobsdb.JobStatusT{
+			JobID:         jobID,
+			AttemptNum:    1,
+			JobState:      jobsdb.Succeeded.State,
+			ExecTime:      now,
+			RetryTime:     now,
+			ErrorResponse: []byte(`{"success":"OK"}`),
+			Parameters:    []byte(`{}`),
+			WorkspaceId:   object.WorkspaceID,
+		}
+	})
+	parameterFilters := []jobsdb.ParameterFilterT{{Name: "destination_id", Value: object.DestinationID}}
+	err := misc.RetryWithNotify(context.Background(), brt.jobsDBCommandTimeout, brt.jobdDBMaxRetries, func(ctx context.Context) error {
+		return brt.jobsDB.UpdateJobStatus(ctx, statusList, []string{brt.destType}, parameterFilters)
+	}, brt.sendRetryUpdateStats)
+	if err != nil {
+		panic(fmt.Errorf("BRT: Error marking recovered jobs of %s as succeeded: %w", object.Key, err))
+	}
+}
+
+// downloadManifest downloads the manifest of the object of an incomplete journal entry
+func (brt *Handle) downloadManifest(downloader filemanager.FileManager, object ObjectStorageDefinition) (*Manifest, error) {
+	tmpDirPath, err := misc.CreateTMPDIR()
+	if err != nil {
+		return nil, err
+	}
+	filePath := filepath.Join(tmpDirPath, "rudder-raw-data-dest-upload-crash-recovery", fmt.Sprintf("%v.%v%v", time.Now().Unix(), uuid.New().String(), manifestExtension))
+	if err := os.MkdirAll(filepath.Dir(filePath), os.ModePerm); err != nil {
+		return nil, err
+	}
+	defer func() { _ = os.Remove(filePath) }()
+	file, err := os.Create(filePath)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = file.Close() }()
+
+	var manifestKey string
+	if prefix, ok := object.Config["prefix"]; ok && prefix != "" {
+		manifestKey += fmt.Sprintf("/%s", strings.TrimSpace(prefix.(string)))
+	}
+	manifestKey += object.ManifestKey
+	if err := downloader.Download(context.TODO(), file, manifestKey); err != nil {
+		return nil, fmt.Errorf("downloading manifest: %w", err)
+	}
+	data, err := os.ReadFile(filePath)
+	if err != nil {
+		return nil, fmt.Errorf("reading manifest: %w", err)
+	}
+	var manifest Manifest
+	if err := json.Unmarshal(data, &manifest); err != nil {
+		return nil, fmt.Errorf("unmarshalling manifest: %w", err)
+	}
+	return &manifest, nil
+}
+
 func (brt *Handle) backendConfigSubscriber() {
 	ch := brt.backendConfig.Subscribe(brt.backgroundCtx, backendconfig.TopicBackendConfig)
 	initialized := func() {
@@ -331,6 +417,7 @@ func (brt *Handle) backendConfigSubscriber() {
 		destinationsMap := map[string]*router_utils.DestinationWithSources{}
 		connectionWHNamespaceMap := map[string]string{}
 		uploadIntervalMap := map[string]time.Duration{}
+		flushPolicyMap := map[string]*flushpolicy.Policy{}
 		config := data.Data.(map[string]backendconfig.ConfigT)
 		for _, wConfig := range config {
 			for _, source := range wConfig.Sources {
@@ -340,6 +427,7 @@ func (brt *Handle) backendConfigSubscriber() {
 							if _, ok := destinationsMap[destination.ID]; !ok {
 								destinationsMap[destination.ID] = &router_utils.DestinationWithSources{Destination: destination, Sources: []backendconfig.SourceT{}}
 								uploadIntervalMap[destination.ID] = brt.uploadInterval(destination.Config)
+								flushPolicyMap[destination.ID] = brt.flushPolicy(&destination)
 							}
 							destinationsMap[destination.ID].Sources = append(destinationsMap[destination.ID].Sources, source)
 
@@ -358,6 +446,7 @@ func (brt *Handle) backendConfigSubscriber() {
 		brt.destinationsMap = destinationsMap
 		brt.connectionWHNamespaceMap = connectionWHNamespaceMap
 		brt.uploadIntervalMap = uploadIntervalMap
+		brt.flushPolicyMap = flushPolicyMap
 		initialized()
 		brt.configSubscriberMu.Unlock()
 	}
@@ -94,6 +94,9 @@ func (brt *Handle) recordDeliveryStatus(batchDestination Connection, output Uplo
 	// Payload and AttemptNum don't make sense in recording batch router delivery status,
 	// So they are set to default values.
 	payload, err := sjson.SetBytes([]byte(`{}`), "location", output.FileLocation)
+	if err == nil && len(output.FileLocations) > 1 {
+		payload, err = sjson.SetBytes(payload, "locations", output.FileLocations)
+	}
 	if err != nil {
 		payload = []byte(`{}`)
 	}
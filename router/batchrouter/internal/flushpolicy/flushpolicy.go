@@ -0,0 +1,144 @@
+// Package flushpolicy implements size, count and age based flush triggers for the batches of the batch router.
+//
+// By default the batch router uploads the jobs of a destination once every upload frequency, so that low volume sources
+// create many small files and high volume sources huge ones. Destinations can instead configure a flush policy
+//
+//	"flushTargetSize": 104857600, "flushMaxEvents": 500000, "flushMaxAge": "15m"
+//
+// in which case their jobs are left pending until the size of the pending jobs, once encoded in the destination's output format,
+// reaches the target size, their number reaches the maximum event count or the oldest of them reaches the maximum age, whichever
+// comes first. The policy applies even when the batch router's query limits are reached, as its triggers already bound batches.
+// Batches are then cut at the target size and the maximum event count, leaving the remaining jobs for the next batch.
+// Since pending jobs are not marked in any way and their age is derived from their creation time, partially filled batches survive restarts.
+package flushpolicy
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/rudderlabs/rudder-server/jobsdb"
+)
+
+const (
+	// TargetSizeConfigKey is the key of the destination config holding the target payload size of batches, in bytes
+	TargetSizeConfigKey = "flushTargetSize"
+	// MaxEventsConfigKey is the key of the destination config holding the maximum number of events of batches
+	MaxEventsConfigKey = "flushMaxEvents"
+	// MaxAgeConfigKey is the key of the destination config holding the maximum age of pending jobs, e.g. "15m"
+	MaxAgeConfigKey = "flushMaxAge"
+)
+
+// Policy decides when the pending jobs of a destination are flushed
+type Policy struct {
+	TargetSize int64
+	MaxEvents  int
+	MaxAge     time.Duration
+	// Size returns the size of a job's payload once encoded in the destination's output format, defaulting to the payload's length
+	Size func(payload []byte) int
+}
+
+// FromDestinationConfig returns the flush policy of the destination's config, or nil if the destination doesn't have one.
+// The default maximum age applies to policies without one, so that jobs of low volume destinations are eventually flushed.
+func FromDestinationConfig(destConfig map[string]interface{}, defaultMaxAge time.Duration) (*Policy, error) {
+	var (
+		p   Policy
+		set bool
+	)
+	if raw, ok := destConfig[TargetSizeConfigKey]; ok && raw != nil {
+		v, err := toInt64(raw)
+		if err != nil || v < 0 {
+			return nil, fmt.Errorf("invalid %s config: %v", TargetSizeConfigKey, raw)
+		}
+		p.TargetSize, set = v, set || v > 0
+	}
+	if raw, ok := destConfig[MaxEventsConfigKey]; ok && raw != nil {
+		v, err := toInt64(raw)
+		if err != nil || v < 0 {
+			return nil, fmt.Errorf("invalid %s config: %v", MaxEventsConfigKey, raw)
+		}
+		p.MaxEvents, set = int(v), set || v > 0
+	}
+	if raw, ok := destConfig[MaxAgeConfigKey]; ok && raw != nil {
+		s, ok := raw.(string)
+		if !ok {
+			return nil, fmt.Errorf("invalid %s config: expected a duration string, got %T", MaxAgeConfigKey, raw)
+		}
+		if s != "" {
+			v, err := time.ParseDuration(s)
+			if err != nil || v < 0 {
+				return nil, fmt.Errorf("invalid %s config: %q", MaxAgeConfigKey, s)
+			}
+			p.MaxAge, set = v, set || v > 0
+		}
+	}
+	if !set {
+		return nil, nil
+	}
+	if p.MaxAge == 0 {
+		p.MaxAge = defaultMaxAge
+	}
+	return &p, nil
+}
+
+// Due returns true if any of the policy's triggers fired for the pending jobs
+func (p *Policy) Due(jobs []*jobsdb.JobT, now time.Time) bool {
+	if len(jobs) == 0 {
+		return false
+	}
+	if p.MaxEvents > 0 && len(jobs) >= p.MaxEvents {
+		return true
+	}
+	var size int64
+	oldest := jobs[0].CreatedAt
+	for _, job := range jobs {
+		size += p.size(job)
+		if job.CreatedAt.Before(oldest) {
+			oldest = job.CreatedAt
+		}
+	}
+	if p.TargetSize > 0 && size >= p.TargetSize {
+		return true
+	}
+	return p.MaxAge > 0 && now.Sub(oldest) >= p.MaxAge
+}
+
+// Batch returns the jobs of the next batch, i.e. the leading jobs up to the target size and the maximum event count.
+// A batch always contains at least one job, even if its payload exceeds the target size.
+func (p *Policy) Batch(jobs []*jobsdb.JobT) []*jobsdb.JobT {
+	if p.MaxEvents > 0 && len(jobs) > p.MaxEvents {
+		jobs = jobs[:p.MaxEvents]
+	}
+	if p.TargetSize <= 0 {
+		return jobs
+	}
+	var size int64
+	for i, job := range jobs {
+		if size += p.size(job); size >= p.TargetSize {
+			return jobs[:i+1]
+		}
+	}
+	return jobs
+}
+
+func (p *Policy) size(job *jobsdb.JobT) int64 {
+	if p.Size == nil {
+		return int64(len(job.EventPayload))
+	}
+	return int64(p.Size(job.EventPayload))
+}
+
+func toInt64(raw interface{}) (int64, error) {
+	switch v := raw.(type) {
+	case float64:
+		return int64(v), nil
+	case int:
+		return int64(v), nil
+	case int64:
+		return v, nil
+	case string:
+		return strconv.ParseInt(v, 10, 64)
+	default:
+		return 0, fmt.Errorf("unexpected type %T", raw)
+	}
+}
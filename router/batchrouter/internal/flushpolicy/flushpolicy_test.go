@@ -0,0 +1,67 @@
+package flushpolicy_test
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/rudderlabs/rudder-server/jobsdb"
+	"github.com/rudderlabs/rudder-server/router/batchrouter/internal/flushpolicy"
+)
+
+func TestFromDestinationConfig(t *testing.T) {
+	p, err := flushpolicy.FromDestinationConfig(map[string]interface{}{"bucketName": "bucket"}, time.Hour)
+	require.NoError(t, err)
+	require.Nil(t, p, "no policy")
+
+	p, err = flushpolicy.FromDestinationConfig(map[string]interface{}{"flushTargetSize": float64(1024), "flushMaxEvents": "100"}, time.Hour)
+	require.NoError(t, err)
+	require.Equal(t, &flushpolicy.Policy{TargetSize: 1024, MaxEvents: 100, MaxAge: time.Hour}, p, "default max age")
+
+	p, err = flushpolicy.FromDestinationConfig(map[string]interface{}{"flushMaxAge": "15m"}, time.Hour)
+	require.NoError(t, err)
+	require.Equal(t, &flushpolicy.Policy{MaxAge: 15 * time.Minute}, p)
+
+	for _, destConfig := range []map[string]interface{}{
+		{"flushTargetSize": "1MB"},
+		{"flushMaxEvents": float64(-1)},
+		{"flushMaxAge": float64(15)},
+		{"flushMaxAge": "15 minutes"},
+	} {
+		_, err = flushpolicy.FromDestinationConfig(destConfig, time.Hour)
+		require.Error(t, err, destConfig)
+	}
+}
+
+func TestPolicy(t *testing.T) {
+	now := time.Now()
+	newJobs := func(n, size int, age time.Duration) []*jobsdb.JobT {
+		jobs := make([]*jobsdb.JobT, n)
+		for i := range jobs {
+			jobs[i] = &jobsdb.JobT{JobID: int64(i + 1), EventPayload: []byte(strings.Repeat("a", size)), CreatedAt: now.Add(-age)}
+		}
+		return jobs
+	}
+	p := &flushpolicy.Policy{TargetSize: 100, MaxEvents: 5, MaxAge: time.Minute}
+
+	require.False(t, p.Due(nil, now))
+	require.False(t, p.Due(newJobs(2, 10, time.Second), now), "no trigger fired")
+	require.True(t, p.Due(newJobs(5, 10, time.Second), now), "max events")
+	require.True(t, p.Due(newJobs(2, 50, time.Second), now), "target size")
+	require.True(t, p.Due(newJobs(2, 10, time.Minute), now), "max age")
+
+	jobs := newJobs(4, 40, 0)
+	jobs[3].CreatedAt = now.Add(-2 * time.Minute)
+	require.True(t, p.Due(jobs[3:], now), "age of the oldest job")
+
+	require.Len(t, p.Batch(newJobs(10, 10, 0)), 5, "cut at max events")
+	require.Len(t, p.Batch(newJobs(4, 40, 0)), 3, "cut at target size")
+	require.Len(t, p.Batch(newJobs(2, 200, 0)), 1, "at least one job")
+	require.Len(t, p.Batch(newJobs(2, 10, 0)), 2)
+
+	p.Size = func(payload []byte) int { return 2 * len(payload) }
+	require.True(t, p.Due(newJobs(2, 25, time.Second), now), "target size of the encoded payloads")
+	require.Len(t, p.Batch(newJobs(4, 20, 0)), 3, "cut at the target size of the encoded payloads")
+}
@@ -0,0 +1,123 @@
+package outputformat
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/linkedin/goavro/v2"
+)
+
+// avroTypes are the avro types of the columns, along with the names of their branches in the nullable unions
+var avroTypes = map[string]struct {
+	schema interface{}
+	branch string
+}{
+	TypeBoolean:  {schema: "boolean", branch: "boolean"},
+	TypeFloat:    {schema: "double", branch: "double"},
+	TypeString:   {schema: "string", branch: "string"},
+	TypeJSON:     {schema: "string", branch: "string"},
+	TypeDatetime: {schema: map[string]string{"type": "long", "logicalType": "timestamp-micros"}, branch: "long.timestamp-micros"},
+}
+
+func avroSchema(columns []Column) (string, error) {
+	type avroField struct {
+		Name    string        `json:"name"`
+		Type    []interface{} `json:"type"`
+		Default interface{}   `json:"default"`
+	}
+	fields := make([]avroField, len(columns))
+	for i, c := range columns {
+		fields[i] = avroField{Name: c.Name, Type: []interface{}{"null", avroTypes[c.Type].schema}}
+	}
+	schema, err := json.Marshal(map[string]interface{}{
+		"type":   "record",
+		"name":   "event",
+		"fields": fields,
+	})
+	if err != nil {
+		return "", fmt.Errorf("marshalling avro schema: %w", err)
+	}
+	return string(schema), nil
+}
+
+// avroBlockSize is the number of records written per avro block
+const avroBlockSize = 1000
+
+type avroWriter struct {
+	columns   []Column
+	file      *os.File
+	bufWriter *bufio.Writer
+	writer    *goavro.OCFWriter
+	records   []interface{}
+}
+
+func newAvroWriter(path string, columns []Column) (*avroWriter, error) {
+	schema, err := avroSchema(columns)
+	if err != nil {
+		return nil, err
+	}
+	f, err := os.Create(path)
+	if err != nil {
+		return nil, fmt.Errorf("creating avro file: %w", err)
+	}
+	bufWriter := bufio.NewWriter(f)
+	w, err := goavro.NewOCFWriter(goavro.OCFConfig{W: bufWriter, Schema: schema, CompressionName: goavro.CompressionDeflateLabel})
+	if err != nil {
+		_ = f.Close()
+		return nil, fmt.Errorf("creating avro writer: %w", err)
+	}
+	return &avroWriter{columns: columns, file: f, bufWriter: bufWriter, writer: w}, nil
+}
+
+func (w *avroWriter) WriteRow(r map[string]interface{}) error {
+	record := make(map[string]interface{}, len(w.columns))
+	for _, c := range w.columns {
+		value, ok := r[c.Name]
+		if !ok {
+			record[c.Name] = nil
+			continue
+		}
+		var err error
+		switch c.Type {
+		case TypeJSON:
+			if value, err = jsonValue(value); err != nil {
+				return err
+			}
+		case TypeDatetime:
+			if value, err = time.Parse(time.RFC3339, value.(string)); err != nil {
+				return fmt.Errorf("converting value of column %s: %w", c.Name, err)
+			}
+		}
+		record[c.Name] = goavro.Union(avroTypes[c.Type].branch, value)
+	}
+	w.records = append(w.records, record)
+	if len(w.records) >= avroBlockSize {
+		return w.flush()
+	}
+	return nil
+}
+
+func (w *avroWriter) flush() error {
+	if len(w.records) == 0 {
+		return nil
+	}
+	if err := w.writer.Append(w.records); err != nil {
+		return fmt.Errorf("writing avro records: %w", err)
+	}
+	w.records = w.records[:0]
+	return nil
+}
+
+func (w *avroWriter) Close() error {
+	defer func() { _ = w.file.Close() }()
+	if err := w.flush(); err != nil {
+		return err
+	}
+	if err := w.bufWriter.Flush(); err != nil {
+		return fmt.Errorf("flushing avro file: %w", err)
+	}
+	return w.file.Close()
+}
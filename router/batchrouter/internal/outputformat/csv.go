@@ -0,0 +1,78 @@
+package outputformat
+
+import (
+	"compress/gzip"
+	"encoding/csv"
+	"fmt"
+	"os"
+	"strconv"
+)
+
+type csvWriter struct {
+	columns  []Column
+	file     *os.File
+	gzWriter *gzip.Writer
+	writer   *csv.Writer
+	record   []string
+}
+
+func newCSVWriter(path string, columns []Column) (*csvWriter, error) {
+	f, err := os.Create(path)
+	if err != nil {
+		return nil, fmt.Errorf("creating csv file: %w", err)
+	}
+	gzWriter := gzip.NewWriter(f)
+	w := csv.NewWriter(gzWriter)
+
+	header := make([]string, len(columns))
+	for i, c := range columns {
+		header[i] = c.Name
+	}
+	if err := w.Write(header); err != nil {
+		_ = f.Close()
+		return nil, fmt.Errorf("writing csv header: %w", err)
+	}
+	return &csvWriter{columns: columns, file: f, gzWriter: gzWriter, writer: w, record: make([]string, len(columns))}, nil
+}
+
+func (w *csvWriter) WriteRow(r map[string]interface{}) error {
+	var err error
+	for i, c := range w.columns {
+		if w.record[i], err = csvValue(r[c.Name], c.Type); err != nil {
+			return err
+		}
+	}
+	if err := w.writer.Write(w.record); err != nil {
+		return fmt.Errorf("writing csv record: %w", err)
+	}
+	return nil
+}
+
+func (w *csvWriter) Close() error {
+	defer func() { _ = w.file.Close() }()
+	w.writer.Flush()
+	if err := w.writer.Error(); err != nil {
+		return fmt.Errorf("flushing csv writer: %w", err)
+	}
+	if err := w.gzWriter.Close(); err != nil {
+		return fmt.Errorf("closing gzip writer: %w", err)
+	}
+	return w.file.Close()
+}
+
+func csvValue(value interface{}, typ string) (string, error) {
+	if value == nil {
+		return "", nil
+	}
+	switch v := value.(type) {
+	case bool:
+		return strconv.FormatBool(v), nil
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64), nil
+	case string:
+		if typ != TypeJSON {
+			return v, nil
+		}
+	}
+	return jsonValue(value)
+}
@@ -0,0 +1,231 @@
+// Package outputformat implements the output formats of the files uploaded to object storage destinations.
+//
+// Destinations write gzipped newline delimited json files by default. They can opt for a different format through their config, e.g.
+//
+//	"outputFormat": "parquet"
+//
+// Columnar formats (parquet, avro & csv) use a [Schema] inferred from the flattened fields of the events, which is persisted
+// per destination so that column typing stays consistent across files. Fields that don't fit in the schema are written
+// as json in the [ExtraColumn] column. Events are spooled to disk while the schema evolves, so that batches aren't kept in memory.
+package outputformat
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/rudderlabs/rudder-server/utils/misc"
+)
+
+// ConfigKey is the key of the destination config holding the output format
+const ConfigKey = "outputFormat"
+
+// Format is an output format
+type Format string
+
+const (
+	JSON    Format = "json"
+	Parquet Format = "parquet"
+	Avro    Format = "avro"
+	CSV     Format = "csv"
+)
+
+// FromDestinationConfig returns the output format of the destination's config, defaulting to [JSON]
+func FromDestinationConfig(destConfig map[string]interface{}) (Format, error) {
+	raw, ok := destConfig[ConfigKey]
+	if !ok || raw == nil {
+		return JSON, nil
+	}
+	value, ok := raw.(string)
+	if !ok {
+		return JSON, fmt.Errorf("invalid %s config: expected a string, got %T", ConfigKey, raw)
+	}
+	switch f := Format(strings.ToLower(strings.TrimSpace(value))); f {
+	case "":
+		return JSON, nil
+	case JSON, Parquet, Avro, CSV:
+		return f, nil
+	default:
+		return JSON, fmt.Errorf("unsupported output format %q", value)
+	}
+}
+
+// Extension returns the file extension of the format
+func (f Format) Extension() string {
+	switch f {
+	case Parquet:
+		return ".parquet"
+	case Avro:
+		return ".avro"
+	case CSV:
+		return ".csv.gz"
+	default:
+		return ".json.gz"
+	}
+}
+
+// EncodedSize returns the number of bytes the event takes once encoded in the format, before compression.
+// Sizes of columnar formats are estimated from the event's flattened values, since the actual size of a row depends on the file's schema.
+func (f Format) EncodedSize(payload []byte) int {
+	if f != Parquet && f != Avro && f != CSV {
+		return len(payload) + 1 // newline delimited
+	}
+	var event map[string]interface{}
+	if err := json.Unmarshal(payload, &event); err != nil {
+		return len(payload) + 1
+	}
+	fields := flatten(event)
+	var size int
+	for _, field := range fields {
+		typ, ok := inferType(field.value)
+		if !ok {
+			typ = TypeJSON
+		}
+		value, err := csvValue(field.value, typ)
+		if err != nil {
+			continue
+		}
+		size += len(value)
+	}
+	if f == CSV {
+		size += len(fields) // separators and newline
+	}
+	return size
+}
+
+// Writer writes the events of a batch to a file
+type Writer interface {
+	// Write adds an event to the file
+	Write(payload []byte) error
+	// Close completes the file
+	Close() error
+}
+
+// NewWriter creates a new writer for the file at the provided path. The schema is only used by columnar formats, whereas
+// destType is needed by the parquet format.
+func NewWriter(format Format, path, destType string, schema *Schema) (Writer, error) {
+	switch format {
+	case Parquet:
+		return newColumnarWriter(path, schema, func(columns []Column) (rowWriter, error) {
+			return newParquetWriter(path, destType, columns)
+		})
+	case Avro:
+		return newColumnarWriter(path, schema, func(columns []Column) (rowWriter, error) {
+			return newAvroWriter(path, columns)
+		})
+	case CSV:
+		return newColumnarWriter(path, schema, func(columns []Column) (rowWriter, error) {
+			return newCSVWriter(path, columns)
+		})
+	default:
+		gzWriter, err := misc.CreateGZ(path)
+		if err != nil {
+			return nil, err
+		}
+		return &jsonWriter{gzWriter: gzWriter}, nil
+	}
+}
+
+type jsonWriter struct {
+	gzWriter misc.GZipWriter
+}
+
+func (w *jsonWriter) Write(payload []byte) error {
+	return w.gzWriter.WriteGZ(string(payload) + "\n")
+}
+
+func (w *jsonWriter) Close() error {
+	return w.gzWriter.CloseGZ()
+}
+
+// rowWriter writes the rows of a columnar file
+type rowWriter interface {
+	WriteRow(row map[string]interface{}) error
+	Close() error
+}
+
+// columnarWriter evolves the schema while spooling the events of the batch to a temporary file, so that the columns
+// are known before the file gets written without keeping the batch in memory
+type columnarWriter struct {
+	schema    *Schema
+	spoolPath string
+	spool     *os.File
+	spoolBuf  *bufio.Writer
+	newWriter func(columns []Column) (rowWriter, error)
+}
+
+func newColumnarWriter(path string, schema *Schema, newWriter func(columns []Column) (rowWriter, error)) (*columnarWriter, error) {
+	spoolPath := path + ".spool"
+	spool, err := os.Create(spoolPath)
+	if err != nil {
+		return nil, fmt.Errorf("creating spool file: %w", err)
+	}
+	return &columnarWriter{
+		schema:    schema,
+		spoolPath: spoolPath,
+		spool:     spool,
+		spoolBuf:  bufio.NewWriter(spool),
+		newWriter: newWriter,
+	}, nil
+}
+
+func (w *columnarWriter) Write(payload []byte) error {
+	var event map[string]interface{}
+	if err := json.Unmarshal(payload, &event); err != nil {
+		return fmt.Errorf("unmarshalling event: %w", err)
+	}
+	w.schema.Observe(event)
+	if _, err := w.spoolBuf.Write(payload); err != nil {
+		return fmt.Errorf("spooling event: %w", err)
+	}
+	if err := w.spoolBuf.WriteByte('\n'); err != nil {
+		return fmt.Errorf("spooling event: %w", err)
+	}
+	return nil
+}
+
+func (w *columnarWriter) Close() error {
+	defer func() { _ = os.Remove(w.spoolPath) }()
+	if err := w.spoolBuf.Flush(); err != nil {
+		_ = w.spool.Close()
+		return fmt.Errorf("flushing spool file: %w", err)
+	}
+	if _, err := w.spool.Seek(0, io.SeekStart); err != nil {
+		_ = w.spool.Close()
+		return fmt.Errorf("rewinding spool file: %w", err)
+	}
+	defer func() { _ = w.spool.Close() }()
+
+	columns := w.schema.Columns()
+	rw, err := w.newWriter(columns)
+	if err != nil {
+		return err
+	}
+	decoder := json.NewDecoder(bufio.NewReader(w.spool))
+	for {
+		var event map[string]interface{}
+		if err := decoder.Decode(&event); err == io.EOF {
+			break
+		} else if err != nil {
+			_ = rw.Close()
+			return fmt.Errorf("reading spool file: %w", err)
+		}
+		if err := rw.WriteRow(row(columns, event)); err != nil {
+			_ = rw.Close()
+			return err
+		}
+	}
+	return rw.Close()
+}
+
+// jsonValue returns the json representation of an [ExtraColumn] value
+func jsonValue(value interface{}) (string, error) {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return "", fmt.Errorf("marshalling %s column: %w", ExtraColumn, err)
+	}
+	return string(data), nil
+}
@@ -0,0 +1,183 @@
+package outputformat_test
+
+import (
+	"bufio"
+	"compress/gzip"
+	"encoding/csv"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/linkedin/goavro/v2"
+	"github.com/stretchr/testify/require"
+	"github.com/xitongsys/parquet-go-source/local"
+	"github.com/xitongsys/parquet-go/common"
+	"github.com/xitongsys/parquet-go/reader"
+
+	"github.com/rudderlabs/rudder-server/router/batchrouter/internal/outputformat"
+	"github.com/rudderlabs/rudder-server/warehouse/encoding"
+)
+
+var events = []string{
+	`{"messageId":"m1","userId":"u1","receivedAt":"2023-06-05T12:00:00.000Z","context":{"ip":"1.1.1.1"},"properties":{"total":10.5,"paid":true,"products":[{"id":1}]}}`,
+	`{"messageId":"m2","userId":2,"receivedAt":"2023-06-05T12:00:01.000Z","context":{"ip":"2.2.2.2"},"properties":{"total":"unknown","coupon":"ABC","my-key":"x"}}`,
+}
+
+func TestFromDestinationConfig(t *testing.T) {
+	f, err := outputformat.FromDestinationConfig(map[string]interface{}{})
+	require.NoError(t, err)
+	require.Equal(t, outputformat.JSON, f)
+
+	f, err = outputformat.FromDestinationConfig(map[string]interface{}{"outputFormat": "Parquet"})
+	require.NoError(t, err)
+	require.Equal(t, outputformat.Parquet, f)
+	require.Equal(t, ".parquet", f.Extension())
+
+	_, err = outputformat.FromDestinationConfig(map[string]interface{}{"outputFormat": "xml"})
+	require.Error(t, err)
+}
+
+func TestEncodedSize(t *testing.T) {
+	payload := []byte(`{"messageId":"m1","context":{"ip":"1.1.1.1"},"properties":{"total":10.5,"paid":true,"products":[{"id":1}]}}`)
+	require.Equal(t, len(payload)+1, outputformat.JSON.EncodedSize(payload))
+	// m1 + 1.1.1.1 + 10.5 + true + [{"id":1}]
+	require.Equal(t, 27, outputformat.Parquet.EncodedSize(payload))
+	require.Equal(t, 27, outputformat.Avro.EncodedSize(payload))
+	require.Equal(t, 32, outputformat.CSV.EncodedSize(payload), "separators and newline")
+	require.Equal(t, 8, outputformat.CSV.EncodedSize([]byte("invalid")))
+}
+
+func TestSchema(t *testing.T) {
+	schema := outputformat.NewSchema(100)
+	columns := schema.Evolve([]map[string]interface{}{
+		{"messageId": "m1", "userId": "u1", "sentAt": "2023-06-05T12:00:00Z", "context": map[string]interface{}{"ip": "1.1.1.1"}, "total": 1.0, "tags": []interface{}{"a"}},
+	})
+	require.Equal(t, []outputformat.Column{
+		{Name: "_extra", Type: "json"},
+		{Name: "context_ip", Type: "string"},
+		{Name: "messageId", Type: "string"},
+		{Name: "sentAt", Type: "datetime"},
+		{Name: "total", Type: "float"},
+		{Name: "userId", Type: "string"},
+	}, columns)
+
+	columns = schema.Evolve([]map[string]interface{}{
+		{"userId": 1.0, "UserID": "u1", "my-key": "x", "paid": false},
+	})
+	require.Equal(t, []outputformat.Column{
+		{Name: "_extra", Type: "json"},
+		{Name: "context_ip", Type: "string"},
+		{Name: "messageId", Type: "string"},
+		{Name: "paid", Type: "boolean"},
+		{Name: "sentAt", Type: "datetime"},
+		{Name: "total", Type: "float"},
+		{Name: "userId", Type: "string"},
+	}, columns, "column types are kept, invalid & case insensitive duplicate names are skipped")
+
+	schema = outputformat.NewSchema(1)
+	columns = schema.Evolve([]map[string]interface{}{{"a": "a", "b": "b"}})
+	require.Len(t, columns, 3, "max columns")
+
+	schema = outputformat.NewSchema(100)
+	schema.Seed([]outputformat.Column{{Name: "total", Type: "string"}, {Name: "items", Type: ""}})
+	require.Equal(t, []outputformat.Column{
+		{Name: "_extra", Type: "json"},
+		{Name: "messageId", Type: "string"},
+		{Name: "total", Type: "string"},
+	}, schema.Evolve([]map[string]interface{}{{"total": 1.0, "Items": "x"}}), "seeded columns keep their type, names of columns without a type are reserved")
+}
+
+func TestWriter(t *testing.T) {
+	encoding.Init()
+
+	write := func(t *testing.T, format outputformat.Format) string {
+		path := filepath.Join(t.TempDir(), "events"+format.Extension())
+		w, err := outputformat.NewWriter(format, path, "S3", outputformat.NewSchema(100))
+		require.NoError(t, err)
+		for _, e := range events {
+			require.NoError(t, w.Write([]byte(e)))
+		}
+		require.NoError(t, w.Close())
+
+		messageIDs, err := outputformat.MessageIDs(format, path)
+		require.NoError(t, err)
+		require.Equal(t, []string{"m1", "m2"}, messageIDs)
+		return path
+	}
+
+	t.Run("json", func(t *testing.T) {
+		path := write(t, outputformat.JSON)
+		f, err := os.Open(path)
+		require.NoError(t, err)
+		defer func() { _ = f.Close() }()
+		gzReader, err := gzip.NewReader(f)
+		require.NoError(t, err)
+		sc := bufio.NewScanner(gzReader)
+		var lines []string
+		for sc.Scan() {
+			lines = append(lines, sc.Text())
+		}
+		require.Equal(t, events, lines)
+	})
+
+	t.Run("csv", func(t *testing.T) {
+		path := write(t, outputformat.CSV)
+		f, err := os.Open(path)
+		require.NoError(t, err)
+		defer func() { _ = f.Close() }()
+		gzReader, err := gzip.NewReader(f)
+		require.NoError(t, err)
+		records, err := csv.NewReader(gzReader).ReadAll()
+		require.NoError(t, err)
+		require.Equal(t, [][]string{
+			{"_extra", "context_ip", "messageId", "properties_coupon", "properties_paid", "properties_total", "receivedAt", "userId"},
+			{`{"properties.products":[{"id":1}]}`, "1.1.1.1", "m1", "", "true", "10.5", "2023-06-05T12:00:00.000Z", "u1"},
+			{`{"properties.my-key":"x","properties.total":"unknown","userId":2}`, "2.2.2.2", "m2", "ABC", "", "", "2023-06-05T12:00:01.000Z", ""},
+		}, records)
+	})
+
+	t.Run("avro", func(t *testing.T) {
+		path := write(t, outputformat.Avro)
+		f, err := os.Open(path)
+		require.NoError(t, err)
+		defer func() { _ = f.Close() }()
+		r, err := goavro.NewOCFReader(f)
+		require.NoError(t, err)
+		var records []interface{}
+		for r.Scan() {
+			record, err := r.Read()
+			require.NoError(t, err)
+			records = append(records, record)
+		}
+		require.Len(t, records, 2)
+		record := records[0].(map[string]interface{})
+		require.Equal(t, map[string]interface{}{"string": "1.1.1.1"}, record["context_ip"])
+		require.Equal(t, map[string]interface{}{"double": 10.5}, record["properties_total"])
+		require.Equal(t, map[string]interface{}{"boolean": true}, record["properties_paid"])
+		require.Nil(t, record["properties_coupon"])
+		require.Equal(t, map[string]interface{}{"string": `{"properties.products":[{"id":1}]}`}, record["_extra"])
+		require.Contains(t, record["receivedAt"], "long.timestamp-micros")
+	})
+
+	t.Run("parquet", func(t *testing.T) {
+		path := write(t, outputformat.Parquet)
+		f, err := local.NewLocalFileReader(path)
+		require.NoError(t, err)
+		defer func() { _ = f.Close() }()
+
+		pr, err := reader.NewParquetColumnReader(f, 1)
+		require.NoError(t, err)
+		defer pr.ReadStop()
+		require.EqualValues(t, 2, pr.GetNumRows())
+		column := func(name string) []interface{} {
+			values, _, _, err := pr.ReadColumnByPath(common.ReformPathStr("parquet_go_root."+name), 2)
+			require.NoError(t, err)
+			return values
+		}
+		require.Equal(t, []interface{}{"1.1.1.1", "2.2.2.2"}, column("context_ip"))
+		require.Equal(t, []interface{}{10.5, nil}, column("properties_total"))
+		require.Equal(t, []interface{}{true, nil}, column("properties_paid"))
+		require.Equal(t, []interface{}{int64(1685966400000000), int64(1685966401000000)}, column("receivedAt"))
+		require.Equal(t, []interface{}{`{"properties.products":[{"id":1}]}`, `{"properties.my-key":"x","properties.total":"unknown","userId":2}`}, column("_extra"))
+	})
+}
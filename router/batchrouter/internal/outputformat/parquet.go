@@ -0,0 +1,54 @@
+package outputformat
+
+import (
+	"fmt"
+
+	"github.com/rudderlabs/rudder-server/warehouse/encoding"
+)
+
+type parquetWriter struct {
+	columns []Column
+	writer  *encoding.ParquetWriter
+}
+
+func newParquetWriter(path, destType string, columns []Column) (*parquetWriter, error) {
+	tableSchema := make(map[string]string, len(columns))
+	for _, c := range columns {
+		tableSchema[c.Name] = c.Type
+	}
+	w, err := encoding.CreateParquetWriter(tableSchema, path, destType)
+	if err != nil {
+		return nil, fmt.Errorf("creating parquet writer: %w", err)
+	}
+	return &parquetWriter{columns: columns, writer: w}, nil
+}
+
+func (w *parquetWriter) WriteRow(r map[string]interface{}) error {
+	values := make([]interface{}, len(w.columns)) // columns are sorted, same as the parquet schema
+	for i, c := range w.columns {
+		value, ok := r[c.Name]
+		if !ok {
+			continue
+		}
+		var err error
+		if c.Type == TypeJSON {
+			if value, err = jsonValue(value); err != nil {
+				return err
+			}
+		}
+		if values[i], err = encoding.GetParquetValue(value, c.Type); err != nil {
+			return fmt.Errorf("converting value of column %s: %w", c.Name, err)
+		}
+	}
+	if err := w.writer.WriteRow(values); err != nil {
+		return fmt.Errorf("writing parquet row: %w", err)
+	}
+	return nil
+}
+
+func (w *parquetWriter) Close() error {
+	if err := w.writer.Close(); err != nil {
+		return fmt.Errorf("closing parquet writer: %w", err)
+	}
+	return nil
+}
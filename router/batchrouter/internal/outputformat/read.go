@@ -0,0 +1,141 @@
+package outputformat
+
+import (
+	"bufio"
+	"compress/gzip"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/linkedin/goavro/v2"
+	"github.com/tidwall/gjson"
+	"github.com/xitongsys/parquet-go-source/local"
+	"github.com/xitongsys/parquet-go/common"
+	"github.com/xitongsys/parquet-go/reader"
+)
+
+// MessageIDs returns the message ids of the events found in a file of the provided format
+func MessageIDs(format Format, path string) ([]string, error) {
+	switch format {
+	case Parquet:
+		return parquetMessageIDs(path)
+	case Avro:
+		return avroMessageIDs(path)
+	case CSV:
+		return csvMessageIDs(path)
+	default:
+		return jsonMessageIDs(path)
+	}
+}
+
+func jsonMessageIDs(path string) ([]string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("opening file: %w", err)
+	}
+	defer func() { _ = f.Close() }()
+	gzReader, err := gzip.NewReader(f)
+	if err != nil {
+		return nil, fmt.Errorf("creating gzip reader: %w", err)
+	}
+	defer func() { _ = gzReader.Close() }()
+	var messageIDs []string
+	sc := bufio.NewScanner(gzReader)
+	for sc.Scan() {
+		messageIDs = append(messageIDs, gjson.GetBytes(sc.Bytes(), MessageIDColumn).String())
+	}
+	return messageIDs, sc.Err()
+}
+
+func csvMessageIDs(path string) ([]string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("opening file: %w", err)
+	}
+	defer func() { _ = f.Close() }()
+	gzReader, err := gzip.NewReader(f)
+	if err != nil {
+		return nil, fmt.Errorf("creating gzip reader: %w", err)
+	}
+	defer func() { _ = gzReader.Close() }()
+	r := csv.NewReader(gzReader)
+	header, err := r.Read()
+	if err != nil {
+		return nil, fmt.Errorf("reading csv header: %w", err)
+	}
+	index := -1
+	for i, name := range header {
+		if name == MessageIDColumn {
+			index = i
+		}
+	}
+	if index < 0 {
+		return nil, fmt.Errorf("column %s not found", MessageIDColumn)
+	}
+	var messageIDs []string
+	for {
+		record, err := r.Read()
+		if errors.Is(err, io.EOF) {
+			return messageIDs, nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("reading csv record: %w", err)
+		}
+		messageIDs = append(messageIDs, record[index])
+	}
+}
+
+func avroMessageIDs(path string) ([]string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("opening file: %w", err)
+	}
+	defer func() { _ = f.Close() }()
+	r, err := goavro.NewOCFReader(bufio.NewReader(f))
+	if err != nil {
+		return nil, fmt.Errorf("creating avro reader: %w", err)
+	}
+	var messageIDs []string
+	for r.Scan() {
+		record, err := r.Read()
+		if err != nil {
+			return nil, fmt.Errorf("reading avro record: %w", err)
+		}
+		var messageID string
+		if m, ok := record.(map[string]interface{}); ok {
+			if union, ok := m[MessageIDColumn].(map[string]interface{}); ok {
+				messageID, _ = union["string"].(string)
+			}
+		}
+		messageIDs = append(messageIDs, messageID)
+	}
+	return messageIDs, r.Err()
+}
+
+func parquetMessageIDs(path string) ([]string, error) {
+	f, err := local.NewLocalFileReader(path)
+	if err != nil {
+		return nil, fmt.Errorf("opening file: %w", err)
+	}
+	defer func() { _ = f.Close() }()
+	r, err := reader.NewParquetColumnReader(f, 1)
+	if err != nil {
+		return nil, fmt.Errorf("creating parquet reader: %w", err)
+	}
+	defer r.ReadStop()
+	numRows := r.GetNumRows()
+	if numRows == 0 {
+		return nil, nil
+	}
+	values, _, _, err := r.ReadColumnByPath(common.ReformPathStr("parquet_go_root."+MessageIDColumn), numRows)
+	if err != nil {
+		return nil, fmt.Errorf("reading column %s: %w", MessageIDColumn, err)
+	}
+	messageIDs := make([]string, len(values))
+	for i, v := range values {
+		messageIDs[i], _ = v.(string)
+	}
+	return messageIDs, nil
+}
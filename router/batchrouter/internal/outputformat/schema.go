@@ -0,0 +1,209 @@
+package outputformat
+
+import (
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Column types
+const (
+	TypeBoolean  = "boolean"
+	TypeFloat    = "float"
+	TypeString   = "string"
+	TypeDatetime = "datetime"
+	TypeJSON     = "json"
+)
+
+const (
+	// ExtraColumn is the json column holding the fields of an event which don't fit in the schema,
+	// keyed by their dot separated paths
+	ExtraColumn = "_extra"
+	// MessageIDColumn is the column holding the message id of an event, which is always part of the schema
+	MessageIDColumn = "messageId"
+)
+
+// validColumnName matches the column names supported by all formats
+var validColumnName = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// Column is a column of the schema
+type Column struct {
+	Name string `json:"name"`
+	Type string `json:"type"`
+}
+
+// NewSchema creates a new schema, which can hold up to maxColumns columns, on top of the [MessageIDColumn] & [ExtraColumn] columns.
+func NewSchema(maxColumns int) *Schema {
+	s := &Schema{
+		maxColumns: maxColumns,
+		columns:    map[string]string{},
+		lowerNames: map[string]struct{}{},
+	}
+	s.add(MessageIDColumn, TypeString)
+	s.add(ExtraColumn, TypeJSON)
+	return s
+}
+
+// Schema is the schema of the files of a destination. Columns are inferred from the flattened fields of the events
+// and, once added, keep their type, so that typing is consistent across files. Schemas only grow.
+type Schema struct {
+	maxColumns int
+
+	mu         sync.RWMutex
+	columns    map[string]string   // column name -> column type
+	lowerNames map[string]struct{} // lower case column names, for avoiding case insensitive duplicates
+}
+
+// Evolve adds columns for the new fields of the provided events and returns all columns of the schema, sorted by name
+func (s *Schema) Evolve(events []map[string]interface{}) []Column {
+	for _, event := range events {
+		s.Observe(event)
+	}
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	columns := make([]Column, 0, len(s.columns))
+	for name, typ := range s.columns {
+		columns = append(columns, Column{Name: name, Type: typ})
+	}
+	sort.Slice(columns, func(i, j int) bool { return columns[i].Name < columns[j].Name })
+	return columns
+}
+
+// Observe adds columns for the new fields of the event
+func (s *Schema) Observe(event map[string]interface{}) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, f := range flatten(event) {
+		name := f.columnName()
+		if _, ok := s.columns[name]; ok {
+			continue
+		}
+		if len(s.columns) >= s.maxColumns+2 || !validColumnName.MatchString(name) {
+			continue
+		}
+		if _, ok := s.lowerNames[strings.ToLower(name)]; ok {
+			continue
+		}
+		if typ, ok := inferType(f.value); ok {
+			s.add(name, typ)
+		}
+	}
+}
+
+// Columns returns all columns of the schema, sorted by name
+func (s *Schema) Columns() []Column {
+	return s.Evolve(nil)
+}
+
+// Seed adds the provided columns to the schema, e.g. the columns of an existing table, overriding the types of
+// existing columns. The names of columns without a type are reserved, so that their fields are kept in [ExtraColumn].
+func (s *Schema) Seed(columns []Column) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, c := range columns {
+		if c.Type == "" {
+			s.lowerNames[strings.ToLower(c.Name)] = struct{}{}
+			continue
+		}
+		s.add(c.Name, c.Type)
+	}
+}
+
+func (s *Schema) add(name, typ string) {
+	s.columns[name] = typ
+	s.lowerNames[strings.ToLower(name)] = struct{}{}
+}
+
+// row returns the values of the event for the provided columns. Fields without a column, or with a value not matching
+// their column's type, are collected in the [ExtraColumn] column.
+func row(columns []Column, event map[string]interface{}) map[string]interface{} {
+	types := make(map[string]string, len(columns))
+	for _, c := range columns {
+		types[c.Name] = c.Type
+	}
+	values := map[string]interface{}{}
+	extra := map[string]interface{}{}
+	for _, f := range flatten(event) {
+		if f.value == nil {
+			continue
+		}
+		name := f.columnName()
+		if _, ok := values[name]; !ok && matchesType(f.value, types[name]) {
+			values[name] = f.value
+			continue
+		}
+		extra[strings.Join(f.path, ".")] = f.value
+	}
+	if len(extra) > 0 {
+		values[ExtraColumn] = extra
+	}
+	return values
+}
+
+type field struct {
+	path  []string
+	value interface{}
+}
+
+func (f field) columnName() string {
+	return strings.Join(f.path, "_")
+}
+
+// flatten returns the leaf fields of the event, sorted by path. Arrays are leaves.
+func flatten(event map[string]interface{}) []field {
+	var fields []field
+	var walk func(path []string, m map[string]interface{})
+	walk = func(path []string, m map[string]interface{}) {
+		keys := make([]string, 0, len(m))
+		for k := range m {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			p := append(append([]string(nil), path...), k)
+			if nested, ok := m[k].(map[string]interface{}); ok {
+				walk(p, nested)
+				continue
+			}
+			fields = append(fields, field{path: p, value: m[k]})
+		}
+	}
+	walk(nil, event)
+	return fields
+}
+
+func inferType(value interface{}) (string, bool) {
+	switch v := value.(type) {
+	case bool:
+		return TypeBoolean, true
+	case float64:
+		return TypeFloat, true
+	case string:
+		if isDatetime(v) {
+			return TypeDatetime, true
+		}
+		return TypeString, true
+	default:
+		return "", false
+	}
+}
+
+func matchesType(value interface{}, typ string) bool {
+	switch v := value.(type) {
+	case bool:
+		return typ == TypeBoolean
+	case float64:
+		return typ == TypeFloat
+	case string:
+		return typ == TypeString || (typ == TypeDatetime && isDatetime(v))
+	default:
+		return false
+	}
+}
+
+func isDatetime(v string) bool {
+	_, err := time.Parse(time.RFC3339, v)
+	return err == nil
+}
@@ -0,0 +1,154 @@
+// Package pathtemplate implements templated object keys for the files uploaded to object storage destinations.
+//
+// By default files are uploaded under <folder>/<sourceID>/<date>/. Destinations can instead provide a [text/template]
+// for the key prefix of their files, e.g. a hive style layout
+//
+//	"pathTemplate": "{{.Folder}}/{{.SourceID}}/event_type={{.EventType}}/year={{.Year}}/month={{.Month}}/day={{.Day}}/hour={{.Hour}}"
+//
+// The template is rendered for every event of a batch and events are grouped in one file per rendered prefix,
+// so that query engines can prune partitions. The following are available to templates:
+//
+//   - .Folder, .SourceID, .DestinationID, .WorkspaceID and .Date, the date folder of the default layout
+//   - .EventType and .EventName of the event
+//   - .Year, .Month, .Day and .Hour, zero padded, of the event's receivedAt in UTC
+//   - .Field "path", the value of a field of the event, e.g. {{.Field "context.library.name"}}
+//   - .Bucket "path" n, a hash bucket in [0, n) of the value of a field, e.g. {{.Bucket "userId" 16}}
+//
+// Event values are escaped the way hive escapes partition values, whereas missing values are rendered as [DefaultPartition].
+package pathtemplate
+
+import (
+	"fmt"
+	"hash/fnv"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/tidwall/gjson"
+)
+
+// ConfigKey is the key of the destination config holding the path template
+const ConfigKey = "pathTemplate"
+
+// DefaultPartition is the value rendered for missing event values, same as hive's default partition name
+const DefaultPartition = "__HIVE_DEFAULT_PARTITION__"
+
+// Template renders the key prefixes of events
+type Template struct {
+	tmpl *template.Template
+}
+
+// FromDestinationConfig returns the path template of the destination's config, or nil if the destination uses the default layout
+func FromDestinationConfig(destConfig map[string]interface{}) (*Template, error) {
+	raw, ok := destConfig[ConfigKey]
+	if !ok || raw == nil {
+		return nil, nil
+	}
+	text, ok := raw.(string)
+	if !ok {
+		return nil, fmt.Errorf("invalid %s config: expected a string, got %T", ConfigKey, raw)
+	}
+	if strings.TrimSpace(text) == "" {
+		return nil, nil
+	}
+	return Parse(text)
+}
+
+// Parse parses a path template
+func Parse(text string) (*Template, error) {
+	tmpl, err := template.New(ConfigKey).Parse(text)
+	if err != nil {
+		return nil, fmt.Errorf("parsing %s: %w", ConfigKey, err)
+	}
+	// render a sample event for catching references to unknown variables early
+	if _, err := (&Template{tmpl: tmpl}).Render(Vars{Folder: "folder"}, []byte(`{}`)); err != nil {
+		return nil, err
+	}
+	return &Template{tmpl: tmpl}, nil
+}
+
+// Vars are the variables of a batch
+type Vars struct {
+	Folder        string
+	SourceID      string
+	DestinationID string
+	WorkspaceID   string
+	Date          string
+}
+
+// Render returns the key prefix of an event, as a list of path segments
+func (t *Template) Render(vars Vars, payload []byte) ([]string, error) {
+	receivedAt, err := time.Parse(time.RFC3339, gjson.GetBytes(payload, "receivedAt").String())
+	if err != nil {
+		receivedAt = time.Now()
+	}
+	receivedAt = receivedAt.UTC()
+	e := &event{
+		Vars:      vars,
+		EventType: escape(gjson.GetBytes(payload, "type").String()),
+		EventName: escape(gjson.GetBytes(payload, "event").String()),
+		Year:      receivedAt.Format("2006"),
+		Month:     receivedAt.Format("01"),
+		Day:       receivedAt.Format("02"),
+		Hour:      receivedAt.Format("15"),
+		payload:   payload,
+	}
+	var sb strings.Builder
+	if err := t.tmpl.Execute(&sb, e); err != nil {
+		return nil, fmt.Errorf("rendering %s: %w", ConfigKey, err)
+	}
+	var segments []string
+	for _, s := range strings.Split(sb.String(), "/") {
+		if s = strings.TrimSpace(s); s != "" {
+			segments = append(segments, s)
+		}
+	}
+	if len(segments) == 0 {
+		return nil, fmt.Errorf("rendering %s: empty path", ConfigKey)
+	}
+	return segments, nil
+}
+
+// event is the data of the template
+type event struct {
+	Vars
+	EventType string
+	EventName string
+	Year      string
+	Month     string
+	Day       string
+	Hour      string
+
+	payload []byte
+}
+
+// Field returns the escaped value of a field of the event
+func (e *event) Field(path string) string {
+	return escape(gjson.GetBytes(e.payload, path).String())
+}
+
+// Bucket returns the hash bucket of the value of a field of the event
+func (e *event) Bucket(path string, buckets int) (int, error) {
+	if buckets <= 0 {
+		return 0, fmt.Errorf("invalid number of buckets: %d", buckets)
+	}
+	h := fnv.New32a()
+	_, _ = h.Write([]byte(gjson.GetBytes(e.payload, path).String()))
+	return int(h.Sum32() % uint32(buckets)), nil
+}
+
+// escape escapes a partition value, the same way hive does
+func escape(value string) string {
+	if value == "" {
+		return DefaultPartition
+	}
+	var sb strings.Builder
+	for _, c := range value {
+		if c < 0x20 || c == 0x7F || strings.ContainsRune("\"#%'*/:=?\\{[]^", c) {
+			fmt.Fprintf(&sb, "%%%02X", c)
+			continue
+		}
+		sb.WriteRune(c)
+	}
+	return sb.String()
+}
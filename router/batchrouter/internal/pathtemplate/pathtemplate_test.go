@@ -0,0 +1,84 @@
+package pathtemplate_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/rudderlabs/rudder-server/router/batchrouter/internal/pathtemplate"
+)
+
+func TestFromDestinationConfig(t *testing.T) {
+	tmpl, err := pathtemplate.FromDestinationConfig(map[string]interface{}{})
+	require.NoError(t, err)
+	require.Nil(t, tmpl)
+
+	tmpl, err = pathtemplate.FromDestinationConfig(map[string]interface{}{"pathTemplate": "{{.Folder}}/{{.SourceID}}"})
+	require.NoError(t, err)
+	require.NotNil(t, tmpl)
+
+	_, err = pathtemplate.FromDestinationConfig(map[string]interface{}{"pathTemplate": "{{.Folder"})
+	require.Error(t, err, "invalid syntax")
+
+	_, err = pathtemplate.FromDestinationConfig(map[string]interface{}{"pathTemplate": "{{.Unknown}}"})
+	require.Error(t, err, "unknown variable")
+
+	_, err = pathtemplate.FromDestinationConfig(map[string]interface{}{"pathTemplate": `{{.Bucket "userId" 0}}`})
+	require.Error(t, err, "invalid number of buckets")
+
+	_, err = pathtemplate.FromDestinationConfig(map[string]interface{}{"pathTemplate": 1})
+	require.Error(t, err)
+}
+
+func TestRender(t *testing.T) {
+	vars := pathtemplate.Vars{
+		Folder:        "rudder-logs",
+		SourceID:      "source-1",
+		DestinationID: "destination-1",
+		WorkspaceID:   "workspace-1",
+		Date:          "2023-06-05",
+	}
+
+	t.Run("hive layout", func(t *testing.T) {
+		tmpl, err := pathtemplate.Parse("{{.Folder}}/{{.WorkspaceID}}/{{.SourceID}}/event_type={{.EventType}}/event_name={{.EventName}}/year={{.Year}}/month={{.Month}}/day={{.Day}}/hour={{.Hour}}")
+		require.NoError(t, err)
+
+		segments, err := tmpl.Render(vars, []byte(`{"type":"track","event":"Order Completed: 50/50","receivedAt":"2023-06-05T03:04:05.123+02:00"}`))
+		require.NoError(t, err)
+		require.Equal(t, []string{"rudder-logs", "workspace-1", "source-1", "event_type=track", "event_name=Order Completed%3A 50%2F50", "year=2023", "month=06", "day=05", "hour=01"}, segments)
+
+		segments, err = tmpl.Render(vars, []byte(`{"type":"identify","receivedAt":"2023-06-05T23:04:05.123Z"}`))
+		require.NoError(t, err)
+		require.Equal(t, []string{"rudder-logs", "workspace-1", "source-1", "event_type=identify", "event_name=__HIVE_DEFAULT_PARTITION__", "year=2023", "month=06", "day=05", "hour=23"}, segments)
+	})
+
+	t.Run("fields and buckets", func(t *testing.T) {
+		tmpl, err := pathtemplate.Parse(`/{{.Folder}}//{{.Date}}/library={{.Field "context.library.name"}}/bucket={{.Bucket "userId" 16}}/`)
+		require.NoError(t, err)
+
+		segments, err := tmpl.Render(vars, []byte(`{"userId":"user-1","context":{"library":{"name":"analytics-go"}}}`))
+		require.NoError(t, err)
+		require.Len(t, segments, 4)
+		require.Equal(t, []string{"rudder-logs", "2023-06-05", "library=analytics-go"}, segments[:3])
+
+		again, err := tmpl.Render(vars, []byte(`{"userId":"user-1","context":{"library":{"name":"analytics-go"}}}`))
+		require.NoError(t, err)
+		require.Equal(t, segments, again, "buckets are stable")
+
+		buckets := map[string]struct{}{}
+		for _, userID := range []string{"user-1", "user-2", "user-3", "user-4", "user-5", "user-6", "user-7", "user-8"} {
+			segments, err := tmpl.Render(vars, []byte(`{"userId":"`+userID+`"}`))
+			require.NoError(t, err)
+			require.Regexp(t, `^bucket=([0-9]|1[0-5])$`, segments[3])
+			buckets[segments[3]] = struct{}{}
+		}
+		require.Greater(t, len(buckets), 1)
+	})
+
+	t.Run("empty path", func(t *testing.T) {
+		tmpl, err := pathtemplate.Parse("{{.Folder}}")
+		require.NoError(t, err)
+		_, err = tmpl.Render(pathtemplate.Vars{}, []byte(`{}`))
+		require.Error(t, err)
+	})
+}
@@ -0,0 +1,338 @@
+package tableformat
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/rudderlabs/rudder-server/router/batchrouter/internal/outputformat"
+)
+
+const (
+	deltaLogDir = "_delta_log"
+	// deltaLookback is the minimum number of commits read when loading a table, for detecting data files which got already committed
+	deltaLookback = 100
+	// deltaWriterVersion is the highest writer protocol version supported
+	deltaWriterVersion = 2
+)
+
+var deltaCommitName = regexp.MustCompile(`^` + deltaLogDir + `/(\d{20})\.json$`)
+
+// deltaTypes are the delta lake types of the columns
+var deltaTypes = map[string]string{
+	outputformat.TypeBoolean:  "boolean",
+	outputformat.TypeFloat:    "double",
+	outputformat.TypeString:   "string",
+	outputformat.TypeJSON:     "string",
+	outputformat.TypeDatetime: "timestamp",
+}
+
+type deltaProtocol struct {
+	MinReaderVersion int `json:"minReaderVersion"`
+	MinWriterVersion int `json:"minWriterVersion"`
+}
+
+type deltaMetadata struct {
+	ID               string            `json:"id"`
+	Format           deltaFileFormat   `json:"format"`
+	SchemaString     string            `json:"schemaString"`
+	PartitionColumns []string          `json:"partitionColumns"`
+	Configuration    map[string]string `json:"configuration"`
+	CreatedTime      int64             `json:"createdTime,omitempty"`
+}
+
+type deltaFileFormat struct {
+	Provider string            `json:"provider"`
+	Options  map[string]string `json:"options"`
+}
+
+type deltaSchema struct {
+	Type   string            `json:"type"`
+	Fields []json.RawMessage `json:"fields"` // kept verbatim, so that fields of other writers are preserved
+}
+
+type deltaField struct {
+	Name     string                 `json:"name"`
+	Type     interface{}            `json:"type"` // nested types are objects
+	Nullable bool                   `json:"nullable"`
+	Metadata map[string]interface{} `json:"metadata"`
+}
+
+type deltaAdd struct {
+	Path             string            `json:"path"`
+	PartitionValues  map[string]string `json:"partitionValues"`
+	Size             int64             `json:"size"`
+	ModificationTime int64             `json:"modificationTime"`
+	DataChange       bool              `json:"dataChange"`
+	Stats            string            `json:"stats,omitempty"`
+}
+
+type deltaRemove struct {
+	Path string `json:"path"`
+}
+
+type deltaCommitInfo struct {
+	Timestamp           int64             `json:"timestamp"`
+	Operation           string            `json:"operation"`
+	OperationParameters map[string]string `json:"operationParameters"`
+	EngineInfo          string            `json:"engineInfo"`
+}
+
+// deltaAction is a line of a commit of the transaction log
+type deltaAction struct {
+	CommitInfo *deltaCommitInfo `json:"commitInfo,omitempty"`
+	Protocol   *deltaProtocol   `json:"protocol,omitempty"`
+	MetaData   *deltaMetadata   `json:"metaData,omitempty"`
+	Add        *deltaAdd        `json:"add,omitempty"`
+	Remove     *deltaRemove     `json:"remove,omitempty"`
+}
+
+// deltaTable is a delta lake table, the transaction log of which consists of json commits
+type deltaTable struct {
+	version  int64 // the latest version of the table, -1 if the table doesn't exist
+	loaded   bool
+	metadata *deltaMetadata
+	protocol *deltaProtocol
+	paths    map[string]bool // paths of the data files added by the commits read so far
+}
+
+func (d *deltaTable) load(ctx context.Context, s *store) ([]outputformat.Column, error) {
+	if err := d.refresh(ctx, s); err != nil {
+		return nil, err
+	}
+	if d.metadata == nil {
+		return nil, nil
+	}
+	fields, err := deltaFields(d.metadata)
+	if err != nil {
+		return nil, err
+	}
+	columns := make([]outputformat.Column, 0, len(fields))
+	for _, f := range fields {
+		columns = append(columns, outputformat.Column{Name: f.Name, Type: fromDeltaType(f.Name, f.Type)})
+	}
+	return columns, nil
+}
+
+func (d *deltaTable) commit(ctx context.Context, s *store, file DataFile, columns []outputformat.Column) error {
+	if err := d.refresh(ctx, s); err != nil {
+		return err
+	}
+	name := s.name(file.Key)
+	if d.paths[name] {
+		return nil // committed before a crash
+	}
+	now := time.Now().UnixMilli()
+	actions := []deltaAction{{CommitInfo: &deltaCommitInfo{
+		Timestamp:           now,
+		Operation:           "WRITE",
+		OperationParameters: map[string]string{"mode": "Append"},
+		EngineInfo:          "rudder-server",
+	}}}
+	if d.version < 0 {
+		d.protocol = &deltaProtocol{MinReaderVersion: 1, MinWriterVersion: 2}
+		actions = append(actions, deltaAction{Protocol: d.protocol})
+	}
+	metadata, changed, err := d.evolve(columns, now)
+	if err != nil {
+		return err
+	}
+	if changed {
+		actions = append(actions, deltaAction{MetaData: metadata})
+	}
+	actions = append(actions, deltaAction{Add: &deltaAdd{
+		Path:             name,
+		PartitionValues:  map[string]string{},
+		Size:             file.Size,
+		ModificationTime: now,
+		DataChange:       true,
+		Stats:            fmt.Sprintf(`{"numRecords":%d}`, file.Records),
+	}})
+
+	var buf bytes.Buffer
+	for _, action := range actions {
+		line, err := json.Marshal(action)
+		if err != nil {
+			return fmt.Errorf("marshalling action: %w", err)
+		}
+		buf.Write(line)
+		buf.WriteByte('\n')
+	}
+	version := d.version + 1
+	if err := s.put(ctx, deltaCommit(version), buf.Bytes()); err != nil {
+		return err
+	}
+	d.version = version
+	d.metadata = metadata
+	d.paths[name] = true
+	return nil
+}
+
+// evolve returns the metadata of the table with the columns it doesn't have yet, and whether it changed
+func (d *deltaTable) evolve(columns []outputformat.Column, now int64) (*deltaMetadata, bool, error) {
+	metadata := d.metadata
+	if metadata == nil {
+		metadata = &deltaMetadata{
+			ID:               uuid.New().String(),
+			Format:           deltaFileFormat{Provider: "parquet", Options: map[string]string{}},
+			SchemaString:     `{"type":"struct","fields":[]}`,
+			PartitionColumns: []string{},
+			Configuration:    map[string]string{},
+			CreatedTime:      now,
+		}
+	}
+	var schema deltaSchema
+	if err := json.Unmarshal([]byte(metadata.SchemaString), &schema); err != nil {
+		return nil, false, fmt.Errorf("unmarshalling schema: %w", err)
+	}
+	fields, err := deltaFields(metadata)
+	if err != nil {
+		return nil, false, err
+	}
+	existing := make(map[string]bool, len(fields))
+	for _, f := range fields {
+		existing[strings.ToLower(f.Name)] = true
+	}
+	changed := d.metadata == nil
+	for _, c := range columns {
+		if existing[strings.ToLower(c.Name)] {
+			continue
+		}
+		field, err := json.Marshal(deltaField{Name: c.Name, Type: deltaTypes[c.Type], Nullable: true, Metadata: map[string]interface{}{}})
+		if err != nil {
+			return nil, false, fmt.Errorf("marshalling field: %w", err)
+		}
+		schema.Fields = append(schema.Fields, field)
+		changed = true
+	}
+	if !changed {
+		return metadata, false, nil
+	}
+	schemaString, err := json.Marshal(schema)
+	if err != nil {
+		return nil, false, fmt.Errorf("marshalling schema: %w", err)
+	}
+	evolved := *metadata
+	evolved.SchemaString = string(schemaString)
+	return &evolved, true, nil
+}
+
+// refresh reads the commits of the table which were added since it was last read
+func (d *deltaTable) refresh(ctx context.Context, s *store) error {
+	var startAfter string
+	if d.loaded && d.version >= 0 {
+		startAfter = deltaCommit(d.version)
+	}
+	names, err := s.list(ctx, deltaLogDir+"/", startAfter)
+	if err != nil {
+		return err
+	}
+	latest := d.version
+	if !d.loaded {
+		latest = -1
+	}
+	for _, name := range names {
+		if m := deltaCommitName.FindStringSubmatch(name); m != nil {
+			if version, _ := strconv.ParseInt(m[1], 10, 64); version > latest {
+				latest = version
+			}
+		}
+	}
+	if d.loaded && latest == d.version {
+		return nil
+	}
+	if !d.loaded {
+		d.paths = map[string]bool{}
+	}
+	// commits are read from the latest one backwards, until the latest metadata & protocol are found
+	var metadata *deltaMetadata
+	var protocol *deltaProtocol
+	for version := latest; version >= 0; version-- {
+		if d.loaded && version <= d.version {
+			break
+		}
+		if metadata != nil && protocol != nil && latest-version >= deltaLookback {
+			break
+		}
+		data, err := s.get(ctx, deltaCommit(version))
+		if err != nil {
+			return err
+		}
+		sc := bufio.NewScanner(bytes.NewReader(data))
+		sc.Buffer(nil, 64*1024*1024)
+		for sc.Scan() {
+			var action deltaAction
+			if err := json.Unmarshal(sc.Bytes(), &action); err != nil {
+				return fmt.Errorf("unmarshalling action of version %d: %w", version, err)
+			}
+			switch {
+			case action.MetaData != nil && metadata == nil:
+				metadata = action.MetaData
+			case action.Protocol != nil && protocol == nil:
+				protocol = action.Protocol
+			case action.Add != nil:
+				d.paths[action.Add.Path] = true
+			}
+		}
+		if err := sc.Err(); err != nil {
+			return fmt.Errorf("reading version %d: %w", version, err)
+		}
+	}
+	if metadata == nil {
+		metadata = d.metadata
+	}
+	if protocol == nil {
+		protocol = d.protocol
+	}
+	if latest >= 0 && (metadata == nil || protocol == nil) {
+		return fmt.Errorf("metadata or protocol of version %d not found", latest)
+	}
+	if protocol != nil && protocol.MinWriterVersion > deltaWriterVersion {
+		return fmt.Errorf("unsupported writer version %d", protocol.MinWriterVersion)
+	}
+	if metadata != nil && len(metadata.PartitionColumns) > 0 {
+		return fmt.Errorf("partitioned tables are not supported")
+	}
+	d.version, d.metadata, d.protocol, d.loaded = latest, metadata, protocol, true
+	return nil
+}
+
+func deltaCommit(version int64) string {
+	return fmt.Sprintf("%s/%020d.json", deltaLogDir, version)
+}
+
+func deltaFields(metadata *deltaMetadata) ([]deltaField, error) {
+	var schema struct {
+		Fields []deltaField `json:"fields"`
+	}
+	if err := json.Unmarshal([]byte(metadata.SchemaString), &schema); err != nil {
+		return nil, fmt.Errorf("unmarshalling schema: %w", err)
+	}
+	return schema.Fields, nil
+}
+
+func fromDeltaType(name string, typ interface{}) string {
+	switch typ {
+	case "boolean":
+		return outputformat.TypeBoolean
+	case "double", "float":
+		return outputformat.TypeFloat
+	case "string":
+		if name == outputformat.ExtraColumn {
+			return outputformat.TypeJSON
+		}
+		return outputformat.TypeString
+	case "timestamp":
+		return outputformat.TypeDatetime
+	default:
+		return unknownType
+	}
+}
@@ -0,0 +1,620 @@
+package tableformat
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"math/rand"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/linkedin/goavro/v2"
+
+	"github.com/rudderlabs/rudder-server/router/batchrouter/internal/outputformat"
+)
+
+const (
+	icebergMetadataDir = "metadata"
+	icebergVersionHint = icebergMetadataDir + "/version-hint.text"
+	// icebergDataFileProperty is the snapshot summary property holding the path of the data file appended by the snapshot
+	icebergDataFileProperty = "rudder.data-file"
+	icebergNameMapping      = "schema.name-mapping.default"
+)
+
+var icebergMetadataName = regexp.MustCompile(`^` + icebergMetadataDir + `/v(\d+)\.metadata\.json$`)
+
+// icebergTypes are the iceberg types of the columns
+var icebergTypes = map[string]string{
+	outputformat.TypeBoolean:  "boolean",
+	outputformat.TypeFloat:    "double",
+	outputformat.TypeString:   "string",
+	outputformat.TypeJSON:     "string",
+	outputformat.TypeDatetime: "timestamptz",
+}
+
+// icebergManifestSchema is the schema of the manifest files, listing the data files added by a snapshot
+const icebergManifestSchema = `{
+	"type": "record",
+	"name": "manifest_entry",
+	"fields": [
+		{"name": "status", "type": "int", "field-id": 0},
+		{"name": "snapshot_id", "type": ["null", "long"], "default": null, "field-id": 1},
+		{"name": "sequence_number", "type": ["null", "long"], "default": null, "field-id": 3},
+		{"name": "file_sequence_number", "type": ["null", "long"], "default": null, "field-id": 4},
+		{"name": "data_file", "field-id": 2, "type": {
+			"type": "record",
+			"name": "r2",
+			"fields": [
+				{"name": "content", "type": "int", "field-id": 134},
+				{"name": "file_path", "type": "string", "field-id": 100},
+				{"name": "file_format", "type": "string", "field-id": 101},
+				{"name": "partition", "type": {"type": "record", "name": "r102", "fields": []}, "field-id": 102},
+				{"name": "record_count", "type": "long", "field-id": 103},
+				{"name": "file_size_in_bytes", "type": "long", "field-id": 104}
+			]
+		}}
+	]
+}`
+
+// icebergManifestListSchema is the schema of the manifest lists, listing the manifest files of a snapshot
+const icebergManifestListSchema = `{
+	"type": "record",
+	"name": "manifest_file",
+	"fields": [
+		{"name": "manifest_path", "type": "string", "field-id": 500},
+		{"name": "manifest_length", "type": "long", "field-id": 501},
+		{"name": "partition_spec_id", "type": "int", "field-id": 502},
+		{"name": "content", "type": "int", "default": 0, "field-id": 517},
+		{"name": "sequence_number", "type": "long", "default": 0, "field-id": 515},
+		{"name": "min_sequence_number", "type": "long", "default": 0, "field-id": 516},
+		{"name": "added_snapshot_id", "type": "long", "field-id": 503},
+		{"name": "added_files_count", "type": "int", "field-id": 504},
+		{"name": "existing_files_count", "type": "int", "field-id": 505},
+		{"name": "deleted_files_count", "type": "int", "field-id": 506},
+		{"name": "added_rows_count", "type": "long", "field-id": 512},
+		{"name": "existing_rows_count", "type": "long", "field-id": 513},
+		{"name": "deleted_rows_count", "type": "long", "field-id": 514},
+		{"name": "partitions", "default": null, "field-id": 507, "type": ["null", {
+			"type": "array",
+			"element-id": 508,
+			"items": {
+				"type": "record",
+				"name": "r508",
+				"fields": [
+					{"name": "contains_null", "type": "boolean", "field-id": 509},
+					{"name": "contains_nan", "type": ["null", "boolean"], "default": null, "field-id": 518},
+					{"name": "lower_bound", "type": ["null", "bytes"], "default": null, "field-id": 510},
+					{"name": "upper_bound", "type": ["null", "bytes"], "default": null, "field-id": 511}
+				]
+			}
+		}]}
+	]
+}`
+
+type icebergSchema struct {
+	Type     string            `json:"type"`
+	SchemaID int               `json:"schema-id"`
+	Fields   []json.RawMessage `json:"fields"` // kept verbatim, so that fields of other writers are preserved
+}
+
+type icebergField struct {
+	ID       int         `json:"id"`
+	Name     string      `json:"name"`
+	Required bool        `json:"required"`
+	Type     interface{} `json:"type"` // nested types are objects
+}
+
+type icebergSnapshot struct {
+	SnapshotID       int64             `json:"snapshot-id"`
+	ParentSnapshotID *int64            `json:"parent-snapshot-id,omitempty"`
+	SequenceNumber   int64             `json:"sequence-number"`
+	TimestampMs      int64             `json:"timestamp-ms"`
+	Summary          map[string]string `json:"summary"`
+	ManifestList     string            `json:"manifest-list"`
+	SchemaID         int               `json:"schema-id"`
+}
+
+type icebergPartitionSpec struct {
+	SpecID int               `json:"spec-id"`
+	Fields []json.RawMessage `json:"fields"`
+}
+
+// icebergMetadata is the part of the table metadata which is needed for appending data files
+type icebergMetadata struct {
+	FormatVersion      int                    `json:"format-version"`
+	Location           string                 `json:"location"`
+	LastSequenceNumber int64                  `json:"last-sequence-number"`
+	LastColumnID       int                    `json:"last-column-id"`
+	CurrentSchemaID    int                    `json:"current-schema-id"`
+	Schemas            []icebergSchema        `json:"schemas"`
+	DefaultSpecID      int                    `json:"default-spec-id"`
+	PartitionSpecs     []icebergPartitionSpec `json:"partition-specs"`
+	Properties         map[string]string      `json:"properties"`
+	CurrentSnapshotID  *int64                 `json:"current-snapshot-id"`
+	Snapshots          []icebergSnapshot      `json:"snapshots"`
+}
+
+// icebergTable is an iceberg table of a file based catalog, the versions of which are metadata files named v<version>.metadata.json
+type icebergTable struct {
+	version  int // the latest version of the table, 0 if the table doesn't exist
+	raw      map[string]json.RawMessage
+	metadata *icebergMetadata
+}
+
+func (it *icebergTable) load(ctx context.Context, s *store) ([]outputformat.Column, error) {
+	names, err := s.list(ctx, icebergMetadataDir+"/v", "")
+	if err != nil {
+		return nil, err
+	}
+	var latest int
+	for _, name := range names {
+		if m := icebergMetadataName.FindStringSubmatch(name); m != nil {
+			if version, _ := strconv.Atoi(m[1]); version > latest {
+				latest = version
+			}
+		}
+	}
+	if err := it.read(ctx, s, latest); err != nil {
+		return nil, err
+	}
+	if it.metadata == nil {
+		return nil, nil
+	}
+	fields, err := it.fields()
+	if err != nil {
+		return nil, err
+	}
+	columns := make([]outputformat.Column, 0, len(fields))
+	for _, f := range fields {
+		columns = append(columns, outputformat.Column{Name: f.Name, Type: fromIcebergType(f.Name, f.Type)})
+	}
+	return columns, nil
+}
+
+func (it *icebergTable) commit(ctx context.Context, s *store, file DataFile, columns []outputformat.Column) error {
+	// newer versions written by other writers are read before committing
+	for {
+		names, err := s.list(ctx, icebergMetadataFile(it.version+1), "")
+		if err != nil {
+			return err
+		}
+		if len(names) == 0 {
+			break
+		}
+		if err := it.read(ctx, s, it.version+1); err != nil {
+			return err
+		}
+	}
+	filePath := s.location(s.name(file.Key))
+	if it.metadata != nil {
+		for _, snapshot := range it.metadata.Snapshots {
+			if snapshot.Summary[icebergDataFileProperty] == filePath {
+				return nil // committed before a crash
+			}
+		}
+	}
+	raw, metadata, err := it.evolve(s, columns)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now().UnixMilli()
+	snapshotID := rand.Int63() // nolint:gosec
+	sequenceNumber := metadata.LastSequenceNumber + 1
+	commitUUID := uuid.New().String()
+
+	manifestName := fmt.Sprintf("%s/%s-m0.avro", icebergMetadataDir, commitUUID)
+	manifest, err := it.manifest(metadata, snapshotID, sequenceNumber, filePath, file)
+	if err != nil {
+		return err
+	}
+	if err := s.put(ctx, manifestName, manifest); err != nil {
+		return err
+	}
+	manifestListName := fmt.Sprintf("%s/snap-%d-1-%s.avro", icebergMetadataDir, snapshotID, commitUUID)
+	manifestList, err := it.manifestList(ctx, s, metadata, map[string]interface{}{
+		"manifest_path":        s.location(manifestName),
+		"manifest_length":      int64(len(manifest)),
+		"partition_spec_id":    int32(metadata.DefaultSpecID),
+		"content":              int32(0),
+		"sequence_number":      sequenceNumber,
+		"min_sequence_number":  sequenceNumber,
+		"added_snapshot_id":    snapshotID,
+		"added_files_count":    int32(1),
+		"existing_files_count": int32(0),
+		"deleted_files_count":  int32(0),
+		"added_rows_count":     file.Records,
+		"existing_rows_count":  int64(0),
+		"deleted_rows_count":   int64(0),
+		"partitions":           nil,
+	})
+	if err != nil {
+		return err
+	}
+	if err := s.put(ctx, manifestListName, manifestList); err != nil {
+		return err
+	}
+
+	summary := map[string]string{
+		"operation":             "append",
+		"added-data-files":      "1",
+		"added-records":         strconv.FormatInt(file.Records, 10),
+		"added-files-size":      strconv.FormatInt(file.Size, 10),
+		icebergDataFileProperty: filePath,
+	}
+	var parentSnapshotID *int64
+	if metadata.CurrentSnapshotID != nil && *metadata.CurrentSnapshotID >= 0 {
+		parentSnapshotID = metadata.CurrentSnapshotID
+		for _, snapshot := range metadata.Snapshots {
+			if snapshot.SnapshotID != *parentSnapshotID {
+				continue
+			}
+			for _, total := range []struct{ total, added string }{
+				{"total-data-files", "added-data-files"},
+				{"total-records", "added-records"},
+				{"total-files-size", "added-files-size"},
+			} {
+				if value, err := strconv.ParseInt(snapshot.Summary[total.total], 10, 64); err == nil {
+					added, _ := strconv.ParseInt(summary[total.added], 10, 64)
+					summary[total.total] = strconv.FormatInt(value+added, 10)
+				}
+			}
+		}
+	} else {
+		summary["total-data-files"] = summary["added-data-files"]
+		summary["total-records"] = summary["added-records"]
+		summary["total-files-size"] = summary["added-files-size"]
+	}
+	snapshot := icebergSnapshot{
+		SnapshotID:       snapshotID,
+		ParentSnapshotID: parentSnapshotID,
+		SequenceNumber:   sequenceNumber,
+		TimestampMs:      now,
+		Summary:          summary,
+		ManifestList:     s.location(manifestListName),
+		SchemaID:         metadata.CurrentSchemaID,
+	}
+	if err := appendRaw(raw, "snapshots", snapshot); err != nil {
+		return err
+	}
+	if err := appendRaw(raw, "snapshot-log", map[string]int64{"timestamp-ms": now, "snapshot-id": snapshotID}); err != nil {
+		return err
+	}
+	if it.version > 0 {
+		if err := appendRaw(raw, "metadata-log", map[string]interface{}{"timestamp-ms": now, "metadata-file": s.location(icebergMetadataFile(it.version))}); err != nil {
+			return err
+		}
+	}
+	if err := setRaw(raw, map[string]interface{}{
+		"last-sequence-number": sequenceNumber,
+		"last-updated-ms":      now,
+		"current-snapshot-id":  snapshotID,
+		"refs":                 map[string]interface{}{"main": map[string]interface{}{"snapshot-id": snapshotID, "type": "branch"}},
+	}); err != nil {
+		return err
+	}
+
+	data, err := json.Marshal(raw)
+	if err != nil {
+		return fmt.Errorf("marshalling metadata: %w", err)
+	}
+	version := it.version + 1
+	if err := s.put(ctx, icebergMetadataFile(version), data); err != nil {
+		return err
+	}
+	if err := s.put(ctx, icebergVersionHint, []byte(strconv.Itoa(version))); err != nil {
+		return err
+	}
+	return it.parse(version, data)
+}
+
+// evolve returns the metadata of the table with the columns it doesn't have yet, creating the table if it doesn't exist
+func (it *icebergTable) evolve(s *store, columns []outputformat.Column) (map[string]json.RawMessage, *icebergMetadata, error) {
+	raw := make(map[string]json.RawMessage, len(it.raw))
+	for k, v := range it.raw {
+		raw[k] = v
+	}
+	if it.metadata == nil {
+		if err := setRaw(raw, map[string]interface{}{
+			"format-version":        2,
+			"table-uuid":            uuid.New().String(),
+			"location":              s.location(""),
+			"last-sequence-number":  0,
+			"last-updated-ms":       time.Now().UnixMilli(),
+			"last-column-id":        0,
+			"current-schema-id":     0,
+			"schemas":               []icebergSchema{{Type: "struct", SchemaID: 0, Fields: []json.RawMessage{}}},
+			"default-spec-id":       0,
+			"partition-specs":       []icebergPartitionSpec{{SpecID: 0, Fields: []json.RawMessage{}}},
+			"last-partition-id":     999,
+			"default-sort-order-id": 0,
+			"sort-orders":           []map[string]interface{}{{"order-id": 0, "fields": []interface{}{}}},
+			"properties":            map[string]string{"write.format.default": "parquet"},
+			"current-snapshot-id":   -1,
+			"refs":                  map[string]interface{}{},
+			"snapshots":             []icebergSnapshot{},
+			"snapshot-log":          []interface{}{},
+			"metadata-log":          []interface{}{},
+			"partition-statistics":  []interface{}{},
+			"statistics":            []interface{}{},
+		}); err != nil {
+			return nil, nil, err
+		}
+	}
+	var metadata icebergMetadata
+	if err := unmarshalRaw(raw, &metadata); err != nil {
+		return nil, nil, err
+	}
+	current, err := currentIcebergSchema(&metadata)
+	if err != nil {
+		return nil, nil, err
+	}
+	var fields []icebergField
+	for _, f := range current.Fields {
+		var field icebergField
+		if err := json.Unmarshal(f, &field); err != nil {
+			return nil, nil, fmt.Errorf("unmarshalling field: %w", err)
+		}
+		fields = append(fields, field)
+	}
+	existing := make(map[string]bool, len(fields))
+	for _, f := range fields {
+		existing[strings.ToLower(f.Name)] = true
+	}
+	evolved := icebergSchema{Type: "struct", Fields: append([]json.RawMessage(nil), current.Fields...)}
+	for _, c := range columns {
+		if existing[strings.ToLower(c.Name)] {
+			continue
+		}
+		metadata.LastColumnID++
+		field := icebergField{ID: metadata.LastColumnID, Name: c.Name, Type: icebergTypes[c.Type]}
+		data, err := json.Marshal(field)
+		if err != nil {
+			return nil, nil, fmt.Errorf("marshalling field: %w", err)
+		}
+		evolved.Fields = append(evolved.Fields, data)
+		fields = append(fields, field)
+	}
+	if len(evolved.Fields) > len(current.Fields) {
+		for _, s := range metadata.Schemas {
+			if s.SchemaID >= evolved.SchemaID {
+				evolved.SchemaID = s.SchemaID + 1
+			}
+		}
+		metadata.Schemas = append(metadata.Schemas, evolved)
+		metadata.CurrentSchemaID = evolved.SchemaID
+		if it.metadata == nil {
+			// the table is created along with its first schema
+			metadata.Schemas = []icebergSchema{{Type: "struct", SchemaID: 0, Fields: evolved.Fields}}
+			metadata.CurrentSchemaID = 0
+		}
+		type nameMapping struct {
+			FieldID int      `json:"field-id"`
+			Names   []string `json:"names"`
+		}
+		mappings := make([]nameMapping, len(fields))
+		for i, f := range fields {
+			mappings[i] = nameMapping{FieldID: f.ID, Names: []string{f.Name}}
+		}
+		mapping, err := json.Marshal(mappings)
+		if err != nil {
+			return nil, nil, fmt.Errorf("marshalling name mapping: %w", err)
+		}
+		if metadata.Properties == nil {
+			metadata.Properties = map[string]string{}
+		}
+		metadata.Properties[icebergNameMapping] = string(mapping)
+		if err := setRaw(raw, map[string]interface{}{
+			"last-column-id":    metadata.LastColumnID,
+			"current-schema-id": metadata.CurrentSchemaID,
+			"schemas":           metadata.Schemas,
+			"properties":        metadata.Properties,
+		}); err != nil {
+			return nil, nil, err
+		}
+	}
+	return raw, &metadata, nil
+}
+
+// manifest returns a manifest file, listing the data file as added by the snapshot
+func (*icebergTable) manifest(metadata *icebergMetadata, snapshotID, sequenceNumber int64, filePath string, file DataFile) ([]byte, error) {
+	schema, err := currentIcebergSchema(metadata)
+	if err != nil {
+		return nil, err
+	}
+	schemaJSON, err := json.Marshal(schema)
+	if err != nil {
+		return nil, fmt.Errorf("marshalling schema: %w", err)
+	}
+	return writeAvro(icebergManifestSchema, map[string][]byte{
+		"schema":            schemaJSON,
+		"schema-id":         []byte(strconv.Itoa(schema.SchemaID)),
+		"partition-spec":    []byte("[]"),
+		"partition-spec-id": []byte(strconv.Itoa(metadata.DefaultSpecID)),
+		"format-version":    []byte("2"),
+		"content":           []byte("data"),
+	}, []interface{}{map[string]interface{}{
+		"status":               int32(1), // added
+		"snapshot_id":          goavro.Union("long", snapshotID),
+		"sequence_number":      goavro.Union("long", sequenceNumber),
+		"file_sequence_number": goavro.Union("long", sequenceNumber),
+		"data_file": map[string]interface{}{
+			"content":            int32(0), // data
+			"file_path":          filePath,
+			"file_format":        "PARQUET",
+			"partition":          map[string]interface{}{},
+			"record_count":       file.Records,
+			"file_size_in_bytes": file.Size,
+		},
+	}})
+}
+
+// manifestList returns the manifest list of a snapshot, consisting of the manifests of the current snapshot and the provided one
+func (*icebergTable) manifestList(ctx context.Context, s *store, metadata *icebergMetadata, manifest map[string]interface{}) ([]byte, error) {
+	var records []interface{}
+	for _, snapshot := range metadata.Snapshots {
+		if metadata.CurrentSnapshotID == nil || snapshot.SnapshotID != *metadata.CurrentSnapshotID {
+			continue
+		}
+		prefix := s.location("") + "/"
+		if !strings.HasPrefix(snapshot.ManifestList, prefix) {
+			return nil, fmt.Errorf("manifest list %s is not part of the table", snapshot.ManifestList)
+		}
+		data, err := s.get(ctx, strings.TrimPrefix(snapshot.ManifestList, prefix))
+		if err != nil {
+			return nil, err
+		}
+		r, err := goavro.NewOCFReader(bytes.NewReader(data))
+		if err != nil {
+			return nil, fmt.Errorf("reading manifest list: %w", err)
+		}
+		for r.Scan() {
+			record, err := r.Read()
+			if err != nil {
+				return nil, fmt.Errorf("reading manifest list: %w", err)
+			}
+			records = append(records, record)
+		}
+		if err := r.Err(); err != nil {
+			return nil, fmt.Errorf("reading manifest list: %w", err)
+		}
+	}
+	records = append(records, manifest)
+	return writeAvro(icebergManifestListSchema, map[string][]byte{
+		"snapshot-id":     []byte(strconv.FormatInt(manifest["added_snapshot_id"].(int64), 10)),
+		"sequence-number": []byte(strconv.FormatInt(manifest["sequence_number"].(int64), 10)),
+		"format-version":  []byte("2"),
+	}, records)
+}
+
+// read reads the provided version of the table, if any
+func (it *icebergTable) read(ctx context.Context, s *store, version int) error {
+	if version == 0 {
+		it.version, it.raw, it.metadata = 0, map[string]json.RawMessage{}, nil
+		return nil
+	}
+	data, err := s.get(ctx, icebergMetadataFile(version))
+	if err != nil {
+		return err
+	}
+	return it.parse(version, data)
+}
+
+func (it *icebergTable) parse(version int, data []byte) error {
+	var raw map[string]json.RawMessage
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return fmt.Errorf("unmarshalling metadata of version %d: %w", version, err)
+	}
+	var metadata icebergMetadata
+	if err := unmarshalRaw(raw, &metadata); err != nil {
+		return err
+	}
+	if metadata.FormatVersion != 2 {
+		return fmt.Errorf("unsupported format version %d", metadata.FormatVersion)
+	}
+	for _, spec := range metadata.PartitionSpecs {
+		if spec.SpecID == metadata.DefaultSpecID && len(spec.Fields) > 0 {
+			return fmt.Errorf("partitioned tables are not supported")
+		}
+	}
+	it.version, it.raw, it.metadata = version, raw, &metadata
+	return nil
+}
+
+func (it *icebergTable) fields() ([]icebergField, error) {
+	schema, err := currentIcebergSchema(it.metadata)
+	if err != nil {
+		return nil, err
+	}
+	fields := make([]icebergField, len(schema.Fields))
+	for i, f := range schema.Fields {
+		if err := json.Unmarshal(f, &fields[i]); err != nil {
+			return nil, fmt.Errorf("unmarshalling field: %w", err)
+		}
+	}
+	return fields, nil
+}
+
+func currentIcebergSchema(metadata *icebergMetadata) (*icebergSchema, error) {
+	for i := range metadata.Schemas {
+		if metadata.Schemas[i].SchemaID == metadata.CurrentSchemaID {
+			return &metadata.Schemas[i], nil
+		}
+	}
+	return nil, fmt.Errorf("current schema %d not found", metadata.CurrentSchemaID)
+}
+
+func icebergMetadataFile(version int) string {
+	return fmt.Sprintf("%s/v%d.metadata.json", icebergMetadataDir, version)
+}
+
+func fromIcebergType(name string, typ interface{}) string {
+	switch typ {
+	case "boolean":
+		return outputformat.TypeBoolean
+	case "double", "float":
+		return outputformat.TypeFloat
+	case "string":
+		if name == outputformat.ExtraColumn {
+			return outputformat.TypeJSON
+		}
+		return outputformat.TypeString
+	case "timestamptz":
+		return outputformat.TypeDatetime
+	default:
+		return unknownType
+	}
+}
+
+// writeAvro returns an avro file with the provided records
+func writeAvro(schema string, metadata map[string][]byte, records []interface{}) ([]byte, error) {
+	var buf bytes.Buffer
+	w, err := goavro.NewOCFWriter(goavro.OCFConfig{W: &buf, Schema: schema, MetaData: metadata})
+	if err != nil {
+		return nil, fmt.Errorf("creating avro writer: %w", err)
+	}
+	if err := w.Append(records); err != nil {
+		return nil, fmt.Errorf("writing avro records: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+func setRaw(raw map[string]json.RawMessage, values map[string]interface{}) error {
+	for k, v := range values {
+		data, err := json.Marshal(v)
+		if err != nil {
+			return fmt.Errorf("marshalling %s: %w", k, err)
+		}
+		raw[k] = data
+	}
+	return nil
+}
+
+func appendRaw(raw map[string]json.RawMessage, key string, value interface{}) error {
+	var values []json.RawMessage
+	if data, ok := raw[key]; ok && string(data) != "null" {
+		if err := json.Unmarshal(data, &values); err != nil {
+			return fmt.Errorf("unmarshalling %s: %w", key, err)
+		}
+	}
+	data, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Errorf("marshalling %s: %w", key, err)
+	}
+	return setRaw(raw, map[string]interface{}{key: append(values, data)})
+}
+
+func unmarshalRaw(raw map[string]json.RawMessage, v interface{}) error {
+	data, err := json.Marshal(raw)
+	if err != nil {
+		return fmt.Errorf("marshalling metadata: %w", err)
+	}
+	if err := json.Unmarshal(data, v); err != nil {
+		return fmt.Errorf("unmarshalling metadata: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,111 @@
+package tableformat
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/rudderlabs/rudder-go-kit/filemanager"
+)
+
+// listPageSize is the number of objects listed at a time
+const listPageSize = 1000
+
+// store reads and writes the objects of a table, which are named by their paths relative to the table's root
+type store struct {
+	fm     filemanager.FileManager
+	bucket string
+	root   string // relative to the file manager's prefix
+}
+
+// key returns the object key of the named object
+func (s *store) key(name string) string {
+	return path.Join(s.fm.Prefix(), s.root, name)
+}
+
+// name returns the name of the object with the provided key
+func (s *store) name(key string) string {
+	return strings.TrimPrefix(key, s.key("")+"/")
+}
+
+// location returns the uri of the named object
+func (s *store) location(name string) string {
+	return "s3://" + s.bucket + "/" + s.key(name)
+}
+
+// get downloads the named object
+func (s *store) get(ctx context.Context, name string) ([]byte, error) {
+	dir, err := s.tmpDir()
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = os.RemoveAll(dir) }()
+	f, err := os.Create(filepath.Join(dir, path.Base(name)))
+	if err != nil {
+		return nil, fmt.Errorf("creating file: %w", err)
+	}
+	defer func() { _ = f.Close() }()
+	if err := s.fm.Download(ctx, f, s.key(name)); err != nil {
+		return nil, fmt.Errorf("downloading %s: %w", name, err)
+	}
+	data, err := os.ReadFile(f.Name())
+	if err != nil {
+		return nil, fmt.Errorf("reading %s: %w", name, err)
+	}
+	return data, nil
+}
+
+// put uploads the named object
+func (s *store) put(ctx context.Context, name string, data []byte) error {
+	dir, err := s.tmpDir()
+	if err != nil {
+		return err
+	}
+	defer func() { _ = os.RemoveAll(dir) }()
+	filePath := filepath.Join(dir, path.Base(name))
+	if err := os.WriteFile(filePath, data, 0o600); err != nil {
+		return fmt.Errorf("writing %s: %w", name, err)
+	}
+	f, err := os.Open(filePath)
+	if err != nil {
+		return fmt.Errorf("opening %s: %w", name, err)
+	}
+	defer func() { _ = f.Close() }()
+	if _, err := s.fm.Upload(ctx, f, strings.Split(path.Join(s.root, path.Dir(name)), "/")...); err != nil {
+		return fmt.Errorf("uploading %s: %w", name, err)
+	}
+	return nil
+}
+
+// list returns the names of the objects starting with the provided name prefix, sorted by key.
+// Only objects with keys after startAfter are listed, if provided.
+func (s *store) list(ctx context.Context, prefix, startAfter string) ([]string, error) {
+	if startAfter != "" {
+		startAfter = s.key(startAfter)
+	}
+	session := s.fm.ListFilesWithPrefix(ctx, startAfter, s.key(prefix), listPageSize)
+	var names []string
+	for {
+		files, err := session.Next()
+		if err != nil {
+			return nil, fmt.Errorf("listing %s: %w", prefix, err)
+		}
+		if len(files) == 0 {
+			return names, nil
+		}
+		for _, f := range files {
+			names = append(names, s.name(f.Key))
+		}
+	}
+}
+
+func (*store) tmpDir() (string, error) {
+	dir, err := os.MkdirTemp("", "rudder-table-format")
+	if err != nil {
+		return "", fmt.Errorf("creating temporary directory: %w", err)
+	}
+	return dir, nil
+}
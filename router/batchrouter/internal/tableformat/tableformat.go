@@ -0,0 +1,178 @@
+// Package tableformat commits the files uploaded to object storage destinations into Delta Lake or Apache Iceberg tables.
+//
+// S3 and MinIO destinations can opt for a table format through their config, e.g.
+//
+//	"tableFormat": "iceberg", "tablePath": "events/tracks"
+//
+// in which case their files are written as parquet files under the data folder of the table and, once uploaded, committed
+// into the table by writing the table's metadata files next to them, without the need for a query engine. The table's root
+// defaults to <folder>/<destinationID>. Delta Lake tables keep their transaction log under _delta_log, whereas Iceberg tables
+// use a file based catalog, keeping their versioned metadata files under metadata along with a version-hint.text file.
+//
+// The schema of the files is seeded from the table's schema, so new fields of the events are added as new columns of the
+// table, whereas existing columns keep their type. Tables are unpartitioned and expected to have a single writer.
+// The output format and path template of the destination, if any, don't apply to tables.
+package tableformat
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"strings"
+	"sync"
+
+	"github.com/rudderlabs/rudder-go-kit/filemanager"
+	"github.com/rudderlabs/rudder-server/router/batchrouter/internal/outputformat"
+)
+
+const (
+	// ConfigKey is the key of the destination config holding the table format
+	ConfigKey = "tableFormat"
+	// PathConfigKey is the key of the destination config holding the root of the table, relative to the destination's prefix
+	PathConfigKey = "tablePath"
+)
+
+// Providers are the object storage providers supporting table formats
+var Providers = []string{"S3", "MINIO"}
+
+// Format is a table format
+type Format string
+
+const (
+	None    Format = ""
+	Delta   Format = "delta"
+	Iceberg Format = "iceberg"
+)
+
+// FromDestinationConfig returns the table format of the destination's config, defaulting to [None]
+func FromDestinationConfig(destConfig map[string]interface{}) (Format, error) {
+	raw, ok := destConfig[ConfigKey]
+	if !ok || raw == nil {
+		return None, nil
+	}
+	value, ok := raw.(string)
+	if !ok {
+		return None, fmt.Errorf("invalid %s config: expected a string, got %T", ConfigKey, raw)
+	}
+	switch f := Format(strings.ToLower(strings.TrimSpace(value))); f {
+	case None, Delta, Iceberg:
+		return f, nil
+	default:
+		return None, fmt.Errorf("unsupported table format %q", value)
+	}
+}
+
+// PathFromDestinationConfig returns the root of the destination's table, defaulting to <folder>/<destinationID>
+func PathFromDestinationConfig(destConfig map[string]interface{}, folder, destinationID string) string {
+	if value, _ := destConfig[PathConfigKey].(string); strings.Trim(value, "/ ") != "" {
+		return path.Clean(strings.Trim(value, "/ "))
+	}
+	return path.Join(folder, destinationID)
+}
+
+// DataFile is a parquet file uploaded under the data folder of a table
+type DataFile struct {
+	Key     string // the object key of the file, as returned by the file manager
+	Size    int64
+	Records int64
+}
+
+// committer implements a table format
+type committer interface {
+	// load reads the latest version of the table, returning its columns, or nil if the table doesn't exist yet
+	load(ctx context.Context, s *store) ([]outputformat.Column, error)
+	// commit adds the data file to the table, adding the columns the table doesn't have yet
+	commit(ctx context.Context, s *store, file DataFile, columns []outputformat.Column) error
+}
+
+// NewTable creates a table of the provided format, rooted at the provided path of the bucket
+func NewTable(format Format, bucket, root string, maxColumns int) (*Table, error) {
+	t := &Table{format: format, bucket: bucket, root: root, maxColumns: maxColumns}
+	switch format {
+	case Delta:
+		t.committer = &deltaTable{}
+	case Iceberg:
+		t.committer = &icebergTable{}
+	default:
+		return nil, fmt.Errorf("unsupported table format %q", format)
+	}
+	return t, nil
+}
+
+// Table is a table of an object storage destination. The state of the table is loaded on first use and kept in memory,
+// checking for newer versions of the table on every commit.
+type Table struct {
+	format     Format
+	bucket     string
+	root       string
+	maxColumns int
+
+	mu        sync.Mutex
+	schema    *outputformat.Schema
+	committer committer
+}
+
+// Format returns the format of the table
+func (t *Table) Format() Format {
+	return t.format
+}
+
+// Root returns the root of the table, relative to the destination's prefix
+func (t *Table) Root() string {
+	return t.root
+}
+
+// Bucket returns the bucket of the table
+func (t *Table) Bucket() string {
+	return t.bucket
+}
+
+// DataPrefixes returns the key prefixes of the table's data files
+func (t *Table) DataPrefixes() []string {
+	return strings.Split(path.Join(t.root, "data"), "/")
+}
+
+// Schema returns the schema of the table's data files, seeded from the table's columns
+func (t *Table) Schema(ctx context.Context, fm filemanager.FileManager) (*outputformat.Schema, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.loadSchema(ctx, t.store(fm))
+}
+
+func (t *Table) loadSchema(ctx context.Context, s *store) (*outputformat.Schema, error) {
+	if t.schema != nil {
+		return t.schema, nil
+	}
+	columns, err := t.committer.load(ctx, s)
+	if err != nil {
+		return nil, fmt.Errorf("loading %s table %s: %w", t.format, t.root, err)
+	}
+	schema := outputformat.NewSchema(t.maxColumns)
+	schema.Seed(columns)
+	t.schema = schema
+	return schema, nil
+}
+
+// Commit adds the data file to the table, evolving the table's schema to the columns of the file
+func (t *Table) Commit(ctx context.Context, fm filemanager.FileManager, file DataFile, columns []outputformat.Column) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	s := t.store(fm)
+	if !strings.HasPrefix(file.Key, s.key("data")+"/") {
+		return fmt.Errorf("data file %s is not part of table %s", file.Key, t.root)
+	}
+	if _, err := t.loadSchema(ctx, s); err != nil {
+		return err
+	}
+	if err := t.committer.commit(ctx, s, file, columns); err != nil {
+		return fmt.Errorf("committing %s into %s table %s: %w", file.Key, t.format, t.root, err)
+	}
+	return nil
+}
+
+func (t *Table) store(fm filemanager.FileManager) *store {
+	return &store{fm: fm, bucket: t.bucket, root: t.root}
+}
+
+// unknownType is the type of columns with a type which can't be written, so that their names are reserved
+const unknownType = ""
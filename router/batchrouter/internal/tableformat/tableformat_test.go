@@ -0,0 +1,189 @@
+package tableformat_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/linkedin/goavro/v2"
+	"github.com/ory/dockertest/v3"
+	"github.com/stretchr/testify/require"
+
+	"github.com/rudderlabs/rudder-go-kit/filemanager"
+	"github.com/rudderlabs/rudder-server/router/batchrouter/internal/outputformat"
+	"github.com/rudderlabs/rudder-server/router/batchrouter/internal/tableformat"
+	"github.com/rudderlabs/rudder-server/testhelper/destination"
+	"github.com/rudderlabs/rudder-server/warehouse/encoding"
+)
+
+func TestFromDestinationConfig(t *testing.T) {
+	for destConfig, expected := range map[string]tableformat.Format{
+		`{}`:                        tableformat.None,
+		`{"tableFormat":""}`:        tableformat.None,
+		`{"tableFormat":"delta"}`:   tableformat.Delta,
+		`{"tableFormat":"Iceberg"}`: tableformat.Iceberg,
+	} {
+		var c map[string]interface{}
+		require.NoError(t, json.Unmarshal([]byte(destConfig), &c))
+		format, err := tableformat.FromDestinationConfig(c)
+		require.NoError(t, err, destConfig)
+		require.Equal(t, expected, format, destConfig)
+	}
+	_, err := tableformat.FromDestinationConfig(map[string]interface{}{"tableFormat": "hudi"})
+	require.Error(t, err)
+	_, err = tableformat.FromDestinationConfig(map[string]interface{}{"tableFormat": true})
+	require.Error(t, err)
+
+	require.Equal(t, "rudder-logs/destination-1", tableformat.PathFromDestinationConfig(map[string]interface{}{}, "rudder-logs", "destination-1"))
+	require.Equal(t, "events/tracks", tableformat.PathFromDestinationConfig(map[string]interface{}{"tablePath": "/events/tracks/"}, "rudder-logs", "destination-1"))
+}
+
+func TestTable(t *testing.T) {
+	encoding.Init()
+	pool, err := dockertest.NewPool("")
+	require.NoError(t, err)
+	minioResource, err := destination.SetupMINIO(pool, t)
+	require.NoError(t, err)
+
+	for _, format := range []tableformat.Format{tableformat.Delta, tableformat.Iceberg} {
+		format := format
+		t.Run(string(format), func(t *testing.T) {
+			ctx := context.Background()
+			fm, err := filemanager.New(&filemanager.Settings{
+				Provider: "MINIO",
+				Config: map[string]interface{}{
+					"bucketName":      minioResource.BucketName,
+					"prefix":          "prefix",
+					"endPoint":        minioResource.Endpoint,
+					"accessKeyID":     minioResource.AccessKey,
+					"secretAccessKey": minioResource.SecretKey,
+				},
+			})
+			require.NoError(t, err)
+			root := "tables/" + string(format)
+
+			table, err := tableformat.NewTable(format, minioResource.BucketName, root, 10)
+			require.NoError(t, err)
+			require.Equal(t, []string{"tables", string(format), "data"}, table.DataPrefixes())
+			schema, err := table.Schema(ctx, fm)
+			require.NoError(t, err)
+			require.Len(t, schema.Columns(), 2, "new table")
+
+			upload := func(name string, events ...string) tableformat.DataFile {
+				filePath := filepath.Join(t.TempDir(), name)
+				w, err := outputformat.NewWriter(outputformat.Parquet, filePath, "MINIO", schema)
+				require.NoError(t, err)
+				for _, event := range events {
+					require.NoError(t, w.Write([]byte(event)))
+				}
+				require.NoError(t, w.Close())
+				f, err := os.Open(filePath)
+				require.NoError(t, err)
+				defer func() { _ = f.Close() }()
+				fileInfo, err := f.Stat()
+				require.NoError(t, err)
+				output, err := fm.Upload(ctx, f, table.DataPrefixes()...)
+				require.NoError(t, err)
+				return tableformat.DataFile{Key: output.ObjectName, Size: fileInfo.Size(), Records: int64(len(events))}
+			}
+
+			file1 := upload("1.parquet", `{"messageId":"1","event":"a","sentAt":"2023-01-01T00:00:00Z"}`)
+			require.NoError(t, table.Commit(ctx, fm, file1, schema.Columns()))
+			require.NoError(t, table.Commit(ctx, fm, file1, schema.Columns()), "committing the same file again")
+			file2 := upload("2.parquet", `{"messageId":"2","event":"b","properties":{"revenue":10}}`)
+			require.NoError(t, table.Commit(ctx, fm, file2, schema.Columns()))
+
+			t.Run("reload", func(t *testing.T) {
+				reloaded, err := tableformat.NewTable(format, minioResource.BucketName, root, 10)
+				require.NoError(t, err)
+				reloadedSchema, err := reloaded.Schema(ctx, fm)
+				require.NoError(t, err)
+				require.Equal(t, []outputformat.Column{
+					{Name: "_extra", Type: outputformat.TypeJSON},
+					{Name: "event", Type: outputformat.TypeString},
+					{Name: "messageId", Type: outputformat.TypeString},
+					{Name: "properties_revenue", Type: outputformat.TypeFloat},
+					{Name: "sentAt", Type: outputformat.TypeDatetime},
+				}, reloadedSchema.Columns())
+				require.NoError(t, reloaded.Commit(ctx, fm, file2, reloadedSchema.Columns()), "committing the same file after a restart")
+			})
+
+			session := fm.ListFilesWithPrefix(ctx, "", "prefix/"+root+"/", 1000)
+			files, err := session.Next()
+			require.NoError(t, err)
+			var keys []string
+			for _, f := range files {
+				keys = append(keys, strings.TrimPrefix(f.Key, "prefix/"+root+"/"))
+			}
+
+			switch format {
+			case tableformat.Delta:
+				require.ElementsMatch(t, []string{
+					"_delta_log/00000000000000000000.json",
+					"_delta_log/00000000000000000001.json",
+					"data/1.parquet",
+					"data/2.parquet",
+				}, keys)
+				log := download(t, fm, "prefix/"+root+"/_delta_log/00000000000000000001.json")
+				require.Contains(t, string(log), `"path":"data/2.parquet"`)
+				require.Contains(t, string(log), `\"name\":\"properties_revenue\",\"type\":\"double\"`, "schema evolution")
+			case tableformat.Iceberg:
+				require.Contains(t, keys, "metadata/v1.metadata.json")
+				require.Contains(t, keys, "metadata/v2.metadata.json")
+				require.NotContains(t, keys, "metadata/v3.metadata.json")
+				require.Equal(t, "2", string(download(t, fm, "prefix/"+root+"/metadata/version-hint.text")))
+
+				var metadata struct {
+					CurrentSchemaID   int   `json:"current-schema-id"`
+					CurrentSnapshotID int64 `json:"current-snapshot-id"`
+					Schemas           []struct {
+						SchemaID int `json:"schema-id"`
+						Fields   []struct {
+							ID   int    `json:"id"`
+							Name string `json:"name"`
+							Type string `json:"type"`
+						} `json:"fields"`
+					} `json:"schemas"`
+					Snapshots []struct {
+						SnapshotID   int64  `json:"snapshot-id"`
+						ManifestList string `json:"manifest-list"`
+					} `json:"snapshots"`
+				}
+				require.NoError(t, json.Unmarshal(download(t, fm, "prefix/"+root+"/metadata/v2.metadata.json"), &metadata))
+				require.Equal(t, 1, metadata.CurrentSchemaID, "schema evolution")
+				require.Len(t, metadata.Schemas, 2)
+				require.Len(t, metadata.Schemas[1].Fields, 5)
+				require.Equal(t, "sentAt", metadata.Schemas[1].Fields[3].Name)
+				require.Equal(t, "timestamptz", metadata.Schemas[1].Fields[3].Type)
+				require.Len(t, metadata.Snapshots, 2)
+				require.Equal(t, metadata.Snapshots[1].SnapshotID, metadata.CurrentSnapshotID)
+
+				manifestList := strings.TrimPrefix(metadata.Snapshots[1].ManifestList, "s3://"+minioResource.BucketName+"/")
+				r, err := goavro.NewOCFReader(bytes.NewReader(download(t, fm, manifestList)))
+				require.NoError(t, err)
+				var manifests int
+				for r.Scan() {
+					_, err := r.Read()
+					require.NoError(t, err)
+					manifests++
+				}
+				require.Equal(t, 2, manifests, "manifests of both snapshots")
+			}
+		})
+	}
+}
+
+func download(t *testing.T, fm filemanager.FileManager, key string) []byte {
+	t.Helper()
+	f, err := os.Create(filepath.Join(t.TempDir(), filepath.Base(key)))
+	require.NoError(t, err)
+	defer func() { _ = f.Close() }()
+	require.NoError(t, fm.Download(context.Background(), f, key))
+	data, err := os.ReadFile(f.Name())
+	require.NoError(t, err)
+	return data
+}
@@ -5,6 +5,8 @@ import (
 
 	backendconfig "github.com/rudderlabs/rudder-server/backend-config"
 	"github.com/rudderlabs/rudder-server/jobsdb"
+	"github.com/rudderlabs/rudder-server/router/batchrouter/internal/outputformat"
+	"github.com/rudderlabs/rudder-server/router/batchrouter/internal/tableformat"
 	router_utils "github.com/rudderlabs/rudder-server/router/utils"
 )
 
@@ -40,6 +42,28 @@ type ObjectStorageDefinition struct {
 	Provider        string
 	DestinationID   string
 	DestinationType string
+	Format          outputformat.Format
+	ManifestKey     string
+	TableFormat     tableformat.Format
+	WorkspaceID     string
+}
+
+const (
+	// manifestFolder is the folder holding the manifests, mirroring the keys of the objects they describe.
+	// Its name starts with an underscore, so that it is ignored by readers of the destination folder (e.g. Athena, Spark).
+	manifestFolder = "_manifests"
+	// manifestExtension is the extension of manifests, which are named after the objects they describe
+	manifestExtension = ".manifest.json"
+)
+
+// Manifest is uploaded for every object of a destination with exactly once uploads, or a table, once the object itself
+// is uploaded, listing the jobs found in the object. Readers can rely on objects with a manifest being complete,
+// whereas the batch router relies on manifests for marking the jobs of objects uploaded before a crash as succeeded.
+type Manifest struct {
+	Key         string              `json:"key"`
+	Format      outputformat.Format `json:"format"`
+	JobIDs      []int64             `json:"jobIds"`
+	TotalEvents int                 `json:"totalEvents"`
 }
 
 type batchRequestMetric struct {
@@ -48,11 +72,14 @@ type batchRequestMetric struct {
 }
 
 type UploadResult struct {
-	Config           map[string]interface{}
-	Key              string
-	FileLocation     string
+	Config       map[string]interface{}
+	Key          string
+	FileLocation string
+	// Keys and FileLocations contain every uploaded object, since a batch can be uploaded as multiple partitions
+	Keys             []string
+	FileLocations    []string
 	LocalFilePaths   []string
-	JournalOpID      int64
+	JournalOpIDs     []int64
 	Error            error
 	FirstEventAt     string
 	LastEventAt      string
@@ -70,18 +97,3 @@ type BatchedJobs struct {
 	Connection *Connection
 	TimeWindow time.Time
 }
-
-type AsyncPoll struct {
-	Config   map[string]interface{} `json:"config"`
-	ImportId string                 `json:"importId"`
-	DestType string                 `json:"destType"`
-}
-
-type AsyncStatusResponse struct {
-	Success        bool
-	StatusCode     int
-	HasFailed      bool
-	HasWarning     bool
-	FailedJobsURL  string
-	WarningJobsURL string
-}
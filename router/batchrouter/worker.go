@@ -15,6 +15,7 @@ import (
 	"github.com/rudderlabs/rudder-go-kit/stats"
 	backendconfig "github.com/rudderlabs/rudder-server/backend-config"
 	"github.com/rudderlabs/rudder-server/jobsdb"
+	"github.com/rudderlabs/rudder-server/router/batchrouter/asyncdestinationmanager"
 	router_utils "github.com/rudderlabs/rudder-server/router/utils"
 	"github.com/rudderlabs/rudder-server/rruntime"
 	"github.com/rudderlabs/rudder-server/services/rmetrics"
@@ -205,8 +206,8 @@ func (w *worker) processJobAsync(jobsWg *sync.WaitGroup, destinationJobs *Destin
 					brt.recordDeliveryStatus(*batchedJobs.Connection, output, false)
 					brt.updateJobStatus(&batchedJobs, false, output.Error, false)
 					misc.RemoveFilePaths(output.LocalFilePaths...)
-					if output.JournalOpID > 0 {
-						brt.jobsDB.JournalDeleteEntry(output.JournalOpID)
+					for _, opID := range output.JournalOpIDs {
+						brt.jobsDB.JournalDeleteEntry(opID)
 					}
 					if output.Error == nil {
 						brt.recordUploadStats(*batchedJobs.Connection, output)
@@ -235,7 +236,7 @@ func (w *worker) processJobAsync(jobsWg *sync.WaitGroup, destinationJobs *Destin
 						misc.RemoveFilePaths(output.LocalFilePaths...)
 					}
 					destUploadStat.Since(destUploadStart)
-				case slices.Contains(asyncDestinations, brt.destType):
+				case asyncdestinationmanager.IsAsyncDestination(brt.destType):
 					destUploadStat := stats.Default.NewStat(fmt.Sprintf(`batch_router.%s_dest_upload_time`, brt.destType), stats.TimerType)
 					destUploadStart := time.Now()
 					brt.sendJobsToStorage(batchedJobs)
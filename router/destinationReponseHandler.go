@@ -2,12 +2,14 @@ package router
 
 import (
 	"fmt"
+	"net/http"
 	"reflect"
 	"strings"
 
 	"github.com/tidwall/gjson"
 
 	"github.com/rudderlabs/rudder-go-kit/logger"
+	"github.com/rudderlabs/rudder-server/router/internal/expression"
 )
 
 // ResponseHandler - handle destination response
@@ -15,6 +17,20 @@ type ResponseHandler interface {
 	IsSuccessStatus(respCode int, respBody string) (returnCode int)
 }
 
+// ResponseClassifier - a response handler which can also attach a custom error type to the responses it classifies
+type ResponseClassifier interface {
+	ResponseHandler
+	Classify(respCode int, respBody string) (returnCode int, errorType string)
+}
+
+// classifyResponse returns the status code and the error type (if any) of a destination response, as classified by the handler
+func classifyResponse(handler ResponseHandler, respCode int, respBody string) (returnCode int, errorType string) {
+	if classifier, ok := handler.(ResponseClassifier); ok {
+		return classifier.Classify(respCode, respBody)
+	}
+	return handler.IsSuccessStatus(respCode, respBody), ""
+}
+
 // jsonResponseHandler handler for json response
 type jsonResponseHandler struct {
 	logger         logger.Logger
@@ -47,7 +63,25 @@ func getRulesArrForKey(key string, rules map[string]interface{}) []map[string]in
 }
 
 // NewResponseHandler returns a destination response handler. Can be nil(Check before using this)
+//
+// Apart from the json rules, response rules can contain a list of expressions (see package router/internal/expression),
+// which are evaluated in order against the response's status code and body. The first matching expression decides the
+// outcome of the response, along with an optional error type which is reported for it, e.g.
+//
+//	"expressions": [
+//		{"when": "status == 200 && body.error.code =~ '^RATE_LIMIT'", "outcome": "throttled", "errorType": "RATE_LIMITED"},
+//		{"when": "status == 200 && body.success == false", "outcome": "abortable", "errorType": "INVALID_EVENT"}
+//	]
+//
+// Supported outcomes are success, retryable, throttled and abortable.
 func NewResponseHandler(logger logger.Logger, responseRules map[string]interface{}) ResponseHandler {
+	if expressionRules := getExpressionRules(logger, responseRules); len(expressionRules) > 0 {
+		return &expressionResponseHandler{rules: expressionRules, fallback: newRulesResponseHandler(logger, responseRules)}
+	}
+	return newRulesResponseHandler(logger, responseRules)
+}
+
+func newRulesResponseHandler(logger logger.Logger, responseRules map[string]interface{}) ResponseHandler {
 	if responseType, ok := responseRules["responseType"]; !ok || reflect.TypeOf(responseType).Kind() != reflect.String {
 		return nil
 	}
@@ -153,3 +187,66 @@ func (*txtResponseHandler) IsSuccessStatus(respCode int, _ string) (returnCode i
 	returnCode = respCode
 	return
 }
+
+// expressionResponseHandler -- start
+
+// outcome status codes
+var expressionOutcomes = map[string]int{
+	"success":   http.StatusOK,
+	"retryable": http.StatusInternalServerError, // Rudder retry code
+	"throttled": http.StatusTooManyRequests,     // Rudder throttle code
+	"abortable": http.StatusBadRequest,          // Rudder abort code
+}
+
+type expressionRule struct {
+	expression *expression.Expression
+	statusCode int
+	errorType  string
+}
+
+// expressionResponseHandler handler classifying responses using expressions
+type expressionResponseHandler struct {
+	rules    []expressionRule
+	fallback ResponseHandler // handler to use if no expression matches, can be nil
+}
+
+func getExpressionRules(logger logger.Logger, responseRules map[string]interface{}) []expressionRule {
+	var rules []expressionRule
+	for _, value := range getRulesArrForKey("expressions", responseRules) {
+		when, _ := value["when"].(string)
+		outcome, _ := value["outcome"].(string)
+		errorType, _ := value["errorType"].(string)
+		statusCode, ok := expressionOutcomes[outcome]
+		if !ok {
+			logger.Errorf("Ignoring response rule expression %q with unsupported outcome %q", when, outcome)
+			continue
+		}
+		e, err := expression.Parse(when)
+		if err != nil {
+			logger.Errorf("Ignoring invalid response rule expression %q: %v", when, err)
+			continue
+		}
+		rules = append(rules, expressionRule{expression: e, statusCode: statusCode, errorType: errorType})
+	}
+	return rules
+}
+
+// IsSuccessStatus - returns the status code based on the response code and body
+func (handler *expressionResponseHandler) IsSuccessStatus(respCode int, respBody string) (returnCode int) {
+	returnCode, _ = handler.Classify(respCode, respBody)
+	return
+}
+
+// Classify - returns the status code and error type of the first expression matching the response code and body
+func (handler *expressionResponseHandler) Classify(respCode int, respBody string) (returnCode int, errorType string) {
+	env := expression.Env{Status: respCode, Body: respBody}
+	for _, rule := range handler.rules {
+		if rule.expression.Eval(env) {
+			return rule.statusCode, rule.errorType
+		}
+	}
+	if handler.fallback != nil {
+		return handler.fallback.IsSuccessStatus(respCode, respBody), ""
+	}
+	return respCode, ""
+}
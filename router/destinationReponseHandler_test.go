@@ -101,4 +101,62 @@ var _ = Describe("DestinationReponseHandler", func() {
 			Expect(jsonHandler).To(BeNil())
 		})
 	})
+
+	Context("Expression rules", func() {
+		var handler router.ResponseHandler
+		BeforeEach(func() {
+			config := `{
+			"responseType": "TXT",
+			"expressions": [
+				{ "when": "status == 200 && body.error.code =~ '^RATE_LIMIT'", "outcome": "throttled", "errorType": "RATE_LIMITED" },
+				{ "when": "status == 200 && body.success == false", "outcome": "abortable", "errorType": "INVALID_EVENT" },
+				{ "when": "status == 400 && contains(body, 'try again')", "outcome": "retryable" },
+				{ "when": "status ==", "outcome": "abortable" },
+				{ "when": "status == 201", "outcome": "unknown" }
+			]
+			}`
+			var rules map[string]interface{}
+			Expect(json.Unmarshal([]byte(config), &rules)).To(Succeed())
+			handler = router.NewResponseHandler(logger.NOP, rules)
+			Expect(handler).ToNot(BeNil())
+		})
+
+		It("classifies responses using the first matching expression", func() {
+			classifier, ok := handler.(router.ResponseClassifier)
+			Expect(ok).To(BeTrue())
+
+			status, errorType := classifier.Classify(200, `{"success": false, "error": {"code": "RATE_LIMIT_EXCEEDED"}}`)
+			Expect(status).To(Equal(429))
+			Expect(errorType).To(Equal("RATE_LIMITED"))
+
+			status, errorType = classifier.Classify(200, `{"success": false}`)
+			Expect(status).To(Equal(400))
+			Expect(errorType).To(Equal("INVALID_EVENT"))
+
+			status, errorType = classifier.Classify(400, `Please try again later`)
+			Expect(status).To(Equal(500))
+			Expect(errorType).To(BeEmpty())
+
+			Expect(handler.IsSuccessStatus(200, `{"success": false}`)).To(Equal(400))
+		})
+
+		It("passes the response code as is when no expression matches and ignores invalid expressions", func() {
+			Expect(handler.IsSuccessStatus(200, `{"success": true}`)).To(Equal(200))
+			Expect(handler.IsSuccessStatus(503, `{"success": true}`)).To(Equal(503))
+			Expect(handler.IsSuccessStatus(201, `{"success": true}`)).To(Equal(201))
+		})
+
+		It("falls back to json rules when no expression matches", func() {
+			config := `{
+			"responseType": "JSON",
+			"rules": { "retryable": [{ "success": false }] },
+			"expressions": [{ "when": "body.error.code == 'INVALID'", "outcome": "abortable" }]
+			}`
+			var rules map[string]interface{}
+			Expect(json.Unmarshal([]byte(config), &rules)).To(Succeed())
+			handler = router.NewResponseHandler(logger.NOP, rules)
+			Expect(handler.IsSuccessStatus(200, `{"success": false, "error": {"code": "INVALID"}}`)).To(Equal(400))
+			Expect(handler.IsSuccessStatus(200, `{"success": false}`)).To(Equal(500))
+		})
+	})
 })
@@ -25,13 +25,18 @@ import (
 	"github.com/rudderlabs/rudder-server/jobsdb"
 	"github.com/rudderlabs/rudder-server/processor/integrations"
 	customDestinationManager "github.com/rudderlabs/rudder-server/router/customdestinationmanager"
+	"github.com/rudderlabs/rudder-server/router/internal/circuitbreaker"
 	"github.com/rudderlabs/rudder-server/router/internal/jobiterator"
 	"github.com/rudderlabs/rudder-server/router/internal/partition"
+	"github.com/rudderlabs/rudder-server/router/internal/schedule"
 	"github.com/rudderlabs/rudder-server/router/isolation"
+	"github.com/rudderlabs/rudder-server/router/ordering"
+	"github.com/rudderlabs/rudder-server/router/priority"
 	rtThrottler "github.com/rudderlabs/rudder-server/router/throttler"
 	"github.com/rudderlabs/rudder-server/router/transformer"
 	"github.com/rudderlabs/rudder-server/router/types"
 	routerutils "github.com/rudderlabs/rudder-server/router/utils"
+	"github.com/rudderlabs/rudder-server/services/debugger/capture"
 	destinationdebugger "github.com/rudderlabs/rudder-server/services/debugger/destination"
 	"github.com/rudderlabs/rudder-server/services/diagnostics"
 	"github.com/rudderlabs/rudder-server/services/oauth"
@@ -81,10 +86,14 @@ type Handle struct {
 	oauth                          oauth.Authorizer
 	destinationsMapMu              sync.RWMutex
 	destinationsMap                map[string]*routerutils.DestinationWithSources // destinationID -> destination
+	nativeDeliveries               map[string]*nativeDelivery                     // destinationID -> native delivery, for destinations bypassing the transformer proxy
+	deliverySchedules              map[string]*schedule.Schedule                  // destinationID -> delivery schedule, for destinations with delivery windows
 	isBackendConfigInitialized     bool
 	backendConfigInitialized       chan bool
 	responseQ                      chan workerJobStatus
 	throttlingCosts                atomic.Pointer[types.EventTypeThrottlingCost]
+	circuitBreaker                 *circuitbreaker.Breaker
+	capturer                       *capture.Capturer // captures the http exchanges of destinations with capturing enabled, can be nil
 	batchInputCountStat            stats.Measurement
 	batchOutputCountStat           stats.Measurement
 	routerTransformInputCountStat  stats.Measurement
@@ -150,6 +159,7 @@ func (rt *Handle) pickup(ctx context.Context, partition string, workers []*worke
 
 	var firstJob *jobsdb.JobT
 	var lastJob *jobsdb.JobT
+	var pausedCount int // jobs not picked up due to their destination's delivery schedule
 
 	iterator := jobiterator.New(
 		rt.getQueryParams(partition, rt.reloadableConfig.jobQueryBatchSize),
@@ -200,17 +210,23 @@ func (rt *Handle) pickup(ctx context.Context, partition string, workers []*worke
 		statusList = nil
 	}
 
-	// Identify jobs which can be processed
-	for iterator.HasNext() {
-		if ctx.Err() != nil {
-			return 0, false
-		}
-		job := iterator.Next()
+	// with priority pickup enabled, every priority class gets a weighted share of the available worker slots.
+	// Jobs exceeding their class' share are deferred until the end of the pickup loop, so that they can only use
+	// the slots left over by the other classes.
+	var quota *priority.Quota
+	var deferredJobs []*jobsdb.JobT
+	var deferredPickupCount int                    // deferred jobs which were eventually picked up
+	deferredOrderKeys := make(map[string]struct{}) // order keys with deferred jobs
+	if rt.reloadableConfig.priorityPickup {
+		quota = priority.NewQuota(map[priority.Class]int{
+			priority.High:   rt.reloadableConfig.priorityWeights.high,
+			priority.Normal: rt.reloadableConfig.priorityWeights.normal,
+			priority.Low:    rt.reloadableConfig.priorityWeights.low,
+		}, lo.SumBy(workers, func(w *worker) int { return w.AvailableSlots() }))
+	}
 
-		if firstJob == nil {
-			firstJob = job
-		}
-		lastJob = job
+	// tryPickup tries to reserve a worker slot for the job, returning true if iteration should stop
+	tryPickup := func(job *jobsdb.JobT, deferred bool) (stop bool) {
 		slot, err := rt.findWorkerSlot(workers, job, blockedOrderKeys)
 		if err == nil {
 			status := jobsdb.JobStatusT{
@@ -227,27 +243,67 @@ func (rt *Handle) pickup(ctx context.Context, partition string, workers []*worke
 			}
 			statusList = append(statusList, &status)
 			reservedJobs = append(reservedJobs, reservedJob{slot: slot, job: job})
+			if quota != nil {
+				quota.Use(jobPriorityClass(job))
+			}
+			if deferred {
+				deferredPickupCount++
+			}
 			if shouldFlush() {
 				flush()
 			}
-		} else {
-			stats.Default.NewTaggedStat("router_iterator_stats_discarded_job_count", stats.CountType, stats.Tags{"destType": rt.destType, "partition": partition, "reason": err.Error()}).Increment()
+			return false
+		}
+		stats.Default.NewTaggedStat("router_iterator_stats_discarded_job_count", stats.CountType, stats.Tags{"destType": rt.destType, "partition": partition, "reason": err.Error()}).Increment()
+		if !deferred { // deferred jobs are already discarded from the iterator
 			iterator.Discard(job)
-			discardedCount++
-			if rt.stopIteration(err) {
-				break
-			}
 		}
+		discardedCount++
+		if errors.Is(err, types.ErrDestinationPausedBySchedule) {
+			pausedCount++
+		}
+		return rt.stopIteration(err)
+	}
+
+	// Identify jobs which can be processed
+	var stopped bool
+	for iterator.HasNext() {
+		if ctx.Err() != nil {
+			return 0, false
+		}
+		job := iterator.Next()
+
+		if firstJob == nil {
+			firstJob = job
+		}
+		lastJob = job
+		if quota != nil && rt.deferJob(job, quota, deferredOrderKeys) {
+			iterator.Discard(job) // allowing the iterator to fetch more jobs in place of the deferred one
+			deferredJobs = append(deferredJobs, job)
+			continue
+		}
+		if stopped = tryPickup(job, false); stopped {
+			break
+		}
+	}
+	// deferred jobs can use any slots left over, in job id order
+	for i := 0; i < len(deferredJobs) && !stopped; i++ {
+		if ctx.Err() != nil {
+			return 0, false
+		}
+		stopped = tryPickup(deferredJobs[i], true)
 	}
 	iteratorStats := iterator.Stats()
 	stats.Default.NewTaggedStat("router_iterator_stats_query_count", stats.GaugeType, stats.Tags{"destType": rt.destType, "partition": partition}).Gauge(iteratorStats.QueryCount)
 	stats.Default.NewTaggedStat("router_iterator_stats_total_jobs", stats.GaugeType, stats.Tags{"destType": rt.destType, "partition": partition}).Gauge(iteratorStats.TotalJobs)
 	stats.Default.NewTaggedStat("router_iterator_stats_discarded_jobs", stats.GaugeType, stats.Tags{"destType": rt.destType, "partition": partition}).Gauge(iteratorStats.DiscardedJobs)
+	stats.Default.NewTaggedStat("router_iterator_stats_paused_by_schedule_jobs", stats.GaugeType, stats.Tags{"destType": rt.destType, "partition": partition}).Gauge(pausedCount)
+	stats.Default.NewTaggedStat("router_iterator_stats_deferred_jobs", stats.GaugeType, stats.Tags{"destType": rt.destType, "partition": partition}).Gauge(len(deferredJobs))
 
 	flush()
 	rt.pipelineDelayStats(partition, firstJob, lastJob)
 	limitsReached = iteratorStats.LimitsReached
-	discardedRatio := float64(iteratorStats.DiscardedJobs) / float64(iteratorStats.TotalJobs)
+	discardedRatio := float64(iteratorStats.DiscardedJobs-deferredPickupCount) / float64(iteratorStats.TotalJobs)
 	// If the discarded ratio is greater than the penalty threshold,
 	// sleep for a while to avoid having a loop running continuously without producing events
 	if limitsReached && discardedRatio > rt.reloadableConfig.failingJobsPenaltyThreshold {
@@ -287,12 +343,15 @@ func (rt *Handle) commitStatusList(workerJobStatuses *[]workerJobStatus) {
 		if err != nil {
 			rt.logger.Error("Unmarshal of job parameters failed. ", string(workerJobStatus.job.Parameters))
 		}
+		// releasing the job in case it was picked up as a circuit breaker probe
+		rt.circuitBreaker.Done(parameters.DestinationID, workerJobStatus.job.JobID)
 		// Update metrics maps
 		// REPORTING - ROUTER - START
 		workspaceID := workerJobStatus.status.WorkspaceId
 		eventName := gjson.GetBytes(workerJobStatus.job.Parameters, "event_name").String()
 		eventType := gjson.GetBytes(workerJobStatus.job.Parameters, "event_type").String()
-		key := fmt.Sprintf("%s:%s:%s:%s:%s:%s:%s", parameters.SourceID, parameters.DestinationID, parameters.SourceJobRunID, workerJobStatus.status.JobState, workerJobStatus.status.ErrorCode, eventName, eventType)
+		errorType := gjson.GetBytes(workerJobStatus.status.ErrorResponse, "errorType").String()
+		key := fmt.Sprintf("%s:%s:%s:%s:%s:%s:%s:%s", parameters.SourceID, parameters.DestinationID, parameters.SourceJobRunID, workerJobStatus.status.JobState, workerJobStatus.status.ErrorCode, eventName, eventType, errorType)
 		_, ok := connectionDetailsMap[key]
 		if !ok {
 			cd := utilTypes.CreateConnectionDetail(parameters.SourceID, parameters.DestinationID, parameters.SourceTaskRunID, parameters.SourceJobID, parameters.SourceJobRunID, parameters.SourceDefinitionID, parameters.DestinationDefinitionID, parameters.SourceCategory, "", "", "", 0)
@@ -309,7 +368,7 @@ func (rt *Handle) commitStatusList(workerJobStatuses *[]workerJobStatus) {
 			if rt.transientSources.Apply(parameters.SourceID) {
 				sampleEvent = routerutils.EmptyPayload
 			}
-			sd = utilTypes.CreateStatusDetail(workerJobStatus.status.JobState, 0, 0, errorCode, string(workerJobStatus.status.ErrorResponse), sampleEvent, eventName, eventType, "")
+			sd = utilTypes.CreateStatusDetail(workerJobStatus.status.JobState, 0, 0, errorCode, string(workerJobStatus.status.ErrorResponse), sampleEvent, eventName, eventType, errorType)
 			statusDetailsMap[key] = sd
 		}
 
@@ -428,7 +487,7 @@ func (rt *Handle) commitStatusList(workerJobStatuses *[]workerJobStatus) {
 			userID := resp.userID
 			worker := resp.worker
 			if status != jobsdb.Failed.State {
-				orderKey := jobOrderKey(userID, gjson.GetBytes(resp.job.Parameters, "destination_id").String())
+				orderKey := jobOrderKey(userID, gjson.GetBytes(resp.job.Parameters, ordering.ParameterKey).String(), gjson.GetBytes(resp.job.Parameters, "destination_id").String())
 				rt.logger.Debugf("EventOrder: [%d] job %d for key %s %s", worker.id, resp.status.JobID, orderKey, status)
 				if err := worker.barrier.StateChanged(orderKey, resp.status.JobID, status); err != nil {
 					panic(err)
@@ -475,7 +534,7 @@ func (rt *Handle) findWorkerSlot(workers []*worker, job *jobsdb.JobT, blockedOrd
 		rt.logger.Errorf(`[%v Router] :: Unmarshalling parameters failed with the error %v . Returning nil worker`, err)
 		return nil, types.ErrParamsUnmarshal
 	}
-	orderKey := jobOrderKey(job.UserID, parameters.DestinationID)
+	orderKey := jobOrderKey(job.UserID, parameters.OrderingKey, parameters.DestinationID)
 
 	// checking if the orderKey is in blockedOrderKeys. If yes, returning nil.
 	// this check is done to maintain order.
@@ -484,6 +543,13 @@ func (rt *Handle) findWorkerSlot(workers []*worker, job *jobsdb.JobT, blockedOrd
 		return nil, types.ErrJobOrderBlocked
 	}
 
+	if rt.pausedBySchedule(parameters.DestinationID) {
+		if rt.guaranteeUserEventOrder {
+			blockedOrderKeys[orderKey] = struct{}{}
+		}
+		return nil, types.ErrDestinationPausedBySchedule
+	}
+
 	if !rt.guaranteeUserEventOrder {
 		availableWorkers := lo.Filter(workers, func(w *worker, _ int) bool { return w.AvailableSlots() > 0 })
 		if len(availableWorkers) == 0 {
@@ -492,13 +558,18 @@ func (rt *Handle) findWorkerSlot(workers []*worker, job *jobsdb.JobT, blockedOrd
 		if rt.shouldBackoff(job) {
 			return nil, types.ErrJobBackoff
 		}
+		if !rt.circuitBreaker.Allow(parameters.DestinationID, job.JobID) {
+			return nil, types.ErrDestinationCircuitOpen
+		}
 		if rt.shouldThrottle(job, parameters) {
+			rt.circuitBreaker.Done(parameters.DestinationID, job.JobID)
 			return nil, types.ErrDestinationThrottled
 		}
 
 		if slot := availableWorkers[rand.Intn(len(availableWorkers))].ReserveSlot(); slot != nil { // skipcq: GSC-G404
 			return slot, nil
 		}
+		rt.circuitBreaker.Done(parameters.DestinationID, job.JobID)
 		return nil, types.ErrWorkerNoSlot
 
 	}
@@ -509,8 +580,13 @@ func (rt *Handle) findWorkerSlot(workers []*worker, job *jobsdb.JobT, blockedOrd
 		blockedOrderKeys[orderKey] = struct{}{}
 		return nil, types.ErrJobBackoff
 	}
+	if !rt.circuitBreaker.Allow(parameters.DestinationID, job.JobID) {
+		blockedOrderKeys[orderKey] = struct{}{}
+		return nil, types.ErrDestinationCircuitOpen
+	}
 	slot := worker.ReserveSlot()
 	if slot == nil {
+		rt.circuitBreaker.Done(parameters.DestinationID, job.JobID)
 		blockedOrderKeys[orderKey] = struct{}{}
 		return nil, types.ErrWorkerNoSlot
 	}
@@ -522,6 +598,7 @@ func (rt *Handle) findWorkerSlot(workers []*worker, job *jobsdb.JobT, blockedOrd
 			blockedOrderKeys[orderKey] = struct{}{}
 			worker.barrier.Leave(orderKey, job.JobID)
 			slot.Release()
+			rt.circuitBreaker.Done(parameters.DestinationID, job.JobID)
 			return nil, types.ErrDestinationThrottled
 		}
 		return slot, nil
@@ -532,6 +609,7 @@ func (rt *Handle) findWorkerSlot(workers []*worker, job *jobsdb.JobT, blockedOrd
 	}
 	rt.logger.Debugf("EventOrder: job %d of orderKey %s is blocked (previousFailedJobID: %s)", job.JobID, orderKey, previousFailedJobIDStr)
 	slot.Release()
+	rt.circuitBreaker.Done(parameters.DestinationID, job.JobID)
 	return nil, types.ErrBarrierExists
 	//#EndJobOrder
 }
@@ -540,6 +618,39 @@ func (*Handle) shouldBackoff(job *jobsdb.JobT) bool {
 	return job.LastJobStatus.JobState == jobsdb.Failed.State && job.LastJobStatus.AttemptNum > 0 && time.Until(job.LastJobStatus.RetryTime) > 0
 }
 
+// deferJob returns true if the job's pickup needs to be deferred until the end of the pickup loop, either because its
+// priority class has used up its share of the available slots or, if user event order is guaranteed, because an earlier
+// job with the same order key has already been deferred
+func (rt *Handle) deferJob(job *jobsdb.JobT, quota *priority.Quota, deferredOrderKeys map[string]struct{}) bool {
+	var orderKey string
+	if rt.guaranteeUserEventOrder {
+		orderKey = jobOrderKey(job.UserID, gjson.GetBytes(job.Parameters, ordering.ParameterKey).String(), gjson.GetBytes(job.Parameters, "destination_id").String())
+		if _, ok := deferredOrderKeys[orderKey]; ok {
+			return true
+		}
+	}
+	if quota.Available(jobPriorityClass(job)) {
+		return false
+	}
+	if rt.guaranteeUserEventOrder {
+		deferredOrderKeys[orderKey] = struct{}{}
+	}
+	return true
+}
+
+// jobPriorityClass returns the priority class of the job, as assigned by the processor
+func jobPriorityClass(job *jobsdb.JobT) priority.Class {
+	return priority.ClassOf(gjson.GetBytes(job.Parameters, priority.ParameterKey).String())
+}
+
+// pausedBySchedule returns true if the destination's delivery schedule doesn't allow delivering events at the moment
+func (rt *Handle) pausedBySchedule(destinationID string) bool {
+	rt.destinationsMapMu.RLock()
+	s, ok := rt.deliverySchedules[destinationID]
+	rt.destinationsMapMu.RUnlock()
+	return ok && !s.IsOpen(time.Now())
+}
+
 func (rt *Handle) shouldThrottle(job *jobsdb.JobT, parameters JobParameters) (limited bool) {
 	if rt.throttlerFactory == nil {
 		// throttlerFactory could be nil when throttling is disabled or misconfigured.
@@ -3,6 +3,7 @@ package router
 import (
 	"context"
 	"fmt"
+	"path/filepath"
 	"sync"
 	"time"
 
@@ -11,22 +12,28 @@ import (
 
 	"github.com/rudderlabs/rudder-go-kit/bytesize"
 	"github.com/rudderlabs/rudder-go-kit/config"
+	"github.com/rudderlabs/rudder-go-kit/filemanager"
 	"github.com/rudderlabs/rudder-go-kit/logger"
 	"github.com/rudderlabs/rudder-go-kit/stats"
 	kitsync "github.com/rudderlabs/rudder-go-kit/sync"
 	backendconfig "github.com/rudderlabs/rudder-server/backend-config"
 	"github.com/rudderlabs/rudder-server/jobsdb"
 	customDestinationManager "github.com/rudderlabs/rudder-server/router/customdestinationmanager"
+	"github.com/rudderlabs/rudder-server/router/internal/circuitbreaker"
+	"github.com/rudderlabs/rudder-server/router/internal/nativedelivery"
 	"github.com/rudderlabs/rudder-server/router/internal/partition"
+	"github.com/rudderlabs/rudder-server/router/internal/schedule"
 	"github.com/rudderlabs/rudder-server/router/isolation"
 	"github.com/rudderlabs/rudder-server/router/transformer"
 	"github.com/rudderlabs/rudder-server/router/types"
 	routerutils "github.com/rudderlabs/rudder-server/router/utils"
 	"github.com/rudderlabs/rudder-server/rruntime"
+	"github.com/rudderlabs/rudder-server/services/debugger/capture"
 	destinationdebugger "github.com/rudderlabs/rudder-server/services/debugger/destination"
 	"github.com/rudderlabs/rudder-server/services/oauth"
 	"github.com/rudderlabs/rudder-server/services/rsources"
 	"github.com/rudderlabs/rudder-server/services/transientsource"
+	"github.com/rudderlabs/rudder-server/utils/filemanagerutil"
 	"github.com/rudderlabs/rudder-server/utils/misc"
 	utilTypes "github.com/rudderlabs/rudder-server/utils/types"
 	"github.com/rudderlabs/rudder-server/utils/workerpool"
@@ -90,6 +97,10 @@ func (rt *Handle) Setup(
 	config.RegisterDurationConfigVariable(2, &rt.reloadableConfig.pickupFlushInterval, true, time.Second, "Router.pickupFlushInterval")
 	config.RegisterDurationConfigVariable(2000, &rt.reloadableConfig.failingJobsPenaltySleep, true, time.Millisecond, []string{"Router.failingJobsPenaltySleep"}...)
 	config.RegisterFloat64ConfigVariable(0.6, &rt.reloadableConfig.failingJobsPenaltyThreshold, true, []string{"Router.failingJobsPenaltyThreshold"}...)
+	config.RegisterBoolConfigVariable(false, &rt.reloadableConfig.priorityPickup, true, []string{"Router." + rt.destType + ".priority.enabled", "Router.priority.enabled"}...)
+	config.RegisterIntConfigVariable(6, &rt.reloadableConfig.priorityWeights.high, true, 1, []string{"Router." + rt.destType + ".priority.weights.high", "Router.priority.weights.high"}...)
+	config.RegisterIntConfigVariable(3, &rt.reloadableConfig.priorityWeights.normal, true, 1, []string{"Router." + rt.destType + ".priority.weights.normal", "Router.priority.weights.normal"}...)
+	config.RegisterIntConfigVariable(1, &rt.reloadableConfig.priorityWeights.low, true, 1, []string{"Router." + rt.destType + ".priority.weights.low", "Router.priority.weights.low"}...)
 
 	config.RegisterDurationConfigVariable(60, &rt.diagnosisTickerTime, false, time.Second, []string{"Diagnostics.routerTimePeriod", "Diagnostics.routerTimePeriodInS"}...)
 
@@ -135,6 +146,26 @@ func (rt *Handle) Setup(
 	rt.throttlingErrorStat = stats.Default.NewTaggedStat("router_throttling_error", stats.CountType, statTags)
 	rt.throttledStat = stats.Default.NewTaggedStat("router_throttled", stats.CountType, statTags)
 
+	var (
+		circuitBreakerErrorRateThreshold float64
+		circuitBreakerMinRequests        int
+		circuitBreakerInterval           time.Duration
+		circuitBreakerTimeout            time.Duration
+	)
+	config.RegisterFloat64ConfigVariable(0.5, &circuitBreakerErrorRateThreshold, false, []string{"Router." + rt.destType + ".circuitBreaker.errorRateThreshold", "Router.circuitBreaker.errorRateThreshold"}...)
+	config.RegisterIntConfigVariable(20, &circuitBreakerMinRequests, false, 1, []string{"Router." + rt.destType + ".circuitBreaker.minRequests", "Router.circuitBreaker.minRequests"}...)
+	config.RegisterDurationConfigVariable(60, &circuitBreakerInterval, false, time.Second, []string{"Router." + rt.destType + ".circuitBreaker.interval", "Router.circuitBreaker.interval"}...)
+	config.RegisterDurationConfigVariable(30, &circuitBreakerTimeout, false, time.Second, []string{"Router." + rt.destType + ".circuitBreaker.timeout", "Router.circuitBreaker.timeout"}...)
+	rt.circuitBreaker = circuitbreaker.NewBreaker(
+		circuitbreaker.WithEnabled(getRouterConfigBool("circuitBreaker.enabled", rt.destType, false)),
+		circuitbreaker.WithErrorRateThreshold(circuitBreakerErrorRateThreshold),
+		circuitbreaker.WithMinRequests(uint32(circuitBreakerMinRequests)),
+		circuitbreaker.WithInterval(circuitBreakerInterval),
+		circuitbreaker.WithTimeout(circuitBreakerTimeout),
+		circuitbreaker.WithStateChangeListener(rt.circuitBreakerStateChanged),
+	)
+	rt.capturer = rt.newCapturer(config)
+
 	rt.transformer = transformer.NewTransformer(rt.netClientTimeout, rt.backendProxyTimeout)
 
 	rt.oauth = oauth.NewOAuthErrorHandler(backendConfig)
@@ -257,6 +288,22 @@ func (rt *Handle) Setup(
 		}
 	}))
 
+	g.Go(misc.WithBugsnag(func() error {
+		for {
+			select {
+			case <-ctx.Done():
+				return nil
+			case <-time.After(15 * time.Second):
+				rt.deliveryScheduleStats()
+			}
+		}
+	}))
+
+	g.Go(misc.WithBugsnag(func() error {
+		rt.capturer.Run(ctx)
+		return nil
+	}))
+
 	g.Go(misc.WithBugsnag(func() error {
 		rt.collectMetrics(ctx)
 		return nil
@@ -359,6 +406,9 @@ func (rt *Handle) backendConfigSubscriber() {
 	ch := rt.backendConfig.Subscribe(context.TODO(), backendconfig.TopicBackendConfig)
 	for configEvent := range ch {
 		destinationsMap := map[string]*routerutils.DestinationWithSources{}
+		nativeDeliveries := map[string]*nativeDelivery{}
+		deliverySchedules := map[string]*schedule.Schedule{}
+		captureDeadlines := map[string]time.Time{}
 		configData := configEvent.Data.(map[string]backendconfig.ConfigT)
 		for _, wConfig := range configData {
 			for i := range wConfig.Sources {
@@ -373,6 +423,23 @@ func (rt *Handle) backendConfigSubscriber() {
 							}
 						}
 						destinationsMap[destination.ID].Sources = append(destinationsMap[destination.ID].Sources, *source)
+						if _, ok := nativeDeliveries[destination.ID]; !ok {
+							if nd := rt.newNativeDelivery(destination); nd != nil {
+								nativeDeliveries[destination.ID] = nd
+							}
+						}
+						if _, ok := deliverySchedules[destination.ID]; !ok {
+							if s, err := schedule.FromDestinationConfig(destination.Config); err != nil {
+								rt.logger.Errorf("[%v Router] :: Invalid delivery schedule for destination %s, ignoring it: %v", rt.destType, destination.ID, err)
+							} else if s != nil {
+								deliverySchedules[destination.ID] = s
+							}
+						}
+						if enabled, until, err := capture.FromDestinationConfig(destination.Config); err != nil {
+							rt.logger.Errorf("[%v Router] :: Invalid http exchange capturing config for destination %s, ignoring it: %v", rt.destType, destination.ID, err)
+						} else if enabled {
+							captureDeadlines[destination.ID] = until
+						}
 
 						rt.destinationResponseHandler = NewResponseHandler(rt.logger, destination.DestinationDefinition.ResponseRules)
 						if value, ok := destination.DestinationDefinition.Config["saveDestinationResponse"].(bool); ok {
@@ -391,11 +458,85 @@ func (rt *Handle) backendConfigSubscriber() {
 			}
 		}
 		rt.destinationsMapMu.Lock()
+		var unscheduled []string
+		for destinationID := range rt.deliverySchedules {
+			if _, ok := deliverySchedules[destinationID]; !ok {
+				unscheduled = append(unscheduled, destinationID)
+			}
+		}
 		rt.destinationsMap = destinationsMap
+		rt.nativeDeliveries = nativeDeliveries
+		rt.deliverySchedules = deliverySchedules
 		rt.destinationsMapMu.Unlock()
+		rt.resetDeliveryScheduleStats(unscheduled)
+		rt.capturer.SetDestinations(captureDeadlines)
 		if !rt.isBackendConfigInitialized {
 			rt.isBackendConfigInitialized = true
 			rt.backendConfigInitialized <- true
 		}
 	}
 }
+
+// newCapturer returns a capturer for the http exchanges of destinations with capturing enabled, storing them either into
+// a local directory or into object storage. Returns nil if the capturer cannot be created.
+func (rt *Handle) newCapturer(conf *config.Config) *capture.Capturer {
+	var store capture.Store
+	switch storage := conf.GetString("Router.capture.storage", "local"); storage {
+	case "local":
+		dir := conf.GetString("Router.capture.dir", "")
+		if dir == "" {
+			tmpDir, err := misc.CreateTMPDIR()
+			if err != nil {
+				rt.logger.Errorf("[%v Router] :: Creating tmp dir for capturing http exchanges, capturing is disabled: %v", rt.destType, err)
+				return nil
+			}
+			dir = filepath.Join(tmpDir, "rudder-destination-captures")
+		}
+		localStore, err := capture.NewLocalStore(dir, conf.GetInt("Router.capture.capacity", 100))
+		if err != nil {
+			rt.logger.Errorf("[%v Router] :: Creating local store for capturing http exchanges, capturing is disabled: %v", rt.destType, err)
+			return nil
+		}
+		store = localStore
+	case "objectStorage":
+		provider := conf.GetString("JOBS_BACKUP_STORAGE_PROVIDER", "S3")
+		fm, err := filemanager.New(&filemanager.Settings{
+			Provider: provider,
+			Config:   filemanagerutil.GetProviderConfigForBackupsFromEnv(context.TODO(), conf),
+			Conf:     conf,
+		})
+		if err != nil {
+			rt.logger.Errorf("[%v Router] :: Creating file manager for capturing http exchanges, capturing is disabled: %v", rt.destType, err)
+			return nil
+		}
+		store = capture.NewObjectStorageStore(fm, conf.GetString("Router.capture.prefix", "destination-captures"))
+	default:
+		rt.logger.Errorf("[%v Router] :: Unsupported storage %q for capturing http exchanges, capturing is disabled", rt.destType, storage)
+		return nil
+	}
+	return capture.NewCapturer(store, rt.logger.Child("capture"),
+		capture.WithDefaultDuration(conf.GetDuration("Router.capture.defaultDuration", 30, time.Minute)),
+		capture.WithRedactor(capture.NewRedactor(capture.WithMaxBodySize(conf.GetInt("Router.capture.maxBodySize", 64*1024)))),
+	)
+}
+
+// newNativeDelivery returns the native delivery of the destination, or nil if the destination isn't configured for native delivery
+func (rt *Handle) newNativeDelivery(destination *backendconfig.DestinationT) *nativeDelivery {
+	conf, err := nativedelivery.ParseConfig(destination.Config)
+	if err != nil {
+		rt.logger.Errorf("[%v Router] :: Invalid native delivery config for destination %s, falling back to the default delivery: %v", rt.destType, destination.ID, err)
+		return nil
+	}
+	if conf == nil {
+		return nil
+	}
+	signer, err := nativedelivery.NewSigner(conf.Auth)
+	if err != nil {
+		rt.logger.Errorf("[%v Router] :: Invalid native delivery auth config for destination %s, falling back to the default delivery: %v", rt.destType, destination.ID, err)
+		return nil
+	}
+	return &nativeDelivery{
+		signer:          signer,
+		responseHandler: NewResponseHandler(rt.logger, conf.ResponseRules),
+	}
+}
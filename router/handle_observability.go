@@ -7,6 +7,7 @@ import (
 
 	"github.com/rudderlabs/rudder-go-kit/stats"
 	"github.com/rudderlabs/rudder-server/jobsdb"
+	"github.com/rudderlabs/rudder-server/router/internal/circuitbreaker"
 	"github.com/rudderlabs/rudder-server/services/diagnostics"
 	"github.com/rudderlabs/rudder-server/services/rsources"
 )
@@ -147,3 +148,37 @@ func (rt *Handle) pipelineDelayStats(partition string, first, last *jobsdb.JobT)
 	stats.Default.NewTaggedStat("pipeline_delay_min_seconds", stats.GaugeType, stats.Tags{"destType": rt.destType, "partition": partition, "module": "router"}).Gauge(lastJobDelay)
 	stats.Default.NewTaggedStat("pipeline_delay_max_seconds", stats.GaugeType, stats.Tags{"destType": rt.destType, "partition": partition, "module": "router"}).Gauge(firstJobDelay)
 }
+
+// circuitBreakerStateChanged reports the new state of a destination's circuit breaker
+func (rt *Handle) circuitBreakerStateChanged(destinationID string, from, to circuitbreaker.State) {
+	rt.logger.Infof("[%v Router] :: circuit breaker for destination %s changed state from %s to %s", rt.destType, destinationID, from, to)
+	statTags := stats.Tags{"destType": rt.destType, "destId": destinationID}
+	stats.Default.NewTaggedStat("router_circuit_breaker_state", stats.GaugeType, statTags).Gauge(int(to))
+	stats.Default.NewTaggedStat("router_circuit_breaker_state_changes", stats.CountType, stats.Tags{
+		"destType": rt.destType,
+		"destId":   destinationID,
+		"from":     from.String(),
+		"to":       to.String(),
+	}).Increment()
+}
+
+// deliveryScheduleStats reports whether each destination with a delivery schedule is currently paused by it
+func (rt *Handle) deliveryScheduleStats() {
+	rt.destinationsMapMu.RLock()
+	defer rt.destinationsMapMu.RUnlock()
+	now := time.Now()
+	for destinationID, s := range rt.deliverySchedules {
+		var paused int
+		if !s.IsOpen(now) {
+			paused = 1
+		}
+		stats.Default.NewTaggedStat("router_destination_paused_by_schedule", stats.GaugeType, stats.Tags{"destType": rt.destType, "destId": destinationID}).Gauge(paused)
+	}
+}
+
+// resetDeliveryScheduleStats reports destinations which were removed, or no longer have a delivery schedule, as not paused
+func (rt *Handle) resetDeliveryScheduleStats(destinationIDs []string) {
+	for _, destinationID := range destinationIDs {
+		stats.Default.NewTaggedStat("router_destination_paused_by_schedule", stats.GaugeType, stats.Tags{"destType": rt.destType, "destId": destinationID}).Gauge(0)
+	}
+}
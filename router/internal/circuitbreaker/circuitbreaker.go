@@ -0,0 +1,189 @@
+package circuitbreaker
+
+import (
+	"sync"
+	"time"
+
+	"github.com/sony/gobreaker"
+)
+
+// State is the state of a destination's circuit breaker
+type State = gobreaker.State
+
+const (
+	StateClosed   = gobreaker.StateClosed
+	StateHalfOpen = gobreaker.StateHalfOpen
+	StateOpen     = gobreaker.StateOpen
+)
+
+type OptFn func(b *Breaker)
+
+// WithEnabled enables or disables the breaker. A disabled breaker always allows jobs to be picked up and ignores delivery outcomes
+func WithEnabled(enabled bool) OptFn {
+	return func(b *Breaker) {
+		b.enabled = enabled
+	}
+}
+
+// WithErrorRateThreshold sets the ratio of failed deliveries (0-1) which, when reached within an interval, opens the circuit
+func WithErrorRateThreshold(threshold float64) OptFn {
+	return func(b *Breaker) {
+		b.errorRateThreshold = threshold
+	}
+}
+
+// WithMinRequests sets the minimum number of deliveries within an interval before the error rate is taken into account
+func WithMinRequests(minRequests uint32) OptFn {
+	return func(b *Breaker) {
+		b.minRequests = minRequests
+	}
+}
+
+// WithInterval sets the cyclic period of the closed state after which delivery counts are cleared
+func WithInterval(interval time.Duration) OptFn {
+	return func(b *Breaker) {
+		b.interval = interval
+	}
+}
+
+// WithTimeout sets the period of the open state, after which the state of the circuit becomes half-open
+func WithTimeout(timeout time.Duration) OptFn {
+	return func(b *Breaker) {
+		b.timeout = timeout
+	}
+}
+
+// WithStateChangeListener registers a function which gets called whenever the circuit of a destination changes its state.
+// The function must not call back into the breaker.
+func WithStateChangeListener(fn func(destinationID string, from, to State)) OptFn {
+	return func(b *Breaker) {
+		b.onStateChange = fn
+	}
+}
+
+// NewBreaker creates a new properly initialized Breaker
+func NewBreaker(fns ...OptFn) *Breaker {
+	b := &Breaker{
+		errorRateThreshold: 0.5,
+		minRequests:        10,
+		interval:           time.Minute,
+		timeout:            30 * time.Second,
+		circuits:           make(map[string]*circuit),
+	}
+	for _, fn := range fns {
+		fn(b)
+	}
+	return b
+}
+
+// Breaker keeps a circuit breaker for every destination of the router.
+//
+// A destination's circuit opens once the rate of failed deliveries reaches the configured threshold.
+// While the circuit is open, no jobs should be picked up for the destination. After the open timeout
+// the circuit becomes half-open and a single probe job is allowed to go through: if its delivery succeeds
+// the circuit closes again, otherwise it reopens.
+//
+// A nil Breaker behaves like a disabled one.
+type Breaker struct {
+	mu       sync.Mutex
+	circuits map[string]*circuit
+
+	enabled            bool
+	errorRateThreshold float64
+	minRequests        uint32
+	interval           time.Duration
+	timeout            time.Duration
+	onStateChange      func(destinationID string, from, to State)
+}
+
+type circuit struct {
+	cb      *gobreaker.TwoStepCircuitBreaker
+	probeID int64 // the job picked up as a probe while the circuit is half-open (0 if none)
+}
+
+// Allow returns true if the given job of the destination can be picked up. While the circuit is half-open only one job is
+// allowed at a time, which is kept as the probe until [Done] is called for it.
+func (b *Breaker) Allow(destinationID string, jobID int64) bool {
+	if b == nil || !b.enabled {
+		return true
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	c := b.circuit(destinationID)
+	switch c.cb.State() {
+	case StateOpen:
+		return false
+	case StateHalfOpen:
+		if c.probeID != 0 && c.probeID != jobID {
+			return false
+		}
+		c.probeID = jobID
+	}
+	return true
+}
+
+// Done releases the probe reservation of the given job, if any. It needs to be called for every allowed job once
+// its status is known or once it is discarded.
+func (b *Breaker) Done(destinationID string, jobID int64) {
+	if b == nil || !b.enabled {
+		return
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if c, ok := b.circuits[destinationID]; ok && c.probeID == jobID {
+		c.probeID = 0
+	}
+}
+
+// Report records the outcome of a delivery attempt to the destination
+func (b *Breaker) Report(destinationID string, success bool) {
+	if b == nil || !b.enabled {
+		return
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	done, err := b.circuit(destinationID).cb.Allow()
+	if err != nil { // circuit is open or a probe outcome is already being evaluated
+		return
+	}
+	done(success)
+}
+
+// State returns the current state of the destination's circuit
+func (b *Breaker) State(destinationID string) State {
+	if b == nil || !b.enabled {
+		return StateClosed
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if c, ok := b.circuits[destinationID]; ok {
+		return c.cb.State()
+	}
+	return StateClosed
+}
+
+// circuit returns the circuit of the destination, creating it if needed. Callers must hold the lock.
+func (b *Breaker) circuit(destinationID string) *circuit {
+	if c, ok := b.circuits[destinationID]; ok {
+		return c
+	}
+	c := &circuit{}
+	c.cb = gobreaker.NewTwoStepCircuitBreaker(gobreaker.Settings{
+		Name:        destinationID,
+		MaxRequests: 1,
+		Interval:    b.interval,
+		Timeout:     b.timeout,
+		ReadyToTrip: func(counts gobreaker.Counts) bool {
+			return counts.Requests >= b.minRequests &&
+				float64(counts.TotalFailures)/float64(counts.Requests) >= b.errorRateThreshold
+		},
+		OnStateChange: func(name string, from, to State) {
+			c.probeID = 0
+			if b.onStateChange != nil {
+				b.onStateChange(name, from, to)
+			}
+		},
+	})
+	b.circuits[destinationID] = c
+	return c
+}
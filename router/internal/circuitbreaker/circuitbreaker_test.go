@@ -0,0 +1,82 @@
+package circuitbreaker
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestBreaker(t *testing.T) {
+	t.Run("disabled breaker always allows", func(t *testing.T) {
+		b := NewBreaker(WithEnabled(false), WithMinRequests(1), WithErrorRateThreshold(0.1))
+		for i := 0; i < 10; i++ {
+			b.Report("dest1", false)
+		}
+		require.True(t, b.Allow("dest1", 1))
+		require.Equal(t, StateClosed, b.State("dest1"))
+	})
+
+	t.Run("circuit opens when error rate threshold is reached", func(t *testing.T) {
+		var transitions []string
+		b := NewBreaker(
+			WithEnabled(true),
+			WithMinRequests(4),
+			WithErrorRateThreshold(0.5),
+			WithTimeout(time.Hour),
+			WithStateChangeListener(func(destinationID string, from, to State) {
+				transitions = append(transitions, destinationID+":"+from.String()+"->"+to.String())
+			}),
+		)
+		b.Report("dest1", true)
+		b.Report("dest1", false)
+		b.Report("dest1", true)
+		require.Equal(t, StateClosed, b.State("dest1"), "minimum requests not reached yet")
+		b.Report("dest1", false)
+		require.Equal(t, StateOpen, b.State("dest1"))
+		require.False(t, b.Allow("dest1", 1), "jobs shouldn't be allowed while the circuit is open")
+		require.True(t, b.Allow("dest2", 1), "other destinations shouldn't be affected")
+		require.Equal(t, []string{"dest1:closed->open"}, transitions)
+	})
+
+	t.Run("half-open circuit allows a single probe", func(t *testing.T) {
+		b := NewBreaker(
+			WithEnabled(true),
+			WithMinRequests(1),
+			WithErrorRateThreshold(1),
+			WithTimeout(10*time.Millisecond),
+		)
+		b.Report("dest1", false)
+		require.Equal(t, StateOpen, b.State("dest1"))
+		require.Eventually(t, func() bool { return b.State("dest1") == StateHalfOpen }, time.Second, time.Millisecond)
+
+		require.True(t, b.Allow("dest1", 1), "the first job should be allowed as a probe")
+		require.True(t, b.Allow("dest1", 1), "the probe should be allowed again")
+		require.False(t, b.Allow("dest1", 2), "other jobs shouldn't be allowed while a probe is in flight")
+
+		b.Done("dest1", 2)
+		require.False(t, b.Allow("dest1", 2), "done for another job shouldn't release the probe")
+		b.Done("dest1", 1)
+		require.True(t, b.Allow("dest1", 2), "a new probe should be allowed after the previous one is done")
+
+		b.Report("dest1", true)
+		require.Equal(t, StateClosed, b.State("dest1"), "a successful probe should close the circuit")
+		require.True(t, b.Allow("dest1", 3))
+		require.True(t, b.Allow("dest1", 4))
+	})
+
+	t.Run("failed probe reopens the circuit", func(t *testing.T) {
+		b := NewBreaker(
+			WithEnabled(true),
+			WithMinRequests(1),
+			WithErrorRateThreshold(1),
+			WithTimeout(10*time.Millisecond),
+		)
+		b.Report("dest1", false)
+		require.Eventually(t, func() bool { return b.State("dest1") == StateHalfOpen }, time.Second, time.Millisecond)
+		require.True(t, b.Allow("dest1", 1))
+		b.Report("dest1", false)
+		require.Equal(t, StateOpen, b.State("dest1"))
+		require.False(t, b.Allow("dest1", 1))
+	})
+}
@@ -0,0 +1,254 @@
+// Package expression implements a small expression language for evaluating destination responses.
+//
+// An expression combines comparisons with boolean logic, e.g.
+//
+//	status == 200 && body.success == false && (body.error.code =~ '^RATE_' || exists(body.retryAfter))
+//
+// Supported constructs:
+//
+//   - literals: numbers, single or double quoted strings, true, false and null
+//   - variables: status (the response status code), body (the raw response body) and body.<path> for
+//     accessing a field of a json body using a gjson path (https://github.com/tidwall/gjson/blob/master/SYNTAX.md)
+//   - comparison operators: ==, !=, <, <=, >, >= and =~ (regular expression match)
+//   - boolean operators: &&, || and !, along with parentheses for grouping
+//   - functions: exists(path) and contains(value, substring)
+package expression
+
+import (
+	"fmt"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/tidwall/gjson"
+)
+
+// Env is the environment an expression is evaluated against
+type Env struct {
+	Status int    // the status code of the response
+	Body   string // the body of the response
+}
+
+// Expression is a parsed expression, ready for evaluation
+type Expression struct {
+	src  string
+	root node
+}
+
+// Parse parses the provided source into an expression
+func Parse(src string) (*Expression, error) {
+	p := &parser{lexer: lexer{src: src}}
+	if err := p.next(); err != nil {
+		return nil, err
+	}
+	root, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if p.tok.kind != tokEOF {
+		return nil, fmt.Errorf("unexpected %q at position %d", p.tok.text, p.tok.pos)
+	}
+	return &Expression{src: src, root: root}, nil
+}
+
+// MustParse is like [Parse] but panics if the source cannot be parsed
+func MustParse(src string) *Expression {
+	e, err := Parse(src)
+	if err != nil {
+		panic(fmt.Errorf("parsing expression %q: %w", src, err))
+	}
+	return e
+}
+
+// Eval evaluates the expression against the provided environment
+func (e *Expression) Eval(env Env) bool {
+	return truthy(e.root.eval(&env))
+}
+
+// String returns the source of the expression
+func (e *Expression) String() string {
+	return e.src
+}
+
+// nodes
+
+type node interface {
+	eval(env *Env) interface{} // returns nil, bool, float64 or string
+}
+
+type literalNode struct{ value interface{} }
+
+func (n literalNode) eval(_ *Env) interface{} { return n.value }
+
+type pathNode struct{ path string }
+
+func (n pathNode) eval(env *Env) interface{} {
+	switch {
+	case n.path == "status":
+		return float64(env.Status)
+	case n.path == "body":
+		return env.Body
+	case strings.HasPrefix(n.path, "body."):
+		return resultValue(gjson.Get(env.Body, n.path[len("body."):]))
+	default:
+		return nil
+	}
+}
+
+type notNode struct{ operand node }
+
+func (n notNode) eval(env *Env) interface{} { return !truthy(n.operand.eval(env)) }
+
+type andNode struct{ left, right node }
+
+func (n andNode) eval(env *Env) interface{} {
+	return truthy(n.left.eval(env)) && truthy(n.right.eval(env))
+}
+
+type orNode struct{ left, right node }
+
+func (n orNode) eval(env *Env) interface{} {
+	return truthy(n.left.eval(env)) || truthy(n.right.eval(env))
+}
+
+type compareNode struct {
+	op          string
+	left, right node
+}
+
+func (n compareNode) eval(env *Env) interface{} {
+	l, r := n.left.eval(env), n.right.eval(env)
+	switch n.op {
+	case "==":
+		return equal(l, r)
+	case "!=":
+		return !equal(l, r)
+	}
+	lf, lok := number(l)
+	rf, rok := number(r)
+	if !lok || !rok {
+		return false
+	}
+	switch n.op {
+	case "<":
+		return lf < rf
+	case "<=":
+		return lf <= rf
+	case ">":
+		return lf > rf
+	case ">=":
+		return lf >= rf
+	}
+	return false
+}
+
+type matchNode struct {
+	operand node
+	re      *regexp.Regexp
+}
+
+func (n matchNode) eval(env *Env) interface{} {
+	v := n.operand.eval(env)
+	if v == nil {
+		return false
+	}
+	return n.re.MatchString(stringify(v))
+}
+
+type existsNode struct{ path pathNode }
+
+func (n existsNode) eval(env *Env) interface{} {
+	if !strings.HasPrefix(n.path.path, "body.") {
+		return n.path.eval(env) != nil
+	}
+	return gjson.Get(env.Body, n.path.path[len("body."):]).Exists()
+}
+
+type containsNode struct{ value, substring node }
+
+func (n containsNode) eval(env *Env) interface{} {
+	v, s := n.value.eval(env), n.substring.eval(env)
+	if v == nil || s == nil {
+		return false
+	}
+	return strings.Contains(stringify(v), stringify(s))
+}
+
+// values
+
+func resultValue(r gjson.Result) interface{} {
+	switch r.Type {
+	case gjson.Null:
+		return nil
+	case gjson.False:
+		return false
+	case gjson.True:
+		return true
+	case gjson.Number:
+		return r.Num
+	case gjson.String:
+		return r.Str
+	default: // json objects and arrays
+		return r.Raw
+	}
+}
+
+func truthy(v interface{}) bool {
+	switch v := v.(type) {
+	case bool:
+		return v
+	case float64:
+		return v != 0
+	case string:
+		return v != ""
+	default:
+		return false
+	}
+}
+
+func number(v interface{}) (float64, bool) {
+	switch v := v.(type) {
+	case float64:
+		return v, true
+	case string:
+		f, err := strconv.ParseFloat(v, 64)
+		return f, err == nil
+	default:
+		return 0, false
+	}
+}
+
+func stringify(v interface{}) string {
+	switch v := v.(type) {
+	case nil:
+		return "null"
+	case float64:
+		if v == math.Trunc(v) && math.Abs(v) < 1e15 {
+			return strconv.FormatInt(int64(v), 10)
+		}
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+// equal compares two values, numerically if both of them are numbers (or numeric strings, e.g. a status code returned as a string)
+func equal(l, r interface{}) bool {
+	if l == nil || r == nil {
+		return l == nil && r == nil
+	}
+	if lb, ok := l.(bool); ok {
+		rb, ok := r.(bool)
+		return ok && lb == rb
+	}
+	if _, ok := r.(bool); ok {
+		return false
+	}
+	if lf, ok := number(l); ok {
+		if rf, ok := number(r); ok {
+			return lf == rf
+		}
+	}
+	return stringify(l) == stringify(r)
+}
@@ -0,0 +1,85 @@
+package expression_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/rudderlabs/rudder-server/router/internal/expression"
+)
+
+func TestEval(t *testing.T) {
+	env := expression.Env{
+		Status: 200,
+		Body:   `{"success":false,"code":"411","errors":[{"code":411,"message":"Rate limit exceeded"}],"retryAfter":null,"nested":{"ok":true}}`,
+	}
+	tests := []struct {
+		expr     string
+		expected bool
+	}{
+		{`status == 200`, true},
+		{`status != 200`, false},
+		{`status >= 200 && status < 300`, true},
+		{`status > 200 || status <= 100`, false},
+		{`body.success == false`, true},
+		{`body.success`, false},
+		{`!body.success`, true},
+		{`body.nested.ok`, true},
+		{`body.errors.0.code == 411`, true},
+		{`body.code == 411`, true},
+		{`body.code == '411'`, true},
+		{`body.errors.0.message == "Rate limit exceeded"`, true},
+		{`body.errors.0.message =~ '(?i)^rate limit'`, true},
+		{`body.errors.0.message =~ '^quota'`, false},
+		{`body.errors.#(code==411).message =~ 'limit'`, true},
+		{`body.errors.# == 1`, true},
+		{`body.missing == null`, true},
+		{`body.retryAfter == null`, true},
+		{`body.missing =~ '.*'`, false},
+		{`exists(body.retryAfter)`, true},
+		{`exists(body.missing)`, false},
+		{`contains(body, "Rate limit")`, true},
+		{`contains(body.errors.0.message, 'quota')`, false},
+		{`status == 200 && (body.success == true || body.errors.0.code == 411)`, true},
+		{`!(status == 200) || body.success`, false},
+		{`body.nested == '{"ok":true}'`, true},
+		{`status == 200 && body.success == false && body.errors.0.code >= 400 && body.errors.0.code < 500`, true},
+	}
+	for _, tc := range tests {
+		t.Run(tc.expr, func(t *testing.T) {
+			e, err := expression.Parse(tc.expr)
+			require.NoError(t, err)
+			require.Equal(t, tc.expected, e.Eval(env))
+		})
+	}
+
+	t.Run("non json body", func(t *testing.T) {
+		e := expression.MustParse(`status == 200 && body =~ '^ERROR' && body.success != true`)
+		require.True(t, e.Eval(expression.Env{Status: 200, Body: "ERROR: invalid api key"}))
+		require.False(t, e.Eval(expression.Env{Status: 200, Body: "OK"}))
+	})
+}
+
+func TestParseErrors(t *testing.T) {
+	for _, src := range []string{
+		``,
+		`status ==`,
+		`status == 200 &&`,
+		`(status == 200`,
+		`status == 200)`,
+		`unknown == 1`,
+		`body =~ 5`,
+		`body =~ '('`,
+		`exists(status, body)`,
+		`exists('x')`,
+		`contains(body)`,
+		`unknown(body)`,
+		`body == 'unterminated`,
+		`status $ 1`,
+	} {
+		t.Run(src, func(t *testing.T) {
+			_, err := expression.Parse(src)
+			require.Error(t, err)
+		})
+	}
+}
@@ -0,0 +1,305 @@
+package expression
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+type tokenKind int
+
+const (
+	tokEOF tokenKind = iota
+	tokNumber
+	tokString
+	tokIdent
+	tokOperator
+	tokLParen
+	tokRParen
+	tokComma
+)
+
+type token struct {
+	kind tokenKind
+	text string
+	pos  int
+}
+
+type lexer struct {
+	src string
+	pos int
+}
+
+// operators sorted so that longer operators are matched first
+var operators = []string{"&&", "||", "==", "!=", "<=", ">=", "=~", "<", ">", "!"}
+
+func isIdentStart(c byte) bool {
+	return c == '_' || c == '@' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z')
+}
+
+func isIdentPart(c byte) bool {
+	return isIdentStart(c) || (c >= '0' && c <= '9') || c == '.' || c == '#' || c == '*' || c == '-'
+}
+
+func (l *lexer) next() (token, error) {
+	for l.pos < len(l.src) && strings.ContainsRune(" \t\r\n", rune(l.src[l.pos])) {
+		l.pos++
+	}
+	start := l.pos
+	if l.pos >= len(l.src) {
+		return token{kind: tokEOF, pos: start}, nil
+	}
+	c := l.src[l.pos]
+	switch {
+	case c == '(':
+		l.pos++
+		return token{kind: tokLParen, text: "(", pos: start}, nil
+	case c == ')':
+		l.pos++
+		return token{kind: tokRParen, text: ")", pos: start}, nil
+	case c == ',':
+		l.pos++
+		return token{kind: tokComma, text: ",", pos: start}, nil
+	case c == '\'' || c == '"':
+		var sb strings.Builder
+		l.pos++
+		for l.pos < len(l.src) && l.src[l.pos] != c {
+			if l.src[l.pos] == '\\' && l.pos+1 < len(l.src) {
+				l.pos++
+			}
+			sb.WriteByte(l.src[l.pos])
+			l.pos++
+		}
+		if l.pos >= len(l.src) {
+			return token{}, fmt.Errorf("unterminated string at position %d", start)
+		}
+		l.pos++
+		return token{kind: tokString, text: sb.String(), pos: start}, nil
+	case c == '-' || (c >= '0' && c <= '9'):
+		l.pos++
+		for l.pos < len(l.src) && (l.src[l.pos] == '.' || (l.src[l.pos] >= '0' && l.src[l.pos] <= '9')) {
+			l.pos++
+		}
+		return token{kind: tokNumber, text: l.src[start:l.pos], pos: start}, nil
+	case isIdentStart(c):
+		for l.pos < len(l.src) && isIdentPart(l.src[l.pos]) {
+			if l.src[l.pos] == '#' && l.pos+1 < len(l.src) && l.src[l.pos+1] == '(' { // gjson query, e.g. errors.#(code==411)
+				depth := 0
+				for ; l.pos < len(l.src); l.pos++ {
+					if l.src[l.pos] == '(' {
+						depth++
+					} else if l.src[l.pos] == ')' {
+						depth--
+						if depth == 0 {
+							break
+						}
+					}
+				}
+				if depth != 0 {
+					return token{}, fmt.Errorf("unterminated query at position %d", start)
+				}
+			}
+			l.pos++
+		}
+		return token{kind: tokIdent, text: l.src[start:l.pos], pos: start}, nil
+	}
+	for _, op := range operators {
+		if strings.HasPrefix(l.src[l.pos:], op) {
+			l.pos += len(op)
+			return token{kind: tokOperator, text: op, pos: start}, nil
+		}
+	}
+	return token{}, fmt.Errorf("unexpected character %q at position %d", c, start)
+}
+
+type parser struct {
+	lexer lexer
+	tok   token
+}
+
+func (p *parser) next() error {
+	tok, err := p.lexer.next()
+	if err != nil {
+		return err
+	}
+	p.tok = tok
+	return nil
+}
+
+func (p *parser) expect(kind tokenKind, what string) error {
+	if p.tok.kind != kind {
+		return fmt.Errorf("expected %s at position %d", what, p.tok.pos)
+	}
+	return p.next()
+}
+
+func (p *parser) parseOr() (node, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	for p.tok.kind == tokOperator && p.tok.text == "||" {
+		if err := p.next(); err != nil {
+			return nil, err
+		}
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		left = orNode{left: left, right: right}
+	}
+	return left, nil
+}
+
+func (p *parser) parseAnd() (node, error) {
+	left, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+	for p.tok.kind == tokOperator && p.tok.text == "&&" {
+		if err := p.next(); err != nil {
+			return nil, err
+		}
+		right, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		left = andNode{left: left, right: right}
+	}
+	return left, nil
+}
+
+func (p *parser) parseUnary() (node, error) {
+	if p.tok.kind == tokOperator && p.tok.text == "!" {
+		if err := p.next(); err != nil {
+			return nil, err
+		}
+		operand, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return notNode{operand: operand}, nil
+	}
+	return p.parseComparison()
+}
+
+func (p *parser) parseComparison() (node, error) {
+	left, err := p.parseOperand()
+	if err != nil {
+		return nil, err
+	}
+	if p.tok.kind != tokOperator {
+		return left, nil
+	}
+	op := p.tok.text
+	switch op {
+	case "==", "!=", "<", "<=", ">", ">=":
+		if err := p.next(); err != nil {
+			return nil, err
+		}
+		right, err := p.parseOperand()
+		if err != nil {
+			return nil, err
+		}
+		return compareNode{op: op, left: left, right: right}, nil
+	case "=~":
+		if err := p.next(); err != nil {
+			return nil, err
+		}
+		if p.tok.kind != tokString {
+			return nil, fmt.Errorf("expected a regular expression string at position %d", p.tok.pos)
+		}
+		re, err := regexp.Compile(p.tok.text)
+		if err != nil {
+			return nil, fmt.Errorf("invalid regular expression at position %d: %w", p.tok.pos, err)
+		}
+		if err := p.next(); err != nil {
+			return nil, err
+		}
+		return matchNode{operand: left, re: re}, nil
+	}
+	return left, nil
+}
+
+func (p *parser) parseOperand() (node, error) {
+	tok := p.tok
+	switch tok.kind {
+	case tokNumber:
+		f, err := strconv.ParseFloat(tok.text, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid number %q at position %d", tok.text, tok.pos)
+		}
+		return literalNode{value: f}, p.next()
+	case tokString:
+		return literalNode{value: tok.text}, p.next()
+	case tokLParen:
+		if err := p.next(); err != nil {
+			return nil, err
+		}
+		n, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		return n, p.expect(tokRParen, "')'")
+	case tokIdent:
+		if err := p.next(); err != nil {
+			return nil, err
+		}
+		switch tok.text {
+		case "true":
+			return literalNode{value: true}, nil
+		case "false":
+			return literalNode{value: false}, nil
+		case "null":
+			return literalNode{value: nil}, nil
+		}
+		if p.tok.kind == tokLParen {
+			return p.parseFunction(tok)
+		}
+		if tok.text != "status" && tok.text != "body" && !strings.HasPrefix(tok.text, "body.") {
+			return nil, fmt.Errorf("unknown variable %q at position %d", tok.text, tok.pos)
+		}
+		return pathNode{path: tok.text}, nil
+	}
+	return nil, fmt.Errorf("unexpected %q at position %d", tok.text, tok.pos)
+}
+
+func (p *parser) parseFunction(name token) (node, error) {
+	if err := p.next(); err != nil { // skip '('
+		return nil, err
+	}
+	var args []node
+	for p.tok.kind != tokRParen {
+		if len(args) > 0 {
+			if err := p.expect(tokComma, "','"); err != nil {
+				return nil, err
+			}
+		}
+		arg, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		args = append(args, arg)
+	}
+	if err := p.next(); err != nil { // skip ')'
+		return nil, err
+	}
+	switch name.text {
+	case "exists":
+		if len(args) != 1 {
+			return nil, fmt.Errorf("exists expects 1 argument at position %d", name.pos)
+		}
+		path, ok := args[0].(pathNode)
+		if !ok {
+			return nil, fmt.Errorf("exists expects a variable argument at position %d", name.pos)
+		}
+		return existsNode{path: path}, nil
+	case "contains":
+		if len(args) != 2 {
+			return nil, fmt.Errorf("contains expects 2 arguments at position %d", name.pos)
+		}
+		return containsNode{value: args[0], substring: args[1]}, nil
+	}
+	return nil, fmt.Errorf("unknown function %q at position %d", name.text, name.pos)
+}
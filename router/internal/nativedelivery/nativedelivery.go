@@ -0,0 +1,86 @@
+// Package nativedelivery provides the building blocks for delivering router jobs straight to the destination's API,
+// bypassing the transformer proxy: request signers for authenticating outgoing requests and the parsing of
+// the destination's native delivery configuration.
+package nativedelivery
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+)
+
+// ConfigKey is the key of the destination config holding the native delivery configuration
+const ConfigKey = "nativeDelivery"
+
+// Config is the native delivery configuration of a destination
+type Config struct {
+	Enabled       bool                   `json:"enabled"`
+	Auth          AuthConfig             `json:"auth"`
+	ResponseRules map[string]interface{} `json:"responseRules"`
+}
+
+// AuthConfig describes how outgoing requests need to be authenticated
+type AuthConfig struct {
+	Type string `json:"type"` // one of bearer, basic, awsSigV4, hmac (or empty for no authentication)
+
+	// bearer
+	Token string `json:"token"`
+
+	// basic
+	Username string `json:"username"`
+	Password string `json:"password"`
+
+	// awsSigV4
+	AccessKeyID     string `json:"accessKeyId"`
+	SecretAccessKey string `json:"secretAccessKey"`
+	SessionToken    string `json:"sessionToken"`
+	Region          string `json:"region"`
+	Service         string `json:"service"`
+
+	// hmac
+	Secret    string `json:"secret"`
+	Header    string `json:"header"`    // header carrying the signature, defaults to X-Signature
+	Algorithm string `json:"algorithm"` // one of sha1, sha256, sha512, defaults to sha256
+	Encoding  string `json:"encoding"`  // one of hex, base64, defaults to hex
+	Prefix    string `json:"prefix"`    // optional prefix of the signature value, e.g. sha256=
+}
+
+// ParseConfig extracts the native delivery configuration from the destination's config.
+// It returns nil if native delivery is not configured or not enabled for the destination.
+func ParseConfig(destConfig map[string]interface{}) (*Config, error) {
+	raw, ok := destConfig[ConfigKey]
+	if !ok || raw == nil {
+		return nil, nil
+	}
+	data, err := json.Marshal(raw)
+	if err != nil {
+		return nil, fmt.Errorf("marshalling %s config: %w", ConfigKey, err)
+	}
+	var c Config
+	if err := json.Unmarshal(data, &c); err != nil {
+		return nil, fmt.Errorf("unmarshalling %s config: %w", ConfigKey, err)
+	}
+	if !c.Enabled {
+		return nil, nil
+	}
+	return &c, nil
+}
+
+// Signer authenticates an outgoing request, given the request's body
+type Signer interface {
+	Sign(req *http.Request, body []byte) error
+}
+
+type signerContextKey struct{}
+
+// WithSigner returns a copy of the context carrying the signer which needs to sign requests sent using it
+func WithSigner(ctx context.Context, signer Signer) context.Context {
+	return context.WithValue(ctx, signerContextKey{}, signer)
+}
+
+// SignerFromContext returns the signer carried by the context, if any
+func SignerFromContext(ctx context.Context) (Signer, bool) {
+	signer, ok := ctx.Value(signerContextKey{}).(Signer)
+	return signer, ok && signer != nil
+}
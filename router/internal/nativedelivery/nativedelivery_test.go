@@ -0,0 +1,139 @@
+package nativedelivery_test
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/rudderlabs/rudder-server/router/internal/nativedelivery"
+)
+
+func TestParseConfig(t *testing.T) {
+	t.Run("not configured", func(t *testing.T) {
+		c, err := nativedelivery.ParseConfig(map[string]interface{}{"other": "value"})
+		require.NoError(t, err)
+		require.Nil(t, c)
+	})
+
+	t.Run("disabled", func(t *testing.T) {
+		c, err := nativedelivery.ParseConfig(map[string]interface{}{
+			"nativeDelivery": map[string]interface{}{"enabled": false, "auth": map[string]interface{}{"type": "bearer"}},
+		})
+		require.NoError(t, err)
+		require.Nil(t, c)
+	})
+
+	t.Run("enabled", func(t *testing.T) {
+		c, err := nativedelivery.ParseConfig(map[string]interface{}{
+			"nativeDelivery": map[string]interface{}{
+				"enabled":       true,
+				"auth":          map[string]interface{}{"type": "bearer", "token": "token"},
+				"responseRules": map[string]interface{}{"responseType": "JSON"},
+			},
+		})
+		require.NoError(t, err)
+		require.NotNil(t, c)
+		require.Equal(t, nativedelivery.AuthConfig{Type: "bearer", Token: "token"}, c.Auth)
+		require.Equal(t, map[string]interface{}{"responseType": "JSON"}, c.ResponseRules)
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		_, err := nativedelivery.ParseConfig(map[string]interface{}{
+			"nativeDelivery": map[string]interface{}{"enabled": "yes"},
+		})
+		require.Error(t, err)
+	})
+}
+
+func TestSigners(t *testing.T) {
+	newRequest := func(t *testing.T) *http.Request {
+		req, err := http.NewRequest(http.MethodPost, "https://example.com/path?a=b", strings.NewReader("payload"))
+		require.NoError(t, err)
+		return req
+	}
+
+	t.Run("none", func(t *testing.T) {
+		s, err := nativedelivery.NewSigner(nativedelivery.AuthConfig{})
+		require.NoError(t, err)
+		req := newRequest(t)
+		require.NoError(t, s.Sign(req, []byte("payload")))
+		require.Empty(t, req.Header)
+	})
+
+	t.Run("bearer", func(t *testing.T) {
+		_, err := nativedelivery.NewSigner(nativedelivery.AuthConfig{Type: nativedelivery.AuthTypeBearer})
+		require.Error(t, err, "token is required")
+
+		s, err := nativedelivery.NewSigner(nativedelivery.AuthConfig{Type: nativedelivery.AuthTypeBearer, Token: "token"})
+		require.NoError(t, err)
+		req := newRequest(t)
+		require.NoError(t, s.Sign(req, []byte("payload")))
+		require.Equal(t, "Bearer token", req.Header.Get("Authorization"))
+	})
+
+	t.Run("basic", func(t *testing.T) {
+		s, err := nativedelivery.NewSigner(nativedelivery.AuthConfig{Type: nativedelivery.AuthTypeBasic, Username: "user", Password: "pass"})
+		require.NoError(t, err)
+		req := newRequest(t)
+		require.NoError(t, s.Sign(req, []byte("payload")))
+		require.Equal(t, "Basic dXNlcjpwYXNz", req.Header.Get("Authorization"))
+	})
+
+	t.Run("aws sigv4", func(t *testing.T) {
+		_, err := nativedelivery.NewSigner(nativedelivery.AuthConfig{Type: nativedelivery.AuthTypeAWSSigV4, AccessKeyID: "key"})
+		require.Error(t, err, "secret, region and service are required")
+
+		s, err := nativedelivery.NewSigner(nativedelivery.AuthConfig{
+			Type:            nativedelivery.AuthTypeAWSSigV4,
+			AccessKeyID:     "AKIDEXAMPLE",
+			SecretAccessKey: "secret",
+			Region:          "us-east-1",
+			Service:         "execute-api",
+		})
+		require.NoError(t, err)
+		req := newRequest(t)
+		require.NoError(t, s.Sign(req, []byte("payload")))
+		require.True(t, strings.HasPrefix(req.Header.Get("Authorization"), "AWS4-HMAC-SHA256 Credential=AKIDEXAMPLE/"))
+		require.Contains(t, req.Header.Get("Authorization"), "/us-east-1/execute-api/aws4_request")
+		require.NotEmpty(t, req.Header.Get("X-Amz-Date"))
+	})
+
+	t.Run("hmac", func(t *testing.T) {
+		_, err := nativedelivery.NewSigner(nativedelivery.AuthConfig{Type: nativedelivery.AuthTypeHMAC})
+		require.Error(t, err, "secret is required")
+		_, err = nativedelivery.NewSigner(nativedelivery.AuthConfig{Type: nativedelivery.AuthTypeHMAC, Secret: "k", Algorithm: "md5"})
+		require.Error(t, err, "algorithm is not supported")
+
+		s, err := nativedelivery.NewSigner(nativedelivery.AuthConfig{
+			Type:      nativedelivery.AuthTypeHMAC,
+			Secret:    "k",
+			Header:    "X-Hub-Signature",
+			Algorithm: "sha512",
+			Encoding:  "base64",
+			Prefix:    "sha512=",
+		})
+		require.NoError(t, err)
+		req := newRequest(t)
+		require.NoError(t, s.Sign(req, []byte("payload")))
+		require.Equal(t, "sha512=n5F5f4zlOh4DeiHaSlG6FAtL0iF334I+3fLrZRwzk8Q4QWinztjqU8xtc/ruZKDuuMTrQg5n+TOlg991ClSpSw==", req.Header.Get("X-Hub-Signature"))
+	})
+
+	t.Run("unsupported", func(t *testing.T) {
+		_, err := nativedelivery.NewSigner(nativedelivery.AuthConfig{Type: "digest"})
+		require.Error(t, err)
+	})
+}
+
+func TestSignerContext(t *testing.T) {
+	_, ok := nativedelivery.SignerFromContext(context.Background())
+	require.False(t, ok)
+
+	s, err := nativedelivery.NewSigner(nativedelivery.AuthConfig{Type: nativedelivery.AuthTypeBearer, Token: "token"})
+	require.NoError(t, err)
+	signer, ok := nativedelivery.SignerFromContext(nativedelivery.WithSigner(context.Background(), s))
+	require.True(t, ok)
+	require.Equal(t, s, signer)
+}
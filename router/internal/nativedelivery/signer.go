@@ -0,0 +1,150 @@
+package nativedelivery
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha1" // skipcq: GSC-G505
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"hash"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws/credentials"
+	v4 "github.com/aws/aws-sdk-go/aws/signer/v4"
+)
+
+const (
+	AuthTypeNone     = ""
+	AuthTypeBearer   = "bearer"
+	AuthTypeBasic    = "basic"
+	AuthTypeAWSSigV4 = "awsSigV4"
+	AuthTypeHMAC     = "hmac"
+)
+
+// NewSigner returns the signer for the provided authentication configuration
+func NewSigner(c AuthConfig) (Signer, error) {
+	switch c.Type {
+	case AuthTypeNone:
+		return noneSigner{}, nil
+	case AuthTypeBearer:
+		if c.Token == "" {
+			return nil, errors.New("bearer authentication requires a token")
+		}
+		return bearerSigner{token: c.Token}, nil
+	case AuthTypeBasic:
+		if c.Username == "" {
+			return nil, errors.New("basic authentication requires a username")
+		}
+		return basicSigner{username: c.Username, password: c.Password}, nil
+	case AuthTypeAWSSigV4:
+		if c.AccessKeyID == "" || c.SecretAccessKey == "" || c.Region == "" || c.Service == "" {
+			return nil, errors.New("aws sigv4 authentication requires an access key id, a secret access key, a region and a service")
+		}
+		return &awsSigV4Signer{
+			signer:  v4.NewSigner(credentials.NewStaticCredentials(c.AccessKeyID, c.SecretAccessKey, c.SessionToken)),
+			region:  c.Region,
+			service: c.Service,
+		}, nil
+	case AuthTypeHMAC:
+		return newHMACSigner(c)
+	default:
+		return nil, fmt.Errorf("unsupported authentication type: %q", c.Type)
+	}
+}
+
+// noneSigner leaves requests untouched
+type noneSigner struct{}
+
+func (noneSigner) Sign(_ *http.Request, _ []byte) error {
+	return nil
+}
+
+// bearerSigner sets a bearer token in the Authorization header
+type bearerSigner struct {
+	token string
+}
+
+func (s bearerSigner) Sign(req *http.Request, _ []byte) error {
+	req.Header.Set("Authorization", "Bearer "+s.token)
+	return nil
+}
+
+// basicSigner sets basic authentication credentials in the Authorization header
+type basicSigner struct {
+	username string
+	password string
+}
+
+func (s basicSigner) Sign(req *http.Request, _ []byte) error {
+	req.SetBasicAuth(s.username, s.password)
+	return nil
+}
+
+// awsSigV4Signer signs requests using AWS Signature Version 4
+type awsSigV4Signer struct {
+	signer  *v4.Signer
+	region  string
+	service string
+}
+
+func (s *awsSigV4Signer) Sign(req *http.Request, body []byte) error {
+	if _, err := s.signer.Sign(req, bytes.NewReader(body), s.service, s.region, time.Now()); err != nil {
+		return fmt.Errorf("signing request with aws sigv4: %w", err)
+	}
+	return nil
+}
+
+// hmacSigner sets a header containing the HMAC signature of the request's body
+type hmacSigner struct {
+	secret []byte
+	header string
+	hash   func() hash.Hash
+	encode func([]byte) string
+	prefix string
+}
+
+func newHMACSigner(c AuthConfig) (*hmacSigner, error) {
+	if c.Secret == "" {
+		return nil, errors.New("hmac authentication requires a secret")
+	}
+	s := &hmacSigner{
+		secret: []byte(c.Secret),
+		header: c.Header,
+		prefix: c.Prefix,
+	}
+	if s.header == "" {
+		s.header = "X-Signature"
+	}
+	switch strings.ToLower(c.Algorithm) {
+	case "", "sha256":
+		s.hash = sha256.New
+	case "sha1":
+		s.hash = sha1.New
+	case "sha512":
+		s.hash = sha512.New
+	default:
+		return nil, fmt.Errorf("unsupported hmac algorithm: %q", c.Algorithm)
+	}
+	switch strings.ToLower(c.Encoding) {
+	case "", "hex":
+		s.encode = hex.EncodeToString
+	case "base64":
+		s.encode = base64.StdEncoding.EncodeToString
+	default:
+		return nil, fmt.Errorf("unsupported hmac encoding: %q", c.Encoding)
+	}
+	return s, nil
+}
+
+func (s *hmacSigner) Sign(req *http.Request, body []byte) error {
+	mac := hmac.New(s.hash, s.secret)
+	mac.Write(body)
+	req.Header.Set(s.header, s.prefix+s.encode(mac.Sum(nil)))
+	return nil
+}
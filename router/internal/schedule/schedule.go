@@ -0,0 +1,219 @@
+// Package schedule implements delivery schedules for destinations, i.e. the time windows during which the router is
+// allowed to deliver events to a destination.
+package schedule
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ConfigKey is the key of the destination config holding the delivery schedule
+const ConfigKey = "deliverySchedule"
+
+// Config is the delivery schedule configuration of a destination, e.g.
+//
+//	"deliverySchedule": {
+//		"timezone": "America/New_York",
+//		"windows": ["* 9-17 * * mon-fri"],
+//		"blackouts": ["* 12 * * *"]
+//	}
+//
+// Windows and blackouts are cron-like expressions with five fields (minute, hour, day of month, month and day of week),
+// each one of them supporting wildcards, lists, ranges and steps. Day of week ranges can wrap around the end of the week,
+// e.g. fri-mon matches friday to monday. Every minute matched by an expression belongs to the
+// window (or blackout). Delivery is allowed during any of the windows (always, if no windows are defined), unless
+// a blackout matches.
+type Config struct {
+	Timezone  string   `json:"timezone"`
+	Windows   []string `json:"windows"`
+	Blackouts []string `json:"blackouts"`
+}
+
+// Schedule is a parsed delivery schedule
+type Schedule struct {
+	location  *time.Location
+	windows   []*expression
+	blackouts []*expression
+}
+
+// FromDestinationConfig returns the delivery schedule of the destination's config, or nil if the destination doesn't have one
+func FromDestinationConfig(destConfig map[string]interface{}) (*Schedule, error) {
+	raw, ok := destConfig[ConfigKey]
+	if !ok || raw == nil {
+		return nil, nil
+	}
+	data, err := json.Marshal(raw)
+	if err != nil {
+		return nil, fmt.Errorf("marshalling %s config: %w", ConfigKey, err)
+	}
+	var c Config
+	if err := json.Unmarshal(data, &c); err != nil {
+		return nil, fmt.Errorf("unmarshalling %s config: %w", ConfigKey, err)
+	}
+	if len(c.Windows) == 0 && len(c.Blackouts) == 0 {
+		return nil, nil
+	}
+	return New(c)
+}
+
+// New creates a new schedule out of the provided configuration
+func New(c Config) (*Schedule, error) {
+	location := time.UTC
+	if c.Timezone != "" {
+		var err error
+		if location, err = time.LoadLocation(c.Timezone); err != nil {
+			return nil, fmt.Errorf("loading timezone %q: %w", c.Timezone, err)
+		}
+	}
+	s := &Schedule{location: location}
+	for _, w := range c.Windows {
+		e, err := parseExpression(w)
+		if err != nil {
+			return nil, fmt.Errorf("parsing window %q: %w", w, err)
+		}
+		s.windows = append(s.windows, e)
+	}
+	for _, b := range c.Blackouts {
+		e, err := parseExpression(b)
+		if err != nil {
+			return nil, fmt.Errorf("parsing blackout %q: %w", b, err)
+		}
+		s.blackouts = append(s.blackouts, e)
+	}
+	return s, nil
+}
+
+// IsOpen returns true if delivery is allowed at the provided time
+func (s *Schedule) IsOpen(t time.Time) bool {
+	t = t.In(s.location)
+	for _, b := range s.blackouts {
+		if b.matches(t) {
+			return false
+		}
+	}
+	if len(s.windows) == 0 {
+		return true
+	}
+	for _, w := range s.windows {
+		if w.matches(t) {
+			return true
+		}
+	}
+	return false
+}
+
+// expression is a parsed cron-like expression
+type expression struct {
+	minute, hour, dom, month, dow uint64 // bitsets of allowed values
+	domStar, dowStar              bool
+}
+
+func (e *expression) matches(t time.Time) bool {
+	if e.minute&(1<<uint(t.Minute())) == 0 || e.hour&(1<<uint(t.Hour())) == 0 || e.month&(1<<uint(t.Month())) == 0 {
+		return false
+	}
+	domMatch := e.dom&(1<<uint(t.Day())) != 0
+	dowMatch := e.dow&(1<<uint(t.Weekday())) != 0
+	// like cron, when both day of month and day of week are restricted, either of them needs to match
+	if !e.domStar && !e.dowStar {
+		return domMatch || dowMatch
+	}
+	return domMatch && dowMatch
+}
+
+var (
+	monthNames = map[string]int{"jan": 1, "feb": 2, "mar": 3, "apr": 4, "may": 5, "jun": 6, "jul": 7, "aug": 8, "sep": 9, "oct": 10, "nov": 11, "dec": 12}
+	dayNames   = map[string]int{"sun": 0, "mon": 1, "tue": 2, "wed": 3, "thu": 4, "fri": 5, "sat": 6}
+)
+
+func parseExpression(s string) (*expression, error) {
+	fields := strings.Fields(s)
+	if len(fields) != 5 {
+		return nil, fmt.Errorf("expected 5 fields, got %d", len(fields))
+	}
+	var (
+		e   expression
+		err error
+	)
+	if e.minute, err = parseField(fields[0], 0, 59, nil, false); err != nil {
+		return nil, fmt.Errorf("minute: %w", err)
+	}
+	if e.hour, err = parseField(fields[1], 0, 23, nil, false); err != nil {
+		return nil, fmt.Errorf("hour: %w", err)
+	}
+	if e.dom, err = parseField(fields[2], 1, 31, nil, false); err != nil {
+		return nil, fmt.Errorf("day of month: %w", err)
+	}
+	if e.month, err = parseField(fields[3], 1, 12, monthNames, false); err != nil {
+		return nil, fmt.Errorf("month: %w", err)
+	}
+	if e.dow, err = parseField(fields[4], 0, 7, dayNames, true); err != nil {
+		return nil, fmt.Errorf("day of week: %w", err)
+	}
+	if e.dow&(1<<7) != 0 { // 7 is also sunday
+		e.dow |= 1
+	}
+	e.domStar = fields[2] == "*"
+	e.dowStar = fields[4] == "*"
+	return &e, nil
+}
+
+// parseField parses a comma separated list of values, ranges (e.g. 1-5) or wildcards, each one with an optional step (e.g. */15).
+// Ranges of day of week fields can wrap around the end of the week (e.g. 5-1), in which case max is an alias of min.
+func parseField(field string, min, max int, names map[string]int, weekdays bool) (uint64, error) {
+	var bits uint64
+	for _, part := range strings.Split(field, ",") {
+		rangePart, step := part, 1
+		if i := strings.Index(part, "/"); i >= 0 {
+			var err error
+			if step, err = strconv.Atoi(part[i+1:]); err != nil || step <= 0 {
+				return 0, fmt.Errorf("invalid step in %q", part)
+			}
+			rangePart = part[:i]
+		}
+		start, end := min, max
+		if rangePart != "*" {
+			bounds := strings.SplitN(rangePart, "-", 2)
+			var err error
+			if start, err = parseValue(bounds[0], names); err != nil {
+				return 0, err
+			}
+			end = start
+			if len(bounds) == 2 {
+				if end, err = parseValue(bounds[1], names); err != nil {
+					return 0, err
+				}
+			} else if step > 1 { // e.g. 5/15 means 5-max/15
+				end = max
+			}
+		}
+		if start < min || end > max || (start > end && !weekdays) {
+			return 0, fmt.Errorf("%q is out of range [%d-%d]", part, min, max)
+		}
+		if start > end { // wrapping around the end of the week: unroll it into the next one, max being the same day as min
+			for v := start; v <= end+max; v += step {
+				bits |= 1 << uint(v%max)
+			}
+			continue
+		}
+		for v := start; v <= end; v += step {
+			bits |= 1 << uint(v)
+		}
+	}
+	return bits, nil
+}
+
+func parseValue(s string, names map[string]int) (int, error) {
+	if v, ok := names[strings.ToLower(s)]; ok {
+		return v, nil
+	}
+	v, err := strconv.Atoi(s)
+	if err != nil {
+		return 0, errors.New("invalid value " + strconv.Quote(s))
+	}
+	return v, nil
+}
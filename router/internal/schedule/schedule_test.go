@@ -0,0 +1,129 @@
+package schedule_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/rudderlabs/rudder-server/router/internal/schedule"
+)
+
+func TestSchedule(t *testing.T) {
+	at := func(s string) time.Time {
+		t.Helper()
+		tm, err := time.Parse(time.RFC3339, s)
+		require.NoError(t, err)
+		return tm
+	}
+
+	t.Run("business hours", func(t *testing.T) {
+		s, err := schedule.New(schedule.Config{Windows: []string{"* 9-16 * * mon-fri"}})
+		require.NoError(t, err)
+		require.True(t, s.IsOpen(at("2023-06-05T09:00:00Z")))  // monday
+		require.True(t, s.IsOpen(at("2023-06-09T16:59:59Z")))  // friday
+		require.False(t, s.IsOpen(at("2023-06-09T17:00:00Z"))) // friday evening
+		require.False(t, s.IsOpen(at("2023-06-10T12:00:00Z"))) // saturday
+	})
+
+	t.Run("timezone", func(t *testing.T) {
+		s, err := schedule.New(schedule.Config{Timezone: "America/New_York", Windows: []string{"* 9-16 * * *"}})
+		require.NoError(t, err)
+		require.False(t, s.IsOpen(at("2023-06-05T09:00:00Z")))
+		require.True(t, s.IsOpen(at("2023-06-05T13:00:00Z")))
+	})
+
+	t.Run("blackouts", func(t *testing.T) {
+		s, err := schedule.New(schedule.Config{Blackouts: []string{"0-29 2 * * *", "* * 25 dec *"}})
+		require.NoError(t, err)
+		require.True(t, s.IsOpen(at("2023-06-05T01:59:00Z")))
+		require.False(t, s.IsOpen(at("2023-06-05T02:15:00Z")))
+		require.True(t, s.IsOpen(at("2023-06-05T02:30:00Z")))
+		require.False(t, s.IsOpen(at("2023-12-25T12:00:00Z")))
+	})
+
+	t.Run("blackouts take precedence over windows", func(t *testing.T) {
+		s, err := schedule.New(schedule.Config{Windows: []string{"* * * * *"}, Blackouts: []string{"* 12 * * *"}})
+		require.NoError(t, err)
+		require.True(t, s.IsOpen(at("2023-06-05T11:00:00Z")))
+		require.False(t, s.IsOpen(at("2023-06-05T12:00:00Z")))
+	})
+
+	t.Run("multiple windows", func(t *testing.T) {
+		s, err := schedule.New(schedule.Config{Windows: []string{"*/15 22-23 * * *", "0,30 0-5 * * sat,7"}})
+		require.NoError(t, err)
+		require.True(t, s.IsOpen(at("2023-06-05T22:45:00Z")))
+		require.False(t, s.IsOpen(at("2023-06-05T22:46:00Z")))
+		require.True(t, s.IsOpen(at("2023-06-10T05:30:00Z")))  // saturday
+		require.True(t, s.IsOpen(at("2023-06-11T00:00:00Z")))  // sunday
+		require.False(t, s.IsOpen(at("2023-06-12T00:00:00Z"))) // monday
+	})
+
+	t.Run("day of week ranges wrapping around the week", func(t *testing.T) {
+		s, err := schedule.New(schedule.Config{Windows: []string{"* * * * fri-sun"}})
+		require.NoError(t, err)
+		require.False(t, s.IsOpen(at("2023-06-08T12:00:00Z"))) // thursday
+		require.True(t, s.IsOpen(at("2023-06-09T12:00:00Z")))  // friday
+		require.True(t, s.IsOpen(at("2023-06-10T12:00:00Z")))  // saturday
+		require.True(t, s.IsOpen(at("2023-06-11T12:00:00Z")))  // sunday
+		require.False(t, s.IsOpen(at("2023-06-12T12:00:00Z"))) // monday
+
+		s, err = schedule.New(schedule.Config{Windows: []string{"* * * * fri-tue/2"}})
+		require.NoError(t, err)
+		require.True(t, s.IsOpen(at("2023-06-09T12:00:00Z")))  // friday
+		require.False(t, s.IsOpen(at("2023-06-10T12:00:00Z"))) // saturday
+		require.True(t, s.IsOpen(at("2023-06-11T12:00:00Z")))  // sunday
+		require.False(t, s.IsOpen(at("2023-06-12T12:00:00Z"))) // monday
+		require.True(t, s.IsOpen(at("2023-06-13T12:00:00Z")))  // tuesday
+	})
+
+	t.Run("day of month or day of week", func(t *testing.T) {
+		s, err := schedule.New(schedule.Config{Windows: []string{"* * 1 * mon"}})
+		require.NoError(t, err)
+		require.True(t, s.IsOpen(at("2023-06-01T12:00:00Z"))) // thursday, 1st
+		require.True(t, s.IsOpen(at("2023-06-05T12:00:00Z"))) // monday
+		require.False(t, s.IsOpen(at("2023-06-06T12:00:00Z")))
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		for _, c := range []schedule.Config{
+			{Windows: []string{"* * * *"}},
+			{Windows: []string{"60 * * * *"}},
+			{Windows: []string{"* 5-1 * * *"}},
+			{Windows: []string{"* * * foo *"}},
+			{Windows: []string{"*/0 * * * *"}},
+			{Blackouts: []string{"* * 0 * *"}},
+			{Timezone: "Nowhere/Land", Windows: []string{"* * * * *"}},
+		} {
+			_, err := schedule.New(c)
+			require.Error(t, err, c)
+		}
+	})
+}
+
+func TestFromDestinationConfig(t *testing.T) {
+	s, err := schedule.FromDestinationConfig(map[string]interface{}{})
+	require.NoError(t, err)
+	require.Nil(t, s)
+
+	s, err = schedule.FromDestinationConfig(map[string]interface{}{
+		"deliverySchedule": map[string]interface{}{"windows": []interface{}{}},
+	})
+	require.NoError(t, err)
+	require.Nil(t, s)
+
+	s, err = schedule.FromDestinationConfig(map[string]interface{}{
+		"deliverySchedule": map[string]interface{}{
+			"timezone": "Europe/Athens",
+			"windows":  []interface{}{"* 9-16 * * *"},
+		},
+	})
+	require.NoError(t, err)
+	require.NotNil(t, s)
+	require.True(t, s.IsOpen(time.Date(2023, 6, 5, 7, 0, 0, 0, time.UTC)))
+
+	_, err = schedule.FromDestinationConfig(map[string]interface{}{
+		"deliverySchedule": map[string]interface{}{"windows": "* * * * *"},
+	})
+	require.Error(t, err)
+}
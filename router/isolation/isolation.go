@@ -96,7 +96,9 @@ func (destinationStrategy) AugmentQueryParams(partition string, params *jobsdb.G
 	params.ParameterFilters = append(params.ParameterFilters, jobsdb.ParameterFilterT{Name: "destination_id", Value: partition})
 }
 
-// StopIteration returns true if the error is ErrDestinationThrottled
+// StopIteration returns true if the error is ErrDestinationThrottled, ErrDestinationCircuitOpen or ErrDestinationPausedBySchedule
 func (destinationStrategy) StopIteration(err error) bool {
-	return errors.Is(err, types.ErrDestinationThrottled)
+	return errors.Is(err, types.ErrDestinationThrottled) ||
+		errors.Is(err, types.ErrDestinationCircuitOpen) ||
+		errors.Is(err, types.ErrDestinationPausedBySchedule)
 }
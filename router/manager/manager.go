@@ -10,14 +10,14 @@ import (
 	backendconfig "github.com/rudderlabs/rudder-server/backend-config"
 	"github.com/rudderlabs/rudder-server/router"
 	"github.com/rudderlabs/rudder-server/router/batchrouter"
+	"github.com/rudderlabs/rudder-server/router/batchrouter/asyncdestinationmanager"
 )
 
 var (
 	objectStorageDestinations = []string{"S3", "GCS", "AZURE_BLOB", "MINIO", "DIGITAL_OCEAN_SPACES"}
-	asyncDestinations         = []string{"MARKETO_BULK_UPLOAD"}
 	warehouseDestinations     = []string{
 		"RS", "BQ", "SNOWFLAKE", "POSTGRES", "CLICKHOUSE", "MSSQL",
-		"AZURE_SYNAPSE", "S3_DATALAKE", "GCS_DATALAKE", "AZURE_DATALAKE", "DELTALAKE",
+		"AZURE_SYNAPSE", "S3_DATALAKE", "GCS_DATALAKE", "AZURE_DATALAKE", "DELTALAKE", "SQLITE",
 	}
 )
 
@@ -100,7 +100,7 @@ loop:
 						// For batch router destinations
 						if slices.Contains(objectStorageDestinations, destination.DestinationDefinition.Name) ||
 							slices.Contains(warehouseDestinations, destination.DestinationDefinition.Name) ||
-							slices.Contains(asyncDestinations, destination.DestinationDefinition.Name) {
+							asyncdestinationmanager.IsAsyncDestination(destination.DestinationDefinition.Name) {
 							_, ok := dstToBatchRouter[destination.DestinationDefinition.Name]
 							if !ok {
 								r.logger.Infof("Starting a new Batch Destination Router: %s", destination.DestinationDefinition.Name)
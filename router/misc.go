@@ -57,7 +57,12 @@ func getWorkerPartition(key string, noOfWorkers int) int {
 	return misc.GetHash(key) % noOfWorkers
 }
 
-func jobOrderKey(userID, destinationID string) string {
+// jobOrderKey returns the key for ordering a destination's jobs, i.e. the job's ordering key as assigned by the processor
+// (see router/ordering) or, if the job doesn't have one, its user id
+func jobOrderKey(userID, orderingKey, destinationID string) string {
+	if orderingKey != "" {
+		return orderingKey + ":" + destinationID
+	}
 	return userID + ":" + destinationID
 }
 
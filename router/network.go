@@ -17,7 +17,9 @@ import (
 
 	"github.com/rudderlabs/rudder-go-kit/logger"
 	"github.com/rudderlabs/rudder-server/processor/integrations"
+	"github.com/rudderlabs/rudder-server/router/internal/nativedelivery"
 	"github.com/rudderlabs/rudder-server/router/utils"
+	"github.com/rudderlabs/rudder-server/services/debugger/capture"
 	"github.com/rudderlabs/rudder-server/utils/httputil"
 	"github.com/rudderlabs/rudder-server/utils/misc"
 	"github.com/rudderlabs/rudder-server/utils/sysUtils"
@@ -158,8 +160,21 @@ func (network *netHandle) SendPost(ctx context.Context, structData integrations.
 
 		req.Header.Add("User-Agent", "RudderLabs")
 
+		// requests of natively delivered destinations need to be authenticated by the destination's signer
+		if signer, ok := nativedelivery.SignerFromContext(ctx); ok {
+			if err := network.sign(signer, req); err != nil {
+				network.logger.Error(fmt.Sprintf(`400 Unable to sign %q request for URL : %q. Error: %s`, requestMethod, postInfo.URL, err.Error()))
+				return &utils.SendPostResponse{
+					StatusCode:   400,
+					ResponseBody: []byte(fmt.Sprintf(`400 Unable to sign %q request for URL : %q. Error: %s`, requestMethod, postInfo.URL, err.Error())),
+				}
+			}
+		}
+
+		startedAt := time.Now()
 		resp, err := client.Do(req)
 		if err != nil {
+			capture.Record(ctx, req, nil, nil, err, time.Since(startedAt))
 			return &utils.SendPostResponse{
 				StatusCode:   http.StatusGatewayTimeout,
 				ResponseBody: []byte(fmt.Sprintf(`504 Unable to make %q request for URL : %q. Error: %s`, requestMethod, postInfo.URL, err.Error())),
@@ -169,6 +184,7 @@ func (network *netHandle) SendPost(ctx context.Context, structData integrations.
 		defer func() { httputil.CloseResponse(resp) }()
 
 		respBody, err := io.ReadAll(resp.Body)
+		capture.Record(ctx, req, resp, respBody, err, time.Since(startedAt))
 		if err != nil {
 			return &utils.SendPostResponse{
 				StatusCode:   resp.StatusCode,
@@ -210,6 +226,22 @@ func (network *netHandle) SendPost(ctx context.Context, structData integrations.
 	}
 }
 
+// sign signs the request using the provided signer, passing it a copy of the request's body
+func (*netHandle) sign(signer nativedelivery.Signer, req *http.Request) error {
+	var body []byte
+	if req.GetBody != nil {
+		rc, err := req.GetBody()
+		if err != nil {
+			return fmt.Errorf("getting request body: %w", err)
+		}
+		defer func() { _ = rc.Close() }()
+		if body, err = io.ReadAll(rc); err != nil {
+			return fmt.Errorf("reading request body: %w", err)
+		}
+	}
+	return signer.Sign(req, body)
+}
+
 // Setup initializes the module
 func (network *netHandle) Setup(destID string, netClientTimeout time.Duration) {
 	network.logger.Info("Network Handler Startup")
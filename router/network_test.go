@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"io"
 	"net/http"
+	"time"
 
 	"github.com/golang/mock/gomock"
 	. "github.com/onsi/ginkgo/v2"
@@ -14,6 +15,8 @@ import (
 	"github.com/rudderlabs/rudder-go-kit/logger"
 	mocksSysUtils "github.com/rudderlabs/rudder-server/mocks/utils/sysUtils"
 	"github.com/rudderlabs/rudder-server/processor/integrations"
+	"github.com/rudderlabs/rudder-server/router/internal/nativedelivery"
+	"github.com/rudderlabs/rudder-server/services/debugger/capture"
 )
 
 type networkContext struct {
@@ -118,6 +121,82 @@ var _ = Describe("Network", func() {
 			fmt.Println(string(resp.ResponseBody))
 			Expect(string(resp.ResponseBody)).To(Equal("504 Unable to make \"\" request for URL : \"https://www.google-analytics.com/collect\". Error: Get \"https://www.google-analytics.com/collect\": context canceled"))
 		})
+
+		It("should sign the request using the signer of the context", func() {
+			network := &netHandle{}
+			network.logger = logger.NOP
+			network.httpClient = c.mockHTTPClient
+
+			structData := integrations.PostParametersT{
+				Type:          "REST",
+				URL:           "https://example.com/webhook",
+				RequestMethod: "POST",
+				Headers:       map[string]interface{}{},
+				QueryParams:   map[string]interface{}{},
+				Body: map[string]interface{}{
+					"JSON": map[string]interface{}{"key": "value"},
+				},
+			}
+			signer, err := nativedelivery.NewSigner(nativedelivery.AuthConfig{Type: nativedelivery.AuthTypeHMAC, Secret: "secret"})
+			Expect(err).To(BeNil())
+
+			c.mockHTTPClient.EXPECT().Do(gomock.Any()).Times(1).DoAndReturn(func(req *http.Request) (*http.Response, error) {
+				body, err := io.ReadAll(req.Body)
+				Expect(err).To(BeNil())
+				Expect(string(body)).To(Equal(`{"key":"value"}`))
+				Expect(req.Header.Get("X-Signature")).To(Equal("ee2012a00f1649bc35f4cfe1fa582b2ebda5cbf2ef82713d6dc2ec93d81f96fb"))
+				return &http.Response{StatusCode: 200, Body: io.NopCloser(bytes.NewReader([]byte(`{}`)))}, nil
+			})
+
+			resp := network.SendPost(nativedelivery.WithSigner(context.Background(), signer), structData)
+			Expect(resp.StatusCode).To(Equal(http.StatusOK))
+		})
+
+		It("should capture the http exchange using the recorder of the context", func() {
+			network := &netHandle{}
+			network.logger = logger.NOP
+			network.httpClient = c.mockHTTPClient
+
+			structData := integrations.PostParametersT{
+				Type:          "REST",
+				URL:           "https://example.com/webhook",
+				RequestMethod: "POST",
+				Headers:       map[string]interface{}{"Authorization": "Bearer secret"},
+				QueryParams:   map[string]interface{}{},
+				Body: map[string]interface{}{
+					"JSON": map[string]interface{}{"key": "value"},
+				},
+			}
+			c.mockHTTPClient.EXPECT().Do(gomock.Any()).Times(1).Return(&http.Response{
+				StatusCode: 202,
+				Header:     http.Header{"Content-Type": []string{"application/json"}},
+				Body:       io.NopCloser(bytes.NewReader([]byte(`{"ok":true}`))),
+			}, nil)
+
+			store, err := capture.NewLocalStore(GinkgoT().TempDir(), 10)
+			Expect(err).To(BeNil())
+			capturer := capture.NewCapturer(store, logger.NOP)
+			capturer.SetDestinations(map[string]time.Time{"destination": {}})
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+			go capturer.Run(ctx)
+
+			resp := network.SendPost(capture.WithRecorder(context.Background(), capturer.Recorder("workspace", "destination", "WEBHOOK", []int64{1}, nil)), structData)
+			Expect(resp.StatusCode).To(Equal(http.StatusAccepted))
+
+			var files []string
+			Eventually(func() int {
+				files, _ = store.List("destination")
+				return len(files)
+			}).Should(Equal(1))
+			e, err := capture.ReadExchange(files[0])
+			Expect(err).To(BeNil())
+			Expect(e.Request.URL).To(Equal("https://example.com/webhook"))
+			Expect(e.Request.Header.Get("Authorization")).To(Equal(capture.Redacted))
+			Expect(e.Request.Body).To(Equal(`{"key":"value"}`))
+			Expect(e.Response.StatusCode).To(Equal(http.StatusAccepted))
+			Expect(e.Response.Body).To(Equal(`{"ok":true}`))
+		})
 	})
 
 	Context("Verify response bodies are propagated/filtered based on the response's content-type", func() {
@@ -0,0 +1,108 @@
+// Package ordering implements configurable ordering keys for router jobs.
+//
+// By default the router guarantees the delivery order of a destination's events per user, i.e. all events sharing
+// the same userID & destinationID are delivered in order and a failing event blocks the rest of its user's events.
+// Destinations can instead order their events by the value of a field of the event, e.g.
+//
+//	"eventOrderingKey": "properties.orderId"
+//
+// or turn ordering off entirely
+//
+//	"disableEventOrdering": true
+//
+// The processor evaluates the ordering key of every event and stores it in the router job's parameters, where the
+// router picks it up instead of the userID. Events without a value for the configured field fall back to user ordering.
+package ordering
+
+import (
+	"fmt"
+	"strings"
+)
+
+const (
+	// ConfigKey is the key of the destination config holding the path of the event field used for ordering events
+	ConfigKey = "eventOrderingKey"
+	// DisabledConfigKey is the key of the destination config for turning event ordering off
+	DisabledConfigKey = "disableEventOrdering"
+	// ParameterKey is the key of the job parameter holding the ordering key of a job
+	ParameterKey = "ordering_key"
+)
+
+// unorderedPrefix prefixes the ordering keys of events belonging to destinations without event ordering
+const unorderedPrefix = "unordered:"
+
+// Key evaluates the ordering keys of events
+type Key struct {
+	path     []string
+	disabled bool
+}
+
+// FromDestinationConfig returns the ordering key of the destination's config, or nil if the destination
+// orders its events per user
+func FromDestinationConfig(destConfig map[string]interface{}) (*Key, error) {
+	if raw, ok := destConfig[DisabledConfigKey]; ok && raw != nil {
+		disabled, ok := raw.(bool)
+		if !ok {
+			return nil, fmt.Errorf("invalid %s config: expected a boolean, got %T", DisabledConfigKey, raw)
+		}
+		if disabled {
+			return &Key{disabled: true}, nil
+		}
+	}
+	raw, ok := destConfig[ConfigKey]
+	if !ok || raw == nil {
+		return nil, nil
+	}
+	expr, ok := raw.(string)
+	if !ok {
+		return nil, fmt.Errorf("invalid %s config: expected a string, got %T", ConfigKey, raw)
+	}
+	expr = strings.TrimSpace(expr)
+	if expr == "" {
+		return nil, nil
+	}
+	path := strings.Split(expr, ".")
+	for _, p := range path {
+		if p == "" {
+			return nil, fmt.Errorf("invalid %s config: %q", ConfigKey, expr)
+		}
+	}
+	return &Key{path: path}, nil
+}
+
+// Evaluate returns the ordering key of an event. Events of destinations without event ordering get a unique
+// key, based on the provided job id, whereas an empty key is returned for events without a value for the configured field.
+func (k *Key) Evaluate(event map[string]interface{}, jobID string) string {
+	if k == nil {
+		return ""
+	}
+	if k.disabled {
+		return unorderedPrefix + jobID
+	}
+	var value interface{} = event
+	for _, p := range k.path {
+		m, ok := value.(map[string]interface{})
+		if !ok {
+			return ""
+		}
+		value = m[p]
+	}
+	switch v := value.(type) {
+	case nil:
+		return ""
+	case string:
+		if v == "" {
+			return ""
+		}
+		return k.prefix() + v
+	case map[string]interface{}, []interface{}:
+		return ""
+	default:
+		return k.prefix() + fmt.Sprint(v)
+	}
+}
+
+// prefix keeps the keys of different fields apart from each other and from user ids
+func (k *Key) prefix() string {
+	return strings.Join(k.path, ".") + "="
+}
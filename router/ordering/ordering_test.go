@@ -0,0 +1,64 @@
+package ordering_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/rudderlabs/rudder-server/router/ordering"
+)
+
+func TestFromDestinationConfig(t *testing.T) {
+	key, err := ordering.FromDestinationConfig(map[string]interface{}{})
+	require.NoError(t, err)
+	require.Nil(t, key)
+
+	key, err = ordering.FromDestinationConfig(map[string]interface{}{"eventOrderingKey": " "})
+	require.NoError(t, err)
+	require.Nil(t, key)
+
+	key, err = ordering.FromDestinationConfig(map[string]interface{}{"eventOrderingKey": "properties.orderId", "disableEventOrdering": false})
+	require.NoError(t, err)
+	require.NotNil(t, key)
+
+	key, err = ordering.FromDestinationConfig(map[string]interface{}{"eventOrderingKey": "properties.orderId", "disableEventOrdering": true})
+	require.NoError(t, err)
+	require.NotNil(t, key)
+
+	_, err = ordering.FromDestinationConfig(map[string]interface{}{"eventOrderingKey": "properties..orderId"})
+	require.Error(t, err)
+
+	_, err = ordering.FromDestinationConfig(map[string]interface{}{"eventOrderingKey": 1})
+	require.Error(t, err)
+
+	_, err = ordering.FromDestinationConfig(map[string]interface{}{"disableEventOrdering": "true"})
+	require.Error(t, err)
+}
+
+func TestEvaluate(t *testing.T) {
+	event := map[string]interface{}{
+		"userId": "user-1",
+		"context": map[string]interface{}{
+			"groupId": "group-1",
+		},
+		"properties": map[string]interface{}{
+			"orderId":  float64(1234),
+			"empty":    "",
+			"products": []interface{}{"product-1"},
+		},
+	}
+	evaluate := func(destConfig map[string]interface{}) string {
+		key, err := ordering.FromDestinationConfig(destConfig)
+		require.NoError(t, err)
+		return key.Evaluate(event, "job-1")
+	}
+
+	require.Empty(t, evaluate(map[string]interface{}{}), "user ordering")
+	require.Equal(t, "context.groupId=group-1", evaluate(map[string]interface{}{"eventOrderingKey": "context.groupId"}))
+	require.Equal(t, "properties.orderId=1234", evaluate(map[string]interface{}{"eventOrderingKey": "properties.orderId"}))
+	require.Equal(t, "unordered:job-1", evaluate(map[string]interface{}{"eventOrderingKey": "context.groupId", "disableEventOrdering": true}))
+
+	for _, path := range []string{"context.missing", "properties.empty", "properties.products", "properties", "userId.nested"} {
+		require.Empty(t, evaluate(map[string]interface{}{"eventOrderingKey": path}), "fallback to user ordering for %q", path)
+	}
+}
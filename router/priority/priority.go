@@ -0,0 +1,138 @@
+// Package priority implements priority classes for router jobs.
+//
+// Priority classes are assigned to events by the processor, according to the rules found in the destination's config, e.g.
+//
+//	"priorityClasses": {
+//		"high": {"eventNames": ["Order Completed"]},
+//		"low": {"eventTypes": ["page", "screen"]}
+//	}
+//
+// and stored in the router job's parameters. Events not matching any rule belong to the normal class.
+// The router then uses a weighted [Quota] for sharing its capacity between the different classes during pickup.
+package priority
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+)
+
+// ConfigKey is the key of the destination config holding the priority class rules
+const ConfigKey = "priorityClasses"
+
+// ParameterKey is the key of the job parameter holding the priority class of a job
+const ParameterKey = "priority_class"
+
+// Class is a priority class
+type Class string
+
+const (
+	High   Class = "high"
+	Normal Class = "normal"
+	Low    Class = "low"
+)
+
+// Classes are all the supported priority classes, from highest to lowest priority
+var Classes = []Class{High, Normal, Low}
+
+// ClassOf returns the priority class corresponding to the provided value, falling back to [Normal] for unknown values
+func ClassOf(value string) Class {
+	switch c := Class(value); c {
+	case High, Low:
+		return c
+	default:
+		return Normal
+	}
+}
+
+// Rule matches events by their names or types
+type Rule struct {
+	EventNames []string `json:"eventNames"`
+	EventTypes []string `json:"eventTypes"`
+}
+
+func (r Rule) matches(eventName, eventType string) bool {
+	for _, n := range r.EventNames {
+		if n == eventName {
+			return true
+		}
+	}
+	for _, t := range r.EventTypes {
+		if t == eventType {
+			return true
+		}
+	}
+	return false
+}
+
+// Rules assign priority classes to events
+type Rules map[Class]Rule
+
+// RulesFromDestinationConfig returns the priority class rules of the destination's config, or nil if the destination doesn't have any
+func RulesFromDestinationConfig(destConfig map[string]interface{}) (Rules, error) {
+	raw, ok := destConfig[ConfigKey]
+	if !ok || raw == nil {
+		return nil, nil
+	}
+	data, err := json.Marshal(raw)
+	if err != nil {
+		return nil, fmt.Errorf("marshalling %s config: %w", ConfigKey, err)
+	}
+	var rules Rules
+	if err := json.Unmarshal(data, &rules); err != nil {
+		return nil, fmt.Errorf("unmarshalling %s config: %w", ConfigKey, err)
+	}
+	for class := range rules {
+		if class != High && class != Low {
+			return nil, fmt.Errorf("unsupported priority class %q", class)
+		}
+	}
+	return rules, nil
+}
+
+// Classify returns the priority class of an event. Rules of higher priority classes take precedence.
+func (r Rules) Classify(eventName, eventType string) Class {
+	for _, class := range []Class{High, Low} {
+		if rule, ok := r[class]; ok && rule.matches(eventName, eventType) {
+			return class
+		}
+	}
+	return Normal
+}
+
+// Quota shares a capacity between priority classes, proportionally to their weights
+type Quota struct {
+	limits map[Class]int
+	used   map[Class]int
+}
+
+// NewQuota creates a new quota for sharing the provided capacity according to the weights of the classes.
+// Classes without a weight get no share of the capacity.
+func NewQuota(weights map[Class]int, capacity int) *Quota {
+	var total int
+	for _, w := range weights {
+		if w > 0 {
+			total += w
+		}
+	}
+	q := &Quota{limits: map[Class]int{}, used: map[Class]int{}}
+	if total == 0 {
+		return q
+	}
+	for class, w := range weights {
+		if w > 0 {
+			q.limits[class] = int(math.Ceil(float64(capacity*w) / float64(total)))
+		}
+	}
+	return q
+}
+
+// Available returns true if the class hasn't used its share of the capacity yet
+func (q *Quota) Available(class Class) bool {
+	return q.used[class] < q.limits[class]
+}
+
+// Use consumes one unit of the class' share
+func (q *Quota) Use(class Class) {
+	q.used[class]++
+}
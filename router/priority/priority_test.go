@@ -0,0 +1,72 @@
+package priority_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/rudderlabs/rudder-server/router/priority"
+)
+
+func TestRules(t *testing.T) {
+	rules, err := priority.RulesFromDestinationConfig(map[string]interface{}{})
+	require.NoError(t, err)
+	require.Nil(t, rules)
+	require.Equal(t, priority.Normal, rules.Classify("Order Completed", "track"))
+
+	rules, err = priority.RulesFromDestinationConfig(map[string]interface{}{
+		"priorityClasses": map[string]interface{}{
+			"high": map[string]interface{}{"eventNames": []interface{}{"Order Completed"}},
+			"low":  map[string]interface{}{"eventTypes": []interface{}{"page", "screen", "track"}},
+		},
+	})
+	require.NoError(t, err)
+	require.Equal(t, priority.High, rules.Classify("Order Completed", "track"), "high rules take precedence")
+	require.Equal(t, priority.Low, rules.Classify("Product Viewed", "track"))
+	require.Equal(t, priority.Low, rules.Classify("", "page"))
+	require.Equal(t, priority.Normal, rules.Classify("", "identify"))
+
+	_, err = priority.RulesFromDestinationConfig(map[string]interface{}{
+		"priorityClasses": map[string]interface{}{"urgent": map[string]interface{}{"eventTypes": []interface{}{"track"}}},
+	})
+	require.Error(t, err)
+
+	_, err = priority.RulesFromDestinationConfig(map[string]interface{}{"priorityClasses": "high"})
+	require.Error(t, err)
+}
+
+func TestClassOf(t *testing.T) {
+	require.Equal(t, priority.High, priority.ClassOf("high"))
+	require.Equal(t, priority.Low, priority.ClassOf("low"))
+	require.Equal(t, priority.Normal, priority.ClassOf("normal"))
+	require.Equal(t, priority.Normal, priority.ClassOf(""))
+	require.Equal(t, priority.Normal, priority.ClassOf("other"))
+}
+
+func TestQuota(t *testing.T) {
+	use := func(q *priority.Quota, class priority.Class) int {
+		var n int
+		for q.Available(class) {
+			q.Use(class)
+			n++
+		}
+		return n
+	}
+
+	q := priority.NewQuota(map[priority.Class]int{priority.High: 6, priority.Normal: 3, priority.Low: 1}, 100)
+	require.Equal(t, 60, use(q, priority.High))
+	require.Equal(t, 30, use(q, priority.Normal))
+	require.Equal(t, 10, use(q, priority.Low))
+
+	q = priority.NewQuota(map[priority.Class]int{priority.High: 1, priority.Low: 0}, 3)
+	require.Equal(t, 3, use(q, priority.High))
+	require.Zero(t, use(q, priority.Normal))
+	require.Zero(t, use(q, priority.Low))
+
+	q = priority.NewQuota(map[priority.Class]int{priority.High: 2, priority.Low: 1}, 1)
+	require.Equal(t, 1, use(q, priority.High), "shares are rounded up")
+	require.Equal(t, 1, use(q, priority.Low), "shares are rounded up")
+
+	q = priority.NewQuota(nil, 10)
+	require.Zero(t, use(q, priority.Normal))
+}
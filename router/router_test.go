@@ -33,7 +33,10 @@ import (
 	mocksJobsDB "github.com/rudderlabs/rudder-server/mocks/jobsdb"
 	mocksRouter "github.com/rudderlabs/rudder-server/mocks/router"
 	mocksTransformer "github.com/rudderlabs/rudder-server/mocks/router/transformer"
+	"github.com/rudderlabs/rudder-server/router/internal/circuitbreaker"
 	"github.com/rudderlabs/rudder-server/router/internal/eventorder"
+	"github.com/rudderlabs/rudder-server/router/internal/schedule"
+	"github.com/rudderlabs/rudder-server/router/priority"
 	"github.com/rudderlabs/rudder-server/router/types"
 	routerUtils "github.com/rudderlabs/rudder-server/router/utils"
 	destinationdebugger "github.com/rudderlabs/rudder-server/services/debugger/destination"
@@ -305,11 +308,97 @@ func TestBackoff(t *testing.T) {
 					RetryTime:  time.Now().Add(1 * time.Hour),
 				},
 			}
-			slot, err := r.findWorkerSlot(workers, backoffJob, map[string]struct{}{jobOrderKey(job.UserID, "destination"): {}})
+			slot, err := r.findWorkerSlot(workers, backoffJob, map[string]struct{}{jobOrderKey(job.UserID, "", "destination"): {}})
 			require.Nil(t, slot)
 			require.ErrorIs(t, err, types.ErrJobOrderBlocked)
 		})
+
+		t.Run("circuit open", func(t *testing.T) {
+			defer func() { r.circuitBreaker = nil }()
+			r.circuitBreaker = circuitbreaker.NewBreaker(
+				circuitbreaker.WithEnabled(true),
+				circuitbreaker.WithMinRequests(1),
+				circuitbreaker.WithErrorRateThreshold(1),
+				circuitbreaker.WithTimeout(time.Hour),
+			)
+			r.circuitBreaker.Report("destination", false)
+			for _, guaranteeUserEventOrder := range []bool{false, true} {
+				r.guaranteeUserEventOrder = guaranteeUserEventOrder
+				workers[0].inputReservations = 0
+				blockedOrderKeys := map[string]struct{}{}
+				slot, err := r.findWorkerSlot(workers, noBackoffJob1, blockedOrderKeys)
+				require.Nil(t, slot)
+				require.ErrorIs(t, err, types.ErrDestinationCircuitOpen)
+				require.Equal(t, guaranteeUserEventOrder, len(blockedOrderKeys) == 1, "order key should be blocked only when event ordering is enabled")
+			}
+		})
+
+		t.Run("paused by schedule", func(t *testing.T) {
+			defer func() { r.deliverySchedules = nil }()
+			s, err := schedule.New(schedule.Config{Windows: []string{"* * 30 feb *"}}) // never open
+			require.NoError(t, err)
+			r.deliverySchedules = map[string]*schedule.Schedule{"destination": s}
+			for _, guaranteeUserEventOrder := range []bool{false, true} {
+				r.guaranteeUserEventOrder = guaranteeUserEventOrder
+				workers[0].inputReservations = 0
+				blockedOrderKeys := map[string]struct{}{}
+				slot, err := r.findWorkerSlot(workers, backoffJob, blockedOrderKeys)
+				require.Nil(t, slot)
+				require.ErrorIs(t, err, types.ErrDestinationPausedBySchedule, "schedule should be checked before backoff")
+				require.Equal(t, guaranteeUserEventOrder, len(blockedOrderKeys) == 1, "order key should be blocked only when event ordering is enabled")
+			}
+		})
+	})
+}
+
+func TestDeferJob(t *testing.T) {
+	newJob := func(jobID int64, userID string, class priority.Class) *jobsdb.JobT {
+		return &jobsdb.JobT{
+			JobID:      jobID,
+			UserID:     userID,
+			Parameters: []byte(fmt.Sprintf(`{"destination_id": "destination", "priority_class": %q}`, class)),
+		}
+	}
+	r := &Handle{}
+	weights := map[priority.Class]int{priority.High: 1, priority.Normal: 1, priority.Low: 1}
+
+	t.Run("without event ordering", func(t *testing.T) {
+		r.guaranteeUserEventOrder = false
+		quota := priority.NewQuota(weights, 3)
+		deferredOrderKeys := map[string]struct{}{}
+		require.False(t, r.deferJob(newJob(1, "u1", priority.Low), quota, deferredOrderKeys))
+		quota.Use(priority.Low)
+		require.True(t, r.deferJob(newJob(2, "u2", priority.Low), quota, deferredOrderKeys), "low class share is used up")
+		require.False(t, r.deferJob(newJob(3, "u2", priority.High), quota, deferredOrderKeys))
+		require.Empty(t, deferredOrderKeys)
 	})
+
+	t.Run("with event ordering", func(t *testing.T) {
+		r.guaranteeUserEventOrder = true
+		quota := priority.NewQuota(weights, 3)
+		deferredOrderKeys := map[string]struct{}{}
+		require.False(t, r.deferJob(newJob(1, "u1", priority.Low), quota, deferredOrderKeys))
+		quota.Use(priority.Low)
+		require.True(t, r.deferJob(newJob(2, "u2", priority.Low), quota, deferredOrderKeys), "low class share is used up")
+		require.True(t, r.deferJob(newJob(3, "u2", priority.High), quota, deferredOrderKeys), "an earlier job of the same user is deferred")
+		require.False(t, r.deferJob(newJob(4, "u1", priority.High), quota, deferredOrderKeys))
+		require.False(t, r.deferJob(newJob(5, "u3", ""), quota, deferredOrderKeys), "jobs without a priority class belong to the normal class")
+	})
+}
+
+func TestJobOrderKey(t *testing.T) {
+	require.Equal(t, "u1:destination", jobOrderKey("u1", "", "destination"))
+	require.Equal(t, "context.groupId=g1:destination", jobOrderKey("u1", "context.groupId=g1", "destination"))
+	require.Equal(t, jobOrderKey("u1", "context.groupId=g1", "destination"), jobOrderKey("u2", "context.groupId=g1", "destination"), "jobs of different users sharing the same ordering key are ordered together")
+
+	r := &Handle{}
+	r.guaranteeUserEventOrder = true
+	job := &jobsdb.JobT{JobID: 1, UserID: "u2", Parameters: []byte(`{"destination_id": "destination", "ordering_key": "context.groupId=g1"}`)}
+	require.True(t, r.deferJob(job, priority.NewQuota(nil, 1), map[string]struct{}{}), "no quota left")
+	deferredOrderKeys := map[string]struct{}{"context.groupId=g1:destination": {}}
+	require.True(t, r.deferJob(job, priority.NewQuota(map[priority.Class]int{priority.Normal: 1}, 1), deferredOrderKeys), "an earlier job with the same ordering key is deferred")
+	deferredOrderKeys = map[string]struct{}{"u2:destination": {}}
+	require.False(t, r.deferJob(job, priority.NewQuota(map[priority.Class]int{priority.Normal: 1}, 1), deferredOrderKeys), "jobs with an ordering key are not ordered by user")
 }
 
 var _ = Describe("router", func() {
@@ -9,6 +9,7 @@ import (
 	"fmt"
 	"io"
 	"net/http"
+	"net/url"
 	"os"
 	"strconv"
 	"strings"
@@ -23,6 +24,7 @@ import (
 	"github.com/rudderlabs/rudder-server/processor/integrations"
 	"github.com/rudderlabs/rudder-server/router/types"
 	router_utils "github.com/rudderlabs/rudder-server/router/utils"
+	"github.com/rudderlabs/rudder-server/services/debugger/capture"
 	"github.com/rudderlabs/rudder-server/utils/httputil"
 	"github.com/rudderlabs/rudder-server/utils/sysUtils"
 	utilTypes "github.com/rudderlabs/rudder-server/utils/types"
@@ -261,6 +263,12 @@ func (trans *handle) ProxyRequest(ctx context.Context, proxyReqParams *ProxyRequ
 	rdlTime := time.Now()
 	httpPrxResp := trans.doProxyRequest(ctx, proxyReqParams)
 	respData, respCode, requestError := httpPrxResp.respData, httpPrxResp.statusCode, httpPrxResp.err
+	// capturing the exchange between the proxy and the destination, not the proxy request which carries the destination's config
+	var destResp *capture.Response
+	if requestError == nil {
+		destResp = destinationResponse(respData)
+	}
+	capture.RecordExchange(ctx, destinationRequest(proxyReqParams.ResponseData.PostParametersT), destResp, requestError, time.Since(rdlTime))
 	reqSuccessStr := strconv.FormatBool(requestError == nil)
 	stats.Default.NewTaggedStat("transformer_proxy.request_latency", stats.TimerType, stats.Tags{"requestSuccess": reqSuccessStr, "destType": proxyReqParams.DestName}).SendTiming(time.Since(rdlTime))
 	stats.Default.NewTaggedStat("transformer_proxy.request_result", stats.CountType, stats.Tags{"requestSuccess": reqSuccessStr, "destType": proxyReqParams.DestName}).Increment()
@@ -414,6 +422,75 @@ func (trans *handle) doProxyRequest(ctx context.Context, proxyReqParams *ProxyRe
 	}
 }
 
+// destinationRequest returns the request which the transformer proxy delivers to the destination, assembled the same way the router's network handler does
+func destinationRequest(params integrations.PostParametersT) capture.Request {
+	req := capture.Request{
+		Method: params.RequestMethod,
+		URL:    params.URL,
+		Header: http.Header{},
+	}
+	if u, err := url.Parse(params.URL); err == nil && len(params.QueryParams) > 0 {
+		query := u.Query()
+		for key, val := range params.QueryParams {
+			query.Add(key, fmt.Sprint(val))
+		}
+		u.RawQuery = query.Encode()
+		req.URL = u.String()
+	}
+	for key, val := range params.Headers {
+		req.Header.Add(key, fmt.Sprint(val))
+	}
+	var format string
+	var body map[string]interface{}
+	for k, v := range params.Body {
+		if v, _ := v.(map[string]interface{}); len(v) > 0 {
+			format, body = k, v
+			break
+		}
+	}
+	switch format {
+	case "JSON":
+		payload, _ := jsonfast.Marshal(body)
+		req.Body = string(payload)
+	case "JSON_ARRAY":
+		req.Body, _ = body["batch"].(string)
+	case "XML":
+		req.Body, _ = body["payload"].(string)
+	case "FORM":
+		form := url.Values{}
+		for key, val := range body {
+			form.Set(key, fmt.Sprint(val))
+		}
+		req.Body = form.Encode()
+		if req.Header.Get("Content-Type") == "" {
+			req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		}
+	}
+	return req
+}
+
+// destinationResponse returns the destination's response as reported by the transformer proxy under output.destinationResponse,
+// which is either the destination's raw response or an object with its response and status code
+func destinationResponse(respData []byte) *capture.Response {
+	output := gjson.GetBytes(respData, "output")
+	destResp := output.Get("destinationResponse")
+	if !destResp.Exists() {
+		return nil
+	}
+	resp := &capture.Response{StatusCode: int(output.Get("status").Int())}
+	body := destResp
+	if destResp.IsObject() && destResp.Get("status").Exists() {
+		resp.StatusCode = int(destResp.Get("status").Int())
+		body = destResp.Get("response")
+	}
+	if body.Type == gjson.String {
+		resp.Body = body.Str
+	} else {
+		resp.Body = body.Raw
+	}
+	return resp
+}
+
 func getBatchURL() string {
 	return strings.TrimSuffix(config.GetString("DEST_TRANSFORM_URL", "http://localhost:9090"), "/") + "/batch"
 }
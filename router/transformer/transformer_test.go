@@ -22,6 +22,7 @@ import (
 	"github.com/rudderlabs/rudder-server/jobsdb"
 	"github.com/rudderlabs/rudder-server/processor/integrations"
 	"github.com/rudderlabs/rudder-server/router/types"
+	"github.com/rudderlabs/rudder-server/services/debugger/capture"
 	utilTypes "github.com/rudderlabs/rudder-server/utils/types"
 )
 
@@ -319,6 +320,66 @@ func TestProxyRequest(t *testing.T) {
 	}
 }
 
+func TestProxyRequestCapture(t *testing.T) {
+	initMocks(t)
+
+	srv := httptest.NewServer(mockProxyHandler(0, http.StatusOK, `{"output": {"status": 400, "message": "", "destinationResponse": {"response": {"error": "invalid conversion", "token": "dt-1234567"}, "status": 400}}}`))
+	defer srv.Close()
+
+	store, err := capture.NewLocalStore(t.TempDir(), 10)
+	require.NoError(t, err)
+	capturer := capture.NewCapturer(store, logger.NOP)
+	capturer.SetDestinations(map[string]time.Time{"dest-1": {}})
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go capturer.Run(ctx)
+
+	secrets := capture.DestinationSecrets(
+		map[string]interface{}{"secretKeys": []interface{}{"developerToken", "clientKey"}},
+		map[string]interface{}{"developerToken": "dt-1234567", "clientKey": "ck-7654321", "customerId": "1234"},
+	)
+	recordCtx := capture.WithRecorder(ctx, capturer.Recorder("workspace", "dest-1", "good_dest", []int64{1}, secrets))
+	tr := NewTransformer(time.Second, time.Second)
+	stCd, _, _ := tr.ProxyRequest(recordCtx, &ProxyRequestParams{
+		ResponseData: ProxyRequestPayload{
+			PostParametersT: integrations.PostParametersT{
+				Type:          "REST",
+				URL:           "https://api.good_dest.com/v1/customers/1234/conversions",
+				RequestMethod: http.MethodPost,
+				Headers:       map[string]interface{}{"Content-Type": "application/json", "X-Developer": "dt-1234567"},
+				QueryParams:   map[string]interface{}{"validate": true},
+				Body: map[string]interface{}{
+					"JSON":       map[string]interface{}{"conversion": "purchase", "clientKey": "ck-7654321"},
+					"FORM":       map[string]interface{}{},
+					"JSON_ARRAY": map[string]interface{}{},
+					"XML":        map[string]interface{}{},
+				},
+				Files: map[string]interface{}{},
+			},
+		},
+		DestName: "good_dest",
+		JobID:    1,
+		BaseUrl:  srv.URL,
+	})
+	require.Equal(t, http.StatusOK, stCd)
+
+	var files []string
+	require.Eventually(t, func() bool {
+		files, err = store.List("dest-1")
+		return err == nil && len(files) == 1
+	}, 5*time.Second, 10*time.Millisecond)
+	e, err := capture.ReadExchange(files[0])
+	require.NoError(t, err)
+	require.Equal(t, []int64{1}, e.JobIDs)
+	require.Equal(t, http.MethodPost, e.Request.Method)
+	require.Equal(t, "https://api.good_dest.com/v1/customers/1234/conversions?validate=true", e.Request.URL, "the destination request should be captured instead of the proxy request")
+	require.Equal(t, []string{capture.Redacted}, e.Request.Header["X-Developer"])
+	require.JSONEq(t, `{"conversion":"purchase","clientKey":"[REDACTED]"}`, e.Request.Body)
+	require.NotNil(t, e.Response)
+	require.Equal(t, http.StatusBadRequest, e.Response.StatusCode)
+	require.JSONEq(t, `{"error":"invalid conversion","token":"[REDACTED]"}`, e.Response.Body)
+}
+
 // A kind of mock for transformer proxy endpoint in transformer
 func mockProxyHandler(timeout time.Duration, code int, response string) *chi.Mux {
 	srvMux := chi.NewRouter()
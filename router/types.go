@@ -7,6 +7,7 @@ import (
 	"time"
 
 	"github.com/rudderlabs/rudder-server/jobsdb"
+	"github.com/rudderlabs/rudder-server/router/internal/nativedelivery"
 	"github.com/rudderlabs/rudder-server/router/types"
 )
 
@@ -26,6 +27,13 @@ type JobParameters struct {
 	MessageID               string      `json:"message_id"`
 	WorkspaceID             string      `json:"workspaceId"`
 	RudderAccountID         string      `json:"rudderAccountId"`
+	OrderingKey             string      `json:"ordering_key"`
+}
+
+// nativeDelivery holds what is needed for delivering a destination's jobs directly to its API, bypassing the transformer proxy
+type nativeDelivery struct {
+	signer          nativedelivery.Signer
+	responseHandler ResponseHandler // can be nil
 }
 
 type workerJobStatus struct {
@@ -66,6 +74,7 @@ type JobResponse struct {
 	respStatusCode         int
 	respBody               string
 	errorAt                string
+	errorType              string // custom error type assigned by the destination's response rules
 	status                 *jobsdb.JobStatusT
 }
 
@@ -95,4 +104,10 @@ type reloadableConfig struct {
 	transformerProxy                        bool
 	skipRtAbortAlertForTransformation       bool // represents if event delivery(via transformerProxy) should be alerted via router-aborted-count alert def
 	skipRtAbortAlertForDelivery             bool // represents if transformation(router or batch) should be alerted via router-aborted-count alert def
+	priorityPickup                          bool // represents if jobs should be picked up with weighted fairness between their priority classes
+	priorityWeights                         struct {
+		high   int
+		normal int
+		low    int
+	}
 }
@@ -65,6 +65,7 @@ func (dj *DestinationJobT) JobIDs() map[int64]struct{} {
 // JobMetadataT holds the job metadata
 type JobMetadataT struct {
 	UserID             string          `json:"userId"`
+	OrderingKey        string          `json:"orderingKey,omitempty"`
 	JobID              int64           `json:"jobId"`
 	SourceID           string          `json:"sourceId"`
 	DestinationID      string          `json:"destinationId"`
@@ -142,4 +143,8 @@ var (
 	ErrDestinationThrottled = errors.New("throttled")
 	// ErrBarrierExists is returned when a job ordering barrier exists for the job's ordering key
 	ErrBarrierExists = errors.New("barrier")
+	// ErrDestinationCircuitOpen is returned when the destination's circuit breaker doesn't allow any more jobs to be picked up
+	ErrDestinationCircuitOpen = errors.New("circuit open")
+	// ErrDestinationPausedBySchedule is returned when the destination's delivery schedule doesn't allow delivering events at the moment
+	ErrDestinationPausedBySchedule = errors.New("paused by schedule")
 )
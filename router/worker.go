@@ -19,11 +19,14 @@ import (
 	backendconfig "github.com/rudderlabs/rudder-server/backend-config"
 	"github.com/rudderlabs/rudder-server/jobsdb"
 	"github.com/rudderlabs/rudder-server/processor/integrations"
+	"github.com/rudderlabs/rudder-server/router/internal/circuitbreaker"
 	"github.com/rudderlabs/rudder-server/router/internal/eventorder"
+	"github.com/rudderlabs/rudder-server/router/internal/nativedelivery"
 	"github.com/rudderlabs/rudder-server/router/transformer"
 	"github.com/rudderlabs/rudder-server/router/types"
 	routerutils "github.com/rudderlabs/rudder-server/router/utils"
 	"github.com/rudderlabs/rudder-server/rruntime"
+	"github.com/rudderlabs/rudder-server/services/debugger/capture"
 	destinationdebugger "github.com/rudderlabs/rudder-server/services/debugger/destination"
 	"github.com/rudderlabs/rudder-server/services/oauth"
 	"github.com/rudderlabs/rudder-server/utils/misc"
@@ -123,7 +126,7 @@ func (w *worker) workLoop() {
 			}
 
 			if w.rt.guaranteeUserEventOrder {
-				orderKey := jobOrderKey(userID, parameters.DestinationID)
+				orderKey := jobOrderKey(userID, parameters.OrderingKey, parameters.DestinationID)
 				if wait, previousFailedJobID := w.barrier.Wait(orderKey, job.JobID); wait {
 					previousFailedJobIDStr := "<nil>"
 					if previousFailedJobID != nil {
@@ -158,6 +161,7 @@ func (w *worker) workLoop() {
 			firstAttemptedAt := gjson.GetBytes(job.LastJobStatus.ErrorResponse, "firstAttemptedAt").Str
 			jobMetadata := types.JobMetadataT{
 				UserID:             userID,
+				OrderingKey:        parameters.OrderingKey,
 				JobID:              job.JobID,
 				SourceID:           parameters.SourceID,
 				DestinationID:      parameters.DestinationID,
@@ -187,7 +191,7 @@ func (w *worker) workLoop() {
 					WorkspaceId:   job.WorkspaceId,
 				}
 				if w.rt.guaranteeUserEventOrder {
-					orderKey := jobOrderKey(job.UserID, parameters.DestinationID)
+					orderKey := jobOrderKey(job.UserID, parameters.OrderingKey, parameters.DestinationID)
 					w.logger.Debugf("EventOrder: [%d] job %d for key %s failed", w.id, status.JobID, orderKey)
 					if err := w.barrier.StateChanged(orderKey, job.JobID, status.JobState); err != nil {
 						panic(err)
@@ -376,13 +380,18 @@ func (w *worker) processDestinationJobs() {
 	})
 
 	for _, destinationJob := range w.destinationJobs {
-		var errorAt string
+		var errorAt, errorType string
 		respBodyArr := make([]string, 0)
 		if destinationJob.StatusCode == 200 || destinationJob.StatusCode == 0 {
 			if w.canSendJobToDestination(prevRespStatusCode, failedJobOrderKeys, &destinationJob) {
 				diagnosisStartTime := time.Now()
 				destinationID := destinationJob.JobMetadataArray[0].DestinationID
 				transformAt := destinationJob.JobMetadataArray[0].TransformAt
+				w.rt.destinationsMapMu.RLock()
+				nativeDelivery := w.rt.nativeDeliveries[destinationID]
+				w.rt.destinationsMapMu.RUnlock()
+				// natively delivered destinations bypass the transformer proxy
+				useTransformerProxy := w.rt.reloadableConfig.transformerProxy && nativeDelivery == nil
 
 				// START: request to destination endpoint
 				workspaceID := destinationJob.JobMetadataArray[0].JobT.WorkspaceId
@@ -428,6 +437,11 @@ func (w *worker) processDestinationJobs() {
 						errorAt = routerutils.ERROR_AT_TF
 						respStatusCode, respBody = types.RouterUnMarshalErrorCode, fmt.Errorf("transformer response unmarshal error: %w", err).Error()
 					} else {
+						// capturing the http exchanges of destinations with capturing enabled
+						deliveryCtx := capture.WithRecorder(ctx, w.rt.capturer.Recorder(workspaceID, destinationID, w.rt.destType,
+							lo.Map(destinationJob.JobMetadataArray, func(m types.JobMetadataT, _ int) int64 { return m.JobID }),
+							capture.DestinationSecrets(destinationJob.Destination.DestinationDefinition.Config, destinationJob.Destination.Config),
+						))
 						for _, val := range result {
 							err := integrations.ValidatePostInfo(val)
 							if err != nil {
@@ -436,10 +450,10 @@ func (w *worker) processDestinationJobs() {
 								respBodyArr = append(respBodyArr, respBodyTemp)
 							} else {
 								// stat start
-								w.logger.Debugf(`responseTransform status :%v, %s`, w.rt.reloadableConfig.transformerProxy, w.rt.destType)
+								w.logger.Debugf(`responseTransform status :%v, %s`, useTransformerProxy, w.rt.destType)
 								// transformer proxy start
 								errorAt = routerutils.ERROR_AT_DEL
-								if w.rt.reloadableConfig.transformerProxy {
+								if useTransformerProxy {
 									jobID := destinationJob.JobMetadataArray[0].JobID
 									w.logger.Debugf(`[TransformerProxy] (Dest-%[1]v) {Job - %[2]v} Request started`, w.rt.destType, jobID)
 
@@ -462,7 +476,7 @@ func (w *worker) processDestinationJobs() {
 										},
 									}
 									rtlTime := time.Now()
-									respStatusCode, respBodyTemp, respContentType = w.rt.transformer.ProxyRequest(ctx, proxyReqparams)
+									respStatusCode, respBodyTemp, respContentType = w.rt.transformer.ProxyRequest(deliveryCtx, proxyReqparams)
 									w.routerProxyStat.SendTiming(time.Since(rtlTime))
 									w.logger.Debugf(`[TransformerProxy] (Dest-%[1]v) {Job - %[2]v} Request ended`, w.rt.destType, jobID)
 									authType := oauth.GetAuthType(destinationJob.Destination.DestinationDefinition.Config)
@@ -479,7 +493,11 @@ func (w *worker) processDestinationJobs() {
 										})
 									}
 								} else {
-									sendCtx, cancel := context.WithTimeout(ctx, w.rt.netClientTimeout)
+									sendCtx := deliveryCtx
+									if nativeDelivery != nil {
+										sendCtx = nativedelivery.WithSigner(sendCtx, nativeDelivery.signer)
+									}
+									sendCtx, cancel := context.WithTimeout(sendCtx, w.rt.netClientTimeout)
 									rdlTime := time.Now()
 									resp := w.rt.netHandle.SendPost(sendCtx, val)
 									cancel()
@@ -497,7 +515,7 @@ func (w *worker) processDestinationJobs() {
 							}
 						}
 						respBody = strings.Join(respBodyArr, " ")
-						if w.rt.reloadableConfig.transformerProxy {
+						if useTransformerProxy {
 							stats.Default.NewTaggedStat("transformer_proxy.input_events_count", stats.CountType, stats.Tags{
 								"destType":      w.rt.destType,
 								"destinationId": destinationJob.Destination.ID,
@@ -524,9 +542,16 @@ func (w *worker) processDestinationJobs() {
 				timeTaken := time.Since(startedAt)
 
 				// Using response status code and body to get response code rudder router logic is based on.
-				// Works when transformer proxy in disabled
-				if !w.rt.reloadableConfig.transformerProxy && destinationResponseHandler != nil {
-					respStatusCode = destinationResponseHandler.IsSuccessStatus(respStatusCode, respBody)
+				// Works when transformer proxy in disabled. Natively delivered destinations can override the response rules of their definition.
+				if nativeDelivery != nil && nativeDelivery.responseHandler != nil {
+					respStatusCode, errorType = classifyResponse(nativeDelivery.responseHandler, respStatusCode, respBody)
+				} else if !useTransformerProxy && destinationResponseHandler != nil {
+					respStatusCode, errorType = classifyResponse(destinationResponseHandler, respStatusCode, respBody)
+				}
+
+				// only delivery outcomes are taken into account by the circuit breaker, a rejected (4xx) request still means that the destination is reachable
+				if errorAt == routerutils.ERROR_AT_DEL {
+					w.rt.circuitBreaker.Report(destinationID, isJobTerminated(respStatusCode))
 				}
 
 				w.deliveryTimeStat.SendTiming(timeTaken)
@@ -561,7 +586,7 @@ func (w *worker) processDestinationJobs() {
 
 		if !isJobTerminated(respStatusCode) {
 			for _, metadata := range destinationJob.JobMetadataArray {
-				failedJobOrderKeys[jobOrderKey(metadata.UserID, metadata.DestinationID)] = struct{}{}
+				failedJobOrderKeys[jobOrderKey(metadata.UserID, metadata.OrderingKey, metadata.DestinationID)] = struct{}{}
 			}
 		}
 
@@ -585,6 +610,7 @@ func (w *worker) processDestinationJobs() {
 				respStatusCode:         respStatusCode,
 				respBody:               respBody,
 				errorAt:                errorAt,
+				errorType:              errorType,
 			})
 		}
 	}
@@ -614,7 +640,7 @@ func (w *worker) processDestinationJobs() {
 		routerJobResponse.status = &status
 
 		if !isJobTerminated(respStatusCode) {
-			orderKey := jobOrderKey(destinationJobMetadata.UserID, destinationJobMetadata.DestinationID)
+			orderKey := jobOrderKey(destinationJobMetadata.UserID, destinationJobMetadata.OrderingKey, destinationJobMetadata.DestinationID)
 			if prevFailedJobID, ok := jobOrderKeyToJobIDMap[orderKey]; ok {
 				// This means more than two jobs of the same user are in the batch & the batch job is failed
 				// Only one job is marked failed and the rest are marked waiting
@@ -635,6 +661,9 @@ func (w *worker) processDestinationJobs() {
 
 		status.AttemptNum++
 		status.ErrorResponse = routerutils.EnhanceJSON(routerutils.EmptyPayload, "response", routerJobResponse.respBody)
+		if routerJobResponse.errorType != "" {
+			status.ErrorResponse = routerutils.EnhanceJSON(status.ErrorResponse, "errorType", routerJobResponse.errorType)
+		}
 		status.ErrorCode = strconv.Itoa(respStatusCode)
 
 		if isJobTerminated(respStatusCode) {
@@ -692,7 +721,7 @@ func (w *worker) canSendJobToDestination(prevRespStatusCode int, failedJobOrderK
 	// If the destinationJob has come through router transform,
 	// drop the request if it is of a failed user, else send
 	for i := range destinationJob.JobMetadataArray {
-		if _, ok := failedJobOrderKeys[jobOrderKey(destinationJob.JobMetadataArray[i].UserID, destinationJob.JobMetadataArray[i].DestinationID)]; ok {
+		if _, ok := failedJobOrderKeys[jobOrderKey(destinationJob.JobMetadataArray[i].UserID, destinationJob.JobMetadataArray[i].OrderingKey, destinationJob.JobMetadataArray[i].DestinationID)]; ok {
 			return false
 		}
 	}
@@ -780,6 +809,9 @@ func (w *worker) postStatusOnResponseQ(respStatusCode int, payload json.RawMessa
 			destinationJobMetadata.JobT.Parameters = misc.UpdateJSONWithNewKeyVal(destinationJobMetadata.JobT.Parameters, "reason", status.ErrorResponse) // NOTE: Old key used was "error_response"
 		} else {
 			status.JobState = jobsdb.Failed.State
+			if state := w.rt.circuitBreaker.State(destinationJobMetadata.DestinationID); state != circuitbreaker.StateClosed {
+				status.ErrorResponse = routerutils.EnhanceJSON(status.ErrorResponse, "circuitBreaker", state.String())
+			}
 			if !w.retryLimitReached(status) { // don't delay retry time if retry limit is reached, so that the job can be aborted immediately on the next loop
 				status.RetryTime = status.ExecTime.Add(nextAttemptAfter(status.AttemptNum, w.rt.reloadableConfig.minRetryBackoff, w.rt.reloadableConfig.maxRetryBackoff))
 			}
@@ -788,7 +820,7 @@ func (w *worker) postStatusOnResponseQ(respStatusCode int, payload json.RawMessa
 		if w.rt.guaranteeUserEventOrder {
 			if status.JobState == jobsdb.Failed.State {
 
-				orderKey := jobOrderKey(destinationJobMetadata.UserID, destinationJobMetadata.DestinationID)
+				orderKey := jobOrderKey(destinationJobMetadata.UserID, destinationJobMetadata.OrderingKey, destinationJobMetadata.DestinationID)
 				w.logger.Debugf("EventOrder: [%d] job %d for key %s failed", w.id, status.JobID, orderKey)
 				if err := w.barrier.StateChanged(orderKey, destinationJobMetadata.JobID, status.JobState); err != nil {
 					panic(err)
@@ -27,6 +27,7 @@ import (
 	backendconfig "github.com/rudderlabs/rudder-server/backend-config"
 	eventschema "github.com/rudderlabs/rudder-server/event-schema"
 	"github.com/rudderlabs/rudder-server/gateway"
+	"github.com/rudderlabs/rudder-server/gateway/streamsource"
 	"github.com/rudderlabs/rudder-server/gateway/webhook"
 	"github.com/rudderlabs/rudder-server/info"
 	"github.com/rudderlabs/rudder-server/jobsdb"
@@ -347,6 +348,7 @@ func runAllInit() {
 	warehousearchiver.Init()
 	validations.Init()
 	webhook.Init()
+	streamsource.Init()
 	asyncdestinationmanager.Init()
 	batchrouterutils.Init()
 	eventschema.Init()
@@ -0,0 +1,301 @@
+// Package capture records the full http exchanges (requests and responses) between the router and destinations,
+// for debugging destination integrations.
+//
+// Capturing is opt-in per destination and time-boxed, configured through the destination's config, e.g.
+//
+//	"captureExchanges": true,
+//	"captureExchangesUntil": "2023-06-05T12:00:00Z"
+//
+// If no deadline is provided, exchanges are captured for a default duration since capturing got enabled.
+// Secrets are redacted from captured exchanges before they get stored (see [Redactor]), either into a local ring buffer or
+// into object storage (see [Store]). Captured exchanges can be replayed against a local stub using [Replay].
+package capture
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/rudderlabs/rudder-go-kit/logger"
+	"github.com/rudderlabs/rudder-go-kit/stats"
+	"github.com/rudderlabs/rudder-server/utils/misc"
+)
+
+const (
+	// ConfigKey is the key of the destination config for enabling capturing
+	ConfigKey = "captureExchanges"
+	// UntilConfigKey is the key of the destination config holding the capturing deadline, in RFC3339 format
+	UntilConfigKey = "captureExchangesUntil"
+)
+
+// Exchange is a captured http exchange
+type Exchange struct {
+	ID            string    `json:"id"`
+	WorkspaceID   string    `json:"workspaceId"`
+	DestinationID string    `json:"destinationId"`
+	DestType      string    `json:"destType"`
+	JobIDs        []int64   `json:"jobIds"`
+	CapturedAt    time.Time `json:"capturedAt"`
+	DurationMs    int64     `json:"durationMs"`
+	Request       Request   `json:"request"`
+	Response      *Response `json:"response,omitempty"`
+	Error         string    `json:"error,omitempty"`
+}
+
+// Request is a captured http request
+type Request struct {
+	Method string      `json:"method"`
+	URL    string      `json:"url"`
+	Header http.Header `json:"header"`
+	Body   string      `json:"body"`
+}
+
+// Response is a captured http response
+type Response struct {
+	StatusCode int         `json:"statusCode"`
+	Header     http.Header `json:"header"`
+	Body       string      `json:"body"`
+}
+
+// FromDestinationConfig returns whether capturing is enabled in the destination's config along with the capturing
+// deadline, which is zero if the destination's config doesn't specify one
+func FromDestinationConfig(destConfig map[string]interface{}) (enabled bool, until time.Time, err error) {
+	if enabled, _ = destConfig[ConfigKey].(bool); !enabled {
+		return false, time.Time{}, nil
+	}
+	if v, ok := destConfig[UntilConfigKey].(string); ok && v != "" {
+		if until, err = time.Parse(time.RFC3339, v); err != nil {
+			return false, time.Time{}, fmt.Errorf("parsing %s: %w", UntilConfigKey, err)
+		}
+	}
+	return true, until, nil
+}
+
+type OptFn func(*Capturer)
+
+// WithDefaultDuration sets the duration for which exchanges are captured, for destinations without a capturing deadline
+func WithDefaultDuration(d time.Duration) OptFn {
+	return func(c *Capturer) {
+		c.defaultDuration = d
+	}
+}
+
+// WithQueueSize sets the number of captured exchanges which can be waiting for getting stored. Exchanges exceeding it are dropped.
+func WithQueueSize(size int) OptFn {
+	return func(c *Capturer) {
+		c.queueSize = size
+	}
+}
+
+// WithRedactor sets the redactor for captured exchanges
+func WithRedactor(r *Redactor) OptFn {
+	return func(c *Capturer) {
+		c.redactor = r
+	}
+}
+
+// WithNow sets the function for getting the current time
+func WithNow(now func() time.Time) OptFn {
+	return func(c *Capturer) {
+		c.now = now
+	}
+}
+
+// NewCapturer creates a new capturer storing captured exchanges into the provided store
+func NewCapturer(store Store, log logger.Logger, fns ...OptFn) *Capturer {
+	c := &Capturer{
+		store:           store,
+		log:             log,
+		defaultDuration: 30 * time.Minute,
+		queueSize:       1000,
+		redactor:        NewRedactor(),
+		now:             time.Now,
+		deadlines:       map[string]time.Time{},
+	}
+	for _, fn := range fns {
+		fn(c)
+	}
+	c.queue = make(chan *Exchange, c.queueSize)
+	return c
+}
+
+// Capturer captures the http exchanges of destinations with capturing enabled
+type Capturer struct {
+	store           Store
+	log             logger.Logger
+	defaultDuration time.Duration
+	queueSize       int
+	redactor        *Redactor
+	now             func() time.Time
+
+	mu        sync.RWMutex
+	deadlines map[string]time.Time // destinationID -> capturing deadline
+	queue     chan *Exchange
+}
+
+// SetDestinations sets the destinations with capturing enabled, along with their capturing deadlines.
+// Destinations with a zero deadline keep their current deadline, or get a new one after the default duration.
+func (c *Capturer) SetDestinations(deadlines map[string]time.Time) {
+	if c == nil {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	now := c.now()
+	newDeadlines := make(map[string]time.Time, len(deadlines))
+	for destinationID, until := range deadlines {
+		if until.IsZero() {
+			var ok bool
+			if until, ok = c.deadlines[destinationID]; !ok {
+				until = now.Add(c.defaultDuration)
+			}
+		}
+		if _, ok := c.deadlines[destinationID]; !ok && until.After(now) {
+			c.log.Infof("Capturing http exchanges of destination %s until %s", destinationID, until.Format(time.RFC3339))
+		}
+		newDeadlines[destinationID] = until
+	}
+	c.deadlines = newDeadlines
+}
+
+// Recorder returns a recorder for the exchanges of a destination, or nil if the destination isn't being captured at the moment.
+// The destination's secrets (see [DestinationSecrets]) get redacted from its exchanges, along with any sensitive fields.
+func (c *Capturer) Recorder(workspaceID, destinationID, destType string, jobIDs []int64, secrets Secrets) *Recorder {
+	if c == nil {
+		return nil
+	}
+	c.mu.RLock()
+	until, ok := c.deadlines[destinationID]
+	c.mu.RUnlock()
+	if !ok || !c.now().Before(until) {
+		return nil
+	}
+	return &Recorder{
+		capturer:      c,
+		workspaceID:   workspaceID,
+		destinationID: destinationID,
+		destType:      destType,
+		jobIDs:        jobIDs,
+		secrets:       secrets,
+	}
+}
+
+// Run stores captured exchanges until the context gets cancelled
+func (c *Capturer) Run(ctx context.Context) {
+	if c == nil {
+		return
+	}
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case e := <-c.queue:
+			if err := c.store.Store(ctx, e); err != nil && ctx.Err() == nil {
+				c.log.Errorf("Storing captured http exchange of destination %s: %v", e.DestinationID, err)
+				c.stat(e, "failed")
+				continue
+			}
+			c.stat(e, "stored")
+		}
+	}
+}
+
+func (c *Capturer) enqueue(e *Exchange) {
+	select {
+	case c.queue <- e:
+	default:
+		c.stat(e, "dropped")
+	}
+}
+
+func (*Capturer) stat(e *Exchange, status string) {
+	stats.Default.NewTaggedStat("destination_captured_exchanges", stats.CountType, stats.Tags{
+		"destType": e.DestType,
+		"destId":   e.DestinationID,
+		"status":   status,
+	}).Increment()
+}
+
+// Recorder records the http exchanges of a destination
+type Recorder struct {
+	capturer      *Capturer
+	workspaceID   string
+	destinationID string
+	destType      string
+	jobIDs        []int64
+	secrets       Secrets
+}
+
+// record captures an http exchange. The request's body is read using [http.Request.GetBody], while the response can be nil if the request failed.
+func (r *Recorder) record(req *http.Request, resp *http.Response, respBody []byte, err error, duration time.Duration) {
+	request := Request{
+		Method: req.Method,
+		URL:    req.URL.String(),
+		Header: req.Header.Clone(),
+	}
+	if req.GetBody != nil {
+		if rc, err := req.GetBody(); err == nil {
+			body, _ := io.ReadAll(rc)
+			_ = rc.Close()
+			request.Body = string(body)
+		}
+	}
+	var response *Response
+	if resp != nil {
+		response = &Response{
+			StatusCode: resp.StatusCode,
+			Header:     resp.Header.Clone(),
+			Body:       string(respBody),
+		}
+	}
+	r.recordExchange(request, response, err, duration)
+}
+
+func (r *Recorder) recordExchange(req Request, resp *Response, err error, duration time.Duration) {
+	e := &Exchange{
+		ID:            misc.FastUUID().String(),
+		WorkspaceID:   r.workspaceID,
+		DestinationID: r.destinationID,
+		DestType:      r.destType,
+		JobIDs:        r.jobIDs,
+		CapturedAt:    r.capturer.now().UTC(),
+		DurationMs:    duration.Milliseconds(),
+		Request:       req,
+		Response:      resp,
+	}
+	if err != nil {
+		e.Error = err.Error()
+	}
+	r.capturer.redactor.Redact(e, r.secrets)
+	r.capturer.enqueue(e)
+}
+
+type recorderKey struct{}
+
+// WithRecorder returns a new context carrying the provided recorder
+func WithRecorder(ctx context.Context, r *Recorder) context.Context {
+	if r == nil {
+		return ctx
+	}
+	return context.WithValue(ctx, recorderKey{}, r)
+}
+
+// Record captures an http exchange, if the context carries a recorder (see [WithRecorder]).
+// The response can be nil if the request failed.
+func Record(ctx context.Context, req *http.Request, resp *http.Response, respBody []byte, err error, duration time.Duration) {
+	if r, ok := ctx.Value(recorderKey{}).(*Recorder); ok {
+		r.record(req, resp, respBody, err, duration)
+	}
+}
+
+// RecordExchange captures an already assembled http exchange, if the context carries a recorder (see [WithRecorder]).
+// It is meant for deliveries not performed by rudder-server itself, e.g. the ones performed through the transformer proxy.
+// The response can be nil if the request failed.
+func RecordExchange(ctx context.Context, req Request, resp *Response, err error, duration time.Duration) {
+	if r, ok := ctx.Value(recorderKey{}).(*Recorder); ok {
+		r.recordExchange(req, resp, err, duration)
+	}
+}
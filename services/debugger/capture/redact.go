@@ -0,0 +1,202 @@
+package capture
+
+import (
+	"encoding/json"
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+// Redacted is the value replacing redacted secrets
+const Redacted = "[REDACTED]"
+
+// defaultSensitiveKeys matches the names of headers, query parameters and body fields which are considered secrets
+var defaultSensitiveKeys = regexp.MustCompile(`(?i)(passw(or)?d|secret|token|api[-_]?key|write[-_]?key|client[-_]?key|account[-_]?sid|authorization|credential|signature|private[-_]?key|cookie)`)
+
+// minSecretValueLength is the minimum length of secret values getting redacted wherever they appear, so that short values don't mangle whole exchanges
+const minSecretValueLength = 4
+
+// Secrets are the secret fields of a destination's config, i.e. their values keyed by field name
+type Secrets map[string]string
+
+// DestinationSecrets returns the secrets of a destination, i.e. the fields of the destination's config which are listed
+// under secretKeys in its destination definition's config
+func DestinationSecrets(destDefConfig, destConfig map[string]interface{}) Secrets {
+	keys, _ := destDefConfig["secretKeys"].([]interface{})
+	secrets := make(Secrets, len(keys))
+	for _, key := range keys {
+		name, ok := key.(string)
+		if !ok || name == "" {
+			continue
+		}
+		value, _ := destConfig[name].(string)
+		secrets[name] = value
+	}
+	return secrets
+}
+
+type RedactorOptFn func(*Redactor)
+
+// WithSensitiveKeys sets the regular expression matching the names of headers, query parameters and body fields to be redacted
+func WithSensitiveKeys(re *regexp.Regexp) RedactorOptFn {
+	return func(r *Redactor) {
+		r.sensitiveKeys = re
+	}
+}
+
+// WithMaxBodySize sets the maximum size of captured bodies, larger bodies are truncated. A non positive value disables truncation.
+func WithMaxBodySize(size int) RedactorOptFn {
+	return func(r *Redactor) {
+		r.maxBodySize = size
+	}
+}
+
+// NewRedactor creates a new redactor
+func NewRedactor(fns ...RedactorOptFn) *Redactor {
+	r := &Redactor{
+		sensitiveKeys: defaultSensitiveKeys,
+		maxBodySize:   64 * 1024,
+	}
+	for _, fn := range fns {
+		fn(r)
+	}
+	return r
+}
+
+// Redactor redacts secrets from captured exchanges, i.e. the values of headers, query parameters and json or form body fields
+// with sensitive names, along with any password found in urls. The destination's secrets are redacted both by name and by value.
+type Redactor struct {
+	sensitiveKeys *regexp.Regexp
+	maxBodySize   int
+}
+
+// Redact redacts the exchange in place, along with the provided destination secrets
+func (r *Redactor) Redact(e *Exchange, secrets Secrets) {
+	sr := &secretsRedactor{Redactor: r, secrets: secrets}
+	e.Request.URL = sr.redactURL(e.Request.URL)
+	sr.redactHeader(e.Request.Header)
+	e.Request.Body = sr.redactBody(e.Request.Body, e.Request.Header.Get("Content-Type"))
+	if e.Response != nil {
+		sr.redactHeader(e.Response.Header)
+		e.Response.Body = sr.redactBody(e.Response.Body, e.Response.Header.Get("Content-Type"))
+	}
+}
+
+// secretsRedactor redacts an exchange using the redactor's sensitive keys along with a destination's secrets
+type secretsRedactor struct {
+	*Redactor
+	secrets Secrets
+}
+
+func (r *secretsRedactor) sensitive(key string) bool {
+	if r.sensitiveKeys.MatchString(key) {
+		return true
+	}
+	for name := range r.secrets {
+		if strings.EqualFold(name, key) {
+			return true
+		}
+	}
+	return false
+}
+
+// redactSecretValues replaces any occurrence of the destination's secret values, e.g. secrets embedded in urls or in request bodies
+func (r *secretsRedactor) redactSecretValues(s string) string {
+	for _, value := range r.secrets {
+		if len(value) >= minSecretValueLength {
+			s = strings.ReplaceAll(s, value, Redacted)
+		}
+	}
+	return s
+}
+
+func (r *secretsRedactor) redactHeader(header map[string][]string) {
+	for key, values := range header {
+		for i := range values {
+			if r.sensitive(key) {
+				values[i] = Redacted
+				continue
+			}
+			values[i] = r.redactSecretValues(values[i])
+		}
+	}
+}
+
+func (r *secretsRedactor) redactURL(rawURL string) string {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return r.redactSecretValues(rawURL)
+	}
+	if _, ok := u.User.Password(); ok {
+		u.User = url.UserPassword(u.User.Username(), Redacted)
+	}
+	if u.RawQuery != "" {
+		query := u.Query()
+		r.redactValues(query)
+		u.RawQuery = query.Encode()
+	}
+	u.Path = r.redactSecretValues(u.Path)
+	u.RawPath = ""
+	return u.String()
+}
+
+func (r *secretsRedactor) redactValues(values url.Values) {
+	for key, vs := range values {
+		for i := range vs {
+			if r.sensitive(key) {
+				vs[i] = Redacted
+				continue
+			}
+			vs[i] = r.redactSecretValues(vs[i])
+		}
+	}
+}
+
+func (r *secretsRedactor) redactBody(body, contentType string) string {
+	if body == "" {
+		return body
+	}
+	trimmed := strings.TrimSpace(body)
+	switch {
+	case strings.HasPrefix(trimmed, "{") || strings.HasPrefix(trimmed, "["):
+		var v interface{}
+		if err := json.Unmarshal([]byte(trimmed), &v); err == nil {
+			if redacted, err := json.Marshal(r.redactJSON(v)); err == nil {
+				body = string(redacted)
+			}
+		}
+	case strings.HasPrefix(contentType, "application/x-www-form-urlencoded"):
+		if values, err := url.ParseQuery(body); err == nil {
+			r.redactValues(values)
+			body = values.Encode()
+		}
+	}
+	body = r.redactSecretValues(body)
+	if r.maxBodySize > 0 && len(body) > r.maxBodySize {
+		body = body[:r.maxBodySize] + "...[TRUNCATED]"
+	}
+	return body
+}
+
+func (r *secretsRedactor) redactJSON(v interface{}) interface{} {
+	switch v := v.(type) {
+	case map[string]interface{}:
+		for key, value := range v {
+			if r.sensitive(key) {
+				v[key] = Redacted
+				continue
+			}
+			v[key] = r.redactJSON(value)
+		}
+		return v
+	case []interface{}:
+		for i := range v {
+			v[i] = r.redactJSON(v[i])
+		}
+		return v
+	case string:
+		return r.redactSecretValues(v)
+	default:
+		return v
+	}
+}
@@ -0,0 +1,49 @@
+package capture
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// Replay sends the captured request of an exchange to the target (e.g. http://localhost:8083), keeping the request's path and query.
+// Redacted secrets are sent as is, so the target is expected to be a stub rather than the actual destination.
+func Replay(ctx context.Context, client *http.Client, e *Exchange, target string) (*http.Response, []byte, error) {
+	targetURL, err := url.Parse(target)
+	if err != nil {
+		return nil, nil, fmt.Errorf("parsing target url: %w", err)
+	}
+	reqURL, err := url.Parse(e.Request.URL)
+	if err != nil {
+		return nil, nil, fmt.Errorf("parsing request url: %w", err)
+	}
+	reqURL.Scheme, reqURL.Host, reqURL.User = targetURL.Scheme, targetURL.Host, nil
+	reqURL.Path = strings.TrimSuffix(targetURL.Path, "/") + reqURL.Path
+
+	method := e.Request.Method
+	if method == "" {
+		method = http.MethodPost
+	}
+	req, err := http.NewRequestWithContext(ctx, method, reqURL.String(), strings.NewReader(e.Request.Body))
+	if err != nil {
+		return nil, nil, fmt.Errorf("creating request: %w", err)
+	}
+	for key, values := range e.Request.Header {
+		for _, value := range values {
+			req.Header.Add(key, value)
+		}
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, nil, fmt.Errorf("sending request: %w", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, nil, fmt.Errorf("reading response body: %w", err)
+	}
+	return resp, body, nil
+}
@@ -0,0 +1,169 @@
+package capture
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/rudderlabs/rudder-go-kit/filemanager"
+)
+
+// Store stores captured exchanges
+type Store interface {
+	Store(ctx context.Context, e *Exchange) error
+}
+
+// NewLocalStore creates a store keeping the latest captured exchanges of every destination as json files in a local directory,
+// i.e. <dir>/<destinationID>/<capturedAt>-<id>.json. Every destination's directory acts as a ring buffer, holding up to capacity exchanges.
+func NewLocalStore(dir string, capacity int) (*LocalStore, error) {
+	if capacity <= 0 {
+		return nil, fmt.Errorf("invalid capacity: %d", capacity)
+	}
+	if err := os.MkdirAll(dir, os.ModePerm); err != nil {
+		return nil, fmt.Errorf("creating directory %q: %w", dir, err)
+	}
+	return &LocalStore{dir: dir, capacity: capacity, files: map[string][]string{}}, nil
+}
+
+// LocalStore is a ring buffer of captured exchanges in a local directory
+type LocalStore struct {
+	dir      string
+	capacity int
+
+	mu    sync.Mutex
+	files map[string][]string // destinationID -> exchange files, oldest first
+}
+
+// Store stores the exchange, removing the oldest exchange of the destination if its capacity is exceeded
+func (s *LocalStore) Store(_ context.Context, e *Exchange) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	files, err := s.list(e.DestinationID)
+	if err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(e, "", "  ")
+	if err != nil {
+		return fmt.Errorf("marshalling exchange: %w", err)
+	}
+	file := filepath.Join(s.dir, e.DestinationID, fileName(e))
+	if err := os.WriteFile(file, data, 0o600); err != nil {
+		return fmt.Errorf("writing exchange file: %w", err)
+	}
+	files = append(files, file)
+	for len(files) > s.capacity {
+		if err := os.Remove(files[0]); err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("removing oldest exchange file: %w", err)
+		}
+		files = files[1:]
+	}
+	s.files[e.DestinationID] = files
+	return nil
+}
+
+// List returns the files of the destination's captured exchanges, oldest first
+func (s *LocalStore) List(destinationID string) ([]string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	files, err := s.list(destinationID)
+	return append([]string(nil), files...), err
+}
+
+func (s *LocalStore) list(destinationID string) ([]string, error) {
+	if files, ok := s.files[destinationID]; ok {
+		return files, nil
+	}
+	destDir := filepath.Join(s.dir, destinationID)
+	if err := os.MkdirAll(destDir, os.ModePerm); err != nil {
+		return nil, fmt.Errorf("creating directory %q: %w", destDir, err)
+	}
+	files, err := ListFiles(destDir)
+	if err != nil {
+		return nil, err
+	}
+	s.files[destinationID] = files
+	return files, nil
+}
+
+// ListFiles returns the exchange files found in a directory, oldest first
+func ListFiles(dir string) ([]string, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("reading directory %q: %w", dir, err)
+	}
+	var files []string
+	for _, entry := range entries {
+		if !entry.IsDir() && strings.HasSuffix(entry.Name(), ".json") {
+			files = append(files, filepath.Join(dir, entry.Name()))
+		}
+	}
+	sort.Strings(files) // file names start with the capture time
+	return files, nil
+}
+
+// NewObjectStorageStore creates a store uploading captured exchanges as json files to object storage,
+// under <prefix>/<destinationID>/<capturedAt>-<id>.json
+func NewObjectStorageStore(fm filemanager.FileManager, prefix string) *ObjectStorageStore {
+	return &ObjectStorageStore{fm: fm, prefix: prefix}
+}
+
+// ObjectStorageStore stores captured exchanges into object storage
+type ObjectStorageStore struct {
+	fm     filemanager.FileManager
+	prefix string
+}
+
+// Store uploads the exchange to object storage
+func (s *ObjectStorageStore) Store(ctx context.Context, e *Exchange) error {
+	tmpDir, err := os.MkdirTemp("", "rudder-capture")
+	if err != nil {
+		return fmt.Errorf("creating temporary directory: %w", err)
+	}
+	defer func() { _ = os.RemoveAll(tmpDir) }()
+
+	data, err := json.Marshal(e)
+	if err != nil {
+		return fmt.Errorf("marshalling exchange: %w", err)
+	}
+	file := filepath.Join(tmpDir, fileName(e))
+	if err := os.WriteFile(file, data, 0o600); err != nil {
+		return fmt.Errorf("writing exchange file: %w", err)
+	}
+	f, err := os.Open(file)
+	if err != nil {
+		return fmt.Errorf("opening exchange file: %w", err)
+	}
+	defer func() { _ = f.Close() }()
+	if _, err := s.fm.Upload(ctx, f, s.prefix, e.DestinationID); err != nil {
+		return fmt.Errorf("uploading exchange file: %w", err)
+	}
+	return nil
+}
+
+// ReadExchange reads a captured exchange from a json file
+func ReadExchange(file string) (*Exchange, error) {
+	f, err := os.Open(file)
+	if err != nil {
+		return nil, fmt.Errorf("opening exchange file: %w", err)
+	}
+	defer func() { _ = f.Close() }()
+	data, err := io.ReadAll(f)
+	if err != nil {
+		return nil, fmt.Errorf("reading exchange file: %w", err)
+	}
+	var e Exchange
+	if err := json.Unmarshal(data, &e); err != nil {
+		return nil, fmt.Errorf("unmarshalling exchange: %w", err)
+	}
+	return &e, nil
+}
+
+func fileName(e *Exchange) string {
+	return fmt.Sprintf("%020d-%s.json", e.CapturedAt.UnixNano(), e.ID)
+}
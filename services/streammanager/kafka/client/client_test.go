@@ -329,6 +329,63 @@ func TestConsumer_Partition(t *testing.T) {
 	t.Logf("Messages consumed by c02: %d", atomic.LoadInt32(&c02Count))
 }
 
+func TestConsumer_FetchAndCommit(t *testing.T) {
+	pool, err := dockertest.NewPool("")
+	require.NoError(t, err)
+
+	kafkaContainer, err := dockerKafka.Setup(pool, &testCleanup{t},
+		dockerKafka.WithLogger(t),
+		dockerKafka.WithBrokers(1))
+	require.NoError(t, err)
+
+	kafkaHost := fmt.Sprintf("localhost:%s", kafkaContainer.Ports[0])
+	c, err := New("tcp", []string{kafkaHost}, Config{ClientID: "some-client", DialTimeout: 5 * time.Second})
+	require.NoError(t, err)
+
+	var (
+		ctx, cancel = context.WithTimeout(context.Background(), 2*time.Minute)
+		tc          = testutil.NewWithDialer(c.dialer, c.network, c.addresses...)
+	)
+	t.Cleanup(cancel)
+
+	require.NoError(t, c.Ping(ctx))
+	require.Eventually(t, func() bool {
+		err := tc.CreateTopic(ctx, t.Name(), 1, 1) // partitions = 1, replication factor = 1
+		if err != nil {
+			t.Logf("Could not create topic: %v", err)
+		}
+		return err == nil
+	}, defaultTestTimeout, time.Second)
+
+	p, err := c.NewProducer(ProducerConfig{ClientID: "producer-01"})
+	require.NoError(t, err)
+	publishMessages(ctx, t, p, 2)
+
+	consumerConf := ConsumerConfig{GroupID: "group-01", StartOffset: FirstOffset}
+	fetch := func(c *Consumer) Message {
+		fetchCtx, fetchCancel := context.WithTimeout(ctx, 30*time.Second)
+		defer fetchCancel()
+		msg, err := c.Fetch(fetchCtx)
+		require.NoError(t, err)
+		return msg
+	}
+
+	// Only the first message is committed, the second one should be fetched again by the next consumer of the group
+	c01 := c.NewConsumer(t.Name(), consumerConf)
+	msg := fetch(c01)
+	require.Equal(t, "key-0", string(msg.Key))
+	require.NoError(t, c01.Commit(ctx, msg))
+	msg = fetch(c01)
+	require.Equal(t, "key-1", string(msg.Key))
+	require.NoError(t, c01.Close(ctx))
+
+	c02 := c.NewConsumer(t.Name(), consumerConf)
+	t.Cleanup(func() { _ = c02.Close(context.Background()) })
+	msg = fetch(c02)
+	require.Equal(t, "key-1", string(msg.Key))
+	require.Equal(t, "value-1", string(msg.Value))
+}
+
 func TestWithSASL(t *testing.T) {
 	// Prepare cluster - Zookeeper and one Kafka broker
 	path, err := os.Getwd()
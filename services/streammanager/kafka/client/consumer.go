@@ -81,7 +81,33 @@ func (c *Consumer) Receive(ctx context.Context) (Message, error) {
 	if err != nil {
 		return Message{}, err
 	}
+	return newMessage(msg), nil
+}
+
+// Fetch reads and returns the next message from the consumer, without committing its offset.
+// When consuming as part of a group, the offsets have to be committed explicitly via Commit.
+func (c *Consumer) Fetch(ctx context.Context) (Message, error) {
+	msg, err := c.reader.FetchMessage(ctx)
+	if err != nil {
+		return Message{}, err
+	}
+	return newMessage(msg), nil
+}
 
+// Commit commits the offsets of the given messages, which is only supported when consuming as part of a group.
+func (c *Consumer) Commit(ctx context.Context, msgs ...Message) error {
+	kafkaMessages := make([]kafka.Message, len(msgs))
+	for i := range msgs {
+		kafkaMessages[i] = kafka.Message{
+			Topic:     msgs[i].Topic,
+			Partition: int(msgs[i].Partition),
+			Offset:    msgs[i].Offset,
+		}
+	}
+	return c.reader.CommitMessages(ctx, kafkaMessages...)
+}
+
+func newMessage(msg kafka.Message) Message {
 	var headers []MessageHeader
 	if l := len(msg.Headers); l > 0 {
 		headers = make([]MessageHeader, l)
@@ -101,5 +127,5 @@ func (c *Consumer) Receive(ctx context.Context) (Message, error) {
 		Offset:    msg.Offset,
 		Headers:   headers,
 		Timestamp: msg.Time,
-	}, nil
+	}
 }
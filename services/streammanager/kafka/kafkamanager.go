@@ -24,6 +24,7 @@ import (
 	"github.com/rudderlabs/rudder-server/services/controlplane/identity"
 	"github.com/rudderlabs/rudder-server/services/streammanager/common"
 	"github.com/rudderlabs/rudder-server/services/streammanager/kafka/client"
+	"github.com/rudderlabs/rudder-server/services/streammanager/kafka/schemaregistry"
 )
 
 // schema is the AVRO schema required to convert the data to AVRO
@@ -32,6 +33,11 @@ type avroSchema struct {
 	Schema   string
 }
 
+// registrySchema is a local schema of the Schema Registry, used for auto registration
+type registrySchema struct {
+	Schema string
+}
+
 // configuration is the config that is required to send data to Kafka
 type configuration struct {
 	Topic    string
@@ -49,6 +55,15 @@ type configuration struct {
 	EmbedAvroSchemaID bool
 	AvroSchemas       []avroSchema
 
+	UseSchemaRegistry      bool
+	SchemaRegistryURL      string
+	SchemaRegistryUsername string
+	SchemaRegistryPassword string
+	SchemaFormat           string
+	SubjectNameStrategy    string
+	AutoRegisterSchemas    bool
+	RegistrySchemas        []registrySchema
+
 	UseSSH  bool
 	SSHHost string
 	SSHPort string
@@ -76,6 +91,14 @@ func (c *configuration) validate() error {
 			return fmt.Errorf("invalid ssh port: %w", err)
 		}
 	}
+	if c.UseSchemaRegistry {
+		if c.SchemaRegistryURL == "" {
+			return fmt.Errorf("schema registry url cannot be empty")
+		}
+		if c.ConvertToAvro {
+			return fmt.Errorf("avro conversion with inline schemas cannot be used along with the schema registry")
+		}
+	}
 	return nil
 }
 
@@ -131,12 +154,17 @@ type publisher interface {
 	Publish(context.Context, ...client.Message) error
 }
 
+type serializer interface {
+	Serialize(schemaregistry.Record) ([]byte, error)
+}
+
 type producerManager interface {
 	io.Closer
 	publisher
 	getTimeout() time.Duration
 	getEmbedAvroSchemaID() bool
 	getCodecs() map[string]*goavro.Codec
+	getSerializer() serializer
 }
 
 type internalProducer interface {
@@ -149,6 +177,7 @@ type ProducerManager struct {
 	timeout           time.Duration
 	embedAvroSchemaID bool
 	codecs            map[string]*goavro.Codec
+	serializer        serializer
 }
 
 func (p *ProducerManager) getTimeout() time.Duration {
@@ -160,6 +189,7 @@ func (p *ProducerManager) getTimeout() time.Duration {
 
 func (p *ProducerManager) getCodecs() map[string]*goavro.Codec { return p.codecs }
 func (p *ProducerManager) getEmbedAvroSchemaID() bool          { return p.embedAvroSchemaID }
+func (p *ProducerManager) getSerializer() serializer           { return p.serializer }
 
 type logger interface {
 	Error(args ...interface{})
@@ -179,6 +209,7 @@ type managerStats struct {
 	closeProducerTime          stats.Measurement
 	jsonSerializationMsgErr    stats.Measurement
 	avroSerializationErr       stats.Measurement
+	registrySerializationErr   stats.Measurement
 	batchSize                  stats.Measurement
 }
 
@@ -200,6 +231,8 @@ var (
 	kafkaBatchingEnabled                 bool
 	kafkaCompression                     client.Compression
 	allowReqsWithoutUserIDAndAnonymousID bool
+	schemaRegistryCacheTTL               time.Duration
+	schemaRegistryTimeout                time.Duration
 
 	kafkaStats managerStats
 	pkgLogger  logger
@@ -244,6 +277,8 @@ func Init() {
 	config.RegisterBoolConfigVariable(
 		false, &allowReqsWithoutUserIDAndAnonymousID, true, "Gateway.allowReqsWithoutUserIDAndAnonymousID",
 	)
+	config.RegisterDurationConfigVariable(5, &schemaRegistryCacheTTL, true, time.Minute, "Router.kafkaSchemaRegistryCacheTTL")
+	config.RegisterDurationConfigVariable(10, &schemaRegistryTimeout, false, time.Second, "Router.kafkaSchemaRegistryTimeout")
 
 	pkgLogger = rslogger.NewLogger().Child("streammanager").Child("kafka")
 
@@ -280,6 +315,7 @@ func Init() {
 		closeProducerTime:          stats.Default.NewStat("router.kafka.close_producer_time", stats.TimerType),
 		jsonSerializationMsgErr:    stats.Default.NewStat("router.kafka.json_serialization_msg_err", stats.CountType),
 		avroSerializationErr:       stats.Default.NewStat("router.kafka.avro_serialization_err", stats.CountType),
+		registrySerializationErr:   stats.Default.NewStat("router.kafka.schema_registry_serialization_err", stats.CountType),
 		batchSize:                  stats.Default.NewStat("router.kafka.batch_size", stats.HistogramType),
 	}
 }
@@ -325,6 +361,28 @@ func NewProducer(destination *backendconfig.DestinationT, o common.Opts) (*Produ
 		}
 	}
 
+	var registrySerializer serializer
+	if destConfig.UseSchemaRegistry {
+		schemas := make([]string, 0, len(destConfig.RegistrySchemas))
+		for _, s := range destConfig.RegistrySchemas {
+			schemas = append(schemas, s.Schema)
+		}
+		registrySerializer, err = schemaregistry.New(schemaregistry.Config{
+			URL:                 destConfig.SchemaRegistryURL,
+			Username:            destConfig.SchemaRegistryUsername,
+			Password:            destConfig.SchemaRegistryPassword,
+			Format:              schemaregistry.Format(destConfig.SchemaFormat),
+			SubjectNameStrategy: schemaregistry.SubjectNameStrategy(destConfig.SubjectNameStrategy),
+			AutoRegister:        destConfig.AutoRegisterSchemas,
+			Schemas:             schemas,
+			CacheTTL:            schemaRegistryCacheTTL,
+			RequestTimeout:      schemaRegistryTimeout,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("[Kafka] invalid schema registry configuration: %w", err)
+		}
+	}
+
 	// TODO: once the latest control-plane changes are in production we can safely remove this
 	sshConfig, err := getSSHConfig(destination.ID, config.Default)
 	if err != nil {
@@ -400,6 +458,7 @@ func NewProducer(destination *backendconfig.DestinationT, o common.Opts) (*Produ
 		timeout:           o.Timeout,
 		embedAvroSchemaID: embedAvroSchemaID,
 		codecs:            codecs,
+		serializer:        registrySerializer,
 	}, nil
 }
 
@@ -561,6 +620,28 @@ func addAvroSchemaIDHeader(schemaID string, msgBytes []byte) (header []byte, err
 	return buf.Bytes(), nil
 }
 
+// serializeRegistryMessage serializes the message with the schema registry, using either the registry schema ID
+// or the record name set in the event, if any
+func serializeRegistryMessage(s serializer, topic string, schemaID, recordName interface{}, value []byte) ([]byte, error) {
+	record := schemaregistry.Record{Topic: topic, Value: value}
+	switch id := schemaID.(type) {
+	case nil:
+	case float64:
+		record.SchemaID = int(id)
+	case string:
+		if id != "" {
+			var err error
+			if record.SchemaID, err = strconv.Atoi(id); err != nil {
+				return nil, fmt.Errorf("invalid schemaId %q: %w", id, err)
+			}
+		}
+	default:
+		return nil, fmt.Errorf("invalid schemaId %v", schemaID)
+	}
+	record.RecordName, _ = recordName.(string)
+	return s.Serialize(record)
+}
+
 func prepareBatchOfMessages(batch []map[string]interface{}, timestamp time.Time, p producerManager, defaultTopic string) (
 	[]client.Message, error,
 ) {
@@ -592,6 +673,18 @@ func prepareBatchOfMessages(batch []map[string]interface{}, timestamp time.Time,
 			pkgLogger.Errorf("unable to marshal message at index %d", i)
 			continue
 		}
+		if s := p.getSerializer(); s != nil {
+			marshalledMsg, err = serializeRegistryMessage(s, topic, data["schemaId"], data["recordName"], marshalledMsg)
+			if err != nil {
+				kafkaStats.registrySerializationErr.Increment()
+				if schemaregistry.IsTemporary(err) {
+					// failing the whole batch so that it gets retried, instead of dropping the event
+					return nil, fmt.Errorf("unable to serialize the event at index %d with the schema registry: %w", i, err)
+				}
+				pkgLogger.Errorf("unable to serialize the event at index %d with the schema registry: %s", i, err)
+				continue
+			}
+		}
 		codecs := p.getCodecs()
 		if len(codecs) > 0 {
 			schemaId, _ := data["schemaId"].(string)
@@ -688,7 +781,7 @@ func sendBatchedMessage(ctx context.Context, jsonData json.RawMessage, p produce
 	timestamp := time.Now()
 	batchOfMessages, err := prepareBatchOfMessages(batch, timestamp, p, defaultTopic)
 	if err != nil {
-		return 400, "Failure", "Error while preparing batched message: " + err.Error()
+		return getStatusCodeFromError(err), "Failure", "Error while preparing batched message: " + err.Error()
 	}
 
 	err = publish(ctx, p, batchOfMessages...)
@@ -716,6 +809,23 @@ func sendMessage(ctx context.Context, jsonData json.RawMessage, p producerManage
 
 	timestamp := time.Now()
 	userID := parsedJSON.Get("userId").String()
+	topic := parsedJSON.Get("topic").String()
+
+	if topic == "" {
+		topic = defaultTopic
+	}
+
+	if s := p.getSerializer(); s != nil {
+		value, err = serializeRegistryMessage(
+			s, topic, parsedJSON.Get("schemaId").Value(), parsedJSON.Get("recordName").Value(), value,
+		)
+		if err != nil {
+			return makeErrorResponse(fmt.Errorf(
+				"unable to serialize event with messageId %s with the schema registry: %w",
+				parsedJSON.Get("message.messageId").String(), err,
+			))
+		}
+	}
 	codecs := p.getCodecs()
 	if len(codecs) > 0 {
 		schemaId := parsedJSON.Get("schemaId").String()
@@ -736,12 +846,6 @@ func sendMessage(ctx context.Context, jsonData json.RawMessage, p producerManage
 		}
 	}
 
-	topic := parsedJSON.Get("topic").String()
-
-	if topic == "" {
-		topic = defaultTopic
-	}
-
 	message := prepareMessage(topic, userID, value, timestamp)
 
 	if err = publish(ctx, p, message); err != nil {
@@ -766,7 +870,7 @@ func makeErrorResponse(err error) (int, string, string) {
 
 // getStatusCodeFromError parses the error and returns the status so that event gets retried or failed.
 func getStatusCodeFromError(err error) int {
-	if client.IsProducerErrTemporary(err) {
+	if client.IsProducerErrTemporary(err) || schemaregistry.IsTemporary(err) {
 		return 500
 	}
 	return 400
@@ -30,6 +30,7 @@ import (
 	"github.com/rudderlabs/rudder-server/services/streammanager/common"
 	"github.com/rudderlabs/rudder-server/services/streammanager/kafka/client"
 	"github.com/rudderlabs/rudder-server/services/streammanager/kafka/client/testutil"
+	registry "github.com/rudderlabs/rudder-server/services/streammanager/kafka/schemaregistry"
 	dockerKafka "github.com/rudderlabs/rudder-server/testhelper/destination/kafka"
 	"github.com/rudderlabs/rudder-server/testhelper/destination/sshserver"
 )
@@ -116,6 +117,28 @@ func TestNewProducer(t *testing.T) {
 				"got error: json: cannot unmarshal object into Go struct field "+
 				"avroSchema.AvroSchemas.Schema of type string"),
 		)
+		t.Run("missing schema registry url", buildTest(
+			withRequired(map[string]interface{}{
+				"useSchemaRegistry": true,
+			}),
+			"invalid configuration: schema registry url cannot be empty"),
+		)
+		t.Run("schema registry along with avro schemas", buildTest(
+			withRequired(map[string]interface{}{
+				"useSchemaRegistry": true,
+				"schemaRegistryUrl": "http://localhost:8081",
+				"convertToAvro":     true,
+			}),
+			"invalid configuration: avro conversion with inline schemas cannot be used along with the schema registry"),
+		)
+		t.Run("invalid schema registry format", buildTest(
+			withRequired(map[string]interface{}{
+				"useSchemaRegistry": true,
+				"schemaRegistryUrl": "http://localhost:8081",
+				"schemaFormat":      "XML",
+			}),
+			`invalid schema registry configuration: unknown schema format: "XML"`),
+		)
 		t.Run("invalid ssh config", func(t *testing.T) {
 			t.Run("missing ssh host", buildTest(
 				withRequired(map[string]interface{}{
@@ -933,6 +956,55 @@ func TestSendBatchedMessage(t *testing.T) {
 		require.Equal(t, "Failure", res)
 		require.Equal(t, "Error while preparing batched message: unable to process any of the event in the batch", err)
 	})
+
+	t.Run("schema registry", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		kafkaStats.publishTime = getMockedTimer(t, ctrl, false)
+		kafkaStats.prepareBatchTime = getMockedTimer(t, ctrl, false)
+		kafkaStats.registrySerializationErr = getMockedCounter(t, ctrl)
+		kafkaStats.batchSize = mock_stats.NewMockMeasurement(ctrl)
+		kafkaStats.batchSize.(*mock_stats.MockMeasurement).EXPECT().Observe(1.0).Times(1)
+
+		p := &pMockErr{error: nil}
+		s := &serializerMock{}
+		pm := &ProducerManager{p: p, serializer: s}
+		sc, _, _ := sendBatchedMessage(
+			context.Background(),
+			json.RawMessage(`[
+				{"message":{"id":"u1"},"userId":"123","topic":"users","schemaId":"7"},
+				{"message":{"id":"u2"},"userId":"456","schemaId":"not-a-number"}
+			]`),
+			pm,
+			"some-topic",
+		)
+		require.Equal(t, 200, sc)
+		require.Equal(t, []registry.Record{{Topic: "users", SchemaID: 7, Value: []byte(`{"id":"u1"}`)}}, s.records)
+		require.Len(t, p.calls, 1)
+		require.Len(t, p.calls[0], 1)
+		require.Equal(t, []byte(`serialized:{"id":"u1"}`), p.calls[0][0].Value)
+		require.Equal(t, "users", p.calls[0][0].Topic)
+	})
+
+	t.Run("schema registry unavailable", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		kafkaStats.prepareBatchTime = getMockedTimer(t, ctrl, false)
+		kafkaStats.registrySerializationErr = getMockedCounter(t, ctrl)
+
+		s, err := registry.New(registry.Config{URL: newUnavailableRegistry(t)})
+		require.NoError(t, err)
+		p := &pMockErr{error: nil}
+		pm := &ProducerManager{p: p, serializer: s}
+		sc, res, errMsg := sendBatchedMessage(
+			context.Background(),
+			json.RawMessage(`[{"message":{"id":"u1"},"userId":"123","topic":"users","schemaId":"7"}]`),
+			pm,
+			"some-topic",
+		)
+		require.Equal(t, 500, sc)
+		require.Equal(t, "Failure", res)
+		require.Contains(t, errMsg, "unable to serialize the event at index 0 with the schema registry: getting schema 7")
+		require.Empty(t, p.calls)
+	})
 }
 
 func TestSendMessage(t *testing.T) {
@@ -1040,6 +1112,67 @@ func TestSendMessage(t *testing.T) {
 		require.Equal(t, `unable to serialize event with schemaId "schemaId001" and messageId message001: unable convert the event to native from textual, with error: cannot decode textual record "kafkaAvroTest.myrecord": cannot decode textual map: cannot determine codec: "data" error occurred.`, res)
 		require.Equal(t, `unable to serialize event with schemaId "schemaId001" and messageId message001: unable convert the event to native from textual, with error: cannot decode textual record "kafkaAvroTest.myrecord": cannot decode textual map: cannot determine codec: "data"`, err)
 	})
+
+	t.Run("schema registry", func(t *testing.T) {
+		kafkaStats.publishTime = getMockedTimer(t, gomock.NewController(t), false)
+
+		p := &pMockErr{error: nil}
+		s := &serializerMock{}
+		pm := &ProducerManager{p: p, serializer: s}
+		sc, _, _ := sendMessage(
+			context.Background(),
+			json.RawMessage(`{"message":{"id":"u1"},"userId":"123","schemaId":7,"recordName":"com.rudderstack.User"}`),
+			pm,
+			"some-topic",
+		)
+		require.Equal(t, 200, sc)
+		require.Equal(t, []registry.Record{{
+			Topic:      "some-topic",
+			RecordName: "com.rudderstack.User",
+			SchemaID:   7,
+			Value:      []byte(`{"id":"u1"}`),
+		}}, s.records)
+		require.Len(t, p.calls, 1)
+		require.Equal(t, []byte(`serialized:{"id":"u1"}`), p.calls[0][0].Value)
+	})
+
+	t.Run("schema registry error", func(t *testing.T) {
+		pm := &pmMockErr{serializer: &serializerMock{err: fmt.Errorf("subject not found")}}
+		sc, _, err := sendMessage(
+			context.Background(),
+			json.RawMessage(`{"message":{"messageId":"message001"},"userId":"123","topic":"some-topic"}`),
+			pm,
+			"some-topic",
+		)
+		require.Equal(t, 400, sc)
+		require.Equal(t, "unable to serialize event with messageId message001 with the schema registry: subject not found", err)
+	})
+
+	t.Run("schema registry unavailable", func(t *testing.T) {
+		s, err := registry.New(registry.Config{URL: newUnavailableRegistry(t)})
+		require.NoError(t, err)
+		pm := &pmMockErr{serializer: s}
+		sc, _, errMsg := sendMessage(
+			context.Background(),
+			json.RawMessage(`{"message":{"messageId":"message001"},"userId":"123","schemaId":7}`),
+			pm,
+			"some-topic",
+		)
+		require.Equal(t, 500, sc)
+		require.Contains(t, errMsg, "unable to serialize event with messageId message001 with the schema registry: getting schema 7")
+	})
+
+	t.Run("invalid schema registry schemaId", func(t *testing.T) {
+		pm := &pmMockErr{serializer: &serializerMock{}}
+		sc, _, err := sendMessage(
+			context.Background(),
+			json.RawMessage(`{"message":{"messageId":"message001"},"userId":"123","schemaId":"schemaId001"}`),
+			pm,
+			"some-topic",
+		)
+		require.Equal(t, 400, sc)
+		require.Contains(t, err, `invalid schemaId "schemaId001"`)
+	})
 }
 
 func TestPublish(t *testing.T) {
@@ -1353,7 +1486,8 @@ func requireEqualMessage(t testing.TB, topic, key, value string, actual client.M
 
 // Mocks
 type pmMockErr struct {
-	codecs map[string]*goavro.Codec
+	codecs     map[string]*goavro.Codec
+	serializer serializer
 }
 
 func (*pmMockErr) Close() error                                         { return nil }
@@ -1363,6 +1497,30 @@ func (*pmMockErr) getEmbedAvroSchemaID() bool                           { return
 func (pm *pmMockErr) getCodecs() map[string]*goavro.Codec {
 	return pm.codecs
 }
+func (pm *pmMockErr) getSerializer() serializer { return pm.serializer }
+
+// newUnavailableRegistry returns the url of a schema registry failing all the requests
+func newUnavailableRegistry(t *testing.T) string {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+		_, _ = w.Write([]byte(`{"error_code": 50003, "message": "error forwarding request to the leader"}`))
+	}))
+	t.Cleanup(srv.Close)
+	return srv.URL
+}
+
+type serializerMock struct {
+	records []registry.Record
+	err     error
+}
+
+func (s *serializerMock) Serialize(r registry.Record) ([]byte, error) {
+	s.records = append(s.records, r)
+	if s.err != nil {
+		return nil, s.err
+	}
+	return append([]byte("serialized:"), r.Value...), nil
+}
 
 type pMockErr struct {
 	error error
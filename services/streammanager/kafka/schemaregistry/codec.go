@@ -0,0 +1,172 @@
+package schemaregistry
+
+import (
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/bufbuild/protocompile"
+	"github.com/linkedin/goavro/v2"
+	"github.com/xeipuuv/gojsonschema"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/dynamicpb"
+)
+
+// protobufSchemaFile is the import path under which the protobuf schema is compiled
+const protobufSchemaFile = "schema.proto"
+
+// codec converts JSON messages to the payload of a schema
+type codec interface {
+	// recordName returns the fully qualified name of the record of the schema
+	recordName() string
+	encode(value []byte) ([]byte, error)
+}
+
+type avroCodec struct {
+	name  string
+	codec *goavro.Codec
+}
+
+func newAvroCodec(schema string) (*avroCodec, error) {
+	c, err := goavro.NewCodec(schema)
+	if err != nil {
+		return nil, fmt.Errorf("parsing avro schema: %w", err)
+	}
+	var record struct {
+		Name      string `json:"name"`
+		Namespace string `json:"namespace"`
+	}
+	// primitive schemas are not json objects and have no name
+	_ = json.Unmarshal([]byte(schema), &record)
+	name := record.Name
+	if record.Namespace != "" && !strings.Contains(name, ".") {
+		name = record.Namespace + "." + name
+	}
+	return &avroCodec{name: name, codec: c}, nil
+}
+
+func (c *avroCodec) recordName() string { return c.name }
+
+func (c *avroCodec) encode(value []byte) ([]byte, error) {
+	native, _, err := c.codec.NativeFromTextual(value)
+	if err != nil {
+		return nil, fmt.Errorf("converting the message to native from textual: %w", err)
+	}
+	bin, err := c.codec.BinaryFromNative(nil, native)
+	if err != nil {
+		return nil, fmt.Errorf("converting the message to binary from native: %w", err)
+	}
+	return bin, nil
+}
+
+// protobufCodec encodes the messages with the descriptor of a message of the schema.
+// The payload is prefixed by the indexes of the message in the schema, as expected by the protobuf deserializers.
+type protobufCodec struct {
+	descriptor protoreflect.MessageDescriptor
+	indexes    []byte
+}
+
+// newProtobufCodec compiles the protobuf schema along with its references and uses the message with the given name,
+// or the first message of the schema if empty
+func newProtobufCodec(sources map[string]string, messageName string) (*protobufCodec, error) {
+	compiler := protocompile.Compiler{
+		Resolver: protocompile.WithStandardImports(&protocompile.SourceResolver{
+			Accessor: protocompile.SourceAccessorFromMap(sources),
+		}),
+	}
+	files, err := compiler.Compile(context.Background(), protobufSchemaFile)
+	if err != nil {
+		return nil, fmt.Errorf("compiling protobuf schema: %w", err)
+	}
+	file := files[0]
+
+	var descriptor protoreflect.MessageDescriptor
+	if messageName == "" {
+		if file.Messages().Len() == 0 {
+			return nil, fmt.Errorf("protobuf schema has no messages")
+		}
+		descriptor = file.Messages().Get(0)
+	} else {
+		d := file.FindDescriptorByName(protoreflect.FullName(messageName))
+		md, ok := d.(protoreflect.MessageDescriptor)
+		if !ok {
+			return nil, fmt.Errorf("message %q not found in protobuf schema", messageName)
+		}
+		descriptor = md
+	}
+	return &protobufCodec{descriptor: descriptor, indexes: messageIndexes(descriptor)}, nil
+}
+
+// messageIndexes returns the zigzag encoded indexes of the message, from the top level message down to the nested one.
+// The common case of the first top level message is encoded as a single zero.
+func messageIndexes(descriptor protoreflect.MessageDescriptor) []byte {
+	var indexes []int
+	for d := protoreflect.Descriptor(descriptor); ; d = d.Parent() {
+		if _, ok := d.(protoreflect.MessageDescriptor); !ok {
+			break
+		}
+		indexes = append([]int{d.Index()}, indexes...)
+	}
+	if len(indexes) == 1 && indexes[0] == 0 {
+		return []byte{0}
+	}
+	b := binary.AppendVarint(nil, int64(len(indexes)))
+	for _, index := range indexes {
+		b = binary.AppendVarint(b, int64(index))
+	}
+	return b
+}
+
+func (c *protobufCodec) recordName() string { return string(c.descriptor.FullName()) }
+
+func (c *protobufCodec) encode(value []byte) ([]byte, error) {
+	msg := dynamicpb.NewMessage(c.descriptor)
+	// events usually carry more fields than the ones of the schema
+	if err := (protojson.UnmarshalOptions{DiscardUnknown: true}).Unmarshal(value, msg); err != nil {
+		return nil, fmt.Errorf("converting the message to %s: %w", c.descriptor.FullName(), err)
+	}
+	bin, err := proto.Marshal(msg)
+	if err != nil {
+		return nil, fmt.Errorf("marshalling %s: %w", c.descriptor.FullName(), err)
+	}
+	return append(append([]byte{}, c.indexes...), bin...), nil
+}
+
+// jsonSchemaCodec validates the messages against the json schema, the payload being the message itself
+type jsonSchemaCodec struct {
+	title  string
+	schema *gojsonschema.Schema
+}
+
+func newJSONSchemaCodec(schema string) (*jsonSchemaCodec, error) {
+	s, err := gojsonschema.NewSchema(gojsonschema.NewStringLoader(schema))
+	if err != nil {
+		return nil, fmt.Errorf("parsing json schema: %w", err)
+	}
+	var definition struct {
+		Title string `json:"title"`
+	}
+	_ = json.Unmarshal([]byte(schema), &definition)
+	return &jsonSchemaCodec{title: definition.Title, schema: s}, nil
+}
+
+func (c *jsonSchemaCodec) recordName() string { return c.title }
+
+func (c *jsonSchemaCodec) encode(value []byte) ([]byte, error) {
+	result, err := c.schema.Validate(gojsonschema.NewBytesLoader(value))
+	if err != nil {
+		return nil, fmt.Errorf("validating the message: %w", err)
+	}
+	if !result.Valid() {
+		errs := make([]string, 0, len(result.Errors()))
+		for _, e := range result.Errors() {
+			errs = append(errs, e.String())
+		}
+		return nil, fmt.Errorf("message does not match the json schema: %s", strings.Join(errs, "; "))
+	}
+	return value, nil
+}
@@ -0,0 +1,409 @@
+// Package schemaregistry serializes the messages produced to Kafka with the schemas of a
+// Schema Registry-compatible API, using the Confluent wire format:
+//
+//	| magic byte (0) | schema ID (4 bytes, big endian) | [protobuf message indexes] | payload |
+//
+// Schemas are fetched and cached by subject, and can optionally be auto-registered from the
+// local schemas provided in the configuration.
+package schemaregistry
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	sr "github.com/confluentinc/confluent-kafka-go/v2/schemaregistry"
+)
+
+// Format is the serialization format of the messages, named after the schema types of the registry
+type Format string
+
+const (
+	FormatAvro       Format = "AVRO"
+	FormatProtobuf   Format = "PROTOBUF"
+	FormatJSONSchema Format = "JSON"
+)
+
+// SubjectNameStrategy determines the subject under which the schema of a message is registered
+type SubjectNameStrategy string
+
+const (
+	// TopicNameStrategy uses <topic>-value as the subject
+	TopicNameStrategy SubjectNameStrategy = "TopicNameStrategy"
+	// RecordNameStrategy uses the fully qualified record name as the subject
+	RecordNameStrategy SubjectNameStrategy = "RecordNameStrategy"
+	// TopicRecordNameStrategy uses <topic>-<fully qualified record name> as the subject
+	TopicRecordNameStrategy SubjectNameStrategy = "TopicRecordNameStrategy"
+)
+
+const (
+	magicByte = byte(0x0)
+
+	defaultCacheTTL = 5 * time.Minute
+)
+
+var (
+	ErrUnknownFormat              = errors.New("unknown schema format")
+	ErrUnknownSubjectNameStrategy = errors.New("unknown subject name strategy")
+	ErrMissingRecordName          = errors.New("record name is required by the subject name strategy")
+)
+
+// Config is the configuration of the Serializer
+type Config struct {
+	URL      string
+	Username string
+	Password string
+
+	Format              Format
+	SubjectNameStrategy SubjectNameStrategy
+	// AutoRegister registers the local schemas under their subjects before using them
+	AutoRegister bool
+	// Schemas are the local schemas, used for auto registration and to derive the record names
+	Schemas []string
+
+	// CacheTTL is how long the latest schema of a subject is cached for, defaults to 5 minutes
+	CacheTTL time.Duration
+	// RequestTimeout is the timeout of the requests to the registry
+	RequestTimeout time.Duration
+}
+
+// Record is a message to be serialized
+type Record struct {
+	Topic string
+	// RecordName is the fully qualified name of the record (i.e. the avro record, the protobuf message or the
+	// title of the json schema). It is only required by the record name strategies when it can't be derived
+	// from the local schemas.
+	RecordName string
+	// SchemaID is the ID of the registry schema to use, if zero the schema is looked up by subject
+	SchemaID int
+	// Value is the JSON message
+	Value []byte
+}
+
+type codecKey struct {
+	id         int
+	recordName string
+}
+
+type latestSchema struct {
+	id        int
+	info      sr.SchemaInfo
+	fetchedAt time.Time
+}
+
+// Serializer serializes JSON messages with the schemas of the registry
+type Serializer struct {
+	client       sr.Client
+	format       Format
+	strategy     SubjectNameStrategy
+	autoRegister bool
+	cacheTTL     time.Duration
+
+	// localSchemas are the local schemas by record name
+	localSchemas map[string]string
+
+	mu     sync.RWMutex
+	codecs map[codecKey]codec
+	latest map[string]latestSchema
+
+	now func() time.Time
+}
+
+// New creates a Serializer for the given configuration
+func New(conf Config) (*Serializer, error) {
+	if conf.URL == "" {
+		return nil, fmt.Errorf("schema registry url cannot be empty")
+	}
+	srConf := sr.NewConfig(conf.URL)
+	if conf.Username != "" {
+		srConf = sr.NewConfigWithAuthentication(conf.URL, conf.Username, conf.Password)
+	}
+	if conf.RequestTimeout > 0 {
+		srConf.RequestTimeoutMs = int(conf.RequestTimeout.Milliseconds())
+		srConf.ConnectionTimeoutMs = int(conf.RequestTimeout.Milliseconds())
+	}
+	client, err := sr.NewClient(srConf)
+	if err != nil {
+		return nil, fmt.Errorf("creating schema registry client: %w", err)
+	}
+	return newSerializer(client, conf)
+}
+
+func newSerializer(client sr.Client, conf Config) (*Serializer, error) {
+	format := conf.Format
+	if format == "" {
+		format = FormatAvro
+	}
+	switch format {
+	case FormatAvro, FormatProtobuf, FormatJSONSchema:
+	default:
+		return nil, fmt.Errorf("%w: %q", ErrUnknownFormat, format)
+	}
+
+	strategy := conf.SubjectNameStrategy
+	if strategy == "" {
+		strategy = TopicNameStrategy
+	}
+	switch strategy {
+	case TopicNameStrategy, RecordNameStrategy, TopicRecordNameStrategy:
+	default:
+		return nil, fmt.Errorf("%w: %q", ErrUnknownSubjectNameStrategy, strategy)
+	}
+
+	cacheTTL := conf.CacheTTL
+	if cacheTTL <= 0 {
+		cacheTTL = defaultCacheTTL
+	}
+
+	s := &Serializer{
+		client:       client,
+		format:       format,
+		strategy:     strategy,
+		autoRegister: conf.AutoRegister,
+		cacheTTL:     cacheTTL,
+		localSchemas: make(map[string]string, len(conf.Schemas)),
+		codecs:       make(map[codecKey]codec),
+		latest:       make(map[string]latestSchema),
+		now:          time.Now,
+	}
+	for i, schema := range conf.Schemas {
+		c, err := s.newCodec(sr.SchemaInfo{Schema: schema, SchemaType: s.schemaType()}, "")
+		if err != nil {
+			return nil, fmt.Errorf("invalid local schema at index %d: %w", i, err)
+		}
+		s.localSchemas[c.recordName()] = schema
+	}
+	if conf.AutoRegister && len(s.localSchemas) == 0 {
+		return nil, fmt.Errorf("auto registration requires at least one local schema")
+	}
+	return s, nil
+}
+
+// Serialize converts the JSON value of the record to the configured format and prefixes it
+// with the magic byte and the ID of the schema
+func (s *Serializer) Serialize(r Record) ([]byte, error) {
+	var (
+		id   int
+		info sr.SchemaInfo
+		err  error
+	)
+	if r.SchemaID != 0 {
+		id = r.SchemaID
+		if info, err = s.client.GetBySubjectAndID("", id); err != nil {
+			return nil, fmt.Errorf("getting schema %d: %w", id, registryError(err))
+		}
+	} else {
+		recordName, localSchema := s.localSchema(r.RecordName)
+		subject, err := s.subject(r.Topic, recordName)
+		if err != nil {
+			return nil, err
+		}
+		if s.autoRegister && localSchema != "" {
+			info = sr.SchemaInfo{Schema: localSchema, SchemaType: s.schemaType()}
+			if id, err = s.client.Register(subject, info, false); err != nil {
+				return nil, fmt.Errorf("registering schema under subject %q: %w", subject, registryError(err))
+			}
+		} else if id, info, err = s.latestSchema(subject); err != nil {
+			return nil, fmt.Errorf("getting latest schema of subject %q: %w", subject, err)
+		}
+		r.RecordName = recordName
+	}
+
+	c, err := s.codec(id, info, r.RecordName)
+	if err != nil {
+		return nil, fmt.Errorf("creating codec for schema %d: %w", id, err)
+	}
+	payload, err := c.encode(r.Value)
+	if err != nil {
+		return nil, fmt.Errorf("serializing with schema %d: %w", id, err)
+	}
+	return appendWireFormat(id, payload), nil
+}
+
+// localSchema returns the record name along with its local schema, if any.
+// Without a record name, the only local schema is used.
+func (s *Serializer) localSchema(recordName string) (string, string) {
+	if recordName != "" {
+		return recordName, s.localSchemas[recordName]
+	}
+	if len(s.localSchemas) == 1 {
+		for name, schema := range s.localSchemas {
+			return name, schema
+		}
+	}
+	return "", ""
+}
+
+// subject returns the subject of the value schema according to the subject name strategy
+func (s *Serializer) subject(topic, recordName string) (string, error) {
+	switch s.strategy {
+	case RecordNameStrategy:
+		if recordName == "" {
+			return "", ErrMissingRecordName
+		}
+		return recordName, nil
+	case TopicRecordNameStrategy:
+		if recordName == "" {
+			return "", ErrMissingRecordName
+		}
+		return topic + "-" + recordName, nil
+	default:
+		return topic + "-value", nil
+	}
+}
+
+// latestSchema returns the latest schema of the subject, which is cached for the cache TTL
+func (s *Serializer) latestSchema(subject string) (int, sr.SchemaInfo, error) {
+	s.mu.RLock()
+	latest, ok := s.latest[subject]
+	s.mu.RUnlock()
+	if ok && s.now().Sub(latest.fetchedAt) < s.cacheTTL {
+		return latest.id, latest.info, nil
+	}
+
+	metadata, err := s.client.GetLatestSchemaMetadata(subject)
+	if err != nil {
+		return 0, sr.SchemaInfo{}, registryError(err)
+	}
+	s.mu.Lock()
+	s.latest[subject] = latestSchema{id: metadata.ID, info: metadata.SchemaInfo, fetchedAt: s.now()}
+	s.mu.Unlock()
+	return metadata.ID, metadata.SchemaInfo, nil
+}
+
+// codec returns the cached codec of the schema, creating it if needed.
+// Schemas are immutable in the registry, so codecs never expire.
+func (s *Serializer) codec(id int, info sr.SchemaInfo, recordName string) (codec, error) {
+	key := codecKey{id: id, recordName: recordName}
+	if s.format != FormatProtobuf {
+		// the record name only selects the message of protobuf schemas
+		key.recordName = ""
+	}
+	s.mu.RLock()
+	c, ok := s.codecs[key]
+	s.mu.RUnlock()
+	if ok {
+		return c, nil
+	}
+
+	c, err := s.newCodec(info, key.recordName)
+	if err != nil {
+		return nil, err
+	}
+	s.mu.Lock()
+	s.codecs[key] = c
+	s.mu.Unlock()
+	return c, nil
+}
+
+func (s *Serializer) newCodec(info sr.SchemaInfo, recordName string) (codec, error) {
+	schemaType := info.SchemaType
+	if schemaType == "" {
+		schemaType = string(FormatAvro)
+	}
+	if !strings.EqualFold(schemaType, string(s.format)) {
+		return nil, fmt.Errorf("schema type %q does not match the %s format", schemaType, s.format)
+	}
+	switch s.format {
+	case FormatProtobuf:
+		sources, err := s.protobufSources(info)
+		if err != nil {
+			return nil, err
+		}
+		return newProtobufCodec(sources, recordName)
+	case FormatJSONSchema:
+		if len(info.References) > 0 {
+			return nil, fmt.Errorf("json schema references are not supported")
+		}
+		return newJSONSchemaCodec(info.Schema)
+	default:
+		if len(info.References) > 0 {
+			return nil, fmt.Errorf("avro schema references are not supported")
+		}
+		return newAvroCodec(info.Schema)
+	}
+}
+
+// protobufSources returns the protobuf files of the schema by import path, along with the ones of its references
+func (s *Serializer) protobufSources(info sr.SchemaInfo) (map[string]string, error) {
+	sources := map[string]string{protobufSchemaFile: info.Schema}
+	var addReferences func(refs []sr.Reference) error
+	addReferences = func(refs []sr.Reference) error {
+		for _, ref := range refs {
+			if _, ok := sources[ref.Name]; ok {
+				continue
+			}
+			metadata, err := s.client.GetSchemaMetadata(ref.Subject, ref.Version)
+			if err != nil {
+				return fmt.Errorf("getting reference %q: %w", ref.Name, registryError(err))
+			}
+			sources[ref.Name] = metadata.Schema
+			if err := addReferences(metadata.References); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	if err := addReferences(info.References); err != nil {
+		return nil, err
+	}
+	return sources, nil
+}
+
+// schemaType returns the registry schema type of the format. The registry omits the type of avro schemas.
+func (s *Serializer) schemaType() string {
+	if s.format == FormatAvro {
+		return ""
+	}
+	return string(s.format)
+}
+
+// appendWireFormat prefixes the payload with the magic byte and the schema ID
+func appendWireFormat(id int, payload []byte) []byte {
+	msg := make([]byte, 5, 5+len(payload))
+	msg[0] = magicByte
+	binary.BigEndian.PutUint32(msg[1:5], uint32(id))
+	return append(msg, payload...)
+}
+
+// temporaryError is an error of a registry request which was not caused by the request itself,
+// e.g. the registry being unreachable or failing to serve it, so that serializing the message can be retried
+type temporaryError struct {
+	err error
+}
+
+func (e *temporaryError) Error() string { return e.err.Error() }
+
+func (e *temporaryError) Unwrap() error { return e.err }
+
+// IsTemporary returns true if the serialization failed because of the registry rather than
+// because of the message or the schemas, in which case it can be retried
+func IsTemporary(err error) bool {
+	var temporaryErr *temporaryError
+	return errors.As(err, &temporaryErr)
+}
+
+// registryError marks the error of a registry request as temporary, unless the registry rejected the request
+func registryError(err error) error {
+	var restErr *sr.RestError
+	if !errors.As(err, &restErr) {
+		return &temporaryError{err: err}
+	}
+	// the registry error codes are the http status codes, optionally followed by two digits (e.g. 40401)
+	status := restErr.Code
+	if status >= 10000 {
+		status /= 100
+	}
+	switch {
+	case status == http.StatusRequestTimeout, status == http.StatusTooManyRequests:
+		return &temporaryError{err: err}
+	case status >= 400 && status < 500:
+		return err
+	default:
+		return &temporaryError{err: err}
+	}
+}
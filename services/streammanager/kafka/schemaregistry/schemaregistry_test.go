@@ -0,0 +1,414 @@
+package schemaregistry
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/linkedin/goavro/v2"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/dynamicpb"
+)
+
+const (
+	userAvroSchema = `{
+		"type": "record",
+		"name": "User",
+		"namespace": "com.rudderstack",
+		"fields": [
+			{"name": "id", "type": "string"},
+			{"name": "age", "type": "int"}
+		]
+	}`
+	userProtobufSchema = `syntax = "proto3";
+package com.rudderstack;
+
+message Event {
+  string name = 1;
+  message User {
+    string id = 1;
+    int32 age = 2;
+  }
+}`
+	userJSONSchema = `{
+		"title": "com.rudderstack.User",
+		"type": "object",
+		"properties": {
+			"id": {"type": "string"},
+			"age": {"type": "integer"}
+		},
+		"required": ["id"]
+	}`
+)
+
+func TestSerializer(t *testing.T) {
+	t.Run("avro with auto registration", func(t *testing.T) {
+		registry := newRegistryStub(t)
+		s, err := New(Config{
+			URL:          registry.URL,
+			Format:       FormatAvro,
+			AutoRegister: true,
+			Schemas:      []string{userAvroSchema},
+		})
+		require.NoError(t, err)
+
+		for i := 0; i < 3; i++ {
+			msg, err := s.Serialize(Record{Topic: "users", Value: []byte(`{"id":"u1","age":30}`)})
+			require.NoError(t, err)
+
+			id, payload := parseWireFormat(t, msg)
+			require.Equal(t, registry.subjectVersions("users-value")[0], id)
+
+			codec, err := goavro.NewCodec(userAvroSchema)
+			require.NoError(t, err)
+			native, _, err := codec.NativeFromBinary(payload)
+			require.NoError(t, err)
+			require.Equal(t, map[string]interface{}{"id": "u1", "age": int32(30)}, native)
+		}
+		require.Equal(t, 1, registry.requestCount(http.MethodPost), "registrations should be cached")
+	})
+
+	t.Run("avro with the latest schema of the subject", func(t *testing.T) {
+		registry := newRegistryStub(t)
+		id := registry.register("users-value", userAvroSchema, "")
+
+		s, err := New(Config{URL: registry.URL, CacheTTL: time.Minute})
+		require.NoError(t, err)
+		now := time.Now()
+		s.now = func() time.Time { return now }
+
+		for i := 0; i < 3; i++ {
+			msg, err := s.Serialize(Record{Topic: "users", Value: []byte(`{"id":"u1","age":30}`)})
+			require.NoError(t, err)
+			gotID, _ := parseWireFormat(t, msg)
+			require.Equal(t, id, gotID)
+		}
+		require.Equal(t, 1, registry.requestCount(http.MethodGet), "latest schema should be cached")
+
+		now = now.Add(2 * time.Minute)
+		_, err = s.Serialize(Record{Topic: "users", Value: []byte(`{"id":"u1","age":30}`)})
+		require.NoError(t, err)
+		require.Equal(t, 2, registry.requestCount(http.MethodGet), "latest schema should be fetched once expired")
+
+		_, err = s.Serialize(Record{Topic: "users", Value: []byte(`{"id":"u1"}`)})
+		require.Error(t, err, "message not matching the schema")
+
+		_, err = s.Serialize(Record{Topic: "unknown", Value: []byte(`{"id":"u1","age":30}`)})
+		require.ErrorContains(t, err, `getting latest schema of subject "unknown-value"`)
+	})
+
+	t.Run("avro with schema id", func(t *testing.T) {
+		registry := newRegistryStub(t)
+		id := registry.register("another-subject", userAvroSchema, "")
+
+		s, err := New(Config{URL: registry.URL})
+		require.NoError(t, err)
+		msg, err := s.Serialize(Record{Topic: "users", SchemaID: id, Value: []byte(`{"id":"u1","age":30}`)})
+		require.NoError(t, err)
+		gotID, _ := parseWireFormat(t, msg)
+		require.Equal(t, id, gotID)
+
+		_, err = s.Serialize(Record{Topic: "users", SchemaID: 1000, Value: []byte(`{"id":"u1","age":30}`)})
+		require.ErrorContains(t, err, "getting schema 1000")
+	})
+
+	t.Run("protobuf with record name strategy", func(t *testing.T) {
+		registry := newRegistryStub(t)
+		s, err := New(Config{
+			URL:                 registry.URL,
+			Format:              FormatProtobuf,
+			SubjectNameStrategy: RecordNameStrategy,
+			AutoRegister:        true,
+			Schemas:             []string{userProtobufSchema},
+		})
+		require.NoError(t, err)
+
+		msg, err := s.Serialize(Record{Topic: "events", Value: []byte(`{"name":"signup","extra":true}`)})
+		require.NoError(t, err)
+		id, payload := parseWireFormat(t, msg)
+		require.Equal(t, registry.subjectVersions("com.rudderstack.Event")[0], id)
+		require.Equal(t, byte(0), payload[0], "first message index")
+
+		c, err := newProtobufCodec(map[string]string{protobufSchemaFile: userProtobufSchema}, "")
+		require.NoError(t, err)
+		event := dynamicpb.NewMessage(c.descriptor)
+		require.NoError(t, proto.Unmarshal(payload[1:], event))
+		require.Equal(t, "signup", event.Get(c.descriptor.Fields().ByName("name")).String())
+
+		_, err = s.Serialize(Record{Topic: "events", RecordName: "com.rudderstack.Event.User", Value: []byte(`{"id":"u1"}`)})
+		require.ErrorContains(t, err, `getting latest schema of subject "com.rudderstack.Event.User"`)
+
+		registry.register("com.rudderstack.Event.User", userProtobufSchema, string(FormatProtobuf))
+		msg, err = s.Serialize(Record{Topic: "events", RecordName: "com.rudderstack.Event.User", Value: []byte(`{"id":"u1","age":30}`)})
+		require.NoError(t, err)
+		_, payload = parseWireFormat(t, msg)
+
+		count, n := binary.Varint(payload)
+		require.EqualValues(t, 2, count)
+		payload = payload[n:]
+		for _, expected := range []int64{0, 0} {
+			index, n := binary.Varint(payload)
+			require.Equal(t, expected, index)
+			payload = payload[n:]
+		}
+		c, err = newProtobufCodec(map[string]string{protobufSchemaFile: userProtobufSchema}, "com.rudderstack.Event.User")
+		require.NoError(t, err)
+		user := dynamicpb.NewMessage(c.descriptor)
+		require.NoError(t, proto.Unmarshal(payload, user))
+		require.Equal(t, "u1", user.Get(c.descriptor.Fields().ByName("id")).String())
+		require.EqualValues(t, 30, user.Get(c.descriptor.Fields().ByName("age")).Int())
+	})
+
+	t.Run("protobuf with references", func(t *testing.T) {
+		registry := newRegistryStub(t)
+		registry.register("common", `syntax = "proto3";
+package common;
+message Address { string city = 1; }`, string(FormatProtobuf))
+		id := registry.registerWithReferences("users-value", `syntax = "proto3";
+import "common.proto";
+import "google/protobuf/timestamp.proto";
+message User {
+  common.Address address = 1;
+  google.protobuf.Timestamp created_at = 2;
+}`, string(FormatProtobuf), []map[string]interface{}{{"name": "common.proto", "subject": "common", "version": 1}})
+
+		s, err := New(Config{URL: registry.URL, Format: FormatProtobuf})
+		require.NoError(t, err)
+		msg, err := s.Serialize(Record{Topic: "users", Value: []byte(`{"address":{"city":"Berlin"},"createdAt":"2023-01-01T00:00:00Z"}`)})
+		require.NoError(t, err)
+		gotID, _ := parseWireFormat(t, msg)
+		require.Equal(t, id, gotID)
+	})
+
+	t.Run("json schema with topic record name strategy", func(t *testing.T) {
+		registry := newRegistryStub(t)
+		s, err := New(Config{
+			URL:                 registry.URL,
+			Format:              FormatJSONSchema,
+			SubjectNameStrategy: TopicRecordNameStrategy,
+			AutoRegister:        true,
+			Schemas:             []string{userJSONSchema},
+		})
+		require.NoError(t, err)
+
+		value := []byte(`{"id":"u1","age":30}`)
+		msg, err := s.Serialize(Record{Topic: "users", Value: value})
+		require.NoError(t, err)
+		id, payload := parseWireFormat(t, msg)
+		require.Equal(t, registry.subjectVersions("users-com.rudderstack.User")[0], id)
+		require.Equal(t, value, payload)
+
+		_, err = s.Serialize(Record{Topic: "users", Value: []byte(`{"age":"thirty"}`)})
+		require.ErrorContains(t, err, "message does not match the json schema")
+	})
+
+	t.Run("registry errors", func(t *testing.T) {
+		registry := newRegistryStub(t)
+		s, err := New(Config{URL: registry.URL})
+		require.NoError(t, err)
+		_, err = s.Serialize(Record{Topic: "users", Value: []byte(`{"id":"u1","age":30}`)})
+		require.ErrorContains(t, err, `getting latest schema of subject "users-value"`)
+		require.False(t, IsTemporary(err), "a missing subject is not temporary")
+
+		unavailable := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+			w.WriteHeader(http.StatusInternalServerError)
+			_, _ = w.Write([]byte(`{"error_code": 50002, "message": "operation timed out"}`))
+		}))
+		t.Cleanup(unavailable.Close)
+		s, err = New(Config{URL: unavailable.URL})
+		require.NoError(t, err)
+		_, err = s.Serialize(Record{Topic: "users", SchemaID: 1, Value: []byte(`{"id":"u1","age":30}`)})
+		require.ErrorContains(t, err, "operation timed out")
+		require.True(t, IsTemporary(err))
+
+		unavailable.Close()
+		_, err = s.Serialize(Record{Topic: "users", Value: []byte(`{"id":"u1","age":30}`)})
+		require.Error(t, err)
+		require.True(t, IsTemporary(err), "an unreachable registry is temporary")
+	})
+
+	t.Run("schema type mismatch", func(t *testing.T) {
+		registry := newRegistryStub(t)
+		registry.register("users-value", userAvroSchema, "")
+
+		s, err := New(Config{URL: registry.URL, Format: FormatJSONSchema})
+		require.NoError(t, err)
+		_, err = s.Serialize(Record{Topic: "users", Value: []byte(`{"id":"u1","age":30}`)})
+		require.ErrorContains(t, err, `schema type "AVRO" does not match the JSON format`)
+	})
+}
+
+func TestNew(t *testing.T) {
+	_, err := New(Config{})
+	require.EqualError(t, err, "schema registry url cannot be empty")
+
+	_, err = New(Config{URL: "http://localhost", Format: "XML"})
+	require.ErrorIs(t, err, ErrUnknownFormat)
+
+	_, err = New(Config{URL: "http://localhost", SubjectNameStrategy: "Unknown"})
+	require.ErrorIs(t, err, ErrUnknownSubjectNameStrategy)
+
+	_, err = New(Config{URL: "http://localhost", AutoRegister: true})
+	require.EqualError(t, err, "auto registration requires at least one local schema")
+
+	_, err = New(Config{URL: "http://localhost", Schemas: []string{`{"type": "record"}`}})
+	require.ErrorContains(t, err, "invalid local schema at index 0")
+}
+
+func TestSubject(t *testing.T) {
+	testCases := []struct {
+		strategy   SubjectNameStrategy
+		recordName string
+		expected   string
+		err        error
+	}{
+		{strategy: TopicNameStrategy, expected: "topic-value"},
+		{strategy: TopicNameStrategy, recordName: "com.rudderstack.User", expected: "topic-value"},
+		{strategy: RecordNameStrategy, recordName: "com.rudderstack.User", expected: "com.rudderstack.User"},
+		{strategy: RecordNameStrategy, err: ErrMissingRecordName},
+		{strategy: TopicRecordNameStrategy, recordName: "com.rudderstack.User", expected: "topic-com.rudderstack.User"},
+		{strategy: TopicRecordNameStrategy, err: ErrMissingRecordName},
+	}
+	for _, tc := range testCases {
+		s := &Serializer{strategy: tc.strategy}
+		subject, err := s.subject("topic", tc.recordName)
+		require.ErrorIs(t, err, tc.err)
+		require.Equal(t, tc.expected, subject)
+	}
+}
+
+func parseWireFormat(t *testing.T, msg []byte) (int, []byte) {
+	t.Helper()
+	require.Greater(t, len(msg), 5)
+	require.Equal(t, magicByte, msg[0])
+	return int(binary.BigEndian.Uint32(msg[1:5])), msg[5:]
+}
+
+type stubSchema struct {
+	Schema     string                   `json:"schema"`
+	SchemaType string                   `json:"schemaType,omitempty"`
+	References []map[string]interface{} `json:"references,omitempty"`
+}
+
+// registryStub is a minimal in-memory implementation of the schema registry API
+type registryStub struct {
+	*httptest.Server
+
+	mu       sync.Mutex
+	schemas  []stubSchema
+	subjects map[string][]int
+	requests map[string]int
+}
+
+func newRegistryStub(t *testing.T) *registryStub {
+	r := &registryStub{subjects: make(map[string][]int), requests: make(map[string]int)}
+	r.Server = httptest.NewServer(http.HandlerFunc(r.handle))
+	t.Cleanup(r.Close)
+	return r
+}
+
+func (r *registryStub) register(subject, schema, schemaType string) int {
+	return r.registerWithReferences(subject, schema, schemaType, nil)
+}
+
+func (r *registryStub) registerWithReferences(subject, schema, schemaType string, references []map[string]interface{}) int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.schemas = append(r.schemas, stubSchema{Schema: schema, SchemaType: schemaType, References: references})
+	id := len(r.schemas)
+	r.subjects[subject] = append(r.subjects[subject], id)
+	return id
+}
+
+func (r *registryStub) subjectVersions(subject string) []int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.subjects[subject]
+}
+
+func (r *registryStub) requestCount(method string) int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.requests[method]
+}
+
+func (r *registryStub) handle(w http.ResponseWriter, req *http.Request) {
+	r.mu.Lock()
+	r.requests[req.Method]++
+	r.mu.Unlock()
+
+	notFound := func() {
+		w.WriteHeader(http.StatusNotFound)
+		_, _ = w.Write([]byte(`{"error_code": 40401, "message": "not found"}`))
+	}
+	respond := func(v interface{}) {
+		w.Header().Set("Content-Type", "application/vnd.schemaregistry.v1+json")
+		_ = json.NewEncoder(w).Encode(v)
+	}
+	schemaMetadata := func(subject string, version int) {
+		r.mu.Lock()
+		defer r.mu.Unlock()
+		ids := r.subjects[subject]
+		if version == -1 {
+			version = len(ids)
+		}
+		if version < 1 || version > len(ids) {
+			notFound()
+			return
+		}
+		id := ids[version-1]
+		s := r.schemas[id-1]
+		respond(map[string]interface{}{
+			"subject": subject, "version": version, "id": id,
+			"schema": s.Schema, "schemaType": s.SchemaType, "references": s.References,
+		})
+	}
+
+	parts := strings.Split(strings.Trim(req.URL.Path, "/"), "/")
+	switch {
+	case req.Method == http.MethodPost && len(parts) == 3 && parts[0] == "subjects" && parts[2] == "versions":
+		var s stubSchema
+		if err := json.NewDecoder(req.Body).Decode(&s); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		r.mu.Lock()
+		for _, id := range r.subjects[parts[1]] {
+			if r.schemas[id-1].Schema == s.Schema {
+				r.mu.Unlock()
+				respond(map[string]int{"id": id})
+				return
+			}
+		}
+		r.mu.Unlock()
+		respond(map[string]int{"id": r.registerWithReferences(parts[1], s.Schema, s.SchemaType, s.References)})
+	case req.Method == http.MethodGet && len(parts) == 4 && parts[0] == "subjects" && parts[2] == "versions":
+		version := -1
+		if parts[3] != "latest" {
+			var err error
+			if version, err = strconv.Atoi(parts[3]); err != nil {
+				notFound()
+				return
+			}
+		}
+		schemaMetadata(parts[1], version)
+	case req.Method == http.MethodGet && len(parts) == 3 && parts[0] == "schemas" && parts[1] == "ids":
+		id, _ := strconv.Atoi(parts[2])
+		r.mu.Lock()
+		defer r.mu.Unlock()
+		if id < 1 || id > len(r.schemas) {
+			notFound()
+			return
+		}
+		respond(r.schemas[id-1])
+	default:
+		notFound()
+	}
+}
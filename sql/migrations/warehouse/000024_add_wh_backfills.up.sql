@@ -0,0 +1,21 @@
+--
+-- wh_backfills
+--
+
+CREATE TABLE IF NOT EXISTS wh_backfills (
+    id BIGSERIAL PRIMARY KEY,
+    workspace_id VARCHAR NOT NULL DEFAULT '',
+    source_id VARCHAR(64) NOT NULL DEFAULT '',
+    destination_id VARCHAR(64) NOT NULL,
+    tables TEXT[] NOT NULL,
+    start_time TIMESTAMP WITHOUT TIME ZONE NOT NULL,
+    end_time TIMESTAMP WITHOUT TIME ZONE NOT NULL,
+    delete_rows BOOLEAN NOT NULL DEFAULT FALSE,
+    status VARCHAR(64) NOT NULL,
+    error TEXT,
+    upload_ids BIGINT[] NOT NULL DEFAULT '{}',
+    created_at TIMESTAMP WITHOUT TIME ZONE NOT NULL,
+    updated_at TIMESTAMP WITHOUT TIME ZONE NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS wh_backfills_destination_id_index ON wh_backfills (destination_id);
@@ -0,0 +1,8 @@
+--
+-- wh_table_uploads
+--
+
+ALTER TABLE wh_table_uploads ADD COLUMN IF NOT EXISTS cost_queries BIGINT NOT NULL DEFAULT 0;
+ALTER TABLE wh_table_uploads ADD COLUMN IF NOT EXISTS cost_bytes_scanned BIGINT NOT NULL DEFAULT 0;
+ALTER TABLE wh_table_uploads ADD COLUMN IF NOT EXISTS cost_bytes_billed BIGINT NOT NULL DEFAULT 0;
+ALTER TABLE wh_table_uploads ADD COLUMN IF NOT EXISTS cost_credits DOUBLE PRECISION NOT NULL DEFAULT 0;
@@ -18,11 +18,13 @@ import (
 	"google.golang.org/grpc/status"
 	"google.golang.org/protobuf/types/known/timestamppb"
 
+	"github.com/samber/lo"
 	"github.com/tidwall/gjson"
 
 	"github.com/rudderlabs/rudder-go-kit/config"
 	"github.com/rudderlabs/rudder-go-kit/filemanager"
 	"github.com/rudderlabs/rudder-go-kit/logger"
+	"github.com/rudderlabs/rudder-go-kit/stats"
 	backendconfig "github.com/rudderlabs/rudder-server/backend-config"
 	"github.com/rudderlabs/rudder-server/controlplane"
 	proto "github.com/rudderlabs/rudder-server/proto/warehouse"
@@ -31,8 +33,10 @@ import (
 	"github.com/rudderlabs/rudder-server/utils/timeutil"
 	"github.com/rudderlabs/rudder-server/utils/types/deployment"
 	cpclient "github.com/rudderlabs/rudder-server/warehouse/client/controlplane"
+	"github.com/rudderlabs/rudder-server/warehouse/integrations/manager"
 	"github.com/rudderlabs/rudder-server/warehouse/internal/model"
 	"github.com/rudderlabs/rudder-server/warehouse/internal/repo"
+	"github.com/rudderlabs/rudder-server/warehouse/internal/service"
 	warehouseutils "github.com/rudderlabs/rudder-server/warehouse/utils"
 	"github.com/rudderlabs/rudder-server/warehouse/validations"
 )
@@ -54,6 +58,23 @@ type UploadReq struct {
 	API         UploadAPIT
 }
 
+type BackfillReq struct {
+	WorkspaceID   string
+	SourceID      string
+	DestinationID string
+	Tables        []string
+	StartTime     time.Time
+	EndTime       time.Time
+	DeleteRows    bool
+	API           UploadAPIT
+}
+
+type BackfillStatusReq struct {
+	WorkspaceID string
+	BackfillID  int64
+	API         UploadAPIT
+}
+
 type UploadsRes struct {
 	Uploads    []UploadRes      `json:"uploads"`
 	Pagination UploadPagination `json:"pagination"`
@@ -363,6 +384,7 @@ func (uploadReq *UploadReq) GetWHUpload(ctx context.Context) (*proto.WHUploadRes
 	if err != nil {
 		return &proto.WHUploadResponse{}, status.Errorf(codes.Code(code.Code_INTERNAL), err.Error())
 	}
+	upload.Cost = uploadCost(upload.Tables)
 
 	return &upload, nil
 }
@@ -417,12 +439,231 @@ func (uploadReq *UploadReq) TriggerWHUpload(ctx context.Context) (response *prot
 	return
 }
 
+// PlanWHUpload runs the upload in plan mode and returns the tables and columns it would create or alter,
+// along with the number of rows it would load into each of them.
+func (uploadReq *UploadReq) PlanWHUpload(ctx context.Context) (*proto.WHUploadPlanResponse, error) {
+	err := uploadReq.validateReq()
+	if err != nil {
+		return &proto.WHUploadPlanResponse{}, status.Errorf(codes.Code(code.Code_INVALID_ARGUMENT), err.Error())
+	}
+
+	uploadsRepo := repo.NewUploads(uploadReq.API.warehouseDBHandle.handle)
+
+	upload, err := uploadsRepo.Get(ctx, uploadReq.UploadId)
+	if err == model.ErrUploadNotFound {
+		return &proto.WHUploadPlanResponse{}, status.Errorf(codes.Code(code.Code_NOT_FOUND), "sync not found")
+	}
+	if err != nil {
+		uploadReq.API.log.Errorf(err.Error())
+		return &proto.WHUploadPlanResponse{}, status.Errorf(codes.Code(code.Code_INTERNAL), err.Error())
+	}
+
+	if !uploadReq.authorizeSource(upload.SourceID) {
+		pkgLogger.Errorf(`Unauthorized request for upload:%d with sourceId:%s in workspaceId:%s`, uploadReq.UploadId, upload.SourceID, uploadReq.WorkspaceID)
+		return &proto.WHUploadPlanResponse{}, status.Error(codes.Code(code.Code_UNAUTHENTICATED), "unauthorized request")
+	}
+
+	if upload.Status == model.ExportedData || upload.Status == model.Aborted {
+		return &proto.WHUploadPlanResponse{}, status.Errorf(codes.Code(code.Code_FAILED_PRECONDITION), "cannot plan sync with status: %s", upload.Status)
+	}
+
+	// plans generate the load files of the staging files, which must not happen while the upload itself is generating them
+	inProgress, err := uploadsRepo.Count(ctx,
+		repo.FilterBy{Key: "id", Value: upload.ID},
+		repo.FilterBy{Key: "in_progress", Value: true},
+	)
+	if err != nil {
+		return &proto.WHUploadPlanResponse{}, status.Errorf(codes.Code(code.Code_INTERNAL), err.Error())
+	}
+	if inProgress > 0 {
+		return &proto.WHUploadPlanResponse{}, status.Error(codes.Code(code.Code_FAILED_PRECONDITION), "sync is in progress")
+	}
+
+	connectionsMapLock.RLock()
+	warehouse, ok := connectionsMap[upload.DestinationID][upload.SourceID]
+	connectionsMapLock.RUnlock()
+	if !ok {
+		return &proto.WHUploadPlanResponse{}, status.Errorf(codes.Code(code.Code_NOT_FOUND), "unable to find source : %s or destination : %s, both or the connection between them", upload.SourceID, upload.DestinationID)
+	}
+	upload.UseRudderStorage = warehouse.GetBoolDestinationConfig("useRudderStorage")
+
+	stagingFiles, err := repo.NewStagingFiles(uploadReq.API.warehouseDBHandle.handle).GetForUpload(ctx, upload)
+	if err != nil {
+		return &proto.WHUploadPlanResponse{}, status.Errorf(codes.Code(code.Code_INTERNAL), err.Error())
+	}
+
+	whManager, err := manager.New(warehouse.Type, config.Default, uploadReq.API.log, stats.Default)
+	if err != nil {
+		return &proto.WHUploadPlanResponse{}, status.Errorf(codes.Code(code.Code_INTERNAL), err.Error())
+	}
+
+	uploadJobFactory := newUploadJobFactory(warehouse.Type, uploadReq.API.warehouseDBHandle.handle, &notifier)
+	uploadJob := uploadJobFactory.NewUploadJob(ctx, &model.UploadJob{
+		Warehouse:    warehouse,
+		Upload:       upload,
+		StagingFiles: stagingFiles,
+	}, whManager)
+
+	plan, err := uploadJob.plan()
+	if err != nil {
+		return &proto.WHUploadPlanResponse{}, status.Errorf(codes.Code(code.Code_INTERNAL), err.Error())
+	}
+	return uploadPlanToProto(plan), nil
+}
+
+func uploadPlanToProto(plan model.UploadPlan) *proto.WHUploadPlanResponse {
+	columnsToProto := func(columns model.TableSchema) []*proto.WHColumn {
+		columnNames := lo.Keys(columns)
+		slices.Sort(columnNames)
+
+		protoColumns := make([]*proto.WHColumn, 0, len(columnNames))
+		for _, columnName := range columnNames {
+			protoColumns = append(protoColumns, &proto.WHColumn{
+				Name: columnName,
+				Type: columns[columnName],
+			})
+		}
+		return protoColumns
+	}
+
+	response := &proto.WHUploadPlanResponse{
+		UploadId:  plan.UploadID,
+		Tables:    make([]*proto.WHTablePlan, 0, len(plan.Tables)),
+		TotalRows: plan.TotalRows(),
+	}
+	for _, table := range plan.Tables {
+		response.Tables = append(response.Tables, &proto.WHTablePlan{
+			Name:           table.Name,
+			CreateTable:    table.CreateTable,
+			AddedColumns:   columnsToProto(table.AddedColumns),
+			AlteredColumns: columnsToProto(table.AlteredColumns),
+			TotalRows:      table.TotalRows,
+		})
+	}
+	return response
+}
+
+// CreateWHBackfill creates a backfill which re-syncs the staging files of the destination received in the time range
+// into the tables, by scheduling new uploads reprocessing them.
+func (backfillReq *BackfillReq) CreateWHBackfill(ctx context.Context) (*proto.WHBackfillResponse, error) {
+	err := backfillReq.validateReq()
+	if err != nil {
+		return &proto.WHBackfillResponse{}, status.Errorf(codes.Code(code.Code_INVALID_ARGUMENT), err.Error())
+	}
+
+	authorizedSourceIDs := (&UploadsReq{WorkspaceID: backfillReq.WorkspaceID}).authorizedSources()
+	if backfillReq.SourceID != "" && !slices.Contains(authorizedSourceIDs, backfillReq.SourceID) {
+		pkgLogger.Errorf(`Unauthorized backfill request for sourceId:%s in workspaceId:%s`, backfillReq.SourceID, backfillReq.WorkspaceID)
+		return &proto.WHBackfillResponse{}, status.Error(codes.Code(code.Code_UNAUTHENTICATED), "unauthorized request")
+	}
+
+	var warehouses []model.Warehouse
+	connectionsMapLock.RLock()
+	for sourceID, warehouse := range connectionsMap[backfillReq.DestinationID] {
+		if backfillReq.SourceID != "" && sourceID != backfillReq.SourceID {
+			continue
+		}
+		if !slices.Contains(authorizedSourceIDs, sourceID) {
+			continue
+		}
+		warehouses = append(warehouses, warehouse)
+	}
+	connectionsMapLock.RUnlock()
+
+	slices.SortFunc(warehouses, func(a, b model.Warehouse) bool {
+		return a.Source.ID < b.Source.ID
+	})
+
+	backfill, err := backfillReq.API.backfillService().Create(ctx, warehouses, model.Backfill{
+		WorkspaceID:   backfillReq.WorkspaceID,
+		SourceID:      backfillReq.SourceID,
+		DestinationID: backfillReq.DestinationID,
+		Tables:        backfillReq.Tables,
+		StartTime:     backfillReq.StartTime,
+		EndTime:       backfillReq.EndTime,
+		DeleteRows:    backfillReq.DeleteRows,
+	})
+	switch {
+	case errors.Is(err, service.ErrBackfillNoTables),
+		errors.Is(err, service.ErrBackfillInvalidRange),
+		errors.Is(err, service.ErrBackfillUnalignedRange):
+		return &proto.WHBackfillResponse{}, status.Errorf(codes.Code(code.Code_INVALID_ARGUMENT), err.Error())
+	case errors.Is(err, service.ErrBackfillArchived):
+		return &proto.WHBackfillResponse{}, status.Errorf(codes.Code(code.Code_FAILED_PRECONDITION), err.Error())
+	case errors.Is(err, service.ErrBackfillNoWarehouses),
+		errors.Is(err, service.ErrBackfillNoStagingFiles):
+		return &proto.WHBackfillResponse{}, status.Errorf(codes.Code(code.Code_NOT_FOUND), err.Error())
+	case err != nil:
+		backfillReq.API.log.Errorf(err.Error())
+		return &proto.WHBackfillResponse{}, status.Errorf(codes.Code(code.Code_INTERNAL), err.Error())
+	}
+	return backfillToProto(backfill), nil
+}
+
+// GetWHBackfill returns the backfill along with the progress of its uploads
+func (backfillStatusReq *BackfillStatusReq) GetWHBackfill(ctx context.Context) (*proto.WHBackfillResponse, error) {
+	err := backfillStatusReq.validateReq()
+	if err != nil {
+		return &proto.WHBackfillResponse{}, status.Errorf(codes.Code(code.Code_INVALID_ARGUMENT), err.Error())
+	}
+
+	backfill, err := backfillStatusReq.API.backfillService().Get(ctx, backfillStatusReq.BackfillID)
+	if errors.Is(err, model.ErrBackfillNotFound) {
+		return &proto.WHBackfillResponse{}, status.Errorf(codes.Code(code.Code_NOT_FOUND), "backfill not found")
+	}
+	if err != nil {
+		backfillStatusReq.API.log.Errorf(err.Error())
+		return &proto.WHBackfillResponse{}, status.Errorf(codes.Code(code.Code_INTERNAL), err.Error())
+	}
+
+	if backfill.WorkspaceID != backfillStatusReq.WorkspaceID {
+		pkgLogger.Errorf(`Unauthorized request for backfill:%d in workspaceId:%s`, backfillStatusReq.BackfillID, backfillStatusReq.WorkspaceID)
+		return &proto.WHBackfillResponse{}, status.Error(codes.Code(code.Code_UNAUTHENTICATED), "unauthorized request")
+	}
+	return backfillToProto(backfill), nil
+}
+
+func (uploadAPI UploadAPIT) backfillService() *service.Backfill {
+	db := uploadAPI.warehouseDBHandle.handle
+	return service.NewBackfill(
+		repo.NewBackfills(db),
+		repo.NewStagingFiles(db),
+		repo.NewUploads(db),
+		func(destType string) (service.WarehouseDeleter, error) {
+			return manager.NewWarehouseOperations(destType, config.Default, uploadAPI.log, stats.Default)
+		},
+		stagingFilesBatchSize,
+		uploadAPI.log,
+	)
+}
+
+func backfillToProto(backfill model.Backfill) *proto.WHBackfillResponse {
+	response := &proto.WHBackfillResponse{
+		Id:              backfill.ID,
+		SourceId:        backfill.SourceID,
+		DestinationId:   backfill.DestinationID,
+		Tables:          backfill.Tables,
+		EndTime:         timestamppb.New(backfill.EndTime),
+		DeleteRows:      backfill.DeleteRows,
+		Status:          backfill.CurrentStatus(),
+		Error:           backfill.Error,
+		UploadIds:       backfill.UploadIDs,
+		ExportedUploads: backfill.ExportedUploads,
+		AbortedUploads:  backfill.AbortedUploads,
+		CreatedAt:       timestamppb.New(backfill.CreatedAt),
+	}
+	if !backfill.StartTime.IsZero() {
+		response.StartTime = timestamppb.New(backfill.StartTime)
+	}
+	return response
+}
+
 func (tableUploadReq TableUploadReq) GetWhTableUploads(ctx context.Context) ([]*proto.WHTable, error) {
 	err := tableUploadReq.validateReq()
 	if err != nil {
 		return []*proto.WHTable{}, err
 	}
-	query := tableUploadReq.generateQuery(`id, wh_upload_id, table_name, total_events, status, error, last_exec_time, updated_at`)
+	query := tableUploadReq.generateQuery(`id, wh_upload_id, table_name, total_events, status, error, last_exec_time, updated_at, cost_queries, cost_bytes_scanned, cost_bytes_billed, cost_credits`)
 	tableUploadReq.API.log.Debug(query)
 	rows, err := tableUploadReq.API.dbHandle.QueryContext(ctx, query)
 	if err != nil {
@@ -434,6 +675,7 @@ func (tableUploadReq TableUploadReq) GetWhTableUploads(ctx context.Context) ([]*
 		var tableUpload proto.WHTable
 		var count sql.NullInt32
 		var lastExecTime, updatedAt sql.NullTime
+		var cost proto.WHLoadCost
 		err = rows.Scan(
 			&tableUpload.Id,
 			&tableUpload.UploadId,
@@ -443,6 +685,10 @@ func (tableUploadReq TableUploadReq) GetWhTableUploads(ctx context.Context) ([]*
 			&tableUpload.Error,
 			&lastExecTime,
 			&updatedAt,
+			&cost.Queries,
+			&cost.BytesScanned,
+			&cost.BytesBilled,
+			&cost.Credits,
 		)
 		if err != nil {
 			tableUploadReq.API.log.Errorf(err.Error())
@@ -463,6 +709,7 @@ func (tableUploadReq TableUploadReq) GetWhTableUploads(ctx context.Context) ([]*
 			tableUpload.LastExecAt = timestamppb.New(lastExecTime.Time)
 			tableUpload.Duration = int32(updatedAt.Time.Sub(lastExecTime.Time) / time.Second)
 		}
+		tableUpload.Cost = &cost
 		tableUploads = append(tableUploads, &tableUpload)
 	}
 	if err = rows.Err(); err != nil {
@@ -473,6 +720,18 @@ func (tableUploadReq TableUploadReq) GetWhTableUploads(ctx context.Context) ([]*
 	return tableUploads, nil
 }
 
+// uploadCost returns the cost of loading all the tables of the upload
+func uploadCost(tables []*proto.WHTable) *proto.WHLoadCost {
+	cost := &proto.WHLoadCost{}
+	for _, table := range tables {
+		cost.Queries += table.GetCost().GetQueries()
+		cost.BytesScanned += table.GetCost().GetBytesScanned()
+		cost.BytesBilled += table.GetCost().GetBytesBilled()
+		cost.Credits += table.GetCost().GetCredits()
+	}
+	return cost
+}
+
 func (tableUploadReq TableUploadReq) generateQuery(selectFields string) string {
 	query := fmt.Sprintf(`
 	SELECT
@@ -527,6 +786,26 @@ func (uploadReq *UploadReq) validateReq() error {
 	return nil
 }
 
+func (backfillReq *BackfillReq) validateReq() error {
+	if !backfillReq.API.enabled || backfillReq.API.log == nil || backfillReq.API.dbHandle == nil {
+		return errors.New("warehouse api are not initialized")
+	}
+	if backfillReq.DestinationID == "" {
+		return errors.New("destination_id is empty")
+	}
+	return nil
+}
+
+func (backfillStatusReq *BackfillStatusReq) validateReq() error {
+	if !backfillStatusReq.API.enabled || backfillStatusReq.API.log == nil || backfillStatusReq.API.dbHandle == nil {
+		return errors.New("warehouse api are not initialized")
+	}
+	if backfillStatusReq.BackfillID < 1 {
+		return errors.New("backfill_id is empty or should be greater than 0")
+	}
+	return nil
+}
+
 func (uploadReq *UploadReq) authorizeSource(sourceID string) bool {
 	var authorizedSourceIDs []string
 	var ok bool
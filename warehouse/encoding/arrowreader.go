@@ -0,0 +1,105 @@
+package encoding
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/apache/arrow/go/v12/arrow"
+	"github.com/apache/arrow/go/v12/arrow/array"
+	"github.com/apache/arrow/go/v12/arrow/ipc"
+	"github.com/apache/arrow/go/v12/arrow/memory"
+)
+
+// ArrowReader reads the rows of arrow IPC load files
+type ArrowReader struct {
+	*columnarRecordReader
+	file   *os.File
+	reader *ipc.FileReader
+}
+
+func NewArrowReader(filePath string) (*ArrowReader, error) {
+	file, err := os.Open(filePath)
+	if err != nil {
+		return nil, fmt.Errorf("opening file: %w", err)
+	}
+	reader, err := ipc.NewFileReader(file, ipc.WithAllocator(memory.DefaultAllocator))
+	if err != nil {
+		_ = file.Close()
+		return nil, fmt.Errorf("creating arrow reader: %w", err)
+	}
+
+	fields := reader.Schema().Fields()
+	fieldIndexes := make(map[string]int, len(fields))
+	fieldTypes := make([]columnarType, len(fields))
+	for i, field := range fields {
+		fieldIndexes[field.Name] = i
+		fieldTypes[i] = arrowColumnarType(field.Type)
+	}
+
+	r := &ArrowReader{
+		file:   file,
+		reader: reader,
+	}
+	r.columnarRecordReader = &columnarRecordReader{
+		fieldIndexes: fieldIndexes,
+		fieldTypes:   fieldTypes,
+		nextBatch: func() ([][]interface{}, int, error) {
+			record, err := reader.Read()
+			if err != nil {
+				return nil, 0, err
+			}
+			columns := make([][]interface{}, record.NumCols())
+			for i := range columns {
+				columns[i] = arrowColumnValues(record.Column(i))
+			}
+			return columns, int(record.NumRows()), nil
+		},
+	}
+	return r, nil
+}
+
+func (r *ArrowReader) Close() error {
+	_ = r.reader.Close()
+	return r.file.Close()
+}
+
+func arrowColumnarType(dataType arrow.DataType) columnarType {
+	switch dataType.ID() {
+	case arrow.INT64:
+		return columnarInt64
+	case arrow.FLOAT64:
+		return columnarDouble
+	case arrow.BOOL:
+		return columnarBoolean
+	case arrow.TIMESTAMP:
+		return columnarTimestamp
+	default:
+		return columnarString
+	}
+}
+
+// arrowColumnValues returns the values of the column as the go types of its columnar type, nil for nulls
+func arrowColumnValues(column arrow.Array) []interface{} {
+	values := make([]interface{}, column.Len())
+	for i := range values {
+		if column.IsNull(i) {
+			continue
+		}
+		switch c := column.(type) {
+		case *array.Int64:
+			values[i] = c.Value(i)
+		case *array.Float64:
+			values[i] = c.Value(i)
+		case *array.Boolean:
+			values[i] = c.Value(i)
+		case *array.Timestamp:
+			unit := c.DataType().(*arrow.TimestampType).Unit
+			values[i] = c.Value(i).ToTime(unit).UnixMicro()
+		case *array.String:
+			values[i] = c.Value(i)
+		default:
+			values[i] = fmt.Sprintf("%v", c.GetOneForMarshal(i))
+		}
+	}
+	return values
+}
@@ -0,0 +1,138 @@
+package encoding
+
+import (
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/apache/arrow/go/v12/arrow"
+	"github.com/apache/arrow/go/v12/arrow/array"
+	"github.com/apache/arrow/go/v12/arrow/ipc"
+	"github.com/apache/arrow/go/v12/arrow/memory"
+
+	"github.com/rudderlabs/rudder-server/utils/misc"
+	"github.com/rudderlabs/rudder-server/warehouse/internal/model"
+	warehouseutils "github.com/rudderlabs/rudder-server/warehouse/utils"
+)
+
+// arrowRecordBatchSize is the number of rows in each record batch of the arrow load files
+const arrowRecordBatchSize = 10000
+
+var columnarTypeToArrowDataType = map[columnarType]arrow.DataType{
+	columnarString:    arrow.BinaryTypes.String,
+	columnarInt64:     arrow.PrimitiveTypes.Int64,
+	columnarDouble:    arrow.PrimitiveTypes.Float64,
+	columnarBoolean:   arrow.FixedWidthTypes.Boolean,
+	columnarTimestamp: arrow.FixedWidthTypes.Timestamp_us,
+}
+
+// ArrowWriter writes arrow IPC load files, with the columns sorted by name
+type ArrowWriter struct {
+	writer      *ipc.FileWriter
+	builder     *array.RecordBuilder
+	fileWriter  misc.BufferedWriter
+	columnTypes []columnarType
+	rows        int
+}
+
+func CreateArrowWriter(schema model.TableSchema, outputFilePath, destType string) (*ArrowWriter, error) {
+	columns := getSortedTableColumns(schema)
+	fields := make([]arrow.Field, 0, len(columns))
+	columnTypes := make([]columnarType, 0, len(columns))
+	for _, col := range columns {
+		colType := getColumnarType(schema[col])
+		fields = append(fields, arrow.Field{
+			Name:     warehouseutils.ToProviderCase(destType, col),
+			Type:     columnarTypeToArrowDataType[colType],
+			Nullable: true,
+		})
+		columnTypes = append(columnTypes, colType)
+	}
+	arrowSchema := arrow.NewSchema(fields, nil)
+
+	bufWriter, err := misc.CreateBufferedWriter(outputFilePath)
+	if err != nil {
+		return nil, err
+	}
+	w, err := ipc.NewFileWriter(
+		&offsetWriter{w: bufWriter},
+		ipc.WithSchema(arrowSchema),
+		ipc.WithAllocator(memory.DefaultAllocator),
+	)
+	if err != nil {
+		_ = bufWriter.Close()
+		return nil, fmt.Errorf("creating arrow writer: %w", err)
+	}
+	return &ArrowWriter{
+		writer:      w,
+		builder:     array.NewRecordBuilder(memory.DefaultAllocator, arrowSchema),
+		fileWriter:  bufWriter,
+		columnTypes: columnTypes,
+	}, nil
+}
+
+func (a *ArrowWriter) WriteRow(row []interface{}) error {
+	if len(row) != len(a.columnTypes) {
+		return fmt.Errorf("row has %d values, expected %d", len(row), len(a.columnTypes))
+	}
+	for i, val := range row {
+		if val != nil {
+			val = getColumnarValue(val, a.columnTypes[i])
+		}
+		fieldBuilder := a.builder.Field(i)
+		if val == nil {
+			fieldBuilder.AppendNull()
+			continue
+		}
+		switch b := fieldBuilder.(type) {
+		case *array.Int64Builder:
+			b.Append(val.(int64))
+		case *array.Float64Builder:
+			b.Append(val.(float64))
+		case *array.BooleanBuilder:
+			b.Append(val.(bool))
+		case *array.TimestampBuilder:
+			b.Append(arrow.Timestamp(val.(int64)))
+		case *array.StringBuilder:
+			b.Append(val.(string))
+		}
+	}
+	a.rows++
+	if a.rows >= arrowRecordBatchSize {
+		return a.flush()
+	}
+	return nil
+}
+
+func (a *ArrowWriter) flush() error {
+	if a.rows == 0 {
+		return nil
+	}
+	record := a.builder.NewRecord()
+	defer record.Release()
+	a.rows = 0
+	return a.writer.Write(record)
+}
+
+func (a *ArrowWriter) Close() error {
+	defer a.builder.Release()
+	if err := a.flush(); err != nil {
+		return err
+	}
+	if err := a.writer.Close(); err != nil {
+		return err
+	}
+	return a.fileWriter.Close()
+}
+
+func (*ArrowWriter) WriteGZ(_ string) error {
+	return errors.New("not implemented")
+}
+
+func (*ArrowWriter) Write(_ []byte) (int, error) {
+	return 0, errors.New("not implemented")
+}
+
+func (a *ArrowWriter) GetLoadFile() *os.File {
+	return a.fileWriter.GetFile()
+}
@@ -0,0 +1,217 @@
+package encoding
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"time"
+
+	warehouseutils "github.com/rudderlabs/rudder-server/warehouse/utils"
+)
+
+// columnarType is the type of a column in the arrow and orc load files
+type columnarType int
+
+const (
+	columnarString columnarType = iota
+	columnarInt64
+	columnarDouble
+	columnarBoolean
+	// columnarTimestamp holds the microseconds since the unix epoch, same as parquet
+	columnarTimestamp
+)
+
+// getColumnarType returns the columnar type for the rudder data type.
+// Types without a columnar counterpart, e.g. json and the clickhouse arrays, are written as strings.
+func getColumnarType(dataType string) columnarType {
+	switch dataType {
+	case "bigint", "int":
+		return columnarInt64
+	case "float":
+		return columnarDouble
+	case "boolean":
+		return columnarBoolean
+	case "datetime":
+		return columnarTimestamp
+	default:
+		return columnarString
+	}
+}
+
+// ColumnarLoader is used for generating arrow and orc load files.
+type ColumnarLoader struct {
+	destType   string
+	Values     []interface{}
+	FileWriter LoadFileWriter
+}
+
+func NewColumnarLoader(destType string, w LoadFileWriter) *ColumnarLoader {
+	return &ColumnarLoader{
+		destType:   destType,
+		FileWriter: w,
+	}
+}
+
+func (loader *ColumnarLoader) IsLoadTimeColumn(columnName string) bool {
+	return columnName == warehouseutils.ToProviderCase(loader.destType, UUIDTsColumn)
+}
+
+func (*ColumnarLoader) GetLoadTimeFormat(string) string {
+	return time.RFC3339Nano
+}
+
+func (loader *ColumnarLoader) AddColumn(_, colType string, val interface{}) {
+	if val != nil {
+		val = getColumnarValue(val, getColumnarType(colType))
+	}
+	loader.Values = append(loader.Values, val)
+}
+
+func (loader *ColumnarLoader) AddRow(_, row []string) {
+	for _, val := range row {
+		loader.Values = append(loader.Values, val)
+	}
+}
+
+func (loader *ColumnarLoader) AddEmptyColumn(columnName string) {
+	loader.AddColumn(columnName, "", nil)
+}
+
+func (*ColumnarLoader) WriteToString() (string, error) {
+	return "", fmt.Errorf("not implemented")
+}
+
+func (loader *ColumnarLoader) Write() error {
+	return loader.FileWriter.WriteRow(loader.Values)
+}
+
+// getColumnarValue converts the value to the go type of the columnar type, returning nil if it cannot be converted.
+// Booleans are also accepted as 0 and 1, as they are sent for clickhouse.
+func getColumnarValue(val interface{}, colType columnarType) interface{} {
+	switch colType {
+	case columnarInt64:
+		switch v := val.(type) {
+		case int:
+			return int64(v)
+		case int64:
+			return v
+		case float64:
+			return int64(v)
+		}
+	case columnarDouble:
+		switch v := val.(type) {
+		case float64:
+			return v
+		case int:
+			return float64(v)
+		case int64:
+			return float64(v)
+		}
+	case columnarBoolean:
+		switch v := val.(type) {
+		case bool:
+			return v
+		case int:
+			return v != 0
+		}
+	case columnarTimestamp:
+		switch v := val.(type) {
+		case int64:
+			return v
+		case string:
+			if t, err := time.Parse(time.RFC3339Nano, v); err == nil {
+				return t.UnixMicro()
+			}
+		}
+	case columnarString:
+		if v, ok := val.(string); ok {
+			return v
+		}
+		return fmt.Sprintf("%v", val)
+	}
+	return nil
+}
+
+// formatColumnarValue formats the value read from a columnar load file as it would be in a csv load file
+func formatColumnarValue(val interface{}, colType columnarType) string {
+	if val == nil {
+		return ""
+	}
+	switch colType {
+	case columnarInt64:
+		return strconv.FormatInt(val.(int64), 10)
+	case columnarDouble:
+		return strconv.FormatFloat(val.(float64), 'f', -1, 64)
+	case columnarBoolean:
+		return strconv.FormatBool(val.(bool))
+	case columnarTimestamp:
+		return time.UnixMicro(val.(int64)).UTC().Format(time.RFC3339Nano)
+	default:
+		return val.(string)
+	}
+}
+
+// columnarRecordReader reads the rows of a columnar load file, one batch of columns at a time
+type columnarRecordReader struct {
+	fieldIndexes map[string]int
+	fieldTypes   []columnarType
+	// nextBatch returns the columns of the next batch of rows along with the number of rows, or io.EOF
+	nextBatch func() ([][]interface{}, int, error)
+
+	columns [][]interface{}
+	numRows int
+	row     int
+}
+
+// Read returns the next row with the values of the columns, or of all the columns in the load file if none are given
+func (r *columnarRecordReader) Read(columnNames []string) ([]string, error) {
+	if len(columnNames) == 0 {
+		columnNames = r.columnNames()
+	}
+	for r.row >= r.numRows {
+		columns, numRows, err := r.nextBatch()
+		if err != nil {
+			return nil, err
+		}
+		r.columns, r.numRows, r.row = columns, numRows, 0
+	}
+
+	record := make([]string, len(columnNames))
+	for i, columnName := range columnNames {
+		if index, ok := r.fieldIndexes[columnName]; ok {
+			record[i] = formatColumnarValue(r.columns[index][r.row], r.fieldTypes[index])
+		}
+	}
+	r.row++
+	return record, nil
+}
+
+// columnNames returns the names of the columns in the load file, in order
+func (r *columnarRecordReader) columnNames() []string {
+	columnNames := make([]string, len(r.fieldIndexes))
+	for columnName, index := range r.fieldIndexes {
+		columnNames[index] = columnName
+	}
+	return columnNames
+}
+
+// offsetWriter keeps track of the offset in the load file being written, so that it can be used by the columnar writers
+// which only need to know about their current position, without seeking the underlying buffered writer.
+type offsetWriter struct {
+	w      io.Writer
+	offset int64
+}
+
+func (w *offsetWriter) Write(p []byte) (int, error) {
+	n, err := w.w.Write(p)
+	w.offset += int64(n)
+	return n, err
+}
+
+func (w *offsetWriter) Seek(offset int64, whence int) (int64, error) {
+	if offset != 0 || whence != io.SeekCurrent {
+		return 0, errors.New("only seeking the current offset is supported")
+	}
+	return w.offset, nil
+}
@@ -0,0 +1,126 @@
+package encoding
+
+import (
+	"compress/gzip"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/rudderlabs/rudder-server/utils/misc"
+	"github.com/rudderlabs/rudder-server/warehouse/internal/model"
+	warehouseutils "github.com/rudderlabs/rudder-server/warehouse/utils"
+)
+
+// LoadFileReader reads the rows of a load file, as the string values they would have in a csv load file
+type LoadFileReader interface {
+	EventReader
+	Close() error
+}
+
+// Encoder creates the writers, event loaders and readers for a load file type
+type Encoder struct {
+	NewWriter      func(schema model.TableSchema, outputFilePath, destType string) (LoadFileWriter, error)
+	NewEventLoader func(destType string, w LoadFileWriter) EventLoader
+	// NewReader is optional, for the load file types which are read back by the warehouse, e.g. clickhouse
+	NewReader func(filePath string) (LoadFileReader, error)
+}
+
+var encoders = map[string]Encoder{
+	warehouseutils.LoadFileTypeCsv: {
+		NewWriter:      newGZWriter,
+		NewEventLoader: func(destType string, w LoadFileWriter) EventLoader { return NewCSVLoader(destType, w) },
+		NewReader: func(filePath string) (LoadFileReader, error) {
+			return newGZReader(filePath, func(r io.Reader) EventReader { return NewCsvReader(r) })
+		},
+	},
+	warehouseutils.LoadFileTypeJson: {
+		NewWriter:      newGZWriter,
+		NewEventLoader: func(destType string, w LoadFileWriter) EventLoader { return NewJSONLoader(destType, w) },
+		NewReader: func(filePath string) (LoadFileReader, error) {
+			return newGZReader(filePath, func(r io.Reader) EventReader { return NewJSONReader(r) })
+		},
+	},
+	warehouseutils.LoadFileTypeParquet: {
+		NewWriter: func(schema model.TableSchema, outputFilePath, destType string) (LoadFileWriter, error) {
+			return CreateParquetWriter(schema, outputFilePath, destType)
+		},
+		NewEventLoader: func(destType string, w LoadFileWriter) EventLoader { return NewParquetLoader(destType, w) },
+	},
+	warehouseutils.LoadFileTypeArrow: {
+		NewWriter: func(schema model.TableSchema, outputFilePath, destType string) (LoadFileWriter, error) {
+			return CreateArrowWriter(schema, outputFilePath, destType)
+		},
+		NewEventLoader: func(destType string, w LoadFileWriter) EventLoader { return NewColumnarLoader(destType, w) },
+		NewReader: func(filePath string) (LoadFileReader, error) {
+			return NewArrowReader(filePath)
+		},
+	},
+	warehouseutils.LoadFileTypeOrc: {
+		NewWriter: func(schema model.TableSchema, outputFilePath, destType string) (LoadFileWriter, error) {
+			return CreateOrcWriter(schema, outputFilePath, destType)
+		},
+		NewEventLoader: func(destType string, w LoadFileWriter) EventLoader { return NewColumnarLoader(destType, w) },
+		NewReader: func(filePath string) (LoadFileReader, error) {
+			return NewOrcReader(filePath)
+		},
+	},
+}
+
+// Register registers the encoder for the load file type, replacing the existing one if any.
+// It is not safe to be called concurrently with the encoders being used.
+func Register(loadFileType string, encoder Encoder) {
+	encoders[loadFileType] = encoder
+}
+
+// NewLoadFileWriter creates the writer for a load file of the load file type, csv being the default
+func NewLoadFileWriter(loadFileType string, schema model.TableSchema, outputFilePath, destType string) (LoadFileWriter, error) {
+	return getEncoder(loadFileType).NewWriter(schema, outputFilePath, destType)
+}
+
+// NewLoadFileReader creates the reader for a load file of the load file type, csv being the default
+func NewLoadFileReader(loadFileType, filePath string) (LoadFileReader, error) {
+	encoder := getEncoder(loadFileType)
+	if encoder.NewReader == nil {
+		return nil, fmt.Errorf("reading %s load files is not supported", loadFileType)
+	}
+	return encoder.NewReader(filePath)
+}
+
+func getEncoder(loadFileType string) Encoder {
+	if encoder, ok := encoders[loadFileType]; ok {
+		return encoder
+	}
+	return encoders[warehouseutils.LoadFileTypeCsv]
+}
+
+func newGZWriter(_ model.TableSchema, outputFilePath, _ string) (LoadFileWriter, error) {
+	return misc.CreateGZ(outputFilePath)
+}
+
+type gzReader struct {
+	EventReader
+	file       *os.File
+	gzipReader *gzip.Reader
+}
+
+func newGZReader(filePath string, newEventReader func(r io.Reader) EventReader) (*gzReader, error) {
+	file, err := os.Open(filePath)
+	if err != nil {
+		return nil, fmt.Errorf("opening file: %w", err)
+	}
+	gzipReader, err := gzip.NewReader(file)
+	if err != nil {
+		_ = file.Close()
+		return nil, fmt.Errorf("creating gzip reader: %w", err)
+	}
+	return &gzReader{
+		EventReader: newEventReader(gzipReader),
+		file:        file,
+		gzipReader:  gzipReader,
+	}, nil
+}
+
+func (r *gzReader) Close() error {
+	_ = r.gzipReader.Close()
+	return r.file.Close()
+}
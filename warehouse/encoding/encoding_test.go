@@ -1,10 +1,16 @@
 package encoding_test
 
 import (
+	"bytes"
 	"compress/gzip"
+	"encoding/json"
 	"errors"
 	"fmt"
+	"io"
 	"os"
+	"os/exec"
+	"sort"
+	"strconv"
 	"strings"
 	"testing"
 	"time"
@@ -286,3 +292,304 @@ func TestReaderLoader(t *testing.T) {
 		})
 	})
 }
+
+func TestColumnarRoundTrip(t *testing.T) {
+	misc.Init()
+	encoding.Init()
+
+	schema := model.TableSchema{
+		"array":    "array(int)",
+		"bigint":   "bigint",
+		"boolean":  "boolean",
+		"datetime": "datetime",
+		"float":    "float",
+		"int":      "int",
+		"json":     "json",
+		"string":   "string",
+		"text":     "text",
+	}
+	columns := lo.Keys(schema)
+	sort.Strings(columns)
+
+	// spanning multiple record batches and stripes, with timestamps before the unix epoch as well
+	lines := 25000
+	baseTime := time.Date(1969, 12, 31, 23, 59, 59, 500000000, time.UTC)
+
+	testCases := []struct {
+		name         string
+		destType     string
+		loadFileType string
+		// booleans are sent as 0 and 1 for clickhouse
+		booleanValue func(bool) interface{}
+	}{
+		{
+			name:         "Arrow",
+			destType:     warehouseutils.CLICKHOUSE,
+			loadFileType: warehouseutils.LoadFileTypeArrow,
+			booleanValue: func(b bool) interface{} { return lo.Ternary(b, 1, 0) },
+		},
+		{
+			name:         "ORC",
+			destType:     warehouseutils.S3Datalake,
+			loadFileType: warehouseutils.LoadFileTypeOrc,
+			booleanValue: func(b bool) interface{} { return b },
+		},
+	}
+	for _, tc := range testCases {
+		tc := tc
+
+		t.Run(tc.name, func(t *testing.T) {
+			outputFilePath := fmt.Sprintf("/tmp/%s.%s", uuid.New().String(), warehouseutils.GetLoadFileFormat(tc.loadFileType))
+
+			writer, err := encoding.NewLoadFileWriter(tc.loadFileType, schema, outputFilePath, tc.destType)
+			require.NoError(t, err)
+
+			t.Cleanup(func() {
+				require.NoError(t, os.Remove(outputFilePath))
+			})
+
+			expected := make([][]string, 0, lines)
+			for i := 0; i < lines; i++ {
+				c := encoding.GetNewEventLoader(tc.destType, tc.loadFileType, writer)
+
+				if i%7 == 0 {
+					for _, column := range columns {
+						c.AddEmptyColumn(column)
+					}
+					require.NoError(t, c.Write())
+					expected = append(expected, make([]string, len(columns)))
+					continue
+				}
+
+				bigint := int64(i) * 1000003
+				if i%2 == 0 {
+					bigint = -bigint
+				}
+				datetime := baseTime.Add(time.Duration(i)*37*time.Hour + time.Duration(i%1000)*time.Microsecond)
+				str := lo.Ternary(i%11 == 0, "", fmt.Sprintf("value-%d", i))
+
+				c.AddColumn("array", "array(int)", "[1,2,3]")
+				c.AddColumn("bigint", "bigint", bigint)
+				c.AddColumn("boolean", "boolean", tc.booleanValue(i%3 == 0))
+				c.AddColumn("datetime", "datetime", datetime.Format(time.RFC3339Nano))
+				c.AddColumn("float", "float", float64(i)+0.125)
+				c.AddColumn("int", "int", i/10)
+				c.AddColumn("json", "json", fmt.Sprintf(`{"key":%d}`, i))
+				c.AddColumn("string", "string", str)
+				c.AddColumn("text", "text", "RudderStack")
+				require.NoError(t, c.Write())
+
+				expected = append(expected, []string{
+					"[1,2,3]",
+					strconv.FormatInt(bigint, 10),
+					strconv.FormatBool(i%3 == 0),
+					datetime.Format(time.RFC3339Nano),
+					strconv.FormatFloat(float64(i)+0.125, 'f', -1, 64),
+					strconv.Itoa(i / 10),
+					fmt.Sprintf(`{"key":%d}`, i),
+					str,
+					"RudderStack",
+				})
+			}
+			require.NoError(t, writer.Close())
+
+			t.Run("all columns", func(t *testing.T) {
+				r, err := encoding.NewLoadFileReader(tc.loadFileType, outputFilePath)
+				require.NoError(t, err)
+				defer func() { require.NoError(t, r.Close()) }()
+
+				for i := 0; i < lines; i++ {
+					record, err := r.Read(nil)
+					require.NoError(t, err)
+					require.Equal(t, expected[i], record, "row %d", i)
+				}
+				_, err = r.Read(nil)
+				require.ErrorIs(t, err, io.EOF)
+			})
+
+			t.Run("some columns", func(t *testing.T) {
+				r, err := encoding.NewLoadFileReader(tc.loadFileType, outputFilePath)
+				require.NoError(t, err)
+				defer func() { require.NoError(t, r.Close()) }()
+
+				record, err := r.Read([]string{"text", "missing", "bigint"})
+				require.NoError(t, err)
+				require.Equal(t, []string{"", "", ""}, record)
+
+				record, err = r.Read([]string{"text", "missing", "bigint"})
+				require.NoError(t, err)
+				require.Equal(t, []string{"RudderStack", "", "1000003"}, record)
+			})
+
+			t.Run("time column", func(t *testing.T) {
+				c := encoding.GetNewEventLoader(tc.destType, tc.loadFileType, writer)
+				require.Equal(t, time.RFC3339Nano, c.GetLoadTimeFormat(encoding.UUIDTsColumn))
+				require.True(t, c.IsLoadTimeColumn(encoding.UUIDTsColumn))
+				require.False(t, c.IsLoadTimeColumn("default"))
+			})
+		})
+	}
+
+	t.Run("empty", func(t *testing.T) {
+		for _, loadFileType := range []string{warehouseutils.LoadFileTypeArrow, warehouseutils.LoadFileTypeOrc} {
+			outputFilePath := fmt.Sprintf("/tmp/%s.%s", uuid.New().String(), loadFileType)
+
+			writer, err := encoding.NewLoadFileWriter(loadFileType, schema, outputFilePath, warehouseutils.CLICKHOUSE)
+			require.NoError(t, err)
+			require.NoError(t, writer.Close())
+
+			r, err := encoding.NewLoadFileReader(loadFileType, outputFilePath)
+			require.NoError(t, err)
+			_, err = r.Read(nil)
+			require.ErrorIs(t, err, io.EOF)
+			require.NoError(t, r.Close())
+			require.NoError(t, os.Remove(outputFilePath))
+		}
+	})
+
+	t.Run("unsupported reader", func(t *testing.T) {
+		_, err := encoding.NewLoadFileReader(warehouseutils.LoadFileTypeParquet, "")
+		require.EqualError(t, err, "reading parquet load files is not supported")
+	})
+}
+
+// orcInteropScript writes the rows read from stdin into an orc file with pyarrow, or prints the rows of an orc file
+// read with pyarrow, as json
+const orcInteropScript = `
+import datetime, json, sys
+import pyarrow as pa, pyarrow.orc as orc
+
+mode, path = sys.argv[1], sys.argv[2]
+if mode == "write":
+    rows = json.load(sys.stdin)
+    parse = lambda v: v if v is None else datetime.datetime.strptime(v, "%Y-%m-%dT%H:%M:%S.%fZ")
+    table = pa.table({
+        "bigint": pa.array([r["bigint"] for r in rows], pa.int64()),
+        "boolean": pa.array([r["boolean"] for r in rows], pa.bool_()),
+        "datetime": pa.array([parse(r["datetime"]) for r in rows], pa.timestamp("us")),
+        "float": pa.array([r["float"] for r in rows], pa.float64()),
+        "string": pa.array([r["string"] for r in rows], pa.string()),
+    })
+    orc.write_table(table, path, file_version="0.11", compression="uncompressed")
+else:
+    rows = orc.read_table(path).to_pylist()
+    for r in rows:
+        if r["datetime"] is not None:
+            r["datetime"] = r["datetime"].strftime("%Y-%m-%dT%H:%M:%S.%fZ")
+    json.dump(rows, sys.stdout)
+`
+
+// TestOrcInterop checks that the orc load files are read by pyarrow, and that the orc files written by pyarrow are read
+func TestOrcInterop(t *testing.T) {
+	if err := exec.Command("python3", "-c", "import pyarrow.orc").Run(); err != nil {
+		t.Skip("Skipping tests. Install pyarrow to run test.")
+	}
+
+	misc.Init()
+	encoding.Init()
+
+	type row struct {
+		BigInt   *int64   `json:"bigint"`
+		Boolean  *bool    `json:"boolean"`
+		Datetime *string  `json:"datetime"`
+		Float    *float64 `json:"float"`
+		String   *string  `json:"string"`
+	}
+
+	schema := model.TableSchema{
+		"bigint":   "bigint",
+		"boolean":  "boolean",
+		"datetime": "datetime",
+		"float":    "float",
+		"string":   "string",
+	}
+
+	// with nulls and timestamps before the unix epoch as well
+	baseTime := time.Date(1969, 12, 31, 23, 59, 59, 500000000, time.UTC)
+	rows := make([]row, 0, 100)
+	for i := 0; i < 100; i++ {
+		if i%7 == 0 {
+			rows = append(rows, row{})
+			continue
+		}
+		bigint := int64(i) * 1000003
+		if i%2 == 0 {
+			bigint = -bigint
+		}
+		rows = append(rows, row{
+			BigInt:   lo.ToPtr(bigint),
+			Boolean:  lo.ToPtr(i%3 == 0),
+			Datetime: lo.ToPtr(baseTime.Add(time.Duration(i)*37*time.Hour + time.Duration(i)*time.Microsecond).Format("2006-01-02T15:04:05.000000Z")),
+			Float:    lo.ToPtr(float64(i) + 0.125),
+			String:   lo.ToPtr(lo.Ternary(i%11 == 0, "", fmt.Sprintf("value-%d", i))),
+		})
+	}
+
+	t.Run("read by pyarrow", func(t *testing.T) {
+		outputFilePath := fmt.Sprintf("%s/%s.orc", t.TempDir(), uuid.New().String())
+
+		writer, err := encoding.NewLoadFileWriter(warehouseutils.LoadFileTypeOrc, schema, outputFilePath, warehouseutils.S3Datalake)
+		require.NoError(t, err)
+
+		for _, r := range rows {
+			c := encoding.GetNewEventLoader(warehouseutils.S3Datalake, warehouseutils.LoadFileTypeOrc, writer)
+			if r.BigInt == nil {
+				for _, column := range []string{"bigint", "boolean", "datetime", "float", "string"} {
+					c.AddEmptyColumn(column)
+				}
+			} else {
+				c.AddColumn("bigint", "bigint", *r.BigInt)
+				c.AddColumn("boolean", "boolean", *r.Boolean)
+				c.AddColumn("datetime", "datetime", *r.Datetime)
+				c.AddColumn("float", "float", *r.Float)
+				c.AddColumn("string", "string", *r.String)
+			}
+			require.NoError(t, c.Write())
+		}
+		require.NoError(t, writer.Close())
+
+		output, err := exec.Command("python3", "-c", orcInteropScript, "read", outputFilePath).Output()
+		require.NoError(t, err)
+
+		var read []row
+		require.NoError(t, json.Unmarshal(output, &read))
+		require.Equal(t, rows, read)
+	})
+
+	t.Run("written by pyarrow", func(t *testing.T) {
+		outputFilePath := fmt.Sprintf("%s/%s.orc", t.TempDir(), uuid.New().String())
+
+		input, err := json.Marshal(rows)
+		require.NoError(t, err)
+
+		cmd := exec.Command("python3", "-c", orcInteropScript, "write", outputFilePath)
+		cmd.Stdin = bytes.NewReader(input)
+		require.NoError(t, cmd.Run())
+
+		r, err := encoding.NewLoadFileReader(warehouseutils.LoadFileTypeOrc, outputFilePath)
+		require.NoError(t, err)
+		defer func() { require.NoError(t, r.Close()) }()
+
+		for i, expected := range rows {
+			record, err := r.Read([]string{"bigint", "boolean", "datetime", "float", "string"})
+			require.NoError(t, err)
+
+			if expected.BigInt == nil {
+				require.Equal(t, make([]string, 5), record, "row %d", i)
+				continue
+			}
+			datetime, err := time.Parse(time.RFC3339Nano, *expected.Datetime)
+			require.NoError(t, err)
+
+			require.Equal(t, []string{
+				strconv.FormatInt(*expected.BigInt, 10),
+				strconv.FormatBool(*expected.Boolean),
+				datetime.Format(time.RFC3339Nano),
+				strconv.FormatFloat(*expected.Float, 'f', -1, 64),
+				*expected.String,
+			}, record, "row %d", i)
+		}
+		_, err = r.Read(nil)
+		require.ErrorIs(t, err, io.EOF)
+	})
+}
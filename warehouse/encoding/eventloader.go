@@ -1,7 +1,5 @@
 package encoding
 
-import warehouseutils "github.com/rudderlabs/rudder-server/warehouse/utils"
-
 type EventLoader interface {
 	IsLoadTimeColumn(columnName string) bool
 	GetLoadTimeFormat(columnName string) string
@@ -13,12 +11,5 @@ type EventLoader interface {
 }
 
 func GetNewEventLoader(destinationType, loadFileType string, w LoadFileWriter) EventLoader {
-	switch loadFileType {
-	case warehouseutils.LoadFileTypeJson:
-		return NewJSONLoader(destinationType, w)
-	case warehouseutils.LoadFileTypeParquet:
-		return NewParquetLoader(destinationType, w)
-	default:
-		return NewCSVLoader(destinationType, w)
-	}
+	return getEncoder(loadFileType).NewEventLoader(destinationType, w)
 }
@@ -0,0 +1,328 @@
+package encoding
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+// The orc load files are written uncompressed, with the DIRECT (run length encoding v1) column encodings,
+// which are supported by every orc reader. Only the parts of the orc format used by the load files are implemented,
+// see https://orc.apache.org/specification/ORCv1/
+
+const (
+	orcMagic = "ORC"
+
+	orcCompressionNone = 0
+
+	orcColumnEncodingDirect = 0
+)
+
+// orc type kinds
+const (
+	orcKindBoolean   = 0
+	orcKindInt       = 3
+	orcKindLong      = 4
+	orcKindDouble    = 6
+	orcKindString    = 7
+	orcKindTimestamp = 9
+	orcKindStruct    = 12
+	orcKindVarchar   = 16
+)
+
+// orc stream kinds
+const (
+	orcStreamPresent   = 0
+	orcStreamData      = 1
+	orcStreamLength    = 2
+	orcStreamSecondary = 5
+)
+
+// orcTimestampBase is the base of the seconds of the orc timestamps
+var orcTimestampBase = time.Date(2015, 1, 1, 0, 0, 0, 0, time.UTC).Unix()
+
+var columnarTypeToOrcKind = map[columnarType]uint64{
+	columnarString:    orcKindString,
+	columnarInt64:     orcKindLong,
+	columnarDouble:    orcKindDouble,
+	columnarBoolean:   orcKindBoolean,
+	columnarTimestamp: orcKindTimestamp,
+}
+
+var errOrcCorrupted = errors.New("corrupted orc file")
+
+// appendOrcVarintField appends the varint field to the protobuf message
+func appendOrcVarintField(b []byte, num protowire.Number, v uint64) []byte {
+	b = protowire.AppendTag(b, num, protowire.VarintType)
+	return protowire.AppendVarint(b, v)
+}
+
+// appendOrcBytesField appends the bytes field, e.g. an embedded message, to the protobuf message
+func appendOrcBytesField(b []byte, num protowire.Number, v []byte) []byte {
+	b = protowire.AppendTag(b, num, protowire.BytesType)
+	return protowire.AppendBytes(b, v)
+}
+
+// consumeOrcFields calls fn for the varint and bytes fields of the protobuf message, skipping the other ones
+func consumeOrcFields(b []byte, fn func(num protowire.Number, v uint64, bytes []byte, isBytes bool) error) error {
+	for len(b) > 0 {
+		num, typ, n := protowire.ConsumeTag(b)
+		if n < 0 {
+			return fmt.Errorf("%w: %v", errOrcCorrupted, protowire.ParseError(n))
+		}
+		b = b[n:]
+
+		var err error
+		switch typ {
+		case protowire.VarintType:
+			var v uint64
+			v, n = protowire.ConsumeVarint(b)
+			if n >= 0 {
+				err = fn(num, v, nil, false)
+			}
+		case protowire.BytesType:
+			var v []byte
+			v, n = protowire.ConsumeBytes(b)
+			if n >= 0 {
+				err = fn(num, 0, v, true)
+			}
+		default:
+			n = protowire.ConsumeFieldValue(num, typ, b)
+		}
+		if n < 0 {
+			return fmt.Errorf("%w: %v", errOrcCorrupted, protowire.ParseError(n))
+		}
+		if err != nil {
+			return err
+		}
+		b = b[n:]
+	}
+	return nil
+}
+
+// consumeOrcRepeatedVarint returns the values of a repeated varint field, which can be either packed or not
+func consumeOrcRepeatedVarint(v uint64, bytes []byte, isBytes bool) ([]uint64, error) {
+	if !isBytes {
+		return []uint64{v}, nil
+	}
+	var values []uint64
+	for len(bytes) > 0 {
+		v, n := protowire.ConsumeVarint(bytes)
+		if n < 0 {
+			return nil, fmt.Errorf("%w: %v", errOrcCorrupted, protowire.ParseError(n))
+		}
+		values = append(values, v)
+		bytes = bytes[n:]
+	}
+	return values, nil
+}
+
+// encodeOrcByteRLE encodes the bytes using runs of 3 to 130 repeated bytes, or up to 128 literals
+func encodeOrcByteRLE(values []byte) []byte {
+	var (
+		out      []byte
+		literals []byte
+	)
+	flushLiterals := func() {
+		for len(literals) > 0 {
+			n := len(literals)
+			if n > 128 {
+				n = 128
+			}
+			out = append(out, byte(-n))
+			out = append(out, literals[:n]...)
+			literals = literals[n:]
+		}
+	}
+	for i := 0; i < len(values); {
+		run := 1
+		for i+run < len(values) && run < 130 && values[i+run] == values[i] {
+			run++
+		}
+		if run < 3 {
+			literals = append(literals, values[i])
+			i++
+			continue
+		}
+		flushLiterals()
+		out = append(out, byte(run-3), values[i])
+		i += run
+	}
+	flushLiterals()
+	return out
+}
+
+func decodeOrcByteRLE(b []byte, count int) ([]byte, error) {
+	values := make([]byte, 0, count)
+	for len(values) < count {
+		if len(b) < 2 {
+			return nil, errOrcCorrupted
+		}
+		header := int8(b[0])
+		if header >= 0 {
+			for i := 0; i < int(header)+3; i++ {
+				values = append(values, b[1])
+			}
+			b = b[2:]
+			continue
+		}
+		n := -int(header)
+		if len(b) < n+1 {
+			return nil, errOrcCorrupted
+		}
+		values = append(values, b[1:n+1]...)
+		b = b[n+1:]
+	}
+	return values[:count], nil
+}
+
+// encodeOrcBooleanRLE packs the booleans into bytes, most significant bit first, which are then byte run length encoded
+func encodeOrcBooleanRLE(values []bool) []byte {
+	packed := make([]byte, (len(values)+7)/8)
+	for i, v := range values {
+		if v {
+			packed[i/8] |= 1 << (7 - i%8)
+		}
+	}
+	return encodeOrcByteRLE(packed)
+}
+
+func decodeOrcBooleanRLE(b []byte, count int) ([]bool, error) {
+	packed, err := decodeOrcByteRLE(b, (count+7)/8)
+	if err != nil {
+		return nil, err
+	}
+	values := make([]bool, count)
+	for i := range values {
+		values[i] = packed[i/8]&(1<<(7-i%8)) != 0
+	}
+	return values, nil
+}
+
+// encodeOrcIntRLE encodes the integers using runs of 3 to 130 values with a fixed delta, or up to 128 literals.
+// Signed integers are zigzag encoded.
+func encodeOrcIntRLE(values []int64, signed bool) []byte {
+	var (
+		out      []byte
+		literals []int64
+	)
+	appendValue := func(v int64) {
+		if signed {
+			out = protowire.AppendVarint(out, protowire.EncodeZigZag(v))
+		} else {
+			out = protowire.AppendVarint(out, uint64(v))
+		}
+	}
+	flushLiterals := func() {
+		for len(literals) > 0 {
+			n := len(literals)
+			if n > 128 {
+				n = 128
+			}
+			out = append(out, byte(-n))
+			for _, v := range literals[:n] {
+				appendValue(v)
+			}
+			literals = literals[n:]
+		}
+	}
+	for i := 0; i < len(values); {
+		run, delta := 1, int64(0)
+		if i+1 < len(values) {
+			delta = values[i+1] - values[i]
+			if delta >= -128 && delta <= 127 {
+				run = 2
+				for i+run < len(values) && run < 130 && values[i+run]-values[i+run-1] == delta {
+					run++
+				}
+			}
+		}
+		if run < 3 {
+			literals = append(literals, values[i])
+			i++
+			continue
+		}
+		flushLiterals()
+		out = append(out, byte(run-3), byte(int8(delta)))
+		appendValue(values[i])
+		i += run
+	}
+	flushLiterals()
+	return out
+}
+
+func decodeOrcIntRLE(b []byte, count int, signed bool) ([]int64, error) {
+	values := make([]int64, 0, count)
+	consumeValue := func() (int64, error) {
+		v, n := protowire.ConsumeVarint(b)
+		if n < 0 {
+			return 0, errOrcCorrupted
+		}
+		b = b[n:]
+		if signed {
+			return protowire.DecodeZigZag(v), nil
+		}
+		return int64(v), nil
+	}
+	for len(values) < count {
+		if len(b) < 1 {
+			return nil, errOrcCorrupted
+		}
+		header := int8(b[0])
+		b = b[1:]
+		if header >= 0 {
+			if len(b) < 1 {
+				return nil, errOrcCorrupted
+			}
+			delta := int64(int8(b[0]))
+			b = b[1:]
+			base, err := consumeValue()
+			if err != nil {
+				return nil, err
+			}
+			for i := 0; i < int(header)+3; i++ {
+				values = append(values, base+int64(i)*delta)
+			}
+			continue
+		}
+		for i := 0; i < -int(header); i++ {
+			v, err := consumeValue()
+			if err != nil {
+				return nil, err
+			}
+			values = append(values, v)
+		}
+	}
+	return values[:count], nil
+}
+
+// encodeOrcNanos encodes the nanoseconds of a timestamp, with the number of removed trailing zeros
+// minus one in the 3 least significant bits if there are at least 2 of them.
+func encodeOrcNanos(nanos int64) int64 {
+	if nanos == 0 {
+		return 0
+	}
+	if nanos%100 != 0 {
+		return nanos << 3
+	}
+	nanos /= 100
+	zeros := int64(1)
+	for nanos%10 == 0 && zeros < 7 {
+		nanos /= 10
+		zeros++
+	}
+	return nanos<<3 | zeros
+}
+
+func decodeOrcNanos(v int64) int64 {
+	nanos := v >> 3
+	zeros := v & 7
+	if zeros != 0 {
+		for i := int64(0); i <= zeros; i++ {
+			nanos *= 10
+		}
+	}
+	return nanos
+}
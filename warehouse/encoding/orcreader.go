@@ -0,0 +1,366 @@
+package encoding
+
+import (
+	"encoding/binary"
+	"fmt"
+	"io"
+	"math"
+	"os"
+
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+// OrcReader reads the rows of uncompressed orc load files with DIRECT column encodings, as written by OrcWriter
+type OrcReader struct {
+	*columnarRecordReader
+	file    *os.File
+	stripes []orcStripe
+	stripe  int
+}
+
+type orcStripe struct {
+	offset, indexLength, dataLength, footerLength, numberOfRows uint64
+}
+
+func NewOrcReader(filePath string) (*OrcReader, error) {
+	file, err := os.Open(filePath)
+	if err != nil {
+		return nil, fmt.Errorf("opening file: %w", err)
+	}
+	r := &OrcReader{file: file}
+	if err := r.readTail(); err != nil {
+		_ = file.Close()
+		return nil, fmt.Errorf("reading orc file tail: %w", err)
+	}
+	return r, nil
+}
+
+func (r *OrcReader) Close() error {
+	return r.file.Close()
+}
+
+func (r *OrcReader) readAt(offset, length uint64) ([]byte, error) {
+	b := make([]byte, length)
+	if _, err := r.file.ReadAt(b, int64(offset)); err != nil {
+		return nil, err
+	}
+	return b, nil
+}
+
+// readTail reads the postscript and the file footer, with the stripes and the columns of the file
+func (r *OrcReader) readTail() error {
+	info, err := r.file.Stat()
+	if err != nil {
+		return err
+	}
+	size := uint64(info.Size())
+	if size < uint64(len(orcMagic))+1 {
+		return errOrcCorrupted
+	}
+	psLength, err := r.readAt(size-1, 1)
+	if err != nil {
+		return err
+	}
+	postscriptOffset := size - 1 - uint64(psLength[0])
+	postscript, err := r.readAt(postscriptOffset, uint64(psLength[0]))
+	if err != nil {
+		return err
+	}
+
+	var footerLength, compression uint64
+	err = consumeOrcFields(postscript, func(num protowire.Number, v uint64, _ []byte, _ bool) error {
+		switch num {
+		case 1:
+			footerLength = v
+		case 2:
+			compression = v
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	if compression != orcCompressionNone {
+		return fmt.Errorf("unsupported orc compression kind %d", compression)
+	}
+	if footerLength > postscriptOffset {
+		return errOrcCorrupted
+	}
+	footer, err := r.readAt(postscriptOffset-footerLength, footerLength)
+	if err != nil {
+		return err
+	}
+
+	var types [][]byte
+	err = consumeOrcFields(footer, func(num protowire.Number, _ uint64, b []byte, _ bool) error {
+		switch num {
+		case 3:
+			var stripe orcStripe
+			err := consumeOrcFields(b, func(num protowire.Number, v uint64, _ []byte, _ bool) error {
+				switch num {
+				case 1:
+					stripe.offset = v
+				case 2:
+					stripe.indexLength = v
+				case 3:
+					stripe.dataLength = v
+				case 4:
+					stripe.footerLength = v
+				case 5:
+					stripe.numberOfRows = v
+				}
+				return nil
+			})
+			if err != nil {
+				return err
+			}
+			r.stripes = append(r.stripes, stripe)
+		case 4:
+			types = append(types, b)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	return r.readTypes(types)
+}
+
+// readTypes reads the columns of the root struct type
+func (r *OrcReader) readTypes(types [][]byte) error {
+	kinds := make([]uint64, len(types))
+	var (
+		subtypes   []uint64
+		fieldNames []string
+	)
+	for i, t := range types {
+		err := consumeOrcFields(t, func(num protowire.Number, v uint64, b []byte, isBytes bool) error {
+			switch num {
+			case 1:
+				kinds[i] = v
+			case 2:
+				if i == 0 {
+					values, err := consumeOrcRepeatedVarint(v, b, isBytes)
+					if err != nil {
+						return err
+					}
+					subtypes = append(subtypes, values...)
+				}
+			case 3:
+				if i == 0 {
+					fieldNames = append(fieldNames, string(b))
+				}
+			}
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+	}
+	if len(kinds) == 0 || kinds[0] != orcKindStruct || len(subtypes) != len(fieldNames) {
+		return fmt.Errorf("%w: the root type is not a struct", errOrcCorrupted)
+	}
+
+	fieldIndexes := make(map[string]int, len(fieldNames))
+	fieldTypes := make([]columnarType, len(fieldNames))
+	fieldColumns := make([]uint64, len(fieldNames))
+	for i, fieldName := range fieldNames {
+		column := subtypes[i]
+		if column >= uint64(len(kinds)) {
+			return errOrcCorrupted
+		}
+		switch kinds[column] {
+		case orcKindBoolean:
+			fieldTypes[i] = columnarBoolean
+		case orcKindInt, orcKindLong:
+			fieldTypes[i] = columnarInt64
+		case orcKindDouble:
+			fieldTypes[i] = columnarDouble
+		case orcKindString, orcKindVarchar:
+			fieldTypes[i] = columnarString
+		case orcKindTimestamp:
+			fieldTypes[i] = columnarTimestamp
+		default:
+			return fmt.Errorf("unsupported orc type kind %d for column %s", kinds[column], fieldName)
+		}
+		fieldIndexes[fieldName] = i
+		fieldColumns[i] = column
+	}
+
+	r.columnarRecordReader = &columnarRecordReader{
+		fieldIndexes: fieldIndexes,
+		fieldTypes:   fieldTypes,
+		nextBatch: func() ([][]interface{}, int, error) {
+			return r.readStripe(fieldColumns, fieldTypes)
+		},
+	}
+	return nil
+}
+
+// readStripe returns the columns of the next stripe, or io.EOF
+func (r *OrcReader) readStripe(fieldColumns []uint64, fieldTypes []columnarType) ([][]interface{}, int, error) {
+	if r.stripe >= len(r.stripes) {
+		return nil, 0, io.EOF
+	}
+	stripe := r.stripes[r.stripe]
+	r.stripe++
+
+	b, err := r.readAt(stripe.offset, stripe.indexLength+stripe.dataLength+stripe.footerLength)
+	if err != nil {
+		return nil, 0, fmt.Errorf("reading stripe: %w", err)
+	}
+	footer := b[stripe.indexLength+stripe.dataLength:]
+
+	type streamKey struct {
+		column, kind uint64
+	}
+	var (
+		streams   = make(map[streamKey][]byte)
+		position  uint64
+		encodings []uint64
+	)
+	err = consumeOrcFields(footer, func(num protowire.Number, _ uint64, v []byte, _ bool) error {
+		switch num {
+		case 1:
+			var key streamKey
+			var length uint64
+			err := consumeOrcFields(v, func(num protowire.Number, v uint64, _ []byte, _ bool) error {
+				switch num {
+				case 1:
+					key.kind = v
+				case 2:
+					key.column = v
+				case 3:
+					length = v
+				}
+				return nil
+			})
+			if err != nil {
+				return err
+			}
+			if position+length > stripe.indexLength+stripe.dataLength {
+				return errOrcCorrupted
+			}
+			streams[key] = b[position : position+length]
+			position += length
+		case 2:
+			var kind uint64
+			err := consumeOrcFields(v, func(num protowire.Number, v uint64, _ []byte, _ bool) error {
+				if num == 1 {
+					kind = v
+				}
+				return nil
+			})
+			if err != nil {
+				return err
+			}
+			encodings = append(encodings, kind)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, 0, err
+	}
+
+	numRows := int(stripe.numberOfRows)
+	columns := make([][]interface{}, len(fieldColumns))
+	for i, column := range fieldColumns {
+		if column < uint64(len(encodings)) && encodings[column] != orcColumnEncodingDirect {
+			return nil, 0, fmt.Errorf("unsupported orc column encoding %d", encodings[column])
+		}
+
+		present := make([]bool, numRows)
+		nonNulls := numRows
+		if stream, ok := streams[streamKey{column, orcStreamPresent}]; ok {
+			if present, err = decodeOrcBooleanRLE(stream, numRows); err != nil {
+				return nil, 0, err
+			}
+			nonNulls = 0
+			for _, p := range present {
+				if p {
+					nonNulls++
+				}
+			}
+		} else {
+			for j := range present {
+				present[j] = true
+			}
+		}
+
+		values, err := decodeOrcColumn(
+			fieldTypes[i],
+			nonNulls,
+			streams[streamKey{column, orcStreamData}],
+			streams[streamKey{column, orcStreamLength}],
+			streams[streamKey{column, orcStreamSecondary}],
+		)
+		if err != nil {
+			return nil, 0, fmt.Errorf("decoding column %d: %w", column, err)
+		}
+
+		columns[i] = make([]interface{}, numRows)
+		var k int
+		for j := range present {
+			if present[j] {
+				columns[i][j] = values[k]
+				k++
+			}
+		}
+	}
+	return columns, numRows, nil
+}
+
+// decodeOrcColumn returns the non-null values of the column in the stripe
+func decodeOrcColumn(colType columnarType, count int, data, length, secondary []byte) ([]interface{}, error) {
+	values := make([]interface{}, count)
+	switch colType {
+	case columnarInt64:
+		longs, err := decodeOrcIntRLE(data, count, true)
+		if err != nil {
+			return nil, err
+		}
+		for i, v := range longs {
+			values[i] = v
+		}
+	case columnarDouble:
+		if len(data) < 8*count {
+			return nil, errOrcCorrupted
+		}
+		for i := range values {
+			values[i] = math.Float64frombits(binary.LittleEndian.Uint64(data[8*i:]))
+		}
+	case columnarBoolean:
+		bools, err := decodeOrcBooleanRLE(data, count)
+		if err != nil {
+			return nil, err
+		}
+		for i, v := range bools {
+			values[i] = v
+		}
+	case columnarTimestamp:
+		seconds, err := decodeOrcIntRLE(data, count, true)
+		if err != nil {
+			return nil, err
+		}
+		nanos, err := decodeOrcIntRLE(secondary, count, false)
+		if err != nil {
+			return nil, err
+		}
+		for i := range values {
+			values[i] = (seconds[i]+orcTimestampBase)*1e6 + decodeOrcNanos(nanos[i])/1e3
+		}
+	default:
+		lengths, err := decodeOrcIntRLE(length, count, false)
+		if err != nil {
+			return nil, err
+		}
+		for i, l := range lengths {
+			if l < 0 || int64(len(data)) < l {
+				return nil, errOrcCorrupted
+			}
+			values[i] = string(data[:l])
+			data = data[l:]
+		}
+	}
+	return values, nil
+}
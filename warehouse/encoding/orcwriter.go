@@ -0,0 +1,273 @@
+package encoding
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+	"os"
+
+	"google.golang.org/protobuf/encoding/protowire"
+
+	"github.com/rudderlabs/rudder-server/utils/misc"
+	"github.com/rudderlabs/rudder-server/warehouse/internal/model"
+	warehouseutils "github.com/rudderlabs/rudder-server/warehouse/utils"
+)
+
+// orcStripeSize is the number of rows in each stripe of the orc load files
+const orcStripeSize = 10000
+
+// OrcWriter writes orc load files, with the columns sorted by name
+type OrcWriter struct {
+	fileWriter  misc.BufferedWriter
+	writer      *offsetWriter
+	columnNames []string
+	columnTypes []columnarType
+
+	// values are the values of the columns in the current stripe
+	values [][]interface{}
+	rows   int
+
+	stripes      [][]byte
+	numberOfRows uint64
+	// numberOfValues and hasNull are the column statistics
+	numberOfValues []uint64
+	hasNull        []bool
+}
+
+func CreateOrcWriter(schema model.TableSchema, outputFilePath, destType string) (*OrcWriter, error) {
+	columns := getSortedTableColumns(schema)
+	columnNames := make([]string, 0, len(columns))
+	columnTypes := make([]columnarType, 0, len(columns))
+	for _, col := range columns {
+		columnNames = append(columnNames, warehouseutils.ToProviderCase(destType, col))
+		columnTypes = append(columnTypes, getColumnarType(schema[col]))
+	}
+
+	bufWriter, err := misc.CreateBufferedWriter(outputFilePath)
+	if err != nil {
+		return nil, err
+	}
+	w := &offsetWriter{w: bufWriter}
+	if _, err := w.Write([]byte(orcMagic)); err != nil {
+		_ = bufWriter.Close()
+		return nil, fmt.Errorf("writing orc header: %w", err)
+	}
+	return &OrcWriter{
+		fileWriter:     bufWriter,
+		writer:         w,
+		columnNames:    columnNames,
+		columnTypes:    columnTypes,
+		values:         make([][]interface{}, len(columns)),
+		numberOfValues: make([]uint64, len(columns)),
+		hasNull:        make([]bool, len(columns)),
+	}, nil
+}
+
+func (o *OrcWriter) WriteRow(row []interface{}) error {
+	if len(row) != len(o.columnTypes) {
+		return fmt.Errorf("row has %d values, expected %d", len(row), len(o.columnTypes))
+	}
+	for i, val := range row {
+		if val != nil {
+			val = getColumnarValue(val, o.columnTypes[i])
+		}
+		if val == nil {
+			o.hasNull[i] = true
+		} else {
+			o.numberOfValues[i]++
+		}
+		o.values[i] = append(o.values[i], val)
+	}
+	o.rows++
+	if o.rows >= orcStripeSize {
+		return o.flushStripe()
+	}
+	return nil
+}
+
+// flushStripe writes the streams of the columns in the current stripe, followed by the stripe footer
+func (o *OrcWriter) flushStripe() error {
+	if o.rows == 0 {
+		return nil
+	}
+
+	var data, footer []byte
+	addStream := func(column, kind int, stream []byte) {
+		data = append(data, stream...)
+
+		var s []byte
+		s = appendOrcVarintField(s, 1, uint64(kind))
+		s = appendOrcVarintField(s, 2, uint64(column))
+		s = appendOrcVarintField(s, 3, uint64(len(stream)))
+		footer = appendOrcBytesField(footer, 1, s)
+	}
+	for i, values := range o.values {
+		column := i + 1
+
+		present := make([]bool, len(values))
+		for j, val := range values {
+			present[j] = val != nil
+		}
+		addStream(column, orcStreamPresent, encodeOrcBooleanRLE(present))
+
+		switch o.columnTypes[i] {
+		case columnarInt64:
+			var longs []int64
+			for _, val := range values {
+				if val != nil {
+					longs = append(longs, val.(int64))
+				}
+			}
+			addStream(column, orcStreamData, encodeOrcIntRLE(longs, true))
+		case columnarDouble:
+			var doubles []byte
+			for _, val := range values {
+				if val != nil {
+					doubles = binary.LittleEndian.AppendUint64(doubles, math.Float64bits(val.(float64)))
+				}
+			}
+			addStream(column, orcStreamData, doubles)
+		case columnarBoolean:
+			var bools []bool
+			for _, val := range values {
+				if val != nil {
+					bools = append(bools, val.(bool))
+				}
+			}
+			addStream(column, orcStreamData, encodeOrcBooleanRLE(bools))
+		case columnarTimestamp:
+			var seconds, nanos []int64
+			for _, val := range values {
+				if val != nil {
+					micros := val.(int64)
+					sec, micro := micros/1e6, micros%1e6
+					if micro < 0 {
+						sec, micro = sec-1, micro+1e6
+					}
+					seconds = append(seconds, sec-orcTimestampBase)
+					nanos = append(nanos, encodeOrcNanos(micro*1e3))
+				}
+			}
+			addStream(column, orcStreamData, encodeOrcIntRLE(seconds, true))
+			addStream(column, orcStreamSecondary, encodeOrcIntRLE(nanos, false))
+		default:
+			var (
+				bytes   []byte
+				lengths []int64
+			)
+			for _, val := range values {
+				if val != nil {
+					bytes = append(bytes, val.(string)...)
+					lengths = append(lengths, int64(len(val.(string))))
+				}
+			}
+			addStream(column, orcStreamData, bytes)
+			addStream(column, orcStreamLength, encodeOrcIntRLE(lengths, false))
+		}
+	}
+	// the root struct column along with the other columns
+	for i := 0; i <= len(o.values); i++ {
+		footer = appendOrcBytesField(footer, 2, appendOrcVarintField(nil, 1, orcColumnEncodingDirect))
+	}
+	footer = appendOrcBytesField(footer, 3, []byte("UTC"))
+
+	var stripe []byte
+	stripe = appendOrcVarintField(stripe, 1, uint64(o.writer.offset))
+	stripe = appendOrcVarintField(stripe, 2, 0)
+	stripe = appendOrcVarintField(stripe, 3, uint64(len(data)))
+	stripe = appendOrcVarintField(stripe, 4, uint64(len(footer)))
+	stripe = appendOrcVarintField(stripe, 5, uint64(o.rows))
+
+	if _, err := o.writer.Write(data); err != nil {
+		return err
+	}
+	if _, err := o.writer.Write(footer); err != nil {
+		return err
+	}
+
+	o.stripes = append(o.stripes, stripe)
+	o.numberOfRows += uint64(o.rows)
+	o.rows = 0
+	for i := range o.values {
+		o.values[i] = o.values[i][:0]
+	}
+	return nil
+}
+
+// writeTail writes the file footer and the postscript
+func (o *OrcWriter) writeTail() error {
+	var footer []byte
+	footer = appendOrcVarintField(footer, 1, uint64(len(orcMagic)))
+	footer = appendOrcVarintField(footer, 2, uint64(o.writer.offset)-uint64(len(orcMagic)))
+	for _, stripe := range o.stripes {
+		footer = appendOrcBytesField(footer, 3, stripe)
+	}
+
+	var root, subtypes []byte
+	root = appendOrcVarintField(root, 1, orcKindStruct)
+	for i := range o.columnNames {
+		subtypes = protowire.AppendVarint(subtypes, uint64(i+1))
+	}
+	root = appendOrcBytesField(root, 2, subtypes)
+	for _, columnName := range o.columnNames {
+		root = appendOrcBytesField(root, 3, []byte(columnName))
+	}
+	footer = appendOrcBytesField(footer, 4, root)
+	for _, colType := range o.columnTypes {
+		footer = appendOrcBytesField(footer, 4, appendOrcVarintField(nil, 1, columnarTypeToOrcKind[colType]))
+	}
+	footer = appendOrcVarintField(footer, 6, o.numberOfRows)
+
+	footer = appendOrcBytesField(footer, 7, appendOrcVarintField(nil, 1, o.numberOfRows))
+	for i := range o.columnTypes {
+		var statistics []byte
+		statistics = appendOrcVarintField(statistics, 1, o.numberOfValues[i])
+		if o.hasNull[i] {
+			statistics = appendOrcVarintField(statistics, 10, 1)
+		} else {
+			statistics = appendOrcVarintField(statistics, 10, 0)
+		}
+		footer = appendOrcBytesField(footer, 7, statistics)
+	}
+	footer = appendOrcVarintField(footer, 8, 0)
+
+	var postscript []byte
+	postscript = appendOrcVarintField(postscript, 1, uint64(len(footer)))
+	postscript = appendOrcVarintField(postscript, 2, orcCompressionNone)
+	// version 0.12
+	postscript = appendOrcBytesField(postscript, 4, protowire.AppendVarint(protowire.AppendVarint(nil, 0), 12))
+	postscript = appendOrcVarintField(postscript, 5, 0)
+	postscript = appendOrcBytesField(postscript, 8000, []byte(orcMagic))
+
+	if _, err := o.writer.Write(footer); err != nil {
+		return err
+	}
+	if _, err := o.writer.Write(postscript); err != nil {
+		return err
+	}
+	_, err := o.writer.Write([]byte{byte(len(postscript))})
+	return err
+}
+
+func (o *OrcWriter) Close() error {
+	if err := o.flushStripe(); err != nil {
+		return err
+	}
+	if err := o.writeTail(); err != nil {
+		return err
+	}
+	return o.fileWriter.Close()
+}
+
+func (*OrcWriter) WriteGZ(_ string) error {
+	return errors.New("not implemented")
+}
+
+func (*OrcWriter) Write(_ []byte) (int, error) {
+	return 0, errors.New("not implemented")
+}
+
+func (o *OrcWriter) GetLoadFile() *os.File {
+	return o.fileWriter.GetFile()
+}
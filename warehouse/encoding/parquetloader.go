@@ -122,7 +122,7 @@ func GetParquetValue(val interface{}, colType string) (retVal interface{}, err e
 	case "datetime":
 		retVal, err = getUnixTimestamp(val)
 		return
-	case "string", "text":
+	case "string", "text", "json":
 		retVal, err = getString(val)
 		return
 	}
@@ -23,6 +23,16 @@ const (
 	ParquetTimestampMicros = "type=INT64, convertedtype=TIMESTAMP_MICROS, repetitiontype=OPTIONAL"
 )
 
+// objectStorageParquetDataTypes are the parquet data types used by object storage destinations writing parquet files,
+// where json columns hold the fields which don't fit in the inferred schema
+var objectStorageParquetDataTypes = map[string]string{
+	"boolean":  ParquetBoolean,
+	"float":    ParquetDouble,
+	"string":   ParquetString,
+	"json":     ParquetString,
+	"datetime": ParquetTimestampMicros,
+}
+
 var rudderDataTypeToParquetDataType = map[string]map[string]string{
 	warehouseutils.RS: {
 		"bigint":   ParquetInt64,
@@ -63,6 +73,19 @@ var rudderDataTypeToParquetDataType = map[string]map[string]string{
 		"string":   ParquetString,
 		"datetime": ParquetTimestampMicros,
 	},
+	warehouseutils.SQLITE: {
+		"int":      ParquetInt64,
+		"boolean":  ParquetBoolean,
+		"float":    ParquetDouble,
+		"string":   ParquetString,
+		"json":     ParquetString,
+		"datetime": ParquetTimestampMicros,
+	},
+	warehouseutils.S3:        objectStorageParquetDataTypes,
+	warehouseutils.GCS:       objectStorageParquetDataTypes,
+	warehouseutils.AzureBlob: objectStorageParquetDataTypes,
+	warehouseutils.MINIO:     objectStorageParquetDataTypes,
+	"DIGITAL_OCEAN_SPACES":   objectStorageParquetDataTypes,
 }
 
 type ParquetWriter struct {
@@ -95,6 +95,7 @@ type AzureSynapse struct {
 
 	config struct {
 		numWorkersDownloadLoadFiles int
+		enableDeleteByJobs          bool
 	}
 }
 
@@ -126,6 +127,7 @@ func New(conf *config.Config, log logger.Logger) *AzureSynapse {
 	az.logger = log.Child("integrations").Child("synapse")
 
 	az.config.numWorkersDownloadLoadFiles = conf.GetInt("Warehouse.azure_synapse.numWorkersDownloadLoadFiles", 1)
+	az.config.enableDeleteByJobs = conf.GetBool("Warehouse.azure_synapse.enableDeleteByJobs", false)
 
 	return az
 }
@@ -552,8 +554,86 @@ func (as *AzureSynapse) loadUserTables(ctx context.Context) (errorMap map[string
 	return
 }
 
-func (*AzureSynapse) DeleteBy(context.Context, []string, warehouseutils.DeleteByParams) error {
-	return fmt.Errorf(warehouseutils.NotImplementedErrorCode)
+func (as *AzureSynapse) DeleteBy(ctx context.Context, tableNames []string, params warehouseutils.DeleteByParams) (err error) {
+	if params.EndTime != "" {
+		return as.deleteReceivedInRange(ctx, tableNames, params)
+	}
+
+	for _, tb := range tableNames {
+		as.logger.Infof("AZ: Cleaning up the table %q ", tb)
+		sqlStatement := fmt.Sprintf(`DELETE FROM "%[1]s"."%[2]s" WHERE
+		context_sources_job_run_id <> @jobrunid AND
+		context_sources_task_run_id <> @taskrunid AND
+		context_source_id = @sourceid AND
+		received_at < @starttime`,
+			as.Namespace,
+			tb,
+		)
+
+		as.logger.Debugf("AZ: Deleting rows in table in synapse for AZ:%s ", as.Warehouse.Destination.ID)
+		as.logger.Infof("AZ: Executing the statement %v", sqlStatement)
+
+		if as.config.enableDeleteByJobs {
+			_, err = as.DB.ExecContext(ctx, sqlStatement,
+				sql.Named("jobrunid", params.JobRunId),
+				sql.Named("taskrunid", params.TaskRunId),
+				sql.Named("sourceid", params.SourceId),
+				sql.Named("starttime", params.StartTime),
+			)
+			if err != nil {
+				as.logger.Errorf("Error %s", err)
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// deleteReceivedInRange deletes the rows of the source received in the range [StartTime, EndTime), irrespective of the runs they were loaded by
+func (as *AzureSynapse) deleteReceivedInRange(ctx context.Context, tableNames []string, params warehouseutils.DeleteByParams) error {
+	for _, tableName := range tableNames {
+		sqlStatement := fmt.Sprintf(`DELETE FROM "%[1]s"."%[2]s" WHERE context_source_id = @sourceid AND received_at >= @starttime AND received_at < @endtime`,
+			as.Namespace,
+			tableName,
+		)
+
+		as.logger.Infof("AZ: Deleting rows of source %s received between %s and %s in table %s for AZ:%s", params.SourceId, params.StartTime, params.EndTime, tableName, as.Warehouse.Destination.ID)
+		as.logger.Debugf("AZ: Executing the statement %v", sqlStatement)
+
+		if _, err := as.DB.ExecContext(ctx, sqlStatement,
+			sql.Named("sourceid", params.SourceId),
+			sql.Named("starttime", params.StartTime),
+			sql.Named("endtime", params.EndTime),
+		); err != nil {
+			return fmt.Errorf("deleting from table %s: %w", tableName, err)
+		}
+	}
+	return nil
+}
+
+// DeleteByColumnValues deletes the rows of the table having any of the values in the column
+func (as *AzureSynapse) DeleteByColumnValues(ctx context.Context, tableName, columnName string, values []string) error {
+	placeholders := warehouseutils.JoinWithFormatting(values, func(idx int, _ string) string {
+		return fmt.Sprintf("@p%d", idx+1)
+	}, ",")
+	sqlStatement := fmt.Sprintf(`DELETE FROM "%[1]s"."%[2]s" WHERE "%[3]s" IN (%[4]s)`,
+		as.Namespace,
+		tableName,
+		columnName,
+		placeholders,
+	)
+
+	as.logger.Infof("AZ: Deleting %d values of column %s in table %s for AZ:%s", len(values), columnName, tableName, as.Warehouse.Destination.ID)
+	as.logger.Debugf("AZ: Executing the statement %v", sqlStatement)
+
+	args := make([]interface{}, len(values))
+	for i, value := range values {
+		args[i] = value
+	}
+	if _, err := as.DB.ExecContext(ctx, sqlStatement, args...); err != nil {
+		return fmt.Errorf("deleting from table %s: %w", tableName, err)
+	}
+	return nil
 }
 
 func (as *AzureSynapse) CreateSchema(ctx context.Context) (err error) {
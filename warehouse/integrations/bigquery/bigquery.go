@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"regexp"
 	"strings"
+	"sync"
 	"time"
 
 	"cloud.google.com/go/bigquery"
@@ -34,6 +35,10 @@ type BigQuery struct {
 	uploader   warehouseutils.Uploader
 	logger     logger.Logger
 
+	// loadCosts are the costs of the jobs run for loading the tables
+	loadCostsMu sync.Mutex
+	loadCosts   map[string]model.LoadCost
+
 	config struct {
 		setUsersLoadPartitionFirstEventFilter bool
 		customPartitionsEnabled               bool
@@ -303,6 +308,10 @@ func (bq *BigQuery) dropStagingTable(ctx context.Context, stagingTableName strin
 }
 
 func (bq *BigQuery) DeleteBy(ctx context.Context, tableNames []string, params warehouseutils.DeleteByParams) error {
+	if params.EndTime != "" {
+		return bq.deleteReceivedInRange(ctx, tableNames, params)
+	}
+
 	for _, tb := range tableNames {
 		bq.logger.Infof("BQ: Cleaning up the following tables in bigquery for BQ:%s", tb)
 		tableName := fmt.Sprintf("`%s`.`%s`", bq.namespace, tb)
@@ -347,12 +356,81 @@ func (bq *BigQuery) DeleteBy(ctx context.Context, tableNames []string, params wa
 	return nil
 }
 
+// deleteReceivedInRange deletes the rows of the source received in the range [StartTime, EndTime), irrespective of the runs they were loaded by
+func (bq *BigQuery) deleteReceivedInRange(ctx context.Context, tableNames []string, params warehouseutils.DeleteByParams) error {
+	for _, tableName := range tableNames {
+		sqlStatement := fmt.Sprintf("DELETE FROM `%s`.`%s` WHERE context_source_id = @sourceid AND received_at >= @starttime AND received_at < @endtime;",
+			bq.namespace,
+			tableName,
+		)
+
+		bq.logger.Infof("BQ: Deleting rows of source %s received between %s and %s in table %s for BQ:%s", params.SourceId, params.StartTime, params.EndTime, tableName, bq.warehouse.Destination.ID)
+		bq.logger.Debugf("BQ: Executing the sql statement %v", sqlStatement)
+
+		query := bq.db.Query(sqlStatement)
+		query.Parameters = []bigquery.QueryParameter{
+			{Name: "sourceid", Value: params.SourceId},
+			{Name: "starttime", Value: params.StartTime},
+			{Name: "endtime", Value: params.EndTime},
+		}
+		job, err := bq.getMiddleware().Run(ctx, query)
+		if err != nil {
+			return fmt.Errorf("deleting from table %s: %w", tableName, err)
+		}
+		status, err := job.Wait(ctx)
+		if err != nil {
+			return fmt.Errorf("waiting for deleting from table %s: %w", tableName, err)
+		}
+		if status.Err() != nil {
+			return fmt.Errorf("deleting from table %s: %w", tableName, status.Err())
+		}
+	}
+	return nil
+}
+
+// DeleteByColumnValues deletes the rows of the table having any of the values in the column
+func (bq *BigQuery) DeleteByColumnValues(ctx context.Context, tableName, columnName string, values []string) error {
+	sqlStatement := fmt.Sprintf("DELETE FROM `%s`.`%s` WHERE `%s` IN UNNEST(@values);",
+		bq.namespace,
+		tableName,
+		columnName,
+	)
+
+	bq.logger.Infof("BQ: Deleting %d values of column %s in table %s for BQ:%s", len(values), columnName, tableName, bq.warehouse.Destination.ID)
+	bq.logger.Debugf("BQ: Executing the sql statement %v", sqlStatement)
+
+	query := bq.db.Query(sqlStatement)
+	query.Parameters = []bigquery.QueryParameter{
+		{Name: "values", Value: values},
+	}
+
+	job, err := bq.getMiddleware().Run(ctx, query)
+	if err != nil {
+		return fmt.Errorf("deleting from table %s: %w", tableName, err)
+	}
+	status, err := job.Wait(ctx)
+	if err != nil {
+		return fmt.Errorf("waiting for delete from table %s: %w", tableName, err)
+	}
+	if status.Err() != nil {
+		return fmt.Errorf("deleting from table %s: %w", tableName, status.Err())
+	}
+	return nil
+}
+
 func partitionedTable(tableName, partitionDate string) string {
 	return fmt.Sprintf(`%s$%v`, tableName, strings.ReplaceAll(partitionDate, "-", ""))
 }
 
 func (bq *BigQuery) loadTable(ctx context.Context, tableName string, _, getLoadFileLocFromTableUploads, skipTempTableDelete bool) (stagingLoadTable StagingLoadTable, err error) {
 	bq.logger.Infof("BQ: Starting load for table:%s\n", tableName)
+	mergeKeys, hasMergeKeys := warehouseutils.GetMergeKeys(bq.warehouse, tableName)
+	if hasMergeKeys {
+		if err = mergeKeys.Validate(bq.uploader.GetTableSchemaInUpload(tableName)); err != nil {
+			return stagingLoadTable, fmt.Errorf("validating merge keys: %w", err)
+		}
+	}
+
 	var loadFiles []warehouseutils.LoadFile
 	if getLoadFileLocFromTableUploads {
 		loadFile, err := bq.uploader.GetSingleLoadFile(ctx, tableName)
@@ -396,6 +474,7 @@ func (bq *BigQuery) loadTable(ctx context.Context, tableName string, _, getLoadF
 		if status.Err() != nil {
 			return status.Err()
 		}
+		bq.addLoadCost(tableName, status)
 		return
 	}
 
@@ -431,6 +510,7 @@ func (bq *BigQuery) loadTable(ctx context.Context, tableName string, _, getLoadF
 		if status.Err() != nil {
 			return status.Err()
 		}
+		bq.addLoadCost(tableName, status)
 
 		if !skipTempTableDelete {
 			defer bq.dropStagingTable(ctx, stagingTableName)
@@ -473,6 +553,21 @@ func (bq *BigQuery) loadTable(ctx context.Context, tableName string, _, getLoadF
 			orderByClause = "ORDER BY received_at DESC"
 		}
 
+		// Tables with merge keys are upserted on the primary keys, keeping the latest record
+		matchedClause := "WHEN MATCHED THEN"
+		if hasMergeKeys {
+			partitionKey = warehouseutils.JoinWithFormatting(mergeKeys.PrimaryKeys, func(_ int, name string) string {
+				return fmt.Sprintf("`%s`", name)
+			}, ",")
+			primaryJoinClause = warehouseutils.JoinWithFormatting(mergeKeys.PrimaryKeys, func(_ int, name string) string {
+				return fmt.Sprintf("original.`%[1]s` = staging.`%[1]s`", name)
+			}, " AND ")
+			orderByClause = fmt.Sprintf("ORDER BY `%s` DESC NULLS LAST", mergeKeys.OrderByColumn(warehouseutils.BQ))
+			if mergeKeys.LatestBy != "" {
+				matchedClause = fmt.Sprintf("WHEN MATCHED AND (original.`%[1]s` IS NULL OR staging.`%[1]s` >= original.`%[1]s`) THEN", mergeKeys.LatestBy)
+			}
+		}
+
 		sqlStatement := fmt.Sprintf(`MERGE INTO %[1]s AS original
 										USING (
 											SELECT * FROM (
@@ -480,7 +575,7 @@ func (bq *BigQuery) loadTable(ctx context.Context, tableName string, _, getLoadF
 											) AS q WHERE _rudder_staging_row_number = 1
 										) AS staging
 										ON (%[3]s)
-										WHEN MATCHED THEN
+										%[9]s
 										UPDATE SET %[6]s
 										WHEN NOT MATCHED THEN
 										INSERT (%[4]s) VALUES (%[5]s)`,
@@ -492,6 +587,7 @@ func (bq *BigQuery) loadTable(ctx context.Context, tableName string, _, getLoadF
 			columnsWithValues,
 			partitionKey,
 			orderByClause,
+			matchedClause,
 		)
 		bq.logger.Infof("BQ: Dedup records for table:%s using staging table: %s\n", tableName, sqlStatement)
 
@@ -510,10 +606,11 @@ func (bq *BigQuery) loadTable(ctx context.Context, tableName string, _, getLoadF
 		if status.Err() != nil {
 			return status.Err()
 		}
+		bq.addLoadCost(tableName, status)
 		return
 	}
 
-	if !bq.dedupEnabled() {
+	if !bq.dedupEnabled() && !hasMergeKeys {
 		err = loadTableByAppend()
 		return
 	}
@@ -524,6 +621,11 @@ func (bq *BigQuery) loadTable(ctx context.Context, tableName string, _, getLoadF
 
 func (bq *BigQuery) LoadUserTables(ctx context.Context) (errorMap map[string]error) {
 	errorMap = map[string]error{warehouseutils.IdentifiesTable: nil}
+	bq.loadCostsMu.Lock()
+	delete(bq.loadCosts, warehouseutils.IdentifiesTable)
+	delete(bq.loadCosts, warehouseutils.UsersTable)
+	bq.loadCostsMu.Unlock()
+
 	bq.logger.Infof("BQ: Starting load for identifies and users tables\n")
 	identifyLoadTable, err := bq.loadTable(ctx, warehouseutils.IdentifiesTable, true, false, true)
 	if err != nil {
@@ -627,6 +729,7 @@ func (bq *BigQuery) LoadUserTables(ctx context.Context) (errorMap map[string]err
 			errorMap[warehouseutils.UsersTable] = status.Err()
 			return
 		}
+		bq.addLoadCost(warehouseutils.UsersTable, status)
 	}
 
 	loadUserTableByMerge := func() {
@@ -653,6 +756,7 @@ func (bq *BigQuery) LoadUserTables(ctx context.Context) (errorMap map[string]err
 			errorMap[warehouseutils.UsersTable] = status.Err()
 			return
 		}
+		bq.addLoadCost(warehouseutils.UsersTable, status)
 		defer bq.dropStagingTable(ctx, identifyLoadTable.stagingTableName)
 		defer bq.dropStagingTable(ctx, stagingTableName)
 
@@ -700,6 +804,7 @@ func (bq *BigQuery) LoadUserTables(ctx context.Context) (errorMap map[string]err
 			errorMap[warehouseutils.UsersTable] = status.Err()
 			return
 		}
+		bq.addLoadCost(warehouseutils.UsersTable, status)
 	}
 
 	if !bq.dedupEnabled() {
@@ -855,10 +960,47 @@ func (bq *BigQuery) LoadTable(ctx context.Context, tableName string) error {
 	default:
 		getLoadFileLocFromTableUploads = false
 	}
+	bq.loadCostsMu.Lock()
+	delete(bq.loadCosts, tableName)
+	bq.loadCostsMu.Unlock()
+
 	_, err := bq.loadTable(ctx, tableName, false, getLoadFileLocFromTableUploads, false)
 	return err
 }
 
+// LoadTableCost returns the cost of the jobs run by the last load of the table, from the job statistics
+func (bq *BigQuery) LoadTableCost(_ context.Context, tableName string) (model.LoadCost, error) {
+	bq.loadCostsMu.Lock()
+	defer bq.loadCostsMu.Unlock()
+
+	return bq.loadCosts[tableName], nil
+}
+
+// addLoadCost adds the cost of the job to the cost of loading the table.
+// Load jobs are free, so only the bytes read from the load files are accounted for them.
+func (bq *BigQuery) addLoadCost(tableName string, status *bigquery.JobStatus) {
+	cost := model.LoadCost{Queries: 1}
+	if status.Statistics != nil {
+		switch details := status.Statistics.Details.(type) {
+		case *bigquery.QueryStatistics:
+			cost.BytesScanned = details.TotalBytesProcessed
+			cost.BytesBilled = details.TotalBytesBilled
+		case *bigquery.LoadStatistics:
+			cost.BytesScanned = details.InputFileBytes
+		default:
+			cost.BytesScanned = status.Statistics.TotalBytesProcessed
+		}
+	}
+
+	bq.loadCostsMu.Lock()
+	defer bq.loadCostsMu.Unlock()
+
+	if bq.loadCosts == nil {
+		bq.loadCosts = make(map[string]model.LoadCost)
+	}
+	bq.loadCosts[tableName] = bq.loadCosts[tableName].Add(cost)
+}
+
 func (bq *BigQuery) AddColumns(ctx context.Context, tableName string, columnsInfo []warehouseutils.ColumnInfo) (err error) {
 	bq.logger.Infof("BQ: Adding columns for destinationID: %s, tableName: %s, dataset: %s, project: %s", bq.warehouse.Destination.ID, tableName, bq.namespace, bq.projectID)
 	tableRef := bq.db.Dataset(bq.namespace).Table(tableName)
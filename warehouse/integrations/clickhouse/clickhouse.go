@@ -1,18 +1,15 @@
 package clickhouse
 
 import (
-	"compress/gzip"
 	"context"
 	"crypto/tls"
 	"crypto/x509"
 	"database/sql"
-	"encoding/csv"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
 	"net/url"
-	"os"
 	"path"
 	"regexp"
 	"sort"
@@ -37,6 +34,7 @@ import (
 	"github.com/rudderlabs/rudder-server/rruntime"
 	"github.com/rudderlabs/rudder-server/utils/misc"
 	"github.com/rudderlabs/rudder-server/warehouse/client"
+	"github.com/rudderlabs/rudder-server/warehouse/encoding"
 
 	warehouseutils "github.com/rudderlabs/rudder-server/warehouse/utils"
 )
@@ -52,6 +50,8 @@ const (
 	caCertificate  = "caCertificate"
 	Cluster        = "cluster"
 	partitionField = "received_at"
+
+	useArrowLoadFiles = "useArrowLoadFiles"
 )
 
 var clickhouseDefaultDateTime, _ = time.Parse(time.RFC3339, "1970-01-01 00:00:00")
@@ -159,6 +159,8 @@ type Clickhouse struct {
 		loadTableFailureRetries     int
 		numWorkersDownloadLoadFiles int
 		s3EngineEnabledWorkspaceIDs []string
+		enableDeleteByJobs          bool
+		useLightweightDeletes       bool
 	}
 }
 
@@ -255,6 +257,15 @@ func (ch *Clickhouse) connectToClickhouse(cred credentials, includeDBInConn bool
 	return db, nil
 }
 
+// LoadFileType returns the type of the load files of the warehouse, arrow when the destination is configured with
+// useArrowLoadFiles and csv otherwise
+func LoadFileType(warehouse model.Warehouse) string {
+	if warehouseutils.GetConfigValueBoolString(useArrowLoadFiles, warehouse) == "true" {
+		return warehouseutils.LoadFileTypeArrow
+	}
+	return warehouseutils.LoadFileTypeCsv
+}
+
 func New(conf *config.Config, log logger.Logger, stat stats.Stats) *Clickhouse {
 	ch := &Clickhouse{}
 
@@ -273,6 +284,8 @@ func New(conf *config.Config, log logger.Logger, stat stats.Stats) *Clickhouse {
 	ch.config.loadTableFailureRetries = conf.GetInt("Warehouse.clickhouse.loadTableFailureRetries", 3)
 	ch.config.numWorkersDownloadLoadFiles = conf.GetInt("Warehouse.clickhouse.numWorkersDownloadLoadFiles", 8)
 	ch.config.s3EngineEnabledWorkspaceIDs = conf.GetStringSlice("Warehouse.clickhouse.s3EngineEnabledWorkspaceIDs", nil)
+	ch.config.enableDeleteByJobs = conf.GetBool("Warehouse.clickhouse.enableDeleteByJobs", false)
+	ch.config.useLightweightDeletes = conf.GetBool("Warehouse.clickhouse.useLightweightDeletes", false)
 
 	return ch
 }
@@ -360,8 +373,96 @@ func (ch *Clickhouse) getClickHouseColumnTypeForSpecificTable(tableName, columnN
 	return getClickhouseColumnTypeForSpecificColumn(columnName, columnType, true)
 }
 
-func (*Clickhouse) DeleteBy(context.Context, []string, warehouseutils.DeleteByParams) error {
-	return fmt.Errorf(warehouseutils.NotImplementedErrorCode)
+// DeleteBy deletes the rows of the previous runs of a source, received before the start time of the current run,
+// or the rows of a source received in a range when an end time is provided.
+// Rows are deleted with mutations, or with lightweight deletes when enabled, which mark rows as deleted instead of rewriting parts
+// and require Clickhouse 22.8 or later.
+func (ch *Clickhouse) DeleteBy(ctx context.Context, tableNames []string, params warehouseutils.DeleteByParams) (err error) {
+	ch.logger.Infof("CH: Cleaning up the following tables in clickhouse for CH:%s : %+v", tableNames, params)
+
+	cluster := warehouseutils.GetConfigValue(Cluster, ch.Warehouse)
+	clusterClause := ""
+	if len(strings.TrimSpace(cluster)) > 0 {
+		clusterClause = fmt.Sprintf(`ON CLUSTER %q`, cluster)
+	}
+
+	condition := `
+		context_sources_job_run_id <> ? AND
+		context_sources_task_run_id <> ? AND
+		context_source_id = ? AND
+		received_at < ?`
+	args := []interface{}{params.JobRunId, params.TaskRunId, params.SourceId, params.StartTime}
+	enabled := ch.config.enableDeleteByJobs
+
+	if params.EndTime != "" {
+		startTime, err := time.Parse(time.RFC3339Nano, params.StartTime)
+		if err != nil {
+			return fmt.Errorf("parsing start time: %w", err)
+		}
+		endTime, err := time.Parse(time.RFC3339Nano, params.EndTime)
+		if err != nil {
+			return fmt.Errorf("parsing end time: %w", err)
+		}
+
+		condition = `
+		context_source_id = ? AND
+		received_at >= ? AND
+		received_at < ?`
+		args = []interface{}{params.SourceId, startTime.UTC(), endTime.UTC()}
+		enabled = true
+	}
+
+	for _, tb := range tableNames {
+
+		var sqlStatement string
+		if ch.config.useLightweightDeletes {
+			sqlStatement = fmt.Sprintf(`DELETE FROM %q.%q %s WHERE %s`, ch.Namespace, tb, clusterClause, condition)
+		} else {
+			sqlStatement = fmt.Sprintf(`ALTER TABLE %q.%q %s DELETE WHERE %s`, ch.Namespace, tb, clusterClause, condition)
+		}
+		ch.logger.Infof("CH: Deleting rows in table in clickhouse for CH:%s", ch.Warehouse.Destination.ID)
+		ch.logger.Debugf("CH: Executing the statement  %v", sqlStatement)
+
+		if enabled {
+			_, err = ch.DB.ExecContext(ctx, sqlStatement, args...)
+			if err != nil {
+				ch.logger.Errorf("CH: Error deleting rows in table %s: %v", tb, err)
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// DeleteByColumnValues deletes the rows of the table having any of the values in the column.
+// Same as DeleteBy, rows are deleted with mutations or with lightweight deletes when enabled.
+func (ch *Clickhouse) DeleteByColumnValues(ctx context.Context, tableName, columnName string, values []string) error {
+	cluster := warehouseutils.GetConfigValue(Cluster, ch.Warehouse)
+	clusterClause := ""
+	if len(strings.TrimSpace(cluster)) > 0 {
+		clusterClause = fmt.Sprintf(`ON CLUSTER %q`, cluster)
+	}
+
+	condition := fmt.Sprintf(`%q IN (%s)`, columnName, generateArgumentString(len(values)))
+
+	// deletes are mutations, which are executed asynchronously unless waited for, on every replica
+	var sqlStatement string
+	if ch.config.useLightweightDeletes {
+		sqlStatement = fmt.Sprintf(`DELETE FROM %q.%q %s WHERE %s SETTINGS mutations_sync = 2`, ch.Namespace, tableName, clusterClause, condition)
+	} else {
+		sqlStatement = fmt.Sprintf(`ALTER TABLE %q.%q %s DELETE WHERE %s SETTINGS mutations_sync = 2`, ch.Namespace, tableName, clusterClause, condition)
+	}
+	ch.logger.Infof("CH: Deleting %d values of column %s in table %s for CH:%s", len(values), columnName, tableName, ch.Warehouse.Destination.ID)
+	ch.logger.Debugf("CH: Executing the statement  %v", sqlStatement)
+
+	args := make([]interface{}, len(values))
+	for i, value := range values {
+		args[i] = value
+	}
+	if _, err := ch.DB.ExecContext(ctx, sqlStatement, args...); err != nil {
+		return fmt.Errorf("deleting from table %s: %w", tableName, err)
+	}
+	return nil
 }
 
 func generateArgumentString(length int) string {
@@ -545,7 +646,14 @@ func (ch *Clickhouse) loadByCopyCommand(ctx context.Context, tableName string, t
 		return fmt.Errorf("sample load file location with error: %w", err)
 	}
 	loadFolderDir, _ := path.Split(csvObjectLocation)
-	loadFolder := loadFolderDir + "*.csv.gz"
+	loadFileFormat := warehouseutils.GetLoadFileFormat(ch.Uploader.GetLoadFileType())
+	loadFolder := loadFolderDir + "*." + loadFileFormat
+
+	// arrow load files are already typed and uncompressed, whereas the csv ones are gzipped
+	format, compression := "CSV", "gz"
+	if ch.Uploader.GetLoadFileType() == warehouseutils.LoadFileTypeArrow {
+		format, compression = "Arrow", "none"
+	}
 
 	accessKeyID, secretAccessKey, err := ch.credentials()
 	if err != nil {
@@ -563,9 +671,9 @@ func (ch *Clickhouse) loadByCopyCommand(ctx context.Context, tableName string, t
 			'%[4]s',
 		  	'%[5]s',
 		  	'%[6]s',
-			'CSV',
+			'%[8]s',
 			'%[7]s',
-			'gz'
+			'%[9]s'
 		  )
 			settings
 				date_time_input_format = 'best_effort',
@@ -578,6 +686,8 @@ func (ch *Clickhouse) loadByCopyCommand(ctx context.Context, tableName string, t
 		accessKeyID,                    // 5
 		secretAccessKey,                // 6
 		sortedColumnNamesWithDataTypes, // 7
+		format,                         // 8
+		compression,                    // 9
 	)
 	_, err = ch.DB.ExecContext(ctx, sqlStatement)
 	if err != nil {
@@ -638,36 +748,27 @@ func (ch *Clickhouse) loadTablesFromFilesNamesWithRetry(ctx context.Context, tab
 	for _, objectFileName := range fileNames {
 		syncStart := time.Now()
 
-		var gzipFile *os.File
-		gzipFile, err = os.Open(objectFileName)
-		if err != nil {
-			err = fmt.Errorf("%s Error opening file using os.Open for file:%s while loading to table with error:%v", ch.GetLogIdentifier(tableName), objectFileName, err.Error())
-			onError(err)
-			return
-		}
-
-		var gzipReader *gzip.Reader
-		gzipReader, err = gzip.NewReader(gzipFile)
+		var loadFileReader encoding.LoadFileReader
+		loadFileReader, err = encoding.NewLoadFileReader(ch.Uploader.GetLoadFileType(), objectFileName)
 		if err != nil {
 			rruntime.GoForWarehouse(func() {
 				misc.RemoveFilePaths(objectFileName)
 			})
-			_ = gzipFile.Close()
-			err = fmt.Errorf("%s Error reading file using gzip.NewReader for file:%s while loading to table with error:%v", ch.GetLogIdentifier(tableName), gzipFile.Name(), err.Error())
+			err = fmt.Errorf("%s Error opening load file:%s while loading to table with error:%v", ch.GetLogIdentifier(tableName), objectFileName, err.Error())
 			onError(err)
 			return
 		}
 
-		csvReader := csv.NewReader(gzipReader)
 		var csvRowsProcessedCount int
 		for {
 			var record []string
-			record, err = csvReader.Read()
+			record, err = loadFileReader.Read(nil)
 			if err != nil {
 				if err == io.EOF {
 					ch.logger.Debugf("%s File reading completed while reading csv file for loading in table for objectFileName:%s", ch.GetLogIdentifier(tableName), objectFileName)
 					break
 				}
+				_ = loadFileReader.Close()
 				err = fmt.Errorf("%s Error while reading csv file %s for loading in table with error:%v", ch.GetLogIdentifier(tableName), objectFileName, err)
 				onError(err)
 				return
@@ -711,8 +812,7 @@ func (ch *Clickhouse) loadTablesFromFilesNamesWithRetry(ctx context.Context, tab
 
 		chStats.numRowsLoadFile.Count(csvRowsProcessedCount)
 
-		_ = gzipReader.Close()
-		_ = gzipFile.Close()
+		_ = loadFileReader.Close()
 
 		chStats.syncLoadFileTime.Since(syncStart)
 	}
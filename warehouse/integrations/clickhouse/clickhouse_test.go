@@ -1063,6 +1063,103 @@ func TestClickhouse_FetchSchema(t *testing.T) {
 	})
 }
 
+func TestClickhouse_DeleteBy(t *testing.T) {
+	c := testcompose.New(t, compose.FilePaths([]string{"testdata/docker-compose.clickhouse.yml"}))
+	c.Start(context.Background())
+
+	misc.Init()
+	warehouseutils.Init()
+
+	clickhousePort := c.Port("clickhouse", 9000)
+
+	databaseName := "rudderdb"
+	password := "rudder-password"
+	user := "rudder"
+	sourceID := "test_source_id"
+	namespace := "test_namespace_delete_by"
+	table := "test_table"
+
+	ctx := context.Background()
+
+	conf := config.New()
+	conf.Set("Warehouse.clickhouse.enableDeleteByJobs", true)
+
+	ch := clickhouse.New(conf, logger.NOP, stats.Default)
+
+	warehouse := model.Warehouse{
+		Namespace: namespace,
+		Destination: backendconfig.DestinationT{
+			Config: map[string]any{
+				"host":     "localhost",
+				"port":     strconv.Itoa(clickhousePort),
+				"database": databaseName,
+				"user":     user,
+				"password": password,
+			},
+		},
+	}
+
+	err := ch.Setup(ctx, warehouse, &mockUploader{})
+	require.NoError(t, err)
+
+	err = ch.CreateSchema(ctx)
+	require.NoError(t, err)
+
+	err = ch.CreateTable(ctx, table, model.TableSchema{
+		"id":                          "string",
+		"context_sources_job_run_id":  "string",
+		"context_sources_task_run_id": "string",
+		"context_source_id":           "string",
+		"received_at":                 "datetime",
+	})
+	require.NoError(t, err)
+
+	txn, err := ch.DB.Begin()
+	require.NoError(t, err)
+	stmt, err := txn.Prepare(fmt.Sprintf(`INSERT INTO %q.%q (id, context_sources_job_run_id, context_sources_task_run_id, context_source_id, received_at) VALUES (?, ?, ?, ?, ?)`, namespace, table))
+	require.NoError(t, err)
+	for _, row := range []struct {
+		id, jobRunID, taskRunID, sourceID string
+		receivedAt                        time.Time
+	}{
+		{"1", "old_job", "old_task", sourceID, time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)},
+		{"2", "new_job", "new_task", sourceID, time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)},
+		{"3", "old_job", "old_task", "other_source", time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)},
+		{"4", "old_job", "old_task", sourceID, time.Date(2023, 1, 3, 0, 0, 0, 0, time.UTC)},
+	} {
+		_, err = stmt.Exec(row.id, row.jobRunID, row.taskRunID, row.sourceID, row.receivedAt)
+		require.NoError(t, err)
+	}
+	require.NoError(t, txn.Commit())
+
+	err = ch.DeleteBy(ctx, []string{table}, warehouseutils.DeleteByParams{
+		SourceId:  sourceID,
+		JobRunId:  "new_job",
+		TaskRunId: "new_task",
+		StartTime: "2023-01-02 00:00:00",
+	})
+	require.NoError(t, err)
+
+	// Mutations are applied asynchronously
+	require.Eventually(t, func() bool {
+		rows, err := ch.DB.Query(fmt.Sprintf(`SELECT id FROM %q.%q ORDER BY id`, namespace, table))
+		if err != nil {
+			return false
+		}
+		defer func() { _ = rows.Close() }()
+
+		var ids []string
+		for rows.Next() {
+			var id string
+			if err := rows.Scan(&id); err != nil {
+				return false
+			}
+			ids = append(ids, id)
+		}
+		return rows.Err() == nil && strings.Join(ids, ",") == "2,3,4"
+	}, time.Minute, 100*time.Millisecond)
+}
+
 func connectClickhouseDB(ctx context.Context, t testing.TB, dsn string) *sql.DB {
 	t.Helper()
 
@@ -10,6 +10,8 @@ import (
 
 	schemarepository "github.com/rudderlabs/rudder-server/warehouse/integrations/datalake/schema-repository"
 
+	"github.com/rudderlabs/rudder-go-kit/config"
+	"github.com/rudderlabs/rudder-go-kit/filemanager"
 	"github.com/rudderlabs/rudder-go-kit/logger"
 	"github.com/rudderlabs/rudder-server/utils/misc"
 	"github.com/rudderlabs/rudder-server/warehouse/client"
@@ -27,21 +29,41 @@ var errorsMappings = []model.JobError{
 	},
 }
 
+const useOrcLoadFiles = "useOrcLoadFiles"
+
 type Datalake struct {
 	SchemaRepository schemarepository.SchemaRepository
 	Warehouse        model.Warehouse
 	Uploader         warehouseutils.Uploader
 	logger           logger.Logger
+
+	fileManagerFactory filemanager.Factory
+
+	config struct {
+		enableDeleteByJobs bool
+	}
 }
 
-func New(log logger.Logger) *Datalake {
+func New(conf *config.Config, log logger.Logger) *Datalake {
 	d := &Datalake{}
 
 	d.logger = log.Child("integrations").Child("datalake")
+	d.fileManagerFactory = filemanager.New
+
+	d.config.enableDeleteByJobs = conf.GetBool("Warehouse.datalake.enableDeleteByJobs", false)
 
 	return d
 }
 
+// LoadFileType returns the type of the load files of the warehouse, orc when the destination is configured with
+// useOrcLoadFiles and parquet otherwise. With glue, the tables created before keep the format they were created with.
+func LoadFileType(warehouse model.Warehouse) string {
+	if warehouseutils.GetConfigValueBoolString(useOrcLoadFiles, warehouse) == "true" {
+		return warehouseutils.LoadFileTypeOrc
+	}
+	return warehouseutils.LoadFileTypeParquet
+}
+
 func (d *Datalake) Setup(_ context.Context, warehouse model.Warehouse, uploader warehouseutils.Uploader) (err error) {
 	d.Warehouse = warehouse
 	d.Uploader = uploader
@@ -82,10 +104,6 @@ func (d *Datalake) LoadTable(_ context.Context, tableName string) error {
 	return nil
 }
 
-func (*Datalake) DeleteBy(context.Context, []string, warehouseutils.DeleteByParams) (err error) {
-	return fmt.Errorf(warehouseutils.NotImplementedErrorCode)
-}
-
 func (d *Datalake) LoadUserTables(context.Context) map[string]error {
 	d.logger.Infof("Skipping load for user tables : %s is a datalake destination", d.Warehouse.Destination.ID)
 	// return map with nil error entries for identifies and users(if any) tables
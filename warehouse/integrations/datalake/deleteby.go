@@ -0,0 +1,379 @@
+package datalake
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/xitongsys/parquet-go-source/local"
+	"github.com/xitongsys/parquet-go/reader"
+	"github.com/xitongsys/parquet-go/writer"
+
+	"github.com/rudderlabs/rudder-go-kit/filemanager"
+	"github.com/rudderlabs/rudder-server/utils/misc"
+	warehouseutils "github.com/rudderlabs/rudder-server/warehouse/utils"
+)
+
+const parquetParallelWriters = 8
+
+var startTimeLayouts = []string{"2006-01-02 15:04:05", time.RFC3339Nano}
+
+var errOrcDeleteUnsupported = errors.New("deleting rows from orc files is not supported")
+
+// DeleteBy removes the rows of a previous run of a source from the tables, i.e. rows with the same source id but with a
+// different job run id and task run id received before the start time of the current run.
+// When an end time is provided, the rows of the source received in the range [start time, end time) are removed instead.
+// Datalakes don't support deletes, so every parquet file of the table which contains such rows is rewritten in place without them,
+// while files left without any rows are deleted. Tables holding orc files are rejected, since only parquet files get rewritten.
+func (d *Datalake) DeleteBy(ctx context.Context, tableNames []string, params warehouseutils.DeleteByParams) error {
+	d.logger.Infof("DL: Cleaning up the following tables in datalake for DL:%s : %+v", tableNames, params)
+
+	if !d.config.enableDeleteByJobs && params.EndTime == "" {
+		return nil
+	}
+
+	startTime, err := parseStartTime(params.StartTime)
+	if err != nil {
+		return fmt.Errorf("parsing start time: %w", err)
+	}
+	if params.EndTime != "" {
+		endTime, err := parseStartTime(params.EndTime)
+		if err != nil {
+			return fmt.Errorf("parsing end time: %w", err)
+		}
+		return d.deleteFromTables(ctx, tableNames, func(columns map[string][]interface{}, numRows int64) ([]int, error) {
+			return rowsOutsideRange(columns, numRows, params.SourceId, startTime, endTime)
+		})
+	}
+
+	return d.deleteFromTables(ctx, tableNames, func(columns map[string][]interface{}, numRows int64) ([]int, error) {
+		return rowsToKeep(columns, numRows, startTime, params)
+	})
+}
+
+// DeleteByColumnValues deletes the rows of the table having any of the values in the column,
+// rewriting the parquet files of the table same as DeleteBy.
+func (d *Datalake) DeleteByColumnValues(ctx context.Context, tableName, columnName string, values []string) error {
+	d.logger.Infof("DL: Deleting %d values of column %s in table %s for DL:%s", len(values), columnName, tableName, d.Warehouse.Destination.ID)
+
+	valuesSet := make(map[string]struct{}, len(values))
+	for _, value := range values {
+		valuesSet[value] = struct{}{}
+	}
+
+	return d.deleteFromTables(ctx, []string{tableName}, func(columns map[string][]interface{}, numRows int64) ([]int, error) {
+		keep := make([]int, 0, numRows)
+		for i := 0; i < int(numRows); i++ {
+			if value, ok := columns[columnName][i].(string); ok {
+				if _, found := valuesSet[value]; found {
+					continue
+				}
+			}
+			keep = append(keep, i)
+		}
+		return keep, nil
+	})
+}
+
+// rowFilter returns the indexes of the rows to keep out of the columns read from a parquet file
+type rowFilter func(columns map[string][]interface{}, numRows int64) ([]int, error)
+
+func (d *Datalake) deleteFromTables(ctx context.Context, tableNames []string, filter rowFilter) error {
+	fm, err := d.fileManager()
+	if err != nil {
+		return fmt.Errorf("creating file manager: %w", err)
+	}
+
+	tmpDirPath, err := misc.CreateTMPDIR()
+	if err != nil {
+		return fmt.Errorf("creating tmp dir: %w", err)
+	}
+
+	for _, tableName := range tableNames {
+		prefix := path.Join(fm.Prefix(), warehouseutils.GetTablePathInObjectStorage(d.Warehouse.Namespace, tableName)) + "/"
+
+		keys, err := listKeys(ctx, fm, prefix)
+		if err != nil {
+			return fmt.Errorf("listing files for table %s: %w", tableName, err)
+		}
+
+		for _, key := range keys {
+			if err := d.deleteFromFile(ctx, fm, tmpDirPath, key, filter); err != nil {
+				return fmt.Errorf("deleting from file %s of table %s: %w", key, tableName, err)
+			}
+		}
+	}
+	return nil
+}
+
+func (d *Datalake) fileManager() (filemanager.FileManager, error) {
+	provider := warehouseutils.ObjectStorageType(d.Warehouse.Type, d.Warehouse.Destination.Config, false)
+
+	return d.fileManagerFactory(&filemanager.Settings{
+		Provider: provider,
+		Config: misc.GetObjectStorageConfig(misc.ObjectStorageOptsT{
+			Provider:    provider,
+			Config:      d.Warehouse.Destination.Config,
+			WorkspaceID: d.Warehouse.WorkspaceID,
+		}),
+	})
+}
+
+func parseStartTime(startTime string) (time.Time, error) {
+	for _, layout := range startTimeLayouts {
+		if t, err := time.Parse(layout, startTime); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("unsupported start time format: %s", startTime)
+}
+
+func listKeys(ctx context.Context, fm filemanager.FileManager, prefix string) ([]string, error) {
+	var keys []string
+
+	session := fm.ListFilesWithPrefix(ctx, "", prefix, 1000)
+	for {
+		fileObjects, err := session.Next()
+		if err != nil {
+			return nil, err
+		}
+		if len(fileObjects) == 0 {
+			break
+		}
+		for _, fileObject := range fileObjects {
+			switch {
+			case strings.HasSuffix(fileObject.Key, ".parquet"):
+				keys = append(keys, fileObject.Key)
+			case strings.HasSuffix(fileObject.Key, ".orc"):
+				return nil, fmt.Errorf("%w: %s", errOrcDeleteUnsupported, fileObject.Key)
+			}
+		}
+	}
+	return keys, nil
+}
+
+// deleteFromFile downloads the parquet file with the given key, filters out the rows not to keep and replaces the
+// file in the object storage with the remaining rows.
+func (d *Datalake) deleteFromFile(
+	ctx context.Context,
+	fm filemanager.FileManager,
+	tmpDirPath, key string,
+	filter rowFilter,
+) error {
+	dirPath, err := os.MkdirTemp(tmpDirPath, "datalake-delete-by-*")
+	if err != nil {
+		return fmt.Errorf("creating tmp dir: %w", err)
+	}
+	defer func() { _ = os.RemoveAll(dirPath) }()
+
+	// the name of the file is kept, so that the rewritten file gets uploaded with the same key
+	filePath := filepath.Join(dirPath, path.Base(key))
+
+	if err := download(ctx, fm, filePath, key); err != nil {
+		return fmt.Errorf("downloading: %w", err)
+	}
+
+	metadata, columns, numRows, err := readParquetFile(filePath)
+	if err != nil {
+		return fmt.Errorf("reading: %w", err)
+	}
+
+	keep, err := filter(columns, numRows)
+	if err != nil {
+		return err
+	}
+	if len(keep) == int(numRows) {
+		return nil
+	}
+
+	d.logger.Infof("DL: Deleting %d rows out of %d from file %s", int(numRows)-len(keep), numRows, key)
+
+	if len(keep) == 0 {
+		if err := fm.Delete(ctx, []string{key}); err != nil {
+			return fmt.Errorf("deleting file: %w", err)
+		}
+		return nil
+	}
+
+	if err := writeParquetFile(filePath, metadata, columns, keep); err != nil {
+		return fmt.Errorf("writing: %w", err)
+	}
+
+	file, err := os.Open(filePath)
+	if err != nil {
+		return fmt.Errorf("opening file: %w", err)
+	}
+	defer func() { _ = file.Close() }()
+
+	keyPrefix := strings.TrimPrefix(strings.TrimPrefix(path.Dir(key), fm.Prefix()), "/")
+	if _, err := fm.Upload(ctx, file, keyPrefix); err != nil {
+		return fmt.Errorf("uploading file: %w", err)
+	}
+	return nil
+}
+
+func download(ctx context.Context, fm filemanager.FileManager, filePath, key string) error {
+	file, err := os.Create(filePath)
+	if err != nil {
+		return fmt.Errorf("creating file: %w", err)
+	}
+	defer func() { _ = file.Close() }()
+
+	return fm.Download(ctx, file, key)
+}
+
+// readParquetFile reads all the columns of a flat parquet file, returning the writer metadata for its schema,
+// the values by column name and the number of rows.
+func readParquetFile(filePath string) ([]string, map[string][]interface{}, int64, error) {
+	file, err := local.NewLocalFileReader(filePath)
+	if err != nil {
+		return nil, nil, 0, fmt.Errorf("opening file: %w", err)
+	}
+	defer func() { _ = file.Close() }()
+
+	r, err := reader.NewParquetColumnReader(file, 1)
+	if err != nil {
+		return nil, nil, 0, fmt.Errorf("creating parquet reader: %w", err)
+	}
+	defer r.ReadStop()
+
+	numRows := r.GetNumRows()
+
+	elements := r.SchemaHandler.SchemaElements
+
+	metadata := make([]string, 0, len(elements))
+	columns := make(map[string][]interface{}, len(elements))
+
+	// the first schema element is the root
+	for i := 1; i < len(elements); i++ {
+		element := elements[i]
+		name := r.SchemaHandler.GetExName(i)
+
+		if element.GetNumChildren() > 0 {
+			return nil, nil, 0, fmt.Errorf("nested column %s is not supported", name)
+		}
+
+		md := fmt.Sprintf("name=%s, type=%s", name, element.GetType())
+		if element.IsSetConvertedType() {
+			md += fmt.Sprintf(", convertedtype=%s", element.GetConvertedType())
+		}
+		md += fmt.Sprintf(", repetitiontype=%s", element.GetRepetitionType())
+		metadata = append(metadata, md)
+
+		values, _, _, err := r.ReadColumnByPath(r.SchemaHandler.IndexMap[int32(i)], numRows)
+		if err != nil {
+			return nil, nil, 0, fmt.Errorf("reading column %s: %w", name, err)
+		}
+		if int64(len(values)) != numRows {
+			return nil, nil, 0, fmt.Errorf("reading column %s: expected %d values, got %d", name, numRows, len(values))
+		}
+		columns[name] = values
+	}
+	return metadata, columns, numRows, nil
+}
+
+// rowsToKeep returns the indexes of the rows which are not to be deleted.
+// Files without the columns used for the deletion are kept as they are.
+func rowsToKeep(
+	columns map[string][]interface{},
+	numRows int64,
+	startTime time.Time,
+	params warehouseutils.DeleteByParams,
+) ([]int, error) {
+	keep := make([]int, 0, numRows)
+
+	jobRunIDs, taskRunIDs := columns["context_sources_job_run_id"], columns["context_sources_task_run_id"]
+	sourceIDs, receivedAts := columns["context_source_id"], columns["received_at"]
+	if jobRunIDs == nil || taskRunIDs == nil || sourceIDs == nil || receivedAts == nil {
+		for i := 0; i < int(numRows); i++ {
+			keep = append(keep, i)
+		}
+		return keep, nil
+	}
+
+	for i := 0; i < int(numRows); i++ {
+		receivedAt, ok := receivedAts[i].(int64)
+		if receivedAts[i] != nil && !ok {
+			return nil, errors.New("received_at is not a timestamp column")
+		}
+
+		// comparisons with nulls are never true, same as in the warehouses
+		toDelete := jobRunIDs[i] != nil && jobRunIDs[i] != params.JobRunId &&
+			taskRunIDs[i] != nil && taskRunIDs[i] != params.TaskRunId &&
+			sourceIDs[i] == params.SourceId &&
+			receivedAts[i] != nil && time.UnixMicro(receivedAt).Before(startTime)
+		if !toDelete {
+			keep = append(keep, i)
+		}
+	}
+	return keep, nil
+}
+
+// rowsOutsideRange returns the indexes of the rows which are not of the source or weren't received in the range [startTime, endTime).
+// Files without the columns used for the deletion are kept as they are.
+func rowsOutsideRange(
+	columns map[string][]interface{},
+	numRows int64,
+	sourceID string,
+	startTime, endTime time.Time,
+) ([]int, error) {
+	keep := make([]int, 0, numRows)
+
+	sourceIDs, receivedAts := columns["context_source_id"], columns["received_at"]
+	if sourceIDs == nil || receivedAts == nil {
+		for i := 0; i < int(numRows); i++ {
+			keep = append(keep, i)
+		}
+		return keep, nil
+	}
+
+	for i := 0; i < int(numRows); i++ {
+		receivedAt, ok := receivedAts[i].(int64)
+		if receivedAts[i] != nil && !ok {
+			return nil, errors.New("received_at is not a timestamp column")
+		}
+
+		toDelete := sourceIDs[i] == sourceID &&
+			receivedAts[i] != nil &&
+			!time.UnixMicro(receivedAt).Before(startTime) &&
+			time.UnixMicro(receivedAt).Before(endTime)
+		if !toDelete {
+			keep = append(keep, i)
+		}
+	}
+	return keep, nil
+}
+
+func writeParquetFile(filePath string, metadata []string, columns map[string][]interface{}, rows []int) error {
+	fw, err := local.NewLocalFileWriter(filePath)
+	if err != nil {
+		return fmt.Errorf("creating file: %w", err)
+	}
+	defer func() { _ = fw.Close() }()
+
+	w, err := writer.NewCSVWriter(metadata, fw, parquetParallelWriters)
+	if err != nil {
+		return fmt.Errorf("creating parquet writer: %w", err)
+	}
+
+	names := make([]string, len(metadata))
+	for i, md := range metadata {
+		names[i] = strings.TrimPrefix(strings.Split(md, ",")[0], "name=")
+	}
+
+	for _, row := range rows {
+		record := make([]interface{}, len(names))
+		for i, name := range names {
+			record[i] = columns[name][row]
+		}
+		if err := w.Write(record); err != nil {
+			return fmt.Errorf("writing row: %w", err)
+		}
+	}
+	return w.WriteStop()
+}
@@ -0,0 +1,306 @@
+package datalake
+
+import (
+	"context"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/rudderlabs/rudder-go-kit/config"
+	"github.com/rudderlabs/rudder-go-kit/filemanager"
+	"github.com/rudderlabs/rudder-go-kit/logger"
+	backendconfig "github.com/rudderlabs/rudder-server/backend-config"
+	"github.com/rudderlabs/rudder-server/utils/misc"
+	"github.com/rudderlabs/rudder-server/warehouse/encoding"
+	"github.com/rudderlabs/rudder-server/warehouse/internal/model"
+	warehouseutils "github.com/rudderlabs/rudder-server/warehouse/utils"
+)
+
+// dirFileManager is a file manager storing the objects in a local directory
+type dirFileManager struct {
+	filemanager.FileManager
+
+	dir    string
+	prefix string
+}
+
+func (m *dirFileManager) ListFilesWithPrefix(_ context.Context, _, prefix string, _ int64) filemanager.ListSession {
+	var fileObjects []*filemanager.FileInfo
+	_ = filepath.Walk(m.dir, func(p string, info os.FileInfo, err error) error {
+		if err != nil || info.IsDir() {
+			return err
+		}
+		key, _ := filepath.Rel(m.dir, p)
+		if strings.HasPrefix(key, prefix) {
+			fileObjects = append(fileObjects, &filemanager.FileInfo{Key: key})
+		}
+		return nil
+	})
+	return &listSession{fileObjects: fileObjects}
+}
+
+func (m *dirFileManager) Download(_ context.Context, output *os.File, key string) error {
+	f, err := os.Open(filepath.Join(m.dir, key))
+	if err != nil {
+		return err
+	}
+	defer func() { _ = f.Close() }()
+
+	_, err = io.Copy(output, f)
+	return err
+}
+
+func (m *dirFileManager) Upload(_ context.Context, file *os.File, prefixes ...string) (filemanager.UploadedFile, error) {
+	key := path.Join(m.prefix, path.Join(prefixes...), path.Base(file.Name()))
+	if err := os.MkdirAll(filepath.Join(m.dir, path.Dir(key)), os.ModePerm); err != nil {
+		return filemanager.UploadedFile{}, err
+	}
+
+	f, err := os.Create(filepath.Join(m.dir, key))
+	if err != nil {
+		return filemanager.UploadedFile{}, err
+	}
+	defer func() { _ = f.Close() }()
+
+	if _, err := io.Copy(f, file); err != nil {
+		return filemanager.UploadedFile{}, err
+	}
+	return filemanager.UploadedFile{ObjectName: key}, nil
+}
+
+func (m *dirFileManager) Delete(_ context.Context, keys []string) error {
+	for _, key := range keys {
+		if err := os.Remove(filepath.Join(m.dir, key)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (m *dirFileManager) Prefix() string {
+	return m.prefix
+}
+
+type listSession struct {
+	fileObjects []*filemanager.FileInfo
+}
+
+func (s *listSession) Next() ([]*filemanager.FileInfo, error) {
+	fileObjects := s.fileObjects
+	s.fileObjects = nil
+	return fileObjects, nil
+}
+
+func TestDatalake_DeleteBy(t *testing.T) {
+	misc.Init()
+	warehouseutils.Init()
+	encoding.Init()
+
+	const (
+		namespace = "test_namespace"
+		tableName = "tracks"
+		prefix    = "some-prefix"
+		sourceID  = "test_source_id"
+		jobRunID  = "test_job_run_id"
+		taskRunID = "test_task_run_id"
+	)
+
+	schema := model.TableSchema{
+		"id":                          "string",
+		"received_at":                 "datetime",
+		"context_source_id":           "string",
+		"context_sources_job_run_id":  "string",
+		"context_sources_task_run_id": "string",
+	}
+
+	startTime := time.Date(2023, 1, 2, 0, 0, 0, 0, time.UTC)
+	before := startTime.Add(-time.Hour).UnixMicro()
+	after := startTime.Add(time.Hour).UnixMicro()
+
+	// writeFile writes the rows with columns sorted by name, same as the load files
+	writeFile := func(t testing.TB, dir, key string, rows [][]interface{}) {
+		t.Helper()
+
+		filePath := filepath.Join(dir, key)
+		require.NoError(t, os.MkdirAll(filepath.Dir(filePath), os.ModePerm))
+
+		w, err := encoding.CreateParquetWriter(schema, filePath, warehouseutils.S3Datalake)
+		require.NoError(t, err)
+		for _, row := range rows {
+			require.NoError(t, w.WriteRow(row))
+		}
+		require.NoError(t, w.Close())
+	}
+
+	readIDs := func(t testing.TB, filePath string) []string {
+		t.Helper()
+
+		_, columns, numRows, err := readParquetFile(filePath)
+		require.NoError(t, err)
+
+		ids := make([]string, 0, numRows)
+		for _, id := range columns["id"] {
+			ids = append(ids, id.(string))
+		}
+		sort.Strings(ids)
+		return ids
+	}
+
+	testCases := []struct {
+		name               string
+		enableDeleteByJobs bool
+		startTime          string
+		endTime            string
+		wantFiles          map[string][]string
+		wantError          string
+	}{
+		{
+			name:               "deletes rows of previous runs",
+			enableDeleteByJobs: true,
+			startTime:          startTime.Format("2006-01-02 15:04:05"),
+			wantFiles: map[string][]string{
+				"1.parquet": {"2", "3", "4", "5"},
+				"3.parquet": {"7"},
+			},
+		},
+		{
+			name:               "disabled",
+			enableDeleteByJobs: false,
+			startTime:          startTime.Format("2006-01-02 15:04:05"),
+			wantFiles: map[string][]string{
+				"1.parquet": {"1", "2", "3", "4", "5"},
+				"2.parquet": {"6"},
+				"3.parquet": {"7"},
+			},
+		},
+		{
+			name:      "deletes rows received in range",
+			startTime: startTime.Add(-2 * time.Hour).Format(misc.RFC3339Milli),
+			endTime:   startTime.Format(misc.RFC3339Milli),
+			wantFiles: map[string][]string{
+				"1.parquet": {"3", "4"},
+				"3.parquet": {"7"},
+			},
+		},
+		{
+			name:      "invalid end time",
+			startTime: startTime.Format(misc.RFC3339Milli),
+			endTime:   "invalid",
+			wantError: "parsing end time: unsupported start time format: invalid",
+		},
+		{
+			name:               "invalid start time",
+			enableDeleteByJobs: true,
+			startTime:          "invalid",
+			wantError:          "parsing start time: unsupported start time format: invalid",
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			dir := t.TempDir()
+			tableDir := path.Join(prefix, warehouseutils.GetTablePathInObjectStorage(namespace, tableName), "2023/01/01/23")
+
+			// columns: context_source_id, context_sources_job_run_id, context_sources_task_run_id, id, received_at
+			writeFile(t, dir, path.Join(tableDir, "1.parquet"), [][]interface{}{
+				{sourceID, "old_job_run_id", "old_task_run_id", "1", before},
+				{sourceID, jobRunID, taskRunID, "2", before},
+				{"other_source_id", "old_job_run_id", "old_task_run_id", "3", before},
+				{sourceID, "old_job_run_id", "old_task_run_id", "4", after},
+				{sourceID, nil, nil, "5", before},
+			})
+			writeFile(t, dir, path.Join(tableDir, "2.parquet"), [][]interface{}{
+				{sourceID, "old_job_run_id", "old_task_run_id", "6", before},
+			})
+			writeFile(t, dir, path.Join(prefix, warehouseutils.GetTablePathInObjectStorage(namespace, "tracks_other"), "3.parquet"), [][]interface{}{
+				{sourceID, "old_job_run_id", "old_task_run_id", "7", before},
+			})
+
+			c := config.New()
+			c.Set("Warehouse.datalake.enableDeleteByJobs", tc.enableDeleteByJobs)
+
+			dl := New(c, logger.NOP)
+			dl.fileManagerFactory = func(settings *filemanager.Settings) (filemanager.FileManager, error) {
+				return &dirFileManager{dir: dir, prefix: prefix}, nil
+			}
+			dl.Warehouse = model.Warehouse{
+				Type:      warehouseutils.S3Datalake,
+				Namespace: namespace,
+				Destination: backendconfig.DestinationT{
+					Config: map[string]interface{}{},
+				},
+			}
+
+			err := dl.DeleteBy(context.Background(), []string{tableName}, warehouseutils.DeleteByParams{
+				SourceId:  sourceID,
+				JobRunId:  jobRunID,
+				TaskRunId: taskRunID,
+				StartTime: tc.startTime,
+				EndTime:   tc.endTime,
+			})
+			if tc.wantError != "" {
+				require.EqualError(t, err, tc.wantError)
+				return
+			}
+			require.NoError(t, err)
+
+			files := make(map[string][]string)
+			require.NoError(t, filepath.Walk(dir, func(p string, info os.FileInfo, err error) error {
+				if err != nil || info.IsDir() {
+					return err
+				}
+				files[filepath.Base(p)] = readIDs(t, p)
+				return nil
+			}))
+			require.Equal(t, tc.wantFiles, files)
+		})
+	}
+
+	t.Run("tables with orc files", func(t *testing.T) {
+		dir := t.TempDir()
+		tableDir := path.Join(prefix, warehouseutils.GetTablePathInObjectStorage(namespace, tableName), "2023/01/01/23")
+
+		writeFile(t, dir, path.Join(tableDir, "1.parquet"), [][]interface{}{
+			{sourceID, "old_job_run_id", "old_task_run_id", "1", before},
+		})
+
+		orcFile := filepath.Join(dir, tableDir, "2.orc")
+		w, err := encoding.CreateOrcWriter(schema, orcFile, warehouseutils.S3Datalake)
+		require.NoError(t, err)
+		require.NoError(t, w.WriteRow([]interface{}{sourceID, "old_job_run_id", "old_task_run_id", "2", before}))
+		require.NoError(t, w.Close())
+
+		dl := New(config.New(), logger.NOP)
+		dl.fileManagerFactory = func(settings *filemanager.Settings) (filemanager.FileManager, error) {
+			return &dirFileManager{dir: dir, prefix: prefix}, nil
+		}
+		dl.Warehouse = model.Warehouse{
+			Type:      warehouseutils.S3Datalake,
+			Namespace: namespace,
+			Destination: backendconfig.DestinationT{
+				Config: map[string]interface{}{},
+			},
+		}
+
+		err = dl.DeleteBy(context.Background(), []string{tableName}, warehouseutils.DeleteByParams{
+			SourceId:  sourceID,
+			StartTime: startTime.Add(-2 * time.Hour).Format(misc.RFC3339Milli),
+			EndTime:   startTime.Format(misc.RFC3339Milli),
+		})
+		require.ErrorIs(t, err, errOrcDeleteUnsupported)
+
+		// the parquet files are left untouched as well
+		require.Equal(t, []string{"1"}, readIDs(t, filepath.Join(dir, tableDir, "1.parquet")))
+	})
+}
@@ -26,6 +26,11 @@ var (
 	glueSerdeSerializationLib = "org.apache.hadoop.hive.ql.io.parquet.serde.ParquetHiveSerDe"
 	glueParquetInputFormat    = "org.apache.hadoop.hive.ql.io.parquet.MapredParquetInputFormat"
 	glueParquetOutputFormat   = "org.apache.hadoop.hive.ql.io.parquet.MapredParquetOutputFormat"
+
+	glueOrcSerdeName             = "OrcSerde"
+	glueOrcSerdeSerializationLib = "org.apache.hadoop.hive.ql.io.orc.OrcSerde"
+	glueOrcInputFormat           = "org.apache.hadoop.hive.ql.io.orc.OrcInputFormat"
+	glueOrcOutputFormat          = "org.apache.hadoop.hive.ql.io.orc.OrcOutputFormat"
 )
 
 var (
@@ -40,6 +45,9 @@ type GlueSchemaRepository struct {
 	Warehouse  model.Warehouse
 	Namespace  string
 	logger     logger.Logger
+
+	// LoadFileType is the type of the load files, which the tables get created with
+	LoadFileType string
 }
 
 func NewGlueSchemaRepository(wh model.Warehouse, log logger.Logger) (*GlueSchemaRepository, error) {
@@ -188,8 +196,16 @@ func (gl *GlueSchemaRepository) updateTable(ctx context.Context, tableName strin
 		return fmt.Errorf("partition keys: %w", err)
 	}
 
-	// add storage descriptor to update table request
+	serdeInfo, inputFormat, outputFormat, err := gl.tableStorageFormat(ctx, tableName)
+	if err != nil {
+		return fmt.Errorf("storage format: %w", err)
+	}
+
+	// add storage descriptor to update table request, keeping the format of the table
 	updateTableInput.TableInput.StorageDescriptor = gl.getStorageDescriptor(tableName, tableSchema)
+	updateTableInput.TableInput.StorageDescriptor.SerdeInfo = serdeInfo
+	updateTableInput.TableInput.StorageDescriptor.InputFormat = aws.String(inputFormat)
+	updateTableInput.TableInput.StorageDescriptor.OutputFormat = aws.String(outputFormat)
 	updateTableInput.TableInput.PartitionKeys = partitionKeys
 
 	// update table
@@ -217,16 +233,55 @@ func getGlueClient(wh model.Warehouse) (*glue.Glue, error) {
 	return glue.New(awsSession), nil
 }
 
+// storageFormat returns the serde and the input and output formats of the load files
+func (gl *GlueSchemaRepository) storageFormat() (serdeInfo *glue.SerDeInfo, inputFormat, outputFormat string) {
+	if gl.LoadFileType == warehouseutils.LoadFileTypeOrc {
+		return &glue.SerDeInfo{
+			Name:                 aws.String(glueOrcSerdeName),
+			SerializationLibrary: aws.String(glueOrcSerdeSerializationLib),
+		}, glueOrcInputFormat, glueOrcOutputFormat
+	}
+	return &glue.SerDeInfo{
+		Name:                 aws.String(glueSerdeName),
+		SerializationLibrary: aws.String(glueSerdeSerializationLib),
+	}, glueParquetInputFormat, glueParquetOutputFormat
+}
+
+// tableStorageFormat returns the serde and the input and output formats of the table, so that existing tables keep the
+// format they were created with. The format of the load files is returned for tables which don't exist yet.
+func (gl *GlueSchemaRepository) tableStorageFormat(ctx context.Context, tableName string) (serdeInfo *glue.SerDeInfo, inputFormat, outputFormat string, err error) {
+	output, err := gl.GlueClient.GetTableWithContext(ctx, &glue.GetTableInput{
+		DatabaseName: aws.String(gl.Namespace),
+		Name:         aws.String(tableName),
+	})
+	if err != nil {
+		if _, ok := err.(*glue.EntityNotFoundException); !ok {
+			return nil, "", "", fmt.Errorf("get table: %w", err)
+		}
+		serdeInfo, inputFormat, outputFormat = gl.storageFormat()
+		return serdeInfo, inputFormat, outputFormat, nil
+	}
+
+	storageDescriptor := output.Table.StorageDescriptor
+	if storageDescriptor == nil || storageDescriptor.SerdeInfo == nil {
+		serdeInfo, inputFormat, outputFormat = gl.storageFormat()
+		return serdeInfo, inputFormat, outputFormat, nil
+	}
+
+	if _, loadFilesInputFormat, _ := gl.storageFormat(); aws.StringValue(storageDescriptor.InputFormat) != loadFilesInputFormat {
+		gl.logger.Warnf("Table %s keeps its input format %s, while the load files are %s", tableName, aws.StringValue(storageDescriptor.InputFormat), gl.LoadFileType)
+	}
+	return storageDescriptor.SerdeInfo, aws.StringValue(storageDescriptor.InputFormat), aws.StringValue(storageDescriptor.OutputFormat), nil
+}
+
 func (gl *GlueSchemaRepository) getStorageDescriptor(tableName string, columnMap model.TableSchema) *glue.StorageDescriptor {
+	serdeInfo, inputFormat, outputFormat := gl.storageFormat()
 	storageDescriptor := glue.StorageDescriptor{
-		Columns:  []*glue.Column{},
-		Location: aws.String(gl.getS3LocationForTable(tableName)),
-		SerdeInfo: &glue.SerDeInfo{
-			Name:                 aws.String(glueSerdeName),
-			SerializationLibrary: aws.String(glueSerdeSerializationLib),
-		},
-		InputFormat:  aws.String(glueParquetInputFormat),
-		OutputFormat: aws.String(glueParquetOutputFormat),
+		Columns:      []*glue.Column{},
+		Location:     aws.String(gl.getS3LocationForTable(tableName)),
+		SerdeInfo:    serdeInfo,
+		InputFormat:  aws.String(inputFormat),
+		OutputFormat: aws.String(outputFormat),
 	}
 
 	// add columns to storage descriptor
@@ -269,6 +324,10 @@ func (gl *GlueSchemaRepository) RefreshPartitions(ctx context.Context, tableName
 		partitionGroups      map[string]string
 	)
 
+	serdeInfo, inputFormat, outputFormat, err := gl.tableStorageFormat(ctx, tableName)
+	if err != nil {
+		return fmt.Errorf("storage format: %w", err)
+	}
 	for _, loadFile := range loadFiles {
 		if locationFolder, err = url.QueryUnescape(warehouseutils.GetS3LocationFolder(loadFile.Location)); err != nil {
 			return fmt.Errorf("unesscape location folder: %w", err)
@@ -286,13 +345,10 @@ func (gl *GlueSchemaRepository) RefreshPartitions(ctx context.Context, tableName
 
 		locationsToPartition[locationFolder] = &glue.PartitionInput{
 			StorageDescriptor: &glue.StorageDescriptor{
-				Location: aws.String(locationFolder),
-				SerdeInfo: &glue.SerDeInfo{
-					Name:                 aws.String(glueSerdeName),
-					SerializationLibrary: aws.String(glueSerdeSerializationLib),
-				},
-				InputFormat:  aws.String(glueParquetInputFormat),
-				OutputFormat: aws.String(glueParquetOutputFormat),
+				Location:     aws.String(locationFolder),
+				SerdeInfo:    serdeInfo,
+				InputFormat:  aws.String(inputFormat),
+				OutputFormat: aws.String(outputFormat),
 			},
 			Values: []*string{aws.String(partitionGroups["value"])},
 		}
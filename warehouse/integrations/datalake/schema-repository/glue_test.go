@@ -4,7 +4,10 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"net/http"
+	"net/http/httptest"
 	"os"
+	"sync"
 	"testing"
 
 	"github.com/rudderlabs/rudder-server/warehouse/encoding"
@@ -13,6 +16,9 @@ import (
 
 	"github.com/rudderlabs/rudder-go-kit/logger"
 
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/credentials"
+	"github.com/aws/aws-sdk-go/aws/session"
 	"github.com/aws/aws-sdk-go/service/glue"
 	"github.com/stretchr/testify/require"
 
@@ -191,3 +197,110 @@ func TestGlueSchemaRepositoryRoundTrip(t *testing.T) {
 		})
 	}
 }
+
+// newGlueServer returns a glue client for a fake glue service keeping the tables of a single database
+func newGlueServer(t testing.TB, tables map[string]map[string]interface{}) *glue.Glue {
+	t.Helper()
+
+	var mu sync.Mutex
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+
+		var input map[string]interface{}
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&input))
+
+		var output map[string]interface{}
+		switch r.Header.Get("X-Amz-Target") {
+		case "AWSGlue.GetTables":
+			tableList := make([]interface{}, 0, len(tables))
+			for _, table := range tables {
+				tableList = append(tableList, table)
+			}
+			output = map[string]interface{}{"TableList": tableList}
+		case "AWSGlue.GetTable":
+			table, ok := tables[input["Name"].(string)]
+			if !ok {
+				w.Header().Set("Content-Type", "application/x-amz-json-1.1")
+				w.WriteHeader(http.StatusBadRequest)
+				_, _ = w.Write([]byte(`{"__type":"EntityNotFoundException","Message":"table not found"}`))
+				return
+			}
+			output = map[string]interface{}{"Table": table}
+		case "AWSGlue.CreateTable", "AWSGlue.UpdateTable":
+			table := input["TableInput"].(map[string]interface{})
+			tables[table["Name"].(string)] = table
+			output = map[string]interface{}{}
+		default:
+			t.Errorf("unexpected glue operation %s", r.Header.Get("X-Amz-Target"))
+		}
+
+		w.Header().Set("Content-Type", "application/x-amz-json-1.1")
+		require.NoError(t, json.NewEncoder(w).Encode(output))
+	}))
+	t.Cleanup(srv.Close)
+
+	sess, err := session.NewSession(&aws.Config{
+		Endpoint:    aws.String(srv.URL),
+		Region:      aws.String("us-east-1"),
+		Credentials: credentials.NewStaticCredentials("accessKeyID", "secretAccessKey", ""),
+	})
+	require.NoError(t, err)
+	return glue.New(sess)
+}
+
+func TestGlueSchemaRepository_StorageFormat(t *testing.T) {
+	misc.Init()
+	warehouseutils.Init()
+
+	const namespace = "test_namespace"
+
+	tables := map[string]map[string]interface{}{
+		"parquet_table": {
+			"Name": "parquet_table",
+			"StorageDescriptor": map[string]interface{}{
+				"Columns":      []interface{}{map[string]interface{}{"Name": "id", "Type": varcharType}},
+				"InputFormat":  glueParquetInputFormat,
+				"OutputFormat": glueParquetOutputFormat,
+				"SerdeInfo": map[string]interface{}{
+					"Name":                 glueSerdeName,
+					"SerializationLibrary": glueSerdeSerializationLib,
+				},
+			},
+		},
+	}
+
+	g := &GlueSchemaRepository{
+		GlueClient:   newGlueServer(t, tables),
+		Warehouse:    model.Warehouse{Namespace: namespace, Destination: backendconfig.DestinationT{Config: map[string]interface{}{}}},
+		Namespace:    namespace,
+		logger:       logger.NOP,
+		LoadFileType: warehouseutils.LoadFileTypeOrc,
+	}
+
+	storageFormat := func(tableName string) []interface{} {
+		storageDescriptor := tables[tableName]["StorageDescriptor"].(map[string]interface{})
+		serdeInfo := storageDescriptor["SerdeInfo"].(map[string]interface{})
+		return []interface{}{storageDescriptor["InputFormat"], storageDescriptor["OutputFormat"], serdeInfo["SerializationLibrary"]}
+	}
+
+	ctx := context.Background()
+
+	t.Run("new tables get the format of the load files", func(t *testing.T) {
+		require.NoError(t, g.CreateTable(ctx, "orc_table", model.TableSchema{"id": "string"}))
+		require.Equal(t, []interface{}{glueOrcInputFormat, glueOrcOutputFormat, glueOrcSerdeSerializationLib}, storageFormat("orc_table"))
+
+		require.NoError(t, g.AddColumns(ctx, "orc_table", []warehouseutils.ColumnInfo{{Name: "test_int", Type: "int"}}))
+		require.Equal(t, []interface{}{glueOrcInputFormat, glueOrcOutputFormat, glueOrcSerdeSerializationLib}, storageFormat("orc_table"))
+	})
+
+	t.Run("existing tables keep their format", func(t *testing.T) {
+		require.NoError(t, g.AddColumns(ctx, "parquet_table", []warehouseutils.ColumnInfo{{Name: "test_int", Type: "int"}}))
+		require.Equal(t, []interface{}{glueParquetInputFormat, glueParquetOutputFormat, glueSerdeSerializationLib}, storageFormat("parquet_table"))
+
+		schema, _, err := g.FetchSchema(ctx, g.Warehouse)
+		require.NoError(t, err)
+		require.Equal(t, model.TableSchema{"id": "string", "test_int": "int"}, schema["parquet_table"])
+	})
+}
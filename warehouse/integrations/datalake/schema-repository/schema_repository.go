@@ -51,7 +51,12 @@ func UseGlue(w *model.Warehouse) bool {
 
 func NewSchemaRepository(wh model.Warehouse, uploader warehouseutils.Uploader, logger logger.Logger) (SchemaRepository, error) {
 	if UseGlue(&wh) {
-		return NewGlueSchemaRepository(wh, logger)
+		gl, err := NewGlueSchemaRepository(wh, logger)
+		if err != nil {
+			return nil, err
+		}
+		gl.LoadFileType = uploader.GetLoadFileType()
+		return gl, nil
 	}
 	return NewLocalSchemaRepository(wh, uploader)
 }
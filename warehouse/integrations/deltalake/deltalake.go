@@ -141,6 +141,7 @@ type Deltalake struct {
 		maxRetries             int
 		retryMinWait           time.Duration
 		retryMaxWait           time.Duration
+		enableDeleteByJobs     bool
 	}
 }
 
@@ -156,6 +157,7 @@ func New(conf *config.Config, log logger.Logger, stat stats.Stats) *Deltalake {
 	dl.config.maxRetries = conf.GetInt("Warehouse.deltalake.maxRetries", 10)
 	dl.config.retryMinWait = conf.GetDuration("Warehouse.deltalake.retryMinWait", 1, time.Second)
 	dl.config.retryMaxWait = conf.GetDuration("Warehouse.deltalake.retryMaxWait", 300, time.Second)
+	dl.config.enableDeleteByJobs = conf.GetBool("Warehouse.deltalake.enableDeleteByJobs", false)
 
 	return dl
 }
@@ -579,6 +581,13 @@ func (d *Deltalake) loadTable(ctx context.Context, tableName string, tableSchema
 		logfield.TableName, tableName,
 	)
 
+	mergeKeys, hasMergeKeys := warehouseutils.GetMergeKeys(d.Warehouse, tableName)
+	if hasMergeKeys {
+		if err = mergeKeys.Validate(tableSchemaInUpload); err != nil {
+			return "", fmt.Errorf("validating merge keys: %w", err)
+		}
+	}
+
 	if err = d.CreateTable(ctx, stagingTableName, tableSchemaAfterUpload); err != nil {
 		return "", fmt.Errorf("creating staging table: %w", err)
 	}
@@ -656,7 +665,10 @@ func (d *Deltalake) loadTable(ctx context.Context, tableName string, tableSchema
 		return "", fmt.Errorf("running COPY command: %w", err)
 	}
 
-	if d.config.loadTableStrategy == appendMode {
+	// Tables with merge keys are always merged, even with the append load table strategy
+	appendOnly := d.config.loadTableStrategy == appendMode && !hasMergeKeys
+
+	if appendOnly {
 		query = fmt.Sprintf(`
 			INSERT INTO %[1]s.%[2]s (%[4]s)
 			SELECT
@@ -693,6 +705,20 @@ func (d *Deltalake) loadTable(ctx context.Context, tableName string, tableSchema
 		}
 
 		pk := primaryKey(tableName)
+		joinClause := fmt.Sprintf(`MAIN.%[1]s = STAGING.%[1]s`, pk)
+		orderByClause := "RECEIVED_AT DESC"
+		matchedClause := "WHEN MATCHED THEN"
+
+		if hasMergeKeys {
+			pk = columnNames(mergeKeys.PrimaryKeys)
+			joinClause = warehouseutils.JoinWithFormatting(mergeKeys.PrimaryKeys, func(_ int, name string) string {
+				return fmt.Sprintf(`MAIN.%[1]s = STAGING.%[1]s`, name)
+			}, " AND ")
+			orderByClause = fmt.Sprintf(`%s DESC NULLS LAST`, mergeKeys.OrderByColumn(warehouseutils.DELTALAKE))
+			if mergeKeys.LatestBy != "" {
+				matchedClause = fmt.Sprintf(`WHEN MATCHED AND (MAIN.%[1]s IS NULL OR STAGING.%[1]s >= MAIN.%[1]s) THEN`, mergeKeys.LatestBy)
+			}
+		}
 
 		query = fmt.Sprintf(`
 			MERGE INTO %[1]s.%[2]s AS MAIN USING (
@@ -705,7 +731,7 @@ func (d *Deltalake) loadTable(ctx context.Context, tableName string, tableSchema
 					row_number() OVER (
 					  PARTITION BY %[4]s
 					  ORDER BY
-						RECEIVED_AT DESC
+						%[10]s
 					) AS _rudder_staging_row_number
 				  FROM
 					%[1]s.%[3]s
@@ -713,8 +739,8 @@ func (d *Deltalake) loadTable(ctx context.Context, tableName string, tableSchema
 			  WHERE
 				_rudder_staging_row_number = 1
 			)
-			AS STAGING ON %[8]s MAIN.%[4]s = STAGING.%[4]s
-			WHEN MATCHED THEN
+			AS STAGING ON %[8]s %[9]s
+			%[11]s
 			UPDATE
 			SET
 			  %[5]s
@@ -731,6 +757,9 @@ func (d *Deltalake) loadTable(ctx context.Context, tableName string, tableSchema
 			columnNames(sortedColumnKeys),
 			stagingColumnNames(sortedColumnKeys),
 			partitionQuery,
+			joinClause,
+			orderByClause,
+			matchedClause,
 		)
 	}
 
@@ -743,7 +772,7 @@ func (d *Deltalake) loadTable(ctx context.Context, tableName string, tableSchema
 		inserted int64
 	)
 
-	if d.config.loadTableStrategy == appendMode {
+	if appendOnly {
 		err = row.Scan(&affected, &inserted)
 	} else {
 		err = row.Scan(&affected, &updated, &deleted, &inserted)
@@ -1338,6 +1367,117 @@ func (d *Deltalake) DropTable(ctx context.Context, tableName string) error {
 	return d.dropTable(ctx, tableName)
 }
 
-func (*Deltalake) DeleteBy(context.Context, []string, warehouseutils.DeleteByParams) error {
-	return fmt.Errorf(warehouseutils.NotImplementedErrorCode)
+// DeleteBy deletes the rows of the previous runs of a source, received before the start time of the current run
+func (d *Deltalake) DeleteBy(ctx context.Context, tableNames []string, params warehouseutils.DeleteByParams) error {
+	if params.EndTime != "" {
+		return d.deleteReceivedInRange(ctx, tableNames, params)
+	}
+
+	for _, tableName := range tableNames {
+		// The driver doesn't support query parameters, hence the values are escaped as string literals.
+		query := fmt.Sprintf(`
+			DELETE FROM
+			  %[1]s.%[2]s
+			WHERE
+			  context_sources_job_run_id <> %[3]s
+			  AND context_sources_task_run_id <> %[4]s
+			  AND context_source_id = %[5]s
+			  AND received_at < %[6]s;
+		`,
+			d.Namespace,
+			tableName,
+			stringLiteral(params.JobRunId),
+			stringLiteral(params.TaskRunId),
+			stringLiteral(params.SourceId),
+			stringLiteral(params.StartTime),
+		)
+
+		d.logger.Infow("deleting by",
+			logfield.SourceID, d.Warehouse.Source.ID,
+			logfield.SourceType, d.Warehouse.Source.SourceDefinition.Name,
+			logfield.DestinationID, d.Warehouse.Destination.ID,
+			logfield.DestinationType, d.Warehouse.Destination.DestinationDefinition.Name,
+			logfield.WorkspaceID, d.Warehouse.WorkspaceID,
+			logfield.Namespace, d.Namespace,
+			logfield.TableName, tableName,
+			logfield.Query, query,
+		)
+
+		if !d.config.enableDeleteByJobs {
+			continue
+		}
+		if _, err := d.DB.ExecContext(ctx, query); err != nil {
+			return fmt.Errorf("deleting from table %s: %w", tableName, err)
+		}
+	}
+	return nil
+}
+
+// deleteReceivedInRange deletes the rows of the source received in the range [StartTime, EndTime), irrespective of the runs they were loaded by
+func (d *Deltalake) deleteReceivedInRange(ctx context.Context, tableNames []string, params warehouseutils.DeleteByParams) error {
+	for _, tableName := range tableNames {
+		query := fmt.Sprintf(`
+			DELETE FROM
+			  %[1]s.%[2]s
+			WHERE
+			  context_source_id = %[3]s
+			  AND received_at >= %[4]s
+			  AND received_at < %[5]s;
+		`,
+			d.Namespace,
+			tableName,
+			stringLiteral(params.SourceId),
+			stringLiteral(params.StartTime),
+			stringLiteral(params.EndTime),
+		)
+
+		d.logger.Infow("deleting received in range",
+			logfield.SourceID, d.Warehouse.Source.ID,
+			logfield.SourceType, d.Warehouse.Source.SourceDefinition.Name,
+			logfield.DestinationID, d.Warehouse.Destination.ID,
+			logfield.DestinationType, d.Warehouse.Destination.DestinationDefinition.Name,
+			logfield.WorkspaceID, d.Warehouse.WorkspaceID,
+			logfield.Namespace, d.Namespace,
+			logfield.TableName, tableName,
+			logfield.Query, query,
+		)
+
+		if _, err := d.DB.ExecContext(ctx, query); err != nil {
+			return fmt.Errorf("deleting from table %s: %w", tableName, err)
+		}
+	}
+	return nil
+}
+
+// DeleteByColumnValues deletes the rows of the table having any of the values in the column
+func (d *Deltalake) DeleteByColumnValues(ctx context.Context, tableName, columnName string, values []string) error {
+	literals := warehouseutils.JoinWithFormatting(values, func(_ int, value string) string {
+		return stringLiteral(value)
+	}, ", ")
+	query := fmt.Sprintf("DELETE FROM %[1]s.%[2]s WHERE `%[3]s` IN (%[4]s);",
+		d.Namespace,
+		tableName,
+		columnName,
+		literals,
+	)
+
+	d.logger.Infow("deleting by column values",
+		logfield.DestinationID, d.Warehouse.Destination.ID,
+		logfield.DestinationType, d.Warehouse.Destination.DestinationDefinition.Name,
+		logfield.WorkspaceID, d.Warehouse.WorkspaceID,
+		logfield.Namespace, d.Namespace,
+		logfield.TableName, tableName,
+		logfield.ColumnName, columnName,
+		logfield.Query, query,
+	)
+
+	if _, err := d.DB.ExecContext(ctx, query); err != nil {
+		return fmt.Errorf("deleting from table %s: %w", tableName, err)
+	}
+	return nil
+}
+
+// stringLiteral returns the value as a Spark SQL string literal
+func stringLiteral(value string) string {
+	return "'" + strings.NewReplacer(`\`, `\\`, `'`, `\'`).Replace(value) + "'"
 }
@@ -19,6 +19,7 @@ import (
 	"github.com/rudderlabs/rudder-server/warehouse/integrations/postgres"
 	"github.com/rudderlabs/rudder-server/warehouse/integrations/redshift"
 	"github.com/rudderlabs/rudder-server/warehouse/integrations/snowflake"
+	"github.com/rudderlabs/rudder-server/warehouse/integrations/sqlite"
 
 	"github.com/rudderlabs/rudder-go-kit/config"
 	"github.com/rudderlabs/rudder-server/utils/misc"
@@ -49,9 +50,16 @@ type Manager interface {
 	ErrorMappings() []model.JobError
 }
 
+// LoadCostReporter is implemented by the warehouses which can report the cost of the queries run for loading a table,
+// e.g. the bytes billed by BigQuery or the credits used by Snowflake.
+type LoadCostReporter interface {
+	LoadTableCost(ctx context.Context, tableName string) (model.LoadCost, error)
+}
+
 type WarehouseDelete interface {
 	DropTable(ctx context.Context, tableName string) (err error)
 	DeleteBy(ctx context.Context, tableName []string, params warehouseutils.DeleteByParams) error
+	DeleteByColumnValues(ctx context.Context, tableName, columnName string, values []string) error
 }
 
 type WarehouseOperations interface {
@@ -77,13 +85,29 @@ func New(destType string, conf *config.Config, logger logger.Logger, stats stats
 	case warehouseutils.AzureSynapse:
 		return azuresynapse.New(conf, logger), nil
 	case warehouseutils.S3Datalake, warehouseutils.GCSDatalake, warehouseutils.AzureDatalake:
-		return datalake.New(logger), nil
+		return datalake.New(conf, logger), nil
 	case warehouseutils.DELTALAKE:
 		return deltalake.New(conf, logger, stats), nil
+	case warehouseutils.SQLITE:
+		return sqlite.New(conf, logger, stats), nil
 	}
 	return nil, fmt.Errorf("provider of type %s is not configured for WarehouseManager", destType)
 }
 
+// LoadFileType returns the type of the load files of the warehouse, declared by the integrations supporting more than
+// one type from the configuration of the destination
+func LoadFileType(warehouse model.Warehouse) string {
+	switch warehouse.Type {
+	case warehouseutils.CLICKHOUSE:
+		return clickhouse.LoadFileType(warehouse)
+	case warehouseutils.S3Datalake, warehouseutils.GCSDatalake, warehouseutils.AzureDatalake:
+		return datalake.LoadFileType(warehouse)
+	case warehouseutils.SQLITE:
+		return sqlite.LoadFileType(warehouse)
+	}
+	return warehouseutils.GetLoadFileType(warehouse.Type)
+}
+
 // NewWarehouseOperations is a Factory function that returns a WarehouseOperations of a given destination-type
 func NewWarehouseOperations(destType string, conf *config.Config, logger logger.Logger, stats stats.Stats) (WarehouseOperations, error) {
 	switch destType {
@@ -102,9 +126,11 @@ func NewWarehouseOperations(destType string, conf *config.Config, logger logger.
 	case warehouseutils.AzureSynapse:
 		return azuresynapse.New(conf, logger), nil
 	case warehouseutils.S3Datalake, warehouseutils.GCSDatalake, warehouseutils.AzureDatalake:
-		return datalake.New(logger), nil
+		return datalake.New(conf, logger), nil
 	case warehouseutils.DELTALAKE:
 		return deltalake.New(conf, logger, stats), nil
+	case warehouseutils.SQLITE:
+		return sqlite.New(conf, logger, stats), nil
 	}
 	return nil, fmt.Errorf("provider of type %s is not configured for WarehouseManager", destType)
 }
@@ -0,0 +1,75 @@
+package manager_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	backendconfig "github.com/rudderlabs/rudder-server/backend-config"
+	"github.com/rudderlabs/rudder-server/warehouse/integrations/manager"
+	"github.com/rudderlabs/rudder-server/warehouse/internal/model"
+	warehouseutils "github.com/rudderlabs/rudder-server/warehouse/utils"
+)
+
+func TestLoadFileType(t *testing.T) {
+	testCases := []struct {
+		name     string
+		destType string
+		config   map[string]interface{}
+		expected string
+	}{
+		{
+			name:     "default",
+			destType: warehouseutils.BQ,
+			config:   map[string]interface{}{"useArrowLoadFiles": true},
+			expected: warehouseutils.LoadFileTypeJson,
+		},
+		{
+			name:     "clickhouse",
+			destType: warehouseutils.CLICKHOUSE,
+			expected: warehouseutils.LoadFileTypeCsv,
+		},
+		{
+			name:     "clickhouse with arrow load files",
+			destType: warehouseutils.CLICKHOUSE,
+			config:   map[string]interface{}{"useArrowLoadFiles": true},
+			expected: warehouseutils.LoadFileTypeArrow,
+		},
+		{
+			name:     "datalake",
+			destType: warehouseutils.S3Datalake,
+			expected: warehouseutils.LoadFileTypeParquet,
+		},
+		{
+			name:     "datalake with orc load files",
+			destType: warehouseutils.GCSDatalake,
+			config:   map[string]interface{}{"useOrcLoadFiles": true},
+			expected: warehouseutils.LoadFileTypeOrc,
+		},
+		{
+			name:     "sqlite",
+			destType: warehouseutils.SQLITE,
+			config:   map[string]interface{}{"useParquetLoadFiles": false},
+			expected: warehouseutils.LoadFileTypeCsv,
+		},
+		{
+			name:     "sqlite with parquet load files",
+			destType: warehouseutils.SQLITE,
+			config:   map[string]interface{}{"useParquetLoadFiles": true},
+			expected: warehouseutils.LoadFileTypeParquet,
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+
+		t.Run(tc.name, func(t *testing.T) {
+			require.Equal(t, tc.expected, manager.LoadFileType(model.Warehouse{
+				Type: tc.destType,
+				Destination: backendconfig.DestinationT{
+					Config: tc.config,
+				},
+			}))
+		})
+	}
+}
@@ -200,6 +200,10 @@ func (*MSSQL) IsEmpty(context.Context, model.Warehouse) (empty bool, err error)
 }
 
 func (ms *MSSQL) DeleteBy(ctx context.Context, tableNames []string, params warehouseutils.DeleteByParams) (err error) {
+	if params.EndTime != "" {
+		return ms.deleteReceivedInRange(ctx, tableNames, params)
+	}
+
 	for _, tb := range tableNames {
 		ms.logger.Infof("MSSQL: Cleaning up the table %q ", tb)
 		sqlStatement := fmt.Sprintf(`DELETE FROM "%[1]s"."%[2]s" WHERE
@@ -231,6 +235,53 @@ func (ms *MSSQL) DeleteBy(ctx context.Context, tableNames []string, params wareh
 	return nil
 }
 
+// deleteReceivedInRange deletes the rows of the source received in the range [StartTime, EndTime), irrespective of the runs they were loaded by
+func (ms *MSSQL) deleteReceivedInRange(ctx context.Context, tableNames []string, params warehouseutils.DeleteByParams) error {
+	for _, tableName := range tableNames {
+		sqlStatement := fmt.Sprintf(`DELETE FROM "%[1]s"."%[2]s" WHERE context_source_id = @sourceid AND received_at >= @starttime AND received_at < @endtime`,
+			ms.Namespace,
+			tableName,
+		)
+
+		ms.logger.Infof("MSSQL: Deleting rows of source %s received between %s and %s in table %s for MSSQL:%s", params.SourceId, params.StartTime, params.EndTime, tableName, ms.Warehouse.Destination.ID)
+		ms.logger.Debugf("MSSQL: Executing the statement %v", sqlStatement)
+
+		if _, err := ms.DB.ExecContext(ctx, sqlStatement,
+			sql.Named("sourceid", params.SourceId),
+			sql.Named("starttime", params.StartTime),
+			sql.Named("endtime", params.EndTime),
+		); err != nil {
+			return fmt.Errorf("deleting from table %s: %w", tableName, err)
+		}
+	}
+	return nil
+}
+
+// DeleteByColumnValues deletes the rows of the table having any of the values in the column
+func (ms *MSSQL) DeleteByColumnValues(ctx context.Context, tableName, columnName string, values []string) error {
+	placeholders := warehouseutils.JoinWithFormatting(values, func(idx int, _ string) string {
+		return fmt.Sprintf("@p%d", idx+1)
+	}, ",")
+	sqlStatement := fmt.Sprintf(`DELETE FROM "%[1]s"."%[2]s" WHERE "%[3]s" IN (%[4]s)`,
+		ms.Namespace,
+		tableName,
+		columnName,
+		placeholders,
+	)
+
+	ms.logger.Infof("MSSQL: Deleting %d values of column %s in table %s for MSSQL:%s", len(values), columnName, tableName, ms.Warehouse.Destination.ID)
+	ms.logger.Debugf("MSSQL: Executing the statement %v", sqlStatement)
+
+	args := make([]interface{}, len(values))
+	for i, value := range values {
+		args[i] = value
+	}
+	if _, err := ms.DB.ExecContext(ctx, sqlStatement, args...); err != nil {
+		return fmt.Errorf("deleting from table %s: %w", tableName, err)
+	}
+	return nil
+}
+
 func (ms *MSSQL) loadTable(ctx context.Context, tableName string, tableSchemaInUpload model.TableSchema, skipTempTableDelete bool) (stagingTableName string, err error) {
 	ms.logger.Infof("MSSQL: Starting load for table:%s", tableName)
 
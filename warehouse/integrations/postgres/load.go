@@ -72,6 +72,13 @@ func (pg *Postgres) loadTable(
 	tableName string,
 	tableSchemaInUpload model.TableSchema,
 ) (loadTableResponse, error) {
+	mergeKeys, hasMergeKeys := warehouseutils.GetMergeKeys(pg.Warehouse, tableName)
+	if hasMergeKeys {
+		if err := mergeKeys.Validate(tableSchemaInUpload); err != nil {
+			return loadTableResponse{}, fmt.Errorf("validating merge keys: %w", err)
+		}
+	}
+
 	query := fmt.Sprintf(`SET search_path TO %q;`, pg.Namespace)
 	if _, err := txn.ExecContext(ctx, query); err != nil {
 		return loadTableResponse{}, fmt.Errorf("setting search path: %w", err)
@@ -194,6 +201,41 @@ func (pg *Postgres) loadTable(
 		)
 	}
 
+	joinClause := fmt.Sprintf(`_source.%[3]s = %[1]q.%[2]q.%[3]q`, pg.Namespace, tableName, primaryKey)
+	orderByClause := "received_at DESC"
+
+	// Tables with merge keys are upserted on the primary keys, keeping the latest record.
+	// With latestBy, newer rows already present in the table are kept, skipping the older ones from the staging table.
+	var skipExistingClause string
+	if hasMergeKeys {
+		partitionKey = warehouseutils.DoubleQuoteAndJoinByComma(mergeKeys.PrimaryKeys)
+		joinClause = warehouseutils.JoinWithFormatting(mergeKeys.PrimaryKeys, func(_ int, name string) string {
+			return fmt.Sprintf(`_source.%[3]q = %[1]q.%[2]q.%[3]q`, pg.Namespace, tableName, name)
+		}, " AND ")
+		orderByClause = fmt.Sprintf(`%q DESC NULLS LAST`, mergeKeys.OrderByColumn(warehouseutils.POSTGRES))
+		if mergeKeys.LatestBy != "" {
+			additionalJoinClause = fmt.Sprintf(
+				`AND (%[1]q.%[2]q.%[3]q IS NULL OR _source.%[3]q >= %[1]q.%[2]q.%[3]q)`,
+				pg.Namespace,
+				tableName,
+				mergeKeys.LatestBy,
+			)
+			skipExistingClause = fmt.Sprintf(`
+			AND NOT EXISTS (
+			  SELECT
+				1
+			  FROM
+				%[1]q.%[2]q
+			  WHERE
+				%[3]s
+			)`,
+				pg.Namespace,
+				tableName,
+				joinClause,
+			)
+		}
+	}
+
 	// Deduplication
 	// Delete rows from the table which are already present in the staging table
 	query = fmt.Sprintf(`
@@ -201,13 +243,13 @@ func (pg *Postgres) loadTable(
 		  %[1]q.%[2]q USING %[3]q AS _source
 		WHERE
 		  (
-			_source.%[4]s = %[1]q.%[2]q.%[4]q %[5]s
+			%[4]s %[5]s
 		  );
 	`,
 		pg.Namespace,
 		tableName,
 		stagingTableName,
-		primaryKey,
+		joinClause,
 		additionalJoinClause,
 	)
 
@@ -258,19 +300,21 @@ func (pg *Postgres) loadTable(
 			  ROW_NUMBER() OVER (
 				PARTITION BY %[5]s
 				ORDER BY
-				  received_at DESC
+				  %[6]s
 			  ) AS _rudder_staging_row_number
 			FROM
 			  %[4]q
-		  ) AS _
+		  ) AS _source
 		WHERE
-		  _rudder_staging_row_number = 1;
+		  _rudder_staging_row_number = 1 %[7]s;
 	`,
 		pg.Namespace,
 		tableName,
 		quotedColumnNames,
 		stagingTableName,
 		partitionKey,
+		orderByClause,
+		skipExistingClause,
 	)
 
 	pg.logger.Infow("inserting records",
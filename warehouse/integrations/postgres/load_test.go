@@ -139,6 +139,8 @@ func TestLoadTable(t *testing.T) {
 			mockFiles                    []string
 			additionalFiles              []string
 			queryExecEnabledWorkspaceIDs []string
+			destConfig                   map[string]interface{}
+			wantRecords                  [][]string
 		}{
 			{
 				name:               "schema not present",
@@ -182,6 +184,36 @@ func TestLoadTable(t *testing.T) {
 				mockFiles:                    []string{"load.csv.gz"},
 				queryExecEnabledWorkspaceIDs: []string{workspaceID},
 			},
+			{
+				name:      "merge keys",
+				mockFiles: []string{"merge.csv.gz"},
+				destConfig: map[string]interface{}{
+					"mergeKeys": []interface{}{
+						map[string]interface{}{"table": tableName, "primaryKeys": []interface{}{"test_string", "test_int"}},
+					},
+				},
+				wantRecords: [][]string{{"2", "a"}, {"4", "b"}},
+			},
+			{
+				name:      "merge keys with latest by",
+				mockFiles: []string{"merge.csv.gz"},
+				destConfig: map[string]interface{}{
+					"mergeKeys": []interface{}{
+						map[string]interface{}{"table": tableName, "primaryKeys": "test_string", "latestBy": "test_datetime"},
+					},
+				},
+				wantRecords: [][]string{{"1", "a"}, {"3", "b"}},
+			},
+			{
+				name:      "merge keys not in schema",
+				mockFiles: []string{"merge.csv.gz"},
+				destConfig: map[string]interface{}{
+					"mergeKeys": []interface{}{
+						map[string]interface{}{"table": tableName, "primaryKeys": "test_string", "latestBy": "updated_at"},
+					},
+				},
+				wantError: errors.New("loading table: executing transaction: validating merge keys: merge key updated_at is not a column of the table"),
+			},
 			{
 				name:          "context cancelled",
 				mockFiles:     []string{"load.csv.gz"},
@@ -244,6 +276,7 @@ func TestLoadTable(t *testing.T) {
 				pg.DB = db
 				pg.Namespace = namespace
 				pg.Warehouse = warehouse
+				pg.Warehouse.Destination.Config = tc.destConfig
 				pg.stats = store
 				pg.LoadFileDownloader = &mockLoadFileUploader{
 					mockFiles: map[string][]string{
@@ -273,6 +306,31 @@ func TestLoadTable(t *testing.T) {
 					return
 				}
 				require.NoError(t, err)
+
+				if tc.wantRecords == nil {
+					return
+				}
+
+				// Loading the same records again should not duplicate them
+				pg.LoadFileDownloader = &mockLoadFileUploader{
+					mockFiles: map[string][]string{
+						tableName: cloneFiles(t, tc.mockFiles),
+					},
+				}
+				require.NoError(t, pg.LoadTable(ctx, tableName))
+
+				rows, err := db.QueryContext(ctx, fmt.Sprintf(`SELECT id, test_string FROM %s.%s ORDER BY id;`, namespace, tableName))
+				require.NoError(t, err)
+				defer func() { _ = rows.Close() }()
+
+				var records [][]string
+				for rows.Next() {
+					var id, testString string
+					require.NoError(t, rows.Scan(&id, &testString))
+					records = append(records, []string{id, testString})
+				}
+				require.NoError(t, rows.Err())
+				require.Equal(t, tc.wantRecords, records)
 			})
 		}
 	})
@@ -602,3 +660,66 @@ func TestLoadUsersTable(t *testing.T) {
 		})
 	}
 }
+
+func TestDeleteBy_ReceivedInRange(t *testing.T) {
+	t.Parallel()
+
+	misc.Init()
+	warehouseutils.Init()
+
+	pool, err := dockertest.NewPool("")
+	require.NoError(t, err)
+
+	pgResource, err := resource.SetupPostgres(pool, t)
+	require.NoError(t, err)
+
+	const (
+		namespace = "test_namespace"
+		tableName = "test_table"
+	)
+
+	db := sqlmiddleware.New(pgResource.DB)
+	_, err = db.Exec("CREATE SCHEMA IF NOT EXISTS " + namespace)
+	require.NoError(t, err)
+	_, err = db.Exec(fmt.Sprintf(`
+		CREATE TABLE %s.%s (
+			id varchar(255),
+			context_source_id varchar(255),
+			context_sources_job_run_id varchar(255),
+			received_at timestamptz
+		);
+		INSERT INTO %[1]s.%[2]s VALUES
+			('1', 'source_id', NULL, '2023-01-01T00:00:00Z'),
+			('2', 'source_id', 'job_run_id', '2023-01-01T00:00:00Z'),
+			('3', 'source_id', NULL, '2023-01-03T00:00:00Z'),
+			('4', 'other_source_id', NULL, '2023-01-01T00:00:00Z'),
+			('5', 'source_id', NULL, '2022-12-31T00:00:00Z');
+	`,
+		namespace,
+		tableName,
+	))
+	require.NoError(t, err)
+
+	pg := New(config.New(), logger.NOP, memstats.New())
+	pg.DB = db
+	pg.Namespace = namespace
+
+	require.NoError(t, pg.DeleteBy(context.Background(), []string{tableName}, warehouseutils.DeleteByParams{
+		SourceId:  "source_id",
+		StartTime: time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC).Format(misc.RFC3339Milli),
+		EndTime:   time.Date(2023, 1, 2, 0, 0, 0, 0, time.UTC).Format(misc.RFC3339Milli),
+	}))
+
+	rows, err := db.Query(fmt.Sprintf(`SELECT id FROM %s.%s ORDER BY id;`, namespace, tableName))
+	require.NoError(t, err)
+	defer func() { _ = rows.Close() }()
+
+	var ids []string
+	for rows.Next() {
+		var id string
+		require.NoError(t, rows.Scan(&id))
+		ids = append(ids, id)
+	}
+	require.NoError(t, rows.Err())
+	require.Equal(t, []string{"3", "4", "5"}, ids)
+}
@@ -10,6 +10,8 @@ import (
 	"strings"
 	"time"
 
+	"github.com/lib/pq"
+
 	"github.com/rudderlabs/rudder-go-kit/stats"
 
 	sqlmiddleware "github.com/rudderlabs/rudder-server/warehouse/integrations/middleware/sqlquerywrapper"
@@ -270,6 +272,10 @@ func (*Postgres) IsEmpty(context.Context, model.Warehouse) (empty bool, err erro
 
 // DeleteBy Need to create a structure with delete parameters instead of simply adding a long list of params
 func (pg *Postgres) DeleteBy(ctx context.Context, tableNames []string, params warehouseutils.DeleteByParams) (err error) {
+	if params.EndTime != "" {
+		return pg.deleteReceivedInRange(ctx, tableNames, params)
+	}
+
 	pg.logger.Infof("PG: Cleaning up the following tables in postgres for PG:%s : %+v", tableNames, params)
 	for _, tb := range tableNames {
 		sqlStatement := fmt.Sprintf(`DELETE FROM "%[1]s"."%[2]s" WHERE
@@ -298,6 +304,41 @@ func (pg *Postgres) DeleteBy(ctx context.Context, tableNames []string, params wa
 	return nil
 }
 
+// deleteReceivedInRange deletes the rows of the source received in the range [StartTime, EndTime), irrespective of the runs they were loaded by
+func (pg *Postgres) deleteReceivedInRange(ctx context.Context, tableNames []string, params warehouseutils.DeleteByParams) error {
+	for _, tableName := range tableNames {
+		sqlStatement := fmt.Sprintf(`DELETE FROM "%[1]s"."%[2]s" WHERE context_source_id = $1 AND received_at >= $2 AND received_at < $3;`,
+			pg.Namespace,
+			tableName,
+		)
+
+		pg.logger.Infof("PG: Deleting rows of source %s received between %s and %s in table %s for PG:%s", params.SourceId, params.StartTime, params.EndTime, tableName, pg.Warehouse.Destination.ID)
+		pg.logger.Debugf("PG: Executing the statement  %v", sqlStatement)
+
+		if _, err := pg.DB.ExecContext(ctx, sqlStatement, params.SourceId, params.StartTime, params.EndTime); err != nil {
+			return fmt.Errorf("deleting from table %s: %w", tableName, err)
+		}
+	}
+	return nil
+}
+
+// DeleteByColumnValues deletes the rows of the table having any of the values in the column
+func (pg *Postgres) DeleteByColumnValues(ctx context.Context, tableName, columnName string, values []string) error {
+	sqlStatement := fmt.Sprintf(`DELETE FROM "%[1]s"."%[2]s" WHERE "%[3]s" = ANY($1);`,
+		pg.Namespace,
+		tableName,
+		columnName,
+	)
+
+	pg.logger.Infof("PG: Deleting %d values of column %s in table %s for PG:%s", len(values), columnName, tableName, pg.Warehouse.Destination.ID)
+	pg.logger.Debugf("PG: Executing the statement  %v", sqlStatement)
+
+	if _, err := pg.DB.ExecContext(ctx, sqlStatement, pq.Array(values)); err != nil {
+		return fmt.Errorf("deleting from table %s: %w", tableName, err)
+	}
+	return nil
+}
+
 func (pg *Postgres) schemaExists(ctx context.Context, _ string) (exists bool, err error) {
 	sqlStatement := fmt.Sprintf(`SELECT EXISTS (SELECT 1 FROM pg_catalog.pg_namespace WHERE nspname = '%s');`, pg.Namespace)
 	err = pg.DB.QueryRowContext(ctx, sqlStatement).Scan(&exists)
@@ -379,7 +420,30 @@ func (pg *Postgres) AddColumns(ctx context.Context, tableName string, columnsInf
 	return
 }
 
-func (*Postgres) AlterColumn(context.Context, string, string, string) (model.AlterTableResponse, error) {
+// AlterColumn changes the type of the column, casting the existing values to the new type.
+// Used for widening the columns, e.g. from bigint to numeric or from numeric to text.
+func (pg *Postgres) AlterColumn(ctx context.Context, tableName, columnName, columnType string) (model.AlterTableResponse, error) {
+	dataType, ok := rudderDataTypesMapToPostgres[columnType]
+	if !ok {
+		return model.AlterTableResponse{}, fmt.Errorf("altering column %s of %s: unsupported type %s", columnName, tableName, columnType)
+	}
+
+	query := fmt.Sprintf(`
+		ALTER TABLE
+		  %[1]q.%[2]q
+		ALTER COLUMN
+		  %[3]q TYPE %[4]s USING %[3]q::%[4]s;
+	`,
+		pg.Namespace,
+		tableName,
+		columnName,
+		dataType,
+	)
+
+	pg.logger.Infof("PG: Altering column for destinationID: %s, tableName: %s with query: %v", pg.Warehouse.Destination.ID, tableName, query)
+	if _, err := pg.DB.ExecContext(ctx, query); err != nil {
+		return model.AlterTableResponse{}, fmt.Errorf("altering column %s of %s: %w", columnName, tableName, err)
+	}
 	return model.AlterTableResponse{}, nil
 }
 
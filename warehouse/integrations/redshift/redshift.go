@@ -151,6 +151,7 @@ type Redshift struct {
 	connectTimeout time.Duration
 	logger         logger.Logger
 	stats          stats.Stats
+	loadQueryTags  warehouseutils.LoadQueryTags
 
 	config struct {
 		slowQueryThreshold            time.Duration
@@ -307,6 +308,10 @@ func CheckAndIgnoreColumnAlreadyExistError(err error) bool {
 }
 
 func (rs *Redshift) DeleteBy(ctx context.Context, tableNames []string, params warehouseutils.DeleteByParams) (err error) {
+	if params.EndTime != "" {
+		return rs.deleteReceivedInRange(ctx, tableNames, params)
+	}
+
 	rs.logger.Infof("RS: Cleaning up the following tables in redshift for RS:%s : %+v", tableNames, params)
 	rs.logger.Infof("RS: Flag for enableDeleteByJobs is %t", rs.config.enableDeleteByJobs)
 	for _, tb := range tableNames {
@@ -339,6 +344,49 @@ func (rs *Redshift) DeleteBy(ctx context.Context, tableNames []string, params wa
 	return nil
 }
 
+// deleteReceivedInRange deletes the rows of the source received in the range [StartTime, EndTime), irrespective of the runs they were loaded by
+func (rs *Redshift) deleteReceivedInRange(ctx context.Context, tableNames []string, params warehouseutils.DeleteByParams) error {
+	for _, tableName := range tableNames {
+		sqlStatement := fmt.Sprintf(`DELETE FROM "%[1]s"."%[2]s" WHERE context_source_id = $1 AND received_at >= $2 AND received_at < $3;`,
+			rs.Namespace,
+			tableName,
+		)
+
+		rs.logger.Infof("RS: Deleting rows of source %s received between %s and %s in table %s for RS:%s", params.SourceId, params.StartTime, params.EndTime, tableName, rs.Warehouse.Destination.ID)
+		rs.logger.Infof("RS: Executing the query %v", sqlStatement)
+
+		if _, err := rs.DB.ExecContext(ctx, sqlStatement, params.SourceId, params.StartTime, params.EndTime); err != nil {
+			return fmt.Errorf("deleting from table %s: %w", tableName, err)
+		}
+	}
+	return nil
+}
+
+// DeleteByColumnValues deletes the rows of the table having any of the values in the column
+func (rs *Redshift) DeleteByColumnValues(ctx context.Context, tableName, columnName string, values []string) error {
+	placeholders := warehouseutils.JoinWithFormatting(values, func(idx int, _ string) string {
+		return fmt.Sprintf("$%d", idx+1)
+	}, ",")
+	sqlStatement := fmt.Sprintf(`DELETE FROM "%[1]s"."%[2]s" WHERE "%[3]s" IN (%[4]s);`,
+		rs.Namespace,
+		tableName,
+		columnName,
+		placeholders,
+	)
+
+	rs.logger.Infof("RS: Deleting %d values of column %s in table %s for RS:%s", len(values), columnName, tableName, rs.Warehouse.Destination.ID)
+	rs.logger.Debugf("RS: Executing the query %v", sqlStatement)
+
+	args := make([]interface{}, len(values))
+	for i, value := range values {
+		args[i] = value
+	}
+	if _, err := rs.DB.ExecContext(ctx, sqlStatement, args...); err != nil {
+		return fmt.Errorf("deleting from table %s: %w", tableName, err)
+	}
+	return nil
+}
+
 func (rs *Redshift) createSchema(ctx context.Context) (err error) {
 	sqlStatement := fmt.Sprintf(`CREATE SCHEMA IF NOT EXISTS %q`, rs.Namespace)
 	rs.logger.Infof("Creating schema name in redshift for RS:%s : %v", rs.Warehouse.Destination.ID, sqlStatement)
@@ -432,6 +480,13 @@ func (rs *Redshift) loadTable(ctx context.Context, tableName string, tableSchema
 		logfield.TableName, tableName,
 	)
 
+	mergeKeys, hasMergeKeys := warehouseutils.GetMergeKeys(rs.Warehouse, tableName)
+	if hasMergeKeys {
+		if err = mergeKeys.Validate(tableSchemaInUpload); err != nil {
+			return "", fmt.Errorf("validating merge keys: %w", err)
+		}
+	}
+
 	manifestLocation, err := rs.generateManifest(ctx, tableName)
 	if err != nil {
 		return "", fmt.Errorf("generating manifest: %w", err)
@@ -498,6 +553,12 @@ func (rs *Redshift) loadTable(ctx context.Context, tableName string, tableSchema
 		}
 	}()
 
+	// labelling the queries in the transaction, for looking them up in STL_QUERY
+	queryTag := rs.loadQueryTags.New(rs.Warehouse, tableName)
+	if _, err = txn.ExecContext(ctx, fmt.Sprintf(`SET LOCAL query_group TO '%s';`, strings.ReplaceAll(queryTag, "'", "''"))); err != nil {
+		return "", fmt.Errorf("setting query group: %w", err)
+	}
+
 	if rs.Uploader.GetLoadFileType() == warehouseutils.LoadFileTypeParquet {
 		query = fmt.Sprintf(`
 			COPY %v
@@ -595,6 +656,42 @@ func (rs *Redshift) loadTable(ctx context.Context, tableName string, tableSchema
 		partitionKey = column
 	}
 
+	joinClause := fmt.Sprintf(`_source.%[3]s = %[1]s.%[2]q.%[3]s`, rs.Namespace, tableName, primaryKey)
+	orderByClause := "received_at DESC"
+
+	// Tables with merge keys are upserted on the primary keys, keeping the latest record.
+	// With latestBy, newer rows already present in the table are kept, skipping the older ones from the staging table.
+	var latestByClause, skipExistingClause string
+	if hasMergeKeys {
+		partitionKey = warehouseutils.DoubleQuoteAndJoinByComma(mergeKeys.PrimaryKeys)
+		joinClause = warehouseutils.JoinWithFormatting(mergeKeys.PrimaryKeys, func(_ int, name string) string {
+			return fmt.Sprintf(`_source.%[3]q = %[1]s.%[2]q.%[3]q`, rs.Namespace, tableName, name)
+		}, " AND ")
+		orderByClause = fmt.Sprintf(`%q DESC NULLS LAST`, mergeKeys.OrderByColumn(warehouseutils.RS))
+		if mergeKeys.LatestBy != "" {
+			latestByClause = fmt.Sprintf(`
+			AND (%[1]s.%[2]q.%[3]q IS NULL OR _source.%[3]q >= %[1]s.%[2]q.%[3]q)
+`,
+				rs.Namespace,
+				tableName,
+				mergeKeys.LatestBy,
+			)
+			skipExistingClause = fmt.Sprintf(`
+		  AND NOT EXISTS (
+			SELECT
+			  1
+			FROM
+			  %[1]s.%[2]q
+			WHERE
+			  %[3]s
+		  )`,
+				rs.Namespace,
+				tableName,
+				joinClause,
+			)
+		}
+	}
+
 	// Deduplication
 	// Delete rows from the table which are already present in the staging table
 	query = fmt.Sprintf(`
@@ -603,15 +700,17 @@ func (rs *Redshift) loadTable(ctx context.Context, tableName string, tableSchema
 		USING
 			%[1]s.%[3]q _source
 		WHERE
-			_source.%[4]s = %[1]s.%[2]q.%[4]s
+			%[4]s
 `,
 		rs.Namespace,
 		tableName,
 		stagingTableName,
-		primaryKey,
+		joinClause,
 	)
+	query += latestByClause
 
-	if rs.config.dedupWindow {
+	// The dedup window is not applied for merging, since all the rows with the same primary keys need to be replaced
+	if rs.config.dedupWindow && !hasMergeKeys {
 		if _, ok := tableSchemaAfterUpload["received_at"]; ok {
 			query += fmt.Sprintf(`
 				AND %[1]s.%[2]q.received_at > GETDATE() - INTERVAL '%[3]d HOUR'
@@ -702,19 +801,21 @@ func (rs *Redshift) loadTable(ctx context.Context, tableName string, tableSchema
 			  row_number() OVER (
 				PARTITION BY %[5]s
 				ORDER BY
-				  received_at DESC
+				  %[6]s
 			  ) AS _rudder_staging_row_number
 			FROM
 			  %[1]q.%[4]q
-		  ) AS _
+		  ) AS _source
 		WHERE
-		  _rudder_staging_row_number = 1;
+		  _rudder_staging_row_number = 1 %[7]s;
 `,
 		rs.Namespace,
 		tableName,
 		quotedColumnNames,
 		stagingTableName,
 		partitionKey,
+		orderByClause,
+		skipExistingClause,
 	)
 
 	rs.logger.Infow("inserting into original table",
@@ -887,6 +988,16 @@ func (rs *Redshift) loadUserTables(ctx context.Context) map[string]error {
 		}
 	}
 
+	// labelling the queries in the transaction, for looking them up in STL_QUERY
+	queryTag := rs.loadQueryTags.New(rs.Warehouse, warehouseutils.UsersTable)
+	if _, err = txn.ExecContext(ctx, fmt.Sprintf(`SET LOCAL query_group TO '%s';`, strings.ReplaceAll(queryTag, "'", "''"))); err != nil {
+		_ = txn.Rollback()
+		return map[string]error{
+			warehouseutils.IdentifiesTable: nil,
+			warehouseutils.UsersTable:      fmt.Errorf("setting query group: %w", err),
+		}
+	}
+
 	if _, err = txn.ExecContext(ctx, query); err != nil {
 		_ = txn.Rollback()
 
@@ -1150,6 +1261,10 @@ func (rs *Redshift) AlterColumn(ctx context.Context, tableName, columnName, colu
 		err                  error
 	)
 
+	if stagingColumnType = getRSDataType(columnType); stagingColumnType == "" {
+		return model.AlterTableResponse{}, fmt.Errorf("altering column %s of %s: unsupported type %s", columnName, tableName, columnType)
+	}
+
 	// Begin a transaction
 	if tx, err = rs.DB.BeginTx(ctx, &sql.TxOptions{}); err != nil {
 		return model.AlterTableResponse{}, fmt.Errorf("begin transaction: %w", err)
@@ -1163,7 +1278,6 @@ func (rs *Redshift) AlterColumn(ctx context.Context, tableName, columnName, colu
 	}()
 
 	// creating staging column
-	stagingColumnType = getRSDataType(columnType)
 	stagingColumnName = fmt.Sprintf(`%s-staging-%s`, columnName, misc.FastUUID().String())
 	query = fmt.Sprintf(`
 		ALTER TABLE
@@ -1414,6 +1528,35 @@ func (*Redshift) DownloadIdentityRules(context.Context, *misc.GZipWriter) (err e
 	return
 }
 
+// LoadTableCost returns the cost of the queries run by the last load of the table, looked up by their label in STL_QUERY
+func (rs *Redshift) LoadTableCost(ctx context.Context, tableName string) (model.LoadCost, error) {
+	queryTag, ok := rs.loadQueryTags.Get(tableName)
+	if !ok {
+		return model.LoadCost{}, nil
+	}
+
+	sqlStatement := `
+		SELECT
+		  COUNT(DISTINCT q.query),
+		  COALESCE(SUM(s.bytes), 0)
+		FROM
+		  stl_query q
+		  LEFT JOIN stl_scan s ON s.query = q.query
+		WHERE
+		  TRIM(q.label) = $1;
+`
+
+	var cost model.LoadCost
+	err := rs.DB.QueryRowContext(ctx, sqlStatement, queryTag).Scan(
+		&cost.Queries,
+		&cost.BytesScanned,
+	)
+	if err != nil {
+		return model.LoadCost{}, fmt.Errorf("querying stl_query for table %s: %w", tableName, err)
+	}
+	return cost, nil
+}
+
 func (rs *Redshift) GetTotalCountInTable(ctx context.Context, tableName string) (int64, error) {
 	var (
 		total        int64
@@ -141,6 +141,8 @@ type tableLoadResp struct {
 
 type optionalCreds struct {
 	schemaName string
+	// queryTag is set as the QUERY_TAG of the sessions, for looking up the queries in the query history
+	queryTag string
 }
 
 type Snowflake struct {
@@ -153,6 +155,7 @@ type Snowflake struct {
 	connectTimeout time.Duration
 	logger         logger.Logger
 	stats          stats.Stats
+	loadQueryTags  warehouseutils.LoadQueryTags
 
 	config struct {
 		slowQueryThreshold time.Duration
@@ -260,6 +263,10 @@ func (sf *Snowflake) authString() string {
 }
 
 func (sf *Snowflake) DeleteBy(ctx context.Context, tableNames []string, params warehouseutils.DeleteByParams) (err error) {
+	if params.EndTime != "" {
+		return sf.deleteReceivedInRange(ctx, tableNames, params)
+	}
+
 	for _, tb := range tableNames {
 		sf.logger.Infof("SF: Cleaning up the following tables in snowflake for SF:%s", tb)
 		sqlStatement := fmt.Sprintf(`
@@ -293,6 +300,47 @@ func (sf *Snowflake) DeleteBy(ctx context.Context, tableNames []string, params w
 	return nil
 }
 
+// deleteReceivedInRange deletes the rows of the source received in the range [StartTime, EndTime), irrespective of the runs they were loaded by
+func (sf *Snowflake) deleteReceivedInRange(ctx context.Context, tableNames []string, params warehouseutils.DeleteByParams) error {
+	for _, tableName := range tableNames {
+		sqlStatement := fmt.Sprintf(`DELETE FROM %[1]q.%[2]q WHERE context_source_id = ? AND received_at >= ? AND received_at < ?;`,
+			sf.Namespace,
+			tableName,
+		)
+
+		sf.logger.Infof("SF: Deleting rows of source %s received between %s and %s in table %s for SF:%s", params.SourceId, params.StartTime, params.EndTime, tableName, sf.Warehouse.Destination.ID)
+		sf.logger.Debugf("SF: Executing the sql statement %v", sqlStatement)
+
+		if _, err := sf.DB.ExecContext(ctx, sqlStatement, params.SourceId, params.StartTime, params.EndTime); err != nil {
+			return fmt.Errorf("deleting from table %s: %w", tableName, err)
+		}
+	}
+	return nil
+}
+
+// DeleteByColumnValues deletes the rows of the table having any of the values in the column
+func (sf *Snowflake) DeleteByColumnValues(ctx context.Context, tableName, columnName string, values []string) error {
+	placeholders := strings.TrimSuffix(strings.Repeat("?,", len(values)), ",")
+	sqlStatement := fmt.Sprintf(`DELETE FROM %[1]q.%[2]q WHERE %[3]q IN (%[4]s);`,
+		sf.Namespace,
+		tableName,
+		columnName,
+		placeholders,
+	)
+
+	sf.logger.Infof("SF: Deleting %d values of column %s in table %s for SF:%s", len(values), columnName, tableName, sf.Warehouse.Destination.ID)
+	sf.logger.Debugf("SF: Executing the sql statement %v", sqlStatement)
+
+	args := make([]interface{}, len(values))
+	for i, value := range values {
+		args[i] = value
+	}
+	if _, err := sf.DB.ExecContext(ctx, sqlStatement, args...); err != nil {
+		return fmt.Errorf("deleting from table %s: %w", tableName, err)
+	}
+	return nil
+}
+
 func (sf *Snowflake) loadTable(ctx context.Context, tableName string, tableSchemaInUpload model.TableSchema, skipClosingDBSession bool) (tableLoadResp, error) {
 	var (
 		csvObjectLocation string
@@ -310,7 +358,17 @@ func (sf *Snowflake) loadTable(ctx context.Context, tableName string, tableSchem
 		logfield.TableName, tableName,
 	)
 
-	if db, err = sf.connect(ctx, optionalCreds{schemaName: sf.Namespace}); err != nil {
+	mergeKeys, hasMergeKeys := warehouseutils.GetMergeKeys(sf.Warehouse, tableName)
+	if hasMergeKeys {
+		if err = mergeKeys.Validate(tableSchemaInUpload); err != nil {
+			return tableLoadResp{}, fmt.Errorf("validating merge keys: %w", err)
+		}
+	}
+
+	if db, err = sf.connect(ctx, optionalCreds{
+		schemaName: sf.Namespace,
+		queryTag:   sf.loadQueryTags.New(sf.Warehouse, tableName),
+	}); err != nil {
 		return tableLoadResp{}, fmt.Errorf("connect: %w", err)
 	}
 
@@ -434,6 +492,10 @@ func (sf *Snowflake) loadTable(ctx context.Context, tableName string, tableSchem
 		partitionKey = column
 	}
 
+	joinClause := fmt.Sprintf(`original.%[1]q = staging.%[1]q`, primaryKey)
+	orderByClause := "RECEIVED_AT DESC"
+	matchedClause := "WHEN MATCHED THEN"
+
 	stagingColumnNames := warehouseutils.JoinWithFormatting(strKeys, func(_ int, name string) string {
 		return fmt.Sprintf(`staging.%q`, name)
 	}, ",")
@@ -445,6 +507,20 @@ func (sf *Snowflake) loadTable(ctx context.Context, tableName string, tableSchem
 		additionalJoinClause = fmt.Sprintf(`AND original.%[1]q = staging.%[1]q AND original.%[2]q = staging.%[2]q`, "TABLE_NAME", "COLUMN_NAME")
 	}
 
+	// Tables with merge keys are upserted on the primary keys, keeping the latest record
+	if hasMergeKeys {
+		keepLatestRecordOnDedup = true
+
+		partitionKey = warehouseutils.DoubleQuoteAndJoinByComma(mergeKeys.PrimaryKeys)
+		joinClause = warehouseutils.JoinWithFormatting(mergeKeys.PrimaryKeys, func(_ int, name string) string {
+			return fmt.Sprintf(`original.%[1]q = staging.%[1]q`, name)
+		}, " AND ")
+		orderByClause = fmt.Sprintf(`%q DESC NULLS LAST`, mergeKeys.OrderByColumn(warehouseutils.SNOWFLAKE))
+		if mergeKeys.LatestBy != "" {
+			matchedClause = fmt.Sprintf(`WHEN MATCHED AND (original.%[1]q IS NULL OR staging.%[1]q >= original.%[1]q) THEN`, mergeKeys.LatestBy)
+		}
+	}
+
 	if keepLatestRecordOnDedup {
 		sqlStatement = fmt.Sprintf(`
 			MERGE INTO %[9]s.%[1]q AS original USING (
@@ -457,7 +533,7 @@ func (sf *Snowflake) loadTable(ctx context.Context, tableName string, tableSchem
 					row_number() OVER (
 					  PARTITION BY %[8]s
 					  ORDER BY
-						RECEIVED_AT DESC
+						%[10]s
 					) AS _rudder_staging_row_number
 				  FROM
 					%[9]s.%[2]q
@@ -465,22 +541,24 @@ func (sf *Snowflake) loadTable(ctx context.Context, tableName string, tableSchem
 			  WHERE
 				_rudder_staging_row_number = 1
 			) AS staging ON (
-			  original.%[3]q = staging.%[3]q %[7]s
+			  %[3]s %[7]s
 			)
 			WHEN NOT MATCHED THEN
 			  INSERT (%[4]s) VALUES (%[5]s)
-			WHEN MATCHED THEN
+			%[11]s
 			  UPDATE SET %[6]s;
 `,
 			tableName,
 			stagingTableName,
-			primaryKey,
+			joinClause,
 			sortedColumnNames,
 			stagingColumnNames,
 			columnsWithValues,
 			additionalJoinClause,
 			partitionKey,
 			schemaIdentifier,
+			orderByClause,
+			matchedClause,
 		)
 	} else {
 		// This is being added in order to get the updates count
@@ -505,7 +583,7 @@ func (sf *Snowflake) loadTable(ctx context.Context, tableName string, tableSchem
 			  WHERE
 				_rudder_staging_row_number = 1
 			) AS staging ON (
-			  original.%[3]q = staging.%[3]q %[6]s
+			  %[3]s %[6]s
 			)
 			WHEN NOT MATCHED THEN
 			  INSERT (%[4]s) VALUES (%[5]s)
@@ -514,7 +592,7 @@ func (sf *Snowflake) loadTable(ctx context.Context, tableName string, tableSchem
 `,
 			tableName,
 			stagingTableName,
-			primaryKey,
+			joinClause,
 			sortedColumnNames,
 			stagingColumnNames,
 			additionalJoinClause,
@@ -762,6 +840,16 @@ func (sf *Snowflake) loadUserTables(ctx context.Context) map[string]error {
 		firstValProps = append(firstValProps, firstValPropsQuery)
 	}
 
+	// The users staging table is created from the identifies staging table, so the same session is reused.
+	// Re-tagging the session attributes the queries for the users table to its own query tag.
+	queryTagStatement := fmt.Sprintf(`ALTER SESSION SET QUERY_TAG = '%s';`, strings.ReplaceAll(sf.loadQueryTags.New(sf.Warehouse, usersTable), "'", "''"))
+	if _, err = resp.db.ExecContext(ctx, queryTagStatement); err != nil {
+		return map[string]error{
+			identifiesTable: nil,
+			usersTable:      fmt.Errorf("setting query tag for users: %w", err),
+		}
+	}
+
 	schemaIdentifier := sf.schemaIdentifier()
 	stagingTableName := warehouseutils.StagingTableName(provider, usersTable, tableNameLimit)
 	sqlStatement := fmt.Sprintf(`
@@ -968,6 +1056,11 @@ func (sf *Snowflake) connect(ctx context.Context, opts optionalCreds) (*sqlmiddl
 	if cred.timeout > 0 {
 		urlConfig.LoginTimeout = cred.timeout
 	}
+	if opts.queryTag != "" {
+		urlConfig.Params = map[string]*string{
+			"query_tag": &opts.queryTag,
+		}
+	}
 
 	var err error
 	dsn, err := snowflake.DSN(&urlConfig)
@@ -1321,6 +1414,40 @@ func (sf *Snowflake) LoadTable(ctx context.Context, tableName string) error {
 	return err
 }
 
+// LoadTableCost returns the cost of the queries run by the last load of the table, looked up by its query tag in the query history.
+// Only the cloud services credits can be attributed to the queries, the warehouse credits being billed for the warehouse uptime.
+func (sf *Snowflake) LoadTableCost(ctx context.Context, tableName string) (model.LoadCost, error) {
+	queryTag, ok := sf.loadQueryTags.Get(tableName)
+	if !ok {
+		return model.LoadCost{}, nil
+	}
+
+	sqlStatement := `
+		SELECT
+		  COUNT(*),
+		  COALESCE(SUM(BYTES_SCANNED), 0),
+		  COALESCE(SUM(CREDITS_USED_CLOUD_SERVICES), 0)
+		FROM
+		  TABLE(INFORMATION_SCHEMA.QUERY_HISTORY(
+			END_TIME_RANGE_START => DATEADD('day', -1, CURRENT_TIMESTAMP()),
+			RESULT_LIMIT => 10000
+		  ))
+		WHERE
+		  QUERY_TAG = ?;
+`
+
+	var cost model.LoadCost
+	err := sf.DB.QueryRowContext(ctx, sqlStatement, queryTag).Scan(
+		&cost.Queries,
+		&cost.BytesScanned,
+		&cost.Credits,
+	)
+	if err != nil {
+		return model.LoadCost{}, fmt.Errorf("querying query history for table %s: %w", tableName, err)
+	}
+	return cost, nil
+}
+
 func (sf *Snowflake) GetTotalCountInTable(ctx context.Context, tableName string) (int64, error) {
 	var (
 		total        int64
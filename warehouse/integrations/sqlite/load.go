@@ -0,0 +1,724 @@
+package sqlite
+
+import (
+	"compress/gzip"
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/xitongsys/parquet-go-source/local"
+	"github.com/xitongsys/parquet-go/common"
+	"github.com/xitongsys/parquet-go/reader"
+	"golang.org/x/exp/slices"
+
+	"github.com/rudderlabs/rudder-go-kit/stats"
+
+	"github.com/rudderlabs/rudder-server/utils/misc"
+	sqlmiddleware "github.com/rudderlabs/rudder-server/warehouse/integrations/middleware/sqlquerywrapper"
+	"github.com/rudderlabs/rudder-server/warehouse/internal/model"
+	"github.com/rudderlabs/rudder-server/warehouse/logfield"
+	warehouseutils "github.com/rudderlabs/rudder-server/warehouse/utils"
+)
+
+type loadTableResponse struct {
+	StagingTableName string
+}
+
+type loadUsersTableResponse struct {
+	identifiesError error
+	usersError      error
+}
+
+func (d *SQLite) LoadTable(ctx context.Context, tableName string) error {
+	d.logger.Infow("started loading",
+		logfield.SourceID, d.Warehouse.Source.ID,
+		logfield.SourceType, d.Warehouse.Source.SourceDefinition.Name,
+		logfield.DestinationID, d.Warehouse.Destination.ID,
+		logfield.DestinationType, d.Warehouse.Destination.DestinationDefinition.Name,
+		logfield.WorkspaceID, d.Warehouse.WorkspaceID,
+		logfield.Namespace, d.Namespace,
+		logfield.TableName, tableName,
+	)
+
+	var stagingTableNames []string
+	err := d.DB.WithTx(ctx, func(tx *sqlmiddleware.Tx) error {
+		defer func() { d.dropStagingTables(ctx, tx, stagingTableNames...) }()
+
+		tableSchemaInUpload := d.Uploader.GetTableSchemaInUpload(tableName)
+
+		response, err := d.loadTable(ctx, tx, tableName, tableSchemaInUpload)
+		if response.StagingTableName != "" {
+			stagingTableNames = append(stagingTableNames, response.StagingTableName)
+		}
+		return err
+	})
+	if err != nil {
+		return fmt.Errorf("loading table: %w", err)
+	}
+
+	d.logger.Infow("completed loading",
+		logfield.SourceID, d.Warehouse.Source.ID,
+		logfield.SourceType, d.Warehouse.Source.SourceDefinition.Name,
+		logfield.DestinationID, d.Warehouse.Destination.ID,
+		logfield.DestinationType, d.Warehouse.Destination.DestinationDefinition.Name,
+		logfield.WorkspaceID, d.Warehouse.WorkspaceID,
+		logfield.Namespace, d.Namespace,
+		logfield.TableName, tableName,
+	)
+
+	return nil
+}
+
+func (d *SQLite) loadTable(
+	ctx context.Context,
+	txn *sqlmiddleware.Tx,
+	tableName string,
+	tableSchemaInUpload model.TableSchema,
+) (loadTableResponse, error) {
+	stagingTableName, err := d.createStagingTable(ctx, txn, tableName)
+	if err != nil {
+		return loadTableResponse{}, err
+	}
+	response := loadTableResponse{
+		StagingTableName: stagingTableName,
+	}
+
+	loadFiles, err := d.LoadFileDownloader.Download(ctx, tableName)
+	defer misc.RemoveFilePaths(loadFiles...)
+	if err != nil {
+		return response, fmt.Errorf("downloading load files: %w", err)
+	}
+
+	if _, err := d.copyLoadFiles(ctx, txn, fmt.Sprintf(`temp.%q`, stagingTableName), tableSchemaInUpload, loadFiles, d.Uploader.GetLoadFileType()); err != nil {
+		return response, err
+	}
+
+	var (
+		primaryKey   = "id"
+		partitionKey = "id"
+
+		additionalJoinClause string
+	)
+	if column, ok := primaryKeyMap[tableName]; ok {
+		primaryKey = column
+	}
+	if column, ok := partitionKeyMap[tableName]; ok {
+		partitionKey = column
+	}
+	if tableName == warehouseutils.DiscardsTable {
+		additionalJoinClause = fmt.Sprintf(
+			`AND _source.%[3]s = %[1]q.%[2]q.%[3]q AND _source.%[4]s = %[1]q.%[2]q.%[4]q`,
+			d.Namespace,
+			tableName,
+			"table_name",
+			"column_name",
+		)
+	}
+
+	// Deduplication
+	// Delete rows from the table which are already present in the staging table
+	query := fmt.Sprintf(`
+		DELETE FROM
+		  %[1]q.%[2]q
+		WHERE
+		  EXISTS (
+			SELECT
+			  1
+			FROM
+			  %[3]q AS _source
+			WHERE
+			  _source.%[4]s = %[1]q.%[2]q.%[4]q %[5]s
+		  );
+	`,
+		d.Namespace,
+		tableName,
+		stagingTableName,
+		primaryKey,
+		additionalJoinClause,
+	)
+
+	if !slices.Contains(d.config.skipDedupDestinationIDs, d.Warehouse.Destination.ID) {
+		d.logger.Infow("deduplication",
+			logfield.SourceID, d.Warehouse.Source.ID,
+			logfield.SourceType, d.Warehouse.Source.SourceDefinition.Name,
+			logfield.DestinationID, d.Warehouse.Destination.ID,
+			logfield.DestinationType, d.Warehouse.Destination.DestinationDefinition.Name,
+			logfield.WorkspaceID, d.Warehouse.WorkspaceID,
+			logfield.Namespace, d.Namespace,
+			logfield.TableName, tableName,
+			logfield.StagingTableName, stagingTableName,
+			logfield.Query, query,
+		)
+
+		result, err := txn.ExecContext(ctx, query)
+		if err != nil {
+			return response, fmt.Errorf("deleting from original table for dedup: %w", err)
+		}
+
+		rowsAffected, err := result.RowsAffected()
+		if err != nil {
+			return response, fmt.Errorf("getting rows affected for dedup: %w", err)
+		}
+
+		d.stats.NewTaggedStat("dedup_rows", stats.CountType, stats.Tags{
+			"sourceID":     d.Warehouse.Source.ID,
+			"sourceType":   d.Warehouse.Source.SourceDefinition.Name,
+			"destID":       d.Warehouse.Destination.ID,
+			"destType":     d.Warehouse.Destination.DestinationDefinition.Name,
+			"workspaceId":  d.Warehouse.WorkspaceID,
+			"tableName":    tableName,
+			"rowsAffected": fmt.Sprintf("%d", rowsAffected),
+		})
+	}
+
+	// Insert rows from staging table to the original table
+	quotedColumnNames := warehouseutils.DoubleQuoteAndJoinByComma(warehouseutils.SortColumnKeysFromColumnMap(tableSchemaInUpload))
+	query = fmt.Sprintf(`
+		INSERT INTO %[1]q.%[2]q (%[3]s)
+		SELECT
+		  %[3]s
+		FROM
+		  (
+			SELECT
+			  *,
+			  ROW_NUMBER() OVER (
+				PARTITION BY %[5]s
+				ORDER BY
+				  received_at DESC
+			  ) AS _rudder_staging_row_number
+			FROM
+			  %[4]q
+		  ) AS _
+		WHERE
+		  _rudder_staging_row_number = 1;
+	`,
+		d.Namespace,
+		tableName,
+		quotedColumnNames,
+		stagingTableName,
+		partitionKey,
+	)
+
+	d.logger.Infow("inserting records",
+		logfield.SourceID, d.Warehouse.Source.ID,
+		logfield.SourceType, d.Warehouse.Source.SourceDefinition.Name,
+		logfield.DestinationID, d.Warehouse.Destination.ID,
+		logfield.DestinationType, d.Warehouse.Destination.DestinationDefinition.Name,
+		logfield.WorkspaceID, d.Warehouse.WorkspaceID,
+		logfield.Namespace, d.Namespace,
+		logfield.TableName, tableName,
+		logfield.StagingTableName, stagingTableName,
+		logfield.Query, query,
+	)
+	if _, err := txn.ExecContext(ctx, query); err != nil {
+		return response, fmt.Errorf("executing query: %w", err)
+	}
+
+	return response, nil
+}
+
+// createStagingTable creates a temporary table with the columns of the table
+func (d *SQLite) createStagingTable(ctx context.Context, txn *sqlmiddleware.Tx, tableName string) (string, error) {
+	stagingTableName := warehouseutils.StagingTableName(provider, tableName, tableNameLimit)
+	query := fmt.Sprintf(`
+		CREATE TEMPORARY TABLE %[2]q AS
+		SELECT
+		  *
+		FROM
+		  %[1]q.%[3]q
+		WHERE
+		  false;
+`,
+		d.Namespace,
+		stagingTableName,
+		tableName,
+	)
+	d.logger.Infow("creating temporary table",
+		logfield.SourceID, d.Warehouse.Source.ID,
+		logfield.SourceType, d.Warehouse.Source.SourceDefinition.Name,
+		logfield.DestinationID, d.Warehouse.Destination.ID,
+		logfield.DestinationType, d.Warehouse.Destination.DestinationDefinition.Name,
+		logfield.WorkspaceID, d.Warehouse.WorkspaceID,
+		logfield.Namespace, d.Namespace,
+		logfield.TableName, tableName,
+		logfield.StagingTableName, stagingTableName,
+		logfield.Query, query,
+	)
+	if _, err := txn.ExecContext(ctx, query); err != nil {
+		return "", fmt.Errorf("creating temporary table: %w", err)
+	}
+	return stagingTableName, nil
+}
+
+// dropStagingTables drops the temporary tables, which otherwise live as long as the connection
+func (d *SQLite) dropStagingTables(ctx context.Context, txn *sqlmiddleware.Tx, stagingTableNames ...string) {
+	for _, stagingTableName := range stagingTableNames {
+		if _, err := txn.ExecContext(ctx, fmt.Sprintf(`DROP TABLE IF EXISTS temp.%q;`, stagingTableName)); err != nil {
+			d.logger.Warnw("dropping staging table",
+				logfield.DestinationID, d.Warehouse.Destination.ID,
+				logfield.StagingTableName, stagingTableName,
+				logfield.Error, err.Error(),
+			)
+		}
+	}
+}
+
+// copyLoadFiles inserts the rows of the load files into the target table, returning the number of rows inserted.
+// Columns of load files are sorted by name.
+func (d *SQLite) copyLoadFiles(
+	ctx context.Context,
+	txn *sqlmiddleware.Tx,
+	target string,
+	tableSchemaInUpload model.TableSchema,
+	loadFiles []string,
+	loadFileType string,
+) (int64, error) {
+	sortedColumnKeys := warehouseutils.SortColumnKeysFromColumnMap(tableSchemaInUpload)
+	placeholders := strings.TrimSuffix(strings.Repeat("?,", len(sortedColumnKeys)), ",")
+
+	stmt, err := txn.PrepareContext(ctx, fmt.Sprintf(`INSERT INTO %s (%s) VALUES (%s);`,
+		target,
+		warehouseutils.DoubleQuoteAndJoinByComma(sortedColumnKeys),
+		placeholders,
+	))
+	if err != nil {
+		return 0, fmt.Errorf("preparing statement for insert: %w", err)
+	}
+	defer func() { _ = stmt.Close() }()
+
+	read := readCSVLoadFile
+	if loadFileType == warehouseutils.LoadFileTypeParquet {
+		read = readParquetLoadFile
+	}
+
+	var rowsProcessedCount int64
+	for _, objectFileName := range loadFiles {
+		err := read(objectFileName, sortedColumnKeys, func(record []interface{}) error {
+			values := make([]interface{}, len(record))
+			for i, value := range record {
+				if values[i], err = convert(value, tableSchemaInUpload[sortedColumnKeys[i]]); err != nil {
+					return fmt.Errorf("converting column %s: %w", sortedColumnKeys[i], err)
+				}
+			}
+
+			if _, err := stmt.ExecContext(ctx, values...); err != nil {
+				return fmt.Errorf("exec statement: %w", err)
+			}
+
+			rowsProcessedCount++
+			return nil
+		})
+		if err != nil {
+			return rowsProcessedCount, err
+		}
+	}
+	return rowsProcessedCount, nil
+}
+
+func readCSVLoadFile(objectFileName string, sortedColumnKeys []string, f func([]interface{}) error) error {
+	gzFile, err := os.Open(objectFileName)
+	if err != nil {
+		return fmt.Errorf("opening load file: %w", err)
+	}
+	defer func() { _ = gzFile.Close() }()
+
+	gzReader, err := gzip.NewReader(gzFile)
+	if err != nil {
+		return fmt.Errorf("reading gzip load file: %w", err)
+	}
+	defer func() { _ = gzReader.Close() }()
+
+	csvReader := csv.NewReader(gzReader)
+
+	for {
+		record, err := csvReader.Read()
+		if err != nil {
+			if err == io.EOF {
+				break
+			}
+
+			return fmt.Errorf("reading csv file: %w", err)
+		}
+
+		if len(sortedColumnKeys) != len(record) {
+			return fmt.Errorf("missing columns in csv file %s", objectFileName)
+		}
+
+		recordInterface := make([]interface{}, len(record))
+		for i, value := range record {
+			if strings.TrimSpace(value) != "" {
+				recordInterface[i] = value
+			}
+		}
+		if err := f(recordInterface); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func readParquetLoadFile(objectFileName string, sortedColumnKeys []string, f func([]interface{}) error) error {
+	file, err := local.NewLocalFileReader(objectFileName)
+	if err != nil {
+		return fmt.Errorf("opening load file: %w", err)
+	}
+	defer func() { _ = file.Close() }()
+
+	r, err := reader.NewParquetColumnReader(file, 1)
+	if err != nil {
+		return fmt.Errorf("creating parquet reader: %w", err)
+	}
+	defer r.ReadStop()
+
+	numRows := r.GetNumRows()
+	if numRows == 0 {
+		return nil
+	}
+
+	columns := make([][]interface{}, len(sortedColumnKeys))
+	for i, column := range sortedColumnKeys {
+		values, _, _, err := r.ReadColumnByPath(common.ReformPathStr("parquet_go_root."+column), numRows)
+		if err != nil {
+			return fmt.Errorf("reading column %s of parquet file %s: %w", column, objectFileName, err)
+		}
+		if int64(len(values)) != numRows {
+			return fmt.Errorf("missing column %s in parquet file %s", column, objectFileName)
+		}
+		columns[i] = values
+	}
+
+	for row := int64(0); row < numRows; row++ {
+		record := make([]interface{}, len(columns))
+		for i := range columns {
+			record[i] = columns[i][row]
+		}
+		if err := f(record); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// convert converts the value read from a load file to the value stored for the column's data type.
+// CSV values are strings, while parquet values are typed, with datetimes being microseconds since epoch.
+func convert(value interface{}, dataType string) (interface{}, error) {
+	if value == nil {
+		return nil, nil
+	}
+
+	s, isString := value.(string)
+	switch dataType {
+	case "int":
+		if isString {
+			return strconv.ParseInt(s, 10, 64)
+		}
+	case "float":
+		if isString {
+			return strconv.ParseFloat(s, 64)
+		}
+	case "boolean":
+		if isString {
+			return strconv.ParseBool(s)
+		}
+	case "datetime":
+		if isString {
+			t, err := time.Parse(time.RFC3339Nano, s)
+			if err != nil {
+				return nil, err
+			}
+			return t.UTC().Format(timestampFormat), nil
+		}
+		if micros, ok := value.(int64); ok {
+			return time.UnixMicro(micros).UTC().Format(timestampFormat), nil
+		}
+		return nil, fmt.Errorf("unsupported datetime value %v", value)
+	}
+	return value, nil
+}
+
+func (d *SQLite) LoadUserTables(ctx context.Context) map[string]error {
+	d.logger.Infow("started loading for identifies and users tables",
+		logfield.SourceID, d.Warehouse.Source.ID,
+		logfield.SourceType, d.Warehouse.Source.SourceDefinition.Name,
+		logfield.DestinationID, d.Warehouse.Destination.ID,
+		logfield.DestinationType, d.Warehouse.Destination.DestinationDefinition.Name,
+		logfield.WorkspaceID, d.Warehouse.WorkspaceID,
+		logfield.Namespace, d.Namespace,
+	)
+
+	identifiesSchemaInUpload := d.Uploader.GetTableSchemaInUpload(warehouseutils.IdentifiesTable)
+	usersSchemaInUpload := d.Uploader.GetTableSchemaInUpload(warehouseutils.UsersTable)
+	usersSchemaInWarehouse := d.Uploader.GetTableSchemaInWarehouse(warehouseutils.UsersTable)
+
+	var loadingError loadUsersTableResponse
+	_ = d.DB.WithTx(ctx, func(tx *sqlmiddleware.Tx) error {
+		var stagingTableNames []string
+		defer func() { d.dropStagingTables(ctx, tx, stagingTableNames...) }()
+
+		loadingError, stagingTableNames = d.loadUsersTable(ctx, tx, identifiesSchemaInUpload, usersSchemaInUpload, usersSchemaInWarehouse)
+		if loadingError.identifiesError != nil || loadingError.usersError != nil {
+			return errors.New("loading users and identifies table")
+		}
+
+		return nil
+	})
+	if loadingError.identifiesError != nil {
+		return map[string]error{
+			warehouseutils.IdentifiesTable: loadingError.identifiesError,
+		}
+	}
+	if len(usersSchemaInUpload) == 0 {
+		return map[string]error{
+			warehouseutils.IdentifiesTable: nil,
+		}
+	}
+	if loadingError.usersError != nil {
+		return map[string]error{
+			warehouseutils.IdentifiesTable: nil,
+			warehouseutils.UsersTable:      loadingError.usersError,
+		}
+	}
+
+	d.logger.Infow("completed loading for users and identities table",
+		logfield.SourceID, d.Warehouse.Source.ID,
+		logfield.SourceType, d.Warehouse.Source.SourceDefinition.Name,
+		logfield.DestinationID, d.Warehouse.Destination.ID,
+		logfield.DestinationType, d.Warehouse.Destination.DestinationDefinition.Name,
+		logfield.WorkspaceID, d.Warehouse.WorkspaceID,
+		logfield.Namespace, d.Namespace,
+	)
+
+	return map[string]error{
+		warehouseutils.IdentifiesTable: nil,
+		warehouseutils.UsersTable:      nil,
+	}
+}
+
+// loadUsersTable loads the identifies table and computes the latest traits of the users, returning the staging tables
+// it created
+func (d *SQLite) loadUsersTable(
+	ctx context.Context,
+	tx *sqlmiddleware.Tx,
+	identifiesSchemaInUpload,
+	usersSchemaInUpload,
+	usersSchemaInWarehouse model.TableSchema,
+) (loadUsersTableResponse, []string) {
+	var stagingTableNames []string
+
+	identifiesTableResponse, err := d.loadTable(ctx, tx, warehouseutils.IdentifiesTable, identifiesSchemaInUpload)
+	if identifiesTableResponse.StagingTableName != "" {
+		stagingTableNames = append(stagingTableNames, identifiesTableResponse.StagingTableName)
+	}
+	if err != nil {
+		return loadUsersTableResponse{
+			identifiesError: fmt.Errorf("loading identifies table: %w", err),
+		}, stagingTableNames
+	}
+
+	if len(usersSchemaInUpload) == 0 {
+		return loadUsersTableResponse{}, stagingTableNames
+	}
+
+	canSkipComputingLatestUserTraits := d.config.skipComputingUserLatestTraits || slices.Contains(d.config.skipComputingUserLatestTraitsWorkspaceIDs, d.Warehouse.WorkspaceID)
+	if canSkipComputingLatestUserTraits {
+		usersTableResponse, err := d.loadTable(ctx, tx, warehouseutils.UsersTable, usersSchemaInUpload)
+		if usersTableResponse.StagingTableName != "" {
+			stagingTableNames = append(stagingTableNames, usersTableResponse.StagingTableName)
+		}
+		if err != nil {
+			return loadUsersTableResponse{
+				usersError: fmt.Errorf("loading users table: %w", err),
+			}, stagingTableNames
+		}
+		return loadUsersTableResponse{}, stagingTableNames
+	}
+
+	unionStagingTableName := warehouseutils.StagingTableName(provider, "users_identifies_union", tableNameLimit)
+	usersStagingTableName := warehouseutils.StagingTableName(provider, warehouseutils.UsersTable, tableNameLimit)
+	stagingTableNames = append(stagingTableNames, unionStagingTableName, usersStagingTableName)
+
+	var userColNames, firstValProps []string
+	for _, colName := range warehouseutils.SortColumnKeysFromColumnMap(usersSchemaInWarehouse) {
+		if colName == "id" {
+			continue
+		}
+		userColNames = append(userColNames, fmt.Sprintf(`%q`, colName))
+		caseSubQuery := fmt.Sprintf(`
+			(
+			  SELECT
+				%[1]q
+			  FROM
+				%[2]q AS staging_table
+			  WHERE
+				x.id = staging_table.id AND
+				%[1]q IS NOT NULL
+			  ORDER BY
+				received_at DESC
+			  LIMIT
+				1
+			) AS %[1]q
+`,
+			colName,
+			unionStagingTableName,
+		)
+		firstValProps = append(firstValProps, caseSubQuery)
+	}
+
+	query := fmt.Sprintf(`
+		CREATE TEMPORARY TABLE %[5]q AS
+		SELECT
+		  id,
+		  %[4]s
+		FROM
+		  %[1]q.%[2]q
+		WHERE
+		  id IN (
+			SELECT
+			  user_id
+			FROM
+			  %[3]q
+			WHERE
+			  user_id IS NOT NULL
+		  )
+		UNION
+		SELECT
+		  user_id,
+		  %[4]s
+		FROM
+		  %[3]q
+		WHERE
+		  user_id IS NOT NULL;
+`,
+		d.Namespace,
+		warehouseutils.UsersTable,
+		identifiesTableResponse.StagingTableName,
+		strings.Join(userColNames, ","),
+		unionStagingTableName,
+	)
+
+	d.logger.Infow("creating union staging users table",
+		logfield.SourceID, d.Warehouse.Source.ID,
+		logfield.SourceType, d.Warehouse.Source.SourceDefinition.Name,
+		logfield.DestinationID, d.Warehouse.Destination.ID,
+		logfield.DestinationType, d.Warehouse.Destination.DestinationDefinition.Name,
+		logfield.WorkspaceID, d.Warehouse.WorkspaceID,
+		logfield.TableName, warehouseutils.UsersTable,
+		logfield.StagingTableName, unionStagingTableName,
+		logfield.Namespace, d.Namespace,
+		logfield.Query, query,
+	)
+	if _, err = tx.ExecContext(ctx, query); err != nil {
+		return loadUsersTableResponse{
+			usersError: fmt.Errorf("creating union staging users table: %w", err),
+		}, stagingTableNames
+	}
+
+	query = fmt.Sprintf(`
+		CREATE TEMPORARY TABLE %[1]q AS
+		SELECT
+		  DISTINCT *
+		FROM
+		  (
+			SELECT
+			  x.id,
+			  %[2]s
+			FROM
+			  %[3]q AS x
+		  ) AS xyz;
+`,
+		usersStagingTableName,
+		strings.Join(firstValProps, ","),
+		unionStagingTableName,
+	)
+
+	d.logger.Debugw("creating temporary users table",
+		logfield.SourceID, d.Warehouse.Source.ID,
+		logfield.SourceType, d.Warehouse.Source.SourceDefinition.Name,
+		logfield.DestinationID, d.Warehouse.Destination.ID,
+		logfield.DestinationType, d.Warehouse.Destination.DestinationDefinition.Name,
+		logfield.WorkspaceID, d.Warehouse.WorkspaceID,
+		logfield.TableName, warehouseutils.UsersTable,
+		logfield.StagingTableName, usersStagingTableName,
+		logfield.Query, query,
+	)
+	if _, err = tx.ExecContext(ctx, query); err != nil {
+		return loadUsersTableResponse{
+			usersError: fmt.Errorf("creating temporary users table: %w", err),
+		}, stagingTableNames
+	}
+
+	// Deduplication
+	// Delete from users table if the id is present in the staging table
+	query = fmt.Sprintf(`
+		DELETE FROM
+		  %[1]q.%[2]q
+		WHERE
+		  id IN (
+			SELECT
+			  id
+			FROM
+			  %[3]q
+		  );
+`,
+		d.Namespace,
+		warehouseutils.UsersTable,
+		usersStagingTableName,
+	)
+
+	d.logger.Infow("deduplication for users table",
+		logfield.SourceID, d.Warehouse.Source.ID,
+		logfield.SourceType, d.Warehouse.Source.SourceDefinition.Name,
+		logfield.DestinationID, d.Warehouse.Destination.ID,
+		logfield.DestinationType, d.Warehouse.Destination.DestinationDefinition.Name,
+		logfield.WorkspaceID, d.Warehouse.WorkspaceID,
+		logfield.TableName, warehouseutils.UsersTable,
+		logfield.StagingTableName, usersStagingTableName,
+		logfield.Namespace, d.Namespace,
+		logfield.Query, query,
+	)
+	if _, err = tx.ExecContext(ctx, query); err != nil {
+		return loadUsersTableResponse{
+			usersError: fmt.Errorf("deleting from original users table for dedup: %w", err),
+		}, stagingTableNames
+	}
+
+	// Insert rows from staging table to the original table
+	query = fmt.Sprintf(`
+		INSERT INTO %[1]q.%[2]q (id, %[3]s)
+		SELECT
+		  id,
+		  %[3]s
+		FROM
+		  %[4]q;
+`,
+		d.Namespace,
+		warehouseutils.UsersTable,
+		strings.Join(userColNames, ","),
+		usersStagingTableName,
+	)
+
+	d.logger.Infow("inserting records to users table",
+		logfield.SourceID, d.Warehouse.Source.ID,
+		logfield.SourceType, d.Warehouse.Source.SourceDefinition.Name,
+		logfield.DestinationID, d.Warehouse.Destination.ID,
+		logfield.DestinationType, d.Warehouse.Destination.DestinationDefinition.Name,
+		logfield.WorkspaceID, d.Warehouse.WorkspaceID,
+		logfield.TableName, warehouseutils.UsersTable,
+		logfield.StagingTableName, usersStagingTableName,
+		logfield.Namespace, d.Namespace,
+		logfield.Query, query,
+	)
+	if _, err = tx.ExecContext(ctx, query); err != nil {
+		return loadUsersTableResponse{
+			usersError: fmt.Errorf("inserting records to users table: %w", err),
+		}, stagingTableNames
+	}
+
+	return loadUsersTableResponse{}, stagingTableNames
+}
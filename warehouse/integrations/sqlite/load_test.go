@@ -0,0 +1,673 @@
+package sqlite
+
+import (
+	"compress/gzip"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/docker/docker/pkg/fileutils"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+
+	"github.com/rudderlabs/rudder-go-kit/config"
+	"github.com/rudderlabs/rudder-go-kit/logger"
+	"github.com/rudderlabs/rudder-go-kit/stats/memstats"
+	backendconfig "github.com/rudderlabs/rudder-server/backend-config"
+	"github.com/rudderlabs/rudder-server/utils/misc"
+	"github.com/rudderlabs/rudder-server/warehouse/encoding"
+	"github.com/rudderlabs/rudder-server/warehouse/internal/model"
+	warehouseutils "github.com/rudderlabs/rudder-server/warehouse/utils"
+)
+
+type mockLoadFileUploader struct {
+	mockFiles map[string][]string
+	mockError map[string]error
+}
+
+func (m *mockLoadFileUploader) Download(_ context.Context, tableName string) ([]string, error) {
+	return m.mockFiles[tableName], m.mockError[tableName]
+}
+
+type mockUploader struct {
+	schema       model.Schema
+	loadFileType string
+}
+
+func (*mockUploader) GetSchemaInWarehouse() model.Schema { return model.Schema{} }
+func (*mockUploader) GetLocalSchema(context.Context) (model.Schema, error) {
+	return model.Schema{}, nil
+}
+func (*mockUploader) UpdateLocalSchema(context.Context, model.Schema) error { return nil }
+func (*mockUploader) ShouldOnDedupUseNewRecord() bool                       { return false }
+func (*mockUploader) UseRudderStorage() bool                                { return false }
+func (*mockUploader) GetLoadFileGenStartTIme() time.Time                    { return time.Time{} }
+func (m *mockUploader) GetLoadFileType() string                             { return m.loadFileType }
+func (*mockUploader) GetFirstLastEvent() (time.Time, time.Time)             { return time.Time{}, time.Time{} }
+func (*mockUploader) GetLoadFilesMetadata(context.Context, warehouseutils.GetLoadFilesOptions) []warehouseutils.LoadFile {
+	return []warehouseutils.LoadFile{}
+}
+
+func (*mockUploader) GetSingleLoadFile(context.Context, string) (warehouseutils.LoadFile, error) {
+	return warehouseutils.LoadFile{}, nil
+}
+
+func (*mockUploader) GetSampleLoadFileLocation(context.Context, string) (string, error) {
+	return "", nil
+}
+
+func (m *mockUploader) GetTableSchemaInUpload(tableName string) model.TableSchema {
+	return m.schema[tableName]
+}
+
+func (m *mockUploader) GetTableSchemaInWarehouse(tableName string) model.TableSchema {
+	return m.schema[tableName]
+}
+
+func cloneFiles(t *testing.T, files []string) []string {
+	tempFiles := make([]string, len(files))
+	for i, file := range files {
+		src := fmt.Sprintf("testdata/%s", file)
+		dst := fmt.Sprintf("testdata/%s-%s", uuid.New().String(), file)
+
+		dirPath := filepath.Dir(dst)
+		err := os.MkdirAll(dirPath, os.ModePerm)
+		require.NoError(t, err)
+
+		_, err = fileutils.CopyFile(src, dst)
+		require.NoError(t, err)
+
+		t.Cleanup(func() { _ = os.Remove(dst) })
+
+		tempFiles[i] = dst
+	}
+	return tempFiles
+}
+
+const (
+	namespace   = "test_namespace"
+	sourceID    = "test_source_id"
+	destID      = "test_dest_id"
+	sourceType  = "test_source_type"
+	destType    = "test_dest_type"
+	workspaceID = "test_workspace_id"
+)
+
+var testSchema = model.TableSchema{
+	"test_bool":     "boolean",
+	"test_datetime": "datetime",
+	"test_float":    "float",
+	"test_int":      "int",
+	"test_string":   "string",
+	"id":            "string",
+	"received_at":   "datetime",
+}
+
+// setup returns a SQLite connected to a database file in a temporary directory
+func setup(t *testing.T, c *config.Config) *SQLite {
+	t.Helper()
+
+	store := memstats.New()
+	d := New(c, logger.NOP, store)
+	d.Namespace = namespace
+	d.Warehouse = model.Warehouse{
+		Source: backendconfig.SourceT{
+			ID: sourceID,
+			SourceDefinition: backendconfig.SourceDefinitionT{
+				Name: sourceType,
+			},
+		},
+		Destination: backendconfig.DestinationT{
+			ID: destID,
+			DestinationDefinition: backendconfig.DestinationDefinitionT{
+				Name: destType,
+			},
+			Config: map[string]interface{}{
+				"path": t.TempDir(),
+			},
+		},
+		WorkspaceID: workspaceID,
+		Namespace:   namespace,
+	}
+
+	db, err := d.connect()
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = db.Close() })
+	d.DB = db
+
+	return d
+}
+
+func TestLoadTable(t *testing.T) {
+	t.Parallel()
+
+	misc.Init()
+	warehouseutils.Init()
+	encoding.Init()
+
+	t.Run("Regular tables", func(t *testing.T) {
+		t.Parallel()
+
+		tableName := "test_table"
+
+		testCases := []struct {
+			name              string
+			wantError         error
+			mockError         error
+			skipTableCreation bool
+			cancelContext     bool
+			mockFiles         []string
+			additionalFiles   []string
+			loadFileType      string
+			wantCount         int64
+		}{
+			{
+				name:              "table not present",
+				skipTableCreation: true,
+				mockFiles:         []string{"load.csv.gz"},
+				wantError:         errors.New("loading table: executing transaction: creating temporary table: SQL logic error: no such table: test_namespace.test_table (1)"),
+			},
+			{
+				name:      "download error",
+				mockFiles: []string{"load.csv.gz"},
+				mockError: errors.New("test error"),
+				wantError: errors.New("loading table: executing transaction: downloading load files: test error"),
+			},
+			{
+				name:            "load file not present",
+				additionalFiles: []string{"testdata/random.csv.gz"},
+				wantError:       errors.New("loading table: executing transaction: opening load file: open testdata/random.csv.gz: no such file or directory"),
+			},
+			{
+				name:      "less records than expected",
+				mockFiles: []string{"less-records.csv.gz"},
+				wantError: errors.New("loading table: executing transaction: missing columns in csv file"),
+			},
+			{
+				name:      "bad records",
+				mockFiles: []string{"bad.csv.gz"},
+				wantError: errors.New("loading table: executing transaction: converting column test_datetime: parsing time \"1\""),
+			},
+			{
+				name:      "success",
+				mockFiles: []string{"load.csv.gz"},
+				wantCount: 14,
+			},
+			{
+				name:      "success with duplicates",
+				mockFiles: []string{"load.csv.gz", "load.csv.gz"},
+				wantCount: 14,
+			},
+			{
+				name:         "parquet load files",
+				loadFileType: warehouseutils.LoadFileTypeParquet,
+				wantCount:    2,
+			},
+			{
+				name:          "context cancelled",
+				mockFiles:     []string{"load.csv.gz"},
+				wantError:     errors.New("loading table: begin transaction: context canceled"),
+				cancelContext: true,
+			},
+		}
+
+		for _, tc := range testCases {
+			tc := tc
+
+			t.Run(tc.name, func(t *testing.T) {
+				t.Parallel()
+
+				d := setup(t, config.New())
+
+				ctx, cancel := context.WithCancel(context.Background())
+				if tc.cancelContext {
+					cancel()
+				} else {
+					defer cancel()
+				}
+
+				if !tc.skipTableCreation {
+					require.NoError(t, d.CreateTable(context.Background(), tableName, testSchema))
+				}
+
+				loadFiles := cloneFiles(t, tc.mockFiles)
+				loadFiles = append(loadFiles, tc.additionalFiles...)
+				if tc.loadFileType == warehouseutils.LoadFileTypeParquet {
+					loadFiles = append(loadFiles, parquetLoadFile(t, testSchema, [][]interface{}{
+						{"1", int64(1671087229640000), true, int64(1671087229640000), 125.75, int64(125), "hello-world"},
+						{"2", int64(1671087229640000), nil, nil, nil, nil, nil},
+						{"2", int64(1671087229000000), false, int64(1671087229640000), 126.75, int64(126), "stale"},
+					}))
+				}
+				require.NotEmpty(t, loadFiles)
+
+				d.LoadFileDownloader = &mockLoadFileUploader{
+					mockFiles: map[string][]string{
+						tableName: loadFiles,
+					},
+					mockError: map[string]error{
+						tableName: tc.mockError,
+					},
+				}
+				d.Uploader = &mockUploader{
+					schema: map[string]model.TableSchema{
+						tableName: testSchema,
+					},
+					loadFileType: tc.loadFileType,
+				}
+
+				err := d.LoadTable(ctx, tableName)
+				if tc.wantError != nil {
+					require.ErrorContains(t, err, tc.wantError.Error())
+					return
+				}
+				require.NoError(t, err)
+
+				count, err := d.GetTotalCountInTable(ctx, tableName)
+				require.NoError(t, err)
+				require.Equal(t, tc.wantCount, count)
+
+				if tc.loadFileType == warehouseutils.LoadFileTypeParquet {
+					var (
+						receivedAt, datetime time.Time
+						testString           string
+					)
+					err = d.DB.QueryRowContext(ctx, fmt.Sprintf(`SELECT received_at, test_datetime, test_string FROM %q.%q WHERE id = '1';`, namespace, tableName)).Scan(&receivedAt, &datetime, &testString)
+					require.NoError(t, err)
+					require.Equal(t, time.Date(2022, 12, 15, 6, 53, 49, 640000000, time.UTC), receivedAt)
+					require.Equal(t, receivedAt, datetime)
+					require.Equal(t, "hello-world", testString)
+
+					var nullString *string
+					err = d.DB.QueryRowContext(ctx, fmt.Sprintf(`SELECT test_string FROM %q.%q WHERE id = '2';`, namespace, tableName)).Scan(&nullString)
+					require.NoError(t, err)
+					require.Nil(t, nullString, "latest row is kept")
+				}
+			})
+		}
+	})
+
+	t.Run("Discards tables", func(t *testing.T) {
+		t.Parallel()
+
+		tableName := warehouseutils.DiscardsTable
+
+		testCases := []struct {
+			name              string
+			wantError         error
+			mockError         error
+			skipTableCreation bool
+			mockFiles         []string
+		}{
+			{
+				name:              "table not present",
+				skipTableCreation: true,
+				mockFiles:         []string{"discards.csv.gz"},
+				wantError:         errors.New("loading table: executing transaction: creating temporary table: SQL logic error: no such table: test_namespace.rudder_discards (1)"),
+			},
+			{
+				name:      "download error",
+				mockFiles: []string{"discards.csv.gz"},
+				wantError: errors.New("loading table: executing transaction: downloading load files: test error"),
+				mockError: errors.New("test error"),
+			},
+			{
+				name:      "success",
+				mockFiles: []string{"discards.csv.gz", "discards.csv.gz"},
+			},
+		}
+
+		for _, tc := range testCases {
+			tc := tc
+
+			t.Run(tc.name, func(t *testing.T) {
+				t.Parallel()
+
+				d := setup(t, config.New())
+				ctx := context.Background()
+
+				if !tc.skipTableCreation {
+					require.NoError(t, d.CreateTable(ctx, tableName, warehouseutils.DiscardsSchema))
+				}
+
+				loadFiles := cloneFiles(t, tc.mockFiles)
+				require.NotEmpty(t, loadFiles)
+
+				d.LoadFileDownloader = &mockLoadFileUploader{
+					mockFiles: map[string][]string{
+						tableName: loadFiles,
+					},
+					mockError: map[string]error{
+						tableName: tc.mockError,
+					},
+				}
+				d.Uploader = &mockUploader{
+					schema: map[string]model.TableSchema{
+						tableName: warehouseutils.DiscardsSchema,
+					},
+				}
+
+				err := d.LoadTable(ctx, tableName)
+				if tc.wantError != nil {
+					require.EqualError(t, err, tc.wantError.Error())
+					return
+				}
+				require.NoError(t, err)
+
+				count, err := d.GetTotalCountInTable(ctx, tableName)
+				require.NoError(t, err)
+				require.EqualValues(t, 6, count)
+			})
+		}
+	})
+}
+
+func TestLoadUsersTable(t *testing.T) {
+	t.Parallel()
+
+	misc.Init()
+	warehouseutils.Init()
+
+	testCases := []struct {
+		name                       string
+		wantErrorsMap              map[string]error
+		mockUsersFiles             []string
+		mockIdentifiesFiles        []string
+		mockUsersError             error
+		mockIdentifiesError        error
+		skipUserTraitsWorkspaceIDs []string
+		usersSchemaInUpload        model.TableSchema
+	}{
+		{
+			name:                "success",
+			mockUsersFiles:      []string{"users.csv.gz"},
+			mockIdentifiesFiles: []string{"identifies.csv.gz"},
+			wantErrorsMap: map[string]error{
+				warehouseutils.IdentifiesTable: nil,
+				warehouseutils.UsersTable:      nil,
+			},
+		},
+		{
+			name:                       "skip computing users traits",
+			mockUsersFiles:             []string{"users.csv.gz"},
+			mockIdentifiesFiles:        []string{"identifies.csv.gz"},
+			skipUserTraitsWorkspaceIDs: []string{workspaceID},
+			wantErrorsMap: map[string]error{
+				warehouseutils.IdentifiesTable: nil,
+				warehouseutils.UsersTable:      nil,
+			},
+		},
+		{
+			name:                "empty users schema",
+			mockUsersFiles:      []string{"users.csv.gz"},
+			mockIdentifiesFiles: []string{"identifies.csv.gz"},
+			usersSchemaInUpload: model.TableSchema{},
+			wantErrorsMap: map[string]error{
+				warehouseutils.IdentifiesTable: nil,
+			},
+		},
+		{
+			name:                "download error for identifies",
+			mockUsersFiles:      []string{"users.csv.gz"},
+			mockIdentifiesFiles: []string{"identifies.csv.gz"},
+			wantErrorsMap: map[string]error{
+				warehouseutils.IdentifiesTable: errors.New("loading identifies table: downloading load files: test error"),
+			},
+			mockIdentifiesError: errors.New("test error"),
+		},
+		{
+			name:                "download error for users",
+			mockUsersFiles:      []string{"users.csv.gz"},
+			mockIdentifiesFiles: []string{"identifies.csv.gz"},
+			wantErrorsMap: map[string]error{
+				warehouseutils.IdentifiesTable: nil,
+				warehouseutils.UsersTable:      errors.New("loading users table: downloading load files: test error"),
+			},
+			mockUsersError:             errors.New("test error"),
+			skipUserTraitsWorkspaceIDs: []string{workspaceID},
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			c := config.New()
+			c.Set("Warehouse.sqlite.skipComputingUserLatestTraitsWorkspaceIDs", tc.skipUserTraitsWorkspaceIDs)
+
+			d := setup(t, c)
+			ctx := context.Background()
+
+			schema := model.TableSchema{"user_id": "string"}
+			for k, v := range testSchema {
+				schema[k] = v
+			}
+			for _, table := range []string{warehouseutils.UsersTable, warehouseutils.IdentifiesTable} {
+				require.NoError(t, d.CreateTable(ctx, table, schema))
+			}
+
+			usersLoadFiles := cloneFiles(t, tc.mockUsersFiles)
+			require.NotEmpty(t, usersLoadFiles)
+
+			identifiesLoadFiles := cloneFiles(t, tc.mockIdentifiesFiles)
+			require.NotEmpty(t, identifiesLoadFiles)
+
+			usersSchemaInUpload := schema
+			if tc.usersSchemaInUpload != nil {
+				usersSchemaInUpload = tc.usersSchemaInUpload
+			}
+
+			d.LoadFileDownloader = &mockLoadFileUploader{
+				mockFiles: map[string][]string{
+					warehouseutils.UsersTable:      usersLoadFiles,
+					warehouseutils.IdentifiesTable: identifiesLoadFiles,
+				},
+				mockError: map[string]error{
+					warehouseutils.UsersTable:      tc.mockUsersError,
+					warehouseutils.IdentifiesTable: tc.mockIdentifiesError,
+				},
+			}
+			d.Uploader = &mockUploader{
+				schema: map[string]model.TableSchema{
+					warehouseutils.UsersTable:      usersSchemaInUpload,
+					warehouseutils.IdentifiesTable: schema,
+				},
+			}
+
+			errorsMap := d.LoadUserTables(ctx)
+			require.NotEmpty(t, errorsMap)
+			require.Len(t, errorsMap, len(tc.wantErrorsMap))
+			for table, err := range errorsMap {
+				if wantError := tc.wantErrorsMap[table]; wantError != nil {
+					require.EqualError(t, err, wantError.Error())
+				} else {
+					require.NoError(t, err)
+				}
+			}
+			for _, err := range tc.wantErrorsMap {
+				if err != nil {
+					return
+				}
+			}
+
+			count, err := d.GetTotalCountInTable(ctx, warehouseutils.IdentifiesTable)
+			require.NoError(t, err)
+			require.EqualValues(t, 14, count)
+
+			if _, ok := tc.wantErrorsMap[warehouseutils.UsersTable]; ok {
+				count, err = d.GetTotalCountInTable(ctx, warehouseutils.UsersTable)
+				require.NoError(t, err)
+				require.NotZero(t, count)
+			}
+		})
+	}
+}
+
+func TestSchema(t *testing.T) {
+	t.Parallel()
+
+	misc.Init()
+	warehouseutils.Init()
+
+	ctx := context.Background()
+	d := setup(t, config.New())
+
+	schema, unrecognizedSchema, err := d.FetchSchema(ctx)
+	require.NoError(t, err)
+	require.Empty(t, schema)
+	require.Empty(t, unrecognizedSchema)
+
+	require.NoError(t, d.CreateSchema(ctx))
+	require.NoError(t, d.CreateTable(ctx, "test_table", testSchema))
+	require.NoError(t, d.CreateTable(ctx, "test_table", testSchema), "idempotent")
+	require.NoError(t, d.AddColumns(ctx, "test_table", []warehouseutils.ColumnInfo{
+		{Name: "test_json", Type: "json"},
+		{Name: "test_int", Type: "int"},
+	}))
+	_, err = d.DB.ExecContext(ctx, fmt.Sprintf(`ALTER TABLE %q.%q ADD COLUMN test_blob blob;`, namespace, "test_table"))
+	require.NoError(t, err)
+
+	schema, unrecognizedSchema, err = d.FetchSchema(ctx)
+	require.NoError(t, err)
+	wantSchema := model.TableSchema{"test_json": "json"}
+	for k, v := range testSchema {
+		wantSchema[k] = v
+	}
+	require.Equal(t, model.Schema{"test_table": wantSchema}, schema)
+	require.Equal(t, model.Schema{"test_table": {"test_blob": warehouseutils.MissingDatatype}}, unrecognizedSchema)
+
+	_, err = os.Stat(filepath.Join(warehouseutils.GetConfigValue("path", d.Warehouse), namespace+".db"))
+	require.NoError(t, err, "database file of the namespace")
+
+	require.NoError(t, d.DropTable(ctx, "test_table"))
+	schema, _, err = d.FetchSchema(ctx)
+	require.NoError(t, err)
+	require.Empty(t, schema)
+}
+
+func TestDeleteBy(t *testing.T) {
+	t.Parallel()
+
+	misc.Init()
+	warehouseutils.Init()
+
+	ctx := context.Background()
+	d := setup(t, config.New())
+
+	tableName := "test_table"
+	require.NoError(t, d.CreateTable(ctx, tableName, model.TableSchema{
+		"id":                          "string",
+		"context_sources_job_run_id":  "string",
+		"context_sources_task_run_id": "string",
+		"context_source_id":           "string",
+		"received_at":                 "datetime",
+	}))
+	for _, row := range [][]string{
+		{"1", "old_job", "old_task", sourceID, "2023-01-01T00:00:00.000000Z"},
+		{"2", "new_job", "new_task", sourceID, "2023-01-01T00:00:00.000000Z"},
+		{"3", "old_job", "old_task", "other_source", "2023-01-01T00:00:00.000000Z"},
+		{"4", "old_job", "old_task", sourceID, "2023-01-03T00:00:00.000000Z"},
+	} {
+		_, err := d.DB.ExecContext(ctx, fmt.Sprintf(`INSERT INTO %q.%q (id, context_sources_job_run_id, context_sources_task_run_id, context_source_id, received_at) VALUES (?, ?, ?, ?, ?);`, namespace, tableName), row[0], row[1], row[2], row[3], row[4])
+		require.NoError(t, err)
+	}
+
+	require.NoError(t, d.DeleteBy(ctx, []string{tableName}, warehouseutils.DeleteByParams{
+		SourceId:  sourceID,
+		JobRunId:  "new_job",
+		TaskRunId: "new_task",
+		StartTime: "2023-01-02 00:00:00",
+	}))
+
+	ids := func() []string {
+		rows, err := d.DB.QueryContext(ctx, fmt.Sprintf(`SELECT id FROM %q.%q ORDER BY id;`, namespace, tableName))
+		require.NoError(t, err)
+		defer func() { _ = rows.Close() }()
+
+		var ids []string
+		for rows.Next() {
+			var id string
+			require.NoError(t, rows.Scan(&id))
+			ids = append(ids, id)
+		}
+		require.NoError(t, rows.Err())
+		return ids
+	}
+	require.Equal(t, []string{"2", "3", "4"}, ids())
+
+	require.NoError(t, d.DeleteBy(ctx, []string{tableName}, warehouseutils.DeleteByParams{
+		SourceId:  sourceID,
+		StartTime: "2023-01-01T00:00:00.000Z",
+		EndTime:   "2023-01-02T00:00:00.000Z",
+	}))
+	require.Equal(t, []string{"3", "4"}, ids())
+
+	require.Error(t, d.DeleteBy(ctx, []string{tableName}, warehouseutils.DeleteByParams{StartTime: "yesterday"}))
+	require.Error(t, d.DeleteBy(ctx, []string{tableName}, warehouseutils.DeleteByParams{StartTime: "2023-01-01 00:00:00", EndTime: "tomorrow"}))
+}
+
+func TestDownloadIdentityRules(t *testing.T) {
+	t.Parallel()
+
+	misc.Init()
+	warehouseutils.Init()
+
+	ctx := context.Background()
+	d := setup(t, config.New())
+
+	require.NoError(t, d.CreateTable(ctx, "tracks", model.TableSchema{"anonymous_id": "string", "user_id": "string"}))
+	require.NoError(t, d.CreateTable(ctx, "aliases", model.TableSchema{"user_id": "string"}))
+	for _, row := range [][]interface{}{
+		{"anon_1", "user_1"},
+		{"anon_1", "user_1"},
+		{"anon_2", nil},
+		{nil, nil},
+	} {
+		_, err := d.DB.ExecContext(ctx, fmt.Sprintf(`INSERT INTO %q.tracks (anonymous_id, user_id) VALUES (?, ?);`, namespace), row...)
+		require.NoError(t, err)
+	}
+	_, err := d.DB.ExecContext(ctx, fmt.Sprintf(`INSERT INTO %q.aliases (user_id) VALUES ('user_2');`, namespace))
+	require.NoError(t, err)
+
+	outputFile := filepath.Join(t.TempDir(), "rules.csv.gz")
+	gzWriter, err := misc.CreateGZ(outputFile)
+	require.NoError(t, err)
+	require.NoError(t, d.DownloadIdentityRules(ctx, &gzWriter))
+	require.NoError(t, gzWriter.CloseGZ())
+
+	f, err := os.Open(outputFile)
+	require.NoError(t, err)
+	defer func() { _ = f.Close() }()
+	gzReader, err := gzip.NewReader(f)
+	require.NoError(t, err)
+	defer func() { _ = gzReader.Close() }()
+	content, err := io.ReadAll(gzReader)
+	require.NoError(t, err)
+	require.ElementsMatch(t, []string{
+		"anonymous_id,anon_1,user_id,user_1",
+		"anonymous_id,anon_2,user_id,",
+		"user_id,user_2,anonymous_id,",
+	}, strings.Split(strings.TrimSpace(string(content)), "\n"))
+}
+
+// parquetLoadFile writes the rows, with values sorted by column name, to a parquet load file
+func parquetLoadFile(t *testing.T, schema model.TableSchema, rows [][]interface{}) string {
+	t.Helper()
+
+	outputFile := filepath.Join(t.TempDir(), "load.parquet")
+	w, err := encoding.CreateParquetWriter(schema, outputFile, warehouseutils.SQLITE)
+	require.NoError(t, err)
+	for _, row := range rows {
+		require.NoError(t, w.WriteRow(row))
+	}
+	require.NoError(t, w.Close())
+	return outputFile
+}
@@ -0,0 +1,740 @@
+// Package sqlite implements a warehouse destination loading into local database files, for testing warehouse syncs
+// without running a warehouse and for edge analytics.
+//
+// Every namespace is a database file named <namespace>.db in the directory configured with "path", which is attached
+// to the connection under the namespace's name, so that tables are addressed as "namespace"."table" like in other
+// warehouses. Since the server is built without cgo, the files are written with the pure go SQLite driver and can be
+// queried with the sqlite3 shell:
+//
+//	sqlite3 path/namespace.db
+//
+// Load files are CSV, or Parquet when the destination is configured with useParquetLoadFiles.
+package sqlite
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+
+	"modernc.org/sqlite"
+
+	"github.com/rudderlabs/rudder-go-kit/config"
+	"github.com/rudderlabs/rudder-go-kit/filemanager"
+	"github.com/rudderlabs/rudder-go-kit/logger"
+	"github.com/rudderlabs/rudder-go-kit/stats"
+
+	"github.com/rudderlabs/rudder-server/utils/misc"
+	"github.com/rudderlabs/rudder-server/warehouse/client"
+	sqlmiddleware "github.com/rudderlabs/rudder-server/warehouse/integrations/middleware/sqlquerywrapper"
+	"github.com/rudderlabs/rudder-server/warehouse/internal/model"
+	"github.com/rudderlabs/rudder-server/warehouse/internal/service/loadfiles/downloader"
+	"github.com/rudderlabs/rudder-server/warehouse/logfield"
+	warehouseutils "github.com/rudderlabs/rudder-server/warehouse/utils"
+)
+
+const (
+	path                = "path"
+	useParquetLoadFiles = "useParquetLoadFiles"
+)
+
+const (
+	provider       = warehouseutils.SQLITE
+	tableNameLimit = 127
+)
+
+// timestampFormat is the format datetime values are stored with. Being fixed width, values sort chronologically.
+const timestampFormat = "2006-01-02T15:04:05.000000Z"
+
+var errorsMappings = []model.JobError{
+	{
+		Type:   model.PermissionError,
+		Format: regexp.MustCompile(`unable to open database file`),
+	},
+	{
+		Type:   model.PermissionError,
+		Format: regexp.MustCompile(`attempt to write a readonly database`),
+	},
+	{
+		Type:   model.ResourceNotFoundError,
+		Format: regexp.MustCompile(`no such table`),
+	},
+	{
+		Type:   model.ColumnCountError,
+		Format: regexp.MustCompile(`too many columns`),
+	},
+	{
+		Type:   model.InsufficientResourceError,
+		Format: regexp.MustCompile(`database or disk is full`),
+	},
+	{
+		Type:   model.ConcurrentQueriesError,
+		Format: regexp.MustCompile(`database is locked`),
+	},
+}
+
+var rudderDataTypesMapToSQLite = map[string]string{
+	"int":      "bigint",
+	"float":    "double",
+	"string":   "text",
+	"datetime": "timestamp",
+	"boolean":  "boolean",
+	"json":     "json",
+}
+
+var sqliteDataTypesMapToRudder = map[string]string{
+	"integer":     "int",
+	"int":         "int",
+	"bigint":      "int",
+	"double":      "float",
+	"real":        "float",
+	"numeric":     "float",
+	"text":        "string",
+	"varchar":     "string",
+	"timestamp":   "datetime",
+	"timestamptz": "datetime",
+	"boolean":     "boolean",
+	"json":        "json",
+}
+
+var primaryKeyMap = map[string]string{
+	warehouseutils.UsersTable:      "id",
+	warehouseutils.IdentifiesTable: "id",
+	warehouseutils.DiscardsTable:   "row_id",
+}
+
+var partitionKeyMap = map[string]string{
+	warehouseutils.UsersTable:      "id",
+	warehouseutils.IdentifiesTable: "id",
+	warehouseutils.DiscardsTable:   "row_id, column_name, table_name",
+}
+
+type SQLite struct {
+	DB                 *sqlmiddleware.DB
+	Namespace          string
+	ObjectStorage      string
+	Warehouse          model.Warehouse
+	Uploader           warehouseutils.Uploader
+	connectTimeout     time.Duration
+	logger             logger.Logger
+	stats              stats.Stats
+	LoadFileDownloader downloader.Downloader
+
+	config struct {
+		numWorkersDownloadLoadFiles               int
+		slowQueryThreshold                        time.Duration
+		busyTimeout                               time.Duration
+		skipDedupDestinationIDs                   []string
+		skipComputingUserLatestTraits             bool
+		skipComputingUserLatestTraitsWorkspaceIDs []string
+	}
+}
+
+// LoadFileType returns the type of the load files of the warehouse, parquet when the destination is configured with
+// useParquetLoadFiles and csv otherwise
+func LoadFileType(warehouse model.Warehouse) string {
+	if warehouseutils.GetConfigValueBoolString(useParquetLoadFiles, warehouse) == "true" {
+		return warehouseutils.LoadFileTypeParquet
+	}
+	return warehouseutils.LoadFileTypeCsv
+}
+
+func New(conf *config.Config, log logger.Logger, stat stats.Stats) *SQLite {
+	d := &SQLite{}
+
+	d.logger = log.Child("integrations").Child("sqlite")
+	d.stats = stat
+
+	d.config.numWorkersDownloadLoadFiles = conf.GetInt("Warehouse.sqlite.numWorkersDownloadLoadFiles", 1)
+	d.config.slowQueryThreshold = conf.GetDuration("Warehouse.sqlite.slowQueryThreshold", 5, time.Minute)
+	d.config.busyTimeout = conf.GetDuration("Warehouse.sqlite.busyTimeout", 30, time.Second)
+	d.config.skipDedupDestinationIDs = conf.GetStringSlice("Warehouse.sqlite.skipDedupDestinationIDs", nil)
+	d.config.skipComputingUserLatestTraits = conf.GetBool("Warehouse.sqlite.skipComputingUserLatestTraits", false)
+	d.config.skipComputingUserLatestTraitsWorkspaceIDs = conf.GetStringSlice("Warehouse.sqlite.skipComputingUserLatestTraitsWorkspaceIDs", nil)
+
+	return d
+}
+
+// connector opens connections with the namespace's database file attached
+type connector struct {
+	driver    *sqlite.Driver
+	dsn       string
+	file      string
+	namespace string
+}
+
+func (c *connector) Connect(ctx context.Context) (driver.Conn, error) {
+	conn, err := c.driver.Open(c.dsn)
+	if err != nil {
+		return nil, err
+	}
+	attach := fmt.Sprintf(`ATTACH DATABASE ? AS %q`, c.namespace)
+	if _, err := conn.(driver.ExecerContext).ExecContext(ctx, attach, []driver.NamedValue{{Ordinal: 1, Value: c.file}}); err != nil {
+		_ = conn.Close()
+		return nil, fmt.Errorf("attaching %s: %w", c.file, err)
+	}
+	return conn, nil
+}
+
+func (c *connector) Driver() driver.Driver {
+	return c.driver
+}
+
+func (d *SQLite) getNewMiddleWare(db *sql.DB) *sqlmiddleware.DB {
+	middleware := sqlmiddleware.New(
+		db,
+		sqlmiddleware.WithStats(d.stats),
+		sqlmiddleware.WithLogger(d.logger),
+		sqlmiddleware.WithKeyAndValues(
+			logfield.SourceID, d.Warehouse.Source.ID,
+			logfield.SourceType, d.Warehouse.Source.SourceDefinition.Name,
+			logfield.DestinationID, d.Warehouse.Destination.ID,
+			logfield.DestinationType, d.Warehouse.Destination.DestinationDefinition.Name,
+			logfield.WorkspaceID, d.Warehouse.WorkspaceID,
+			logfield.Schema, d.Namespace,
+		),
+		sqlmiddleware.WithSlowQueryThreshold(d.config.slowQueryThreshold),
+		sqlmiddleware.WithQueryTimeout(d.connectTimeout),
+	)
+	return middleware
+}
+
+func (d *SQLite) connect() (*sqlmiddleware.DB, error) {
+	dir := warehouseutils.GetConfigValue(path, d.Warehouse)
+	if dir == "" {
+		return nil, errors.New("path is not configured")
+	}
+	if err := os.MkdirAll(dir, os.ModePerm); err != nil {
+		return nil, fmt.Errorf("creating directory %s: %w", dir, err)
+	}
+
+	db := sql.OpenDB(&connector{
+		driver:    &sqlite.Driver{},
+		dsn:       fmt.Sprintf(":memory:?_pragma=busy_timeout(%d)", d.config.busyTimeout.Milliseconds()),
+		file:      filepath.Join(dir, d.Namespace+".db"),
+		namespace: d.Namespace,
+	})
+	// SQLite allows a single writer at a time. Having a single connection also keeps temporary tables visible across
+	// the statements of a load.
+	db.SetMaxOpenConns(1)
+
+	return d.getNewMiddleWare(db), nil
+}
+
+func ColumnsWithDataTypes(columns model.TableSchema, prefix string) string {
+	var arr []string
+	for name, dataType := range columns {
+		arr = append(arr, fmt.Sprintf(`"%s%s" %s`, prefix, name, rudderDataTypesMapToSQLite[dataType]))
+	}
+	return strings.Join(arr, ",")
+}
+
+func (*SQLite) IsEmpty(context.Context, model.Warehouse) (empty bool, err error) {
+	return
+}
+
+// DeleteBy deletes the rows of previous runs of a source, which were received before the provided start time,
+// or the rows of a source received in a range when an end time is provided
+func (d *SQLite) DeleteBy(ctx context.Context, tableNames []string, params warehouseutils.DeleteByParams) error {
+	d.logger.Infof("SQLite: Cleaning up the following tables for SQLite:%s : %+v", tableNames, params)
+
+	startTime, err := parseStartTime(params.StartTime)
+	if err != nil {
+		return fmt.Errorf("parsing start time: %w", err)
+	}
+	if params.EndTime != "" {
+		return d.deleteReceivedInRange(ctx, tableNames, params.SourceId, startTime, params.EndTime)
+	}
+
+	for _, tb := range tableNames {
+		sqlStatement := fmt.Sprintf(`DELETE FROM %[1]q.%[2]q WHERE
+		context_sources_job_run_id <> ? AND
+		context_sources_task_run_id <> ? AND
+		context_source_id = ? AND
+		received_at < ?`,
+			d.Namespace,
+			tb,
+		)
+		d.logger.Infof("SQLite: Deleting rows in table for SQLite:%s", d.Warehouse.Destination.ID)
+		d.logger.Debugf("SQLite: Executing the statement  %v", sqlStatement)
+
+		_, err = d.DB.ExecContext(ctx, sqlStatement,
+			params.JobRunId,
+			params.TaskRunId,
+			params.SourceId,
+			startTime.Format(timestampFormat),
+		)
+		if err != nil {
+			return fmt.Errorf("deleting from %s: %w", tb, err)
+		}
+	}
+	return nil
+}
+
+func (d *SQLite) deleteReceivedInRange(ctx context.Context, tableNames []string, sourceID string, startTime time.Time, end string) error {
+	endTime, err := parseStartTime(end)
+	if err != nil {
+		return fmt.Errorf("parsing end time: %w", err)
+	}
+
+	for _, tb := range tableNames {
+		sqlStatement := fmt.Sprintf(`DELETE FROM %[1]q.%[2]q WHERE context_source_id = ? AND received_at >= ? AND received_at < ?`,
+			d.Namespace,
+			tb,
+		)
+		d.logger.Infof("SQLite: Deleting rows of source %s received between %s and %s in table %s for SQLite:%s", sourceID, startTime, endTime, tb, d.Warehouse.Destination.ID)
+		d.logger.Debugf("SQLite: Executing the statement  %v", sqlStatement)
+
+		if _, err := d.DB.ExecContext(ctx, sqlStatement, sourceID, startTime.Format(timestampFormat), endTime.Format(timestampFormat)); err != nil {
+			return fmt.Errorf("deleting from %s: %w", tb, err)
+		}
+	}
+	return nil
+}
+
+// DeleteByColumnValues deletes the rows of the table having any of the values in the column
+func (d *SQLite) DeleteByColumnValues(ctx context.Context, tableName, columnName string, values []string) error {
+	placeholders := strings.TrimSuffix(strings.Repeat("?,", len(values)), ",")
+	sqlStatement := fmt.Sprintf(`DELETE FROM %[1]q.%[2]q WHERE %[3]q IN (%[4]s)`,
+		d.Namespace,
+		tableName,
+		columnName,
+		placeholders,
+	)
+
+	d.logger.Infof("SQLite: Deleting %d values of column %s in table %s for SQLite:%s", len(values), columnName, tableName, d.Warehouse.Destination.ID)
+	d.logger.Debugf("SQLite: Executing the statement  %v", sqlStatement)
+
+	args := make([]interface{}, len(values))
+	for i, value := range values {
+		args[i] = value
+	}
+	if _, err := d.DB.ExecContext(ctx, sqlStatement, args...); err != nil {
+		return fmt.Errorf("deleting from %s: %w", tableName, err)
+	}
+	return nil
+}
+
+// parseStartTime parses the start time of async jobs, which is formatted as 2006-01-02 15:04:05 in UTC
+func parseStartTime(startTime string) (time.Time, error) {
+	for _, layout := range []string{"2006-01-02 15:04:05", time.RFC3339Nano} {
+		if t, err := time.Parse(layout, startTime); err == nil {
+			return t.UTC(), nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("unsupported start time %q", startTime)
+}
+
+// CreateSchema is a no-op, since the database file of the namespace is created once it gets attached
+func (d *SQLite) CreateSchema(ctx context.Context) error {
+	d.logger.Infof("SQLite: Creating database file for namespace %s for SQLite:%s", d.Namespace, d.Warehouse.Destination.ID)
+	return d.DB.PingContext(ctx)
+}
+
+func (d *SQLite) CreateTable(ctx context.Context, tableName string, columnMap model.TableSchema) (err error) {
+	sqlStatement := fmt.Sprintf(`CREATE TABLE IF NOT EXISTS %[1]q.%[2]q ( %v )`, d.Namespace, tableName, ColumnsWithDataTypes(columnMap, ""))
+	d.logger.Infof("SQLite: Creating table for SQLite:%s : %v", d.Warehouse.Destination.ID, sqlStatement)
+	_, err = d.DB.ExecContext(ctx, sqlStatement)
+	return
+}
+
+func (d *SQLite) DropTable(ctx context.Context, tableName string) (err error) {
+	sqlStatement := `DROP TABLE %[1]q.%[2]q`
+	d.logger.Infof("SQLite: Dropping table for SQLite:%s : %v", d.Warehouse.Destination.ID, sqlStatement)
+	_, err = d.DB.ExecContext(ctx, fmt.Sprintf(sqlStatement, d.Namespace, tableName))
+	return
+}
+
+// AddColumns adds the columns which don't exist yet, since SQLite doesn't support ADD COLUMN IF NOT EXISTS
+func (d *SQLite) AddColumns(ctx context.Context, tableName string, columnsInfo []warehouseutils.ColumnInfo) error {
+	existingColumns, err := d.columns(ctx, tableName)
+	if err != nil {
+		return fmt.Errorf("fetching columns of %s: %w", tableName, err)
+	}
+
+	for _, columnInfo := range columnsInfo {
+		if _, ok := existingColumns[columnInfo.Name]; ok {
+			continue
+		}
+
+		query := fmt.Sprintf(`ALTER TABLE %q.%q ADD COLUMN %q %s;`, d.Namespace, tableName, columnInfo.Name, rudderDataTypesMapToSQLite[columnInfo.Type])
+		d.logger.Infof("SQLite: Adding columns for destinationID: %s, tableName: %s with query: %v", d.Warehouse.Destination.ID, tableName, query)
+		if _, err := d.DB.ExecContext(ctx, query); err != nil {
+			return fmt.Errorf("adding column %s to %s: %w", columnInfo.Name, tableName, err)
+		}
+		existingColumns[columnInfo.Name] = columnInfo.Type
+	}
+	return nil
+}
+
+// columns returns the declared types of the columns of the table, keyed by column name
+func (d *SQLite) columns(ctx context.Context, tableName string) (map[string]string, error) {
+	rows, err := d.DB.QueryContext(ctx, `SELECT name, lower(type) FROM pragma_table_info(?, ?);`, tableName, d.Namespace)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = rows.Close() }()
+
+	columns := make(map[string]string)
+	for rows.Next() {
+		var name, columnType string
+		if err := rows.Scan(&name, &columnType); err != nil {
+			return nil, err
+		}
+		columns[name] = columnType
+	}
+	return columns, rows.Err()
+}
+
+// AlterColumn is a no-op, since column types are not enforced
+func (*SQLite) AlterColumn(context.Context, string, string, string) (model.AlterTableResponse, error) {
+	return model.AlterTableResponse{}, nil
+}
+
+func (d *SQLite) TestConnection(ctx context.Context, _ model.Warehouse) error {
+	err := d.DB.PingContext(ctx)
+	if errors.Is(err, context.DeadlineExceeded) {
+		return fmt.Errorf("connection timeout: %w", err)
+	}
+	if err != nil {
+		return fmt.Errorf("pinging: %w", err)
+	}
+
+	return nil
+}
+
+func (d *SQLite) Setup(_ context.Context, warehouse model.Warehouse, uploader warehouseutils.Uploader) (err error) {
+	d.Warehouse = warehouse
+	d.Namespace = warehouse.Namespace
+	d.Uploader = uploader
+	d.ObjectStorage = warehouseutils.ObjectStorageType(warehouseutils.SQLITE, warehouse.Destination.Config, d.Uploader.UseRudderStorage())
+	d.LoadFileDownloader = downloader.NewDownloader(&warehouse, uploader, d.config.numWorkersDownloadLoadFiles)
+
+	d.DB, err = d.connect()
+	return err
+}
+
+func (*SQLite) CrashRecover(context.Context) {}
+
+// FetchSchema returns the schema of the tables in the namespace's database file
+func (d *SQLite) FetchSchema(ctx context.Context) (model.Schema, model.Schema, error) {
+	schema := make(model.Schema)
+	unrecognizedSchema := make(model.Schema)
+
+	sqlStatement := fmt.Sprintf(`
+		SELECT
+		  m.name,
+		  p.name,
+		  lower(p.type)
+		FROM
+		  %q.sqlite_master AS m
+		  JOIN pragma_table_info(m.name, ?) AS p
+		WHERE
+		  m.type = 'table'
+		  AND m.name NOT LIKE 'sqlite_%%';
+	`,
+		d.Namespace,
+	)
+	rows, err := d.DB.QueryContext(ctx, sqlStatement, d.Namespace)
+	if errors.Is(err, sql.ErrNoRows) {
+		return schema, unrecognizedSchema, nil
+	}
+	if err != nil {
+		return nil, nil, fmt.Errorf("fetching schema: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	for rows.Next() {
+		var tableName, columnName, columnType string
+
+		if err := rows.Scan(&tableName, &columnName, &columnType); err != nil {
+			return nil, nil, fmt.Errorf("scanning schema: %w", err)
+		}
+
+		if _, ok := schema[tableName]; !ok {
+			schema[tableName] = make(model.TableSchema)
+		}
+		if datatype, ok := sqliteDataTypesMapToRudder[columnType]; ok {
+			schema[tableName][columnName] = datatype
+		} else {
+			if _, ok := unrecognizedSchema[tableName]; !ok {
+				unrecognizedSchema[tableName] = make(model.TableSchema)
+			}
+			unrecognizedSchema[tableName][columnName] = warehouseutils.MissingDatatype
+
+			warehouseutils.WHCounterStat(warehouseutils.RudderMissingDatatype, &d.Warehouse, warehouseutils.Tag{Name: "datatype", Value: columnType}).Count(1)
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, nil, fmt.Errorf("fetching schema: %w", err)
+	}
+
+	return schema, unrecognizedSchema, nil
+}
+
+func (d *SQLite) Cleanup(context.Context) {
+	if d.DB != nil {
+		_ = d.DB.Close()
+	}
+}
+
+func (d *SQLite) LoadIdentityMergeRulesTable(ctx context.Context) error {
+	tableName := warehouseutils.IdentityMergeRulesWarehouseTableName(provider)
+
+	return d.DB.WithTx(ctx, func(tx *sqlmiddleware.Tx) error {
+		loadFiles, err := d.downloadSingleLoadFile(ctx, tableName)
+		defer misc.RemoveFilePaths(loadFiles...)
+		if err != nil {
+			return fmt.Errorf("downloading load file: %w", err)
+		}
+
+		target := fmt.Sprintf(`%q.%q`, d.Namespace, tableName)
+		if _, err := d.copyLoadFiles(ctx, tx, target, d.Uploader.GetTableSchemaInUpload(tableName), loadFiles, warehouseutils.LoadFileTypeCsv); err != nil {
+			return fmt.Errorf("loading merge rules: %w", err)
+		}
+		return nil
+	})
+}
+
+// LoadIdentityMappingsTable upserts the mappings of the load file, keyed by merge property
+func (d *SQLite) LoadIdentityMappingsTable(ctx context.Context) error {
+	tableName := warehouseutils.IdentityMappingsWarehouseTableName(provider)
+
+	return d.DB.WithTx(ctx, func(tx *sqlmiddleware.Tx) error {
+		loadFiles, err := d.downloadSingleLoadFile(ctx, tableName)
+		defer misc.RemoveFilePaths(loadFiles...)
+		if err != nil {
+			return fmt.Errorf("downloading load file: %w", err)
+		}
+
+		stagingTableName, err := d.createStagingTable(ctx, tx, tableName)
+		if err != nil {
+			return err
+		}
+		defer d.dropStagingTables(ctx, tx, stagingTableName)
+
+		tableSchemaInUpload := d.Uploader.GetTableSchemaInUpload(tableName)
+		if _, err := d.copyLoadFiles(ctx, tx, fmt.Sprintf(`temp.%q`, stagingTableName), tableSchemaInUpload, loadFiles, warehouseutils.LoadFileTypeCsv); err != nil {
+			return fmt.Errorf("loading mappings: %w", err)
+		}
+
+		query := fmt.Sprintf(`
+			DELETE FROM
+			  %[1]q.%[2]q
+			WHERE
+			  EXISTS (
+				SELECT
+				  1
+				FROM
+				  %[3]q AS _source
+				WHERE
+				  _source.merge_property_type = %[1]q.%[2]q.merge_property_type
+				  AND _source.merge_property_value = %[1]q.%[2]q.merge_property_value
+			  );
+		`,
+			d.Namespace,
+			tableName,
+			stagingTableName,
+		)
+		if _, err := tx.ExecContext(ctx, query); err != nil {
+			return fmt.Errorf("deleting updated mappings: %w", err)
+		}
+
+		quotedColumnNames := warehouseutils.DoubleQuoteAndJoinByComma(warehouseutils.SortColumnKeysFromColumnMap(tableSchemaInUpload))
+		query = fmt.Sprintf(`
+			INSERT INTO %[1]q.%[2]q (%[3]s)
+			SELECT
+			  %[3]s
+			FROM
+			  (
+				SELECT
+				  *,
+				  ROW_NUMBER() OVER (
+					PARTITION BY merge_property_type,
+					merge_property_value
+					ORDER BY
+					  rowid DESC
+				  ) AS _rudder_staging_row_number
+				FROM
+				  %[4]q
+			  ) AS _
+			WHERE
+			  _rudder_staging_row_number = 1;
+		`,
+			d.Namespace,
+			tableName,
+			quotedColumnNames,
+			stagingTableName,
+		)
+		if _, err := tx.ExecContext(ctx, query); err != nil {
+			return fmt.Errorf("inserting mappings: %w", err)
+		}
+		return nil
+	})
+}
+
+// downloadSingleLoadFile downloads the load file of the table, which is uploaded as a single file for identity tables
+func (d *SQLite) downloadSingleLoadFile(ctx context.Context, tableName string) ([]string, error) {
+	loadFile, err := d.Uploader.GetSingleLoadFile(ctx, tableName)
+	if err != nil {
+		return nil, fmt.Errorf("getting load file: %w", err)
+	}
+
+	fm, err := filemanager.New(&filemanager.Settings{
+		Provider: d.ObjectStorage,
+		Config: misc.GetObjectStorageConfig(misc.ObjectStorageOptsT{
+			Provider:         d.ObjectStorage,
+			Config:           d.Warehouse.Destination.Config,
+			UseRudderStorage: d.Uploader.UseRudderStorage(),
+			WorkspaceID:      d.Warehouse.Destination.WorkspaceID,
+		}),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("creating filemanager: %w", err)
+	}
+
+	objectName, err := fm.GetObjectNameFromLocation(loadFile.Location)
+	if err != nil {
+		return nil, fmt.Errorf("object name for location: %s, %w", loadFile.Location, err)
+	}
+
+	tmpDirPath, err := misc.CreateTMPDIR()
+	if err != nil {
+		return nil, fmt.Errorf("creating tmp dir: %w", err)
+	}
+	dir := filepath.Join(tmpDirPath, misc.RudderWarehouseLoadUploadsTmp, fmt.Sprintf(`%s_%s_%d`, provider, d.Warehouse.Destination.ID, time.Now().Unix()))
+	if err := os.MkdirAll(dir, os.ModePerm); err != nil {
+		return nil, fmt.Errorf("making tmp dir: %w", err)
+	}
+
+	f, err := os.Create(filepath.Join(dir, filepath.Base(objectName)))
+	if err != nil {
+		return nil, fmt.Errorf("creating file in tmp dir: %w", err)
+	}
+	defer func() { _ = f.Close() }()
+
+	if err := fm.Download(ctx, f, objectName); err != nil {
+		return []string{f.Name()}, fmt.Errorf("downloading file from object storage: %w", err)
+	}
+	return []string{f.Name()}, nil
+}
+
+// DownloadIdentityRules writes the distinct combinations of anonymous_id and user_id of the event tables as merge rules
+func (d *SQLite) DownloadIdentityRules(ctx context.Context, gzWriter *misc.GZipWriter) error {
+	getFromTable := func(tableName string) error {
+		columns, err := d.columns(ctx, tableName)
+		if err != nil {
+			return fmt.Errorf("fetching columns of %s: %w", tableName, err)
+		}
+		_, hasAnonymousID := columns["anonymous_id"]
+		_, hasUserID := columns["user_id"]
+
+		var toSelectFields string
+		switch {
+		case hasAnonymousID && hasUserID:
+			toSelectFields = `anonymous_id, user_id`
+		case hasAnonymousID:
+			toSelectFields = `anonymous_id, NULL AS user_id`
+		case hasUserID:
+			toSelectFields = `NULL AS anonymous_id, user_id`
+		default:
+			d.logger.Infof("SQLite: anonymous_id, user_id columns not present in table: %s", tableName)
+			return nil
+		}
+
+		sqlStatement := fmt.Sprintf(`SELECT DISTINCT %s FROM %q.%q;`, toSelectFields, d.Namespace, tableName)
+		d.logger.Infof("SQLite: Downloading distinct combinations of anonymous_id, user_id: %s", sqlStatement)
+		rows, err := d.DB.QueryContext(ctx, sqlStatement)
+		if err != nil {
+			return fmt.Errorf("querying %s: %w", tableName, err)
+		}
+		defer func() { _ = rows.Close() }()
+
+		for rows.Next() {
+			var anonymousID, userID sql.NullString
+			if err := rows.Scan(&anonymousID, &userID); err != nil {
+				return fmt.Errorf("scanning %s: %w", tableName, err)
+			}
+			if !anonymousID.Valid && !userID.Valid {
+				continue
+			}
+
+			var csvRow []string
+			if anonymousID.Valid {
+				csvRow = append(csvRow, "anonymous_id", anonymousID.String, "user_id", userID.String)
+			} else {
+				csvRow = append(csvRow, "user_id", userID.String, "anonymous_id", anonymousID.String)
+			}
+
+			var buff bytes.Buffer
+			csvWriter := csv.NewWriter(&buff)
+			_ = csvWriter.Write(csvRow)
+			csvWriter.Flush()
+			if err := gzWriter.WriteGZ(buff.String()); err != nil {
+				return fmt.Errorf("writing merge rule: %w", err)
+			}
+		}
+		return rows.Err()
+	}
+
+	for _, table := range []string{"tracks", "pages", "screens", "identifies", "aliases"} {
+		if err := getFromTable(table); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (d *SQLite) GetTotalCountInTable(ctx context.Context, tableName string) (int64, error) {
+	var total int64
+	sqlStatement := fmt.Sprintf(`
+		SELECT count(*) FROM %[1]q.%[2]q;
+	`,
+		d.Namespace,
+		tableName,
+	)
+	err := d.DB.QueryRowContext(ctx, sqlStatement).Scan(&total)
+	return total, err
+}
+
+func (d *SQLite) Connect(_ context.Context, warehouse model.Warehouse) (client.Client, error) {
+	d.Warehouse = warehouse
+	d.Namespace = warehouse.Namespace
+	d.ObjectStorage = warehouseutils.ObjectStorageType(
+		warehouseutils.SQLITE,
+		warehouse.Destination.Config,
+		misc.IsConfiguredToUseRudderObjectStorage(d.Warehouse.Destination.Config),
+	)
+	dbHandle, err := d.connect()
+	if err != nil {
+		return client.Client{}, err
+	}
+
+	return client.Client{Type: client.SQLClient, SQL: dbHandle.DB}, err
+}
+
+func (d *SQLite) LoadTestTable(ctx context.Context, _, tableName string, payloadMap map[string]interface{}, _ string) (err error) {
+	sqlStatement := fmt.Sprintf(`INSERT INTO %q.%q (%v) VALUES (?, ?)`,
+		d.Namespace,
+		tableName,
+		fmt.Sprintf(`%q, %q`, "id", "val"),
+	)
+	_, err = d.DB.ExecContext(ctx, sqlStatement, payloadMap["id"], payloadMap["val"])
+	return
+}
+
+func (d *SQLite) SetConnectionTimeout(timeout time.Duration) {
+	d.connectTimeout = timeout
+}
+
+func (*SQLite) ErrorMappings() []model.JobError {
+	return errorsMappings
+}
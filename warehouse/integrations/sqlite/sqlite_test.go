@@ -0,0 +1,453 @@
+package sqlite_test
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/rudderlabs/compose-test/compose"
+	"github.com/rudderlabs/compose-test/testcompose"
+	"github.com/rudderlabs/rudder-go-kit/config"
+	"github.com/rudderlabs/rudder-go-kit/logger"
+	"github.com/rudderlabs/rudder-go-kit/stats"
+	kithelper "github.com/rudderlabs/rudder-go-kit/testhelper"
+	backendconfig "github.com/rudderlabs/rudder-server/backend-config"
+	"github.com/rudderlabs/rudder-server/runner"
+	"github.com/rudderlabs/rudder-server/testhelper/health"
+	"github.com/rudderlabs/rudder-server/testhelper/workspaceConfig"
+	"github.com/rudderlabs/rudder-server/utils/misc"
+	"github.com/rudderlabs/rudder-server/warehouse/encoding"
+	"github.com/rudderlabs/rudder-server/warehouse/integrations/sqlite"
+	"github.com/rudderlabs/rudder-server/warehouse/integrations/testhelper"
+	"github.com/rudderlabs/rudder-server/warehouse/internal/model"
+	warehouseutils "github.com/rudderlabs/rudder-server/warehouse/utils"
+	"github.com/rudderlabs/rudder-server/warehouse/validations"
+)
+
+type mockUploader struct {
+	schema       model.Schema
+	loadFileType string
+}
+
+func (*mockUploader) GetSchemaInWarehouse() model.Schema { return model.Schema{} }
+func (*mockUploader) GetLocalSchema(context.Context) (model.Schema, error) {
+	return model.Schema{}, nil
+}
+func (*mockUploader) UpdateLocalSchema(context.Context, model.Schema) error { return nil }
+func (*mockUploader) ShouldOnDedupUseNewRecord() bool                       { return false }
+func (*mockUploader) UseRudderStorage() bool                                { return false }
+func (*mockUploader) GetLoadFileGenStartTIme() time.Time                    { return time.Time{} }
+func (m *mockUploader) GetLoadFileType() string                             { return m.loadFileType }
+func (*mockUploader) GetFirstLastEvent() (time.Time, time.Time)             { return time.Time{}, time.Time{} }
+func (*mockUploader) GetLoadFilesMetadata(context.Context, warehouseutils.GetLoadFilesOptions) []warehouseutils.LoadFile {
+	return []warehouseutils.LoadFile{}
+}
+
+func (*mockUploader) GetSingleLoadFile(context.Context, string) (warehouseutils.LoadFile, error) {
+	return warehouseutils.LoadFile{}, nil
+}
+
+func (*mockUploader) GetSampleLoadFileLocation(context.Context, string) (string, error) {
+	return "", nil
+}
+
+func (m *mockUploader) GetTableSchemaInUpload(tableName string) model.TableSchema {
+	return m.schema[tableName]
+}
+
+func (m *mockUploader) GetTableSchemaInWarehouse(tableName string) model.TableSchema {
+	return m.schema[tableName]
+}
+
+type mockLoadFileDownloader struct {
+	loadFiles map[string]string
+}
+
+func (m *mockLoadFileDownloader) Download(_ context.Context, tableName string) ([]string, error) {
+	if loadFile, ok := m.loadFiles[tableName]; ok {
+		return []string{loadFile}, nil
+	}
+	return nil, nil
+}
+
+// TestIntegration runs the events of the testhelper suite through the load files of the slave and the loading of the
+// destination, against database files in a temporary directory and without any container
+func TestIntegration(t *testing.T) {
+	misc.Init()
+	warehouseutils.Init()
+	encoding.Init()
+
+	workspaceID := warehouseutils.RandHex()
+	destType := warehouseutils.SQLITE
+	path := t.TempDir()
+
+	testCases := []struct {
+		name                  string
+		tables                []string
+		stagingFilesEventsMap testhelper.EventsCountMap
+		loadFilesEventsMap    testhelper.EventsCountMap
+		warehouseEventsMap    testhelper.EventsCountMap
+		stagingFilePrefix     string
+		loadFileType          string
+	}{
+		{
+			name:              "Upload Job",
+			tables:            []string{"identifies", "users", "tracks", "product_track", "pages", "screens", "aliases", "groups"},
+			stagingFilePrefix: "testdata/upload-job",
+			loadFileType:      warehouseutils.LoadFileTypeCsv,
+		},
+		{
+			name:              "Upload Job with parquet load files",
+			tables:            []string{"identifies", "users", "tracks", "product_track", "pages", "screens", "aliases", "groups"},
+			stagingFilePrefix: "testdata/upload-job",
+			loadFileType:      warehouseutils.LoadFileTypeParquet,
+		},
+		{
+			name:                  "Sources Job",
+			tables:                []string{"tracks", "google_sheet"},
+			stagingFilesEventsMap: testhelper.SourcesStagingFilesEventsMap(),
+			loadFilesEventsMap:    testhelper.SourcesLoadFilesEventsMap(),
+			warehouseEventsMap:    testhelper.SourcesWarehouseEventsMap(),
+			stagingFilePrefix:     "testdata/sources-job",
+			loadFileType:          warehouseutils.LoadFileTypeCsv,
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctx := context.Background()
+
+			warehouse := model.Warehouse{
+				Source: backendconfig.SourceT{
+					ID: warehouseutils.RandHex(),
+				},
+				Destination: backendconfig.DestinationT{
+					ID: warehouseutils.RandHex(),
+					Config: map[string]interface{}{
+						"path": path,
+					},
+				},
+				WorkspaceID: workspaceID,
+				Namespace:   testhelper.RandSchema(destType),
+				Type:        destType,
+			}
+
+			sqlClient, err := sqlite.New(config.Default, logger.NOP, stats.Default).Connect(ctx, warehouse)
+			require.NoError(t, err)
+			t.Cleanup(func() { _ = sqlClient.SQL.Close() })
+
+			// load loads the load files like an upload does, creating or altering the tables first
+			load := func(t testing.TB, schema model.Schema, loadFiles map[string]string) {
+				sl := sqlite.New(config.Default, logger.NOP, stats.Default)
+				require.NoError(t, sl.Setup(ctx, warehouse, &mockUploader{schema: schema, loadFileType: tc.loadFileType}))
+				defer sl.Cleanup(ctx)
+
+				sl.LoadFileDownloader = &mockLoadFileDownloader{loadFiles: loadFiles}
+
+				require.NoError(t, sl.CreateSchema(ctx))
+
+				schemaInWarehouse, _, err := sl.FetchSchema(ctx)
+				require.NoError(t, err)
+
+				for tableName, tableSchema := range schema {
+					if _, ok := schemaInWarehouse[tableName]; !ok {
+						require.NoError(t, sl.CreateTable(ctx, tableName, tableSchema))
+						continue
+					}
+
+					var columnsInfo []warehouseutils.ColumnInfo
+					for columnName, columnType := range tableSchema {
+						if _, ok := schemaInWarehouse[tableName][columnName]; !ok {
+							columnsInfo = append(columnsInfo, warehouseutils.ColumnInfo{Name: columnName, Type: columnType})
+						}
+					}
+					if len(columnsInfo) > 0 {
+						require.NoError(t, sl.AddColumns(ctx, tableName, columnsInfo))
+					}
+				}
+
+				for tableName := range schema {
+					if tableName == warehouseutils.IdentifiesTable || tableName == warehouseutils.UsersTable {
+						continue
+					}
+					require.NoError(t, sl.LoadTable(ctx, tableName))
+				}
+				if _, ok := schema[warehouseutils.IdentifiesTable]; ok {
+					for tableName, err := range sl.LoadUserTables(ctx) {
+						require.NoError(t, err, "loading %s", tableName)
+					}
+				}
+			}
+
+			for i := 1; i <= 2; i++ {
+				t.Logf("verifying test case %d", i)
+
+				ts := testhelper.TestConfig{
+					Schema:                warehouse.Namespace,
+					Tables:                tc.tables,
+					SourceID:              warehouse.Source.ID,
+					DestinationID:         warehouse.Destination.ID,
+					StagingFilesEventsMap: tc.stagingFilesEventsMap,
+					LoadFilesEventsMap:    tc.loadFilesEventsMap,
+					WarehouseEventsMap:    tc.warehouseEventsMap,
+					WorkspaceID:           workspaceID,
+					DestinationType:       destType,
+					Client:                &sqlClient,
+					JobRunID:              misc.FastUUID().String(),
+					TaskRunID:             misc.FastUUID().String(),
+					StagingFilePath:       fmt.Sprintf("%s.staging-%d.json", tc.stagingFilePrefix, i),
+					UserID:                testhelper.GetUserId(destType),
+				}
+				ts.VerifyEventsEmbedded(t, tc.loadFileType, load)
+			}
+		})
+	}
+}
+
+// TestIntegrationWithRunner runs the testhelper suite through the server, using containers for the jobsDB and the
+// object storage
+func TestIntegrationWithRunner(t *testing.T) {
+	if os.Getenv("SLOW") != "1" {
+		t.Skip("Skipping tests. Add 'SLOW=1' env var to run test.")
+	}
+
+	c := testcompose.New(t, compose.FilePaths([]string{"../testdata/docker-compose.jobsdb.yml", "../testdata/docker-compose.minio.yml"}))
+	c.Start(context.Background())
+
+	misc.Init()
+	validations.Init()
+	warehouseutils.Init()
+	encoding.Init()
+
+	jobsDBPort := c.Port("jobsDb", 5432)
+	minioPort := c.Port("minio", 9000)
+
+	httpPort, err := kithelper.GetFreePort()
+	require.NoError(t, err)
+
+	workspaceID := warehouseutils.RandHex()
+	sourceID := warehouseutils.RandHex()
+	destinationID := warehouseutils.RandHex()
+	writeKey := warehouseutils.RandHex()
+	sourcesSourceID := warehouseutils.RandHex()
+	sourcesDestinationID := warehouseutils.RandHex()
+	sourcesWriteKey := warehouseutils.RandHex()
+
+	destType := warehouseutils.SQLITE
+
+	namespace := testhelper.RandSchema(destType)
+	sourcesNamespace := testhelper.RandSchema(destType)
+
+	path := t.TempDir()
+
+	bucketName := "testbucket"
+	accessKeyID := "MYACCESSKEY"
+	secretAccessKey := "MYSECRETKEY"
+
+	minioEndpoint := fmt.Sprintf("localhost:%d", minioPort)
+
+	templateConfigurations := map[string]any{
+		"workspaceID":          workspaceID,
+		"sourceID":             sourceID,
+		"destinationID":        destinationID,
+		"writeKey":             writeKey,
+		"sourcesSourceID":      sourcesSourceID,
+		"sourcesDestinationID": sourcesDestinationID,
+		"sourcesWriteKey":      sourcesWriteKey,
+		"path":                 path,
+		"namespace":            namespace,
+		"sourcesNamespace":     sourcesNamespace,
+		"bucketName":           bucketName,
+		"accessKeyID":          accessKeyID,
+		"secretAccessKey":      secretAccessKey,
+		"endPoint":             minioEndpoint,
+	}
+	workspaceConfigPath := workspaceConfig.CreateTempFile(t, "testdata/template.json", templateConfigurations)
+
+	testhelper.EnhanceWithDefaultEnvs(t)
+	t.Setenv("JOBS_DB_PORT", strconv.Itoa(jobsDBPort))
+	t.Setenv("WAREHOUSE_JOBS_DB_PORT", strconv.Itoa(jobsDBPort))
+	t.Setenv("MINIO_ACCESS_KEY_ID", accessKeyID)
+	t.Setenv("MINIO_SECRET_ACCESS_KEY", secretAccessKey)
+	t.Setenv("MINIO_MINIO_ENDPOINT", minioEndpoint)
+	t.Setenv("MINIO_SSL", "false")
+	t.Setenv("RSERVER_WAREHOUSE_SQLITE_SKIP_COMPUTING_USER_LATEST_TRAITS_WORKSPACE_IDS", workspaceID)
+	t.Setenv("RSERVER_WAREHOUSE_WEB_PORT", strconv.Itoa(httpPort))
+	t.Setenv("RSERVER_BACKEND_CONFIG_CONFIG_JSONPATH", workspaceConfigPath)
+	t.Setenv("RSERVER_WAREHOUSE_SQLITE_SLOW_QUERY_THRESHOLD", "0s")
+
+	svcDone := make(chan struct{})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	go func() {
+		r := runner.New(runner.ReleaseInfo{})
+		_ = r.Run(ctx, []string{"sqlite-integration-test"})
+
+		close(svcDone)
+	}()
+	t.Cleanup(func() { <-svcDone })
+
+	serviceHealthEndpoint := fmt.Sprintf("http://localhost:%d/health", httpPort)
+	health.WaitUntilReady(ctx, t, serviceHealthEndpoint, time.Minute, time.Second, "serviceHealthEndpoint")
+
+	t.Run("Events flow", func(t *testing.T) {
+		jobsDB := testhelper.JobsDB(t, jobsDBPort)
+
+		testCases := []struct {
+			name                  string
+			writeKey              string
+			schema                string
+			sourceID              string
+			destinationID         string
+			tables                []string
+			stagingFilesEventsMap testhelper.EventsCountMap
+			loadFilesEventsMap    testhelper.EventsCountMap
+			tableUploadsEventsMap testhelper.EventsCountMap
+			warehouseEventsMap    testhelper.EventsCountMap
+			asyncJob              bool
+			stagingFilePrefix     string
+		}{
+			{
+				name:              "Upload Job",
+				writeKey:          writeKey,
+				schema:            namespace,
+				tables:            []string{"identifies", "users", "tracks", "product_track", "pages", "screens", "aliases", "groups"},
+				sourceID:          sourceID,
+				destinationID:     destinationID,
+				stagingFilePrefix: "testdata/upload-job",
+			},
+			{
+				name:                  "Async Job",
+				writeKey:              sourcesWriteKey,
+				schema:                sourcesNamespace,
+				tables:                []string{"tracks", "google_sheet"},
+				sourceID:              sourcesSourceID,
+				destinationID:         sourcesDestinationID,
+				stagingFilesEventsMap: testhelper.SourcesStagingFilesEventsMap(),
+				loadFilesEventsMap:    testhelper.SourcesLoadFilesEventsMap(),
+				tableUploadsEventsMap: testhelper.SourcesTableUploadsEventsMap(),
+				warehouseEventsMap:    testhelper.SourcesWarehouseEventsMap(),
+				asyncJob:              true,
+				stagingFilePrefix:     "testdata/sources-job",
+			},
+		}
+
+		for _, tc := range testCases {
+			tc := tc
+
+			t.Run(tc.name, func(t *testing.T) {
+				t.Parallel()
+
+				// Every namespace is a database file, so the client connects to the one of the test case
+				sqlClient, err := sqlite.New(config.Default, logger.NOP, stats.Default).Connect(ctx, model.Warehouse{
+					Namespace: tc.schema,
+					Destination: backendconfig.DestinationT{
+						Config: map[string]interface{}{
+							"path": path,
+						},
+					},
+				})
+				require.NoError(t, err)
+				t.Cleanup(func() { _ = sqlClient.SQL.Close() })
+
+				conf := map[string]interface{}{
+					"bucketProvider":   "MINIO",
+					"bucketName":       bucketName,
+					"accessKeyID":      accessKeyID,
+					"secretAccessKey":  secretAccessKey,
+					"useSSL":           false,
+					"endPoint":         minioEndpoint,
+					"useRudderStorage": false,
+				}
+
+				t.Log("verifying test case 1")
+				ts1 := testhelper.TestConfig{
+					WriteKey:              tc.writeKey,
+					Schema:                tc.schema,
+					Tables:                tc.tables,
+					SourceID:              tc.sourceID,
+					DestinationID:         tc.destinationID,
+					StagingFilesEventsMap: tc.stagingFilesEventsMap,
+					LoadFilesEventsMap:    tc.loadFilesEventsMap,
+					TableUploadsEventsMap: tc.tableUploadsEventsMap,
+					WarehouseEventsMap:    tc.warehouseEventsMap,
+					Config:                conf,
+					WorkspaceID:           workspaceID,
+					DestinationType:       destType,
+					JobsDB:                jobsDB,
+					HTTPPort:              httpPort,
+					Client:                &sqlClient,
+					JobRunID:              misc.FastUUID().String(),
+					TaskRunID:             misc.FastUUID().String(),
+					StagingFilePath:       tc.stagingFilePrefix + ".staging-1.json",
+					UserID:                testhelper.GetUserId(destType),
+				}
+				ts1.VerifyEvents(t)
+
+				t.Log("verifying test case 2")
+				ts2 := testhelper.TestConfig{
+					WriteKey:              tc.writeKey,
+					Schema:                tc.schema,
+					Tables:                tc.tables,
+					SourceID:              tc.sourceID,
+					DestinationID:         tc.destinationID,
+					StagingFilesEventsMap: tc.stagingFilesEventsMap,
+					LoadFilesEventsMap:    tc.loadFilesEventsMap,
+					TableUploadsEventsMap: tc.tableUploadsEventsMap,
+					WarehouseEventsMap:    tc.warehouseEventsMap,
+					AsyncJob:              tc.asyncJob,
+					Config:                conf,
+					WorkspaceID:           workspaceID,
+					DestinationType:       destType,
+					JobsDB:                jobsDB,
+					HTTPPort:              httpPort,
+					Client:                &sqlClient,
+					JobRunID:              misc.FastUUID().String(),
+					TaskRunID:             misc.FastUUID().String(),
+					StagingFilePath:       tc.stagingFilePrefix + ".staging-2.json",
+					UserID:                testhelper.GetUserId(destType),
+				}
+				if tc.asyncJob {
+					ts2.UserID = ts1.UserID
+				}
+				ts2.VerifyEvents(t)
+			})
+		}
+	})
+
+	t.Run("Validations", func(t *testing.T) {
+		dest := backendconfig.DestinationT{
+			ID: destinationID,
+			Config: map[string]interface{}{
+				"path":             path,
+				"namespace":        "",
+				"bucketProvider":   "MINIO",
+				"bucketName":       bucketName,
+				"accessKeyID":      accessKeyID,
+				"secretAccessKey":  secretAccessKey,
+				"useSSL":           false,
+				"endPoint":         minioEndpoint,
+				"syncFrequency":    "30",
+				"useRudderStorage": false,
+			},
+			DestinationDefinition: backendconfig.DestinationDefinitionT{
+				ID:          "2TU1KrqdEJzCDUxZnIEpg1MRxUP",
+				Name:        "SQLITE",
+				DisplayName: "SQLite",
+			},
+			Name:       "sqlite-demo",
+			Enabled:    true,
+			RevisionID: "29eeuu9kywWsRAybaXcxcnTVEl8",
+		}
+		testhelper.VerifyConfigurationTest(t, dest)
+	})
+}
@@ -0,0 +1,125 @@
+package testhelper
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/rudderlabs/rudder-server/utils/timeutil"
+	"github.com/rudderlabs/rudder-server/warehouse/encoding"
+	"github.com/rudderlabs/rudder-server/warehouse/internal/model"
+	warehouseutils "github.com/rudderlabs/rudder-server/warehouse/utils"
+)
+
+// EmbeddedLoadFunc loads the load files of the tables, keyed by table name, into a warehouse running in the test process
+type EmbeddedLoadFunc func(t testing.TB, schema model.Schema, loadFiles map[string]string)
+
+// VerifyEventsEmbedded verifies the events against a warehouse running in the test process, without a jobsDB or an
+// object storage. The load files are generated from the staging file in the test process, the way the slave does,
+// and handed to load instead of being uploaded.
+func (w *TestConfig) VerifyEventsEmbedded(t testing.TB, loadFileType string, load EmbeddedLoadFunc) {
+	t.Helper()
+
+	w.reset()
+
+	stagingEvents := readStagingEvents(t, prepareStagingFile(t, w))
+	require.Equal(t, w.StagingFilesEventsMap["wh_staging_files"], len(stagingEvents))
+
+	schema := make(model.Schema)
+	for _, event := range stagingEvents {
+		tableName := event.Metadata.Table
+
+		if _, ok := schema[tableName]; !ok {
+			schema[tableName] = make(model.TableSchema)
+		}
+		for columnName, columnType := range event.Metadata.Columns {
+			if _, ok := schema[tableName][columnName]; !ok {
+				schema[tableName][columnName] = columnType
+			}
+		}
+	}
+
+	loadFiles, eventsCount := writeLoadFiles(t, w, loadFileType, schema, stagingEvents)
+	for _, table := range w.Tables {
+		require.Equal(t, w.LoadFilesEventsMap[table], eventsCount[table], "events in load file of table %s", table)
+	}
+
+	load(t, schema, loadFiles)
+
+	if !w.SkipWarehouse {
+		verifyEventsInWareHouse(t, w)
+	}
+}
+
+// writeLoadFiles writes a load file per table, returning their paths and the number of events written in each of them
+func writeLoadFiles(t testing.TB, testConfig *TestConfig, loadFileType string, schema model.Schema, stagingEvents []stagingEvent) (map[string]string, map[string]int) {
+	t.Helper()
+
+	var (
+		dir         = t.TempDir()
+		uuidTS      = timeutil.Now()
+		loadFiles   = make(map[string]string)
+		eventsCount = make(map[string]int)
+		writers     = make(map[string]encoding.LoadFileWriter)
+	)
+
+	sortedColumns := make(map[string][]string)
+	for tableName, tableSchema := range schema {
+		for columnName := range tableSchema {
+			sortedColumns[tableName] = append(sortedColumns[tableName], columnName)
+		}
+		sort.Strings(sortedColumns[tableName])
+	}
+
+	for _, event := range stagingEvents {
+		tableName := event.Metadata.Table
+
+		writer, ok := writers[tableName]
+		if !ok {
+			path := filepath.Join(dir, fmt.Sprintf("%s.%s", tableName, warehouseutils.GetLoadFileFormat(loadFileType)))
+
+			var err error
+			writer, err = encoding.NewLoadFileWriter(loadFileType, schema[tableName], path, testConfig.DestinationType)
+			require.NoError(t, err)
+
+			writers[tableName] = writer
+			loadFiles[tableName] = path
+		}
+
+		eventLoader := encoding.GetNewEventLoader(testConfig.DestinationType, loadFileType, writer)
+		for _, columnName := range sortedColumns[tableName] {
+			if eventLoader.IsLoadTimeColumn(columnName) {
+				eventLoader.AddColumn(columnName, schema[tableName][columnName], uuidTS.Format(eventLoader.GetLoadTimeFormat(columnName)))
+				continue
+			}
+
+			columnVal, ok := event.Data[columnName]
+			if !ok {
+				eventLoader.AddEmptyColumn(columnName)
+				continue
+			}
+
+			columnType := event.Metadata.Columns[columnName]
+			if model.SchemaType(columnType) == model.IntDataType || model.SchemaType(columnType) == model.BigIntDataType {
+				floatVal, ok := columnVal.(float64)
+				if !ok {
+					eventLoader.AddEmptyColumn(columnName)
+					continue
+				}
+				columnVal = int(floatVal)
+			}
+			eventLoader.AddColumn(columnName, columnType, columnVal)
+		}
+		require.NoError(t, eventLoader.Write())
+
+		eventsCount[tableName]++
+	}
+
+	for _, writer := range writers {
+		require.NoError(t, writer.Close())
+	}
+	return loadFiles, eventsCount
+}
@@ -115,36 +115,9 @@ func uploadStagingFile(t testing.TB, testConfig *TestConfig, stagingFile string)
 func prepareStagingPayload(t testing.TB, testConfig *TestConfig, stagingFile string, uploadOutput filemanager.UploadedFile) warehouseclient.StagingFile {
 	t.Helper()
 
-	type StagingEvent struct {
-		Metadata struct {
-			Table   string            `json:"table"`
-			Columns map[string]string `json:"columns"`
-		}
-		Data map[string]interface{} `json:"data"`
-	}
-
-	f, err := os.Open(stagingFile)
-	require.NoError(t, err)
-
-	reader, err := gzip.NewReader(f)
-	require.NoError(t, err)
-	defer func() { _ = reader.Close() }()
-
-	scanner := bufio.NewScanner(reader)
+	stagingEvents := readStagingEvents(t, stagingFile)
 	schemaMap := make(map[string]map[string]interface{})
 
-	stagingEvents := make([]StagingEvent, 0)
-
-	for scanner.Scan() {
-		lineBytes := scanner.Bytes()
-
-		var stagingEvent StagingEvent
-		err := json.Unmarshal(lineBytes, &stagingEvent)
-		require.NoError(t, err)
-
-		stagingEvents = append(stagingEvents, stagingEvent)
-	}
-
 	for _, event := range stagingEvents {
 		tableName := event.Metadata.Table
 
@@ -186,3 +159,38 @@ func prepareStagingPayload(t testing.TB, testConfig *TestConfig, stagingFile str
 	}
 	return payload
 }
+
+type stagingEvent struct {
+	Metadata struct {
+		Table   string            `json:"table"`
+		Columns map[string]string `json:"columns"`
+	}
+	Data map[string]interface{} `json:"data"`
+}
+
+func readStagingEvents(t testing.TB, stagingFile string) []stagingEvent {
+	t.Helper()
+
+	f, err := os.Open(stagingFile)
+	require.NoError(t, err)
+	defer func() { _ = f.Close() }()
+
+	reader, err := gzip.NewReader(f)
+	require.NoError(t, err)
+	defer func() { _ = reader.Close() }()
+
+	scanner := bufio.NewScanner(reader)
+
+	stagingEvents := make([]stagingEvent, 0)
+
+	for scanner.Scan() {
+		var event stagingEvent
+		err := json.Unmarshal(scanner.Bytes(), &event)
+		require.NoError(t, err)
+
+		stagingEvents = append(stagingEvents, event)
+	}
+	require.NoError(t, scanner.Err())
+
+	return stagingEvents
+}
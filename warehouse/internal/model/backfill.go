@@ -0,0 +1,60 @@
+package model
+
+import (
+	"errors"
+	"time"
+)
+
+type BackfillStatus = string
+
+const (
+	BackfillWaiting   BackfillStatus = "waiting"
+	BackfillScheduled BackfillStatus = "scheduled"
+	BackfillExecuting BackfillStatus = "executing"
+	BackfillSucceeded BackfillStatus = "succeeded"
+	BackfillFailed    BackfillStatus = "failed"
+)
+
+var ErrBackfillNotFound = errors.New("backfill not found")
+
+// Backfill re-syncs the staging files of a destination received in a time range into a set of tables.
+//
+//	The rows of the tables can optionally be deleted before the staging files are reprocessed
+//	by the uploads scheduled for the backfill.
+type Backfill struct {
+	ID            int64
+	WorkspaceID   string
+	SourceID      string
+	DestinationID string
+	Tables        []string
+	StartTime     time.Time
+	EndTime       time.Time
+	DeleteRows    bool
+	Status        BackfillStatus
+	Error         string
+	UploadIDs     []int64
+
+	// progress of the scheduled uploads
+	ExportedUploads int64
+	AbortedUploads  int64
+
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+// CurrentStatus returns the status of the backfill, taking into account the progress of the scheduled uploads
+func (b Backfill) CurrentStatus() BackfillStatus {
+	if b.Status != BackfillScheduled {
+		return b.Status
+	}
+	switch {
+	case b.AbortedUploads > 0:
+		return BackfillFailed
+	case b.ExportedUploads >= int64(len(b.UploadIDs)):
+		return BackfillSucceeded
+	case b.ExportedUploads > 0:
+		return BackfillExecuting
+	default:
+		return BackfillScheduled
+	}
+}
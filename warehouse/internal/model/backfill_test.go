@@ -0,0 +1,56 @@
+package model_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/rudderlabs/rudder-server/warehouse/internal/model"
+)
+
+func TestBackfill_CurrentStatus(t *testing.T) {
+	testCases := []struct {
+		name     string
+		backfill model.Backfill
+		want     model.BackfillStatus
+	}{
+		{
+			name:     "waiting",
+			backfill: model.Backfill{Status: model.BackfillWaiting},
+			want:     model.BackfillWaiting,
+		},
+		{
+			name:     "failed before scheduling",
+			backfill: model.Backfill{Status: model.BackfillFailed},
+			want:     model.BackfillFailed,
+		},
+		{
+			name:     "scheduled",
+			backfill: model.Backfill{Status: model.BackfillScheduled, UploadIDs: []int64{1, 2}},
+			want:     model.BackfillScheduled,
+		},
+		{
+			name:     "executing",
+			backfill: model.Backfill{Status: model.BackfillScheduled, UploadIDs: []int64{1, 2}, ExportedUploads: 1},
+			want:     model.BackfillExecuting,
+		},
+		{
+			name:     "succeeded",
+			backfill: model.Backfill{Status: model.BackfillScheduled, UploadIDs: []int64{1, 2}, ExportedUploads: 2},
+			want:     model.BackfillSucceeded,
+		},
+		{
+			name:     "aborted uploads",
+			backfill: model.Backfill{Status: model.BackfillScheduled, UploadIDs: []int64{1, 2}, ExportedUploads: 1, AbortedUploads: 1},
+			want:     model.BackfillFailed,
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+
+		t.Run(tc.name, func(t *testing.T) {
+			require.Equal(t, tc.want, tc.backfill.CurrentStatus())
+		})
+	}
+}
@@ -15,6 +15,27 @@ type TableUpload struct {
 	CreatedAt    time.Time
 	UpdatedAt    time.Time
 	Location     string
+	Cost         LoadCost
+}
+
+// LoadCost is the cost of the queries run in the warehouse for loading a table,
+// as reported by the warehouse e.g. BigQuery job statistics or Snowflake query history.
+type LoadCost struct {
+	Queries      int64
+	BytesScanned int64
+	BytesBilled  int64
+	// Credits are the Snowflake cloud services credits used by the queries
+	Credits float64
+}
+
+// Add returns the sum of both the costs
+func (c LoadCost) Add(other LoadCost) LoadCost {
+	return LoadCost{
+		Queries:      c.Queries + other.Queries,
+		BytesScanned: c.BytesScanned + other.BytesScanned,
+		BytesBilled:  c.BytesBilled + other.BytesBilled,
+		Credits:      c.Credits + other.Credits,
+	}
 }
 
 const (
@@ -0,0 +1,21 @@
+package model_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/rudderlabs/rudder-server/warehouse/internal/model"
+)
+
+func TestLoadCost_Add(t *testing.T) {
+	cost := model.LoadCost{Queries: 2, BytesScanned: 100, BytesBilled: 1000, Credits: 0.5}
+
+	require.Equal(t, model.LoadCost{
+		Queries:      3,
+		BytesScanned: 150,
+		BytesBilled:  1000,
+		Credits:      0.75,
+	}, cost.Add(model.LoadCost{Queries: 1, BytesScanned: 50, Credits: 0.25}))
+	require.Equal(t, cost, cost.Add(model.LoadCost{}))
+}
@@ -59,6 +59,8 @@ type Upload struct {
 	NextRetryTime    time.Time
 	Priority         int
 	Retried          bool
+	// Tables restricts the upload to a subset of the tables in the staging files, e.g. for backfills
+	Tables []string
 
 	StagingFileStartID int64
 	StagingFileEndID   int64
@@ -89,6 +91,30 @@ type UploadJob struct {
 	LoadFileGenStartTime time.Time
 }
 
+// UploadPlan describes the changes an upload would apply to the warehouse
+type UploadPlan struct {
+	UploadID int64
+	Tables   []TablePlan
+}
+
+// TablePlan describes the schema changes and the number of rows an upload would apply to a table
+type TablePlan struct {
+	Name           string
+	CreateTable    bool
+	AddedColumns   TableSchema
+	AlteredColumns TableSchema
+	TotalRows      int64
+}
+
+// TotalRows returns the number of rows which would be loaded across all the tables
+func (p UploadPlan) TotalRows() int64 {
+	var total int64
+	for _, table := range p.Tables {
+		total += table.TotalRows
+	}
+	return total
+}
+
 type PendingTableUpload struct {
 	UploadID      int64
 	DestinationID string
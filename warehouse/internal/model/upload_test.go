@@ -67,3 +67,16 @@ func TestGetLastFailedStatus(t *testing.T) {
 		require.Equal(t, status, input.status)
 	}
 }
+
+func TestUploadPlan_TotalRows(t *testing.T) {
+	require.Zero(t, model.UploadPlan{}.TotalRows())
+
+	plan := model.UploadPlan{
+		Tables: []model.TablePlan{
+			{Name: "tracks", TotalRows: 10},
+			{Name: "users", TotalRows: 5},
+			{Name: "rudder_discards"},
+		},
+	}
+	require.Equal(t, int64(15), plan.TotalRows())
+}
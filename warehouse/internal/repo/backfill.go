@@ -0,0 +1,192 @@
+package repo
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/lib/pq"
+
+	"github.com/rudderlabs/rudder-server/utils/timeutil"
+	sqlmiddleware "github.com/rudderlabs/rudder-server/warehouse/integrations/middleware/sqlquerywrapper"
+	"github.com/rudderlabs/rudder-server/warehouse/internal/model"
+	warehouseutils "github.com/rudderlabs/rudder-server/warehouse/utils"
+)
+
+const (
+	backfillsTableName = warehouseutils.WarehouseBackfillsTable
+
+	backfillColumns = `
+		id,
+		workspace_id,
+		source_id,
+		destination_id,
+		tables,
+		start_time,
+		end_time,
+		delete_rows,
+		status,
+		error,
+		upload_ids,
+		created_at,
+		updated_at
+	`
+)
+
+// Backfills is a repository for backfills
+type Backfills repo
+
+func NewBackfills(db *sqlmiddleware.DB, opts ...Opt) *Backfills {
+	r := &Backfills{
+		db:  db,
+		now: timeutil.Now,
+	}
+	for _, opt := range opts {
+		opt((*repo)(r))
+	}
+	return r
+}
+
+func (repo *Backfills) Create(ctx context.Context, backfill model.Backfill) (int64, error) {
+	var id int64
+	err := repo.db.QueryRowContext(ctx, `
+		INSERT INTO `+backfillsTableName+` (
+		  workspace_id, source_id, destination_id,
+		  tables, start_time, end_time, delete_rows,
+		  status, error, created_at, updated_at
+		)
+		VALUES
+		  ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11) RETURNING id;
+`,
+		backfill.WorkspaceID,
+		backfill.SourceID,
+		backfill.DestinationID,
+		pq.Array(backfill.Tables),
+		backfill.StartTime.UTC(),
+		backfill.EndTime.UTC(),
+		backfill.DeleteRows,
+		model.BackfillWaiting,
+		"",
+		repo.now(),
+		repo.now(),
+	).Scan(&id)
+	if err != nil {
+		return 0, fmt.Errorf("inserting backfill: %w", err)
+	}
+	return id, nil
+}
+
+// Get returns the backfill along with the progress of its uploads
+func (repo *Backfills) Get(ctx context.Context, id int64) (model.Backfill, error) {
+	row := repo.db.QueryRowContext(ctx, `
+		SELECT
+		  `+backfillColumns+`,
+		  (
+			SELECT
+			  COUNT(*)
+			FROM
+			  `+uploadsTableName+`
+			WHERE
+			  id = ANY(B.upload_ids)
+			  AND status = $2
+		  ),
+		  (
+			SELECT
+			  COUNT(*)
+			FROM
+			  `+uploadsTableName+`
+			WHERE
+			  id = ANY(B.upload_ids)
+			  AND status = $3
+		  )
+		FROM
+		  `+backfillsTableName+` B
+		WHERE
+		  id = $1;
+`,
+		id,
+		model.ExportedData,
+		model.Aborted,
+	)
+
+	var (
+		backfill  model.Backfill
+		tables    pq.StringArray
+		uploadIDs pq.Int64Array
+		errorMsg  sql.NullString
+	)
+	err := row.Scan(
+		&backfill.ID,
+		&backfill.WorkspaceID,
+		&backfill.SourceID,
+		&backfill.DestinationID,
+		&tables,
+		&backfill.StartTime,
+		&backfill.EndTime,
+		&backfill.DeleteRows,
+		&backfill.Status,
+		&errorMsg,
+		&uploadIDs,
+		&backfill.CreatedAt,
+		&backfill.UpdatedAt,
+		&backfill.ExportedUploads,
+		&backfill.AbortedUploads,
+	)
+	if err == sql.ErrNoRows {
+		return model.Backfill{}, model.ErrBackfillNotFound
+	}
+	if err != nil {
+		return model.Backfill{}, fmt.Errorf("scanning backfill: %w", err)
+	}
+
+	backfill.Tables = tables
+	backfill.UploadIDs = uploadIDs
+	backfill.Error = errorMsg.String
+	backfill.StartTime = backfill.StartTime.UTC()
+	backfill.EndTime = backfill.EndTime.UTC()
+	backfill.CreatedAt = backfill.CreatedAt.UTC()
+	backfill.UpdatedAt = backfill.UpdatedAt.UTC()
+	return backfill, nil
+}
+
+// SetScheduled marks the backfill as scheduled with the uploads reprocessing its staging files
+func (repo *Backfills) SetScheduled(ctx context.Context, id int64, uploadIDs []int64) error {
+	return repo.update(ctx, id, model.BackfillScheduled, "", uploadIDs)
+}
+
+// SetFailed marks the backfill as failed with the error
+func (repo *Backfills) SetFailed(ctx context.Context, id int64, backfillErr error) error {
+	return repo.update(ctx, id, model.BackfillFailed, backfillErr.Error(), []int64{})
+}
+
+func (repo *Backfills) update(ctx context.Context, id int64, status model.BackfillStatus, errorMsg string, uploadIDs []int64) error {
+	result, err := repo.db.ExecContext(ctx, `
+		UPDATE
+		  `+backfillsTableName+`
+		SET
+		  status = $1,
+		  error = $2,
+		  upload_ids = $3,
+		  updated_at = $4
+		WHERE
+		  id = $5;
+`,
+		status,
+		errorMsg,
+		pq.Array(uploadIDs),
+		repo.now(),
+		id,
+	)
+	if err != nil {
+		return fmt.Errorf("updating backfill: %w", err)
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("rows affected: %w", err)
+	}
+	if affected == 0 {
+		return model.ErrBackfillNotFound
+	}
+	return nil
+}
@@ -0,0 +1,131 @@
+package repo_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/rudderlabs/rudder-server/warehouse/internal/model"
+	"github.com/rudderlabs/rudder-server/warehouse/internal/repo"
+	warehouseutils "github.com/rudderlabs/rudder-server/warehouse/utils"
+)
+
+func TestBackfillRepo(t *testing.T) {
+	ctx := context.Background()
+	now := time.Now().Truncate(time.Second).UTC()
+	db := setupDB(t)
+
+	r := repo.NewBackfills(db, repo.WithNow(func() time.Time {
+		return now
+	}))
+	uploadsRepo := repo.NewUploads(db, repo.WithNow(func() time.Time {
+		return now
+	}))
+	stagingRepo := repo.NewStagingFiles(db, repo.WithNow(func() time.Time {
+		return now
+	}))
+
+	backfill := model.Backfill{
+		WorkspaceID:   "workspace_id",
+		SourceID:      "source_id",
+		DestinationID: "destination_id",
+		Tables:        []string{"tracks", "pages"},
+		StartTime:     now.Add(-2 * time.Hour),
+		EndTime:       now.Add(-time.Hour),
+		DeleteRows:    true,
+	}
+
+	cancelledCtx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	var id int64
+
+	t.Run("Create", func(t *testing.T) {
+		var err error
+
+		id, err = r.Create(ctx, backfill)
+		require.NoError(t, err)
+		require.Equal(t, int64(1), id)
+
+		_, err = r.Create(cancelledCtx, backfill)
+		require.ErrorIs(t, err, context.Canceled)
+	})
+
+	t.Run("Get", func(t *testing.T) {
+		b, err := r.Get(ctx, id)
+		require.NoError(t, err)
+		require.Equal(t, model.Backfill{
+			ID:            id,
+			WorkspaceID:   backfill.WorkspaceID,
+			SourceID:      backfill.SourceID,
+			DestinationID: backfill.DestinationID,
+			Tables:        backfill.Tables,
+			StartTime:     backfill.StartTime,
+			EndTime:       backfill.EndTime,
+			DeleteRows:    backfill.DeleteRows,
+			Status:        model.BackfillWaiting,
+			UploadIDs:     []int64{},
+			CreatedAt:     now,
+			UpdatedAt:     now,
+		}, b)
+
+		_, err = r.Get(ctx, -1)
+		require.ErrorIs(t, err, model.ErrBackfillNotFound)
+	})
+
+	t.Run("SetScheduled", func(t *testing.T) {
+		var uploadIDs []int64
+		for _, status := range []string{model.ExportedData, model.Aborted, model.Waiting} {
+			stagingID, err := stagingRepo.Insert(ctx, &model.StagingFileWithSchema{
+				StagingFile: model.StagingFile{
+					WorkspaceID:   backfill.WorkspaceID,
+					SourceID:      backfill.SourceID,
+					DestinationID: backfill.DestinationID,
+				},
+				Schema: []byte(`{}`),
+			})
+			require.NoError(t, err)
+
+			uploadID, err := uploadsRepo.CreateWithStagingFiles(ctx, model.Upload{
+				WorkspaceID:     backfill.WorkspaceID,
+				SourceID:        backfill.SourceID,
+				DestinationID:   backfill.DestinationID,
+				DestinationType: warehouseutils.POSTGRES,
+				Status:          status,
+				Tables:          backfill.Tables,
+			}, []*model.StagingFile{{ID: stagingID}})
+			require.NoError(t, err)
+
+			uploadIDs = append(uploadIDs, uploadID)
+		}
+
+		require.NoError(t, r.SetScheduled(ctx, id, uploadIDs))
+
+		b, err := r.Get(ctx, id)
+		require.NoError(t, err)
+		require.Equal(t, model.BackfillScheduled, b.Status)
+		require.Equal(t, uploadIDs, b.UploadIDs)
+		require.Equal(t, int64(1), b.ExportedUploads)
+		require.Equal(t, int64(1), b.AbortedUploads)
+		require.Equal(t, model.BackfillFailed, b.CurrentStatus())
+
+		upload, err := uploadsRepo.Get(ctx, uploadIDs[0])
+		require.NoError(t, err)
+		require.Equal(t, backfill.Tables, upload.Tables)
+
+		require.ErrorIs(t, r.SetScheduled(ctx, -1, uploadIDs), model.ErrBackfillNotFound)
+	})
+
+	t.Run("SetFailed", func(t *testing.T) {
+		require.NoError(t, r.SetFailed(ctx, id, errors.New("some error")))
+
+		b, err := r.Get(ctx, id)
+		require.NoError(t, err)
+		require.Equal(t, model.BackfillFailed, b.Status)
+		require.Equal(t, "some error", b.Error)
+		require.Empty(t, b.UploadIDs)
+	})
+}
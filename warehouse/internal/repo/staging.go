@@ -312,6 +312,66 @@ func (repo *StagingFiles) GetForUpload(ctx context.Context, upload model.Upload)
 	return repo.GetForUploadID(ctx, upload.SourceID, upload.DestinationID, upload.ID)
 }
 
+// GetForTimeRange returns the successfully processed staging files of a source and destination,
+// having events in the time range [start, end).
+func (repo *StagingFiles) GetForTimeRange(ctx context.Context, sourceID, destinationID string, start, end time.Time) ([]*model.StagingFile, error) {
+	query := `SELECT ` + stagingTableColumns + ` FROM ` + stagingTableName + `
+	WHERE
+		source_id = $1
+		AND destination_id = $2
+		AND status = $3
+		AND first_event_at < $5
+		AND last_event_at >= $4
+	ORDER BY
+		id ASC;`
+	rows, err := repo.db.QueryContext(ctx, query,
+		sourceID,
+		destinationID,
+		warehouseutils.StagingFileSucceededState,
+		start.UTC(),
+		end.UTC(),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("querying staging files: %w", err)
+	}
+
+	return repo.parseRows(rows)
+}
+
+// CountArchivedForTimeRange returns the number of staging files of a source and destination having events in the time range [start, end),
+// which belong to uploads whose staging and load files were archived, and therefore might not be available in the object storage anymore.
+func (repo *StagingFiles) CountArchivedForTimeRange(ctx context.Context, sourceID, destinationID string, start, end time.Time) (int64, error) {
+	var count int64
+	err := repo.db.QueryRowContext(ctx, `
+		SELECT
+			COUNT(*)
+		FROM
+			`+stagingTableName+` ST
+		WHERE
+			source_id = $1
+			AND destination_id = $2
+			AND first_event_at < $4
+			AND last_event_at >= $3
+			AND EXISTS (
+				SELECT
+					1
+				FROM
+					`+uploadsTableName+` UT
+				WHERE
+					UT.id = ST.upload_id
+					AND COALESCE(UT.metadata->>'archivedStagingAndLoadFiles', 'false')::bool
+			);`,
+		sourceID,
+		destinationID,
+		start.UTC(),
+		end.UTC(),
+	).Scan(&count)
+	if err != nil {
+		return 0, fmt.Errorf("counting archived staging files: %w", err)
+	}
+	return count, nil
+}
+
 func (repo *StagingFiles) Pending(ctx context.Context, sourceID, destinationID string) ([]*model.StagingFile, error) {
 	var (
 		uploadID               int64
@@ -392,6 +392,81 @@ func TestStagingFileRepo_Pending(t *testing.T) {
 	}
 }
 
+func TestStagingFileRepo_GetForTimeRange(t *testing.T) {
+	ctx := context.Background()
+	now := time.Now().Truncate(time.Second).UTC()
+	db := setupDB(t)
+	r := repo.NewStagingFiles(db, repo.WithNow(func() time.Time {
+		return now
+	}))
+	uploadRepo := repo.NewUploads(db)
+
+	var ids []int64
+	for i := 0; i < 5; i++ {
+		file := model.StagingFile{
+			WorkspaceID:   "workspace_id",
+			Location:      fmt.Sprintf("s3://bucket/path/to/file-%d", i),
+			SourceID:      "source_id",
+			DestinationID: "destination_id",
+			FirstEventAt:  now.Add(time.Duration(i) * time.Hour),
+			LastEventAt:   now.Add(time.Duration(i)*time.Hour + 30*time.Minute),
+		}.WithSchema([]byte(`{"type": "object"}`))
+
+		id, err := r.Insert(ctx, &file)
+		require.NoError(t, err)
+
+		ids = append(ids, id)
+	}
+	require.NoError(t, r.SetStatuses(ctx, ids[:4], warehouseutils.StagingFileSucceededState))
+
+	t.Run("succeeded files in range", func(t *testing.T) {
+		files, err := r.GetForTimeRange(ctx, "source_id", "destination_id", now.Add(time.Hour), now.Add(2*time.Hour+15*time.Minute))
+		require.NoError(t, err)
+		require.Equal(t, []int64{ids[1], ids[2]}, repo.StagingFileIDs(files))
+	})
+
+	t.Run("waiting files are excluded", func(t *testing.T) {
+		files, err := r.GetForTimeRange(ctx, "source_id", "destination_id", now.Add(3*time.Hour), now.Add(5*time.Hour))
+		require.NoError(t, err)
+		require.Equal(t, []int64{ids[3]}, repo.StagingFileIDs(files))
+	})
+
+	t.Run("end time is exclusive", func(t *testing.T) {
+		files, err := r.GetForTimeRange(ctx, "source_id", "destination_id", now, now.Add(time.Hour))
+		require.NoError(t, err)
+		require.Equal(t, []int64{ids[0]}, repo.StagingFileIDs(files))
+	})
+
+	t.Run("other connections", func(t *testing.T) {
+		files, err := r.GetForTimeRange(ctx, "other_source_id", "destination_id", now, now.Add(5*time.Hour))
+		require.NoError(t, err)
+		require.Empty(t, files)
+	})
+
+	t.Run("archived uploads", func(t *testing.T) {
+		count, err := r.CountArchivedForTimeRange(ctx, "source_id", "destination_id", now, now.Add(5*time.Hour))
+		require.NoError(t, err)
+		require.Zero(t, count)
+
+		uploadID, err := uploadRepo.CreateWithStagingFiles(ctx, model.Upload{
+			SourceID:      "source_id",
+			DestinationID: "destination_id",
+		}, []*model.StagingFile{{ID: ids[1]}})
+		require.NoError(t, err)
+
+		_, err = db.ExecContext(ctx, `UPDATE wh_uploads SET metadata = metadata || '{"archivedStagingAndLoadFiles": true}' WHERE id = $1`, uploadID)
+		require.NoError(t, err)
+
+		count, err = r.CountArchivedForTimeRange(ctx, "source_id", "destination_id", now.Add(time.Hour), now.Add(2*time.Hour+15*time.Minute))
+		require.NoError(t, err)
+		require.Equal(t, int64(1), count)
+
+		count, err = r.CountArchivedForTimeRange(ctx, "source_id", "destination_id", now.Add(2*time.Hour), now.Add(5*time.Hour))
+		require.NoError(t, err)
+		require.Zero(t, count)
+	})
+}
+
 func TestStagingFileRepo_Status(t *testing.T) {
 	ctx := context.Background()
 	now := time.Now().Truncate(time.Second).UTC()
@@ -30,7 +30,11 @@ const (
 		total_events,
 		created_at,
 		updated_at,
-		location
+		location,
+		cost_queries,
+		cost_bytes_scanned,
+		cost_bytes_billed,
+		cost_credits
 	`
 )
 
@@ -43,6 +47,7 @@ type TableUploadSetOptions struct {
 	LastExecTime *time.Time
 	Location     *string
 	TotalEvents  *int64
+	Cost         *model.LoadCost
 }
 
 func NewTableUploads(db *sqlmiddleware.DB, opts ...Opt) *TableUploads {
@@ -161,6 +166,10 @@ func (*TableUploads) parseRows(rows *sqlmiddleware.Rows) ([]model.TableUpload, e
 			&tableUpload.CreatedAt,
 			&tableUpload.UpdatedAt,
 			&locationRaw,
+			&tableUpload.Cost.Queries,
+			&tableUpload.Cost.BytesScanned,
+			&tableUpload.Cost.BytesBilled,
+			&tableUpload.Cost.Credits,
 		)
 		if err != nil {
 			return nil, fmt.Errorf("scanning row: %w", err)
@@ -283,6 +292,53 @@ func (repo *TableUploads) TotalExportedEvents(ctx context.Context, uploadId int6
 	return 0, errors.New(`count is not valid`)
 }
 
+// LastExportedEventAt returns the last event time of the uploads which exported the tables for the source and destination,
+// keyed by the table name. Tables which were never exported are not returned.
+func (repo *TableUploads) LastExportedEventAt(ctx context.Context, sourceID, destinationID string, tableNames []string) (map[string]time.Time, error) {
+	rows, err := repo.db.QueryContext(ctx, `
+		SELECT
+		  TU.table_name,
+		  MAX(U.last_event_at)
+		FROM
+		  `+tableUploadTableName+` TU
+		  JOIN `+uploadsTableName+` U ON U.id = TU.wh_upload_id
+		WHERE
+		  U.source_id = $1
+		  AND U.destination_id = $2
+		  AND TU.table_name = ANY($3)
+		  AND TU.status = $4
+		GROUP BY
+		  TU.table_name;
+`,
+		sourceID,
+		destinationID,
+		pq.Array(tableNames),
+		model.TableUploadExported,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("querying last exported event time: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	lastEventAt := make(map[string]time.Time)
+	for rows.Next() {
+		var (
+			tableName string
+			eventAt   sql.NullTime
+		)
+		if err := rows.Scan(&tableName, &eventAt); err != nil {
+			return nil, fmt.Errorf("scanning last exported event time: %w", err)
+		}
+		if eventAt.Valid {
+			lastEventAt[tableName] = eventAt.Time.UTC()
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterating last exported event time: %w", err)
+	}
+	return lastEventAt, nil
+}
+
 func (repo *TableUploads) Set(ctx context.Context, uploadId int64, tableName string, options TableUploadSetOptions) error {
 	var (
 		query     string
@@ -317,6 +373,12 @@ func (repo *TableUploads) Set(ctx context.Context, uploadId int64, tableName str
 		setQuery.WriteString(fmt.Sprintf(`total_events = $%d,`, len(queryArgs)+1))
 		queryArgs = append(queryArgs, *options.TotalEvents)
 	}
+	if options.Cost != nil {
+		setQuery.WriteString(fmt.Sprintf(`cost_queries = $%d, cost_bytes_scanned = $%d, cost_bytes_billed = $%d, cost_credits = $%d,`,
+			len(queryArgs)+1, len(queryArgs)+2, len(queryArgs)+3, len(queryArgs)+4,
+		))
+		queryArgs = append(queryArgs, options.Cost.Queries, options.Cost.BytesScanned, options.Cost.BytesBilled, options.Cost.Credits)
+	}
 
 	if setQuery.Len() == 0 {
 		return fmt.Errorf(`no set options provided`)
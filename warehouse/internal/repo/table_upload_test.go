@@ -222,6 +222,24 @@ func TestTableUploadRepo(t *testing.T) {
 			require.Equal(t, now, tableUpload.UpdatedAt)
 		})
 
+		t.Run("set cost", func(t *testing.T) {
+			cost := model.LoadCost{
+				Queries:      3,
+				BytesScanned: 1024,
+				BytesBilled:  10485760,
+				Credits:      0.25,
+			}
+			err := r.Set(ctx, uploadID, table, repo.TableUploadSetOptions{
+				Cost: &cost,
+			})
+			require.NoError(t, err)
+
+			tableUpload, err := r.GetByUploadIDAndTableName(ctx, uploadID, table)
+			require.NoError(t, err)
+			require.Equal(t, cost, tableUpload.Cost)
+			require.Equal(t, now, tableUpload.UpdatedAt)
+		})
+
 		t.Run("set all", func(t *testing.T) {
 			err := r.Set(ctx, uploadID, table, repo.TableUploadSetOptions{
 				Status:       &status,
@@ -344,3 +362,69 @@ func TestTableUploadRepo(t *testing.T) {
 		})
 	})
 }
+
+func TestTableUploadRepo_LastExportedEventAt(t *testing.T) {
+	ctx := context.Background()
+	now := time.Now().Truncate(time.Second).UTC()
+	db := setupDB(t)
+
+	r := repo.NewTableUploads(db, repo.WithNow(func() time.Time {
+		return now
+	}))
+	uploadsRepo := repo.NewUploads(db, repo.WithNow(func() time.Time {
+		return now
+	}))
+	stagingRepo := repo.NewStagingFiles(db, repo.WithNow(func() time.Time {
+		return now
+	}))
+
+	const (
+		sourceID      = "source_id"
+		destinationID = "destination_id"
+	)
+
+	for i, tables := range [][]string{{"tracks", "pages"}, {"tracks"}} {
+		lastEventAt := now.Add(-time.Duration(2-i) * time.Hour)
+
+		stagingID, err := stagingRepo.Insert(ctx, &model.StagingFileWithSchema{
+			StagingFile: model.StagingFile{
+				SourceID:      sourceID,
+				DestinationID: destinationID,
+			},
+			Schema: []byte(`{}`),
+		})
+		require.NoError(t, err)
+
+		uploadID, err := uploadsRepo.CreateWithStagingFiles(ctx, model.Upload{
+			SourceID:      sourceID,
+			DestinationID: destinationID,
+			Status:        model.ExportedData,
+		}, []*model.StagingFile{{ID: stagingID, LastEventAt: lastEventAt}})
+		require.NoError(t, err)
+
+		require.NoError(t, r.Insert(ctx, uploadID, append(tables, "identifies")))
+		status := model.TableUploadExported
+		for _, table := range tables {
+			require.NoError(t, r.Set(ctx, uploadID, table, repo.TableUploadSetOptions{
+				Status: &status,
+			}))
+		}
+	}
+
+	lastEventAt, err := r.LastExportedEventAt(ctx, sourceID, destinationID, []string{"tracks", "pages", "identifies"})
+	require.NoError(t, err)
+	require.Equal(t, map[string]time.Time{
+		"tracks": now.Add(-time.Hour),
+		"pages":  now.Add(-2 * time.Hour),
+	}, lastEventAt)
+
+	lastEventAt, err = r.LastExportedEventAt(ctx, sourceID, "other_destination_id", []string{"tracks"})
+	require.NoError(t, err)
+	require.Empty(t, lastEventAt)
+
+	cancelledCtx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	_, err = r.LastExportedEventAt(cancelledCtx, sourceID, destinationID, []string{"tracks"})
+	require.ErrorIs(t, err, context.Canceled)
+}
@@ -67,6 +67,7 @@ type UploadMetadata struct {
 	Retried          bool      `json:"retried"`
 	Priority         int       `json:"priority"`
 	NextRetryTime    time.Time `json:"nextRetryTime"`
+	Tables           []string  `json:"tables,omitempty"`
 }
 
 func NewUploads(db *sqlmiddleware.DB, opts ...Opt) *Uploads {
@@ -92,6 +93,7 @@ func ExtractUploadMetadata(upload model.Upload) UploadMetadata {
 		Retried:          upload.Retried,
 		Priority:         upload.Priority,
 		NextRetryTime:    upload.NextRetryTime,
+		Tables:           upload.Tables,
 	}
 }
 
@@ -112,15 +114,21 @@ func (uploads *Uploads) CreateWithStagingFiles(ctx context.Context, upload model
 		lastEventAt = files[len(files)-1].LastEventAt
 	}
 
+	loadFileType := upload.LoadFileType
+	if loadFileType == "" {
+		loadFileType = warehouseutils.GetLoadFileType(upload.DestinationType)
+	}
+
 	metadataMap := UploadMetadata{
 		UseRudderStorage: files[0].UseRudderStorage,
 		SourceTaskRunID:  files[0].SourceTaskRunID,
 		SourceJobID:      files[0].SourceJobID,
 		SourceJobRunID:   files[0].SourceJobRunID,
-		LoadFileType:     warehouseutils.GetLoadFileType(upload.DestinationType),
+		LoadFileType:     loadFileType,
 		Retried:          upload.Retried,
 		Priority:         upload.Priority,
 		NextRetryTime:    upload.NextRetryTime,
+		Tables:           upload.Tables,
 	}
 
 	metadata, err := json.Marshal(metadataMap)
@@ -509,6 +517,7 @@ func scanUpload(scan scanFn, upload *model.Upload) error {
 	upload.Priority = metadata.Priority
 	upload.Retried = metadata.Retried
 	upload.UseRudderStorage = metadata.UseRudderStorage
+	upload.Tables = metadata.Tables
 
 	_, upload.FirstAttemptAt = warehouseutils.TimingFromJSONString(firstTiming)
 	var lastStatus string
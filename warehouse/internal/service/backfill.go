@@ -0,0 +1,259 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/samber/lo"
+
+	"github.com/rudderlabs/rudder-go-kit/logger"
+
+	"github.com/rudderlabs/rudder-server/utils/misc"
+	"github.com/rudderlabs/rudder-server/warehouse/integrations/manager"
+	"github.com/rudderlabs/rudder-server/warehouse/internal/model"
+	"github.com/rudderlabs/rudder-server/warehouse/jobs"
+	warehouseutils "github.com/rudderlabs/rudder-server/warehouse/utils"
+)
+
+// backfillUploadPriority is the priority of the uploads scheduled for backfills, same as the regular uploads
+const backfillUploadPriority = 100
+
+var (
+	ErrBackfillNoTables       = errors.New("backfill tables are empty")
+	ErrBackfillInvalidRange   = errors.New("backfill start and end times are required, with the end time after the start time")
+	ErrBackfillNoWarehouses   = errors.New("no warehouse connections found for backfill")
+	ErrBackfillNoStagingFiles = errors.New("no staging files found for backfill")
+	ErrBackfillArchived       = errors.New("staging files of the backfill range were archived")
+	ErrBackfillUnalignedRange = errors.New("deleting rows requires the backfill range to cover the staging files entirely")
+)
+
+type backfillRepo interface {
+	Create(ctx context.Context, backfill model.Backfill) (int64, error)
+	Get(ctx context.Context, id int64) (model.Backfill, error)
+	SetScheduled(ctx context.Context, id int64, uploadIDs []int64) error
+	SetFailed(ctx context.Context, id int64, backfillErr error) error
+}
+
+type backfillStagingRepo interface {
+	GetForTimeRange(ctx context.Context, sourceID, destinationID string, start, end time.Time) ([]*model.StagingFile, error)
+	CountArchivedForTimeRange(ctx context.Context, sourceID, destinationID string, start, end time.Time) (int64, error)
+	SetStatuses(ctx context.Context, ids []int64, status string) error
+}
+
+type backfillUploadRepo interface {
+	CreateWithStagingFiles(ctx context.Context, upload model.Upload, files []*model.StagingFile) (int64, error)
+}
+
+// WarehouseDeleter deletes the rows of a source received in a time range from the tables of a warehouse
+type WarehouseDeleter interface {
+	Setup(ctx context.Context, warehouse model.Warehouse, uploader warehouseutils.Uploader) error
+	DeleteBy(ctx context.Context, tableNames []string, params warehouseutils.DeleteByParams) error
+	Cleanup(ctx context.Context)
+}
+
+// Backfill re-syncs the staging files received in a time range into a set of tables,
+// by scheduling new uploads which reprocess them.
+type Backfill struct {
+	backfills          backfillRepo
+	stagingFiles       backfillStagingRepo
+	uploads            backfillUploadRepo
+	newWarehouseDelete func(destType string) (WarehouseDeleter, error)
+	batchSize          int
+	logger             logger.Logger
+}
+
+func NewBackfill(
+	backfills backfillRepo,
+	stagingFiles backfillStagingRepo,
+	uploads backfillUploadRepo,
+	newWarehouseDelete func(destType string) (WarehouseDeleter, error),
+	batchSize int,
+	log logger.Logger,
+) *Backfill {
+	return &Backfill{
+		backfills:          backfills,
+		stagingFiles:       stagingFiles,
+		uploads:            uploads,
+		newWarehouseDelete: newWarehouseDelete,
+		batchSize:          batchSize,
+		logger:             log,
+	}
+}
+
+// Create creates a backfill for the warehouse connections and schedules the uploads reprocessing their staging files.
+//
+//	Backfills are rejected if any of the staging files in the range were archived, since their events can't be reprocessed.
+//	If rows are to be deleted, the rows of the source received in the range [start time, end time) are deleted from the tables
+//	before scheduling the uploads, which requires the staging files to have no events outside the range.
+//	Errors after the backfill is created are recorded on the backfill.
+func (b *Backfill) Create(ctx context.Context, warehouses []model.Warehouse, backfill model.Backfill) (model.Backfill, error) {
+	if err := validateBackfill(backfill); err != nil {
+		return model.Backfill{}, err
+	}
+	if len(warehouses) == 0 {
+		return model.Backfill{}, ErrBackfillNoWarehouses
+	}
+
+	stagingFilesByWarehouse := make([][]*model.StagingFile, len(warehouses))
+	var totalStagingFiles int
+	for i, warehouse := range warehouses {
+		archived, err := b.stagingFiles.CountArchivedForTimeRange(ctx, warehouse.Source.ID, warehouse.Destination.ID, backfill.StartTime, backfill.EndTime)
+		if err != nil {
+			return model.Backfill{}, fmt.Errorf("counting archived staging files: %w", err)
+		}
+		if archived > 0 {
+			return model.Backfill{}, fmt.Errorf("%w: %d staging files of source %s", ErrBackfillArchived, archived, warehouse.Source.ID)
+		}
+
+		stagingFiles, err := b.stagingFiles.GetForTimeRange(ctx, warehouse.Source.ID, warehouse.Destination.ID, backfill.StartTime, backfill.EndTime)
+		if err != nil {
+			return model.Backfill{}, fmt.Errorf("getting staging files: %w", err)
+		}
+		if backfill.DeleteRows {
+			if err := validateDeleteRange(stagingFiles, backfill); err != nil {
+				return model.Backfill{}, err
+			}
+		}
+		stagingFilesByWarehouse[i] = stagingFiles
+		totalStagingFiles += len(stagingFiles)
+	}
+	if totalStagingFiles == 0 {
+		return model.Backfill{}, ErrBackfillNoStagingFiles
+	}
+
+	// deleters are created upfront, so that no rows are deleted if any of them can't be created
+	deleters := make([]WarehouseDeleter, len(warehouses))
+	if backfill.DeleteRows {
+		for i, warehouse := range warehouses {
+			if len(stagingFilesByWarehouse[i]) == 0 {
+				continue
+			}
+			deleter, err := b.newWarehouseDelete(warehouse.Type)
+			if err != nil {
+				return model.Backfill{}, fmt.Errorf("creating warehouse deleter: %w", err)
+			}
+			deleters[i] = deleter
+		}
+	}
+
+	id, err := b.backfills.Create(ctx, backfill)
+	if err != nil {
+		return model.Backfill{}, fmt.Errorf("creating backfill: %w", err)
+	}
+
+	uploadIDs, err := b.schedule(ctx, warehouses, stagingFilesByWarehouse, deleters, backfill)
+	if err != nil {
+		b.logger.Errorw("scheduling backfill", "backfillID", id, "error", err)
+
+		if err := b.backfills.SetFailed(ctx, id, err); err != nil {
+			return model.Backfill{}, fmt.Errorf("setting backfill failed: %w", err)
+		}
+		return b.backfills.Get(ctx, id)
+	}
+
+	if err := b.backfills.SetScheduled(ctx, id, uploadIDs); err != nil {
+		return model.Backfill{}, fmt.Errorf("setting backfill scheduled: %w", err)
+	}
+	return b.backfills.Get(ctx, id)
+}
+
+// Get returns the backfill along with the progress of its uploads
+func (b *Backfill) Get(ctx context.Context, id int64) (model.Backfill, error) {
+	return b.backfills.Get(ctx, id)
+}
+
+func (b *Backfill) schedule(
+	ctx context.Context,
+	warehouses []model.Warehouse,
+	stagingFilesByWarehouse [][]*model.StagingFile,
+	deleters []WarehouseDeleter,
+	backfill model.Backfill,
+) ([]int64, error) {
+	var uploadIDs []int64
+
+	for i, warehouse := range warehouses {
+		stagingFiles := stagingFilesByWarehouse[i]
+		if len(stagingFiles) == 0 {
+			continue
+		}
+
+		if backfill.DeleteRows {
+			if err := deleteRows(ctx, deleters[i], warehouse, backfill); err != nil {
+				return nil, fmt.Errorf("deleting rows for source %s: %w", warehouse.Source.ID, err)
+			}
+		}
+
+		stagingFileIDs := lo.Map(stagingFiles, func(stagingFile *model.StagingFile, _ int) int64 {
+			return stagingFile.ID
+		})
+
+		// staging files are marked as waiting so that the load files get generated again
+		if err := b.stagingFiles.SetStatuses(ctx, stagingFileIDs, warehouseutils.StagingFileWaitingState); err != nil {
+			return nil, fmt.Errorf("setting staging files status: %w", err)
+		}
+
+		for _, batch := range StageFileBatching(stagingFiles, b.batchSize) {
+			uploadID, err := b.uploads.CreateWithStagingFiles(ctx, model.Upload{
+				SourceID:        warehouse.Source.ID,
+				Namespace:       warehouse.Namespace,
+				WorkspaceID:     warehouse.WorkspaceID,
+				DestinationID:   warehouse.Destination.ID,
+				DestinationType: warehouse.Type,
+				Status:          model.Waiting,
+				LoadFileType:    manager.LoadFileType(warehouse),
+				Priority:        backfillUploadPriority,
+				Tables:          backfill.Tables,
+			}, batch)
+			if err != nil {
+				return nil, fmt.Errorf("creating upload: %w", err)
+			}
+			uploadIDs = append(uploadIDs, uploadID)
+		}
+	}
+	return uploadIDs, nil
+}
+
+func deleteRows(ctx context.Context, deleter WarehouseDeleter, warehouse model.Warehouse, backfill model.Backfill) error {
+	if err := deleter.Setup(ctx, warehouse, &jobs.WhAsyncJob{}); err != nil {
+		return fmt.Errorf("setting up warehouse manager: %w", err)
+	}
+	defer deleter.Cleanup(ctx)
+
+	tableNames := make([]string, 0, len(backfill.Tables))
+	for _, tableName := range backfill.Tables {
+		tableNames = append(tableNames, warehouseutils.ToProviderCase(warehouse.Type, tableName))
+	}
+	return deleter.DeleteBy(ctx, tableNames, warehouseutils.DeleteByParams{
+		SourceId:  warehouse.Source.ID,
+		StartTime: backfill.StartTime.UTC().Format(misc.RFC3339Milli),
+		EndTime:   backfill.EndTime.UTC().Format(misc.RFC3339Milli),
+	})
+}
+
+func validateBackfill(backfill model.Backfill) error {
+	if len(backfill.Tables) == 0 {
+		return ErrBackfillNoTables
+	}
+	if backfill.StartTime.IsZero() || backfill.EndTime.IsZero() || !backfill.StartTime.Before(backfill.EndTime) {
+		return ErrBackfillInvalidRange
+	}
+	return nil
+}
+
+// validateDeleteRange makes sure that the staging files have no events outside the backfill range,
+// since the rows deleted are the ones in the range while the staging files are reprocessed entirely.
+func validateDeleteRange(stagingFiles []*model.StagingFile, backfill model.Backfill) error {
+	for _, stagingFile := range stagingFiles {
+		if stagingFile.FirstEventAt.Before(backfill.StartTime) || !stagingFile.LastEventAt.Before(backfill.EndTime) {
+			return fmt.Errorf("%w: staging file %d has events from %s to %s",
+				ErrBackfillUnalignedRange,
+				stagingFile.ID,
+				stagingFile.FirstEventAt.UTC().Format(misc.RFC3339Milli),
+				stagingFile.LastEventAt.UTC().Format(misc.RFC3339Milli),
+			)
+		}
+	}
+	return nil
+}
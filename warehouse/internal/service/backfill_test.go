@@ -0,0 +1,328 @@
+package service_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/rudderlabs/rudder-go-kit/logger"
+	backendconfig "github.com/rudderlabs/rudder-server/backend-config"
+	"github.com/rudderlabs/rudder-server/warehouse/internal/model"
+	"github.com/rudderlabs/rudder-server/warehouse/internal/service"
+	warehouseutils "github.com/rudderlabs/rudder-server/warehouse/utils"
+)
+
+type mockBackfillRepo struct {
+	backfills map[int64]model.Backfill
+}
+
+func (r *mockBackfillRepo) Create(_ context.Context, backfill model.Backfill) (int64, error) {
+	backfill.ID = int64(len(r.backfills) + 1)
+	backfill.Status = model.BackfillWaiting
+	r.backfills[backfill.ID] = backfill
+	return backfill.ID, nil
+}
+
+func (r *mockBackfillRepo) Get(_ context.Context, id int64) (model.Backfill, error) {
+	backfill, ok := r.backfills[id]
+	if !ok {
+		return model.Backfill{}, model.ErrBackfillNotFound
+	}
+	return backfill, nil
+}
+
+func (r *mockBackfillRepo) SetScheduled(_ context.Context, id int64, uploadIDs []int64) error {
+	backfill := r.backfills[id]
+	backfill.Status = model.BackfillScheduled
+	backfill.UploadIDs = uploadIDs
+	r.backfills[id] = backfill
+	return nil
+}
+
+func (r *mockBackfillRepo) SetFailed(_ context.Context, id int64, backfillErr error) error {
+	backfill := r.backfills[id]
+	backfill.Status = model.BackfillFailed
+	backfill.Error = backfillErr.Error()
+	r.backfills[id] = backfill
+	return nil
+}
+
+type mockBackfillStagingRepo struct {
+	files    map[string][]*model.StagingFile
+	archived map[string]int64
+	statuses map[int64]string
+}
+
+func (r *mockBackfillStagingRepo) GetForTimeRange(_ context.Context, sourceID, _ string, _, _ time.Time) ([]*model.StagingFile, error) {
+	return r.files[sourceID], nil
+}
+
+func (r *mockBackfillStagingRepo) CountArchivedForTimeRange(_ context.Context, sourceID, _ string, _, _ time.Time) (int64, error) {
+	return r.archived[sourceID], nil
+}
+
+func (r *mockBackfillStagingRepo) SetStatuses(_ context.Context, ids []int64, status string) error {
+	for _, id := range ids {
+		r.statuses[id] = status
+	}
+	return nil
+}
+
+type mockBackfillUploadRepo struct {
+	uploads []model.Upload
+}
+
+func (r *mockBackfillUploadRepo) CreateWithStagingFiles(_ context.Context, upload model.Upload, files []*model.StagingFile) (int64, error) {
+	upload.StagingFileStartID = files[0].ID
+	upload.StagingFileEndID = files[len(files)-1].ID
+	r.uploads = append(r.uploads, upload)
+	return int64(len(r.uploads)), nil
+}
+
+type mockWarehouseDeleter struct {
+	tables  []string
+	params  []warehouseutils.DeleteByParams
+	err     error
+	cleaned bool
+}
+
+func (*mockWarehouseDeleter) Setup(context.Context, model.Warehouse, warehouseutils.Uploader) error {
+	return nil
+}
+
+func (d *mockWarehouseDeleter) DeleteBy(_ context.Context, tableNames []string, params warehouseutils.DeleteByParams) error {
+	d.tables = append(d.tables, tableNames...)
+	d.params = append(d.params, params)
+	return d.err
+}
+
+func (d *mockWarehouseDeleter) Cleanup(context.Context) {
+	d.cleaned = true
+}
+
+func TestBackfill(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2023, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	warehouses := []model.Warehouse{
+		{
+			WorkspaceID: "workspace_id",
+			Source:      backendconfig.SourceT{ID: "source_id_1"},
+			Destination: backendconfig.DestinationT{ID: "destination_id"},
+			Namespace:   "namespace",
+			Type:        warehouseutils.SNOWFLAKE,
+		},
+		{
+			WorkspaceID: "workspace_id",
+			Source:      backendconfig.SourceT{ID: "source_id_2"},
+			Destination: backendconfig.DestinationT{ID: "destination_id"},
+			Namespace:   "namespace",
+			Type:        warehouseutils.SNOWFLAKE,
+		},
+	}
+
+	startTime := now.Add(-time.Hour)
+
+	setupWithDeleter := func(newDeleter func(string) (service.WarehouseDeleter, error)) (*service.Backfill, *mockBackfillStagingRepo, *mockBackfillUploadRepo) {
+		stagingRepo := &mockBackfillStagingRepo{
+			files: map[string][]*model.StagingFile{
+				"source_id_1": {
+					{ID: 1, FirstEventAt: startTime, LastEventAt: startTime.Add(10 * time.Minute)},
+					{ID: 2, FirstEventAt: startTime.Add(20 * time.Minute), LastEventAt: startTime.Add(30 * time.Minute)},
+					{ID: 3, FirstEventAt: startTime.Add(40 * time.Minute), LastEventAt: now.Add(-time.Millisecond)},
+				},
+			},
+			archived: map[string]int64{},
+			statuses: map[int64]string{},
+		}
+		uploadRepo := &mockBackfillUploadRepo{}
+
+		b := service.NewBackfill(
+			&mockBackfillRepo{backfills: map[int64]model.Backfill{}},
+			stagingRepo,
+			uploadRepo,
+			newDeleter,
+			2,
+			logger.NOP,
+		)
+		return b, stagingRepo, uploadRepo
+	}
+	setup := func(deleter *mockWarehouseDeleter) (*service.Backfill, *mockBackfillStagingRepo, *mockBackfillUploadRepo) {
+		return setupWithDeleter(func(string) (service.WarehouseDeleter, error) {
+			return deleter, nil
+		})
+	}
+
+	t.Run("validation", func(t *testing.T) {
+		b, _, _ := setup(&mockWarehouseDeleter{})
+
+		testCases := []struct {
+			name     string
+			backfill model.Backfill
+			wantErr  error
+		}{
+			{
+				name:     "no tables",
+				backfill: model.Backfill{StartTime: now.Add(-time.Hour), EndTime: now},
+				wantErr:  service.ErrBackfillNoTables,
+			},
+			{
+				name:     "no start time",
+				backfill: model.Backfill{Tables: []string{"tracks"}, EndTime: now},
+				wantErr:  service.ErrBackfillInvalidRange,
+			},
+			{
+				name:     "no end time",
+				backfill: model.Backfill{Tables: []string{"tracks"}, StartTime: now},
+				wantErr:  service.ErrBackfillInvalidRange,
+			},
+			{
+				name:     "end time before start time",
+				backfill: model.Backfill{Tables: []string{"tracks"}, StartTime: now, EndTime: now.Add(-time.Hour)},
+				wantErr:  service.ErrBackfillInvalidRange,
+			},
+			{
+				name:     "delete rows of staging files outside the range",
+				backfill: model.Backfill{Tables: []string{"tracks"}, StartTime: startTime.Add(time.Minute), EndTime: now, DeleteRows: true},
+				wantErr:  service.ErrBackfillUnalignedRange,
+			},
+			{
+				name:     "delete rows with end time before the last event",
+				backfill: model.Backfill{Tables: []string{"tracks"}, StartTime: startTime, EndTime: now.Add(-time.Millisecond), DeleteRows: true},
+				wantErr:  service.ErrBackfillUnalignedRange,
+			},
+		}
+
+		for _, tc := range testCases {
+			tc := tc
+
+			t.Run(tc.name, func(t *testing.T) {
+				_, err := b.Create(ctx, warehouses, tc.backfill)
+				require.ErrorIs(t, err, tc.wantErr)
+			})
+		}
+
+		_, err := b.Create(ctx, nil, model.Backfill{Tables: []string{"tracks"}, StartTime: startTime, EndTime: now})
+		require.ErrorIs(t, err, service.ErrBackfillNoWarehouses)
+
+		_, err = b.Create(ctx, warehouses[1:], model.Backfill{Tables: []string{"tracks"}, StartTime: startTime, EndTime: now})
+		require.ErrorIs(t, err, service.ErrBackfillNoStagingFiles)
+	})
+
+	t.Run("archived staging files", func(t *testing.T) {
+		deleter := &mockWarehouseDeleter{}
+		b, stagingRepo, uploadRepo := setup(deleter)
+		stagingRepo.archived["source_id_2"] = 1
+
+		_, err := b.Create(ctx, warehouses, model.Backfill{
+			DestinationID: "destination_id",
+			Tables:        []string{"tracks"},
+			StartTime:     startTime,
+			EndTime:       now,
+			DeleteRows:    true,
+		})
+		require.ErrorIs(t, err, service.ErrBackfillArchived)
+		require.Empty(t, deleter.params)
+		require.Empty(t, stagingRepo.statuses)
+		require.Empty(t, uploadRepo.uploads)
+	})
+
+	t.Run("schedules uploads", func(t *testing.T) {
+		deleter := &mockWarehouseDeleter{}
+		b, stagingRepo, uploadRepo := setup(deleter)
+
+		backfill, err := b.Create(ctx, warehouses, model.Backfill{
+			DestinationID: "destination_id",
+			Tables:        []string{"tracks", "pages"},
+			StartTime:     startTime.Add(time.Minute),
+			EndTime:       now,
+		})
+		require.NoError(t, err)
+		require.Equal(t, model.BackfillScheduled, backfill.Status)
+		require.Equal(t, []int64{1, 2}, backfill.UploadIDs)
+
+		require.Equal(t, map[int64]string{
+			1: warehouseutils.StagingFileWaitingState,
+			2: warehouseutils.StagingFileWaitingState,
+			3: warehouseutils.StagingFileWaitingState,
+		}, stagingRepo.statuses)
+
+		require.Len(t, uploadRepo.uploads, 2)
+		for _, upload := range uploadRepo.uploads {
+			require.Equal(t, "source_id_1", upload.SourceID)
+			require.Equal(t, "destination_id", upload.DestinationID)
+			require.Equal(t, "namespace", upload.Namespace)
+			require.Equal(t, model.Waiting, upload.Status)
+			require.Equal(t, []string{"tracks", "pages"}, upload.Tables)
+		}
+		require.Equal(t, int64(1), uploadRepo.uploads[0].StagingFileStartID)
+		require.Equal(t, int64(2), uploadRepo.uploads[0].StagingFileEndID)
+		require.Equal(t, int64(3), uploadRepo.uploads[1].StagingFileStartID)
+
+		require.Empty(t, deleter.params)
+	})
+
+	t.Run("deletes rows", func(t *testing.T) {
+		deleter := &mockWarehouseDeleter{}
+		b, _, _ := setup(deleter)
+
+		backfill, err := b.Create(ctx, warehouses, model.Backfill{
+			DestinationID: "destination_id",
+			Tables:        []string{"tracks"},
+			StartTime:     startTime,
+			EndTime:       now,
+			DeleteRows:    true,
+		})
+		require.NoError(t, err)
+		require.Equal(t, model.BackfillScheduled, backfill.Status)
+
+		require.Equal(t, []string{"TRACKS"}, deleter.tables)
+		require.Equal(t, []warehouseutils.DeleteByParams{
+			{
+				SourceId:  "source_id_1",
+				StartTime: "2023-01-02T02:04:05.000Z",
+				EndTime:   "2023-01-02T03:04:05.000Z",
+			},
+		}, deleter.params)
+		require.True(t, deleter.cleaned)
+	})
+
+	t.Run("creating deleter failure", func(t *testing.T) {
+		b, stagingRepo, uploadRepo := setupWithDeleter(func(destType string) (service.WarehouseDeleter, error) {
+			return nil, fmt.Errorf("unknown destination type: %s", destType)
+		})
+
+		_, err := b.Create(ctx, warehouses, model.Backfill{
+			DestinationID: "destination_id",
+			Tables:        []string{"tracks"},
+			StartTime:     startTime,
+			EndTime:       now,
+			DeleteRows:    true,
+		})
+		require.EqualError(t, err, "creating warehouse deleter: unknown destination type: SNOWFLAKE")
+		require.Empty(t, stagingRepo.statuses)
+		require.Empty(t, uploadRepo.uploads)
+	})
+
+	t.Run("delete rows failure", func(t *testing.T) {
+		deleter := &mockWarehouseDeleter{err: errors.New("delete error")}
+		b, stagingRepo, uploadRepo := setup(deleter)
+
+		backfill, err := b.Create(ctx, warehouses, model.Backfill{
+			DestinationID: "destination_id",
+			Tables:        []string{"tracks"},
+			StartTime:     startTime,
+			EndTime:       now,
+			DeleteRows:    true,
+		})
+		require.NoError(t, err)
+		require.Equal(t, model.BackfillFailed, backfill.Status)
+		require.Equal(t, "deleting rows for source source_id_1: delete error", backfill.Error)
+		require.Empty(t, stagingRepo.statuses)
+		require.Empty(t, uploadRepo.uploads)
+	})
+}
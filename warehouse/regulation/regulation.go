@@ -0,0 +1,424 @@
+// Package regulation deletes the data of users from warehouse destinations, e.g. to fulfil GDPR erasure requests.
+package regulation
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"cloud.google.com/go/bigquery"
+	"golang.org/x/exp/slices"
+	"google.golang.org/api/iterator"
+
+	"github.com/rudderlabs/rudder-go-kit/config"
+	"github.com/rudderlabs/rudder-go-kit/logger"
+	"github.com/rudderlabs/rudder-go-kit/stats"
+	backendconfig "github.com/rudderlabs/rudder-server/backend-config"
+	"github.com/rudderlabs/rudder-server/warehouse/client"
+	"github.com/rudderlabs/rudder-server/warehouse/integrations/manager"
+	"github.com/rudderlabs/rudder-server/warehouse/internal/model"
+	"github.com/rudderlabs/rudder-server/warehouse/jobs"
+	"github.com/rudderlabs/rudder-server/warehouse/logfield"
+	warehouseutils "github.com/rudderlabs/rudder-server/warehouse/utils"
+)
+
+const (
+	userIDColumn             = "user_id"
+	anonymousIDColumn        = "anonymous_id"
+	usersIDColumn            = "id"
+	rudderIDColumn           = "rudder_id"
+	mergePropertyTypeColumn  = "merge_property_type"
+	mergePropertyValueColumn = "merge_property_value"
+	mergeProperty1Column     = "merge_property_1_value"
+	mergeProperty2Column     = "merge_property_2_value"
+)
+
+// Request is a request for deleting the rows of users from a namespace of a warehouse destination
+type Request struct {
+	WorkspaceID  string
+	Destination  backendconfig.DestinationT
+	Namespace    string
+	UserIDs      []string
+	AnonymousIDs []string
+}
+
+// Deleter deletes the rows of users from every table of a namespace having any of the identity columns,
+// i.e. user_id and anonymous_id, the id of the users table and the identity resolution tables.
+// When identity resolution tables are present, the anonymous ids and rudder ids linked with the users are resolved first,
+// so that the rows of the users sent before they were identified are deleted as well.
+type Deleter struct {
+	conf   *config.Config
+	logger logger.Logger
+	stats  stats.Stats
+
+	newWarehouseOperations func(destType string) (manager.WarehouseOperations, error)
+	httpClient             *http.Client
+
+	config struct {
+		batchSize    int
+		warehouseURL string
+	}
+}
+
+func New(conf *config.Config, log logger.Logger, stats stats.Stats) *Deleter {
+	d := &Deleter{
+		conf:   conf,
+		logger: log.Child("warehouse").Child("regulation"),
+		stats:  stats,
+	}
+
+	d.newWarehouseOperations = func(destType string) (manager.WarehouseOperations, error) {
+		return manager.NewWarehouseOperations(destType, d.conf, d.logger, d.stats)
+	}
+
+	d.config.batchSize = conf.GetInt("Warehouse.regulation.batchSize", 100)
+	d.config.warehouseURL = conf.GetString("WAREHOUSE_URL", "http://localhost:8082")
+
+	d.httpClient = &http.Client{
+		Timeout: conf.GetDuration("Warehouse.regulation.warehouseTimeout", 30, time.Second),
+	}
+
+	return d
+}
+
+// Namespace returns the namespace of the warehouse for a source, i.e.
+//  1. the namespace the warehouse service loaded the source into, according to its schemas
+//  2. the database set in the destination config, for clickhouse
+//  3. the namespace set in the destination config
+//  4. the custom dataset prefix followed by the source name
+//  5. the source name
+func (d *Deleter) Namespace(ctx context.Context, destination backendconfig.DestinationT, source backendconfig.SourceT) (string, error) {
+	namespace, err := d.loadedNamespace(ctx, destination.ID, source.ID)
+	if err != nil {
+		return "", fmt.Errorf("fetching namespace from warehouse: %w", err)
+	}
+	if namespace != "" {
+		return namespace, nil
+	}
+
+	destType := destination.DestinationDefinition.Name
+
+	if destType == warehouseutils.CLICKHOUSE {
+		if database, ok := destination.Config["database"].(string); ok {
+			return database, nil
+		}
+		return "rudder", nil
+	}
+	if namespace, _ := destination.Config["namespace"].(string); len(strings.TrimSpace(namespace)) > 0 {
+		return warehouseutils.ToProviderCase(destType, warehouseutils.ToSafeNamespace(destType, namespace)), nil
+	}
+	if prefix := d.conf.GetString(fmt.Sprintf("Warehouse.%s.customDatasetPrefix", warehouseutils.WHDestNameMap[destType]), ""); prefix != "" {
+		return warehouseutils.ToProviderCase(destType, warehouseutils.ToSafeNamespace(destType, fmt.Sprintf(`%s_%s`, prefix, source.Name))), nil
+	}
+	return warehouseutils.ToProviderCase(destType, warehouseutils.ToSafeNamespace(destType, source.Name)), nil
+}
+
+// loadedNamespace returns the namespace of the latest schema of the source in the destination, as stored by the warehouse service,
+// or an empty namespace if nothing was loaded from the source yet
+func (d *Deleter) loadedNamespace(ctx context.Context, destinationID, sourceID string) (string, error) {
+	body, err := json.Marshal(warehouseutils.FetchTablesRequest{
+		Connections: []warehouseutils.SourceIDDestinationID{{SourceID: sourceID, DestinationID: destinationID}},
+	})
+	if err != nil {
+		return "", fmt.Errorf("marshalling request: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, d.config.warehouseURL+"/internal/v1/warehouse/fetch-tables", bytes.NewReader(body))
+	if err != nil {
+		return "", fmt.Errorf("creating request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := d.httpClient.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("fetching tables: %w", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return "", fmt.Errorf("reading response: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("fetching tables: status code %d: %s", resp.StatusCode, respBody)
+	}
+
+	var fetchTablesResponse warehouseutils.FetchTablesResponse
+	if err := json.Unmarshal(respBody, &fetchTablesResponse); err != nil {
+		return "", fmt.Errorf("unmarshalling response: %w", err)
+	}
+	for _, connection := range fetchTablesResponse.ConnectionsTables {
+		if connection.SourceID == sourceID && connection.DestinationID == destinationID {
+			return connection.Namespace, nil
+		}
+	}
+	return "", nil
+}
+
+// Delete deletes the rows of the users in the request from the tables of the namespace
+func (d *Deleter) Delete(ctx context.Context, req Request) error {
+	destType := req.Destination.DestinationDefinition.Name
+
+	whManager, err := d.newWarehouseOperations(destType)
+	if err != nil {
+		return fmt.Errorf("creating warehouse manager: %w", err)
+	}
+
+	warehouse := model.Warehouse{
+		WorkspaceID: req.WorkspaceID,
+		Destination: req.Destination,
+		Namespace:   req.Namespace,
+		Type:        destType,
+	}
+
+	if err := whManager.Setup(ctx, warehouse, &jobs.WhAsyncJob{}); err != nil {
+		return fmt.Errorf("setting up warehouse manager: %w", err)
+	}
+	defer whManager.Cleanup(ctx)
+
+	schema, _, err := whManager.FetchSchema(ctx)
+	if err != nil {
+		return fmt.Errorf("fetching schema: %w", err)
+	}
+
+	ids := identities{
+		userIDs:      req.UserIDs,
+		anonymousIDs: req.AnonymousIDs,
+	}
+
+	mappingsTable := warehouseutils.ToProviderCase(destType, warehouseutils.IdentityMappingsTable)
+	if _, ok := schema[mappingsTable]; ok && slices.Contains(warehouseutils.IdentityEnabledWarehouses, destType) {
+		if ids, err = d.resolveIdentities(ctx, whManager, warehouse, mappingsTable, ids); err != nil {
+			return fmt.Errorf("resolving identities: %w", err)
+		}
+	}
+
+	tableNames := make([]string, 0, len(schema))
+	for tableName := range schema {
+		if len(d.identityColumns(destType, tableName, schema[tableName], allIdentities)) > 0 {
+			tableNames = append(tableNames, tableName)
+		}
+	}
+	if len(tableNames) == 0 {
+		return fmt.Errorf("namespace %s has none of the tables of users", req.Namespace)
+	}
+	sort.Strings(tableNames)
+
+	for _, tableName := range tableNames {
+		for columnName, values := range d.identityColumns(destType, tableName, schema[tableName], ids) {
+			if err := d.deleteInBatches(ctx, whManager, tableName, columnName, values); err != nil {
+				return err
+			}
+		}
+	}
+
+	d.logger.Infow("deleted users",
+		logfield.DestinationID, req.Destination.ID,
+		logfield.DestinationType, destType,
+		logfield.WorkspaceID, req.WorkspaceID,
+		logfield.Namespace, req.Namespace,
+	)
+	return nil
+}
+
+type identities struct {
+	userIDs      []string
+	anonymousIDs []string
+	rudderIDs    []string
+}
+
+// allIdentities matches every identity column, for finding the tables of users
+var allIdentities = identities{
+	userIDs:      []string{""},
+	anonymousIDs: []string{""},
+	rudderIDs:    []string{""},
+}
+
+// identityColumns returns the values to delete by identity column of the table
+func (*Deleter) identityColumns(destType, tableName string, tableSchema model.TableSchema, ids identities) map[string][]string {
+	columns := make(map[string][]string)
+
+	add := func(columnName string, values ...[]string) {
+		columnName = warehouseutils.ToProviderCase(destType, columnName)
+		if _, ok := tableSchema[columnName]; !ok {
+			return
+		}
+		for _, v := range values {
+			columns[columnName] = append(columns[columnName], v...)
+		}
+		if len(columns[columnName]) == 0 {
+			delete(columns, columnName)
+		}
+	}
+
+	switch strings.ToLower(tableName) {
+	case warehouseutils.IdentityMappingsTable:
+		add(rudderIDColumn, ids.rudderIDs)
+		add(mergePropertyValueColumn, ids.userIDs, ids.anonymousIDs)
+	case warehouseutils.IdentityMergeRulesTable:
+		add(mergeProperty1Column, ids.userIDs, ids.anonymousIDs)
+		add(mergeProperty2Column, ids.userIDs, ids.anonymousIDs)
+	case warehouseutils.UsersTable:
+		add(usersIDColumn, ids.userIDs)
+	default:
+		add(userIDColumn, ids.userIDs)
+		add(anonymousIDColumn, ids.anonymousIDs)
+	}
+	return columns
+}
+
+func (d *Deleter) deleteInBatches(ctx context.Context, whManager manager.WarehouseOperations, tableName, columnName string, values []string) error {
+	for start := 0; start < len(values); start += d.config.batchSize {
+		end := start + d.config.batchSize
+		if end > len(values) {
+			end = len(values)
+		}
+		if err := whManager.DeleteByColumnValues(ctx, tableName, columnName, values[start:end]); err != nil {
+			return fmt.Errorf("deleting by column %s from table %s: %w", columnName, tableName, err)
+		}
+	}
+	return nil
+}
+
+// resolveIdentities adds the rudder ids of the users and the anonymous ids and user ids linked with them using the identity mappings
+func (d *Deleter) resolveIdentities(
+	ctx context.Context,
+	whManager manager.WarehouseOperations,
+	warehouse model.Warehouse,
+	mappingsTable string,
+	ids identities,
+) (identities, error) {
+	cl, err := whManager.Connect(ctx, warehouse)
+	if err != nil {
+		return identities{}, fmt.Errorf("connecting to warehouse: %w", err)
+	}
+	defer cl.Close()
+
+	known := append(append([]string{}, ids.userIDs...), ids.anonymousIDs...)
+
+	userIDs := make(map[string]struct{})
+	anonymousIDs := make(map[string]struct{})
+	rudderIDs := make(map[string]struct{})
+
+	for start := 0; start < len(known); start += d.config.batchSize {
+		end := start + d.config.batchSize
+		if end > len(known) {
+			end = len(known)
+		}
+
+		mappings, err := queryMappings(ctx, cl, warehouse, mappingsTable, known[start:end])
+		if err != nil {
+			return identities{}, err
+		}
+		for _, m := range mappings {
+			rudderIDs[m.rudderID] = struct{}{}
+			switch strings.ToLower(m.mergePropertyType) {
+			case userIDColumn:
+				userIDs[m.mergePropertyValue] = struct{}{}
+			case anonymousIDColumn:
+				anonymousIDs[m.mergePropertyValue] = struct{}{}
+			}
+		}
+	}
+
+	merge := func(values []string, resolved map[string]struct{}) []string {
+		for _, value := range values {
+			resolved[value] = struct{}{}
+		}
+		merged := make([]string, 0, len(resolved))
+		for value := range resolved {
+			merged = append(merged, value)
+		}
+		sort.Strings(merged)
+		return merged
+	}
+	return identities{
+		userIDs:      merge(ids.userIDs, userIDs),
+		anonymousIDs: merge(ids.anonymousIDs, anonymousIDs),
+		rudderIDs:    merge(nil, rudderIDs),
+	}, nil
+}
+
+type mapping struct {
+	rudderID           string
+	mergePropertyType  string
+	mergePropertyValue string
+}
+
+// queryMappings returns the identity mappings sharing the rudder id with any of the values
+func queryMappings(ctx context.Context, cl client.Client, warehouse model.Warehouse, mappingsTable string, values []string) ([]mapping, error) {
+	columns := warehouseutils.ToProviderCase(warehouse.Type, strings.Join([]string{rudderIDColumn, mergePropertyTypeColumn, mergePropertyValueColumn}, ", "))
+	rudderID := warehouseutils.ToProviderCase(warehouse.Type, rudderIDColumn)
+	mergePropertyValue := warehouseutils.ToProviderCase(warehouse.Type, mergePropertyValueColumn)
+
+	if cl.Type == client.BQClient {
+		table := fmt.Sprintf("`%s`.`%s`", warehouse.Namespace, mappingsTable)
+		query := cl.BQ.Query(fmt.Sprintf(`SELECT %[1]s FROM %[2]s WHERE %[3]s IN (SELECT %[3]s FROM %[2]s WHERE %[4]s IN UNNEST(@values));`,
+			columns, table, rudderID, mergePropertyValue,
+		))
+		query.Parameters = []bigquery.QueryParameter{{Name: "values", Value: values}}
+
+		it, err := query.Read(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("querying identity mappings: %w", err)
+		}
+
+		var mappings []mapping
+		for {
+			var row []bigquery.Value
+			err := it.Next(&row)
+			if errors.Is(err, iterator.Done) {
+				break
+			}
+			if err != nil {
+				return nil, fmt.Errorf("reading identity mappings: %w", err)
+			}
+			mappings = append(mappings, mapping{
+				rudderID:           fmt.Sprint(row[0]),
+				mergePropertyType:  fmt.Sprint(row[1]),
+				mergePropertyValue: fmt.Sprint(row[2]),
+			})
+		}
+		return mappings, nil
+	}
+
+	table := fmt.Sprintf("%q.%q", warehouse.Namespace, mappingsTable)
+	placeholders := strings.TrimSuffix(strings.Repeat("?,", len(values)), ",")
+	args := make([]interface{}, len(values))
+	for i, value := range values {
+		args[i] = value
+	}
+
+	rows, err := cl.SQL.QueryContext(ctx, fmt.Sprintf(`SELECT %[1]s FROM %[2]s WHERE %[3]s IN (SELECT %[3]s FROM %[2]s WHERE %[4]s IN (%[5]s));`,
+		columns, table, rudderID, mergePropertyValue, placeholders,
+	), args...)
+	if err != nil {
+		return nil, fmt.Errorf("querying identity mappings: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	var mappings []mapping
+	for rows.Next() {
+		var rudderID, mergePropertyType, mergePropertyValue sql.NullString
+		if err := rows.Scan(&rudderID, &mergePropertyType, &mergePropertyValue); err != nil {
+			return nil, fmt.Errorf("scanning identity mappings: %w", err)
+		}
+		mappings = append(mappings, mapping{
+			rudderID:           rudderID.String,
+			mergePropertyType:  mergePropertyType.String,
+			mergePropertyValue: mergePropertyValue.String,
+		})
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterating identity mappings: %w", err)
+	}
+	return mappings, nil
+}
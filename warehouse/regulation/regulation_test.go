@@ -0,0 +1,236 @@
+package regulation_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/rudderlabs/rudder-go-kit/config"
+	"github.com/rudderlabs/rudder-go-kit/logger"
+	"github.com/rudderlabs/rudder-go-kit/stats"
+	backendconfig "github.com/rudderlabs/rudder-server/backend-config"
+	"github.com/rudderlabs/rudder-server/utils/misc"
+	"github.com/rudderlabs/rudder-server/warehouse/integrations/sqlite"
+	"github.com/rudderlabs/rudder-server/warehouse/internal/model"
+	"github.com/rudderlabs/rudder-server/warehouse/jobs"
+	"github.com/rudderlabs/rudder-server/warehouse/regulation"
+	warehouseutils "github.com/rudderlabs/rudder-server/warehouse/utils"
+)
+
+func TestDeleter_Namespace(t *testing.T) {
+	misc.Init()
+	warehouseutils.Init()
+
+	// the warehouse service loaded source_loaded into loaded_namespace
+	wh := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, "/internal/v1/warehouse/fetch-tables", r.URL.Path)
+
+		var req warehouseutils.FetchTablesRequest
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+		require.Len(t, req.Connections, 1)
+
+		var res warehouseutils.FetchTablesResponse
+		switch req.Connections[0].SourceID {
+		case "source_loaded":
+			res.ConnectionsTables = []warehouseutils.FetchTableInfo{{
+				SourceID:      req.Connections[0].SourceID,
+				DestinationID: req.Connections[0].DestinationID,
+				Namespace:     "loaded_namespace",
+				Tables:        []string{"tracks"},
+			}}
+		case "source_failing":
+			http.Error(w, "can't fetch tables from schemas repo", http.StatusInternalServerError)
+			return
+		}
+		require.NoError(t, json.NewEncoder(w).Encode(res))
+	}))
+	t.Cleanup(wh.Close)
+
+	testCases := []struct {
+		name          string
+		destType      string
+		config        map[string]interface{}
+		datasetPrefix string
+		sourceID      string
+		sourceName    string
+		want          string
+		wantErr       bool
+	}{
+		{
+			name:       "loaded namespace",
+			destType:   warehouseutils.SNOWFLAKE,
+			config:     map[string]interface{}{"namespace": "test namespace"},
+			sourceID:   "source_loaded",
+			sourceName: "Test Source",
+			want:       "loaded_namespace",
+		},
+		{
+			name:       "warehouse failing",
+			destType:   warehouseutils.SNOWFLAKE,
+			config:     map[string]interface{}{"namespace": "test namespace"},
+			sourceID:   "source_failing",
+			sourceName: "Test Source",
+			wantErr:    true,
+		},
+		{
+			name:       "clickhouse database",
+			destType:   warehouseutils.CLICKHOUSE,
+			config:     map[string]interface{}{"database": "test_database"},
+			sourceName: "Test Source",
+			want:       "test_database",
+		},
+		{
+			name:       "clickhouse default database",
+			destType:   warehouseutils.CLICKHOUSE,
+			config:     map[string]interface{}{},
+			sourceName: "Test Source",
+			want:       "rudder",
+		},
+		{
+			name:       "namespace from config",
+			destType:   warehouseutils.SNOWFLAKE,
+			config:     map[string]interface{}{"namespace": "test namespace"},
+			sourceName: "Test Source",
+			want:       "TEST_NAMESPACE",
+		},
+		{
+			name:          "custom dataset prefix",
+			destType:      warehouseutils.POSTGRES,
+			config:        map[string]interface{}{},
+			datasetPrefix: "prefix",
+			sourceName:    "Test Source",
+			want:          "prefix_test_source",
+		},
+		{
+			name:       "source name",
+			destType:   warehouseutils.POSTGRES,
+			config:     map[string]interface{}{"namespace": " "},
+			sourceName: "Test Source",
+			want:       "test_source",
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+
+		t.Run(tc.name, func(t *testing.T) {
+			c := config.New()
+			c.Set("Warehouse.postgres.customDatasetPrefix", tc.datasetPrefix)
+			c.Set("WAREHOUSE_URL", wh.URL)
+
+			d := regulation.New(c, logger.NOP, stats.Default)
+			namespace, err := d.Namespace(context.Background(), backendconfig.DestinationT{
+				ID:     "test_destination_id",
+				Config: tc.config,
+				DestinationDefinition: backendconfig.DestinationDefinitionT{
+					Name: tc.destType,
+				},
+			}, backendconfig.SourceT{ID: tc.sourceID, Name: tc.sourceName})
+			if tc.wantErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tc.want, namespace)
+		})
+	}
+}
+
+func TestDeleter_Delete(t *testing.T) {
+	misc.Init()
+	warehouseutils.Init()
+
+	const (
+		workspaceID = "test_workspace_id"
+		namespace   = "test_namespace"
+	)
+
+	ctx := context.Background()
+
+	destination := backendconfig.DestinationT{
+		ID: "test_destination_id",
+		Config: map[string]interface{}{
+			"path": t.TempDir(),
+		},
+		DestinationDefinition: backendconfig.DestinationDefinitionT{
+			Name: warehouseutils.SQLITE,
+		},
+	}
+	warehouse := model.Warehouse{
+		WorkspaceID: workspaceID,
+		Destination: destination,
+		Namespace:   namespace,
+		Type:        warehouseutils.SQLITE,
+	}
+
+	dd := sqlite.New(config.Default, logger.NOP, stats.Default)
+	require.NoError(t, dd.Setup(ctx, warehouse, &jobs.WhAsyncJob{}))
+	t.Cleanup(func() { dd.Cleanup(ctx) })
+
+	require.NoError(t, dd.CreateSchema(ctx))
+	require.NoError(t, dd.CreateTable(ctx, "tracks", model.TableSchema{"id": "string", "user_id": "string", "anonymous_id": "string"}))
+	require.NoError(t, dd.CreateTable(ctx, "users", model.TableSchema{"id": "string"}))
+	require.NoError(t, dd.CreateTable(ctx, "pages", model.TableSchema{"id": "string"}))
+	require.NoError(t, dd.CreateTable(ctx, warehouseutils.IdentityMappingsTable, model.TableSchema{
+		"merge_property_type":  "string",
+		"merge_property_value": "string",
+		"rudder_id":            "string",
+	}))
+
+	cl, err := dd.Connect(ctx, warehouse)
+	require.NoError(t, err)
+	t.Cleanup(cl.Close)
+
+	for _, statement := range []string{
+		`INSERT INTO "test_namespace"."tracks" (id, user_id, anonymous_id) VALUES ('t1', 'u1', 'a1'), ('t2', NULL, 'a1'), ('t3', 'u2', 'a2'), ('t4', NULL, 'a3');`,
+		`INSERT INTO "test_namespace"."users" VALUES ('u1'), ('u2');`,
+		`INSERT INTO "test_namespace"."pages" VALUES ('u1');`,
+		`INSERT INTO "test_namespace"."rudder_identity_mappings" (merge_property_type, merge_property_value, rudder_id) VALUES ('user_id', 'u1', 'r1'), ('anonymous_id', 'a1', 'r1'), ('user_id', 'u2', 'r2'), ('anonymous_id', 'a2', 'r2');`,
+	} {
+		_, err := cl.SQL.ExecContext(ctx, statement)
+		require.NoError(t, err)
+	}
+
+	d := regulation.New(config.Default, logger.NOP, stats.Default)
+	require.NoError(t, d.Delete(ctx, regulation.Request{
+		WorkspaceID: workspaceID,
+		Destination: destination,
+		Namespace:   namespace,
+		UserIDs:     []string{"u1"},
+	}))
+
+	ids := func(t testing.TB, statement string) []string {
+		t.Helper()
+
+		rows, err := cl.SQL.QueryContext(ctx, statement)
+		require.NoError(t, err)
+		defer func() { _ = rows.Close() }()
+
+		var ids []string
+		for rows.Next() {
+			var id string
+			require.NoError(t, rows.Scan(&id))
+			ids = append(ids, id)
+		}
+		require.NoError(t, rows.Err())
+		return ids
+	}
+
+	require.Equal(t, []string{"t3", "t4"}, ids(t, `SELECT id FROM "test_namespace"."tracks" ORDER BY id;`))
+	require.Equal(t, []string{"u2"}, ids(t, `SELECT id FROM "test_namespace"."users" ORDER BY id;`))
+	require.Equal(t, []string{"u1"}, ids(t, `SELECT id FROM "test_namespace"."pages" ORDER BY id;`))
+	require.Equal(t, []string{"a2", "u2"}, ids(t, `SELECT merge_property_value FROM "test_namespace"."rudder_identity_mappings" ORDER BY merge_property_value;`))
+
+	t.Run("namespace without tables of users", func(t *testing.T) {
+		require.EqualError(t, d.Delete(ctx, regulation.Request{
+			WorkspaceID: workspaceID,
+			Destination: destination,
+			Namespace:   "other_namespace",
+			UserIDs:     []string{"u1"},
+		}), "namespace other_namespace has none of the tables of users")
+	})
+}
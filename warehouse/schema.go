@@ -6,6 +6,8 @@ import (
 	"fmt"
 	"reflect"
 	"regexp"
+	"sort"
+	"strings"
 
 	"golang.org/x/exp/slices"
 
@@ -27,6 +29,28 @@ var (
 	errSchemaConversionNotSupported = errors.New("schema conversion not supported")
 )
 
+type schemaEvolutionPolicy string
+
+const (
+	// schemaEvolutionDiscard discards the values which cannot be converted to the type of the column into rudder_discards
+	schemaEvolutionDiscard schemaEvolutionPolicy = "discard"
+	// schemaEvolutionStrict fails the upload if the staging files have values which cannot be converted to the type of the column
+	schemaEvolutionStrict schemaEvolutionPolicy = "strict"
+	// schemaEvolutionWiden promotes the type of the columns along int -> float -> string, altering the columns in the warehouse
+	schemaEvolutionWiden schemaEvolutionPolicy = "widen"
+	// schemaEvolutionQuarantine writes the rows having values which cannot be converted to the type of the column into <table>_quarantine
+	schemaEvolutionQuarantine schemaEvolutionPolicy = "quarantine"
+)
+
+// wideningRanks ranks the types which can be widened into one another, e.g. an int column can be widened into a float one
+var wideningRanks = map[model.SchemaType]int{
+	model.IntDataType:    0,
+	model.BigIntDataType: 0,
+	model.FloatDataType:  1,
+	model.StringDataType: 2,
+	model.TextDataType:   3,
+}
+
 // deprecatedColumnsRegex
 // This regex is used to identify deprecated columns in the warehouse
 // Example: abc-deprecated-dba626a7-406a-4757-b3e0-3875559c5840
@@ -57,6 +81,7 @@ type Schema struct {
 	stagingFilesSchemaPaginationSize int
 	skipDeepEqualSchemas             bool
 	enableIDResolution               bool
+	evolutionPolicy                  schemaEvolutionPolicy
 }
 
 func NewSchema(
@@ -64,14 +89,39 @@ func NewSchema(
 	warehouse model.Warehouse,
 	conf *config.Config,
 ) *Schema {
+	log := logger.NewLogger().Child("warehouse").Child("schema")
 	return &Schema{
 		warehouse:                        warehouse,
 		schemaRepo:                       repo.NewWHSchemas(db),
 		stagingFileRepo:                  repo.NewStagingFiles(db),
-		log:                              logger.NewLogger().Child("warehouse").Child("schema"),
+		log:                              log,
 		stagingFilesSchemaPaginationSize: conf.GetInt("Warehouse.stagingFilesSchemaPaginationSize", 100),
 		skipDeepEqualSchemas:             conf.GetBool("Warehouse.skipDeepEqualSchemas", false),
 		enableIDResolution:               conf.GetBool("Warehouse.enableIDResolution", false),
+		evolutionPolicy:                  schemaEvolutionPolicyFor(conf, log, warehouse.Type, warehouse.Destination.Config),
+	}
+}
+
+// schemaEvolutionPolicyFor returns the schema evolution policy of the destination, configured using the schemaEvolutionPolicy
+// setting of the destination and falling back to Warehouse.schemaEvolutionPolicy.
+// Widening is only supported for the warehouses which can alter the type of the columns, the rest fall back to discarding.
+func schemaEvolutionPolicyFor(conf *config.Config, log logger.Logger, destType string, destConfig map[string]interface{}) schemaEvolutionPolicy {
+	policy, _ := destConfig["schemaEvolutionPolicy"].(string)
+	if policy == "" {
+		policy = conf.GetString("Warehouse.schemaEvolutionPolicy", string(schemaEvolutionDiscard))
+	}
+
+	switch p := schemaEvolutionPolicy(strings.ToLower(policy)); p {
+	case schemaEvolutionStrict, schemaEvolutionQuarantine:
+		return p
+	case schemaEvolutionWiden:
+		if slices.Contains(warehouseutils.WideningEnabledWarehouses, destType) {
+			return p
+		}
+		log.Warnf("Schema evolution policy %s is not supported for %s, falling back to %s", p, destType, schemaEvolutionDiscard)
+		return schemaEvolutionDiscard
+	default:
+		return schemaEvolutionDiscard
 	}
 }
 
@@ -171,6 +221,7 @@ func (sh *Schema) prepareUploadSchema(ctx context.Context, stagingFiles []*model
 // consolidateStagingFilesSchemaUsingWarehouseSchema consolidates staging files schema with warehouse schema
 func (sh *Schema) consolidateStagingFilesSchemaUsingWarehouseSchema(ctx context.Context, stagingFiles []*model.StagingFile) (model.Schema, error) {
 	consolidatedSchema := model.Schema{}
+	stagingColumnTypes := columnTypes{}
 	batches := lo.Chunk(stagingFiles, sh.stagingFilesSchemaPaginationSize)
 	for _, batch := range batches {
 		schemas, err := sh.stagingFileRepo.GetSchemasByIDs(
@@ -182,10 +233,25 @@ func (sh *Schema) consolidateStagingFilesSchemaUsingWarehouseSchema(ctx context.
 		}
 
 		consolidatedSchema = consolidateStagingSchemas(consolidatedSchema, schemas)
+		stagingColumnTypes.collect(schemas)
 	}
 
 	consolidatedSchema = consolidateWarehouseSchema(consolidatedSchema, sh.localSchema)
+	if sh.evolutionPolicy == schemaEvolutionWiden {
+		consolidatedSchema = widenSchema(consolidatedSchema, stagingColumnTypes)
+	}
 	consolidatedSchema = overrideUsersWithIdentifiesSchema(consolidatedSchema, sh.warehouse.Type, sh.localSchema)
+
+	incompatibleColumns := stagingColumnTypes.incompatibleWith(consolidatedSchema)
+	switch sh.evolutionPolicy {
+	case schemaEvolutionStrict:
+		if len(incompatibleColumns) > 0 {
+			return model.Schema{}, fmt.Errorf("%w: %s", errIncompatibleSchemaConversion, incompatibleColumns)
+		}
+	case schemaEvolutionQuarantine:
+		consolidatedSchema = enhanceQuarantineSchema(consolidatedSchema, incompatibleColumns, sh.warehouse.Type)
+	}
+
 	consolidatedSchema = enhanceDiscardsSchema(consolidatedSchema, sh.warehouse.Type)
 	consolidatedSchema = enhanceSchemaWithIDResolution(consolidatedSchema, sh.isIDResolutionEnabled(), sh.warehouse.Type)
 	return consolidatedSchema, nil
@@ -243,6 +309,81 @@ func consolidateWarehouseSchema(consolidatedSchema, warehouseSchema model.Schema
 	return consolidatedSchema
 }
 
+// widenSchema promotes the type of the columns to the widest type seen in the staging files schemas
+func widenSchema(consolidatedSchema model.Schema, stagingColumnTypes columnTypes) model.Schema {
+	for tableName, columnMap := range consolidatedSchema {
+		for columnName, columnType := range columnMap {
+			for _, stagingColumnType := range stagingColumnTypes[tableName][columnName] {
+				if isWiderSchemaType(model.SchemaType(stagingColumnType), model.SchemaType(columnType)) {
+					columnType = stagingColumnType
+				}
+			}
+			consolidatedSchema[tableName][columnName] = columnType
+		}
+	}
+	return consolidatedSchema
+}
+
+// isWiderSchemaType reports whether the current type is a widening of the existing type
+func isWiderSchemaType(currentDataType, existingDataType model.SchemaType) bool {
+	currentRank, currentOk := wideningRanks[currentDataType]
+	existingRank, existingOk := wideningRanks[existingDataType]
+	return currentOk && existingOk && currentRank > existingRank
+}
+
+// columnTypes holds the distinct types of the columns across the staging files schemas
+type columnTypes map[string]map[string][]string
+
+func (ct columnTypes) collect(schemas []model.Schema) {
+	for _, schema := range schemas {
+		for tableName, columnMap := range schema {
+			if _, ok := ct[tableName]; !ok {
+				ct[tableName] = make(map[string][]string)
+			}
+			for columnName, columnType := range columnMap {
+				if !slices.Contains(ct[tableName][columnName], columnType) {
+					ct[tableName][columnName] = append(ct[tableName][columnName], columnType)
+				}
+			}
+		}
+	}
+}
+
+// incompatibleWith returns the columns having types which cannot be converted to the type of the column in the schema,
+// i.e. the columns for which the slaves would not be able to load some of the values.
+func (ct columnTypes) incompatibleWith(schema model.Schema) incompatibleColumns {
+	incompatible := incompatibleColumns{}
+	for tableName, columnMap := range ct {
+		for columnName, types := range columnMap {
+			existingType, ok := schema[tableName][columnName]
+			if !ok {
+				continue
+			}
+			for _, columnType := range types {
+				if !isConvertibleSchemaType(model.SchemaType(existingType), model.SchemaType(columnType)) {
+					incompatible[tableName] = append(incompatible[tableName], columnName)
+					break
+				}
+			}
+		}
+	}
+	return incompatible
+}
+
+// incompatibleColumns holds the incompatible column names, keyed by table name
+type incompatibleColumns map[string][]string
+
+func (ic incompatibleColumns) String() string {
+	var columns []string
+	for tableName, columnNames := range ic {
+		for _, columnName := range columnNames {
+			columns = append(columns, tableName+"."+columnName)
+		}
+	}
+	sort.Strings(columns)
+	return strings.Join(columns, ", ")
+}
+
 // overrideUsersWithIdentifiesSchema overrides the users table with the identifies table
 // users(id) <-> identifies(user_id)
 // Removes the user_id column from the users table
@@ -291,6 +432,29 @@ func enhanceDiscardsSchema(consolidatedSchema model.Schema, warehouseType string
 	return consolidatedSchema
 }
 
+// enhanceQuarantineSchema adds the quarantine tables for the tables having incompatible columns to the schema
+// For bq, adds the loaded_at column same as for the discards table
+func enhanceQuarantineSchema(consolidatedSchema model.Schema, incompatible incompatibleColumns, warehouseType string) model.Schema {
+	for tableName := range incompatible {
+		quarantine := model.TableSchema{}
+
+		for colName, colType := range warehouseutils.QuarantineSchema {
+			quarantine[warehouseutils.ToProviderCase(warehouseType, colName)] = colType
+		}
+
+		if warehouseType == warehouseutils.BQ {
+			quarantine[warehouseutils.ToProviderCase(warehouseType, "loaded_at")] = "datetime"
+		}
+
+		consolidatedSchema[quarantineTableName(warehouseType, tableName)] = quarantine
+	}
+	return consolidatedSchema
+}
+
+func quarantineTableName(warehouseType, tableName string) string {
+	return warehouseutils.ToProviderCase(warehouseType, tableName+warehouseutils.QuarantineTableSuffix)
+}
+
 // enhanceSchemaWithIDResolution adds the merge rules and mappings table to the schema if IDResolution is enabled
 func enhanceSchemaWithIDResolution(consolidatedSchema model.Schema, isIDResolutionEnabled bool, warehouseType string) model.Schema {
 	if !isIDResolutionEnabled {
@@ -384,11 +548,31 @@ func (sh *Schema) generateTableSchemaDiff(tableName string) warehouseutils.Table
 			diff.AlteredColumnMap[columnName] = columnType
 			diff.UpdatedSchema[columnName] = columnType
 			diff.Exists = true
+		} else if sh.evolutionPolicy == schemaEvolutionWiden && isWiderSchemaType(model.SchemaType(columnType), model.SchemaType(currentTableSchema[columnName])) {
+			diff.AlteredColumnMap[columnName] = columnType
+			diff.UpdatedSchema[columnName] = columnType
+			diff.Exists = true
 		}
 	}
 	return diff
 }
 
+// isConvertibleSchemaType reports whether handleSchemaChange is able to convert the values of the current type to the existing type
+func isConvertibleSchemaType(existingDataType, currentDataType model.SchemaType) bool {
+	switch {
+	case existingDataType == currentDataType:
+		return true
+	case existingDataType == model.StringDataType || existingDataType == model.TextDataType || existingDataType == model.JSONDataType:
+		return true
+	case (currentDataType == model.IntDataType || currentDataType == model.BigIntDataType) && existingDataType == model.FloatDataType:
+		return true
+	case currentDataType == model.FloatDataType && (existingDataType == model.IntDataType || existingDataType == model.BigIntDataType):
+		return true
+	default:
+		return false
+	}
+}
+
 // handleSchemaChange checks if the existing column type is compatible with the new column type
 func handleSchemaChange(existingDataType, currentDataType model.SchemaType, value any) (any, error) {
 	var (
@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"testing"
 
+	"github.com/rudderlabs/rudder-go-kit/config"
 	"github.com/rudderlabs/rudder-go-kit/logger"
 	backendconfig "github.com/rudderlabs/rudder-server/backend-config"
 
@@ -589,6 +590,7 @@ func TestSchema_GetUploadSchemaDiff(t *testing.T) {
 		tableName     string
 		currentSchema model.Schema
 		uploadSchema  model.Schema
+		policy        schemaEvolutionPolicy
 		expected      warehouseutils.TableSchemaDiff
 	}{
 		{
@@ -701,6 +703,59 @@ func TestSchema_GetUploadSchemaDiff(t *testing.T) {
 				},
 			},
 		},
+		{
+			name:      "widened column",
+			tableName: "test-table",
+			currentSchema: model.Schema{
+				"test-table": model.TableSchema{
+					"test-column":   "int",
+					"test-column-2": "float",
+				},
+			},
+			uploadSchema: model.Schema{
+				"test-table": model.TableSchema{
+					"test-column":   "float",
+					"test-column-2": "float",
+				},
+			},
+			policy: schemaEvolutionWiden,
+			expected: warehouseutils.TableSchemaDiff{
+				Exists:           true,
+				TableToBeCreated: false,
+				ColumnMap:        model.TableSchema{},
+				UpdatedSchema: model.TableSchema{
+					"test-column":   "float",
+					"test-column-2": "float",
+				},
+				AlteredColumnMap: model.TableSchema{
+					"test-column": "float",
+				},
+			},
+		},
+		{
+			name:      "widened column without widen policy",
+			tableName: "test-table",
+			currentSchema: model.Schema{
+				"test-table": model.TableSchema{
+					"test-column": "int",
+				},
+			},
+			uploadSchema: model.Schema{
+				"test-table": model.TableSchema{
+					"test-column": "float",
+				},
+			},
+			policy: schemaEvolutionDiscard,
+			expected: warehouseutils.TableSchemaDiff{
+				Exists:           false,
+				TableToBeCreated: false,
+				ColumnMap:        model.TableSchema{},
+				UpdatedSchema: model.TableSchema{
+					"test-column": "int",
+				},
+				AlteredColumnMap: model.TableSchema{},
+			},
+		},
 	}
 	for _, tc := range testCases {
 		tc := tc
@@ -711,6 +766,7 @@ func TestSchema_GetUploadSchemaDiff(t *testing.T) {
 			sch := Schema{
 				schemaInWarehouse: tc.currentSchema,
 				uploadSchema:      tc.uploadSchema,
+				evolutionPolicy:   tc.policy,
 			}
 			diff := sch.generateTableSchemaDiff(tc.tableName)
 			require.EqualValues(t, diff, tc.expected)
@@ -903,6 +959,7 @@ func TestSchema_PrepareUploadSchema(t *testing.T) {
 		expectedSchema      model.Schema
 		wantError           error
 		idResolutionEnabled bool
+		evolutionPolicy     schemaEvolutionPolicy
 	}{
 		{
 			name:          "error fetching staging schema",
@@ -1869,6 +1926,166 @@ func TestSchema_PrepareUploadSchema(t *testing.T) {
 				},
 			},
 		},
+		{
+			name:          "strict policy with incompatible columns",
+			warehouseType: warehouseutils.RS,
+			warehouseSchema: model.Schema{
+				"tracks": model.TableSchema{
+					"price": "int",
+				},
+			},
+			mockSchemas: []model.Schema{
+				{
+					"tracks": model.TableSchema{
+						"id":    "string",
+						"price": "boolean",
+					},
+				},
+				{
+					"pages": model.TableSchema{
+						"id":   "int",
+						"name": "string",
+					},
+				},
+				{
+					"pages": model.TableSchema{
+						"id":   "datetime",
+						"name": "int",
+					},
+				},
+			},
+			evolutionPolicy: schemaEvolutionStrict,
+			wantError:       errors.New("consolidating staging files schema: incompatible schema conversion: pages.id, tracks.price"),
+		},
+		{
+			name:          "strict policy with compatible columns",
+			warehouseType: warehouseutils.RS,
+			warehouseSchema: model.Schema{
+				"tracks": model.TableSchema{
+					"price": "float",
+					"name":  "string",
+				},
+			},
+			mockSchemas: []model.Schema{
+				{
+					"tracks": model.TableSchema{
+						"price": "int",
+						"name":  "boolean",
+					},
+				},
+			},
+			evolutionPolicy: schemaEvolutionStrict,
+			expectedSchema: model.Schema{
+				"tracks": model.TableSchema{
+					"price": "float",
+					"name":  "string",
+				},
+				"rudder_discards": model.TableSchema{
+					"column_name":  "string",
+					"column_value": "string",
+					"received_at":  "datetime",
+					"row_id":       "string",
+					"table_name":   "string",
+					"uuid_ts":      "datetime",
+				},
+			},
+		},
+		{
+			name:          "widen policy",
+			warehouseType: warehouseutils.POSTGRES,
+			warehouseSchema: model.Schema{
+				"tracks": model.TableSchema{
+					"price":    "int",
+					"quantity": "int",
+					"name":     "string",
+					"sent_at":  "datetime",
+				},
+			},
+			mockSchemas: []model.Schema{
+				{
+					"tracks": model.TableSchema{
+						"price":    "int",
+						"quantity": "int",
+						"name":     "float",
+						"sent_at":  "string",
+						"rating":   "int",
+					},
+				},
+				{
+					"tracks": model.TableSchema{
+						"price":    "float",
+						"quantity": "string",
+						"name":     "text",
+						"sent_at":  "datetime",
+						"rating":   "float",
+					},
+				},
+			},
+			evolutionPolicy: schemaEvolutionWiden,
+			expectedSchema: model.Schema{
+				"tracks": model.TableSchema{
+					"price":    "float",
+					"quantity": "string",
+					"name":     "text",
+					"sent_at":  "datetime",
+					"rating":   "float",
+				},
+				"rudder_discards": model.TableSchema{
+					"column_name":  "string",
+					"column_value": "string",
+					"received_at":  "datetime",
+					"row_id":       "string",
+					"table_name":   "string",
+					"uuid_ts":      "datetime",
+				},
+			},
+		},
+		{
+			name:          "quarantine policy",
+			warehouseType: warehouseutils.RS,
+			warehouseSchema: model.Schema{
+				"tracks": model.TableSchema{
+					"price": "int",
+				},
+			},
+			mockSchemas: []model.Schema{
+				{
+					"tracks": model.TableSchema{
+						"price": "boolean",
+					},
+					"pages": model.TableSchema{
+						"name": "string",
+					},
+				},
+			},
+			evolutionPolicy: schemaEvolutionQuarantine,
+			expectedSchema: model.Schema{
+				"tracks": model.TableSchema{
+					"price": "int",
+				},
+				"pages": model.TableSchema{
+					"name": "string",
+				},
+				"tracks_quarantine": model.TableSchema{
+					"id":           "string",
+					"event":        "string",
+					"column_name":  "string",
+					"column_type":  "string",
+					"column_value": "string",
+					"reason":       "string",
+					"received_at":  "datetime",
+					"uuid_ts":      "datetime",
+				},
+				"rudder_discards": model.TableSchema{
+					"column_name":  "string",
+					"column_value": "string",
+					"received_at":  "datetime",
+					"row_id":       "string",
+					"table_name":   "string",
+					"uuid_ts":      "datetime",
+				},
+			},
+		},
 	}
 	for _, tc := range testsCases {
 		tc := tc
@@ -1897,6 +2114,7 @@ func TestSchema_PrepareUploadSchema(t *testing.T) {
 					err:     tc.mockErr,
 				},
 				enableIDResolution:               tc.idResolutionEnabled,
+				evolutionPolicy:                  tc.evolutionPolicy,
 				localSchema:                      tc.warehouseSchema,
 				stagingFilesSchemaPaginationSize: 2,
 			}
@@ -1911,3 +2129,70 @@ func TestSchema_PrepareUploadSchema(t *testing.T) {
 		})
 	}
 }
+
+func TestSchemaEvolutionPolicyFor(t *testing.T) {
+	testCases := []struct {
+		name         string
+		destType     string
+		destConfig   map[string]interface{}
+		globalPolicy string
+		want         schemaEvolutionPolicy
+	}{
+		{
+			name:     "default",
+			destType: warehouseutils.RS,
+			want:     schemaEvolutionDiscard,
+		},
+		{
+			name:       "destination config",
+			destType:   warehouseutils.RS,
+			destConfig: map[string]interface{}{"schemaEvolutionPolicy": "strict"},
+			want:       schemaEvolutionStrict,
+		},
+		{
+			name:         "destination config over global config",
+			destType:     warehouseutils.SNOWFLAKE,
+			destConfig:   map[string]interface{}{"schemaEvolutionPolicy": "Quarantine"},
+			globalPolicy: "strict",
+			want:         schemaEvolutionQuarantine,
+		},
+		{
+			name:         "global config",
+			destType:     warehouseutils.POSTGRES,
+			globalPolicy: "widen",
+			want:         schemaEvolutionWiden,
+		},
+		{
+			name:       "widen redshift",
+			destType:   warehouseutils.RS,
+			destConfig: map[string]interface{}{"schemaEvolutionPolicy": "widen"},
+			want:       schemaEvolutionWiden,
+		},
+		{
+			name:         "widen not supported",
+			destType:     warehouseutils.BQ,
+			globalPolicy: "widen",
+			want:         schemaEvolutionDiscard,
+		},
+		{
+			name:       "unknown policy",
+			destType:   warehouseutils.RS,
+			destConfig: map[string]interface{}{"schemaEvolutionPolicy": "unknown"},
+			want:       schemaEvolutionDiscard,
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			c := config.New()
+			if tc.globalPolicy != "" {
+				c.Set("Warehouse.schemaEvolutionPolicy", tc.globalPolicy)
+			}
+			require.Equal(t, tc.want, schemaEvolutionPolicyFor(c, logger.NOP, tc.destType, tc.destConfig))
+		})
+	}
+}
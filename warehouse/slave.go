@@ -90,6 +90,7 @@ type jobRun struct {
 		numLoadFileUploadWorkers int
 		slaveUploadTimeout       time.Duration
 		loadObjectFolder         string
+		schemaEvolutionPolicy    schemaEvolutionPolicy
 	}
 }
 
@@ -109,6 +110,7 @@ func newJobRun(job Payload, conf *config.Config, log logger.Logger, stat stats.S
 		jr.config.slaveUploadTimeout = conf.GetDuration("Warehouse.slaveUploadTimeoutInMin", 10, time.Minute)
 	}
 	jr.config.loadObjectFolder = conf.GetString("WAREHOUSE_BUCKET_LOAD_OBJECTS_FOLDER_NAME", "rudder-warehouse-load-objects")
+	jr.config.schemaEvolutionPolicy = schemaEvolutionPolicyFor(conf, log, job.DestinationType, job.DestinationConfig)
 
 	jr.uploadTimeStat = jr.timerStat("load_file_upload_time")
 	jr.totalUploadTimeStat = jr.timerStat("load_file_total_upload_time")
@@ -355,11 +357,7 @@ func (jr *jobRun) GetWriter(tableName string) (encoding.LoadFileWriter, error) {
 	if !ok {
 		var err error
 		outputFilePath := jr.getLoadFilePath(tableName)
-		if jr.job.LoadFileType == warehouseutils.LoadFileTypeParquet {
-			writer, err = encoding.CreateParquetWriter(jr.job.UploadSchema[tableName], outputFilePath, jr.job.DestinationType)
-		} else {
-			writer, err = misc.CreateGZ(outputFilePath)
-		}
+		writer, err = encoding.NewLoadFileWriter(jr.job.LoadFileType, jr.job.UploadSchema[tableName], outputFilePath, jr.job.DestinationType)
 		if err != nil {
 			return nil, err
 		}
@@ -426,6 +424,53 @@ func (jr *jobRun) handleDiscardTypes(tableName, columnName string, columnVal int
 	return nil
 }
 
+// handleQuarantinedRow writes the row into the quarantine table, along with the column having the value which cannot be
+// converted to the type of the column in the upload schema and the reason for it.
+func (jr *jobRun) handleQuarantinedRow(tableName, columnName string, columnVal interface{}, columnData Data, reason error, quarantineWriter encoding.LoadFileWriter) error {
+	job := jr.job
+
+	receivedAt, ok := columnData[job.getColumnName("received_at")]
+	if !ok {
+		receivedAt = time.Now().Format(misc.RFC3339Milli)
+	}
+	event, err := json.Marshal(columnData)
+	if err != nil {
+		return fmt.Errorf("marshalling event: %w", err)
+	}
+
+	eventLoader := encoding.GetNewEventLoader(job.DestinationType, job.LoadFileType, quarantineWriter)
+	eventLoader.AddColumn("column_name", warehouseutils.QuarantineSchema["column_name"], columnName)
+	eventLoader.AddColumn("column_type", warehouseutils.QuarantineSchema["column_type"], job.UploadSchema[tableName][columnName])
+	eventLoader.AddColumn("column_value", warehouseutils.QuarantineSchema["column_value"], fmt.Sprintf("%v", columnVal))
+	eventLoader.AddColumn("event", warehouseutils.QuarantineSchema["event"], string(event))
+	eventLoader.AddColumn("id", warehouseutils.QuarantineSchema["id"], columnData[job.getColumnName("id")])
+	eventLoader.AddColumn("reason", warehouseutils.QuarantineSchema["reason"], reason.Error())
+	eventLoader.AddColumn("received_at", warehouseutils.QuarantineSchema["received_at"], receivedAt)
+	if eventLoader.IsLoadTimeColumn("uuid_ts") {
+		timestampFormat := eventLoader.GetLoadTimeFormat("uuid_ts")
+		eventLoader.AddColumn("uuid_ts", warehouseutils.QuarantineSchema["uuid_ts"], jr.uuidTS.Format(timestampFormat))
+	}
+	if eventLoader.IsLoadTimeColumn("loaded_at") {
+		timestampFormat := eventLoader.GetLoadTimeFormat("loaded_at")
+		eventLoader.AddColumn("loaded_at", "datetime", jr.uuidTS.Format(timestampFormat))
+	}
+
+	if err := eventLoader.Write(); err != nil {
+		return fmt.Errorf("writing event to quarantine table: %w", err)
+	}
+	return nil
+}
+
+// getQuarantineTable returns the quarantine table of the table, if rows are to be quarantined for it
+func (job *Payload) getQuarantineTable(tableName string, policy schemaEvolutionPolicy) (string, bool) {
+	if policy != schemaEvolutionQuarantine {
+		return "", false
+	}
+	quarantineTable := quarantineTableName(job.DestinationType, tableName)
+	_, ok := job.UploadSchema[quarantineTable]
+	return quarantineTable, ok
+}
+
 func (job *Payload) getDiscardsTable() string {
 	return warehouseutils.ToProviderCase(job.DestinationType, warehouseutils.DiscardsTable)
 }
@@ -641,6 +686,11 @@ func processStagingFile(ctx context.Context, job Payload, workerIndex int) (load
 		tableName := batchRouterEvent.Metadata.Table
 		columnData := batchRouterEvent.Data
 
+		// uploads restricted to a subset of tables, e.g. backfills, only have those tables in the upload schema
+		if _, ok := job.UploadSchema[tableName]; !ok {
+			continue
+		}
+
 		if job.DestinationType == warehouseutils.S3Datalake && len(sortedTableColumnMap[tableName]) > columnCountLimitMap[warehouseutils.S3Datalake] {
 			jr.logger.Errorf("[WH]: Huge staging file columns : columns in upload schema: %v for StagingFileID: %v", len(sortedTableColumnMap[tableName]), job.StagingFileID)
 			return nil, fmt.Errorf("staging file schema limit exceeded for stagingFileID: %d, actualCount: %d", job.StagingFileID, len(sortedTableColumnMap[tableName]))
@@ -652,6 +702,8 @@ func processStagingFile(ctx context.Context, job Payload, workerIndex int) (load
 			return nil, err
 		}
 
+		var quarantined bool
+
 		eventLoader := encoding.GetNewEventLoader(job.DestinationType, job.LoadFileType, writer)
 		for _, columnName := range sortedTableColumnMap[tableName] {
 			if eventLoader.IsLoadTimeColumn(columnName) {
@@ -711,6 +763,20 @@ func processStagingFile(ctx context.Context, job Payload, workerIndex int) (load
 					model.SchemaType(columnType),
 					columnVal,
 				)
+				if quarantineTable, ok := job.getQuarantineTable(tableName, jr.config.schemaEvolutionPolicy); ok && convError != nil && !violatedConstraints.IsViolated {
+					quarantineWriter, err := jr.GetWriter(quarantineTable)
+					if err != nil {
+						return nil, err
+					}
+
+					err = jr.handleQuarantinedRow(tableName, columnName, columnVal, columnData, convError, quarantineWriter)
+					if err != nil {
+						return nil, err
+					}
+					jr.tableEventCountMap[quarantineTable]++
+					quarantined = true
+					break
+				}
 				if convError != nil || violatedConstraints.IsViolated {
 					if violatedConstraints.IsViolated {
 						eventLoader.AddColumn(columnName, job.UploadSchema[tableName][columnName], violatedConstraints.ViolatedIdentifier)
@@ -751,6 +817,11 @@ func processStagingFile(ctx context.Context, job Payload, workerIndex int) (load
 			eventLoader.AddColumn(columnName, job.UploadSchema[tableName][columnName], columnVal)
 		}
 
+		// The whole row has been written to the quarantine table instead
+		if quarantined {
+			continue
+		}
+
 		// Completed parsing all columns, write single event to the file
 		err = eventLoader.Write()
 		if err != nil {
@@ -19,6 +19,7 @@ import (
 	"github.com/rudderlabs/rudder-go-kit/stats/memstats"
 	"github.com/rudderlabs/rudder-server/testhelper/destination"
 	"github.com/rudderlabs/rudder-server/warehouse/encoding"
+	"github.com/rudderlabs/rudder-server/warehouse/internal/model"
 	warehouseutils "github.com/rudderlabs/rudder-server/warehouse/utils"
 
 	"github.com/stretchr/testify/require"
@@ -252,3 +253,64 @@ func TestUploadLoadFilesToObjectStorage(t *testing.T) {
 		})
 	}
 }
+
+type recordingLoadFileWriter struct {
+	mockLoadFileWriter
+	data []string
+}
+
+func (w *recordingLoadFileWriter) WriteGZ(s string) error {
+	w.data = append(w.data, s)
+	return nil
+}
+
+func TestHandleQuarantinedRow(t *testing.T) {
+	uuidTS := time.Date(2023, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	jr := jobRun{
+		job: Payload{
+			DestinationType: warehouseutils.POSTGRES,
+			LoadFileType:    warehouseutils.LoadFileTypeCsv,
+			UploadSchema: model.Schema{
+				"tracks": model.TableSchema{
+					"id":          "string",
+					"price":       "int",
+					"received_at": "datetime",
+				},
+			},
+		},
+		uuidTS: uuidTS,
+	}
+
+	w := &recordingLoadFileWriter{}
+	err := jr.handleQuarantinedRow("tracks", "price", true, Data{
+		"id":          "test-id",
+		"price":       true,
+		"received_at": "2023-01-01T00:00:00.000Z",
+	}, errSchemaConversionNotSupported, w)
+	require.NoError(t, err)
+	require.Equal(t, []string{
+		`price,int,true,"{""id"":""test-id"",""price"":true,""received_at"":""2023-01-01T00:00:00.000Z""}",test-id,schema conversion not supported,2023-01-01T00:00:00.000Z,2023-01-02T03:04:05.000Z` + "\n",
+	}, w.data)
+}
+
+func TestPayload_GetQuarantineTable(t *testing.T) {
+	job := Payload{
+		DestinationType: warehouseutils.SNOWFLAKE,
+		UploadSchema: model.Schema{
+			"TRACKS":            model.TableSchema{},
+			"PAGES":             model.TableSchema{},
+			"TRACKS_QUARANTINE": model.TableSchema{},
+		},
+	}
+
+	quarantineTable, ok := job.getQuarantineTable("TRACKS", schemaEvolutionQuarantine)
+	require.True(t, ok)
+	require.Equal(t, "TRACKS_QUARANTINE", quarantineTable)
+
+	_, ok = job.getQuarantineTable("PAGES", schemaEvolutionQuarantine)
+	require.False(t, ok, "no incompatible columns")
+
+	_, ok = job.getQuarantineTable("TRACKS", schemaEvolutionDiscard)
+	require.False(t, ok, "discard policy")
+}
@@ -10,6 +10,7 @@ import (
 	"github.com/rudderlabs/rudder-go-kit/config"
 	"github.com/rudderlabs/rudder-go-kit/stats"
 	"github.com/rudderlabs/rudder-server/utils/misc"
+	"github.com/rudderlabs/rudder-server/warehouse/internal/model"
 	"github.com/rudderlabs/rudder-server/warehouse/internal/repo"
 	"github.com/rudderlabs/rudder-server/warehouse/logfield"
 	warehouseutils "github.com/rudderlabs/rudder-server/warehouse/utils"
@@ -52,6 +53,16 @@ func (job *UploadJob) guageStat(name string, extraTags ...warehouseutils.Tag) st
 	return job.stats.NewTaggedStat(name, stats.GaugeType, job.buildTags(extraTags...))
 }
 
+// recordTableLoadCost records the cost of the queries run for loading the table
+func (job *UploadJob) recordTableLoadCost(tableName string, cost model.LoadCost) {
+	tableTag := warehouseutils.Tag{Name: "tableName", Value: strings.ToLower(tableName)}
+
+	job.counterStat("load_table_queries", tableTag).Count(int(cost.Queries))
+	job.counterStat("load_table_bytes_scanned", tableTag).Count(int(cost.BytesScanned))
+	job.counterStat("load_table_bytes_billed", tableTag).Count(int(cost.BytesBilled))
+	job.stats.NewTaggedStat("load_table_credits", stats.HistogramType, job.buildTags(tableTag)).Observe(cost.Credits)
+}
+
 func (job *UploadJob) generateUploadSuccessMetrics() {
 	var (
 		numUploadedEvents int64
@@ -4,6 +4,7 @@ import (
 	"context"
 	"database/sql"
 	"fmt"
+	"sort"
 	"strconv"
 	"time"
 
@@ -13,8 +14,10 @@ import (
 
 	"github.com/rudderlabs/rudder-go-kit/config"
 	"github.com/rudderlabs/rudder-go-kit/stats"
+	"github.com/rudderlabs/rudder-server/services/alerta"
 	"github.com/rudderlabs/rudder-server/utils/misc"
 	"github.com/rudderlabs/rudder-server/warehouse/internal/model"
+	"github.com/rudderlabs/rudder-server/warehouse/internal/repo"
 	warehouseutils "github.com/rudderlabs/rudder-server/warehouse/utils"
 )
 
@@ -35,6 +38,14 @@ func (wh *HandleT) CronTracker(ctx context.Context) error {
 					err,
 				)
 			}
+			if err := wh.TrackFreshness(ctx, &warehouse); err != nil {
+				return fmt.Errorf(
+					"cron freshness tracker failed for source: %s, destination: %s with error: %w",
+					warehouse.Source.ID,
+					warehouse.Destination.ID,
+					err,
+				)
+			}
 		}
 
 		select {
@@ -183,3 +194,143 @@ func (wh *HandleT) Track(ctx context.Context, warehouse *model.Warehouse, config
 
 	return nil
 }
+
+// TrackFreshness tracks the freshness of the tables against the SLAs configured for the warehouse.
+// An alert is raised when a table breaches its SLA and resolved once the table is fresh again.
+func (wh *HandleT) TrackFreshness(ctx context.Context, warehouse *model.Warehouse) error {
+	var (
+		Now         = timeutil.Now
+		source      = warehouse.Source
+		destination = warehouse.Destination
+	)
+
+	if wh.Now != nil {
+		Now = wh.Now
+	}
+
+	if !source.Enabled || !destination.Enabled {
+		return nil
+	}
+
+	tablesFreshness, err := getTablesFreshness(ctx, repo.NewTableUploads(wh.dbHandle), *warehouse, Now())
+	if err != nil {
+		return fmt.Errorf("fetching tables freshness for source: %s and destination: %s: %w", source.ID, destination.ID, err)
+	}
+
+	for _, tableFreshness := range tablesFreshness {
+		if tableFreshness.LastEventAt == nil {
+			continue
+		}
+
+		tags := stats.Tags{
+			"workspaceId": warehouse.WorkspaceID,
+			"module":      moduleName,
+			"destType":    wh.destType,
+			"warehouseID": misc.GetTagName(
+				destination.ID,
+				source.Name,
+				destination.Name,
+				misc.TailTruncateStr(source.ID, 6)),
+			"tableName": tableFreshness.TableName,
+		}
+		wh.stats.NewTaggedStat("warehouse_table_freshness_seconds", stats.GaugeType, tags).Gauge(tableFreshness.StalenessInSeconds)
+
+		breached := 0
+		if tableFreshness.Breached {
+			breached = 1
+		}
+		wh.stats.NewTaggedStat("warehouse_table_freshness_sla_breached", stats.GaugeType, tags).Gauge(breached)
+
+		if err := wh.alertFreshness(ctx, warehouse, tableFreshness); err != nil {
+			wh.Logger.Warnw("sending table freshness alert",
+				logfield.SourceID, source.ID,
+				logfield.DestinationID, destination.ID,
+				logfield.WorkspaceID, warehouse.WorkspaceID,
+				logfield.TableName, tableFreshness.TableName,
+				logfield.Error, err.Error(),
+			)
+		}
+	}
+	return nil
+}
+
+// alertFreshness sends an alert when the table starts or stops breaching its SLA
+func (wh *HandleT) alertFreshness(ctx context.Context, warehouse *model.Warehouse, tableFreshness warehouseutils.TableFreshness) error {
+	key := warehouse.Source.ID + ":" + warehouse.Destination.ID + ":" + tableFreshness.TableName
+
+	if wh.freshnessBreaches == nil {
+		wh.freshnessBreaches = make(map[string]bool)
+	}
+	if wh.freshnessBreaches[key] == tableFreshness.Breached {
+		return nil
+	}
+
+	severity := alerta.SeverityOk
+	if tableFreshness.Breached {
+		wh.Logger.Warnw("table freshness SLA breached",
+			logfield.SourceID, warehouse.Source.ID,
+			logfield.DestinationID, warehouse.Destination.ID,
+			logfield.WorkspaceID, warehouse.WorkspaceID,
+			logfield.TableName, tableFreshness.TableName,
+		)
+		severity = alerta.SeverityCritical
+	}
+
+	err := wh.alertSender.SendAlert(ctx, "warehouse-table-freshness",
+		alerta.SendAlertOpts{
+			Severity:    severity,
+			Priority:    alerta.PriorityP2,
+			Environment: alerta.PROXYMODE,
+			Tags: alerta.Tags{
+				"sourceID":    warehouse.Source.ID,
+				"destID":      warehouse.Destination.ID,
+				"destType":    warehouse.Type,
+				"workspaceID": warehouse.WorkspaceID,
+				"tableName":   tableFreshness.TableName,
+			},
+		},
+	)
+	if err != nil {
+		return err
+	}
+
+	wh.freshnessBreaches[key] = tableFreshness.Breached
+	return nil
+}
+
+// getTablesFreshness returns the freshness of the tables having SLAs configured for the warehouse, sorted by the table name.
+// The staleness of a table is the time since the last event of the uploads which exported it.
+func getTablesFreshness(ctx context.Context, tableUploads *repo.TableUploads, warehouse model.Warehouse, now time.Time) ([]warehouseutils.TableFreshness, error) {
+	slas := warehouseutils.GetFreshnessSLAs(warehouse)
+	if len(slas) == 0 {
+		return []warehouseutils.TableFreshness{}, nil
+	}
+
+	tableNames := make([]string, 0, len(slas))
+	for tableName := range slas {
+		tableNames = append(tableNames, tableName)
+	}
+	sort.Strings(tableNames)
+
+	lastEventAtByTable, err := tableUploads.LastExportedEventAt(ctx, warehouse.Source.ID, warehouse.Destination.ID, tableNames)
+	if err != nil {
+		return nil, err
+	}
+
+	tablesFreshness := make([]warehouseutils.TableFreshness, 0, len(tableNames))
+	for _, tableName := range tableNames {
+		tableFreshness := warehouseutils.TableFreshness{
+			TableName:    tableName,
+			SLAInSeconds: int64(slas[tableName] / time.Second),
+		}
+		if lastEventAt, ok := lastEventAtByTable[tableName]; ok {
+			staleness := now.Sub(lastEventAt)
+
+			tableFreshness.LastEventAt = &lastEventAt
+			tableFreshness.StalenessInSeconds = int64(staleness / time.Second)
+			tableFreshness.Breached = staleness > slas[tableName]
+		}
+		tablesFreshness = append(tablesFreshness, tableFreshness)
+	}
+	return tablesFreshness, nil
+}
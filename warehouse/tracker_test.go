@@ -9,6 +9,7 @@ import (
 
 	"github.com/rudderlabs/rudder-server/warehouse/integrations/middleware/sqlquerywrapper"
 	"github.com/rudderlabs/rudder-server/warehouse/internal/model"
+	"github.com/rudderlabs/rudder-server/warehouse/internal/repo"
 
 	"github.com/golang/mock/gomock"
 	"github.com/ory/dockertest/v3"
@@ -21,6 +22,7 @@ import (
 	"github.com/rudderlabs/rudder-go-kit/testhelper/docker/resource"
 	backendconfig "github.com/rudderlabs/rudder-server/backend-config"
 	mock_logger "github.com/rudderlabs/rudder-server/mocks/utils/logger"
+	"github.com/rudderlabs/rudder-server/services/alerta"
 	migrator "github.com/rudderlabs/rudder-server/services/sql-migrator"
 	"github.com/rudderlabs/rudder-server/utils/misc"
 	warehouseutils "github.com/rudderlabs/rudder-server/warehouse/utils"
@@ -277,3 +279,146 @@ func TestHandleT_CronTracker(t *testing.T) {
 		require.EqualError(t, err, errors.New("cron tracker failed for source: test-sourceID, destination: test-destinationID with error: fetching last upload time for source: test-sourceID and destination: test-destinationID: pq: column \"abc\" does not exist").Error())
 	})
 }
+
+type mockSeverityAlertSender struct {
+	severities []alerta.Severity
+}
+
+func (m *mockSeverityAlertSender) SendAlert(_ context.Context, _ string, opts alerta.SendAlertOpts) error {
+	m.severities = append(m.severities, opts.Severity)
+	return nil
+}
+
+func TestHandleT_TrackFreshness(t *testing.T) {
+	var (
+		workspaceID = "test-workspaceID"
+		sourceID    = "test-sourceID"
+		sourceName  = "test-sourceName"
+		destID      = "test-destinationID"
+		destName    = "test-destinationName"
+		destType    = warehouseutils.POSTGRES
+	)
+
+	pool, err := dockertest.NewPool("")
+	require.NoError(t, err)
+
+	pgResource, err := resource.SetupPostgres(pool, t)
+	require.NoError(t, err)
+
+	t.Log("db:", pgResource.DBDsn)
+
+	err = (&migrator.Migrator{
+		Handle:          pgResource.DB,
+		MigrationsTable: "wh_schema_migrations",
+	}).Migrate("warehouse")
+	require.NoError(t, err)
+
+	ctx := context.Background()
+	db := sqlquerywrapper.New(pgResource.DB)
+	now := time.Date(2022, 12, 6, 15, 40, 0, 0, time.UTC)
+
+	stagingID, err := repo.NewStagingFiles(db).Insert(ctx, &model.StagingFileWithSchema{
+		StagingFile: model.StagingFile{
+			SourceID:      sourceID,
+			DestinationID: destID,
+		},
+		Schema: []byte(`{}`),
+	})
+	require.NoError(t, err)
+
+	uploadID, err := repo.NewUploads(db).CreateWithStagingFiles(ctx, model.Upload{
+		WorkspaceID:     workspaceID,
+		SourceID:        sourceID,
+		DestinationID:   destID,
+		DestinationType: destType,
+		Status:          model.ExportedData,
+	}, []*model.StagingFile{{ID: stagingID, LastEventAt: now.Add(-3 * time.Hour)}})
+	require.NoError(t, err)
+
+	tableUploads := repo.NewTableUploads(db)
+	require.NoError(t, tableUploads.Insert(ctx, uploadID, []string{"tracks", "pages"}))
+	status := model.TableUploadExported
+	for _, tableName := range []string{"tracks", "pages"} {
+		require.NoError(t, tableUploads.Set(ctx, uploadID, tableName, repo.TableUploadSetOptions{
+			Status: &status,
+		}))
+	}
+
+	warehouse := model.Warehouse{
+		WorkspaceID: workspaceID,
+		Type:        destType,
+		Source: backendconfig.SourceT{
+			ID:      sourceID,
+			Name:    sourceName,
+			Enabled: true,
+		},
+		Destination: backendconfig.DestinationT{
+			ID:      destID,
+			Name:    destName,
+			Enabled: true,
+			Config: map[string]any{
+				"freshnessSLAs": []any{
+					map[string]any{"table": "tracks", "maxStaleness": "2h"},
+					map[string]any{"table": "pages", "maxStaleness": "4h"},
+					map[string]any{"table": "identifies", "maxStaleness": "1h"},
+				},
+			},
+		},
+	}
+
+	tablesFreshness, err := getTablesFreshness(ctx, tableUploads, warehouse, now)
+	require.NoError(t, err)
+
+	lastEventAt := now.Add(-3 * time.Hour)
+	require.Equal(t, []warehouseutils.TableFreshness{
+		{TableName: "identifies", SLAInSeconds: 3600},
+		{TableName: "pages", SLAInSeconds: 14400, LastEventAt: &lastEventAt, StalenessInSeconds: 10800},
+		{TableName: "tracks", SLAInSeconds: 7200, LastEventAt: &lastEventAt, StalenessInSeconds: 10800, Breached: true},
+	}, tablesFreshness)
+
+	store := memstats.New()
+	alertSender := &mockSeverityAlertSender{}
+
+	wh := HandleT{
+		destType: destType,
+		Now: func() time.Time {
+			return now
+		},
+		stats:       store,
+		dbHandle:    db,
+		Logger:      logger.NOP,
+		alertSender: alertSender,
+	}
+
+	tags := func(tableName string) stats.Tags {
+		return stats.Tags{
+			"module":      moduleName,
+			"workspaceId": warehouse.WorkspaceID,
+			"destType":    wh.destType,
+			"warehouseID": misc.GetTagName(
+				warehouse.Destination.ID,
+				warehouse.Source.Name,
+				warehouse.Destination.Name,
+				misc.TailTruncateStr(warehouse.Source.ID, 6)),
+			"tableName": tableName,
+		}
+	}
+
+	t.Log("breached table is alerted once")
+	require.NoError(t, wh.TrackFreshness(ctx, &warehouse))
+	require.NoError(t, wh.TrackFreshness(ctx, &warehouse))
+	require.Equal(t, []alerta.Severity{alerta.SeverityCritical}, alertSender.severities)
+
+	require.EqualValues(t, 10800, store.Get("warehouse_table_freshness_seconds", tags("tracks")).LastValue())
+	require.EqualValues(t, 1, store.Get("warehouse_table_freshness_sla_breached", tags("tracks")).LastValue())
+	require.EqualValues(t, 0, store.Get("warehouse_table_freshness_sla_breached", tags("pages")).LastValue())
+	require.Nil(t, store.Get("warehouse_table_freshness_seconds", tags("identifies")))
+
+	t.Log("alert is resolved once the table is fresh again")
+	wh.Now = func() time.Time {
+		return now.Add(-2 * time.Hour)
+	}
+	require.NoError(t, wh.TrackFreshness(ctx, &warehouse))
+	require.Equal(t, []alerta.Severity{alerta.SeverityCritical, alerta.SeverityOk}, alertSender.severities)
+	require.EqualValues(t, 0, store.Get("warehouse_table_freshness_sla_breached", tags("tracks")).LastValue())
+}
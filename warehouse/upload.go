@@ -102,6 +102,11 @@ type UploadJob struct {
 	minRetryAttempts          int
 	DisableAlter              bool
 
+	// planMode runs the upload up to the generation of load files without recording its progress,
+	// stopping before the first state that modifies the warehouse and recording the plan instead
+	planMode   bool
+	uploadPlan model.UploadPlan
+
 	errorHandler ErrorHandler
 }
 
@@ -127,6 +132,8 @@ const (
 
 var (
 	alwaysMarkExported                               = []string{warehouseutils.DiscardsTable}
+	warehouseModifyingStates                         = []string{model.CreatedRemoteSchema, model.ExportedData}
+	tableUploadsStates                               = []string{model.CreatedTableUploads, model.UpdatedTableUploadsCounts}
 	warehousesToAlwaysRegenerateAllLoadFilesOnResume = []string{warehouseutils.SNOWFLAKE, warehouseutils.BQ}
 )
 
@@ -155,6 +162,7 @@ func setMaxParallelLoads() {
 		warehouseutils.S3Datalake:    config.GetInt("Warehouse.s3_datalake.maxParallelLoads", 8),
 		warehouseutils.GCSDatalake:   config.GetInt("Warehouse.gcs_datalake.maxParallelLoads", 8),
 		warehouseutils.AzureDatalake: config.GetInt("Warehouse.azure_datalake.maxParallelLoads", 8),
+		warehouseutils.SQLITE:        config.GetInt("Warehouse.sqlite.maxParallelLoads", 1),
 	}
 	columnCountLimitMap = map[string]int{
 		warehouseutils.AzureSynapse: config.GetInt("Warehouse.azure_synapse.columnCountLimit", 1024),
@@ -164,6 +172,7 @@ func setMaxParallelLoads() {
 		warehouseutils.POSTGRES:     config.GetInt("Warehouse.postgres.columnCountLimit", 1600),
 		warehouseutils.RS:           config.GetInt("Warehouse.redshift.columnCountLimit", 1600),
 		warehouseutils.S3Datalake:   config.GetInt("Warehouse.s3_datalake.columnCountLimit", 10000),
+		warehouseutils.SQLITE:       config.GetInt("Warehouse.sqlite.columnCountLimit", 2000),
 	}
 }
 
@@ -252,6 +261,11 @@ func (job *UploadJob) generateUploadSchema() error {
 		return fmt.Errorf("consolidate staging files schema using warehouse schema: %w", err)
 	}
 
+	// uploads restricted to a subset of tables, e.g. backfills, only load those tables
+	if len(job.upload.Tables) > 0 {
+		job.schemaHandle.uploadSchema = restrictSchemaToTables(job.schemaHandle.uploadSchema, job.warehouse.Type, job.upload.Tables)
+	}
+
 	if err := job.setUploadSchema(job.schemaHandle.uploadSchema); err != nil {
 		return fmt.Errorf("set upload schema: %w", err)
 	}
@@ -259,6 +273,18 @@ func (job *UploadJob) generateUploadSchema() error {
 	return nil
 }
 
+// restrictSchemaToTables returns the schema of the tables, along with the discards and the quarantined rows of those tables
+func restrictSchemaToTables(schema model.Schema, warehouseType string, tables []string) model.Schema {
+	tableNames := []string{warehouseutils.ToProviderCase(warehouseType, warehouseutils.DiscardsTable)}
+	for _, tableName := range tables {
+		tableNames = append(tableNames,
+			warehouseutils.ToProviderCase(warehouseType, tableName),
+			quarantineTableName(warehouseType, tableName),
+		)
+	}
+	return lo.PickByKeys(schema, tableNames)
+}
+
 func (job *UploadJob) initTableUploads() error {
 	schemaForUpload := job.upload.UploadSchema
 	destType := job.warehouse.Type
@@ -289,7 +315,7 @@ func (job *UploadJob) syncRemoteSchema() (bool, error) {
 	}
 
 	schemaChanged := job.schemaHandle.hasSchemaChanged()
-	if schemaChanged {
+	if schemaChanged && !job.planMode {
 		pkgLogger.Infow("schema changed",
 			logfield.SourceID, job.warehouse.Source.ID,
 			logfield.SourceType, job.warehouse.Source.SourceDefinition.Name,
@@ -343,6 +369,60 @@ func (job *UploadJob) getTotalRowsInLoadFiles(ctx context.Context) int64 {
 	return total.Int64
 }
 
+func (job *UploadJob) getTotalRowsInLoadFilesByTable(ctx context.Context) (map[string]int64, error) {
+	sqlStatement := fmt.Sprintf(`
+		WITH row_numbered_load_files as (
+		  SELECT
+			total_events,
+			table_name,
+			row_number() OVER (
+			  PARTITION BY staging_file_id,
+			  table_name
+			  ORDER BY
+				id DESC
+			) AS row_number
+		  FROM
+			%[1]s
+		  WHERE
+			staging_file_id IN (%[2]v)
+		)
+		SELECT
+		  table_name,
+		  SUM(total_events)
+		FROM
+		  row_numbered_load_files
+		WHERE
+		  row_number = 1
+		GROUP BY
+		  table_name;
+	`,
+		warehouseutils.WarehouseLoadFilesTable,
+		misc.IntArrayToString(job.stagingFileIDs, ","),
+	)
+
+	rows, err := wrappedDBHandle.QueryContext(ctx, sqlStatement)
+	if err != nil {
+		return nil, fmt.Errorf("querying load files: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	rowsByTable := make(map[string]int64)
+	for rows.Next() {
+		var (
+			tableName string
+			total     sql.NullInt64
+		)
+		if err := rows.Scan(&tableName, &total); err != nil {
+			return nil, fmt.Errorf("scanning load files: %w", err)
+		}
+		rowsByTable[tableName] = total.Int64
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterating load files: %w", err)
+	}
+	return rowsByTable, nil
+}
+
 func (job *UploadJob) matchRowsInStagingAndLoadFiles(ctx context.Context) error {
 	rowsInStagingFiles, err := repo.NewStagingFiles(wrappedDBHandle).TotalEventsForUpload(ctx, job.upload)
 	if err != nil {
@@ -388,9 +468,11 @@ func (job *UploadJob) run() (err error) {
 	}
 	defer whManager.Cleanup(job.ctx)
 
-	if err = job.recovery.Recover(job.ctx, whManager, job.warehouse); err != nil {
-		_, _ = job.setUploadError(err, InternalProcessingFailed)
-		return err
+	if !job.planMode {
+		if err = job.recovery.Recover(job.ctx, whManager, job.warehouse); err != nil {
+			_, _ = job.setUploadError(err, InternalProcessingFailed)
+			return err
+		}
 	}
 
 	hasSchemaChanged, err := job.syncRemoteSchema()
@@ -412,8 +494,9 @@ func (job *UploadJob) run() (err error) {
 		nextUploadState *uploadState
 	)
 
-	// do not set nextUploadState if hasSchemaChanged to make it start from 1st step again
-	if !hasSchemaChanged {
+	// do not set nextUploadState if hasSchemaChanged to make it start from 1st step again,
+	// plans always start from the 1st step since nothing was recorded by the previous ones
+	if !hasSchemaChanged && !job.planMode {
 		nextUploadState = getNextUploadState(job.upload.Status)
 	}
 	if nextUploadState == nil {
@@ -424,6 +507,17 @@ func (job *UploadJob) run() (err error) {
 		stateStartTime := job.now()
 		err = nil
 
+		if job.planMode {
+			if slices.Contains(warehouseModifyingStates, nextUploadState.completed) {
+				return job.generateUploadPlan()
+			}
+			// table uploads are only tracked for the uploads actually loading the tables
+			if slices.Contains(tableUploadsStates, nextUploadState.completed) {
+				nextUploadState = nextUploadState.nextState
+				continue
+			}
+		}
+
 		_ = job.setUploadStatus(UploadStatusOpts{Status: nextUploadState.inProgress})
 		pkgLogger.Debugf("[WH] Upload: %d, Current state: %s", job.upload.ID, nextUploadState.inProgress)
 
@@ -449,7 +543,8 @@ func (job *UploadJob) run() (err error) {
 		case model.GeneratedLoadFiles:
 			newStatus = nextUploadState.failed
 			// generate load files for all staging files(including succeeded) if hasSchemaChanged or if its snowflake(to have all load files in same folder in bucket) or set via toml/env
-			generateAll := hasSchemaChanged || slices.Contains(warehousesToAlwaysRegenerateAllLoadFilesOnResume, job.warehouse.Type) || config.GetBool("Warehouse.alwaysRegenerateAllLoadFiles", true)
+			// plans reuse the load files already generated for the staging files, if any
+			generateAll := !job.planMode && (hasSchemaChanged || slices.Contains(warehousesToAlwaysRegenerateAllLoadFilesOnResume, job.warehouse.Type) || config.GetBool("Warehouse.alwaysRegenerateAllLoadFiles", true))
 			var startLoadFileID, endLoadFileID int64
 			if generateAll {
 				startLoadFileID, endLoadFileID, err = job.loadfile.ForceCreateLoadFiles(job.ctx, job.DTO())
@@ -653,6 +748,55 @@ func (job *UploadJob) run() (err error) {
 	return nil
 }
 
+// plan runs the upload in plan mode and returns the changes it would apply to the warehouse along with the number
+// of rows it would load into each table, without creating the schema, creating or altering tables or loading any data.
+// Load files are generated for counting the rows, while neither the progress of the upload nor its table uploads are recorded.
+func (job *UploadJob) plan() (model.UploadPlan, error) {
+	job.planMode = true
+
+	if err := job.run(); err != nil {
+		return model.UploadPlan{}, err
+	}
+	return job.uploadPlan, nil
+}
+
+func (job *UploadJob) generateUploadPlan() error {
+	rowsByTable, err := job.getTotalRowsInLoadFilesByTable(job.ctx)
+	if err != nil {
+		return fmt.Errorf("total rows in load files by table: %w", err)
+	}
+
+	job.uploadPlan = job.uploadPlanFor(rowsByTable)
+	return nil
+}
+
+// uploadPlanFor returns the plan for the schema of the upload, diffed with the schema in the warehouse
+func (job *UploadJob) uploadPlanFor(rowsByTable map[string]int64) model.UploadPlan {
+	tableNames := lo.Keys(job.upload.UploadSchema)
+	slices.Sort(tableNames)
+
+	plan := model.UploadPlan{
+		UploadID: job.upload.ID,
+		Tables:   make([]model.TablePlan, 0, len(tableNames)),
+	}
+	for _, tableName := range tableNames {
+		tableSchemaDiff := job.schemaHandle.generateTableSchemaDiff(tableName)
+
+		tablePlan := model.TablePlan{
+			Name:           tableName,
+			CreateTable:    tableSchemaDiff.TableToBeCreated,
+			AddedColumns:   tableSchemaDiff.ColumnMap,
+			AlteredColumns: model.TableSchema{},
+			TotalRows:      rowsByTable[tableName],
+		}
+		if !job.DisableAlter {
+			tablePlan.AlteredColumns = tableSchemaDiff.AlteredColumnMap
+		}
+		plan.Tables = append(plan.Tables, tablePlan)
+	}
+	return plan
+}
+
 func (job *UploadJob) exportUserTables(loadFilesTableMap map[tableNameT]bool) (err error) {
 	uploadSchema := job.upload.UploadSchema
 	if _, ok := uploadSchema[job.identifiesTableName()]; ok {
@@ -1103,20 +1247,52 @@ func (job *UploadJob) loadTable(tName string) (bool, error) {
 		job.guageStat(`post_load_table_rows`, warehouseutils.Tag{Name: "tableName", Value: strings.ToLower(tName)}).Gauge(int(totalAfterLoad))
 	}()
 
+	cost := job.loadTableCost(tName)
+
 	status = model.TableUploadExported
 	_ = job.tableUploadsRepo.Set(job.ctx, job.upload.ID, tName, repo.TableUploadSetOptions{
 		Status: &status,
+		Cost:   cost,
 	})
 	tableUpload, queryErr := job.tableUploadsRepo.GetByUploadIDAndTableName(job.ctx, job.upload.ID, tName)
 	if queryErr == nil {
 		job.recordTableLoad(tName, tableUpload.TotalEvents)
 	}
+	if cost != nil {
+		job.recordTableLoadCost(tName, *cost)
+	}
 
 	job.columnCountStat(tName)
 
 	return alteredSchema, nil
 }
 
+// loadTableCost returns the cost of the queries run for loading the table, for the warehouses which can report it
+func (job *UploadJob) loadTableCost(tableName string) *model.LoadCost {
+	reporter, ok := job.whManager.(manager.LoadCostReporter)
+	if !ok {
+		return nil
+	}
+
+	ctx, cancel := context.WithTimeout(job.ctx, loadTableCostTimeout)
+	defer cancel()
+
+	cost, err := reporter.LoadTableCost(ctx, tableName)
+	if err != nil {
+		pkgLogger.Warnw("getting load table cost",
+			logfield.UploadJobID, job.upload.ID,
+			logfield.SourceID, job.upload.SourceID,
+			logfield.DestinationID, job.upload.DestinationID,
+			logfield.DestinationType, job.upload.DestinationType,
+			logfield.WorkspaceID, job.upload.WorkspaceID,
+			logfield.TableName, tableName,
+			logfield.Error, err,
+		)
+		return nil
+	}
+	return &cost
+}
+
 // columnCountStat sent the column count for a table to statsd
 // skip sending for S3_DATALAKE, GCS_DATALAKE, AZURE_DATALAKE
 func (job *UploadJob) columnCountStat(tableName string) {
@@ -1341,9 +1517,12 @@ func (job *UploadJob) processLoadTableResponse(errorMap map[string]error) (error
 				Error:  &errorsString,
 			})
 		} else {
+			cost := job.loadTableCost(tName)
+
 			status := model.TableUploadExported
 			tableUploadErr = job.tableUploadsRepo.Set(job.ctx, job.upload.ID, tName, repo.TableUploadSetOptions{
 				Status: &status,
+				Cost:   cost,
 			})
 			if tableUploadErr == nil {
 				// Since load is successful, we assume all events in load files are uploaded
@@ -1351,6 +1530,9 @@ func (job *UploadJob) processLoadTableResponse(errorMap map[string]error) (error
 				if queryErr == nil {
 					job.recordTableLoad(tName, tableUpload.TotalEvents)
 				}
+				if cost != nil {
+					job.recordTableLoadCost(tName, *cost)
+				}
 			}
 		}
 
@@ -1479,6 +1661,11 @@ type UploadColumnsOpts struct {
 
 // SetUploadColumns sets any column values passed as args in UploadColumn format for WarehouseUploadsTable
 func (job *UploadJob) setUploadColumns(opts UploadColumnsOpts) error {
+	// nothing is recorded for the uploads run in plan mode
+	if job.planMode {
+		return nil
+	}
+
 	var columns string
 	values := []interface{}{job.upload.ID}
 	// setting values using syntax $n since Exec can correctly format time.Time strings
@@ -1597,6 +1784,11 @@ func (job *UploadJob) Aborted(attempts int, startTime time.Time) bool {
 }
 
 func (job *UploadJob) setUploadError(statusError error, state string) (string, error) {
+	// the errors of the uploads run in plan mode are returned to the caller instead
+	if job.planMode {
+		return state, nil
+	}
+
 	var (
 		errorTags                  = job.errorHandler.MatchErrorMappings(statusError)
 		destCredentialsValidations *bool
@@ -15,6 +15,7 @@ import (
 	"github.com/rudderlabs/rudder-go-kit/config"
 	"github.com/rudderlabs/rudder-go-kit/testhelper/docker/resource"
 	"github.com/rudderlabs/rudder-server/services/alerta"
+	"github.com/rudderlabs/rudder-server/warehouse/integrations/manager"
 	"github.com/rudderlabs/rudder-server/warehouse/integrations/middleware/sqlquerywrapper"
 	sqlmiddleware "github.com/rudderlabs/rudder-server/warehouse/integrations/middleware/sqlquerywrapper"
 	"github.com/rudderlabs/rudder-server/warehouse/integrations/redshift"
@@ -29,6 +30,7 @@ import (
 
 	"github.com/rudderlabs/rudder-go-kit/logger"
 	backendconfig "github.com/rudderlabs/rudder-server/backend-config"
+	proto "github.com/rudderlabs/rudder-server/proto/warehouse"
 	"github.com/rudderlabs/rudder-server/warehouse/internal/model"
 	"github.com/rudderlabs/rudder-server/warehouse/internal/repo"
 	warehouseutils "github.com/rudderlabs/rudder-server/warehouse/utils"
@@ -695,3 +697,235 @@ func TestUploadJobT_TablesToSkip(t *testing.T) {
 		})
 	})
 }
+
+func TestUploadPlanToProto(t *testing.T) {
+	plan := model.UploadPlan{
+		UploadID: 1,
+		Tables: []model.TablePlan{
+			{
+				Name:        "tracks",
+				CreateTable: true,
+				AddedColumns: model.TableSchema{
+					"id":          "string",
+					"received_at": "datetime",
+				},
+				AlteredColumns: model.TableSchema{},
+				TotalRows:      10,
+			},
+			{
+				Name: "users",
+				AddedColumns: model.TableSchema{
+					"plan": "string",
+				},
+				AlteredColumns: model.TableSchema{
+					"age": "bigint",
+				},
+				TotalRows: 5,
+			},
+		},
+	}
+
+	response := uploadPlanToProto(plan)
+	require.Equal(t, int64(1), response.UploadId)
+	require.Equal(t, int64(15), response.TotalRows)
+	require.Len(t, response.Tables, 2)
+
+	require.Equal(t, "tracks", response.Tables[0].Name)
+	require.True(t, response.Tables[0].CreateTable)
+	require.Len(t, response.Tables[0].AddedColumns, 2)
+	require.Equal(t, "id", response.Tables[0].AddedColumns[0].Name)
+	require.Equal(t, "string", response.Tables[0].AddedColumns[0].Type)
+	require.Equal(t, "received_at", response.Tables[0].AddedColumns[1].Name)
+	require.Equal(t, "datetime", response.Tables[0].AddedColumns[1].Type)
+	require.Empty(t, response.Tables[0].AlteredColumns)
+	require.Equal(t, int64(10), response.Tables[0].TotalRows)
+
+	require.Equal(t, "users", response.Tables[1].Name)
+	require.False(t, response.Tables[1].CreateTable)
+	require.Len(t, response.Tables[1].AddedColumns, 1)
+	require.Equal(t, "plan", response.Tables[1].AddedColumns[0].Name)
+	require.Len(t, response.Tables[1].AlteredColumns, 1)
+	require.Equal(t, "age", response.Tables[1].AlteredColumns[0].Name)
+	require.Equal(t, "bigint", response.Tables[1].AlteredColumns[0].Type)
+	require.Equal(t, int64(5), response.Tables[1].TotalRows)
+}
+
+func TestBackfillToProto(t *testing.T) {
+	now := time.Date(2023, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	backfill := model.Backfill{
+		ID:              1,
+		WorkspaceID:     "workspace_id",
+		SourceID:        "source_id",
+		DestinationID:   "destination_id",
+		Tables:          []string{"tracks", "pages"},
+		EndTime:         now,
+		DeleteRows:      true,
+		Status:          model.BackfillScheduled,
+		UploadIDs:       []int64{1, 2},
+		ExportedUploads: 1,
+		CreatedAt:       now,
+	}
+
+	response := backfillToProto(backfill)
+	require.Equal(t, int64(1), response.Id)
+	require.Equal(t, "source_id", response.SourceId)
+	require.Equal(t, "destination_id", response.DestinationId)
+	require.Equal(t, []string{"tracks", "pages"}, response.Tables)
+	require.Nil(t, response.StartTime)
+	require.Equal(t, now, response.EndTime.AsTime())
+	require.True(t, response.DeleteRows)
+	require.Equal(t, model.BackfillExecuting, response.Status)
+	require.Equal(t, []int64{1, 2}, response.UploadIds)
+	require.Equal(t, int64(1), response.ExportedUploads)
+	require.Equal(t, now, response.CreatedAt.AsTime())
+
+	backfill.StartTime = now.Add(-time.Hour)
+	require.Equal(t, now.Add(-time.Hour), backfillToProto(backfill).StartTime.AsTime())
+}
+
+func TestUploadCost(t *testing.T) {
+	tables := []*proto.WHTable{
+		{Name: "tracks", Cost: &proto.WHLoadCost{Queries: 2, BytesScanned: 100, BytesBilled: 1000, Credits: 0.5}},
+		{Name: "pages", Cost: &proto.WHLoadCost{Queries: 1, BytesScanned: 50, Credits: 0.25}},
+		{Name: "rudder_discards"},
+	}
+
+	cost := uploadCost(tables)
+	require.Equal(t, int64(3), cost.Queries)
+	require.Equal(t, int64(150), cost.BytesScanned)
+	require.Equal(t, int64(1000), cost.BytesBilled)
+	require.Equal(t, 0.75, cost.Credits)
+
+	require.Equal(t, &proto.WHLoadCost{}, uploadCost(nil))
+}
+
+func TestUploadJob_UploadPlanFor(t *testing.T) {
+	uploadSchema := model.Schema{
+		"pages":  {"id": "string", "received_at": "datetime"},
+		"tracks": {"id": "string", "received_at": "datetime", "event": "string"},
+		"users":  {"id": "string", "plan": "text"},
+	}
+	schemaInWarehouse := model.Schema{
+		"tracks": {"id": "string", "received_at": "datetime"},
+		"users":  {"id": "string", "plan": "string"},
+	}
+	rowsByTable := map[string]int64{
+		"tracks": 10,
+		"users":  5,
+	}
+
+	newJob := func(disableAlter bool) *UploadJob {
+		return &UploadJob{
+			upload: model.Upload{
+				ID:           1,
+				UploadSchema: uploadSchema,
+			},
+			DisableAlter: disableAlter,
+			schemaHandle: &Schema{
+				schemaInWarehouse: schemaInWarehouse,
+				uploadSchema:      uploadSchema,
+			},
+		}
+	}
+
+	t.Run("plan", func(t *testing.T) {
+		plan := newJob(false).uploadPlanFor(rowsByTable)
+		require.Equal(t, int64(1), plan.UploadID)
+		require.Equal(t, int64(15), plan.TotalRows())
+		require.Equal(t, []model.TablePlan{
+			{
+				Name:           "pages",
+				CreateTable:    true,
+				AddedColumns:   model.TableSchema{"id": "string", "received_at": "datetime"},
+				AlteredColumns: model.TableSchema{},
+			},
+			{
+				Name:           "tracks",
+				AddedColumns:   model.TableSchema{"event": "string"},
+				AlteredColumns: model.TableSchema{},
+				TotalRows:      10,
+			},
+			{
+				Name:           "users",
+				AddedColumns:   model.TableSchema{},
+				AlteredColumns: model.TableSchema{"plan": "text"},
+				TotalRows:      5,
+			},
+		}, plan.Tables)
+	})
+
+	t.Run("disable alter", func(t *testing.T) {
+		plan := newJob(true).uploadPlanFor(rowsByTable)
+		require.Equal(t, model.TableSchema{}, plan.Tables[2].AlteredColumns)
+	})
+}
+
+func TestRestrictSchemaToTables(t *testing.T) {
+	schema := model.Schema{
+		"TRACKS":             {"ID": "string"},
+		"TRACKS_QUARANTINE":  {"ID": "string"},
+		"PAGES":              {"ID": "string"},
+		"PAGES_QUARANTINE":   {"ID": "string"},
+		"RUDDER_DISCARDS":    warehouseutils.DiscardsSchema,
+		"RUDDER_IDENTIFIERS": {"ID": "string"},
+	}
+
+	require.Equal(t, model.Schema{
+		"TRACKS":            {"ID": "string"},
+		"TRACKS_QUARANTINE": {"ID": "string"},
+		"RUDDER_DISCARDS":   warehouseutils.DiscardsSchema,
+	}, restrictSchemaToTables(schema, warehouseutils.SNOWFLAKE, []string{"tracks"}))
+
+	require.Equal(t, model.Schema{
+		"RUDDER_DISCARDS": warehouseutils.DiscardsSchema,
+	}, restrictSchemaToTables(schema, warehouseutils.SNOWFLAKE, []string{"users"}))
+}
+
+type mockLoadCostReporter struct {
+	manager.Manager
+
+	cost  model.LoadCost
+	block bool
+}
+
+func (m *mockLoadCostReporter) LoadTableCost(ctx context.Context, _ string) (model.LoadCost, error) {
+	if m.block {
+		<-ctx.Done()
+		return model.LoadCost{}, ctx.Err()
+	}
+	return m.cost, nil
+}
+
+func TestUploadJob_LoadTableCost(t *testing.T) {
+	Init()
+	Init4()
+
+	t.Run("reported", func(t *testing.T) {
+		job := &UploadJob{
+			ctx:       context.Background(),
+			whManager: &mockLoadCostReporter{cost: model.LoadCost{Queries: 2, BytesScanned: 100}},
+		}
+		require.Equal(t, &model.LoadCost{Queries: 2, BytesScanned: 100}, job.loadTableCost("tracks"))
+	})
+
+	t.Run("not supported", func(t *testing.T) {
+		job := &UploadJob{
+			ctx:       context.Background(),
+			whManager: &struct{ manager.Manager }{},
+		}
+		require.Nil(t, job.loadTableCost("tracks"))
+	})
+
+	t.Run("timeout", func(t *testing.T) {
+		initialTimeout := loadTableCostTimeout
+		loadTableCostTimeout = time.Millisecond
+		t.Cleanup(func() { loadTableCostTimeout = initialTimeout })
+
+		job := &UploadJob{
+			ctx:       context.Background(),
+			whManager: &mockLoadCostReporter{block: true},
+		}
+		require.Nil(t, job.loadTableCost("tracks"))
+	})
+}
@@ -15,6 +15,7 @@ import (
 	"regexp"
 	"sort"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/samber/lo"
@@ -54,6 +55,7 @@ const (
 	S3Datalake    = "S3_DATALAKE"
 	GCSDatalake   = "GCS_DATALAKE"
 	AzureDatalake = "AZURE_DATALAKE"
+	SQLITE        = "SQLITE"
 )
 
 const (
@@ -72,10 +74,12 @@ const (
 	WarehouseTableUploadsTable = "wh_table_uploads"
 	WarehouseSchemasTable      = "wh_schemas"
 	WarehouseAsyncJobTable     = "wh_async_jobs"
+	WarehouseBackfillsTable    = "wh_backfills"
 )
 
 const (
 	DiscardsTable           = "rudder_discards"
+	QuarantineTableSuffix   = "_quarantine"
 	IdentityMergeRulesTable = "rudder_identity_merge_rules"
 	IdentityMappingsTable   = "rudder_identity_mappings"
 	SyncFrequency           = "syncFrequency"
@@ -139,8 +143,9 @@ var (
 	AWSCredsExpiryInS  int64
 
 	TimeWindowDestinations    = []string{S3Datalake, GCSDatalake, AzureDatalake}
-	WarehouseDestinations     = []string{RS, BQ, SNOWFLAKE, POSTGRES, CLICKHOUSE, MSSQL, AzureSynapse, S3Datalake, GCSDatalake, AzureDatalake, DELTALAKE}
-	IdentityEnabledWarehouses = []string{SNOWFLAKE, BQ}
+	WarehouseDestinations     = []string{RS, BQ, SNOWFLAKE, POSTGRES, CLICKHOUSE, MSSQL, AzureSynapse, S3Datalake, GCSDatalake, AzureDatalake, DELTALAKE, SQLITE}
+	IdentityEnabledWarehouses = []string{SNOWFLAKE, BQ, SQLITE}
+	WideningEnabledWarehouses = []string{POSTGRES, RS}
 	S3PathStyleRegex          = regexp.MustCompile(`https?://s3([.-](?P<region>[^.]+))?.amazonaws\.com/(?P<bucket>[^/]+)/(?P<keyname>.*)`)
 	S3VirtualHostedRegex      = regexp.MustCompile(`https?://(?P<bucket>[^/]+).s3([.-](?P<region>[^.]+))?.amazonaws\.com/(?P<keyname>.*)`)
 
@@ -161,6 +166,7 @@ var WHDestNameMap = map[string]string{
 	GCSDatalake:   "gcs_datalake",
 	AzureDatalake: "azure_datalake",
 	AzureSynapse:  "azure_synapse",
+	SQLITE:        "sqlite",
 }
 
 var ObjectStorageMap = map[string]string{
@@ -186,10 +192,25 @@ var DiscardsSchema = map[string]string{
 	"uuid_ts":      "datetime",
 }
 
+// QuarantineSchema is the schema of the <table>_quarantine tables, holding the rows quarantined because of a column
+// having a value which cannot be converted to the type of the column in the warehouse, along with the whole event.
+var QuarantineSchema = map[string]string{
+	"id":           "string",
+	"event":        "string",
+	"column_name":  "string",
+	"column_type":  "string",
+	"column_value": "string",
+	"reason":       "string",
+	"received_at":  "datetime",
+	"uuid_ts":      "datetime",
+}
+
 const (
 	LoadFileTypeCsv       = "csv"
 	LoadFileTypeJson      = "json"
 	LoadFileTypeParquet   = "parquet"
+	LoadFileTypeArrow     = "arrow"
+	LoadFileTypeOrc       = "orc"
 	TestConnectionTimeout = 15 * time.Second
 )
 
@@ -209,11 +230,15 @@ type DeleteByMetaData struct {
 	StartTime string `json:"start_time"`
 }
 
+// DeleteByParams are the parameters of the deletes of the rows of a source.
+// Without an EndTime, the rows of the previous runs received before the StartTime are deleted,
+// otherwise the rows received in the range [StartTime, EndTime) are deleted irrespective of the runs they were loaded by.
 type DeleteByParams struct {
 	SourceId  string
 	JobRunId  string
 	TaskRunId string
 	StartTime string
+	EndTime   string
 }
 
 type ColumnInfo struct {
@@ -316,6 +341,23 @@ type FetchTablesResponse struct {
 	ConnectionsTables []FetchTableInfo `json:"connections_tables"`
 }
 
+type TableFreshnessRequest struct {
+	SourceID      string `json:"source_id"`
+	DestinationID string `json:"destination_id"`
+}
+
+type TableFreshness struct {
+	TableName          string     `json:"table_name"`
+	SLAInSeconds       int64      `json:"sla_in_seconds"`
+	LastEventAt        *time.Time `json:"last_event_at,omitempty"`
+	StalenessInSeconds int64      `json:"staleness_in_seconds"`
+	Breached           bool       `json:"breached"`
+}
+
+type TableFreshnessResponse struct {
+	Tables []TableFreshness `json:"tables"`
+}
+
 func TimingFromJSONString(str sql.NullString) (status string, recordedTime time.Time) {
 	timingsMap := gjson.Parse(str.String).Map()
 	for s, t := range timingsMap {
@@ -635,6 +677,113 @@ func GetConfigValueBoolString(key string, warehouse model.Warehouse) string {
 	return "false"
 }
 
+// MergeKeys are the keys for merging the rows of a table into the warehouse, i.e. upserting them on the primary keys
+// instead of deduplicating them on id. They are configured per table with the mergeKeys setting of the destination:
+//
+//	"mergeKeys": [{"table": "identifies", "primaryKeys": ["user_id"], "latestBy": "timestamp"}]
+//
+// Rows with the same primary keys are merged into the latest one by the latestBy column, or by received_at if not configured.
+// With latestBy configured, rows in the warehouse are only replaced by rows which are not older than them.
+// The users table is always merged on id, so merge keys are rejected for it.
+type MergeKeys struct {
+	PrimaryKeys []string
+	LatestBy    string
+}
+
+// GetMergeKeys returns the merge keys configured for the table, in the case of the warehouse
+func GetMergeKeys(warehouse model.Warehouse, tableName string) (MergeKeys, bool) {
+	tables, _ := warehouse.Destination.Config["mergeKeys"].([]interface{})
+	for _, t := range tables {
+		table, _ := t.(map[string]interface{})
+		if name, _ := table["table"].(string); !strings.EqualFold(strings.TrimSpace(name), tableName) {
+			continue
+		}
+
+		var keys []string
+		switch primaryKeys := table["primaryKeys"].(type) {
+		case []interface{}:
+			for _, primaryKey := range primaryKeys {
+				if key, ok := primaryKey.(string); ok {
+					keys = append(keys, key)
+				}
+			}
+		case string:
+			keys = strings.Split(primaryKeys, ",")
+		}
+
+		var mergeKeys MergeKeys
+		for _, key := range keys {
+			if key = strings.TrimSpace(key); key != "" {
+				mergeKeys.PrimaryKeys = append(mergeKeys.PrimaryKeys, ToProviderCase(warehouse.Type, key))
+			}
+		}
+		if len(mergeKeys.PrimaryKeys) == 0 {
+			return MergeKeys{}, false
+		}
+		if latestBy, _ := table["latestBy"].(string); strings.TrimSpace(latestBy) != "" {
+			mergeKeys.LatestBy = ToProviderCase(warehouse.Type, strings.TrimSpace(latestBy))
+		}
+		return mergeKeys, true
+	}
+	return MergeKeys{}, false
+}
+
+// Validate returns an error if any of the merge keys is not a column of the table schema
+func (mk MergeKeys) Validate(tableSchema model.TableSchema) error {
+	columns := mk.PrimaryKeys
+	if mk.LatestBy != "" {
+		columns = append(columns[:len(columns):len(columns)], mk.LatestBy)
+	}
+	for _, column := range columns {
+		if _, ok := tableSchema[column]; !ok {
+			return fmt.Errorf("merge key %s is not a column of the table", column)
+		}
+	}
+	return nil
+}
+
+// ValidateMergeKeys returns an error if merge keys are configured for the users table.
+// Users are always merged on their id with the latest identifies.
+func ValidateMergeKeys(warehouse model.Warehouse) error {
+	if _, ok := GetMergeKeys(warehouse, ToProviderCase(warehouse.Type, UsersTable)); ok {
+		return fmt.Errorf("merge keys are not supported for the %s table", UsersTable)
+	}
+	return nil
+}
+
+// OrderByColumn returns the column for ordering the rows with the same primary keys, latest first
+func (mk MergeKeys) OrderByColumn(warehouseType string) string {
+	if mk.LatestBy != "" {
+		return mk.LatestBy
+	}
+	return ToProviderCase(warehouseType, "received_at")
+}
+
+// GetFreshnessSLAs returns the freshness SLAs of the tables, i.e. how stale the events in a table are allowed to be,
+// in the case of the warehouse. They are configured per table with the freshnessSLAs setting of the destination:
+//
+//	"freshnessSLAs": [{"table": "tracks", "maxStaleness": "2h"}]
+func GetFreshnessSLAs(warehouse model.Warehouse) map[string]time.Duration {
+	slas := make(map[string]time.Duration)
+
+	tables, _ := warehouse.Destination.Config["freshnessSLAs"].([]interface{})
+	for _, t := range tables {
+		table, _ := t.(map[string]interface{})
+
+		name, _ := table["table"].(string)
+		if name = strings.TrimSpace(name); name == "" {
+			continue
+		}
+		maxStaleness, _ := table["maxStaleness"].(string)
+		sla, err := time.ParseDuration(strings.TrimSpace(maxStaleness))
+		if err != nil || sla <= 0 {
+			continue
+		}
+		slas[ToProviderCase(warehouse.Type, name)] = sla
+	}
+	return slas
+}
+
 func GetConfigValueAsMap(key string, config map[string]interface{}) map[string]interface{} {
 	value := map[string]interface{}{}
 	if config[key] != nil {
@@ -893,6 +1042,8 @@ func GetSSLKeyDirPath(destinationID string) (whSSLRootDir string) {
 	return sslDirPath
 }
 
+// GetLoadFileType returns the default type of the load files of the destination type. The integrations supporting more
+// than one type declare the type of a destination themselves, see manager.LoadFileType.
 func GetLoadFileType(destType string) string {
 	switch destType {
 	case BQ:
@@ -917,6 +1068,10 @@ func GetLoadFileFormat(loadFileType string) string {
 		return "json.gz"
 	case LoadFileTypeParquet:
 		return "parquet"
+	case LoadFileTypeArrow:
+		return "arrow"
+	case LoadFileTypeOrc:
+		return "orc"
 	case LoadFileTypeCsv:
 		return "csv.gz"
 	default:
@@ -958,6 +1113,35 @@ func RandHex() string {
 	return string(buf[:])
 }
 
+// LoadQueryTags keeps track of the query tags used while loading the tables,
+// so that the cost of the queries can be looked up in the warehouse query history afterwards.
+type LoadQueryTags struct {
+	mu   sync.Mutex
+	tags map[string]string
+}
+
+// New returns a new query tag for loading the table, replacing the previous one
+func (t *LoadQueryTags) New(warehouse model.Warehouse, tableName string) string {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.tags == nil {
+		t.tags = make(map[string]string)
+	}
+	tag := fmt.Sprintf("rudderstack:%s:%s:%s", warehouse.Source.ID, tableName, RandHex())
+	t.tags[tableName] = tag
+	return tag
+}
+
+// Get returns the query tag used for loading the table, if any
+func (t *LoadQueryTags) Get(tableName string) (string, bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	tag, ok := t.tags[tableName]
+	return tag, ok
+}
+
 func ExtractTunnelInfoFromDestinationConfig(config map[string]interface{}) *tunnelling.TunnelInfo {
 	if tunnelEnabled := ReadAsBool("useSSH", config); !tunnelEnabled {
 		return nil
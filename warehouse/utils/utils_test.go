@@ -1373,3 +1373,185 @@ func TestMain(m *testing.M) {
 	Init()
 	os.Exit(m.Run())
 }
+
+func TestLoadQueryTags(t *testing.T) {
+	var tags LoadQueryTags
+	warehouse := model.Warehouse{
+		Source: backendconfig.SourceT{ID: "source_id"},
+	}
+
+	_, ok := tags.Get("tracks")
+	require.False(t, ok)
+
+	tag := tags.New(warehouse, "tracks")
+	require.True(t, strings.HasPrefix(tag, "rudderstack:source_id:tracks:"))
+
+	got, ok := tags.Get("tracks")
+	require.True(t, ok)
+	require.Equal(t, tag, got)
+
+	newTag := tags.New(warehouse, "tracks")
+	require.NotEqual(t, tag, newTag)
+
+	got, ok = tags.Get("tracks")
+	require.True(t, ok)
+	require.Equal(t, newTag, got)
+}
+
+func TestGetMergeKeys(t *testing.T) {
+	mergeKeysConfig := []interface{}{
+		map[string]interface{}{
+			"table":       "identifies",
+			"primaryKeys": []interface{}{"user_id", " context_traits_email "},
+			"latestBy":    "timestamp",
+		},
+		map[string]interface{}{
+			"table":       "products",
+			"primaryKeys": "sku, store_id",
+		},
+		map[string]interface{}{
+			"table":       "empty",
+			"primaryKeys": []interface{}{" "},
+		},
+	}
+
+	testCases := []struct {
+		name          string
+		warehouseType string
+		tableName     string
+		config        map[string]interface{}
+		want          MergeKeys
+		wantOk        bool
+	}{
+		{
+			name:          "no merge keys",
+			warehouseType: POSTGRES,
+			tableName:     "identifies",
+			config:        map[string]interface{}{},
+		},
+		{
+			name:          "primary keys and latest by",
+			warehouseType: POSTGRES,
+			tableName:     "identifies",
+			config:        map[string]interface{}{"mergeKeys": mergeKeysConfig},
+			want:          MergeKeys{PrimaryKeys: []string{"user_id", "context_traits_email"}, LatestBy: "timestamp"},
+			wantOk:        true,
+		},
+		{
+			name:          "comma separated primary keys",
+			warehouseType: RS,
+			tableName:     "products",
+			config:        map[string]interface{}{"mergeKeys": mergeKeysConfig},
+			want:          MergeKeys{PrimaryKeys: []string{"sku", "store_id"}},
+			wantOk:        true,
+		},
+		{
+			name:          "provider case",
+			warehouseType: SNOWFLAKE,
+			tableName:     "IDENTIFIES",
+			config:        map[string]interface{}{"mergeKeys": mergeKeysConfig},
+			want:          MergeKeys{PrimaryKeys: []string{"USER_ID", "CONTEXT_TRAITS_EMAIL"}, LatestBy: "TIMESTAMP"},
+			wantOk:        true,
+		},
+		{
+			name:          "empty primary keys",
+			warehouseType: POSTGRES,
+			tableName:     "empty",
+			config:        map[string]interface{}{"mergeKeys": mergeKeysConfig},
+		},
+		{
+			name:          "other table",
+			warehouseType: POSTGRES,
+			tableName:     "tracks",
+			config:        map[string]interface{}{"mergeKeys": mergeKeysConfig},
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			mergeKeys, ok := GetMergeKeys(model.Warehouse{
+				Type: tc.warehouseType,
+				Destination: backendconfig.DestinationT{
+					Config: tc.config,
+				},
+			}, tc.tableName)
+			require.Equal(t, tc.wantOk, ok)
+			require.Equal(t, tc.want, mergeKeys)
+		})
+	}
+
+	require.Equal(t, "received_at", MergeKeys{PrimaryKeys: []string{"id"}}.OrderByColumn(POSTGRES))
+	require.Equal(t, "RECEIVED_AT", MergeKeys{PrimaryKeys: []string{"ID"}}.OrderByColumn(SNOWFLAKE))
+	require.Equal(t, "timestamp", MergeKeys{PrimaryKeys: []string{"id"}, LatestBy: "timestamp"}.OrderByColumn(POSTGRES))
+
+	tableSchema := model.TableSchema{"user_id": "string", "context_traits_email": "string", "timestamp": "datetime"}
+	require.NoError(t, MergeKeys{PrimaryKeys: []string{"user_id", "context_traits_email"}, LatestBy: "timestamp"}.Validate(tableSchema))
+	require.EqualError(t, MergeKeys{PrimaryKeys: []string{"user_id", "sku"}}.Validate(tableSchema), "merge key sku is not a column of the table")
+	require.EqualError(t, MergeKeys{PrimaryKeys: []string{"user_id"}, LatestBy: "updated_at"}.Validate(tableSchema), "merge key updated_at is not a column of the table")
+
+	require.NoError(t, ValidateMergeKeys(model.Warehouse{
+		Type:        SNOWFLAKE,
+		Destination: backendconfig.DestinationT{Config: map[string]interface{}{"mergeKeys": mergeKeysConfig}},
+	}))
+	require.EqualError(t, ValidateMergeKeys(model.Warehouse{
+		Type: SNOWFLAKE,
+		Destination: backendconfig.DestinationT{Config: map[string]interface{}{"mergeKeys": []interface{}{
+			map[string]interface{}{"table": "users", "primaryKeys": "email"},
+		}}},
+	}), "merge keys are not supported for the users table")
+}
+
+func TestGetFreshnessSLAs(t *testing.T) {
+	freshnessSLAsConfig := []interface{}{
+		map[string]interface{}{"table": "tracks", "maxStaleness": "2h"},
+		map[string]interface{}{"table": " pages ", "maxStaleness": "30m"},
+		map[string]interface{}{"table": "invalid", "maxStaleness": "2 hours"},
+		map[string]interface{}{"table": "negative", "maxStaleness": "-1h"},
+		map[string]interface{}{"maxStaleness": "1h"},
+	}
+
+	testCases := []struct {
+		name          string
+		warehouseType string
+		config        map[string]interface{}
+		want          map[string]time.Duration
+	}{
+		{
+			name:          "not configured",
+			warehouseType: POSTGRES,
+			config:        map[string]interface{}{},
+			want:          map[string]time.Duration{},
+		},
+		{
+			name:          "configured",
+			warehouseType: POSTGRES,
+			config:        map[string]interface{}{"freshnessSLAs": freshnessSLAsConfig},
+			want:          map[string]time.Duration{"tracks": 2 * time.Hour, "pages": 30 * time.Minute},
+		},
+		{
+			name:          "provider case",
+			warehouseType: SNOWFLAKE,
+			config:        map[string]interface{}{"freshnessSLAs": freshnessSLAsConfig},
+			want:          map[string]time.Duration{"TRACKS": 2 * time.Hour, "PAGES": 30 * time.Minute},
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			require.Equal(t, tc.want, GetFreshnessSLAs(model.Warehouse{
+				Type: tc.warehouseType,
+				Destination: backendconfig.DestinationT{
+					Config: tc.config,
+				},
+			}))
+		})
+	}
+}
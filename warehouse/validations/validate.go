@@ -96,7 +96,15 @@ func (*dummyUploader) GetSingleLoadFile(context.Context, string) (warehouseutils
 }
 
 func (m *dummyUploader) GetLoadFileType() string {
-	return warehouseutils.GetLoadFileType(m.dest.DestinationDefinition.Name)
+	return destinationLoadFileType(m.dest)
+}
+
+// destinationLoadFileType returns the type of the load files of the destination
+func destinationLoadFileType(dest *backendconfig.DestinationT) string {
+	return manager.LoadFileType(model.Warehouse{
+		Type:        dest.DestinationDefinition.Name,
+		Destination: *dest,
+	})
 }
 
 func (m *dummyUploader) UseRudderStorage() bool {
@@ -291,10 +299,15 @@ func (os *objectStorage) Validate(ctx context.Context) error {
 func (c *connections) Validate(ctx context.Context) error {
 	defer c.manager.Cleanup(ctx)
 
+	warehouse := createDummyWarehouse(c.destination)
+	if err := warehouseutils.ValidateMergeKeys(warehouse); err != nil {
+		return fmt.Errorf("validating merge keys: %w", err)
+	}
+
 	ctx, cancel := context.WithTimeout(ctx, warehouseutils.TestConnectionTimeout)
 	defer cancel()
 
-	return c.manager.TestConnection(ctx, createDummyWarehouse(c.destination))
+	return c.manager.TestConnection(ctx, warehouse)
 }
 
 func (cs *createSchema) Validate(ctx context.Context) error {
@@ -332,8 +345,7 @@ func (fs *fetchSchema) Validate(ctx context.Context) error {
 
 func (lt *loadTable) Validate(ctx context.Context) error {
 	var (
-		destinationType = lt.destination.DestinationDefinition.Name
-		loadFileType    = warehouseutils.GetLoadFileType(destinationType)
+		loadFileType = destinationLoadFileType(lt.destination)
 
 		tempPath     string
 		uploadOutput filemanager.UploadedFile
@@ -372,7 +384,7 @@ func CreateTempLoadFile(dest *backendconfig.DestinationT) (string, error) {
 		writer     encoding.LoadFileWriter
 
 		destinationType = dest.DestinationDefinition.Name
-		loadFileType    = warehouseutils.GetLoadFileType(destinationType)
+		loadFileType    = destinationLoadFileType(dest)
 	)
 
 	if tmpDirPath, err = misc.CreateTMPDIR(); err != nil {
@@ -391,11 +403,7 @@ func CreateTempLoadFile(dest *backendconfig.DestinationT) (string, error) {
 		return "", fmt.Errorf("create directory: %w", err)
 	}
 
-	if loadFileType == warehouseutils.LoadFileTypeParquet {
-		writer, err = encoding.CreateParquetWriter(tableSchemaMap, filePath, destinationType)
-	} else {
-		writer, err = misc.CreateGZ(filePath)
-	}
+	writer, err = encoding.NewLoadFileWriter(loadFileType, tableSchemaMap, filePath, destinationType)
 	if err != nil {
 		return "", fmt.Errorf("creating writer for file: %s with error: %w", filePath, err)
 	}
@@ -455,7 +463,7 @@ func downloadFile(ctx context.Context, dest *backendconfig.DestinationT, locatio
 		filePath     string
 
 		destinationType = dest.DestinationDefinition.Name
-		loadFileType    = warehouseutils.GetLoadFileType(destinationType)
+		loadFileType    = destinationLoadFileType(dest)
 	)
 
 	if fm, err = createFileManager(dest); err != nil {
@@ -38,6 +38,7 @@ import (
 	backendconfig "github.com/rudderlabs/rudder-server/backend-config"
 	"github.com/rudderlabs/rudder-server/info"
 	"github.com/rudderlabs/rudder-server/rruntime"
+	"github.com/rudderlabs/rudder-server/services/alerta"
 	"github.com/rudderlabs/rudder-server/services/controlplane"
 	"github.com/rudderlabs/rudder-server/services/db"
 	"github.com/rudderlabs/rudder-server/services/pgnotifier"
@@ -93,6 +94,7 @@ var (
 	longRunningUploadStatThresholdInMin time.Duration
 	pkgLogger                           logger.Logger
 	tableCountQueryTimeout              time.Duration
+	loadTableCostTimeout                time.Duration
 	runningMode                         string
 	uploadStatusTrackFrequency          time.Duration
 	uploadAllocatorSleep                time.Duration
@@ -158,6 +160,8 @@ type HandleT struct {
 	Logger                            logger.Logger
 	cpInternalClient                  cpclient.InternalControlPlane
 	conf                              *config.Config
+	alertSender                       alerta.AlertSender
+	freshnessBreaches                 map[string]bool
 
 	backgroundCancel context.CancelFunc
 	backgroundGroup  errgroup.Group
@@ -205,6 +209,7 @@ func loadConfig() {
 	config.RegisterIntConfigVariable(8, &maxParallelJobCreation, true, 1, "Warehouse.maxParallelJobCreation")
 	config.RegisterBoolConfigVariable(false, &enableJitterForSyncs, true, "Warehouse.enableJitterForSyncs")
 	config.RegisterDurationConfigVariable(30, &tableCountQueryTimeout, true, time.Second, []string{"Warehouse.tableCountQueryTimeout", "Warehouse.tableCountQueryTimeoutInS"}...)
+	config.RegisterDurationConfigVariable(10, &loadTableCostTimeout, true, time.Second, []string{"Warehouse.loadTableCostTimeout", "Warehouse.loadTableCostTimeoutInS"}...)
 	config.RegisterDurationConfigVariable(5, &dbHandleTimeout, true, time.Minute, []string{"Warehouse.dbHandleTimeout", "Warehouse.dbHanndleTimeoutInMin"}...)
 
 	appName = misc.DefaultString("rudder-server").OnError(os.Hostname())
@@ -537,7 +542,7 @@ func (wh *HandleT) createUploadJobsFromStagingFiles(ctx context.Context, warehou
 			DestinationType: wh.destType,
 			Status:          model.Waiting,
 
-			LoadFileType:  warehouseutils.GetLoadFileType(wh.destType),
+			LoadFileType:  manager.LoadFileType(warehouse),
 			NextRetryTime: uploadStartAfter,
 			Priority:      priority,
 
@@ -862,6 +867,25 @@ func (wh *HandleT) Disable() {
 	wh.isEnabled = false
 }
 
+func newUploadJobFactory(whType string, dbHandle *sqlquerywrapper.DB, pgNotifier *pgnotifier.PGNotifier) UploadJobFactory {
+	f := UploadJobFactory{
+		stats:                stats.Default,
+		dbHandle:             dbHandle,
+		pgNotifier:           pgNotifier,
+		destinationValidator: validations.NewDestinationValidator(),
+		loadFile: &loadfiles.LoadFileGenerator{
+			Logger:             pkgLogger.Child("loadfile"),
+			Notifier:           &notifier,
+			StageRepo:          repo.NewStagingFiles(wrappedDBHandle),
+			LoadRepo:           repo.NewLoadFiles(wrappedDBHandle),
+			ControlPlaneClient: controlPlaneClient,
+		},
+		recovery: service.NewRecovery(whType, repo.NewUploads(wrappedDBHandle)),
+	}
+	loadfiles.WithConfig(f.loadFile, config.Default)
+	return f
+}
+
 func (wh *HandleT) Setup(ctx context.Context, whType string) error {
 	pkgLogger.Infof("WH: Warehouse Router started: %s", whType)
 	wh.Logger = pkgLogger
@@ -888,27 +912,17 @@ func (wh *HandleT) Setup(ctx context.Context, whType string) error {
 	}
 	wh.stats = stats.Default
 
-	wh.uploadJobFactory = UploadJobFactory{
-		stats:                stats.Default,
-		dbHandle:             wh.dbHandle,
-		pgNotifier:           &wh.notifier,
-		destinationValidator: validations.NewDestinationValidator(),
-		loadFile: &loadfiles.LoadFileGenerator{
-			Logger:             pkgLogger.Child("loadfile"),
-			Notifier:           &notifier,
-			StageRepo:          repo.NewStagingFiles(wrappedDBHandle),
-			LoadRepo:           repo.NewLoadFiles(wrappedDBHandle),
-			ControlPlaneClient: controlPlaneClient,
-		},
-		recovery: service.NewRecovery(whType, repo.NewUploads(wrappedDBHandle)),
-	}
-	loadfiles.WithConfig(wh.uploadJobFactory.loadFile, config.Default)
+	wh.uploadJobFactory = newUploadJobFactory(whType, wh.dbHandle, &wh.notifier)
 
 	whName := warehouseutils.WHDestNameMap[whType]
 	config.RegisterIntConfigVariable(8, &wh.noOfWorkers, true, 1, fmt.Sprintf(`Warehouse.%v.noOfWorkers`, whName), "Warehouse.noOfWorkers")
 	config.RegisterIntConfigVariable(1, &wh.maxConcurrentUploadJobs, false, 1, fmt.Sprintf(`Warehouse.%v.maxConcurrentUploadJobs`, whName))
 	config.RegisterBoolConfigVariable(false, &wh.allowMultipleSourcesForJobsPickup, false, fmt.Sprintf(`Warehouse.%v.allowMultipleSourcesForJobsPickup`, whName))
 
+	wh.alertSender = alerta.NewClient(
+		config.GetString("ALERTA_URL", "https://alerta.rudderstack.com/api/"),
+	)
+
 	wh.cpInternalClient = cpclient.NewInternalClientWithCache(
 		configBackendURL,
 		cpclient.BasicAuth{
@@ -1450,6 +1464,58 @@ func fetchTablesHandler(w http.ResponseWriter, r *http.Request) {
 	_, _ = w.Write(resBody)
 }
 
+func tableFreshnessHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	defer func() { _ = r.Body.Close() }()
+
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		pkgLogger.Errorf("[WH]: Error reading body: %v", err)
+		http.Error(w, "can't read body", http.StatusBadRequest)
+		return
+	}
+
+	var tableFreshnessReq warehouseutils.TableFreshnessRequest
+	err = json.Unmarshal(body, &tableFreshnessReq)
+	if err != nil {
+		pkgLogger.Errorf("[WH]: Error unmarshalling body: %v", err)
+		http.Error(w, "can't unmarshall body", http.StatusBadRequest)
+		return
+	}
+
+	if tableFreshnessReq.SourceID == "" || tableFreshnessReq.DestinationID == "" {
+		pkgLogger.Errorf("empty source_id or destination_id in the table freshness request")
+		http.Error(w, "empty source_id or destination_id", http.StatusBadRequest)
+		return
+	}
+
+	connectionsMapLock.RLock()
+	warehouse, ok := connectionsMap[tableFreshnessReq.DestinationID][tableFreshnessReq.SourceID]
+	connectionsMapLock.RUnlock()
+	if !ok {
+		http.Error(w, "connection between source and destination not found", http.StatusNotFound)
+		return
+	}
+
+	tables, err := getTablesFreshness(ctx, repo.NewTableUploads(wrappedDBHandle), warehouse, timeutil.Now())
+	if err != nil {
+		pkgLogger.Errorf("[WH]: Error fetching tables freshness: %v", err)
+		http.Error(w, "can't fetch tables freshness", http.StatusInternalServerError)
+		return
+	}
+	resBody, err := json.Marshal(warehouseutils.TableFreshnessResponse{
+		Tables: tables,
+	})
+	if err != nil {
+		err := fmt.Errorf("failed to marshall tables freshness to response : %v", err)
+		pkgLogger.Errorf("[WH]: %v", err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	_, _ = w.Write(resBody)
+}
+
 func isUploadTriggered(wh model.Warehouse) bool {
 	triggerUploadsMapLock.RLock()
 	defer triggerUploadsMapLock.RUnlock()
@@ -1563,6 +1629,9 @@ func startWebHandler(ctx context.Context) error {
 			srvMux.Post("/v1/warehouse/jobs", asyncWh.AddWarehouseJobHandler)          // FIXME: add degraded mode
 			srvMux.Get("/v1/warehouse/jobs/status", asyncWh.StatusWarehouseJobHandler) // FIXME: add degraded mode
 
+			// fetch freshness of the tables against their SLAs
+			srvMux.Post("/v1/warehouse/table-freshness", tableFreshnessHandler)
+
 			// fetch schema info
 			// TODO: Remove this endpoint once sources change is released
 			srvMux.Get("/v1/warehouse/fetch-tables", fetchTablesHandler)
@@ -98,6 +98,62 @@ func (*warehouseGRPC) TriggerWHUpload(ctx context.Context, request *proto.WHUplo
 	return res, err
 }
 
+func (*warehouseGRPC) PlanWHUpload(ctx context.Context, request *proto.WHUploadRequest) (*proto.WHUploadPlanResponse, error) {
+	uploadReq := UploadReq{
+		UploadId:    request.UploadId,
+		WorkspaceID: request.WorkspaceId,
+		API:         UploadAPI,
+	}
+	uploadReq.API.log.Infof(
+		"[PlanWHUpload] Planning warehouse upload for WorkspaceId: %s, UploadId: %d",
+		uploadReq.WorkspaceID,
+		uploadReq.UploadId,
+	)
+	res, err := uploadReq.PlanWHUpload(ctx)
+	return res, err
+}
+
+func (*warehouseGRPC) CreateWHBackfill(ctx context.Context, request *proto.WHBackfillRequest) (*proto.WHBackfillResponse, error) {
+	backfillReq := BackfillReq{
+		WorkspaceID:   request.WorkspaceId,
+		SourceID:      request.SourceId,
+		DestinationID: request.DestinationId,
+		Tables:        request.Tables,
+		DeleteRows:    request.DeleteRows,
+		API:           UploadAPI,
+	}
+	if request.StartTime != nil {
+		backfillReq.StartTime = request.StartTime.AsTime()
+	}
+	if request.EndTime != nil {
+		backfillReq.EndTime = request.EndTime.AsTime()
+	}
+	backfillReq.API.log.Infof(
+		"[CreateWHBackfill] Creating warehouse backfill for WorkspaceId: %s, SourceId: %s, DestinationId: %s, Tables: %v",
+		backfillReq.WorkspaceID,
+		backfillReq.SourceID,
+		backfillReq.DestinationID,
+		backfillReq.Tables,
+	)
+	res, err := backfillReq.CreateWHBackfill(ctx)
+	return res, err
+}
+
+func (*warehouseGRPC) GetWHBackfill(ctx context.Context, request *proto.WHBackfillStatusRequest) (*proto.WHBackfillResponse, error) {
+	backfillStatusReq := BackfillStatusReq{
+		WorkspaceID: request.WorkspaceId,
+		BackfillID:  request.BackfillId,
+		API:         UploadAPI,
+	}
+	backfillStatusReq.API.log.Infof(
+		"[GetWHBackfill] Fetching warehouse backfill for WorkspaceId: %s, BackfillId: %d",
+		backfillStatusReq.WorkspaceID,
+		backfillStatusReq.BackfillID,
+	)
+	res, err := backfillStatusReq.GetWHBackfill(ctx)
+	return res, err
+}
+
 func (grpc *warehouseGRPC) manageTunnellingSecrets(ctx context.Context, config map[string]interface{}) error {
 	if !warehouseutils.ReadAsBool("useSSH", config) {
 		return nil